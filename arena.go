@@ -0,0 +1,137 @@
+package asn1go
+
+import "github.com/openesim/asn1go/ast"
+
+// An Arena allocates the nodes of the syntax trees that its ParseValue and
+// ParseAssignments methods return in blocks, rather than one at a time,
+// for programs that parse thousands of values, such as the profile
+// elements of many profile packages. When the program is done with the
+// trees, Release frees their nodes as a unit and keeps the blocks for the
+// trees parsed next.
+//
+// The zero Arena is ready to use. An Arena must not be used by several
+// goroutines at once.
+type Arena struct {
+	assignments []ast.Assignment
+	objects     []ast.ObjectNode
+	fields      []ast.FieldNode
+	choices     []ast.ChoiceNode
+	containings []ast.ContainingNode
+	oids        []ast.OIDNode
+	idents      []ast.Ident
+	nulls       []ast.NullNode
+	bools       []ast.BoolNode
+	ints        []ast.IntNode
+	reals       []ast.RealNode
+	hexes       []ast.HexNode
+	bits        []ast.BitsNode
+	strings     []ast.StringNode
+	nodes       []ast.Node // elements of ObjectNodes and OIDNodes
+	heap        bool       // see heapArena
+}
+
+// ParseValue is like the function ParseValue, but allocates the nodes of
+// the syntax tree in a.
+func (a *Arena) ParseValue(data []byte) (ast.Node, error) {
+	return parseValue(data, a)
+}
+
+// ParseAssignments is like the function ParseAssignments, but allocates
+// the nodes of the syntax trees in a.
+func (a *Arena) ParseAssignments(data []byte) ([]*ast.Assignment, error) {
+	return parse(data, a)
+}
+
+// Release frees the nodes of the syntax trees parsed with a, which must
+// not be used afterwards, and readies a for the next trees. The strings
+// of the nodes and the comments attached to them are not allocated in a
+// and stay valid.
+func (a *Arena) Release() {
+	release(&a.assignments)
+	release(&a.objects)
+	release(&a.fields)
+	release(&a.choices)
+	release(&a.containings)
+	release(&a.oids)
+	release(&a.idents)
+	release(&a.nulls)
+	release(&a.bools)
+	release(&a.ints)
+	release(&a.reals)
+	release(&a.hexes)
+	release(&a.bits)
+	release(&a.strings)
+	release(&a.nodes)
+}
+
+// heapArena is the Arena of the parses without one, which allocates each
+// node on the heap. Its blocks stay empty.
+var heapArena = &Arena{heap: true}
+
+// Blocks start with minArenaBlock nodes and double up to maxArenaBlock,
+// so that a parse takes few blocks whatever its size, and Release keeps
+// the last and largest block of each kind of node.
+const (
+	minArenaBlock = 64
+	maxArenaBlock = 8192
+)
+
+// arenaBlock returns the number of nodes of the block that follows a full
+// block of n nodes.
+func arenaBlock(n int) int {
+	switch {
+	case n < minArenaBlock:
+		return minArenaBlock
+	case n >= maxArenaBlock:
+		return maxArenaBlock
+	}
+	return 2 * n
+}
+
+// alloc returns a pointer to a copy of n in block, the current block of
+// a for nodes of its type, or on the heap if a is heapArena. It copies n
+// to a new variable rather than return its address, so that n does not
+// escape.
+func alloc[T any](a *Arena, block *[]T, n T) *T {
+	if a.heap {
+		p := new(T)
+		*p = n
+		return p
+	}
+	if len(*block) == cap(*block) {
+		*block = make([]T, 0, arenaBlock(cap(*block)))
+	}
+	*block = append(*block, n)
+	return &(*block)[len(*block)-1]
+}
+
+// release zeroes the nodes of block, so that they do not keep what they
+// point to alive, and empties it for reuse.
+func release[T any](block *[]T) {
+	var zero T
+	for i := range *block {
+		(*block)[i] = zero
+	}
+	*block = (*block)[:0]
+}
+
+// elements returns a copy of the elements list of an ObjectNode or
+// OIDNode, with no room to append to in place, or nil if list is empty.
+func (a *Arena) elements(list []ast.Node) []ast.Node {
+	if len(list) == 0 {
+		return nil
+	}
+	if a.heap {
+		return append([]ast.Node(nil), list...)
+	}
+	if cap(a.nodes)-len(a.nodes) < len(list) {
+		n := arenaBlock(cap(a.nodes))
+		if n < len(list) {
+			n = len(list)
+		}
+		a.nodes = make([]ast.Node, 0, n)
+	}
+	i := len(a.nodes)
+	a.nodes = append(a.nodes, list...)
+	return a.nodes[i:len(a.nodes):len(a.nodes)]
+}
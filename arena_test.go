@@ -0,0 +1,67 @@
+package asn1go
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestArena(t *testing.T) {
+	inputs := []string{
+		"v T ::= { a 1, b { 2 23 143 }, c alt : 'AB'H, d CONTAINING { e NULL } }",
+		"-- doc\nv T ::= { TRUE, 1.5, \"text\", '0101'B, ident } -- trailing",
+		"a T ::= 1 b T ::= { } c T ::= x : { y -1 }",
+	}
+	var a Arena
+	for round := 0; round < 3; round++ {
+		for _, in := range inputs {
+			want, err := ParseAssignments([]byte(in))
+			if err != nil {
+				t.Fatalf("ParseAssignments(%q): %v", in, err)
+			}
+			got, err := a.ParseAssignments([]byte(in))
+			if err != nil {
+				t.Fatalf("Arena.ParseAssignments(%q): %v", in, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round %d: Arena.ParseAssignments(%q) differs from ParseAssignments", round, in)
+			}
+			out, err := Marshal(got)
+			if err != nil {
+				t.Fatalf("Marshal of the tree of %q: %v", in, err)
+			}
+			if wantOut, _ := Marshal(want); string(out) != string(wantOut) {
+				t.Errorf("round %d: Marshal = %q, want %q", round, out, wantOut)
+			}
+		}
+		a.Release()
+	}
+}
+
+func TestArenaParseValue(t *testing.T) {
+	var a Arena
+	v, err := a.ParseValue([]byte("{ a 1, b { 2, 3 } }"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := ParseValue([]byte("{ a 1, b { 2, 3 } }"))
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Arena.ParseValue = %#v, want %#v", v, want)
+	}
+	if _, err := a.ParseValue([]byte("{ a 1,")); err == nil {
+		t.Error("Arena.ParseValue of a truncated value: no error")
+	}
+}
+
+func TestArenaBlock(t *testing.T) {
+	tests := []struct{ n, want int }{
+		{0, minArenaBlock},
+		{minArenaBlock, 2 * minArenaBlock},
+		{maxArenaBlock / 2, maxArenaBlock},
+		{maxArenaBlock, maxArenaBlock},
+	}
+	for _, tt := range tests {
+		if got := arenaBlock(tt.n); got != tt.want {
+			t.Errorf("arenaBlock(%d) = %d, want %d", tt.n, got, tt.want)
+		}
+	}
+}
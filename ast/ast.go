@@ -0,0 +1,228 @@
+// Package ast declares the types of the syntax tree of ASN.1 value
+// notation that asn1go.ParseValue and asn1go.ParseAssignments return.
+//
+// Unlike asn1go.Unmarshal, which stores values in Go variables, the syntax
+// tree keeps each value as it was written and where: every node records
+// the offsets and the line and column numbers of its first byte and of the
+// byte after it, for linters, editors and other tools that report on value
+// notation files. The comments of the source are attached to the nodes
+// next to them, see Comments.
+//
+// Tools may also rewrite the tree, with Inspect for instance, and write it
+// back with asn1go.Marshal or asn1go.MarshalIndent:
+//
+//	ast.Inspect(v, func(n ast.Node) bool {
+//		if f, ok := n.(*ast.FieldNode); ok && f.Name.Name == "fileID" {
+//			if h, ok := f.Value.(*ast.HexNode); ok {
+//				h.SetBytes(newID)
+//			}
+//		}
+//		return true
+//	})
+//	out, err := asn1go.MarshalIndent(v, "", "  ")
+package ast
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// A Pos is a position in the source of a syntax tree.
+type Pos struct {
+	Offset int // byte offset, starting at 0
+	Line   int // line number, starting at 1
+	Column int // column number in bytes, starting at 1
+}
+
+// String returns the position as "line:column".
+func (p Pos) String() string {
+	return strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Column)
+}
+
+// A Node is a node of the syntax tree.
+type Node interface {
+	Pos() Pos            // position of the first byte of the node
+	End() Pos            // position of the byte after the node
+	Attached() *Comments // comments attached to the node
+}
+
+// A Range is the extent of a node in the source. The nodes embed it to
+// implement Node; the range of a node built by a program rather than
+// parsed is the zero Range.
+type Range struct {
+	From Pos // position of the first byte of the node
+	To   Pos // position of the byte after the node
+}
+
+// Pos returns r.From.
+func (r Range) Pos() Pos { return r.From }
+
+// End returns r.To.
+func (r Range) End() Pos { return r.To }
+
+// A Comment is a comment: -- text up to the end of the line or to the
+// next --. Text holds the comment as written, with its hyphens.
+type Comment struct {
+	Range
+	Text string
+}
+
+// Comments holds the comments attached to a node; the nodes embed it. The
+// parser attaches comments to the top-level values and to the elements of
+// brace-delimited values, which are also the nodes whose comments
+// asn1go.Marshal writes:
+//
+//	-- Doc comment of b, on the lines before it.
+//	b 2, -- Trailing comment of b, on its line after it.
+//
+// The parser adds the comments before the closing brace of a value to the
+// trailing comments of its last element, and the comments at the end of
+// the source to those of the last top-level value. A comment with no
+// element next to it, such as one inside an empty value, is a trailing
+// comment of the innermost element or top-level value holding it.
+type Comments struct {
+	Doc      []*Comment
+	Trailing []*Comment
+}
+
+// Attached returns c.
+func (c *Comments) Attached() *Comments { return c }
+
+// An Assignment is a top-level value, with the value reference and type
+// of its value assignment ("value1 ProfileElement ::= ...") if it has one.
+// Name and Type are nil for a plain value; Type holds the words of a type
+// such as OCTET STRING separated by single spaces.
+type Assignment struct {
+	Range
+	Comments
+	Name  *Ident
+	Type  *Ident
+	Value Node
+}
+
+// An ObjectNode is a brace-delimited value other than an object
+// identifier: a SEQUENCE or SET value, whose Elements are FieldNodes, or
+// a SEQUENCE OF or SET OF value, whose Elements are the element values.
+type ObjectNode struct {
+	Range
+	Comments
+	Elements []Node
+}
+
+// A FieldNode is a component of a SEQUENCE or SET value: its identifier
+// and its value.
+type FieldNode struct {
+	Range
+	Comments
+	Name  *Ident
+	Value Node
+}
+
+// A ChoiceNode is a CHOICE value: the identifier of its alternative and
+// the value of the alternative.
+type ChoiceNode struct {
+	Range
+	Comments
+	Name  *Ident
+	Value Node
+}
+
+// A ContainingNode is a containing value, CONTAINING { ... }: the value
+// that an OCTET STRING or BIT STRING value holds the encoding of.
+type ContainingNode struct {
+	Range
+	Comments
+	Value Node
+}
+
+// An OIDNode is an OBJECT IDENTIFIER value, { 2 23 143 1 }. Its
+// Components are IntNodes, or Idents for components given by name.
+type OIDNode struct {
+	Range
+	Comments
+	Components []Node
+}
+
+// An Ident is an identifier: the name of a value reference, type,
+// component or CHOICE alternative, or a value such as an ENUMERATED value
+// or PLUS-INFINITY.
+type Ident struct {
+	Range
+	Comments
+	Name string
+}
+
+// A NullNode is the NULL value.
+type NullNode struct {
+	Range
+	Comments
+}
+
+// A BoolNode is a BOOLEAN value, TRUE or FALSE.
+type BoolNode struct {
+	Range
+	Comments
+	Value bool
+}
+
+// An IntNode is an integer number, in decimal as written, such as -5.
+type IntNode struct {
+	Range
+	Comments
+	Text string
+}
+
+// Int64 returns the number as an int64.
+func (n *IntNode) Int64() (int64, error) {
+	return strconv.ParseInt(n.Text, 10, 64)
+}
+
+// SetInt64 sets the number to i.
+func (n *IntNode) SetInt64(i int64) {
+	n.Text = strconv.FormatInt(i, 10)
+}
+
+// A RealNode is a real number with a fraction or exponent, as written,
+// such as 1.5E2.
+type RealNode struct {
+	Range
+	Comments
+	Text string
+}
+
+// A HexNode is an hstring, '0A1B'H. Digits holds its hexadecimal digits
+// without any white space between them.
+type HexNode struct {
+	Range
+	Comments
+	Digits string
+}
+
+// Bytes returns the octets of the hstring.
+func (n *HexNode) Bytes() ([]byte, error) {
+	return hex.DecodeString(n.Digits)
+}
+
+// SetBytes sets the hstring to the octets b.
+func (n *HexNode) SetBytes(b []byte) {
+	n.Digits = strings.ToUpper(hex.EncodeToString(b))
+}
+
+// A BitsNode is a bstring, '0101'B. Digits holds its binary digits
+// without any white space between them.
+type BitsNode struct {
+	Range
+	Comments
+	Digits string
+}
+
+// A StringNode is a cstring, such as "text". Value holds the characters
+// between the quotes, with doubled quotation marks written once and
+// without the line ends, and the spacing around them, of a cstring
+// spanning several lines.
+type StringNode struct {
+	Range
+	Comments
+	Value string
+}
@@ -0,0 +1,73 @@
+package ast
+
+import "fmt"
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children
+// of node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses a syntax tree in depth-first order: It starts by calling
+// v.Visit(node); node must not be nil. If the visitor w returned by
+// v.Visit(node) is not nil, Walk is invoked recursively with visitor w for
+// each of the non-nil children of node, followed by a call of
+// w.Visit(nil).
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Assignment:
+		if n.Name != nil {
+			Walk(v, n.Name)
+		}
+		if n.Type != nil {
+			Walk(v, n.Type)
+		}
+		Walk(v, n.Value)
+	case *ObjectNode:
+		walkList(v, n.Elements)
+	case *FieldNode:
+		Walk(v, n.Name)
+		Walk(v, n.Value)
+	case *ChoiceNode:
+		Walk(v, n.Name)
+		Walk(v, n.Value)
+	case *ContainingNode:
+		Walk(v, n.Value)
+	case *OIDNode:
+		walkList(v, n.Components)
+	case *Ident, *NullNode, *BoolNode, *IntNode, *RealNode, *HexNode, *BitsNode, *StringNode:
+		// nothing to do
+	default:
+		panic(fmt.Sprintf("ast.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+func walkList(v Visitor, list []Node) {
+	for _, node := range list {
+		Walk(v, node)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses a syntax tree in depth-first order: It starts by
+// calling f(node); node must not be nil. If f returns true, Inspect
+// invokes f recursively for each of the non-nil children of node,
+// followed by a call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
@@ -0,0 +1,183 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// testTree returns the syntax tree of
+//
+//	v T ::= { a 1, b alt : { 2 23 }, c CONTAINING "x", d { TRUE, NULL } }
+func testTree() *Assignment {
+	return &Assignment{
+		Name: &Ident{Name: "v"},
+		Type: &Ident{Name: "T"},
+		Value: &ObjectNode{Elements: []Node{
+			&FieldNode{Name: &Ident{Name: "a"}, Value: &IntNode{Text: "1"}},
+			&FieldNode{Name: &Ident{Name: "b"}, Value: &ChoiceNode{
+				Name:  &Ident{Name: "alt"},
+				Value: &OIDNode{Components: []Node{&IntNode{Text: "2"}, &IntNode{Text: "23"}}},
+			}},
+			&FieldNode{Name: &Ident{Name: "c"}, Value: &ContainingNode{Value: &StringNode{Value: "x"}}},
+			&FieldNode{Name: &Ident{Name: "d"}, Value: &ObjectNode{Elements: []Node{&BoolNode{Value: true}, &NullNode{}}}},
+		}},
+	}
+}
+
+// label describes n for the visit order, or ")" for the nil after the
+// children of a node.
+func label(n Node) string {
+	switch n := n.(type) {
+	case nil:
+		return ")"
+	case *Ident:
+		return n.Name
+	case *IntNode:
+		return n.Text
+	case *StringNode:
+		return fmt.Sprintf("%q", n.Value)
+	case *BoolNode:
+		return fmt.Sprint(n.Value)
+	}
+	return reflect.TypeOf(n).Elem().Name()
+}
+
+// recorder records the nodes it visits, and does not visit the children
+// of those skip selects.
+type recorder struct {
+	visits *[]string
+	skip   func(Node) bool
+}
+
+func (r recorder) Visit(n Node) Visitor {
+	*r.visits = append(*r.visits, label(n))
+	if n != nil && r.skip != nil && r.skip(n) {
+		return nil
+	}
+	return r
+}
+
+func TestWalk(t *testing.T) {
+	tests := []struct {
+		name string
+		skip func(Node) bool
+		want []string
+	}{
+		{"all", nil, []string{
+			"Assignment", "v", ")", "T", ")",
+			"ObjectNode",
+			"FieldNode", "a", ")", "1", ")", ")",
+			"FieldNode", "b", ")", "ChoiceNode", "alt", ")", "OIDNode", "2", ")", "23", ")", ")", ")", ")",
+			"FieldNode", "c", ")", "ContainingNode", `"x"`, ")", ")", ")",
+			"FieldNode", "d", ")", "ObjectNode", "true", ")", "NullNode", ")", ")", ")",
+			")", ")",
+		}},
+		{"no children of the assignment", func(n Node) bool { _, ok := n.(*Assignment); return ok }, []string{"Assignment"}},
+		{"no children of CHOICE, CONTAINING and inner values", func(n Node) bool {
+			switch n := n.(type) {
+			case *ChoiceNode, *ContainingNode:
+				return true
+			case *ObjectNode:
+				return len(n.Elements) == 2 // the value of d
+			}
+			return false
+		}, []string{
+			"Assignment", "v", ")", "T", ")",
+			"ObjectNode",
+			"FieldNode", "a", ")", "1", ")", ")",
+			"FieldNode", "b", ")", "ChoiceNode", ")",
+			"FieldNode", "c", ")", "ContainingNode", ")",
+			"FieldNode", "d", ")", "ObjectNode", ")",
+			")", ")",
+		}},
+	}
+	for _, tt := range tests {
+		var visits []string
+		Walk(recorder{&visits, tt.skip}, testTree())
+		if !reflect.DeepEqual(visits, tt.want) {
+			t.Errorf("%s: Walk visits\nhave %v\nwant %v", tt.name, visits, tt.want)
+		}
+	}
+}
+
+func TestWalkAssignmentWithoutName(t *testing.T) {
+	var visits []string
+	Walk(recorder{visits: &visits}, &Assignment{Value: &IntNode{Text: "5"}})
+	if want := []string{"Assignment", "5", ")", ")"}; !reflect.DeepEqual(visits, want) {
+		t.Errorf("Walk visits %v, want %v", visits, want)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	tests := []struct {
+		name string
+		f    func(Node) bool // whether to go on into the children
+		want []string
+	}{
+		{"all", func(Node) bool { return true }, []string{
+			"Assignment", "v", ")", "T", ")", "ObjectNode",
+			"FieldNode", "a", ")", "1", ")", ")",
+			"FieldNode", "b", ")", "ChoiceNode", "alt", ")", "OIDNode", "2", ")", "23", ")", ")", ")", ")",
+			"FieldNode", "c", ")", "ContainingNode", `"x"`, ")", ")", ")",
+			"FieldNode", "d", ")", "ObjectNode", "true", ")", "NullNode", ")", ")", ")",
+			")", ")",
+		}},
+		{"none", func(Node) bool { return false }, []string{"Assignment"}},
+		{"not into components", func(n Node) bool { _, ok := n.(*FieldNode); return !ok }, []string{
+			"Assignment", "v", ")", "T", ")", "ObjectNode",
+			"FieldNode", "FieldNode", "FieldNode", "FieldNode",
+			")", ")",
+		}},
+	}
+	for _, tt := range tests {
+		var visits []string
+		Inspect(testTree(), func(n Node) bool {
+			visits = append(visits, label(n))
+			return tt.f(n)
+		})
+		if !reflect.DeepEqual(visits, tt.want) {
+			t.Errorf("%s: Inspect visits\nhave %v\nwant %v", tt.name, visits, tt.want)
+		}
+	}
+}
+
+func TestInspectFind(t *testing.T) {
+	// Stopping at the first OIDNode found.
+	var found *OIDNode
+	var visited int
+	Inspect(testTree(), func(n Node) bool {
+		if found != nil {
+			return false
+		}
+		if n == nil {
+			return true
+		}
+		visited++
+		found, _ = n.(*OIDNode)
+		return found == nil
+	})
+	if found == nil || len(found.Components) != 2 {
+		t.Fatalf("Inspect found %v", found)
+	}
+	// Assignment, v, T, ObjectNode, FieldNode a, a, 1, FieldNode b, b,
+	// ChoiceNode, alt, OIDNode.
+	if visited != 12 {
+		t.Errorf("Inspect visited %d nodes before the OIDNode, want 12", visited)
+	}
+}
+
+// unknownNode is a Node of a type that Walk does not know.
+type unknownNode struct {
+	Range
+	Comments
+}
+
+func TestWalkUnexpectedNode(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Walk of an unknown node type did not panic")
+		}
+	}()
+	Walk(recorder{visits: new([]string)}, &unknownNode{})
+}
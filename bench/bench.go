@@ -0,0 +1,382 @@
+// Package bench measures how fast asn1go scans, decodes and encodes the
+// value notation of profile packages of realistic size, and how much it
+// allocates doing so, so that optimizations and regressions are
+// quantified rather than guessed at.
+//
+// The corpora are synthetic profile packages of growing size, from
+// Corpora, and real ones read from files, such as the GSMA Generic eUICC
+// Test Profile:
+//
+//	corpora, err := bench.Corpora()
+//	if err != nil {
+//		return err
+//	}
+//	results, err := bench.Run(corpora)
+//	if err != nil {
+//		return err
+//	}
+//	for _, r := range results {
+//		fmt.Println(r)
+//	}
+//
+// Results saved as JSON from an earlier run are the baseline that Compare
+// checks later results against, as the bench command of asn1go does. The
+// same benchmarks on the synthetic corpora also run with go test -bench.
+package bench
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"time"
+
+	"github.com/openesim/asn1go"
+	"github.com/openesim/asn1go/saip"
+)
+
+// A Corpus is the value notation of a profile package to measure with: a
+// sequence of value assignments of ProfileElement values.
+type Corpus struct {
+	Name string
+	Text []byte
+}
+
+// Corpora returns the synthetic profile packages that Run measures with
+// by default: small, a few files of some bytes each, like a test profile;
+// medium, the size of a production profile; and large, a profile with
+// many large files, such as one with applets and phonebooks.
+func Corpora() ([]Corpus, error) {
+	sizes := []struct {
+		name        string
+		files, size int
+	}{
+		{"small", 16, 32},
+		{"medium", 128, 256},
+		{"large", 1024, 1024},
+	}
+	corpora := make([]Corpus, len(sizes))
+	for i, s := range sizes {
+		c, err := Synthetic(s.name, s.files, s.size)
+		if err != nil {
+			return nil, err
+		}
+		corpora[i] = c
+	}
+	return corpora, nil
+}
+
+// filesPerElement is the number of files each genericFileManagement
+// element of a synthetic profile package creates.
+const filesPerElement = 32
+
+// Synthetic returns a synthetic profile package: a profile header, an MF,
+// genericFileManagement elements that create files EFs of size bytes of
+// pseudo-random content, and the end element. The package is the same for
+// the same arguments.
+func Synthetic(name string, files, size int) (Corpus, error) {
+	if files < 0 || firstFileID+files > 0xffff {
+		return Corpus{}, fmt.Errorf("bench: %d files do not have distinct file identifiers", files)
+	}
+	rnd := rand.New(rand.NewSource(int64(files)*1000003 + int64(size)))
+	iccid := []byte{0x98, 0x10, 0x99, 0x00, 0x00, 0x21, 0x43, 0x65, 0x87, 0xf9}
+	profileType := "synthetic " + name
+	p := saip.NewProfilePackage(&saip.ProfileHeader{
+		MajorVersion:            2,
+		MinorVersion:            3,
+		ProfileType:             &profileType,
+		Iccid:                   iccid,
+		EUICCMandatoryServices:  saip.ServicesList{},
+		EUICCMandatoryGFSTEList: []asn1go.ObjectIdentifier{{2, 23, 143, 1, 2, 1}},
+	})
+	p.Add(saip.ProfileElement{Mf: &saip.PEMF{
+		TemplateID: asn1go.ObjectIdentifier{2, 23, 143, 1, 2, 1},
+		Mf: saip.File{{FileDescriptor: &saip.Fcp{
+			PinStatusTemplateDO: []byte{0x90, 0x01, 0x00, 0x83, 0x01, 0x01},
+		}}},
+		EfIccid: saip.File{{FileDescriptor: &saip.Fcp{}}, {FillFileContent: iccid}},
+		EfArr: saip.File{{FileDescriptor: &saip.Fcp{}}, {FillFileContent: []byte{
+			0x80, 0x01, 0x01, 0xa4, 0x06, 0x83, 0x01, 0x01, 0x95, 0x01, 0x08,
+		}}},
+	}})
+	for first := 0; first < files; first += filesPerElement {
+		cmd := saip.FileManagement{{FilePath: []byte{0x7f, 0xf1}}}
+		for i := first; i < first+filesPerElement && i < files; i++ {
+			content := make([]byte, size)
+			rnd.Read(content)
+			cmd = append(cmd,
+				saip.FileManagementItem{CreateFCP: &saip.Fcp{
+					FileDescriptor:               []byte{0x41, 0x21},
+					FileID:                       fileID(i),
+					SecurityAttributesReferenced: []byte{0x6f, 0x06, 0x01},
+					EfFileSize:                   []byte{byte(size >> 8), byte(size)},
+				}},
+				saip.FileManagementItem{FillFileContent: content},
+			)
+		}
+		p.Add(saip.ProfileElement{GenericFileManagement: &saip.PEGenericFileManagement{
+			FileManagementCMD: []saip.FileManagement{cmd},
+		}})
+	}
+	p.Add(saip.ProfileElement{End: &saip.PEEnd{}})
+	if err := p.Check(); err != nil {
+		return Corpus{}, err
+	}
+	p.Number()
+
+	text, err := asn1go.MarshalIndent(assignments(p.Elements), "", "  ")
+	if err != nil {
+		return Corpus{}, err
+	}
+	return Corpus{Name: name, Text: text}, nil
+}
+
+// fileID returns the file identifier of the i-th file of a synthetic
+// profile package, 6F00 for the first, 6F01 for the next and so on, past
+// 6FFF for more than 256 files.
+func fileID(i int) []byte {
+	id := make([]byte, 2)
+	binary.BigEndian.PutUint16(id, uint16(firstFileID+i))
+	return id
+}
+
+// firstFileID is the file identifier of the first file of a synthetic
+// profile package.
+const firstFileID = 0x6f00
+
+// assignments returns the value assignments of the profile elements pes,
+// value1 ProfileElement ::= ... and so on.
+func assignments(pes []saip.ProfileElement) []asn1go.ValueAssignment {
+	vas := make([]asn1go.ValueAssignment, len(pes))
+	for i := range pes {
+		vas[i] = asn1go.ValueAssignment{Name: fmt.Sprint("value", i+1), Type: "ProfileElement", Value: &pes[i]}
+	}
+	return vas
+}
+
+// A Benchmark measures one operation on a corpus. Prepare is called once
+// before the measurement, to check that the operation succeeds on the
+// corpus and to set up what it needs; the function it returns performs
+// the operation once, and is what is measured.
+type Benchmark struct {
+	Name    string
+	Prepare func(c Corpus) (func(), error)
+}
+
+// Benchmarks are the benchmarks Run runs on each corpus:
+//
+//   - scan checks the syntax of the corpus with asn1go.Valid;
+//   - decode decodes it into saip.ProfileElement values with
+//     asn1go.Unmarshal;
+//   - encode encodes those values back to value notation with
+//     asn1go.Marshal.
+var Benchmarks = []Benchmark{
+	{"scan", prepareScan},
+	{"decode", prepareDecode},
+	{"encode", prepareEncode},
+}
+
+func prepareScan(c Corpus) (func(), error) {
+	if !asn1go.Valid(c.Text) {
+		return nil, asn1go.Unmarshal(c.Text, new(interface{}))
+	}
+	return func() { asn1go.Valid(c.Text) }, nil
+}
+
+func prepareDecode(c Corpus) (func(), error) {
+	var pes []saip.ProfileElement
+	if err := asn1go.Unmarshal(c.Text, &pes); err != nil {
+		return nil, err
+	}
+	return func() {
+		var pes []saip.ProfileElement
+		asn1go.Unmarshal(c.Text, &pes)
+	}, nil
+}
+
+func prepareEncode(c Corpus) (func(), error) {
+	var pes []saip.ProfileElement
+	if err := asn1go.Unmarshal(c.Text, &pes); err != nil {
+		return nil, err
+	}
+	vas := assignments(pes)
+	if _, err := asn1go.Marshal(vas); err != nil {
+		return nil, err
+	}
+	return func() { asn1go.Marshal(vas) }, nil
+}
+
+// A Result is the measurement of a benchmark on a corpus.
+type Result struct {
+	Corpus      string `json:"corpus"`
+	Benchmark   string `json:"benchmark"`
+	Bytes       int64  `json:"bytes"` // size of the value notation of the corpus
+	N           int    `json:"n"`     // number of iterations
+	NsPerOp     int64  `json:"nsPerOp"`
+	AllocsPerOp int64  `json:"allocsPerOp"`
+	BytesPerOp  int64  `json:"bytesPerOp"` // bytes allocated per iteration
+}
+
+// Name returns the name of r as go test names benchmarks, such as
+// decode/medium.
+func (r Result) Name() string { return r.Benchmark + "/" + r.Corpus }
+
+// MBPerSec returns the throughput of r in megabytes of value notation per
+// second.
+func (r Result) MBPerSec() float64 {
+	if r.NsPerOp <= 0 {
+		return 0
+	}
+	return float64(r.Bytes) * 1e3 / float64(r.NsPerOp)
+}
+
+// String returns r in the format of go test -bench -benchmem.
+func (r Result) String() string {
+	return fmt.Sprintf("%-24s %8d %12d ns/op %8.2f MB/s %12d B/op %8d allocs/op",
+		r.Name(), r.N, r.NsPerOp, r.MBPerSec(), r.BytesPerOp, r.AllocsPerOp)
+}
+
+// Run runs the Benchmarks on each of the corpora, for about a second
+// each, and returns their results, by corpus and then by benchmark. A
+// corpus that a benchmark fails on is reported as a *CorpusError before
+// any measurement.
+func Run(corpora []Corpus) ([]Result, error) {
+	runs := make([]func(), 0, len(corpora)*len(Benchmarks))
+	for _, c := range corpora {
+		for _, bm := range Benchmarks {
+			run, err := bm.Prepare(c)
+			if err != nil {
+				return nil, &CorpusError{Corpus: c.Name, Benchmark: bm.Name, Err: err}
+			}
+			runs = append(runs, run)
+		}
+	}
+
+	var results []Result
+	for i, c := range corpora {
+		for j, bm := range Benchmarks {
+			r := measure(runs[i*len(Benchmarks)+j])
+			r.Corpus, r.Benchmark, r.Bytes = c.Name, bm.Name, int64(len(c.Text))
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
+
+// benchTime is the time measure runs an operation for, about.
+var benchTime = time.Second
+
+// maxIterations bounds the number of times measure runs an operation.
+const maxIterations = 1e9
+
+// measure runs op for about benchTime, and returns the number of
+// iterations and the time, allocations and allocated bytes per iteration.
+// Like go test -bench, it runs op once and then as many times as it
+// predicts will take benchTime, until a run takes that long.
+func measure(op func()) Result {
+	n := 1
+	for {
+		d, allocs, bytes := runN(op, n)
+		if d >= benchTime || n >= maxIterations {
+			return Result{
+				N:           n,
+				NsPerOp:     d.Nanoseconds() / int64(n),
+				AllocsPerOp: int64(allocs / uint64(n)),
+				BytesPerOp:  int64(bytes / uint64(n)),
+			}
+		}
+		// Aim 20% beyond benchTime, growing at least by one iteration
+		// and at most a hundredfold.
+		next := 100 * n
+		if ns := d.Nanoseconds(); ns > 0 && int64(next) > benchTime.Nanoseconds()*int64(n)/ns {
+			next = int(benchTime.Nanoseconds() * int64(n) / ns)
+			next += next / 5
+		}
+		if next <= n {
+			next = n + 1
+		}
+		if next > maxIterations {
+			next = maxIterations
+		}
+		n = next
+	}
+}
+
+// runN runs op n times, and returns the time it took and the number of
+// allocations and of bytes allocated.
+func runN(op func(), n int) (d time.Duration, allocs, bytes uint64) {
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		op()
+	}
+	d = time.Since(start)
+	runtime.ReadMemStats(&after)
+	return d, after.Mallocs - before.Mallocs, after.TotalAlloc - before.TotalAlloc
+}
+
+// A CorpusError describes a corpus that a benchmark cannot run on, such
+// as a file that is not a profile package in value notation.
+type CorpusError struct {
+	Corpus    string
+	Benchmark string
+	Err       error
+}
+
+func (e *CorpusError) Error() string {
+	return "bench: " + e.Benchmark + " fails on " + e.Corpus + ": " + e.Err.Error()
+}
+
+func (e *CorpusError) Unwrap() error { return e.Err }
+
+// A Regression is a result that is worse than its baseline.
+type Regression struct {
+	Name     string // name of the result, as Result.Name returns it
+	Metric   string // "ns/op", "allocs/op" or "B/op"
+	Baseline int64
+	Current  int64
+}
+
+func (r Regression) String() string {
+	return fmt.Sprintf("%s: %d %s, was %d (%+.1f%%)", r.Name, r.Current, r.Metric, r.Baseline,
+		100*float64(r.Current-r.Baseline)/float64(r.Baseline))
+}
+
+// Compare returns the regressions of the results against those of the
+// baseline with the same names: times, allocations or allocated bytes per
+// operation that grew by more than the fraction tolerance, such as 0.1
+// for 10%. Results without a baseline, and baselines without a result,
+// are left out.
+//
+// Times vary from run to run and from machine to machine more than
+// allocations do, which the same build on the same input repeats almost
+// exactly; a baseline is best recorded on the machine that checks against
+// it.
+func Compare(baseline, results []Result, tolerance float64) []Regression {
+	base := make(map[string]Result, len(baseline))
+	for _, r := range baseline {
+		base[r.Name()] = r
+	}
+	var regs []Regression
+	for _, r := range results {
+		b, ok := base[r.Name()]
+		if !ok {
+			continue
+		}
+		for _, m := range []struct {
+			metric        string
+			baseline, cur int64
+		}{
+			{"ns/op", b.NsPerOp, r.NsPerOp},
+			{"allocs/op", b.AllocsPerOp, r.AllocsPerOp},
+			{"B/op", b.BytesPerOp, r.BytesPerOp},
+		} {
+			if float64(m.cur) > float64(m.baseline)*(1+tolerance) {
+				regs = append(regs, Regression{r.Name(), m.metric, m.baseline, m.cur})
+			}
+		}
+	}
+	return regs
+}
@@ -0,0 +1,209 @@
+package bench
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/openesim/asn1go"
+	"github.com/openesim/asn1go/saip"
+)
+
+// benchmark runs the benchmark named name on each of the Corpora.
+func benchmark(b *testing.B, name string) {
+	corpora, err := Corpora()
+	if err != nil {
+		b.Fatal(err)
+	}
+	var bm Benchmark
+	for _, bm = range Benchmarks {
+		if bm.Name == name {
+			break
+		}
+	}
+	for _, c := range corpora {
+		op, err := bm.Prepare(c)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(c.Name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(c.Text)))
+			for i := 0; i < b.N; i++ {
+				op()
+			}
+		})
+	}
+}
+
+func BenchmarkScan(b *testing.B)   { benchmark(b, "scan") }
+func BenchmarkDecode(b *testing.B) { benchmark(b, "decode") }
+func BenchmarkEncode(b *testing.B) { benchmark(b, "encode") }
+
+func TestSynthetic(t *testing.T) {
+	tests := []struct {
+		files, size int
+	}{
+		{0, 0},
+		{1, 16},
+		{filesPerElement + 1, 32},
+	}
+	for _, tt := range tests {
+		c, err := Synthetic("test", tt.files, tt.size)
+		if err != nil {
+			t.Fatalf("Synthetic(%d, %d): %v", tt.files, tt.size, err)
+		}
+		again, err := Synthetic("test", tt.files, tt.size)
+		if err != nil || !bytes.Equal(c.Text, again.Text) {
+			t.Errorf("Synthetic(%d, %d) is not the same twice", tt.files, tt.size)
+		}
+		for _, bm := range Benchmarks {
+			if _, err := bm.Prepare(c); err != nil {
+				t.Errorf("Synthetic(%d, %d): %s: %v", tt.files, tt.size, bm.Name, err)
+			}
+		}
+		if n := len(fileIDs(t, c)); n != tt.files {
+			t.Errorf("Synthetic(%d, %d) has %d distinct file identifiers", tt.files, tt.size, n)
+		}
+	}
+	if _, err := Synthetic("test", 0x10000, 1); err == nil {
+		t.Error("Synthetic with more files than file identifiers: no error")
+	}
+}
+
+// fileIDs returns the file identifiers that the profile package c creates
+// files with.
+func fileIDs(t *testing.T, c Corpus) map[string]bool {
+	t.Helper()
+	var pes []saip.ProfileElement
+	if err := asn1go.Unmarshal(c.Text, &pes); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&saip.ProfilePackage{Elements: pes}).Validate(); err != nil {
+		t.Errorf("%s: Validate: %v", c.Name, err)
+	}
+	ids := make(map[string]bool)
+	for _, pe := range pes {
+		if pe.GenericFileManagement == nil {
+			continue
+		}
+		for _, fm := range pe.GenericFileManagement.FileManagementCMD {
+			for _, cmd := range fm {
+				if cmd.CreateFCP != nil {
+					ids[string(cmd.CreateFCP.FileID)] = true
+				}
+			}
+		}
+	}
+	return ids
+}
+
+func TestCorporaFileIDs(t *testing.T) {
+	corpora, err := Corpora()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range corpora {
+		ids := fileIDs(t, c)
+		for _, id := range [][]byte{{0x6f, 0x00}, {0x6f, 0xff}, {0x70, 0x00}, {0x72, 0xff}} {
+			if c.Name == "large" && !ids[string(id)] {
+				t.Errorf("%s: no file %X", c.Name, id)
+			}
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	defer func(d time.Duration) { benchTime = d }(benchTime)
+	benchTime = time.Millisecond
+
+	c, err := Synthetic("tiny", 2, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := Run([]Corpus{c})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(Benchmarks) {
+		t.Fatalf("Run returned %d results, want %d", len(results), len(Benchmarks))
+	}
+	for i, r := range results {
+		if r.Corpus != "tiny" || r.Benchmark != Benchmarks[i].Name || r.Bytes != int64(len(c.Text)) {
+			t.Errorf("result %d is %s of %d bytes", i, r.Name(), r.Bytes)
+		}
+		if r.N < 1 || r.NsPerOp <= 0 {
+			t.Errorf("%s: %d iterations of %d ns", r.Name(), r.N, r.NsPerOp)
+		}
+	}
+	if results[1].AllocsPerOp == 0 {
+		t.Errorf("%s: no allocations", results[1].Name())
+	}
+
+	_, err = Run([]Corpus{{Name: "bad", Text: []byte("{")}})
+	var ce *CorpusError
+	if !errors.As(err, &ce) || ce.Corpus != "bad" || ce.Benchmark != "scan" {
+		t.Errorf("Run of a bad corpus: %v, want a CorpusError for scan", err)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	baseline := []Result{
+		{Corpus: "small", Benchmark: "decode", NsPerOp: 1000, AllocsPerOp: 100, BytesPerOp: 4096},
+		{Corpus: "large", Benchmark: "decode", NsPerOp: 9000, AllocsPerOp: 900, BytesPerOp: 65536},
+	}
+	tests := []struct {
+		name    string
+		results []Result
+		want    []Regression
+	}{
+		{
+			"within tolerance",
+			[]Result{{Corpus: "small", Benchmark: "decode", NsPerOp: 1100, AllocsPerOp: 100, BytesPerOp: 4000}},
+			nil,
+		},
+		{
+			"slower and more allocations",
+			[]Result{{Corpus: "small", Benchmark: "decode", NsPerOp: 1200, AllocsPerOp: 120, BytesPerOp: 4096}},
+			[]Regression{
+				{"decode/small", "ns/op", 1000, 1200},
+				{"decode/small", "allocs/op", 100, 120},
+			},
+		},
+		{
+			"more bytes",
+			[]Result{{Corpus: "large", Benchmark: "decode", NsPerOp: 9000, AllocsPerOp: 900, BytesPerOp: 80000}},
+			[]Regression{{"decode/large", "B/op", 65536, 80000}},
+		},
+		{
+			"no baseline",
+			[]Result{{Corpus: "medium", Benchmark: "decode", NsPerOp: 1e9}},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		got := Compare(baseline, tt.results, 0.1)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: Compare = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: Compare = %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestResultString(t *testing.T) {
+	r := Result{Corpus: "small", Benchmark: "scan", Bytes: 2000, N: 10, NsPerOp: 1000, AllocsPerOp: 3, BytesPerOp: 64}
+	if got := r.MBPerSec(); got != 2000 {
+		t.Errorf("MBPerSec() = %v, want 2000", got)
+	}
+	want := "scan/small                     10         1000 ns/op  2000.00 MB/s           64 B/op        3 allocs/op"
+	if got := r.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
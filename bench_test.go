@@ -0,0 +1,53 @@
+package asn1go_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/openesim/asn1go"
+	"github.com/openesim/asn1go/bench"
+)
+
+// BenchmarkDecoderZeroCopy decodes the profile elements of a large
+// synthetic profile package with a Decoder, with and without ZeroCopy.
+func BenchmarkDecoderZeroCopy(b *testing.B) {
+	c, err := bench.Synthetic("large", 1024, 1024)
+	if err != nil {
+		b.Fatal(err)
+	}
+	targets := []struct {
+		name string
+		new  func() interface{}
+	}{
+		{"RawValue", func() interface{} { return new(asn1go.RawValue) }},
+		{"interface", func() interface{} { return new(interface{}) }},
+	}
+	for _, target := range targets {
+		for _, zeroCopy := range []bool{false, true} {
+			name := target.name + "/copy"
+			if zeroCopy {
+				name = target.name + "/zerocopy"
+			}
+			b.Run(name, func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(len(c.Text)))
+				for i := 0; i < b.N; i++ {
+					dec := asn1go.NewDecoder(bytes.NewReader(c.Text))
+					if zeroCopy {
+						dec.ZeroCopy()
+					}
+					for {
+						err := dec.Decode(target.new())
+						if err == io.EOF {
+							break
+						}
+						if err != nil {
+							b.Fatal(err)
+						}
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package asn1go
+
+import "reflect"
+
+// EncodeCER returns the CER encoding of v as a value of the ASN.1 type t.
+// It takes the same values as EncodeDER. CER differs from DER in that
+// constructed encodings have the indefinite length, and that string
+// values longer than 1000 octets are split into segments of 1000 octets.
+func EncodeCER(t *Type, v interface{}) ([]byte, error) {
+	e := derEncoder{cer: true}
+	return e.marshal(t, reflect.ValueOf(v))
+}
+
+// MarshalCER returns the CER encoding of v, as a value of the ASN.1 type
+// TypeOf derives from the type of v.
+func MarshalCER(v interface{}) ([]byte, error) {
+	t, err := TypeOf(v)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeCER(t, v)
+}
+
+// DecodeOptions select the BER encodings that DecodeOptions.Decode
+// rejects, and the checks it makes on the decoded values. The zero
+// DecodeOptions accept any BER encoding, which includes the CER and DER
+// ones.
+type DecodeOptions struct {
+	// RejectIndefiniteLength rejects constructed encodings with the
+	// indefinite length, which BER and CER allow but DER does not.
+	RejectIndefiniteLength bool
+
+	// RejectConstructedStrings rejects BIT STRING, OCTET STRING and
+	// character string values split into segments in a constructed
+	// encoding.
+	RejectConstructedStrings bool
+
+	// RejectNonCanonical rejects encodings that BER allows but CER and DER
+	// do not: tags and lengths in more octets than needed, INTEGER values
+	// with redundant leading octets, BOOLEAN true values other than FF and
+	// BIT STRING values with unused bits set.
+	RejectNonCanonical bool
+
+	// CheckConstraints rejects decoded values that violate the
+	// constraints of their types, as CheckConstraints reports them.
+	CheckConstraints bool
+}
+
+// derDecodeOptions accept DER encodings only.
+var derDecodeOptions = DecodeOptions{
+	RejectIndefiniteLength:   true,
+	RejectConstructedStrings: true,
+	RejectNonCanonical:       true,
+}
+
+// Decode is like DecodeDER but accepts the encodings that o allows.
+func (o DecodeOptions) Decode(t *Type, data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+	d := derDecoder{data: data, opts: o}
+	if err := d.unmarshal(t, rv); err != nil {
+		return err
+	}
+	if o.CheckConstraints {
+		return CheckConstraints(t, rv.Interface())
+	}
+	return nil
+}
+
+// Unmarshal is like UnmarshalDER but accepts the encodings that o allows.
+func (o DecodeOptions) Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+	t, err := TypeOf(v)
+	if err != nil {
+		return err
+	}
+	return o.Decode(t, data, v)
+}
+
+// DecodeBER is like DecodeDER but accepts any BER encoding, such as the
+// indefinite-length encodings some personalization tools write.
+func DecodeBER(t *Type, data []byte, v interface{}) error {
+	return DecodeOptions{}.Decode(t, data, v)
+}
+
+// UnmarshalBER is like UnmarshalDER but accepts any BER encoding.
+func UnmarshalBER(data []byte, v interface{}) error {
+	return DecodeOptions{}.Unmarshal(data, v)
+}
+
+// isStringKind reports whether values of kind k are strings, which BER
+// allows to split into segments.
+func isStringKind(k Kind) bool {
+	switch k {
+	case KindBitString, KindOctetString, KindUTF8String, KindNumericString, KindPrintableString,
+		KindIA5String, KindVisibleString, KindUTCTime, KindGeneralizedTime:
+		return true
+	}
+	return false
+}
+
+// joinSegments returns the constructed encoding x of a string value of
+// type t as a primitive encoding, with the contents of its segments
+// joined.
+func (d *derDecoder) joinSegments(t *Type, x tlv) tlv {
+	want := Tag{ClassUniversal, t.Kind.universalTag()}
+	bitString := t.Kind == KindBitString
+	var content []byte
+	if bitString {
+		content = []byte{0} // the unused bits of the last segment
+	}
+	var join func(x tlv)
+	join = func(x tlv) {
+		for _, seg := range d.elements(x) {
+			if seg.tag != want {
+				d.syntaxError(seg.off, "segment with tag %v in %v", seg.tag, t.Kind)
+			}
+			if seg.constructed {
+				join(seg)
+				continue
+			}
+			b := seg.content
+			if bitString {
+				if content[0] != 0 || len(b) == 0 {
+					d.syntaxError(seg.off, "invalid BIT STRING segment")
+				}
+				content[0], b = b[0], b[1:]
+			}
+			content = append(content, b...)
+		}
+	}
+	join(x)
+	return tlv{x.tag, false, content, x.raw, x.off}
+}
+
+// trimInteger drops the redundant leading octets of the two's complement
+// integer b.
+func trimInteger(b []byte) []byte {
+	for len(b) > 1 && (b[0] == 0 && b[1]&0x80 == 0 || b[0] == 0xff && b[1]&0x80 != 0) {
+		b = b[1:]
+	}
+	return b
+}
+
+// cerSegmentSize is the number of contents octets of the segments CER
+// splits long strings into.
+const cerSegmentSize = 1000
+
+// appendIndefinite appends the constructed encoding of the given content
+// with the indefinite length to dst.
+func appendIndefinite(dst []byte, tag Tag, content []byte) []byte {
+	dst = appendTag(dst, tag, true)
+	dst = append(dst, 0x80)
+	dst = append(dst, content...)
+	return append(dst, 0x00, 0x00)
+}
+
+// appendSegments appends the CER encoding of the string value of kind k
+// with the contents octets content to dst: a constructed encoding of
+// segments of cerSegmentSize octets. The unused bits octet of a BIT STRING
+// goes into its last segment, and the other segments have none unused.
+func appendSegments(dst []byte, k Kind, content []byte) []byte {
+	tag := Tag{ClassUniversal, k.universalTag()}
+	var segs []byte
+	if k == KindBitString {
+		unused, data := content[0], content[1:]
+		for len(data) > cerSegmentSize-1 {
+			segs = appendTLV(segs, tag, false, append([]byte{0}, data[:cerSegmentSize-1]...))
+			data = data[cerSegmentSize-1:]
+		}
+		segs = appendTLV(segs, tag, false, append([]byte{unused}, data...))
+	} else {
+		for len(content) > cerSegmentSize {
+			segs = appendTLV(segs, tag, false, content[:cerSegmentSize])
+			content = content[cerSegmentSize:]
+		}
+		segs = appendTLV(segs, tag, false, content)
+	}
+	return appendIndefinite(dst, tag, segs)
+}
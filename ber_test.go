@@ -0,0 +1,147 @@
+package asn1go
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncodeCER(t *testing.T) {
+	integer := &Type{Kind: KindInteger}
+	seq := &Type{Kind: KindSequence, Components: []Component{
+		{Name: "a", Type: integer},
+		{Name: "b", Type: &Type{Kind: KindOctetString}, Optional: true},
+	}}
+	tests := []struct {
+		t    *Type
+		v    interface{}
+		want string
+	}{
+		{integer, 5, "02 01 05"},
+		{&Type{Kind: KindOctetString}, []byte{0xAB}, "04 01 AB"},
+		{&Type{Kind: KindOctetString}, make([]byte, 1000), "04 82 03 E8" + strings.Repeat(" 00", 1000)},
+		{
+			&Type{Kind: KindOctetString},
+			make([]byte, 1001),
+			"24 80 04 82 03 E8" + strings.Repeat(" 00", 1000) + " 04 01 00 00 00",
+		},
+		{seq, map[string]interface{}{"a": 1}, "30 80 02 01 01 00 00"},
+		{seq, map[string]interface{}{"a": 1, "b": []byte{0xAB}}, "30 80 02 01 01 04 01 AB 00 00"},
+		{&Type{Kind: KindSetOf, Elem: integer}, []int{2, 1}, "31 80 02 01 01 02 01 02 00 00"},
+		{&Type{Kind: KindInteger, Tags: []TypeTag{{Tag: Tag{ClassContextSpecific, 1}, Explicit: true}}}, 5, "A1 80 02 01 05 00 00"},
+		{&Type{Kind: KindInteger, Tags: []TypeTag{{Tag: Tag{ClassContextSpecific, 1}}}}, 5, "81 01 05"},
+	}
+	for _, tt := range tests {
+		want := fromHex(tt.want)
+		b, err := EncodeCER(tt.t, tt.v)
+		if err != nil {
+			t.Errorf("EncodeCER(%v): %v", tt.t, err)
+			continue
+		}
+		if !bytes.Equal(b, want) {
+			t.Errorf("EncodeCER(%v) = % X, want % X", tt.t, b, want)
+		}
+	}
+}
+
+func TestMarshalCER(t *testing.T) {
+	type record struct {
+		Flag   bool
+		Number int
+	}
+	b, err := MarshalCER(record{true, 5})
+	if err != nil {
+		t.Fatalf("MarshalCER: %v", err)
+	}
+	if want := fromHex("30 80 01 01 FF 02 01 05 00 00"); !bytes.Equal(b, want) {
+		t.Errorf("MarshalCER = % X, want % X", b, want)
+	}
+	var got record
+	if err := UnmarshalBER(b, &got); err != nil || got != (record{true, 5}) {
+		t.Errorf("UnmarshalBER(% X) = %+v, %v", b, got, err)
+	}
+}
+
+func TestDecodeBER(t *testing.T) {
+	integer := &Type{Kind: KindInteger}
+	seq := &Type{Kind: KindSequence, Components: []Component{
+		{Name: "a", Type: integer},
+		{Name: "b", Type: &Type{Kind: KindOctetString}, Optional: true},
+	}}
+	tests := []struct {
+		t    *Type
+		data string
+		want interface{}
+	}{
+		{&Type{Kind: KindBoolean}, "01 01 01", true},
+		{integer, "02 02 00 05", int64(5)},
+		{integer, "02 81 01 05", int64(5)},
+		{&Type{Kind: KindOctetString}, "24 80 04 01 AB 04 01 CD 00 00", []byte{0xAB, 0xCD}},
+		{&Type{Kind: KindOctetString}, "24 06 04 01 AB 04 01 CD", []byte{0xAB, 0xCD}},
+		{seq, "30 80 02 01 01 00 00", map[string]interface{}{"a": int64(1)}},
+		{seq, "30 80 02 01 01 04 01 AB 00 00", map[string]interface{}{"a": int64(1), "b": []byte{0xAB}}},
+		{&Type{Kind: KindInteger, Tags: []TypeTag{{Tag: Tag{ClassContextSpecific, 1}, Explicit: true}}}, "A1 80 02 01 05 00 00", int64(5)},
+		{&Type{Kind: KindSetOf, Elem: integer}, "31 06 02 01 02 02 01 01", []interface{}{int64(2), int64(1)}},
+	}
+	for _, tt := range tests {
+		var got interface{}
+		if err := DecodeBER(tt.t, fromHex(tt.data), &got); err != nil {
+			t.Errorf("DecodeBER(%v, %s): %v", tt.t, tt.data, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("DecodeBER(%v, %s) = %#v, want %#v", tt.t, tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeOptions(t *testing.T) {
+	integer := &Type{Kind: KindInteger}
+	octets := &Type{Kind: KindOctetString}
+	seq := &Type{Kind: KindSequence, Components: []Component{{Name: "a", Type: integer}}}
+	tests := []struct {
+		opts DecodeOptions
+		t    *Type
+		data string
+		ok   bool
+	}{
+		{DecodeOptions{}, seq, "30 80 02 01 01 00 00", true},
+		{DecodeOptions{RejectIndefiniteLength: true}, seq, "30 80 02 01 01 00 00", false},
+		{DecodeOptions{RejectIndefiniteLength: true}, seq, "30 03 02 01 01", true},
+		{DecodeOptions{}, octets, "24 03 04 01 AB", true},
+		{DecodeOptions{RejectConstructedStrings: true}, octets, "24 03 04 01 AB", false},
+		{DecodeOptions{RejectConstructedStrings: true}, octets, "04 01 AB", true},
+		{DecodeOptions{RejectNonCanonical: true}, integer, "02 02 00 05", false},
+		{DecodeOptions{RejectNonCanonical: true}, integer, "02 81 01 05", false},
+		{DecodeOptions{RejectNonCanonical: true}, &Type{Kind: KindBoolean}, "01 01 01", false},
+		{DecodeOptions{RejectNonCanonical: true}, &Type{Kind: KindBitString}, "03 02 04 61", false},
+		{DecodeOptions{RejectNonCanonical: true}, &Type{Kind: KindBitString}, "03 02 04 60", true},
+		{DecodeOptions{RejectNonCanonical: true}, seq, "30 80 02 01 01 00 00", true},
+		{DecodeOptions{CheckConstraints: true}, &Type{Kind: KindInteger, Range: &Range{Min: 0, Max: 3}}, "02 01 05", false},
+		{DecodeOptions{CheckConstraints: true}, &Type{Kind: KindInteger, Range: &Range{Min: 0, Max: 3}}, "02 01 03", true},
+	}
+	for _, tt := range tests {
+		var v interface{}
+		err := tt.opts.Decode(tt.t, fromHex(tt.data), &v)
+		if (err == nil) != tt.ok {
+			t.Errorf("%+v.Decode(%v, %s): error %v, want ok %v", tt.opts, tt.t, tt.data, err, tt.ok)
+		}
+	}
+}
+
+func TestUnmarshalBER(t *testing.T) {
+	var s []int
+	if err := UnmarshalBER(fromHex("30 80 02 01 01 02 02 00 02 00 00"), &s); err != nil {
+		t.Fatalf("UnmarshalBER: %v", err)
+	}
+	if !reflect.DeepEqual(s, []int{1, 2}) {
+		t.Errorf("UnmarshalBER = %v, want [1 2]", s)
+	}
+	if err := UnmarshalBER(fromHex("30 80 02 01 01"), &s); err == nil {
+		t.Error("UnmarshalBER of a truncated encoding: no error")
+	}
+	if err := UnmarshalBER(fromHex("02 01 01"), s); err == nil {
+		t.Error("UnmarshalBER into a non-pointer: no error")
+	}
+}
@@ -0,0 +1,165 @@
+package asn1go
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/openesim/asn1go/ast"
+)
+
+// Canonicalize returns the value notation document data in a canonical
+// form, so that documents that differ only in how they are written have
+// the same bytes, to hash or compare them: the values are written as
+// Compact writes them, without comments and with one top-level value per
+// line, hex digits in upper case, integers without leading zeros and
+// cstrings on a single line.
+//
+// Malformed value notation is reported as a SyntaxError.
+func Canonicalize(data []byte) ([]byte, error) {
+	return CanonicalizeSchema(nil, data)
+}
+
+// CanonicalizeSchema is like Canonicalize but also looks up the types of
+// the value assignments in the module schema, to leave out components
+// equal to their DEFAULT values and to sort the components of SET values
+// by their tags, as DER orders them, and the elements of SET OF values by
+// their canonical form.
+func CanonicalizeSchema(schema *Module, data []byte) ([]byte, error) {
+	as, err := parse(data, nil)
+	if err != nil {
+		return nil, err
+	}
+	var text bytes.Buffer
+	for i, a := range as {
+		if i > 0 {
+			text.WriteByte('\n')
+		}
+		var t *Type
+		if a.Name != nil && a.Type != nil {
+			text.WriteString(a.Name.Name + " " + a.Type.Name + " ::= ")
+			if schema != nil {
+				t = schema.Type(a.Type.Name)
+			}
+		}
+		text.WriteString(canonicalValue(t, a.Value))
+	}
+	var buf bytes.Buffer
+	if err := Compact(&buf, text.Bytes()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// canonicalValue returns the canonical form of the value n, of type t if
+// it is known, with spaces between all tokens for Compact to remove.
+func canonicalValue(t *Type, n ast.Node) string {
+	switch n := n.(type) {
+	case *ast.ObjectNode:
+		if t != nil && (t.Kind == KindSequence || t.Kind == KindSet) && isComponents(n) {
+			return "{ " + strings.Join(canonicalComponents(t, n.Elements), ", ") + " }"
+		}
+		var elem *Type
+		if t != nil {
+			elem = t.Elem
+		}
+		els := make([]string, len(n.Elements))
+		for i, el := range n.Elements {
+			els[i] = canonicalValue(elem, el)
+		}
+		if t != nil && t.Kind == KindSetOf {
+			sort.Strings(els)
+		}
+		return "{ " + strings.Join(els, ", ") + " }"
+	case *ast.FieldNode:
+		return n.Name.Name + " " + canonicalValue(t, n.Value)
+	case *ast.ChoiceNode:
+		return n.Name.Name + " : " + canonicalValue(componentType(t, n.Name.Name), n.Value)
+	case *ast.ContainingNode:
+		var ct *Type
+		if t != nil {
+			ct = t.Contains
+		}
+		return containingKeyword + " " + canonicalValue(ct, n.Value)
+	case *ast.OIDNode:
+		comps := make([]string, len(n.Components))
+		for i, c := range n.Components {
+			comps[i] = canonicalValue(nil, c)
+		}
+		return "{ " + strings.Join(comps, " ") + " }"
+	case *ast.Ident:
+		return n.Name
+	case *ast.NullNode:
+		return "NULL"
+	case *ast.BoolNode:
+		if n.Value {
+			return "TRUE"
+		}
+		return "FALSE"
+	case *ast.IntNode:
+		if i, ok := new(big.Int).SetString(n.Text, 10); ok {
+			return i.String()
+		}
+		return n.Text
+	case *ast.RealNode:
+		return n.Text
+	case *ast.HexNode:
+		return "'" + strings.ToUpper(n.Digits) + "'H"
+	case *ast.BitsNode:
+		return "'" + n.Digits + "'B"
+	case *ast.StringNode:
+		return `"` + strings.ReplaceAll(n.Value, `"`, `""`) + `"`
+	}
+	return ""
+}
+
+// canonicalComponents returns the canonical forms of the components els
+// of a value of the SEQUENCE or SET type t, without those equal to their
+// DEFAULT values, and in the order of their tags for a SET.
+func canonicalComponents(t *Type, els []ast.Node) []string {
+	type component struct {
+		c    *Component
+		text string
+	}
+	var comps []component
+	for _, el := range els {
+		f := el.(*ast.FieldNode)
+		c := t.Component(f.Name.Name)
+		var ct *Type
+		if c != nil {
+			ct = c.Type
+		}
+		value := canonicalValue(ct, f.Value)
+		if c != nil && c.Default != nil && isDefault(c, value) {
+			continue
+		}
+		comps = append(comps, component{c, f.Name.Name + " " + value})
+	}
+	if t.Kind == KindSet {
+		// Components the type does not know go last.
+		sort.SliceStable(comps, func(i, j int) bool {
+			ci, cj := comps[i].c, comps[j].c
+			if ci == nil || cj == nil {
+				return ci != nil && cj == nil
+			}
+			return canonicalTag(ci.Type).less(canonicalTag(cj.Type))
+		})
+	}
+	texts := make([]string, len(comps))
+	for i, c := range comps {
+		texts[i] = c.text
+	}
+	return texts
+}
+
+// isDefault reports whether value, in value notation, is the DEFAULT
+// value of the component c.
+func isDefault(c *Component, value string) bool {
+	var v interface{}
+	if err := Unmarshal([]byte(value), &v); err != nil {
+		return false
+	}
+	return equalValue(c.Type, reflect.ValueOf(v), reflect.ValueOf(c.Default))
+}
@@ -0,0 +1,65 @@
+package asn1go
+
+import "testing"
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"v T ::= { a 'ab'H, -- c\n b 007, s \"x\ny\" }", `v T::={a 'AB'H,b 7,s "xy"}`},
+		{"5  6", "5\n6"},
+		{"{ a 1 }\n\n-- end\n", "{a 1}"},
+		{"v T ::= alt : { x '0101'B }", "v T::=alt:{x '0101'B}"},
+		{"v T ::= { 2 23 143 }", "v T::={2 23 143}"},
+		{"v T ::= -0", "v T::=0"},
+		{"v T ::= { y 1, z 2 }", "v T::={y 1,z 2}"},
+	}
+	for _, tt := range tests {
+		got, err := Canonicalize([]byte(tt.in))
+		if err != nil {
+			t.Errorf("Canonicalize(%q): %v", tt.in, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("Canonicalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	if _, err := Canonicalize([]byte("{")); err == nil {
+		t.Error("Canonicalize of invalid data: no error")
+	} else if _, ok := err.(*SyntaxError); !ok {
+		t.Errorf("Canonicalize of invalid data: error %v, want SyntaxError", err)
+	}
+}
+
+func TestCanonicalizeSchema(t *testing.T) {
+	m, err := ParseModule([]byte(`M DEFINITIONS AUTOMATIC TAGS ::= BEGIN
+S ::= SET { z INTEGER, y BOOLEAN }
+D ::= SEQUENCE { a INTEGER DEFAULT 3, b BOOLEAN DEFAULT TRUE, c OCTET STRING }
+O ::= SET OF OCTET STRING
+N ::= SEQUENCE { s S, o O }
+END`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		in, want string
+	}{
+		{"v S ::= { y TRUE, z 1 }", "v S::={z 1,y TRUE}"},
+		{"v S ::= { z 1, y TRUE }", "v S::={z 1,y TRUE}"},
+		{"v D ::= { a 3, b TRUE, c 'AB'H }", "v D::={c 'AB'H}"},
+		{"v D ::= { a 4, c 'ab'H }", "v D::={a 4,c 'AB'H}"},
+		{"v O ::= { 'BB'H, 'AA'H, '0A'H }", "v O::={'0A'H,'AA'H,'BB'H}"},
+		{"v N ::= { s { y FALSE, z 2 }, o { 'FF'H, '00'H } }", "v N::={s{z 2,y FALSE},o{'00'H,'FF'H}}"},
+		{"v X ::= { y 1, z 2 }", "v X::={y 1,z 2}"},
+	}
+	for _, tt := range tests {
+		got, err := CanonicalizeSchema(m, []byte(tt.in))
+		if err != nil {
+			t.Errorf("CanonicalizeSchema(%q): %v", tt.in, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("CanonicalizeSchema(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
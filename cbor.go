@@ -0,0 +1,511 @@
+package asn1go
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/openesim/asn1go/ast"
+)
+
+// The CBOR tags of ToCBOR for the values that CBOR does not tell apart
+// from text strings, arrays and maps.
+const (
+	CBORTagOID    = 40100 // OBJECT IDENTIFIER value, an array of its components
+	CBORTagChoice = 40101 // CHOICE value, a map of a single pair
+	CBORTagIdent  = 40102 // identifier, a text string
+	CBORTagBits   = 40103 // bstring, a text string of binary digits
+)
+
+// ToCBOR converts the ASN.1 value notation document data to CBOR (RFC
+// 8949), without a schema, the way ToJSON converts it to JSON. The values
+// become:
+//
+//	SEQUENCE, SET     a map with a text string key per component, in order
+//	SEQUENCE OF, SET OF, { }
+//	                  an array
+//	CHOICE            a map of a single pair, tagged CBORTagChoice
+//	BOOLEAN, NULL     true, false and null
+//	INTEGER           an integer, or a bignum beyond 64 bits
+//	REAL              a double-precision float
+//	hstring           a byte string, or a bstring if it has an odd number
+//	                  of hexadecimal digits
+//	bstring           a text string of binary digits, tagged CBORTagBits
+//	cstring           a text string
+//	identifier        a text string, tagged CBORTagIdent
+//	OBJECT IDENTIFIER an array of the components, integers or the text
+//	                  strings of components given by name, tagged
+//	                  CBORTagOID
+//
+// so that FromCBOR needs no hints but the types of the value assignments,
+// which ToCBOR drops along with the comments.
+func ToCBOR(data []byte) ([]byte, error) {
+	as, err := ParseAssignments(data)
+	if err != nil {
+		return nil, err
+	}
+	unwrapContaining(as)
+	named, err := namedAssignments(as, "ToCBOR")
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	switch {
+	case named:
+		cborHead(&buf, 5, uint64(len(as)))
+		for _, a := range as {
+			cborText(&buf, a.Name.Name)
+			nodeCBOR(&buf, a.Value)
+		}
+	case len(as) == 1:
+		nodeCBOR(&buf, as[0].Value)
+	default:
+		cborHead(&buf, 4, uint64(len(as)))
+		for _, a := range as {
+			nodeCBOR(&buf, a.Value)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// nodeCBOR writes the CBOR form of the value n to buf.
+func nodeCBOR(buf *bytes.Buffer, n ast.Node) {
+	switch n := n.(type) {
+	case *ast.ObjectNode:
+		fields, ok := componentsOf(n)
+		if !ok {
+			cborHead(buf, 4, uint64(len(n.Elements)))
+			for _, el := range n.Elements {
+				nodeCBOR(buf, el)
+			}
+			return
+		}
+		cborHead(buf, 5, uint64(len(fields)))
+		for _, f := range fields {
+			cborText(buf, f.Name.Name)
+			nodeCBOR(buf, f.Value)
+		}
+	case *ast.FieldNode:
+		// A component among elements without identifiers, which is an
+		// untagged map of a single pair, as in ToJSON.
+		cborHead(buf, 5, 1)
+		cborText(buf, n.Name.Name)
+		nodeCBOR(buf, n.Value)
+	case *ast.ChoiceNode:
+		cborHead(buf, 6, CBORTagChoice)
+		cborHead(buf, 5, 1)
+		cborText(buf, n.Name.Name)
+		nodeCBOR(buf, n.Value)
+	case *ast.OIDNode:
+		cborHead(buf, 6, CBORTagOID)
+		cborHead(buf, 4, uint64(len(n.Components)))
+		for _, c := range n.Components {
+			if id, ok := c.(*ast.Ident); ok {
+				cborText(buf, id.Name)
+			} else {
+				nodeCBOR(buf, c)
+			}
+		}
+	case *ast.NullNode:
+		buf.WriteByte(0xf6)
+	case *ast.BoolNode:
+		if n.Value {
+			buf.WriteByte(0xf5)
+		} else {
+			buf.WriteByte(0xf4)
+		}
+	case *ast.IntNode:
+		i, _ := new(big.Int).SetString(n.Text, 10)
+		cborInt(buf, i)
+	case *ast.RealNode:
+		f, _ := strconv.ParseFloat(n.Text, 64)
+		buf.WriteByte(0xfb)
+		binary.Write(buf, binary.BigEndian, f)
+	case *ast.HexNode:
+		if len(n.Digits)%2 == 0 {
+			b, _ := n.Bytes()
+			cborHead(buf, 2, uint64(len(b)))
+			buf.Write(b)
+			return
+		}
+		var bits strings.Builder
+		for _, d := range n.Digits {
+			v, _ := strconv.ParseUint(string(d), 16, 8)
+			bits.WriteString(strconv.FormatUint(v|0x10, 2)[1:])
+		}
+		cborHead(buf, 6, CBORTagBits)
+		cborText(buf, bits.String())
+	case *ast.BitsNode:
+		cborHead(buf, 6, CBORTagBits)
+		cborText(buf, n.Digits)
+	case *ast.StringNode:
+		cborText(buf, n.Value)
+	case *ast.Ident:
+		cborHead(buf, 6, CBORTagIdent)
+		cborText(buf, n.Name)
+	}
+}
+
+// cborHead writes the initial byte of a data item of the major type major
+// and the argument n, in the fewest bytes.
+func cborHead(buf *bytes.Buffer, major byte, n uint64) {
+	major <<= 5
+	switch {
+	case n < 24:
+		buf.WriteByte(major | byte(n))
+	case n <= math.MaxUint8:
+		buf.Write([]byte{major | 24, byte(n)})
+	case n <= math.MaxUint16:
+		buf.WriteByte(major | 25)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	case n <= math.MaxUint32:
+		buf.WriteByte(major | 26)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	default:
+		buf.WriteByte(major | 27)
+		binary.Write(buf, binary.BigEndian, n)
+	}
+}
+
+// cborText writes the text string s. CBOR text strings must be valid
+// UTF-8, so each byte of s that is not is replaced by U+FFFD, as ToJSON
+// does.
+func cborText(buf *bytes.Buffer, s string) {
+	if !utf8.ValidString(s) {
+		var b strings.Builder
+		for _, r := range s {
+			b.WriteRune(r)
+		}
+		s = b.String()
+	}
+	cborHead(buf, 3, uint64(len(s)))
+	buf.WriteString(s)
+}
+
+// cborInt writes the integer i, as a bignum (tag 2 or 3) if it does not
+// fit in 64 bits.
+func cborInt(buf *bytes.Buffer, i *big.Int) {
+	major, tag := byte(0), uint64(2)
+	if i.Sign() < 0 {
+		// Negative integers are encoded as -1 - n.
+		i = new(big.Int).Sub(new(big.Int).Neg(i), big.NewInt(1))
+		major, tag = 1, 3
+	}
+	if i.IsUint64() {
+		cborHead(buf, major, i.Uint64())
+		return
+	}
+	cborHead(buf, 6, tag)
+	b := i.Bytes()
+	cborHead(buf, 2, uint64(len(b)))
+	buf.Write(b)
+}
+
+// FromCBOR converts the CBOR cborData, such as that returned by ToCBOR,
+// to ASN.1 value notation the way FromJSON converts JSON. The tags of
+// ToCBOR give the kinds of the values they are on, and hints the types of
+// value assignments and the kinds of untagged text strings; hints may be
+// nil. Byte strings become hstrings and floats REAL values; tags other
+// than those of ToCBOR and the bignum tags 2 and 3 are ignored. The keys
+// of maps must be text strings.
+func FromCBOR(cborData []byte, hints *JSONHints) ([]byte, error) {
+	if hints == nil {
+		hints = new(JSONHints)
+	}
+	d := &cborDecoder{data: cborData}
+	v, err := d.item()
+	if err != nil {
+		return nil, err
+	}
+	if d.off < len(d.data) {
+		return nil, d.errorf("data after the top-level item")
+	}
+	return marshalGeneric(v, hints)
+}
+
+// A CBORSyntaxError describes malformed CBOR input of FromCBOR.
+type CBORSyntaxError struct {
+	Msg    string
+	Offset int64 // error occurred after reading Offset bytes
+}
+
+func (e *CBORSyntaxError) Error() string {
+	return "asn1go: CBOR syntax error at offset " + strconv.FormatInt(e.Offset, 10) + ": " + e.Msg
+}
+
+// A cborDecoder reads CBOR into the values that readJSON returns, and
+// taggedValue values.
+type cborDecoder struct {
+	data []byte
+	off  int
+}
+
+func (d *cborDecoder) errorf(msg string) error {
+	return &CBORSyntaxError{Msg: msg, Offset: int64(d.off)}
+}
+
+// cborBreak is the value of the break stop code of indefinite-length
+// items.
+type cborBreak struct{}
+
+// head reads the initial byte of a data item and its argument; indefinite
+// reports a data item of indefinite length.
+func (d *cborDecoder) head() (major byte, info byte, n uint64, indefinite bool, err error) {
+	if d.off == len(d.data) {
+		return 0, 0, 0, false, d.errorf("unexpected end of data")
+	}
+	b := d.data[d.off]
+	d.off++
+	major, info = b>>5, b&0x1f
+	switch {
+	case info < 24:
+		return major, info, uint64(info), false, nil
+	case info <= 27:
+		size := 1 << (info - 24)
+		if len(d.data)-d.off < size {
+			return 0, 0, 0, false, d.errorf("unexpected end of data")
+		}
+		for _, c := range d.data[d.off : d.off+size] {
+			n = n<<8 | uint64(c)
+		}
+		d.off += size
+		return major, info, n, false, nil
+	case info == 31 && major >= 2 && major != 6:
+		return major, info, 0, true, nil
+	}
+	return 0, 0, 0, false, d.errorf("invalid additional information " + strconv.Itoa(int(info)))
+}
+
+func (d *cborDecoder) value() (interface{}, error) {
+	start := d.off
+	major, info, n, indefinite, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case 0:
+		return json.Number(strconv.FormatUint(n, 10)), nil
+	case 1:
+		i := new(big.Int).SetUint64(n)
+		return json.Number(i.Sub(i.Neg(i), big.NewInt(1)).String()), nil
+	case 2, 3:
+		s, err := d.str(major, n, indefinite)
+		if err != nil {
+			return nil, err
+		}
+		if major == 2 {
+			return taggedValue{JSONHex, strings.ToUpper(hex.EncodeToString([]byte(s)))}, nil
+		}
+		if !utf8.ValidString(s) {
+			d.off = start
+			return nil, d.errorf("text string is not valid UTF-8")
+		}
+		return s, nil
+	case 4:
+		list := []interface{}{}
+		for i := uint64(0); indefinite || i < n; i++ {
+			el, err := d.next(indefinite)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := el.(cborBreak); ok {
+				break
+			}
+			list = append(list, el)
+		}
+		return list, nil
+	case 5:
+		obj := jsonObject{}
+		for i := uint64(0); indefinite || i < n; i++ {
+			keyOff := d.off
+			key, err := d.next(indefinite)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := key.(cborBreak); ok {
+				break
+			}
+			name, ok := key.(string)
+			if !ok {
+				d.off = keyOff
+				return nil, d.errorf("map key is not a text string")
+			}
+			el, err := d.item()
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, jsonMember{name, el})
+		}
+		return obj, nil
+	case 6:
+		return d.tagged(n)
+	}
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23: // null and undefined
+		return nil, nil
+	case 25:
+		return cborFloat(float64(float16(uint16(n)))), nil
+	case 26:
+		return cborFloat(float64(math.Float32frombits(uint32(n)))), nil
+	case 27:
+		return cborFloat(math.Float64frombits(n)), nil
+	case 31:
+		return cborBreak{}, nil
+	}
+	d.off = start
+	return nil, d.errorf("unsupported simple value " + strconv.FormatUint(n, 10))
+}
+
+// item reads a data item that is not a break stop code.
+func (d *cborDecoder) item() (interface{}, error) {
+	return d.next(false)
+}
+
+// next reads the next data item, which may be the break stop code of an
+// item of indefinite length if indefinite.
+func (d *cborDecoder) next(indefinite bool) (interface{}, error) {
+	start := d.off
+	v, err := d.value()
+	if _, ok := v.(cborBreak); ok && !indefinite {
+		d.off = start
+		return nil, d.errorf("unexpected break stop code")
+	}
+	return v, err
+}
+
+// str reads the content of a byte or text string of n bytes, or of
+// indefinite length.
+func (d *cborDecoder) str(major byte, n uint64, indefinite bool) (string, error) {
+	if !indefinite {
+		if uint64(len(d.data)-d.off) < n {
+			return "", d.errorf("unexpected end of data")
+		}
+		s := string(d.data[d.off : d.off+int(n)])
+		d.off += int(n)
+		return s, nil
+	}
+	var b strings.Builder
+	for {
+		m, info, n, indefinite, err := d.head()
+		if err != nil {
+			return "", err
+		}
+		if m == 7 && info == 31 {
+			return b.String(), nil
+		}
+		if m != major || indefinite {
+			return "", d.errorf("invalid chunk of indefinite-length string")
+		}
+		s, err := d.str(major, n, false)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(s)
+	}
+}
+
+// tagged reads the content of a data item tagged tag.
+func (d *cborDecoder) tagged(tag uint64) (interface{}, error) {
+	start := d.off
+	v, err := d.item()
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case 2, 3:
+		t, ok := v.(taggedValue)
+		if !ok || t.kind != JSONHex {
+			break
+		}
+		b, _ := hex.DecodeString(t.value.(string))
+		i := new(big.Int).SetBytes(b)
+		if tag == 3 {
+			i.Sub(i.Neg(i), big.NewInt(1))
+		}
+		return json.Number(i.String()), nil
+	case CBORTagOID:
+		list, ok := v.([]interface{})
+		if !ok {
+			break
+		}
+		parts := make([]string, len(list))
+		for i, c := range list {
+			switch c := c.(type) {
+			case json.Number:
+				parts[i] = c.String()
+			case string:
+				parts[i] = c
+			default:
+				d.off = start
+				return nil, d.errorf("invalid component of OBJECT IDENTIFIER value")
+			}
+		}
+		return taggedValue{JSONOID, strings.Join(parts, ".")}, nil
+	case CBORTagChoice:
+		if obj, ok := v.(jsonObject); ok && len(obj) == 1 {
+			return taggedValue{JSONChoice, obj}, nil
+		}
+	case CBORTagIdent:
+		if _, ok := v.(string); ok {
+			return taggedValue{JSONIdent, v}, nil
+		}
+	case CBORTagBits:
+		if _, ok := v.(string); ok {
+			return taggedValue{JSONBits, v}, nil
+		}
+	default:
+		return v, nil
+	}
+	d.off = start
+	return nil, d.errorf("invalid content of tag " + strconv.FormatUint(tag, 10))
+}
+
+// cborFloat returns the value of the float f: a REAL number, or the
+// identifier of a special REAL value.
+func cborFloat(f float64) interface{} {
+	switch {
+	case math.IsNaN(f):
+		return taggedValue{JSONIdent, "NOT-A-NUMBER"}
+	case math.IsInf(f, 1):
+		return taggedValue{JSONIdent, "PLUS-INFINITY"}
+	case math.IsInf(f, -1):
+		return taggedValue{JSONIdent, "MINUS-INFINITY"}
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		// Keep it a REAL value rather than an INTEGER value.
+		s += ".0"
+	}
+	return json.Number(s)
+}
+
+// float16 returns the value of the IEEE 754 half-precision float h.
+func float16(h uint16) float64 {
+	exp, frac := int(h>>10&0x1f), float64(h&0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(frac, -24)
+	case 0x1f:
+		f = math.Inf(1)
+		if frac != 0 {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(frac+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
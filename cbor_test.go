@@ -0,0 +1,31 @@
+package asn1go
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestToCBOR(t *testing.T) {
+	tests := []struct {
+		name, in, want string // want in hexadecimal
+	}{
+		{"sequence", "v T ::= { a 1, b TRUE }", "a16176a26161016162f5"},
+		{"list", "v T ::= { 1, 2 }", "a16176820102"},
+		{"empty", "v T ::= { }", "a1617680"},
+		{"choice", "v T ::= alt : 'AB'H", "a16176d99ca5a163616c7441ab"},
+		{"component then value", "v T ::= { a 1, { b 2 } }", "a1617682a1616101a1616202"},
+		{"component then identifier", "v T ::= { a 1, b }", "a1617682a1616101d99ca66162"},
+		{"invalid UTF-8", "v T ::= \"a\xa2\"", "a161766461efbfbd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToCBOR([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("ToCBOR(%q): %v", tt.in, err)
+			}
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("ToCBOR(%q) = %x, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
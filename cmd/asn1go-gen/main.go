@@ -0,0 +1,65 @@
+// Command asn1go-gen generates Go types for the types of an ASN.1 module.
+//
+// Usage:
+//
+//	asn1go-gen [-pkg name] [-o file] module.asn
+//
+// It parses the module with asn1go.ParseModule and writes the declarations
+// asn1go.GenerateGo returns to the file given by -o, or to the standard
+// output. The package defaults to $GOPACKAGE, so that a go:generate
+// directive such as
+//
+//	//go:generate asn1go-gen -o pe.go PEDefinitions.asn
+//
+// generates the types into the package of the file it is in.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/openesim/asn1go"
+)
+
+func main() {
+	pkg := flag.String("pkg", os.Getenv("GOPACKAGE"), "package `name` of the generated file")
+	out := flag.String("o", "", "write the generated code to `file` instead of standard output")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: asn1go-gen [-pkg name] [-o file] module.asn\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *pkg == "" {
+		fatalf("no package name: use -pkg or run from go generate")
+	}
+
+	src, err := os.ReadFile(flag.Arg(0))
+	if err != nil {
+		fatalf("%v", err)
+	}
+	m, err := asn1go.ParseModule(src)
+	if err != nil {
+		fatalf("%s: %v", flag.Arg(0), err)
+	}
+	code, err := asn1go.GenerateGo(m, *pkg)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	if *out == "" {
+		os.Stdout.Write(code)
+		return
+	}
+	if err := os.WriteFile(*out, code, 0o666); err != nil {
+		fatalf("%v", err)
+	}
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "asn1go-gen: "+format+"\n", args...)
+	os.Exit(1)
+}
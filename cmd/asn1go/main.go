@@ -0,0 +1,617 @@
+// Command asn1go formats, validates and converts ASN.1 value notation,
+// such as the profile packages of SGP.22 in their text form.
+//
+// Usage:
+//
+//	asn1go fmt [-compact | -canonical] [-schema module.asn] [-w] [-json] [file ...]
+//	asn1go validate [-schema module.asn] [-json] [file ...]
+//	asn1go convert -from format -to format [-schema module.asn] [-type name] [-json] [file]
+//	asn1go get [-json] path [file]
+//	asn1go set [-w] [-json] path value [file]
+//	asn1go dump [-json] [file ...]
+//	asn1go bench [-save results.json] [-baseline results.json] [-tolerance fraction] [file ...]
+//
+// Each command reads the files it is given, or the standard input if it
+// is given none or "-", and writes to the standard output.
+//
+// The fmt command writes the value notation indented as
+// asn1go.MarshalIndent writes a syntax tree, with two spaces per level and
+// the comments of the file, or with -compact as asn1go.Compact writes it,
+// without comments, or with -canonical in the canonical form of
+// asn1go.CanonicalizeSchema, using the types of the -schema module if it
+// is given. With -w it rewrites the files in place instead.
+//
+// The validate command checks the syntax of the value notation, reporting
+// all the syntax errors it finds, and with -schema also checks the values
+// against the types of the module, as asn1go.Validate does.
+//
+// The convert command converts a single file between the formats text,
+// value notation; der, the DER encoding of its values one after the
+// other; and json, the plain JSON of asn1go.ToJSON. Converting to or from
+// der needs the -schema module and the -type of the values, except that
+// der converts to text without -type as asn1go.DERToTextSchema does: each
+// value as the first type of the -schema module it matches, and a value no
+// type matches, or any value without -schema, as a dump of its tags and
+// lengths in comments.
+//
+// The get command writes the values at path, one per line, as they are
+// written in the file. The path is either a path of an asn1go.Document,
+// such as value4.genericFileManagement.fileManagementCMD[0][1], or a query
+// expression of asn1go.Query, beginning with $, such as $..iccid. The set
+// command replaces the value at the Document path with value, in value
+// notation, keeping the comments and layout of the rest of the file, and
+// writes the file, or with -w rewrites it in place:
+//
+//	asn1go set -w value1.header.iccid "'89019990001234567893'H" profile.txt
+//
+// The dump command writes BER or DER encoded files in the pseudo value
+// notation of asn1go.DumpDER, with the tag, offset and length of each
+// encoding, without a schema.
+//
+// The bench command measures how fast asn1go scans, decodes and encodes
+// the synthetic profile packages of the bench package and the profile
+// packages in value notation of the files it is given, if any, and writes
+// the results in the format of go test -bench. With -save it also writes
+// them as JSON to a file, a baseline that a later run with -baseline
+// compares its results with: a time, allocation count or allocated bytes
+// per operation that grew by more than the -tolerance fraction, 0.1 by
+// default, is a regression, which fails the command.
+//
+// Errors are written to the standard error as file:line:column: message,
+// or with -json as JSON objects, one per line, for tools to read:
+//
+//	{"file":"profile.txt","line":12,"column":5,"offset":301,"path":"value1.header","message":"..."}
+//
+// The line and column, counted from 1, and the path of a value that is not
+// valid are left out when they are not known; the offset of an error in
+// DER input is that of the byte in the input. The exit status is 1 if
+// an input has errors and 2 for a usage error.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/openesim/asn1go"
+	"github.com/openesim/asn1go/ast"
+	"github.com/openesim/asn1go/bench"
+)
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, args := flag.Arg(0), flag.Args()[1:]
+	var ok bool
+	switch cmd {
+	case "fmt":
+		ok = fmtCmd(args)
+	case "validate":
+		ok = validateCmd(args)
+	case "convert":
+		ok = convertCmd(args)
+	case "get":
+		ok = getCmd(args)
+	case "set":
+		ok = setCmd(args)
+	case "dump":
+		ok = dumpCmd(args)
+	case "bench":
+		ok = benchCmd(args)
+	default:
+		fmt.Fprintf(os.Stderr, "asn1go: unknown command %q\n", cmd)
+		usage()
+		os.Exit(2)
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `usage: asn1go fmt [-compact | -canonical] [-schema module.asn] [-w] [-json] [file ...]
+       asn1go validate [-schema module.asn] [-json] [file ...]
+       asn1go convert -from format -to format [-schema module.asn] [-type name] [-json] [file]
+       asn1go get [-json] path [file]
+       asn1go set [-w] [-json] path value [file]
+       asn1go dump [-json] [file ...]
+       asn1go bench [-save results.json] [-baseline results.json] [-tolerance fraction] [file ...]
+formats: text, der, json
+`)
+}
+
+// A command holds the flags all commands share and reports their errors.
+type command struct {
+	flags      *flag.FlagSet
+	schemaFile string
+	jsonErrors bool
+	failed     bool
+}
+
+func newCommand(name string) *command {
+	c := &command{flags: flag.NewFlagSet(name, flag.ExitOnError)}
+	c.flags.StringVar(&c.schemaFile, "schema", "", "ASN.1 `module` with the types of the values")
+	c.flags.BoolVar(&c.jsonErrors, "json", false, "write errors as JSON objects")
+	c.flags.Usage = func() {
+		usage()
+		c.flags.PrintDefaults()
+	}
+	return c
+}
+
+// schema returns the module of the -schema flag, or nil if it is not set.
+func (c *command) schema() *asn1go.Module {
+	if c.schemaFile == "" {
+		return nil
+	}
+	src, err := os.ReadFile(c.schemaFile)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	m, err := asn1go.ParseModule(src)
+	if err != nil {
+		fatalf("%s: %v", c.schemaFile, err)
+	}
+	return m
+}
+
+// files returns the file arguments after the first skip arguments, or "-"
+// for the standard input.
+func (c *command) files(skip int) []string {
+	if c.flags.NArg() <= skip {
+		return []string{"-"}
+	}
+	return c.flags.Args()[skip:]
+}
+
+// read returns the contents of the file name, or of the standard input for
+// "-", reporting false if it cannot be read.
+func (c *command) read(name string) ([]byte, bool) {
+	var data []byte
+	var err error
+	if name == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		c.report(name, nil, err)
+		return nil, false
+	}
+	return data, true
+}
+
+// An errorReport is an error as -json writes it.
+type errorReport struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Offset  *int64 `json:"offset,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// report writes the error err found in the input data of the file name;
+// data is nil if the input is not value notation.
+func (c *command) report(name string, data []byte, err error) {
+	c.failed = true
+	var errs asn1go.SyntaxErrors
+	if errors.As(err, &errs) {
+		for _, e := range errs {
+			c.report(name, data, e)
+		}
+		return
+	}
+
+	r := errorReport{File: name, Message: err.Error()}
+	if name == "-" {
+		r.File = "<stdin>"
+	}
+	var syntaxErr *asn1go.SyntaxError
+	var validationErr *asn1go.ValidationError
+	var derErr *asn1go.DERSyntaxError
+	var pathErr *asn1go.PathError
+	switch {
+	case errors.As(err, &syntaxErr):
+		r.Offset = &syntaxErr.Offset
+	case errors.As(err, &validationErr):
+		r.Offset = &validationErr.Offset
+		r.Path = validationErr.Path
+	case errors.As(err, &derErr):
+		r.Offset = &derErr.Offset
+	case errors.As(err, &pathErr):
+		r.Path = pathErr.Path
+	}
+	if r.Offset != nil && data != nil {
+		r.Line, r.Column = position(data, *r.Offset)
+	}
+
+	if c.jsonErrors {
+		enc := json.NewEncoder(os.Stderr)
+		enc.SetEscapeHTML(false)
+		enc.Encode(r)
+		return
+	}
+	pos := r.File
+	if r.Line > 0 {
+		pos += fmt.Sprintf(":%d:%d", r.Line, r.Column)
+	}
+	fmt.Fprintf(os.Stderr, "%s: %s\n", pos, r.Message)
+}
+
+// position returns the line and column, counted from 1, of the byte of
+// data that an error after reading offset bytes is at.
+func position(data []byte, offset int64) (line, column int) {
+	i := int(offset) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i > len(data) {
+		i = len(data)
+	}
+	line = 1 + bytes.Count(data[:i], []byte("\n"))
+	column = i - bytes.LastIndexByte(data[:i], '\n')
+	return line, column
+}
+
+func fmtCmd(args []string) bool {
+	c := newCommand("fmt")
+	compact := c.flags.Bool("compact", false, "write the values without space or comments")
+	canonical := c.flags.Bool("canonical", false, "write the values in canonical form")
+	write := c.flags.Bool("w", false, "rewrite the files instead of writing to standard output")
+	c.flags.Parse(args)
+	if *compact && *canonical {
+		fatalf("fmt: -compact and -canonical are exclusive")
+	}
+	schema := c.schema()
+
+	for _, name := range c.files(0) {
+		data, ok := c.read(name)
+		if !ok {
+			continue
+		}
+		var buf bytes.Buffer
+		var err error
+		switch {
+		case *canonical:
+			var b []byte
+			b, err = asn1go.CanonicalizeSchema(schema, data)
+			buf.Write(b)
+		case *compact:
+			err = asn1go.Compact(&buf, data)
+		default:
+			var b []byte
+			b, err = indent(data)
+			buf.Write(b)
+		}
+		if err != nil {
+			c.report(name, data, err)
+			continue
+		}
+		buf.WriteByte('\n')
+		if *write && name != "-" {
+			if err := os.WriteFile(name, buf.Bytes(), 0o666); err != nil {
+				c.report(name, nil, err)
+			}
+			continue
+		}
+		os.Stdout.Write(buf.Bytes())
+	}
+	return !c.failed
+}
+
+// indent returns the value notation data indented with two spaces per
+// level. Unlike asn1go.Indent, it writes the syntax tree of data back with
+// asn1go.MarshalIndent, which keeps the comments.
+func indent(data []byte) ([]byte, error) {
+	as, err := asn1go.ParseAssignments(data)
+	if err != nil {
+		return nil, err
+	}
+	return asn1go.MarshalIndent(as, "", "  ")
+}
+
+// maxErrors is the number of syntax errors validate reports per file.
+const maxErrors = 100
+
+func validateCmd(args []string) bool {
+	c := newCommand("validate")
+	c.flags.Parse(args)
+	schema := c.schema()
+
+	for _, name := range c.files(0) {
+		data, ok := c.read(name)
+		if !ok {
+			continue
+		}
+		dec := asn1go.NewDecoder(bytes.NewReader(data))
+		dec.CollectErrors(maxErrors)
+		valid := true
+		for {
+			var v asn1go.RawValue
+			err := dec.Decode(&v)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				c.report(name, data, err)
+				valid = false
+				break
+			}
+		}
+		if valid && schema != nil {
+			if err := asn1go.Validate(schema, data); err != nil {
+				c.report(name, data, err)
+			}
+		}
+	}
+	return !c.failed
+}
+
+func convertCmd(args []string) bool {
+	c := newCommand("convert")
+	from := c.flags.String("from", "text", "`format` of the input: text, der or json")
+	to := c.flags.String("to", "text", "`format` of the output: text, der or json")
+	typeName := c.flags.String("type", "", "ASN.1 type `name` of the values, for der")
+	c.flags.Parse(args)
+	for _, f := range []string{*from, *to} {
+		if f != "text" && f != "der" && f != "json" {
+			fatalf("convert: unknown format %q", f)
+		}
+	}
+	if c.flags.NArg() > 1 {
+		fatalf("convert: more than one file")
+	}
+	var t *asn1go.Type
+	schema := c.schema()
+	decompile := *from == "der" && *to == "text" && *typeName == ""
+	if (*from == "der" || *to == "der") && !decompile {
+		if schema == nil || *typeName == "" {
+			fatalf("convert: der needs -schema and -type")
+		}
+		if t = schema.Type(*typeName); t == nil {
+			fatalf("convert: no type %s in %s", *typeName, c.schemaFile)
+		}
+	}
+
+	name := c.files(0)[0]
+	data, ok := c.read(name)
+	if !ok {
+		return false
+	}
+	if decompile {
+		// Comments carry the values no type matches, which Indent
+		// would drop.
+		var out bytes.Buffer
+		if err := asn1go.DERToTextSchema(&out, schema, data); err != nil {
+			c.report(name, data, err)
+			return false
+		}
+		os.Stdout.Write(out.Bytes())
+		return true
+	}
+
+	// Convert the input to value notation, and the value notation to the
+	// output.
+	text := data
+	var err error
+	switch *from {
+	case "der":
+		var buf bytes.Buffer
+		err = asn1go.DERToTextIndent(&buf, t, data, "", "  ")
+		text = buf.Bytes()
+	case "json":
+		text, err = asn1go.FromJSON(data, nil)
+	}
+	if err != nil {
+		if *from == "json" {
+			data = nil
+		}
+		c.report(name, data, err)
+		return false
+	}
+	if *from != "text" {
+		// Errors are reported in the value notation converted to.
+		name, data = name+" (as text)", text
+	}
+
+	var out bytes.Buffer
+	switch *to {
+	case "text":
+		if err = asn1go.Indent(&out, text, "", "  "); err == nil {
+			out.WriteByte('\n')
+		}
+	case "der":
+		err = asn1go.TextToDER(&out, t, text)
+	case "json":
+		var b []byte
+		if b, err = asn1go.ToJSON(text); err == nil {
+			out.Write(append(b, '\n'))
+		}
+	}
+	if err != nil {
+		c.report(name, data, err)
+		return false
+	}
+	os.Stdout.Write(out.Bytes())
+	return true
+}
+
+func dumpCmd(args []string) bool {
+	c := newCommand("dump")
+	c.flags.Parse(args)
+	for _, name := range c.files(0) {
+		data, ok := c.read(name)
+		if !ok {
+			continue
+		}
+		// The encodings before an error are written all the same.
+		var buf bytes.Buffer
+		err := asn1go.DumpDER(&buf, data)
+		os.Stdout.Write(buf.Bytes())
+		if err != nil {
+			c.report(name, data, err)
+		}
+	}
+	return !c.failed
+}
+
+func benchCmd(args []string) bool {
+	c := newCommand("bench")
+	save := c.flags.String("save", "", "write the results as JSON to `file`")
+	baselineFile := c.flags.String("baseline", "", "compare the results with those saved in `file`")
+	tolerance := c.flags.Float64("tolerance", 0.1, "`fraction` by which a result may exceed its baseline")
+	c.flags.Parse(args)
+
+	var baseline []bench.Result
+	if *baselineFile != "" {
+		data, ok := c.read(*baselineFile)
+		if !ok {
+			return false
+		}
+		if err := json.Unmarshal(data, &baseline); err != nil {
+			c.report(*baselineFile, nil, err)
+			return false
+		}
+	}
+	corpora, err := bench.Corpora()
+	if err != nil {
+		fatalf("bench: %v", err)
+	}
+	for _, name := range c.flags.Args() {
+		if data, ok := c.read(name); ok {
+			corpora = append(corpora, bench.Corpus{Name: name, Text: data})
+		}
+	}
+	if c.failed {
+		return false
+	}
+
+	results, err := bench.Run(corpora)
+	if err != nil {
+		var ce *bench.CorpusError
+		if errors.As(err, &ce) {
+			c.report(ce.Corpus, nil, ce.Err)
+			return false
+		}
+		fatalf("bench: %v", err)
+	}
+	for _, r := range results {
+		fmt.Println(r)
+	}
+	if *save != "" {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err == nil {
+			err = os.WriteFile(*save, append(data, '\n'), 0o666)
+		}
+		if err != nil {
+			c.report(*save, nil, err)
+		}
+	}
+	for _, reg := range bench.Compare(baseline, results, *tolerance) {
+		c.failed = true
+		fmt.Fprintln(os.Stderr, "regression:", reg)
+	}
+	return !c.failed
+}
+
+func getCmd(args []string) bool {
+	c := newCommand("get")
+	c.flags.Parse(args)
+	if c.flags.NArg() < 1 || c.flags.NArg() > 2 {
+		c.flags.Usage()
+		os.Exit(2)
+	}
+	path := c.flags.Arg(0)
+	name := c.files(1)[0]
+	data, ok := c.read(name)
+	if !ok {
+		return false
+	}
+
+	var nodes []ast.Node
+	if strings.HasPrefix(path, "$") {
+		var err error
+		if nodes, err = asn1go.Query(data, path); err != nil {
+			c.report(name, data, err)
+			return false
+		}
+		if len(nodes) == 0 {
+			c.report(name, data, &asn1go.PathError{Path: path, Msg: "selects no value"})
+			return false
+		}
+	} else {
+		d, err := asn1go.ParseDocument(data)
+		if err != nil {
+			c.report(name, data, err)
+			return false
+		}
+		n, err := d.Find(path)
+		if err != nil {
+			c.report(name, data, err)
+			return false
+		}
+		nodes = []ast.Node{n}
+	}
+
+	var buf bytes.Buffer
+	for _, n := range nodes {
+		buf.Write(data[n.Pos().Offset:n.End().Offset])
+		buf.WriteByte('\n')
+	}
+	os.Stdout.Write(buf.Bytes())
+	return true
+}
+
+func setCmd(args []string) bool {
+	c := newCommand("set")
+	write := c.flags.Bool("w", false, "rewrite the file instead of writing to standard output")
+	c.flags.Parse(args)
+	if c.flags.NArg() < 2 || c.flags.NArg() > 3 {
+		c.flags.Usage()
+		os.Exit(2)
+	}
+	path, value := c.flags.Arg(0), c.flags.Arg(1)
+	v, err := asn1go.ParseValue([]byte(value))
+	if err != nil {
+		fatalf("set: value %q: %v", value, err)
+	}
+	name := c.files(2)[0]
+	data, ok := c.read(name)
+	if !ok {
+		return false
+	}
+
+	d, err := asn1go.ParseDocument(data)
+	if err != nil {
+		c.report(name, data, err)
+		return false
+	}
+	if err := d.Replace(path, v); err != nil {
+		c.report(name, data, err)
+		return false
+	}
+	if *write && name != "-" {
+		if err := os.WriteFile(name, d.Bytes(), 0o666); err != nil {
+			c.report(name, nil, err)
+			return false
+		}
+		return true
+	}
+	os.Stdout.Write(d.Bytes())
+	return true
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "asn1go: "+format+"\n", args...)
+	os.Exit(2)
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain runs the command itself instead of the tests when runMain sets
+// ASN1GO_RUN_MAIN, so that its output and exit status can be checked.
+func TestMain(m *testing.M) {
+	if os.Getenv("ASN1GO_RUN_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runMain runs the command with the arguments args in the directory dir
+// and returns its standard output, standard error and exit status.
+func runMain(t *testing.T, dir string, args ...string) (stdout, stderr string, status int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "ASN1GO_RUN_MAIN=1")
+	var out, errOut bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &errOut
+	err := cmd.Run()
+	var ee *exec.ExitError
+	switch {
+	case errors.As(err, &ee):
+		status = ee.ExitCode()
+	case err != nil:
+		t.Fatal(err)
+	}
+	return out.String(), errOut.String(), status
+}
+
+const testProfile = `value1 ProfileElement ::= header : {
+  major-version 2,
+  minor-version 3
+}
+value2 ProfileElement ::= end : { }
+`
+
+// writeProfile writes testProfile to p.txt in a new directory, which it
+// returns.
+func writeProfile(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "p.txt"), []byte(testProfile), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		args   []string
+		stdout string
+		stderr string // a part of the standard error
+		status int
+	}{
+		{[]string{"get", "value1.header.major-version", "p.txt"}, "2\n", "", 0},
+		{[]string{"get", "value1.header", "p.txt"}, "{\n  major-version 2,\n  minor-version 3\n}\n", "", 0},
+		{[]string{"get", "$..minor-version", "p.txt"}, "3\n", "", 0},
+		{[]string{"get", "value1.header.nope", "p.txt"}, "", `p.txt: asn1go: path "value1.header.nope" has no element nope`, 1},
+		{[]string{"get", "$..nope", "p.txt"}, "", `path "$..nope" selects no value`, 1},
+		{[]string{"get", "-json", "value1.x", "p.txt"}, "", `{"file":"p.txt","path":"value1.x",`, 1},
+		{[]string{"get", "value1", "missing.txt"}, "", "missing.txt", 1},
+		{[]string{"get"}, "", "usage:", 2},
+	}
+	for _, tt := range tests {
+		dir := writeProfile(t)
+		stdout, stderr, status := runMain(t, dir, tt.args...)
+		if stdout != tt.stdout || !strings.Contains(stderr, tt.stderr) || status != tt.status {
+			t.Errorf("asn1go %s:\nhave %q, %q, exit %d\nwant %q, %q, exit %d",
+				strings.Join(tt.args, " "), stdout, stderr, status, tt.stdout, tt.stderr, tt.status)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	changed := strings.Replace(testProfile, "minor-version 3", "minor-version 4", 1)
+	tests := []struct {
+		args   []string
+		stdout string
+		stderr string // a part of the standard error
+		status int
+		file   string // contents of p.txt afterwards
+	}{
+		{[]string{"set", "value1.header.minor-version", "4", "p.txt"}, changed, "", 0, testProfile},
+		{[]string{"set", "-w", "value1.header.minor-version", "4", "p.txt"}, "", "", 0, changed},
+		{[]string{"set", "value1.header.nope", "4", "p.txt"}, "", `path "value1.header.nope" has no element nope`, 1, testProfile},
+		{[]string{"set", "-w", "value2.nope", "4", "p.txt"}, "", `path "value2.nope"`, 1, testProfile},
+		{[]string{"set", "value1.header.minor-version", "{oops", "p.txt"}, "", `set: value "{oops"`, 2, testProfile},
+		{[]string{"set", "value1.header.minor-version"}, "", "usage:", 2, testProfile},
+	}
+	for _, tt := range tests {
+		dir := writeProfile(t)
+		stdout, stderr, status := runMain(t, dir, tt.args...)
+		if stdout != tt.stdout || !strings.Contains(stderr, tt.stderr) || status != tt.status {
+			t.Errorf("asn1go %s:\nhave %q, %q, exit %d\nwant %q, %q, exit %d",
+				strings.Join(tt.args, " "), stdout, stderr, status, tt.stdout, tt.stderr, tt.status)
+		}
+		if b, err := os.ReadFile(filepath.Join(dir, "p.txt")); err != nil || string(b) != tt.file {
+			t.Errorf("asn1go %s: p.txt is %q, %v, want %q", strings.Join(tt.args, " "), b, err, tt.file)
+		}
+	}
+}
+
+func TestFmtKeepsComments(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{
+			"assignment",
+			"-- The header.\nvalue1 ProfileElement ::= header:{ major-version 2, -- Version.\n minor-version 3 }\n",
+			"-- The header.\nvalue1 ProfileElement ::= header : {\n  major-version 2, -- Version.\n  minor-version 3\n}\n",
+		},
+		{
+			"doc comment of a component",
+			"v T ::= {\n-- Odd digits.\nbits 'ABC'H }\n",
+			"v T ::= {\n  -- Odd digits.\n  bits 'ABC'H\n}\n",
+		},
+		{
+			"several values",
+			"a T ::= 1 -- one\nb T ::= x : 2 -- two\n",
+			"a T ::= 1 -- one\nb T ::= x : 2 -- two\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "value.txt")
+			if err := os.WriteFile(name, []byte(tt.in), 0o666); err != nil {
+				t.Fatal(err)
+			}
+			if !fmtCmd([]string{"-w", name}) {
+				t.Fatalf("fmt -w failed on %q", tt.in)
+			}
+			got, err := os.ReadFile(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("fmt -w of %q wrote %q, want %q", tt.in, got, tt.want)
+			}
+
+			// Formatting the output again changes nothing.
+			if !fmtCmd([]string{"-w", name}) {
+				t.Fatalf("fmt -w failed on %q", got)
+			}
+			again, err := os.ReadFile(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(again) != string(got) {
+				t.Errorf("fmt -w of %q wrote %q, want it unchanged", got, again)
+			}
+		})
+	}
+}
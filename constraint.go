@@ -0,0 +1,183 @@
+package asn1go
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// CheckConstraints reports whether v, a value of the ASN.1 type t in any
+// of the forms EncodeDER accepts, satisfies the constraints of t and of
+// the types of its components and elements: the SIZE constraints, value
+// ranges and permitted alphabets recorded in Type.Size, Type.Range and
+// Type.From.
+//
+// The size of a character string is its number of characters, that of an
+// OCTET STRING its number of octets, that of a BIT STRING its number of
+// bits and that of a SEQUENCE OF or SET OF its number of elements. Values
+// outside an extensible constraint satisfy it, as values of its
+// extensions.
+//
+// The first violation found is returned as a *ConstraintViolationError.
+// Values that do not fit their types otherwise, such as a string given for
+// an INTEGER, are left to the encoders to reject.
+func CheckConstraints(t *Type, v interface{}) error {
+	var c constraintChecker
+	return c.check(t, reflect.ValueOf(v))
+}
+
+// A ConstraintViolationError describes a value that violates a constraint
+// of its type.
+type ConstraintViolationError struct {
+	Path       string // component identifiers leading to the value, such as "header.iccid"
+	Type       string // the ASN.1 type
+	Constraint string // the violated constraint, such as "SIZE (1..16)"
+}
+
+func (e *ConstraintViolationError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "value"
+	}
+	return "asn1go: " + path + " of type " + e.Type + " violates " + e.Constraint
+}
+
+// A constraintChecker checks values against the constraints of their
+// types.
+type constraintChecker struct {
+	path []string // identifiers of the components being checked
+}
+
+func (c *constraintChecker) check(t *Type, v reflect.Value) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if je, ok := r.(asn1Error); ok {
+				err = je.error
+			} else {
+				panic(r)
+			}
+		}
+	}()
+	c.value(t, v)
+	return nil
+}
+
+// violation aborts the check with a ConstraintViolationError for the
+// value of type t.
+func (c *constraintChecker) violation(t *Type, constraint string) {
+	panic(asn1Error{&ConstraintViolationError{strings.Join(c.path, "."), t.String(), constraint}})
+}
+
+// value checks v as a value of type t.
+func (c *constraintChecker) value(t *Type, v reflect.Value) {
+	v = derIndirect(v)
+	if !v.IsValid() {
+		return
+	}
+	switch t.Kind {
+	case KindInteger:
+		n, ok := integerValue(t, v)
+		if !ok && v.Kind() == reflect.String && v.Type() == numberType {
+			// Numbers beyond int64 saturate, which keeps them outside
+			// the bounds of any range they are outside of.
+			n, _ = strconv.ParseInt(v.String(), 10, 64)
+			ok = true
+		}
+		if ok && t.Range != nil && !t.Range.Extensible && !t.Range.contains(n) {
+			c.violation(t, "("+t.Range.String()+")")
+		}
+
+	case KindBitString:
+		if v.Type() == bitStringType {
+			c.size(t, v.Interface().(BitString).BitLength)
+		} else if b, ok := bytesOf(v); ok {
+			c.size(t, 8*len(b))
+		}
+
+	case KindOctetString:
+		if b, ok := bytesOf(v); ok {
+			c.size(t, len(b))
+		}
+
+	case KindUTF8String, KindNumericString, KindPrintableString, KindIA5String, KindVisibleString:
+		if v.Kind() != reflect.String {
+			return
+		}
+		s := v.String()
+		c.size(t, utf8.RuneCountInString(s))
+		if t.From != nil {
+			for _, r := range s {
+				if !inAlphabet(t.From, r) {
+					c.violation(t, "FROM ("+alphabetString(t.From)+")")
+				}
+			}
+		}
+
+	case KindSequence, KindSet:
+		if v.Kind() != reflect.Struct && v.Kind() != reflect.Map {
+			return
+		}
+		for i := range t.Components {
+			comp := &t.Components[i]
+			cv, ok := componentValue(v, comp.Name)
+			if !ok {
+				continue
+			}
+			c.path = append(c.path, comp.Name)
+			c.value(comp.Type, cv)
+			c.path = c.path[:len(c.path)-1]
+		}
+
+	case KindChoice:
+		name, av, ok := choiceOf(v)
+		if !ok {
+			return
+		}
+		if comp := t.Component(name); comp != nil {
+			c.path = append(c.path, name)
+			c.value(comp.Type, av)
+			c.path = c.path[:len(c.path)-1]
+		}
+
+	case KindSequenceOf, KindSetOf:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return
+		}
+		c.size(t, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.path = append(c.path, strconv.Itoa(i))
+			c.value(t.Elem, v.Index(i))
+			c.path = c.path[:len(c.path)-1]
+		}
+	}
+}
+
+// size checks the size n of a value of type t against its SIZE
+// constraint.
+func (c *constraintChecker) size(t *Type, n int) {
+	if t.Size != nil && !t.Size.Extensible && !t.Size.contains(int64(n)) {
+		c.violation(t, "SIZE ("+t.Size.String()+")")
+	}
+}
+
+// inAlphabet reports whether r is among the characters of the permitted
+// alphabet from.
+func inAlphabet(from []CharRange, r rune) bool {
+	for _, cr := range from {
+		if r >= cr.Lo && r <= cr.Hi {
+			return true
+		}
+	}
+	return false
+}
+
+// alphabetString returns the permitted alphabet from in ASN.1 notation,
+// such as "0".."9" | "#".
+func alphabetString(from []CharRange) string {
+	s := make([]string, len(from))
+	for i, cr := range from {
+		s[i] = cr.String()
+	}
+	return strings.Join(s, " | ")
+}
@@ -0,0 +1,88 @@
+package asn1go
+
+import "testing"
+
+const constraintModuleSrc = `C DEFINITIONS AUTOMATIC TAGS ::= BEGIN
+Digits ::= IA5String (FROM ("0".."9"))
+Msisdn ::= Digits (SIZE (1..15))
+Hex ::= IA5String (FROM ("0".."9" | "A".."F") ^ SIZE (2))
+R ::= SEQUENCE {
+  id INTEGER (0..255),
+  n Msisdn OPTIONAL,
+  list SEQUENCE (SIZE (1..2)) OF OCTET STRING (SIZE (3)),
+  b BIT STRING (SIZE (4)) OPTIONAL,
+  e INTEGER (1..4, ...) OPTIONAL,
+  u UTF8String (SIZE (2)) OPTIONAL
+}
+END`
+
+func TestCheckConstraints(t *testing.T) {
+	m, err := ParseModule([]byte(constraintModuleSrc))
+	if err != nil {
+		t.Fatalf("ParseModule: %v", err)
+	}
+	r := m.Type("R")
+	list := []interface{}{[]byte("abc")}
+	tests := []struct {
+		t          *Type
+		v          interface{}
+		path       string // of the violation, if any
+		constraint string
+	}{
+		{r, map[string]interface{}{"id": 3, "n": "123", "list": list, "u": "éé", "e": 9}, "", ""},
+		{r, map[string]interface{}{"id": 255, "list": list, "b": BitString{Bytes: []byte{0xF0}, BitLength: 4}}, "", ""},
+		{r, map[string]interface{}{"id": 256, "list": list}, "id", "(0..255)"},
+		{r, map[string]interface{}{"id": -1, "list": list}, "id", "(0..255)"},
+		{r, map[string]interface{}{"id": Number("99999999999999999999999"), "list": list}, "id", "(0..255)"},
+		{r, map[string]interface{}{"id": 1, "n": "12a", "list": list}, "n", `FROM ("0".."9")`},
+		{r, map[string]interface{}{"id": 1, "n": "1234567890123456", "list": list}, "n", "SIZE (1..15)"},
+		{r, map[string]interface{}{"id": 1, "n": "", "list": list}, "n", "SIZE (1..15)"},
+		{r, map[string]interface{}{"id": 1, "list": []interface{}{}}, "list", "SIZE (1..2)"},
+		{r, map[string]interface{}{"id": 1, "list": []interface{}{[]byte("ab")}}, "list.0", "SIZE (3)"},
+		{r, map[string]interface{}{"id": 1, "list": list, "b": BitString{Bytes: []byte{0}, BitLength: 5}}, "b", "SIZE (4)"},
+		{r, map[string]interface{}{"id": 1, "list": list, "u": "abc"}, "u", "SIZE (2)"},
+		{m.Type("Hex"), "0F", "", ""},
+		{m.Type("Hex"), "0G", "", `FROM ("0".."9" | "A".."F")`},
+		{m.Type("Hex"), "0", "", "SIZE (2)"},
+	}
+	for _, tt := range tests {
+		err := CheckConstraints(tt.t, tt.v)
+		if tt.constraint == "" {
+			if err != nil {
+				t.Errorf("CheckConstraints(%v, %v): %v", tt.t, tt.v, err)
+			}
+			continue
+		}
+		ce, ok := err.(*ConstraintViolationError)
+		if !ok {
+			t.Errorf("CheckConstraints(%v, %v): error %v, want ConstraintViolationError", tt.t, tt.v, err)
+			continue
+		}
+		if ce.Path != tt.path || ce.Constraint != tt.constraint {
+			t.Errorf("CheckConstraints(%v, %v): %q violates %q, want %q violates %q", tt.t, tt.v, ce.Path, ce.Constraint, tt.path, tt.constraint)
+		}
+	}
+}
+
+func TestCheckConstraintsOptions(t *testing.T) {
+	m, err := ParseModule([]byte(constraintModuleSrc))
+	if err != nil {
+		t.Fatalf("ParseModule: %v", err)
+	}
+	r := m.Type("R")
+	bad := map[string]interface{}{"id": 1, "n": "12a", "list": []interface{}{[]byte("abc")}}
+	if _, err := (EncodeOptions{CheckConstraints: true}).Encode(r, bad); err == nil {
+		t.Error("EncodeOptions.Encode of a value violating a constraint: no error")
+	}
+	der, err := EncodeDER(r, bad)
+	if err != nil {
+		t.Fatalf("EncodeDER: %v", err)
+	}
+	var v interface{}
+	if err := (DecodeOptions{}).Decode(r, der, &v); err != nil {
+		t.Errorf("DecodeOptions{}.Decode: %v", err)
+	}
+	if _, ok := (DecodeOptions{CheckConstraints: true}).Decode(r, der, &v).(*ConstraintViolationError); !ok {
+		t.Error("DecodeOptions.Decode of a value violating a constraint: want ConstraintViolationError")
+	}
+}
@@ -0,0 +1,2301 @@
+package asn1go
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// Unmarshal parses the ASN.1 value notation in data and stores the result
+// in the value pointed to by v. If v is nil or not a pointer, Unmarshal
+// returns an InvalidUnmarshalError.
+//
+// data holds a single value, optionally written as a value assignment
+// ("value1 ProfileElement ::= header : { ... }"), or a sequence of value
+// assignments such as a complete profile package. A document with more than
+// one top-level value can only be unmarshaled into a slice, an array, an
+// empty interface (as []interface{}) or a map keyed by value reference name.
+//
+// Unmarshal maps value notation onto Go values as follows:
+//
+//   - A SEQUENCE or SET value, { a 1, b 2 }, is stored in a struct or in a
+//     map with string keys. Components are matched against the field's
+//     asn1 tag name, or the field name ignoring case and hyphens, so that
+//     major-version is stored in a field named MajorVersion. Unknown
+//     components are ignored.
+//   - A SEQUENCE OF or SET OF value, { 1, 2 }, is stored in a slice or an
+//     array. Elements that select a CHOICE alternative, as in
+//     { fillFileOffset : 2, fillFileContent : '00'H }, are stored in the
+//     elements as described for CHOICE values. Nested values without
+//     identifiers, { { a 1 }, { } }, are elements too.
+//   - An empty value, { }, is an empty SEQUENCE, SET, SEQUENCE OF or SET
+//     OF value. It leaves the fields of a struct alone, allocating a nil
+//     pointer to it, and stores an empty map or an empty, non-nil slice.
+//   - An OBJECT IDENTIFIER value, { 2 23 143 1 2 1 }, is stored in an
+//     ObjectIdentifier or any other slice of integers.
+//   - A CHOICE value, alt : value, is stored in the field of a struct or
+//     the entry of a map named after the alternative.
+//   - An hstring, '0A'H, is stored in a []byte, a byte array of the same
+//     length, a BitString or a string holding the hexadecimal digits in
+//     upper case. An odd number of digits is an error, unless a Decoder
+//     pads them by its HexPolicy. A bstring, '0110'B, is stored in a
+//     BitString, a []byte or a string. White space between the digits of
+//     either, as in '4F 3A 00 1B'H broken over several lines, is left out.
+//   - A cstring, "text", is stored in a string or a []byte. A doubled
+//     quotation mark in a cstring stands for one, and a cstring may span
+//     several lines, whose ends are left out with the spacing around them.
+//     A cstring holding a GeneralizedTime, "20230115120000Z", is stored in
+//     a time.Time, or one holding a UTCTime, "230115120000Z", for a field
+//     with the "utc" tag option. A cstring holding a DATE, TIME-OF-DAY,
+//     DATE-TIME or DURATION value, such as "2023-01-15" or "P1Y2M3D", is
+//     stored in a Date, TimeOfDay, DateTime or Duration. Other Go values
+//     that implement encoding.TextUnmarshaler, such as a net.IP, are given
+//     the text of a cstring, "192.0.2.1", with their UnmarshalText method.
+//   - A named bit list, { bitA, bitC }, is stored in a BitString, and an
+//     identifier in an integer, by the named bits or numbers of the
+//     field's "named:<id>=<n>|..." tag option, such as
+//     `asn1:"flags,named:bitA=0|bitB=1|bitC=2"`.
+//   - An INTEGER or REAL number is stored in an integer or float type, and
+//     an INTEGER of any size in a big.Int. A number that does not fit the
+//     integer type, such as 256 for a uint8 or -1 for a uint16, is an
+//     UnmarshalTypeError rather than truncated. An hstring is stored in an
+//     integer or a big.Int, as an unsigned big-endian number, for a field
+//     with the "intfromhex" tag option, as in `asn1:"size,intfromhex"`;
+//     any other value is an UnmarshalTypeError for a big.Int.
+//     REAL values may also be written as { mantissa 314159, base 10,
+//     exponent -5 } or as PLUS-INFINITY, MINUS-INFINITY and NOT-A-NUMBER.
+//   - A number is also stored in a Number, which keeps its literal text.
+//   - TRUE and FALSE are stored in a bool.
+//   - NULL is stored in a bool as true, in an empty struct, or in an
+//     interface as nil. Pointers are allocated as usual, so a *struct{}
+//     field records whether a NULL component is present.
+//   - Other identifiers, such as enumerated values, are stored in a string.
+//
+// To unmarshal value notation into an interface value, Unmarshal stores
+// OrderedObject for SEQUENCE values, []interface{} for SEQUENCE OF
+// values, ObjectIdentifier for OBJECT IDENTIFIER values, a single-entry
+// map[string]interface{} for CHOICE values, []byte for hstrings, BitString
+// for bstrings, string for cstrings and identifiers, int64 for integers,
+// or *big.Int for those too large for an int64, float64 for real numbers,
+// bool for TRUE and FALSE and nil for NULL. An empty value, { }, is an
+// empty OrderedObject, and a value whose components are followed
+// by an element without identifier, { a 1, { b 2 } }, is a []interface{}
+// holding the components as single-entry maps, as SEQUENCE OF CHOICE
+// values are. These forms do not tell a CHOICE value from a SEQUENCE
+// value of one component, nor an identifier from a cstring, so that
+// Marshal writes alt : 5 decoded into an interface value back as
+// { alt 5 }, and an ENUMERATED value x as "x". Unmarshal into a Value to
+// keep them apart.
+//
+// If a value is not appropriate for a given target type, Unmarshal skips
+// that value and completes the unmarshaling as best it can. If no more
+// serious errors are encountered, Unmarshal returns an UnmarshalTypeError
+// describing the earliest such error.
+func Unmarshal(data []byte, v interface{}) error {
+	// Check for well-formedness.
+	// Avoids filling out half a data structure
+	// before discovering a syntax error.
+	d := newDecodeState()
+	defer freeDecodeState(d)
+	n, err := checkValid(data, &d.scan)
+	if err != nil {
+		return err
+	}
+
+	d.init(data)
+	return d.unmarshal(v, n)
+}
+
+// UnmarshalSingle is like Unmarshal but requires data to hold exactly one
+// top-level value, for callers that validate single-value payloads. Any
+// byte after the value other than space and comments is a SyntaxError.
+func UnmarshalSingle(data []byte, v interface{}) error {
+	d := newDecodeState()
+	defer freeDecodeState(d)
+	d.scan.reset()
+	d.scan.allowMultipleTopValues = false
+	n, err := scanValid(data, &d.scan)
+	if err != nil {
+		return err
+	}
+
+	d.init(data)
+	return d.unmarshal(v, n)
+}
+
+// UnmarshalReader is like Unmarshal but reads the value notation from r,
+// one top-level value at a time, rather than needing it all in memory, so
+// that a large profile package takes no more memory to decode than its
+// largest value and the Go values it is decoded into. Unlike Unmarshal,
+// it stores the values before a value with a syntax error, and then
+// returns the SyntaxError, whose offset counts from the start of the input
+// as that of Unmarshal does; the offsets of the errors of values that do
+// not fit their Go values count from the start of the value they are found
+// in. An Unmarshaler that receives a document of several values is given
+// all of it at once.
+func UnmarshalReader(r io.Reader, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+	dec := NewDecoder(r)
+	first, err := dec.nextValue()
+	if err == io.EOF {
+		err = &SyntaxError{msg: "unexpected end of ASN.1 input", err: ErrUnexpectedEOF}
+	}
+	if err != nil {
+		return dec.eofError(err)
+	}
+	more := dec.More()
+	if dec.err != nil && dec.err != io.EOF {
+		return dec.eofError(dec.err)
+	}
+	first = append([]byte(nil), first...)
+	if !more {
+		dec.d.init(first)
+		return dec.d.unmarshal(v, 1)
+	}
+	return dec.eofError(dec.unmarshalAll(rv, first))
+}
+
+// eofError returns err, or the SyntaxError of the value the input ends in
+// if err is the io.ErrUnexpectedEOF of a Decoder, as Unmarshal reports it.
+func (dec *Decoder) eofError(err error) error {
+	if err != io.ErrUnexpectedEOF {
+		return err
+	}
+	if se, ok := dec.scan.err.(*SyntaxError); ok {
+		return se
+	}
+	return &SyntaxError{msg: "unexpected end of ASN.1 input", err: ErrUnexpectedEOF, Offset: dec.scan.bytes}
+}
+
+// nextValue reads the next top-level value and returns its text, which is
+// valid until the next read.
+func (dec *Decoder) nextValue() ([]byte, error) {
+	n, err := dec.readValue()
+	if err != nil {
+		return nil, err
+	}
+	b := dec.buf[dec.scanp : dec.scanp+n]
+	dec.consume(n)
+	return b, nil
+}
+
+// unmarshalAll decodes the top-level values of the input, the first of
+// which is first and at least one other follows, into the elements of v,
+// as topValues does.
+func (dec *Decoder) unmarshalAll(v reflect.Value, first []byte) error {
+	u, pv := indirect(v)
+	if u != nil {
+		rest, err := io.ReadAll(io.MultiReader(dec.Buffered(), dec.r))
+		if err != nil {
+			return err
+		}
+		return u.UnmarshalASN1(append(append(first, '\n'), rest...))
+	}
+	v = pv
+
+	var add func(i int, data []byte) error
+	var saved error
+	decode := func(data []byte, ev reflect.Value) (string, error) {
+		d := dec.d.init(data)
+		d.scan.reset()
+		d.scanWhile(scanSkipSpace)
+		name, err := d.topValue(ev)
+		if err != nil {
+			return "", d.addErrorContext(err)
+		}
+		if saved == nil {
+			saved = d.savedError
+		}
+		return name, nil
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			break
+		}
+		var vals []interface{}
+		add = func(i int, data []byte) error {
+			vals = append(vals, nil)
+			_, err := decode(data, reflect.ValueOf(&vals[i]).Elem())
+			return err
+		}
+		defer func() { v.Set(reflect.ValueOf(vals)) }()
+
+	case reflect.Slice:
+		v.SetLen(0)
+		add = func(i int, data []byte) error {
+			v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+			_, err := decode(data, v.Index(i))
+			return err
+		}
+
+	case reflect.Array:
+		n := 0
+		add = func(i int, data []byte) error {
+			var ev reflect.Value
+			if i < v.Len() {
+				ev = v.Index(i)
+			}
+			n = i + 1
+			_, err := decode(data, ev)
+			return err
+		}
+		defer func() {
+			z := reflect.Zero(v.Type().Elem())
+			for i := n; i < v.Len(); i++ {
+				v.Index(i).Set(z)
+			}
+		}()
+
+	case reflect.Map:
+		t := v.Type()
+		if t.Key().Kind() != reflect.String {
+			break
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(t))
+		}
+		add = func(_ int, data []byte) error {
+			elem := reflect.New(t.Elem()).Elem()
+			name, err := decode(data, elem)
+			if err != nil {
+				return err
+			}
+			if name == "" {
+				if saved == nil {
+					saved = &UnmarshalTypeError{Value: "value without assignment", Type: t}
+				}
+				return nil
+			}
+			v.SetMapIndex(reflect.ValueOf(name).Convert(t.Key()), elem)
+			return nil
+		}
+	}
+	if add == nil {
+		return &UnmarshalTypeError{Value: "several top-level values", Type: v.Type()}
+	}
+
+	data := first
+	for i := 0; ; i++ {
+		if err := add(i, data); err != nil {
+			return err
+		}
+		var err error
+		if data, err = dec.nextValue(); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+	return saved
+}
+
+// UnmarshalConcurrent is like Unmarshal for a document of several
+// independent top-level values, such as the value assignments of a profile
+// package, but decodes the values concurrently on up to n goroutines, or
+// GOMAXPROCS if n is not positive. v must point to a slice, an array, an
+// empty interface or a map keyed by value reference name, which receives
+// one element per value in input order, even if there is only one.
+//
+// The input is split at the boundaries of the top-level values while it
+// is checked for well-formedness, before any value is decoded. If several
+// values are not appropriate for their targets, UnmarshalConcurrent
+// returns the error of the first one in the input, as Unmarshal would.
+func UnmarshalConcurrent(data []byte, v interface{}, n int) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+	bounds, err := splitTopValues(data)
+	if err != nil {
+		return err
+	}
+
+	// Set up a target for each value.
+	rv = rv.Elem()
+	count := len(bounds) - 1
+	targets := make([]reflect.Value, count)
+	var vals []interface{}
+	switch {
+	case rv.Kind() == reflect.Interface && rv.NumMethod() == 0:
+		vals = make([]interface{}, count)
+		for i := range targets {
+			targets[i] = reflect.ValueOf(&vals[i]).Elem()
+		}
+	case rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array:
+		if rv.Kind() == reflect.Slice {
+			rv.Set(reflect.MakeSlice(rv.Type(), count, count))
+		}
+		for i := range targets {
+			if i < rv.Len() {
+				targets[i] = rv.Index(i)
+			}
+		}
+		if rv.Kind() == reflect.Array {
+			z := reflect.Zero(rv.Type().Elem())
+			for i := count; i < rv.Len(); i++ {
+				rv.Index(i).Set(z)
+			}
+		}
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		for i := range targets {
+			targets[i] = reflect.New(rv.Type().Elem()).Elem()
+		}
+	default:
+		return &UnmarshalTypeError{Value: strconv.Itoa(count) + " top-level values", Type: rv.Type()}
+	}
+
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	names := make([]string, count)
+	errs := make([]error, count)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < n && w < count; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				names[i], errs[i] = unmarshalTopValue(data[bounds[i]:bounds[i+1]], bounds[i], targets[i])
+			}
+		}()
+	}
+	for i := 0; i < count; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	if vals != nil {
+		rv.Set(reflect.ValueOf(vals))
+	}
+	if rv.Kind() == reflect.Map {
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMap(rv.Type()))
+		}
+		for i, name := range names {
+			if name == "" && errs[i] == nil {
+				errs[i] = &UnmarshalTypeError{Value: "value without assignment", Type: rv.Type(), Offset: int64(bounds[i])}
+			} else if name != "" {
+				rv.SetMapIndex(reflect.ValueOf(name).Convert(rv.Type().Key()), targets[i])
+			}
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SplitValues splits data into its top-level values, such as the value
+// assignments of a profile package, without decoding them, for sharding,
+// filtering or storing them one by one. Each slice spans a value from its
+// first byte to its last, without the space and comments around it, and
+// shares the memory of data. SplitValues checks that data is valid ASN.1
+// value notation first; see ScanValues to split the values of a stream.
+func SplitValues(data []byte) ([][]byte, error) {
+	scan := newScanner()
+	defer freeScanner(scan)
+	var values [][]byte
+	start, end := -1, 0
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		scan.bytes++
+		op := scan.step(scan, c)
+		if op == scanEnd {
+			// c begins the next top-level value.
+			values = append(values, data[start:end])
+			start = -1
+			scan.restart()
+			op = scan.step(scan, c)
+		}
+		if op == scanError {
+			return nil, addSnippet(scan.err, data, i, 0)
+		}
+		k := scan.skipRun(data[i+1:], c, op)
+		if op != scanSkipSpace {
+			if start < 0 {
+				start = valueStart(scan, c, i)
+			}
+			end = i + 1 + k
+		}
+		i += k
+		scan.bytes += int64(k)
+	}
+	if scan.eof() == scanError {
+		return nil, addSnippet(scan.err, data, len(data), 0)
+	}
+	return append(values, data[start:end]), nil
+}
+
+// valueStart returns the offset of the first byte of a value or literal
+// whose first significant byte, as the scanner reports it, is c at i.
+func valueStart(scan *scanner, c byte, i int) int {
+	if scan.minus && isDigit(c) {
+		// The minus sign of a negative number came first.
+		return i - 1
+	}
+	return i
+}
+
+// splitTopValues checks that data is valid ASN.1 value notation and
+// returns the offsets at which its top-level values begin, followed by
+// len(data).
+func splitTopValues(data []byte) ([]int, error) {
+	scan := newScanner()
+	defer freeScanner(scan)
+	bounds := []int{0}
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		scan.bytes++
+		op := scan.step(scan, c)
+		if op == scanEnd {
+			// c begins the next top-level value.
+			bounds = append(bounds, i)
+			scan.restart()
+			op = scan.step(scan, c)
+		}
+		if op == scanError {
+			return nil, addSnippet(scan.err, data, i, 0)
+		}
+		k := scan.skipRun(data[i+1:], c, op)
+		i += k
+		scan.bytes += int64(k)
+	}
+	if scan.eof() == scanError {
+		return nil, addSnippet(scan.err, data, len(data), 0)
+	}
+	return append(bounds, len(data)), nil
+}
+
+// unmarshalTopValue decodes the single top-level value data, which begins
+// at offset off of the document, into v. It returns the value reference
+// name of the assignment, or "" for a plain value.
+func unmarshalTopValue(data []byte, off int, v reflect.Value) (string, error) {
+	d := newDecodeState()
+	defer freeDecodeState(d)
+	d.init(data)
+	d.scan.reset()
+	d.scanWhile(scanSkipSpace)
+	name, err := d.topValue(v)
+	if err != nil {
+		err = d.addErrorContext(err)
+	} else {
+		err = d.savedError
+	}
+	if te, ok := err.(*UnmarshalTypeError); ok {
+		te.Offset += int64(off)
+	}
+	return name, err
+}
+
+// Unmarshaler is the interface implemented by types that can unmarshal an
+// ASN.1 value notation description of themselves. The input can be assumed
+// to be a valid encoding of a single value. UnmarshalASN1 must copy the
+// data if it wishes to retain the data after returning.
+type Unmarshaler interface {
+	UnmarshalASN1([]byte) error
+}
+
+// An UnmarshalTypeError describes an ASN.1 value that was not appropriate
+// for a value of a specific Go type.
+type UnmarshalTypeError struct {
+	Value  string       // description of ASN.1 value - "hstring", "object", "number 5"
+	Type   reflect.Type // type of Go value it could not be assigned to
+	Offset int64        // error occurred after reading Offset bytes
+	Struct string       // name of the struct type containing the field
+	Field  string       // the full path from root node to the field
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	if e.Struct != "" || e.Field != "" {
+		return "asn1go: cannot unmarshal " + e.Value + " into Go struct field " + e.Struct + "." + e.Field + " of type " + e.Type.String()
+	}
+	return "asn1go: cannot unmarshal " + e.Value + " into Go value of type " + e.Type.String()
+}
+
+// A DuplicateComponentError describes a component identifier that appears
+// twice in one SEQUENCE or SET value decoded into a struct or map, which a
+// Decoder reports after DisallowDuplicateComponents.
+type DuplicateComponentError struct {
+	Name   string // identifier of the component
+	Offset int64  // the second occurrence begins after Offset bytes
+}
+
+func (e *DuplicateComponentError) Error() string {
+	return "asn1go: duplicate component " + e.Name
+}
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// (The argument to Unmarshal must be a non-nil pointer.)
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "asn1go: Unmarshal(nil)"
+	}
+
+	if e.Type.Kind() != reflect.Pointer {
+		return "asn1go: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "asn1go: Unmarshal(nil " + e.Type.String() + ")"
+}
+
+func (d *decodeState) unmarshal(v interface{}, n int) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+
+	d.scan.reset()
+	d.scanWhile(scanSkipSpace)
+	// We decode rv not rv.Elem because the Unmarshaler interface
+	// test must be applied at the top level of the value.
+	var err error
+	if n == 1 {
+		_, err = d.topValue(rv)
+	} else {
+		err = d.topValues(rv, n)
+	}
+	if err != nil {
+		return d.addErrorContext(err)
+	}
+	return d.savedError
+}
+
+// decodeState represents the state while decoding an ASN.1 value.
+type decodeState struct {
+	data         []byte
+	off          int // next read offset in data
+	opcode       int // last read result
+	scan         scanner
+	errorContext *errorContext
+	savedError   error
+
+	// utcTime reports that the field being decoded has the "utc" tag
+	// option, which reads a time.Time from a UTCTime.
+	utcTime bool
+
+	// intFromHex reports that the field being decoded has the
+	// "intfromhex" tag option, which reads an integer from an hstring.
+	intFromHex bool
+
+	// named holds the named numbers or bits of the "named" tag option of
+	// the field being decoded, for its identifiers and named bit lists.
+	named []NamedNumber
+
+	useNumber bool
+
+	// zeroCopy lets the strings and RawValues decoded share the memory of
+	// data rather than copying it.
+	zeroCopy bool
+
+	// hexPolicy says how to read hstrings with an odd number of digits.
+	hexPolicy HexPolicy
+
+	// noDuplicates reports components that appear twice in a value
+	// decoded into a struct or map.
+	noDuplicates bool
+
+	// keepUnknown stores the CHOICE values whose alternatives a struct
+	// does not know in its RawChoice field.
+	keepUnknown bool
+
+	// registry holds the Go types to decode values into interfaces as,
+	// or is nil.
+	registry *Registry
+
+	// resolvers holds the LiteralResolvers of the kinds of literals that
+	// have one, or is nil.
+	resolvers map[TokenKind]LiteralResolver
+
+	// schema holds the types of typed value assignments decoded into
+	// interfaces, or is nil.
+	schema *Module
+
+	// ctx is checked for cancellation every ctxCheckValues values, or
+	// is nil if decoding cannot be cancelled.
+	ctx    context.Context
+	values int
+
+	// refs holds the text of the values of the value references to
+	// resolve by name, or is nil if value references are not resolved.
+	// resolving lists the references whose values are being decoded.
+	refs      map[string][]byte
+	resolving []string
+}
+
+var decodeStatePool = sync.Pool{
+	New: func() interface{} {
+		return new(decodeState)
+	},
+}
+
+// newDecodeState returns a decodeState from the pool, with the options of
+// a new one.
+func newDecodeState() *decodeState {
+	return decodeStatePool.Get().(*decodeState)
+}
+
+// freeDecodeState returns d to the pool.
+func freeDecodeState(d *decodeState) {
+	d.clear()
+	decodeStatePool.Put(d)
+}
+
+// clear resets d to a new decodeState, without the data it decoded, but
+// keeps the buffers of its scanner and error context for reuse unless
+// they grew too large.
+func (d *decodeState) clear() {
+	parseState, names, ec := d.scan.parseState, d.scan.names, d.errorContext
+	// Avoid hanging on to too much memory in extreme cases.
+	if cap(parseState) > 1024 {
+		parseState, names = nil, nil
+	}
+	*d = decodeState{errorContext: ec}
+	d.scan.parseState, d.scan.names = parseState[:0], names
+	if ec != nil {
+		ec.Struct = nil
+		ec.FieldStack = ec.FieldStack[:0]
+	}
+}
+
+// ctxCheckValues is the number of values decoded between checks of the
+// context of a DecodeContext.
+const ctxCheckValues = 1024
+
+// An errorContext provides context for type errors during decoding.
+type errorContext struct {
+	Struct     reflect.Type
+	FieldStack []string
+}
+
+// readIndex returns the position of the last byte read.
+func (d *decodeState) readIndex() int {
+	return d.off - 1
+}
+
+// phasePanicMsg is used as a panic message when we end up with something that
+// shouldn't happen. It can indicate a bug in the ASN.1 decoder, or that
+// something is editing the data slice while the decoder executes.
+const phasePanicMsg = "ASN.1 decoder out of sync - data changing underfoot?"
+
+func (d *decodeState) init(data []byte) *decodeState {
+	d.data = data
+	d.off = 0
+	d.savedError = nil
+	d.utcTime = false
+	d.intFromHex = false
+	d.named = nil
+	if d.errorContext != nil {
+		d.errorContext.Struct = nil
+		// Reuse the allocated space for the FieldStack slice.
+		d.errorContext.FieldStack = d.errorContext.FieldStack[:0]
+	}
+	return d
+}
+
+// saveError saves the first err it is called with,
+// for reporting at the end of the unmarshal.
+func (d *decodeState) saveError(err error) {
+	if d.savedError == nil {
+		d.savedError = d.addErrorContext(err)
+	}
+}
+
+// addErrorContext returns a new error enhanced with information from d.errorContext
+func (d *decodeState) addErrorContext(err error) error {
+	if d.errorContext != nil && (d.errorContext.Struct != nil || len(d.errorContext.FieldStack) > 0) {
+		switch err := err.(type) {
+		case *UnmarshalTypeError:
+			err.Struct = d.errorContext.Struct.Name()
+			err.Field = strings.Join(d.errorContext.FieldStack, ".")
+		}
+	}
+	return err
+}
+
+// skip scans to the end of what was started.
+func (d *decodeState) skip() {
+	s, data, i := &d.scan, d.data, d.off
+	depth := len(s.parseState)
+	for {
+		op := s.step(s, data[i])
+		i++
+		if len(s.parseState) < depth {
+			d.off = i
+			d.opcode = op
+			return
+		}
+		i += s.skipRun(data[i:], data[i-1], op)
+	}
+}
+
+// scanNext processes the byte at d.data[d.off].
+func (d *decodeState) scanNext() {
+	if d.off < len(d.data) {
+		d.opcode = d.scan.step(&d.scan, d.data[d.off])
+		d.off++
+	} else {
+		d.opcode = d.scan.eof()
+		d.off = len(d.data) + 1 // mark processed EOF with len+1
+	}
+}
+
+// scanWhile processes bytes in d.data[d.off:] until it
+// receives a scan code not equal to op.
+func (d *decodeState) scanWhile(op int) {
+	s, data, i := &d.scan, d.data, d.off
+	for i < len(data) {
+		newOp := s.step(s, data[i])
+		i++
+		if newOp != op {
+			d.opcode = newOp
+			d.off = i
+			return
+		}
+		i += s.skipRun(data[i:], data[i-1], op)
+	}
+
+	d.off = len(data) + 1 // mark processed EOF with len+1
+	d.opcode = d.scan.eof()
+}
+
+// topValues decodes a document of n top-level values into v, which must be
+// a slice, an array, an empty interface or a map keyed by value reference
+// name.
+func (d *decodeState) topValues(v reflect.Value, n int) error {
+	u, pv := indirect(v)
+	if u != nil {
+		return u.UnmarshalASN1(d.data)
+	}
+	v = pv
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			break
+		}
+		vals := make([]interface{}, n)
+		for i := range vals {
+			if _, err := d.topValue(reflect.ValueOf(&vals[i]).Elem()); err != nil {
+				return err
+			}
+			d.nextTopValue()
+		}
+		v.Set(reflect.ValueOf(vals))
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), n, n))
+		}
+		for i := 0; i < n; i++ {
+			var ev reflect.Value
+			if i < v.Len() {
+				ev = v.Index(i)
+			}
+			if _, err := d.topValue(ev); err != nil {
+				return err
+			}
+			d.nextTopValue()
+		}
+		if v.Kind() == reflect.Array {
+			z := reflect.Zero(v.Type().Elem())
+			for i := n; i < v.Len(); i++ {
+				v.Index(i).Set(z)
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		t := v.Type()
+		if t.Key().Kind() != reflect.String {
+			break
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(t))
+		}
+		for i := 0; i < n; i++ {
+			start := d.readIndex()
+			elem := reflect.New(t.Elem()).Elem()
+			name, err := d.topValue(elem)
+			if err != nil {
+				return err
+			}
+			if name == "" {
+				d.saveError(&UnmarshalTypeError{Value: "value without assignment", Type: t, Offset: int64(start)})
+			} else {
+				v.SetMapIndex(reflect.ValueOf(name).Convert(t.Key()), elem)
+			}
+			d.nextTopValue()
+		}
+		return nil
+	}
+
+	d.saveError(&UnmarshalTypeError{Value: strconv.Itoa(n) + " top-level values", Type: v.Type(), Offset: int64(d.readIndex())})
+	return nil
+}
+
+// nextTopValue moves on to the top-level value that follows the one just
+// decoded, if there is one.
+func (d *decodeState) nextTopValue() {
+	if d.opcode == scanSkipSpace {
+		d.scanWhile(scanSkipSpace)
+	}
+	if d.off > len(d.data) {
+		return
+	}
+	// The byte that ended the last value begins the next one.
+	d.scan.restart()
+	d.off--
+	d.scanNext()
+}
+
+// topValue decodes a top-level value into v, consuming the value assignment
+// header in front of it if there is one. It returns the value reference
+// name of the assignment, or "" for a plain value.
+func (d *decodeState) topValue(v reflect.Value) (string, error) {
+	a := valueAssignment(v)
+	if a.IsValid() {
+		v = a.Field(2)
+	}
+	if d.opcode != scanBeginValueReference {
+		return "", d.value(v)
+	}
+	start := d.readIndex()
+	name := d.name()
+	if dv := dynamicTarget(v); dv.IsValid() && d.opcode != scanBeginTypeReference {
+		dv.Set(reflect.ValueOf(d.topDynamicValue(name)))
+		return "", nil
+	}
+	switch d.opcode {
+	case scanBeginTypeReference:
+		typ := d.typeReference()
+		d.scanWhile(scanSkipSpace)
+		if a.IsValid() {
+			a.Field(0).SetString(string(name))
+			a.Field(1).SetString(typ)
+		}
+		start := d.readIndex()
+		var err error
+		if iface, nv, ok := d.registered(typ, v); ok {
+			if err = d.value(nv); err == nil {
+				iface.Set(nv)
+			}
+		} else if err = d.value(v); err == nil {
+			d.schemaValue(typ, v)
+		}
+		if d.refs != nil {
+			// Later values may refer to this one.
+			d.refs[string(name)] = append([]byte(nil), d.data[start:d.readIndex()]...)
+		}
+		return string(name), err
+	case scanChoiceTag:
+		d.scanWhile(scanSkipSpace)
+		return "", d.choice(start, name, v)
+	case scanBeginObject, scanBeginLiteral:
+		// The identifier was CONTAINING.
+		return "", d.containing(start, v)
+	}
+	// A lone identifier, such as NULL.
+	if !v.IsValid() {
+		return "", nil
+	}
+	return "", d.literalStore(name, v)
+}
+
+// dynamicTarget returns the Value v points to, allocating pointers as
+// needed, or the zero reflect.Value if v does not point to one.
+func dynamicTarget(v reflect.Value) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+	t := v.Type()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t != valueType {
+		return reflect.Value{}
+	}
+	_, pv := indirect(v)
+	return pv
+}
+
+// valueAssignment returns the ValueAssignment v points to, allocating
+// pointers as needed, or the zero Value if v does not point to one.
+func valueAssignment(v reflect.Value) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+	t := v.Type()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t != valueAssignmentType {
+		return reflect.Value{}
+	}
+	_, pv := indirect(v)
+	return pv
+}
+
+// typeReference reads the type of a value assignment, which began with the
+// last opcode, up to and including "::=". It returns the words of the type
+// separated by single spaces, such as "OCTET STRING".
+func (d *decodeState) typeReference() string {
+	b := []byte{d.data[d.readIndex()]}
+	space := false
+	for {
+		d.scanNext()
+		switch d.opcode {
+		case scanAssignment:
+			return strings.TrimRight(string(b), " :")
+		case scanSkipSpace:
+			if !space {
+				// A hyphen before a comment is not part of the word.
+				b = bytes.TrimRight(b, "-")
+			}
+			space = true
+		case scanContinue:
+			if space {
+				b = append(b, ' ')
+				space = false
+			}
+			b = append(b, d.data[d.readIndex()])
+		}
+	}
+}
+
+// name reads the identifier that began with the last opcode. It leaves
+// d.opcode at the first opcode after the identifier that is not space.
+func (d *decodeState) name() []byte {
+	start := d.readIndex()
+	d.scanWhile(scanContinue)
+	item := trimName(d.data[start:d.readIndex()])
+	if d.opcode == scanSkipSpace {
+		d.scanWhile(scanSkipSpace)
+	}
+	return item
+}
+
+// trimName drops the hyphen left at the end of an identifier that is
+// directly followed by a comment, as in "usim-- comment".
+func trimName(item []byte) []byte {
+	for len(item) > 0 && item[len(item)-1] == '-' {
+		item = item[:len(item)-1]
+	}
+	return item
+}
+
+// isName reports whether the literal item is an identifier or keyword
+// rather than a number or string: it begins with a letter, or under
+// DialectLenient with digits that a letter or underscore follows.
+func (d *decodeState) isName(item []byte) bool {
+	if isLetter(item[0]) {
+		return true
+	}
+	if d.scan.dialect != DialectLenient {
+		return false
+	}
+	i := 0
+	for i < len(item) && isDigit(item[i]) {
+		i++
+	}
+	return i > 0 && i < len(item) && (isLetter(item[i]) && item[i] != 'e' && item[i] != 'E' || item[i] == '_')
+}
+
+// literal reads the literal that began with the last opcode and returns it
+// with its offset in d.data. If the literal is an identifier followed by
+// ':', it reports that the identifier selects a CHOICE alternative and
+// leaves d.opcode at the beginning of the alternative's value. If it is
+// CONTAINING, d.opcode is left at the beginning of the value contained.
+func (d *decodeState) literal() (item []byte, start int, choice bool) {
+	start = d.readIndex()
+	if d.scan.minus && isDigit(d.data[start]) {
+		start--
+	}
+	d.scanWhile(scanContinue)
+	item = d.data[start:d.readIndex()]
+	if d.isName(item) {
+		item = trimName(item)
+		if d.opcode == scanSkipSpace {
+			d.scanWhile(scanSkipSpace)
+		}
+		if d.opcode == scanChoiceTag {
+			d.scanWhile(scanSkipSpace)
+			return item, start, true
+		}
+	}
+	return item, start, false
+}
+
+// value consumes an ASN.1 value from d.data[d.off-1:], decoding into v, and
+// reads the following byte ahead. If v is invalid, the value is discarded.
+// The first byte of the value has been read already.
+func (d *decodeState) value(v reflect.Value) error {
+	if d.ctx != nil {
+		if d.values++; d.values%ctxCheckValues == 0 {
+			if err := d.ctx.Err(); err != nil {
+				return err
+			}
+		}
+	}
+	if dv := dynamicTarget(v); dv.IsValid() {
+		dv.Set(reflect.ValueOf(d.dynamicValue()))
+		return nil
+	}
+	switch d.opcode {
+	default:
+		panic(phasePanicMsg)
+
+	case scanBeginObject:
+		if v.IsValid() {
+			if err := d.object(v); err != nil {
+				return err
+			}
+		} else {
+			d.skip()
+		}
+		d.scanNext()
+
+	case scanBeginLiteral:
+		item, start, choice := d.literal()
+		if choice {
+			return d.choice(start, item, v)
+		}
+		if string(item) == containingKeyword {
+			return d.containing(start, v)
+		}
+		if v.IsValid() {
+			if err := d.literalStore(item, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// indirect walks down v allocating pointers as needed,
+// until it gets to a non-pointer.
+// If it encounters an Unmarshaler, indirect stops and returns that.
+func indirect(v reflect.Value) (Unmarshaler, reflect.Value) {
+	// Issue #24153 indicates that it is generally not a guaranteed property
+	// that you may round-trip a reflect.Value by calling Value.Addr().Elem()
+	// and expect the value to still be settable for values derived from
+	// unexported embedded struct fields.
+	//
+	// The logic below effectively does this when it first addresses the value
+	// (to satisfy possible pointer methods) and continues to dereference
+	// subsequent pointers as necessary.
+	//
+	// After the first round-trip, we set v back to the original value to
+	// preserve the original RW flags contained in reflect.Value.
+	v0 := v
+	haveAddr := false
+
+	// If v is a named type and is addressable,
+	// start with its address, so that if the type has pointer methods,
+	// we find them.
+	if v.Kind() != reflect.Pointer && v.Type().Name() != "" && v.CanAddr() {
+		haveAddr = true
+		v = v.Addr()
+	}
+	for {
+		// Load value from interface, but only if the result will be
+		// usefully addressable.
+		if v.Kind() == reflect.Interface && !v.IsNil() {
+			e := v.Elem()
+			if e.Kind() == reflect.Pointer && !e.IsNil() {
+				haveAddr = false
+				v = e
+				continue
+			}
+		}
+
+		if v.Kind() != reflect.Pointer {
+			break
+		}
+
+		// Prevent infinite loop if v is an interface pointing to its own address:
+		//     var v interface{}
+		//     v = &v
+		if v.Elem().Kind() == reflect.Interface && v.Elem().Elem() == v {
+			v = v.Elem()
+			break
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if v.Type().NumMethod() > 0 && v.CanInterface() {
+			if u, ok := v.Interface().(Unmarshaler); ok {
+				return u, reflect.Value{}
+			}
+		}
+
+		if haveAddr {
+			v = v0 // restore original value after round-trip Value.Addr().Elem()
+			haveAddr = false
+		} else {
+			v = v.Elem()
+		}
+	}
+	return nil, v
+}
+
+// object consumes a brace-delimited value from d.data[d.off-1:], decoding
+// into v. The first byte ('{') of the object has been read already.
+func (d *decodeState) object(v reflect.Value) error {
+	// Check for unmarshaler.
+	u, pv := indirect(v)
+	if u != nil {
+		start := d.readIndex()
+		d.skip()
+		return d.unmarshaler(u, d.data[start:d.off])
+	}
+	v = pv
+	t := v.Type()
+
+	// Decoding into nil interface? Switch to non-reflect code.
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		// An object that does not decode, such as an OBJECT IDENTIFIER
+		// with an arc out of range, is nil and has saved an error.
+		if oi := d.objectInterface(); oi != nil {
+			v.Set(reflect.ValueOf(oi))
+		} else {
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+	}
+
+	if t == bitStringType {
+		return d.namedBits(v)
+	}
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map:
+		if t == bigIntType {
+			break
+		}
+		return d.components(v)
+	case reflect.Slice, reflect.Array:
+		return d.elements(v)
+	case reflect.Float32, reflect.Float64:
+		return d.real(v)
+	}
+	d.saveError(&UnmarshalTypeError{Value: "object", Type: t, Offset: int64(d.off)})
+	d.skip()
+	return nil
+}
+
+// namedBits decodes the named bit list whose opening brace has been read,
+// such as { bitA, bitC }, into the BitString v, by the named bits of the
+// field being decoded.
+func (d *decodeState) namedBits(v reflect.Value) error {
+	start := d.readIndex()
+	var names []string
+	d.scanWhile(scanSkipSpace)
+	for d.opcode != scanEndObject {
+		kind, name, start := d.elementHead()
+		if kind == elementName {
+			names = append(names, string(name))
+		} else {
+			d.saveError(&UnmarshalTypeError{Value: "named bit list element", Type: v.Type(), Offset: int64(start)})
+			if err := d.value(reflect.Value{}); err != nil {
+				return err
+			}
+		}
+		d.nextElement()
+	}
+	bs, err := namedBits(d.named, names)
+	if err != nil {
+		d.saveError(&UnmarshalTypeError{Value: err.Error(), Type: v.Type(), Offset: int64(start)})
+		return nil
+	}
+	v.Set(reflect.ValueOf(bs))
+	return nil
+}
+
+// Kinds of elements of a brace-delimited value, as reported by elementHead.
+const (
+	elementValue      = iota // a value; d.opcode begins it
+	elementName              // a lone identifier, which is the element value
+	elementComponent         // "identifier value"; d.opcode begins the value
+	elementChoice            // "identifier : value"; d.opcode begins the value
+	elementContaining        // "CONTAINING value"; d.opcode begins the value
+)
+
+// elementHead reads the identifier at the beginning of an element, if there
+// is one, and reports what kind of element it is together with the
+// identifier and the offset of the element in d.data.
+func (d *decodeState) elementHead() (kind int, name []byte, start int) {
+	start = d.readIndex()
+	switch {
+	case d.opcode == scanBeginIdentifier:
+	case d.opcode == scanBeginLiteral && !d.scan.minus && isDigit(d.data[start]) && d.isName(d.data[start:]):
+		// An identifier that begins with digits, under DialectLenient.
+	default:
+		return elementValue, nil, start
+	}
+	name = d.name()
+	switch d.opcode {
+	case scanChoiceTag:
+		d.scanWhile(scanSkipSpace)
+		return elementChoice, name, start
+	case scanObjectValue, scanEndObject:
+		return elementName, name, start
+	}
+	if string(name) == containingKeyword {
+		return elementContaining, name, start
+	}
+	return elementComponent, name, start
+}
+
+// nextElement moves past the separator after an element of a
+// brace-delimited value. It leaves d.opcode at the beginning of the next
+// element, or at scanEndObject. It reports whether the next element
+// followed without a separating comma, as object identifier components do.
+func (d *decodeState) nextElement() bool {
+	if d.opcode == scanSkipSpace {
+		d.scanWhile(scanSkipSpace)
+	}
+	switch d.opcode {
+	case scanObjectValue:
+		d.scanWhile(scanSkipSpace)
+	case scanEndObject:
+	case scanBeginLiteral:
+		return true
+	default:
+		panic(phasePanicMsg)
+	}
+	return false
+}
+
+// components decodes the components (or CHOICE alternatives) of a
+// SEQUENCE or SET value into the struct or map v.
+func (d *decodeState) components(v reflect.Value) error {
+	t := v.Type()
+	var fields structFields
+	if v.Kind() == reflect.Map {
+		if t.Key().Kind() != reflect.String {
+			d.saveError(&UnmarshalTypeError{Value: "object", Type: t, Offset: int64(d.off)})
+			d.skip()
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(t))
+		}
+	} else {
+		fields = cachedTypeFields(t)
+	}
+
+	var seen map[string]bool
+	if d.noDuplicates {
+		seen = make(map[string]bool)
+	}
+	d.scanWhile(scanSkipSpace)
+	for d.opcode != scanEndObject {
+		kind, name, start := d.elementHead()
+		switch kind {
+		case elementComponent, elementChoice:
+			if seen != nil {
+				if seen[string(name)] {
+					d.saveError(&DuplicateComponentError{Name: string(name), Offset: int64(start)})
+				}
+				seen[string(name)] = true
+			}
+			if err := d.component(v, &fields, name); err != nil {
+				return err
+			}
+		case elementName:
+			d.saveError(&UnmarshalTypeError{Value: "identifier " + string(name), Type: t, Offset: int64(start)})
+		default:
+			d.saveError(&UnmarshalTypeError{Value: "element without identifier", Type: t, Offset: int64(start)})
+			if err := d.value(reflect.Value{}); err != nil {
+				return err
+			}
+		}
+		d.nextElement()
+	}
+	return nil
+}
+
+// component decodes the value of the component or CHOICE alternative name,
+// which begins with the current opcode, into the struct or map v. fields
+// holds the fields of a struct v.
+func (d *decodeState) component(v reflect.Value, fields *structFields, name []byte) error {
+	t := v.Type()
+	if v.Kind() == reflect.Map {
+		elem := reflect.New(t.Elem()).Elem()
+		if err := d.value(elem); err != nil {
+			return err
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(t))
+		}
+		v.SetMapIndex(reflect.ValueOf(d.str(name)).Convert(t.Key()), elem)
+		return nil
+	}
+
+	f := fields.byName(name)
+	if f == nil {
+		// Unknown component; skip it.
+		return d.value(reflect.Value{})
+	}
+	subv := d.fieldValue(v, f)
+
+	var origErrorContext errorContext
+	if d.errorContext == nil {
+		d.errorContext = new(errorContext)
+	}
+	origErrorContext = *d.errorContext
+	d.errorContext.FieldStack = append(d.errorContext.FieldStack, f.name)
+	d.errorContext.Struct = t
+
+	utcTime, intFromHex, named := d.utcTime, d.intFromHex, d.named
+	d.utcTime, d.intFromHex, d.named = f.options.Contains("utc"), f.options.Contains("intfromhex"), f.named
+	if err := d.value(subv); err != nil {
+		return err
+	}
+	d.utcTime, d.intFromHex, d.named = utcTime, intFromHex, named
+
+	// Reset errorContext to its original state.
+	// Keep the same underlying array for FieldStack, to reuse the
+	// space and avoid unnecessary allocs.
+	d.errorContext.FieldStack = d.errorContext.FieldStack[:len(origErrorContext.FieldStack)]
+	d.errorContext.Struct = origErrorContext.Struct
+	return nil
+}
+
+// fieldValue returns the field f of the struct v, allocating the embedded
+// pointers on the way to it, or the zero Value if it cannot be set.
+func (d *decodeState) fieldValue(v reflect.Value, f *field) reflect.Value {
+	for _, i := range f.index {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				// If a struct embeds a pointer to an unexported type,
+				// it is not possible to set a newly allocated value
+				// since the field is unexported.
+				//
+				// See https://golang.org/issue/21357
+				if !v.CanSet() {
+					d.saveError(fmt.Errorf("asn1go: cannot set embedded pointer to unexported struct: %v", v.Type().Elem()))
+					// The zero Value makes d.value skip over the value
+					// without assigning it.
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v
+}
+
+// elements decodes the elements of a SEQUENCE OF, SET OF or OBJECT
+// IDENTIFIER value into the slice or array v.
+func (d *decodeState) elements(v reflect.Value) error {
+	i := 0
+	d.scanWhile(scanSkipSpace)
+	for d.opcode != scanEndObject {
+		// Expand slice length, growing the slice if necessary.
+		if v.Kind() == reflect.Slice {
+			if i >= v.Cap() {
+				newcap := v.Cap() + v.Cap()/2
+				if newcap < 4 {
+					newcap = 4
+				}
+				newv := reflect.MakeSlice(v.Type(), v.Len(), newcap)
+				reflect.Copy(newv, v)
+				v.Set(newv)
+			}
+			if i >= v.Len() {
+				v.SetLen(i + 1)
+			}
+		}
+
+		var ev reflect.Value
+		if i < v.Len() {
+			ev = v.Index(i)
+		}
+		// Otherwise we ran out of a fixed array and ev stays invalid,
+		// which skips the element.
+
+		kind, name, start := d.elementHead()
+		switch kind {
+		case elementName:
+			if ev.IsValid() {
+				if err := d.literalStore(name, ev); err != nil {
+					return err
+				}
+			}
+		case elementValue:
+			if err := d.value(ev); err != nil {
+				return err
+			}
+		case elementContaining:
+			if err := d.containing(start, ev); err != nil {
+				return err
+			}
+		default:
+			// An element of a SEQUENCE OF CHOICE.
+			if err := d.choice(start, name, ev); err != nil {
+				return err
+			}
+		}
+		i++
+		d.nextElement()
+	}
+
+	if i < v.Len() {
+		if v.Kind() == reflect.Array {
+			// Array. Zero the rest.
+			z := reflect.Zero(v.Type().Elem())
+			for ; i < v.Len(); i++ {
+				v.Index(i).Set(z)
+			}
+		} else {
+			v.SetLen(i)
+		}
+	}
+	if i == 0 && v.Kind() == reflect.Slice {
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+	}
+	return nil
+}
+
+// choice decodes the value of the CHOICE alternative name, which begins
+// with the current opcode, into v. start is the offset of name in d.data.
+func (d *decodeState) choice(start int, name []byte, v reflect.Value) error {
+	if !v.IsValid() {
+		return d.value(v)
+	}
+	if ok, err := d.registeredValue(string(name), v); ok {
+		return err
+	}
+	u, pv := indirect(v)
+	if u != nil {
+		if err := d.value(reflect.Value{}); err != nil {
+			return err
+		}
+		return d.unmarshaler(u, bytes.TrimRight(d.data[start:d.readIndex()], " \t\r\n\f\v"))
+	}
+	v = pv
+
+	openType := isOpenType(name)
+	kind := v.Kind()
+	if v.Type() == bigIntType {
+		kind = reflect.Invalid // a number, not a SEQUENCE
+	}
+	switch kind {
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			break
+		}
+		if openType {
+			v.Set(reflect.ValueOf(OpenTypeValue{Type: string(name), Value: d.valueInterface()}))
+			return nil
+		}
+		v.Set(reflect.ValueOf(map[string]interface{}{string(name): d.valueInterface()}))
+		return nil
+	case reflect.Struct:
+		switch v.Type() {
+		case choiceValueType:
+			v.Field(0).SetString(string(name))
+			return d.value(v.Field(1))
+		case openTypeValueType:
+			v.Field(0).SetString(string(name))
+			if ok, err := d.registeredValue(string(name), v.Field(1)); ok {
+				return err
+			}
+			return d.value(v.Field(1))
+		}
+		fields := cachedTypeFields(v.Type())
+		if openType && !fields.choice && fields.byName(name) == nil {
+			// The value of an open type, Type : Value, of a SEQUENCE
+			// or SET type.
+			return d.value(v)
+		}
+		if d.keepUnknown && fields.byName(name) == nil {
+			if f := fields.byName([]byte("*")); f != nil && f.typ == rawChoiceType {
+				return d.rawChoice(v, f, name)
+			}
+		}
+		return d.component(v, &fields, name)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		return d.component(v, nil, name)
+	}
+	if openType {
+		return d.value(v)
+	}
+	d.saveError(&UnmarshalTypeError{Value: "CHOICE value", Type: v.Type(), Offset: int64(start)})
+	return d.value(reflect.Value{})
+}
+
+// registeredValue decodes the value that d.opcode begins into a new value
+// of the Go type d.registry holds for name, and stores it in the interface
+// v is or points to. It reports false, and decodes nothing, if there is
+// no such type or v cannot hold it, see registered.
+func (d *decodeState) registeredValue(name string, v reflect.Value) (bool, error) {
+	iface, nv, ok := d.registered(name, v)
+	if !ok {
+		return false, nil
+	}
+	if err := d.value(nv); err != nil {
+		return true, err
+	}
+	iface.Set(nv)
+	return true, nil
+}
+
+// isOpenType reports whether the name before the ':' of Name : Value is
+// the type of an open type value, such as INTEGER or Module.Type, rather
+// than a CHOICE alternative: it begins with an upper case letter.
+func isOpenType(name []byte) bool {
+	return len(name) > 0 && 'A' <= name[0] && name[0] <= 'Z'
+}
+
+// rawChoice stores the CHOICE value of the alternative name, whose value
+// d.opcode begins, as a RawChoice in the field f of the struct v.
+func (d *decodeState) rawChoice(v reflect.Value, f *field, name []byte) error {
+	start := d.readIndex()
+	if err := d.value(reflect.Value{}); err != nil {
+		return err
+	}
+	raw := bytes.TrimRight(d.data[start:d.readIndex()], " \t\r\n\f\v")
+	fv := d.fieldValue(v, f)
+	if !fv.IsValid() {
+		return nil
+	}
+	_, fv = indirect(fv)
+	fv.Set(reflect.ValueOf(RawChoice{Alternative: string(name), Value: append(RawValue(nil), raw...)}))
+	return nil
+}
+
+// containing decodes the value that a containing value, CONTAINING value,
+// holds the encoding of into v: into the Value of a Containing, as a
+// Containing into an empty interface and otherwise into v itself, so that
+// it reads as the value contained. d.opcode begins the value contained and
+// start is the offset of CONTAINING in d.data.
+func (d *decodeState) containing(start int, v reflect.Value) error {
+	if !v.IsValid() {
+		return d.value(v)
+	}
+	u, pv := indirect(v)
+	if u != nil {
+		if err := d.value(reflect.Value{}); err != nil {
+			return err
+		}
+		return d.unmarshaler(u, bytes.TrimRight(d.data[start:d.readIndex()], " \t\r\n\f\v"))
+	}
+	v = pv
+	switch {
+	case v.Type() == containingType:
+		return d.value(v.Field(0))
+	case v.Kind() == reflect.Interface && v.NumMethod() == 0:
+		v.Set(reflect.ValueOf(Containing{Value: d.valueInterface()}))
+		return nil
+	}
+	return d.value(v)
+}
+
+// str returns b as a string, which shares the memory of b in zero-copy
+// mode.
+func (d *decodeState) str(b []byte) string {
+	if d.zeroCopy {
+		return *(*string)(unsafe.Pointer(&b))
+	}
+	return string(b)
+}
+
+// cstring returns the text of the cstring item, which shares the memory
+// of item in zero-copy mode if it needs no unquoting.
+func (d *decodeState) cstring(item []byte) string {
+	if text := item[1 : len(item)-1]; d.zeroCopy && bytes.IndexByte(text, '"') < 0 && bytes.IndexAny(text, "\r\n") < 0 {
+		return d.str(text)
+	}
+	return unquoteCString(item)
+}
+
+// unmarshaler calls the UnmarshalASN1 method of u with data, except that
+// in zero-copy mode a RawValue is set to data itself.
+func (d *decodeState) unmarshaler(u Unmarshaler, data []byte) error {
+	if m, ok := u.(*RawValue); ok && m != nil && d.zeroCopy {
+		*m = data[:len(data):len(data)]
+		return nil
+	}
+	return u.UnmarshalASN1(data)
+}
+
+// literalStore decodes a literal stored in item into v.
+func (d *decodeState) literalStore(item []byte, v reflect.Value) error {
+	// Check for unmarshaler.
+	if len(item) == 0 {
+		panic(phasePanicMsg)
+	}
+	u, pv := indirect(v)
+	if u != nil {
+		return d.unmarshaler(u, item)
+	}
+	v = pv
+	if val, ok, err := d.resolveLiteral(item, v.Type()); ok || err != nil {
+		if err != nil {
+			return err
+		}
+		d.storeResolved(item, val, v)
+		return nil
+	}
+
+	switch c := item[0]; {
+	case c == '\'': // hstring or bstring
+		d.hexStringStore(item, v)
+
+	case c == '"': // cstring
+		s := d.cstring(item)
+		if v.Type() == timeType {
+			k := KindGeneralizedTime
+			if d.utcTime {
+				k = KindUTCTime
+			}
+			t, err := parseTime(k, s)
+			if err != nil {
+				d.saveError(err)
+				break
+			}
+			v.Set(reflect.ValueOf(t))
+			break
+		}
+		if k, ok := dateKind(v.Type()); ok {
+			val, err := parseDate(k, s)
+			if err != nil {
+				d.saveError(err)
+				break
+			}
+			v.Set(reflect.ValueOf(val))
+			break
+		}
+		if tu, ok := textUnmarshaler(v); ok && v.Type() != bigIntType {
+			if err := tu.UnmarshalText([]byte(s)); err != nil {
+				d.saveError(err)
+			}
+			break
+		}
+		switch v.Kind() {
+		default:
+			d.saveError(&UnmarshalTypeError{Value: "cstring", Type: v.Type(), Offset: int64(d.readIndex())})
+		case reflect.String:
+			v.SetString(s)
+		case reflect.Slice:
+			if v.Type().Elem().Kind() != reflect.Uint8 {
+				d.saveError(&UnmarshalTypeError{Value: "cstring", Type: v.Type(), Offset: int64(d.readIndex())})
+				break
+			}
+			v.SetBytes([]byte(s))
+		case reflect.Interface:
+			if v.NumMethod() != 0 {
+				d.saveError(&UnmarshalTypeError{Value: "cstring", Type: v.Type(), Offset: int64(d.readIndex())})
+				break
+			}
+			v.Set(reflect.ValueOf(s))
+		}
+
+	case c == '-' || isDigit(c) && !d.isName(item): // number
+		d.numberStore(string(item), v)
+
+	default: // keyword or identifier
+		if sub := d.reference(item); sub != nil {
+			return d.referenceStore(sub, v)
+		}
+		d.nameStore(d.str(item), v)
+	}
+	return nil
+}
+
+// resolveLiteral passes item to the LiteralResolver registered for its
+// kind, if any, to resolve it to a value of type t. It reports false if
+// there is none or it leaves item to d.
+func (d *decodeState) resolveLiteral(item []byte, t reflect.Type) (interface{}, bool, error) {
+	if d.resolvers == nil {
+		return nil, false, nil
+	}
+	fn := d.resolvers[d.literalKind(item)]
+	if fn == nil {
+		return nil, false, nil
+	}
+	return fn(item, t)
+}
+
+// storeResolved stores the value val that a LiteralResolver resolved the
+// literal item to in v. It must be assignable to the type of v, or
+// convertible to it with the same kind, or a signed integer that fits v;
+// nil stores the zero value.
+func (d *decodeState) storeResolved(item []byte, val interface{}, v reflect.Value) {
+	if val == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return
+	}
+	rv := reflect.ValueOf(val)
+	switch {
+	case rv.Type().AssignableTo(v.Type()):
+		v.Set(rv)
+	case rv.Kind() == v.Kind() && rv.Type().ConvertibleTo(v.Type()):
+		v.Set(rv.Convert(v.Type()))
+	case isIntKind(rv.Kind()) && isIntKind(v.Kind()) && !v.OverflowInt(rv.Int()):
+		v.SetInt(rv.Int())
+	default:
+		d.saveError(&UnmarshalTypeError{Value: "literal " + string(item) + " resolved to " + rv.Type().String(), Type: v.Type(), Offset: int64(d.readIndex())})
+	}
+}
+
+var emptyInterfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+// isIntKind reports whether k is the kind of a signed integer.
+func isIntKind(k reflect.Kind) bool {
+	return reflect.Int <= k && k <= reflect.Int64
+}
+
+// textUnmarshaler returns the encoding.TextUnmarshaler that the address
+// of v implements, if any.
+func textUnmarshaler(v reflect.Value) (encoding.TextUnmarshaler, bool) {
+	if v.Kind() == reflect.Interface || !v.CanAddr() || !v.Addr().CanInterface() {
+		return nil, false
+	}
+	tu, ok := v.Addr().Interface().(encoding.TextUnmarshaler)
+	return tu, ok
+}
+
+// hexStringStore stores the hstring or bstring item in v.
+func (d *decodeState) hexStringStore(item []byte, v reflect.Value) {
+	digits, kind := stringDigits(item)
+	if kind == 'B' {
+		bs := parseBitString(digits)
+		switch {
+		case v.Type() == bitStringType:
+			v.Set(reflect.ValueOf(bs))
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+			v.SetBytes(bs.Bytes)
+		case v.Kind() == reflect.String:
+			v.SetString(d.str(digits))
+		case v.Kind() == reflect.Interface && v.NumMethod() == 0:
+			v.Set(reflect.ValueOf(bs))
+		default:
+			d.saveError(&UnmarshalTypeError{Value: "bstring", Type: v.Type(), Offset: int64(d.readIndex())})
+		}
+		return
+	}
+
+	bits := v.Type() == bitStringType
+	digits, err := d.hexDigits(digits, bits)
+	if err != nil {
+		d.saveError(err)
+		return
+	}
+	if v.Kind() == reflect.String {
+		v.SetString(d.str(upperHex(digits)))
+		return
+	}
+	b, err := decodeHex(digits)
+	if err != nil {
+		if !bits {
+			d.saveError(err)
+			return
+		}
+		// The odd number of digits of a BIT STRING value that
+		// HexPadRight keeps, each four bits of it.
+		b, _ = decodeHex(append(digits[:len(digits):len(digits)], '0'))
+	}
+	switch {
+	case bits:
+		v.Set(reflect.ValueOf(BitString{Bytes: b, BitLength: len(digits) * 4}))
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		v.SetBytes(b)
+	case v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8:
+		if v.Len() != len(b) {
+			d.saveError(&UnmarshalTypeError{Value: "hstring of " + strconv.Itoa(len(b)) + " octets", Type: v.Type(), Offset: int64(d.readIndex())})
+			break
+		}
+		reflect.Copy(v, reflect.ValueOf(b))
+	case v.Kind() == reflect.Interface && v.NumMethod() == 0:
+		v.Set(reflect.ValueOf(b))
+	case d.intFromHex:
+		d.hexIntegerStore(b, v)
+	default:
+		d.saveError(&UnmarshalTypeError{Value: "hstring", Type: v.Type(), Offset: int64(d.readIndex())})
+	}
+}
+
+// hexIntegerStore stores the octets b of an hstring in the integer v, as
+// an unsigned big-endian number, for a field with the "intfromhex" tag
+// option.
+func (d *decodeState) hexIntegerStore(b []byte, v reflect.Value) {
+	if v.Type() == bigIntType {
+		bigIntOf(v).SetBytes(b)
+		return
+	}
+	var n uint64
+	overflow := false
+	for _, c := range b {
+		overflow = overflow || n>>56 != 0
+		n = n<<8 | uint64(c)
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if overflow || n > math.MaxInt64 || v.OverflowInt(int64(n)) {
+			break
+		}
+		v.SetInt(int64(n))
+		return
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if overflow || v.OverflowUint(n) {
+			break
+		}
+		v.SetUint(n)
+		return
+	}
+	d.saveError(&UnmarshalTypeError{Value: "hstring of " + strconv.Itoa(len(b)) + " octets", Type: v.Type(), Offset: int64(d.readIndex())})
+}
+
+// stringDigits returns the digits of the bstring or hstring item with any
+// white space between them removed, and its B or H suffix.
+func stringDigits(item []byte) (digits []byte, kind byte) {
+	digits, kind = item[1:len(item)-2], item[len(item)-1]
+	for i, c := range digits {
+		if isSpace(c) {
+			stripped := append([]byte(nil), digits[:i]...)
+			for _, c := range digits[i+1:] {
+				if !isSpace(c) {
+					stripped = append(stripped, c)
+				}
+			}
+			return stripped, kind
+		}
+	}
+	return digits, kind
+}
+
+// decodeHex decodes the hexadecimal digits of an hstring.
+func decodeHex(digits []byte) ([]byte, error) {
+	b := make([]byte, hex.DecodedLen(len(digits)))
+	if _, err := hex.Decode(b, digits); err != nil {
+		if errors.Is(err, hex.ErrLength) {
+			return nil, fmt.Errorf("asn1go: hstring '%s'H has an odd number of digits", digits)
+		}
+		return nil, fmt.Errorf("asn1go: invalid hstring '%s'H: %v", digits, err)
+	}
+	return b, nil
+}
+
+// A HexPolicy says how a Decoder reads an hstring with an odd number of
+// digits, such as 'ABC'H, which profiles in the wild contain.
+type HexPolicy int
+
+const (
+	// HexReject reports an odd number of digits as an error. It is the
+	// default.
+	HexReject HexPolicy = iota
+
+	// HexPadLeft reads the digits as if a 0 came before them, as those
+	// of a number: 'ABC'H is the octets 0A BC.
+	HexPadLeft
+
+	// HexPadRight reads the digits by the rules of X.680: as if a 0
+	// came after them for an OCTET STRING value, 'ABC'H is AB C0, and
+	// as four bits each for a BIT STRING value, 'ABC'H is 12 bits long.
+	HexPadRight
+)
+
+// hexDigits returns the digits of an hstring, padded by the HexPolicy of
+// d if there is an odd number of them. Under HexPadRight, the digits of
+// a BIT STRING value, bits, stay as they are.
+func (d *decodeState) hexDigits(digits []byte, bits bool) ([]byte, error) {
+	if len(digits)%2 == 0 {
+		return digits, nil
+	}
+	switch d.hexPolicy {
+	case HexPadLeft:
+		return append([]byte{'0'}, digits...), nil
+	case HexPadRight:
+		if bits {
+			return digits, nil
+		}
+		return append(digits[:len(digits):len(digits)], '0'), nil
+	}
+	return nil, fmt.Errorf("asn1go: hstring '%s'H has an odd number of digits", digits)
+}
+
+// upperHex returns the hexadecimal digits in upper case, the form Marshal
+// writes, as the value of a string.
+func upperHex(digits []byte) []byte {
+	for i, c := range digits {
+		if 'a' <= c && c <= 'f' {
+			upper := append([]byte(nil), digits...)
+			for j := i; j < len(upper); j++ {
+				if c := upper[j]; 'a' <= c && c <= 'f' {
+					upper[j] = c - 'a' + 'A'
+				}
+			}
+			return upper
+		}
+	}
+	return digits
+}
+
+// numberStore stores the number literal s in v.
+func (d *decodeState) numberStore(s string, v reflect.Value) {
+	if v.Type() == bigIntType {
+		n, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			d.saveError(&UnmarshalTypeError{Value: "number " + s, Type: v.Type(), Offset: int64(d.readIndex())})
+			return
+		}
+		bigIntOf(v).Set(n)
+		return
+	}
+	switch v.Kind() {
+	default:
+		d.saveError(&UnmarshalTypeError{Value: "number", Type: v.Type(), Offset: int64(d.readIndex())})
+	case reflect.Interface:
+		n, err := d.convertNumber(s)
+		if err != nil {
+			d.saveError(err)
+			break
+		}
+		if v.NumMethod() != 0 {
+			d.saveError(&UnmarshalTypeError{Value: "number", Type: v.Type(), Offset: int64(d.readIndex())})
+			break
+		}
+		v.Set(reflect.ValueOf(n))
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || v.OverflowInt(n) {
+			d.saveError(&UnmarshalTypeError{Value: "number " + s, Type: v.Type(), Offset: int64(d.readIndex())})
+			break
+		}
+		v.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil || v.OverflowUint(n) {
+			d.saveError(&UnmarshalTypeError{Value: "number " + s, Type: v.Type(), Offset: int64(d.readIndex())})
+			break
+		}
+		v.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil || v.OverflowFloat(n) {
+			d.saveError(&UnmarshalTypeError{Value: "number " + s, Type: v.Type(), Offset: int64(d.readIndex())})
+			break
+		}
+		v.SetFloat(n)
+
+	case reflect.String:
+		if v.Type() != numberType {
+			d.saveError(&UnmarshalTypeError{Value: "number", Type: v.Type(), Offset: int64(d.readIndex())})
+			break
+		}
+		v.SetString(s)
+	}
+}
+
+// nameStore stores the keyword or identifier s in v.
+func (d *decodeState) nameStore(s string, v reflect.Value) {
+	switch s {
+	case "NULL":
+		switch v.Kind() {
+		case reflect.Interface:
+			v.Set(reflect.Zero(v.Type()))
+		case reflect.Bool:
+			v.SetBool(true)
+		case reflect.Struct:
+			if v.NumField() == 0 {
+				break
+			}
+			fallthrough
+		default:
+			d.saveError(&UnmarshalTypeError{Value: "NULL", Type: v.Type(), Offset: int64(d.readIndex())})
+		}
+
+	case "TRUE", "FALSE":
+		value := s == "TRUE"
+		switch v.Kind() {
+		default:
+			d.saveError(&UnmarshalTypeError{Value: "boolean", Type: v.Type(), Offset: int64(d.readIndex())})
+		case reflect.Bool:
+			v.SetBool(value)
+		case reflect.Interface:
+			if v.NumMethod() != 0 {
+				d.saveError(&UnmarshalTypeError{Value: "boolean", Type: v.Type(), Offset: int64(d.readIndex())})
+				break
+			}
+			v.Set(reflect.ValueOf(value))
+		}
+
+	default:
+		if f, ok := specialReal(s); ok {
+			switch v.Kind() {
+			default:
+				d.saveError(&UnmarshalTypeError{Value: s, Type: v.Type(), Offset: int64(d.readIndex())})
+			case reflect.Float32, reflect.Float64:
+				v.SetFloat(f)
+			case reflect.Interface:
+				if v.NumMethod() != 0 {
+					d.saveError(&UnmarshalTypeError{Value: s, Type: v.Type(), Offset: int64(d.readIndex())})
+					break
+				}
+				v.Set(reflect.ValueOf(f))
+			}
+			return
+		}
+
+		// An identifier, such as an enumerated value.
+		n, named := namedValue(d.named, s)
+		switch v.Kind() {
+		default:
+			d.saveError(&UnmarshalTypeError{Value: "identifier " + s, Type: v.Type(), Offset: int64(d.readIndex())})
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if !named || v.OverflowInt(n) {
+				d.saveError(&UnmarshalTypeError{Value: "identifier " + s, Type: v.Type(), Offset: int64(d.readIndex())})
+				break
+			}
+			v.SetInt(n)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if !named || n < 0 || v.OverflowUint(uint64(n)) {
+				d.saveError(&UnmarshalTypeError{Value: "identifier " + s, Type: v.Type(), Offset: int64(d.readIndex())})
+				break
+			}
+			v.SetUint(uint64(n))
+		case reflect.String:
+			v.SetString(s)
+		case reflect.Interface:
+			if v.NumMethod() != 0 {
+				d.saveError(&UnmarshalTypeError{Value: "identifier " + s, Type: v.Type(), Offset: int64(d.readIndex())})
+				break
+			}
+			v.Set(reflect.ValueOf(s))
+		}
+	}
+}
+
+// The xxxInterface routines build up a value to be stored
+// in an empty interface. They are not strictly necessary,
+// but they avoid the weight of reflection in this common case.
+
+// valueInterface is like value but returns interface{}.
+func (d *decodeState) valueInterface() (val interface{}) {
+	switch d.opcode {
+	default:
+		panic(phasePanicMsg)
+	case scanBeginObject:
+		val = d.objectInterface()
+		d.scanNext()
+	case scanBeginLiteral:
+		item, _, choice := d.literal()
+		if choice {
+			if isOpenType(item) {
+				return OpenTypeValue{Type: string(item), Value: d.valueInterface()}
+			}
+			return map[string]interface{}{string(item): d.valueInterface()}
+		}
+		if string(item) == containingKeyword {
+			return Containing{Value: d.valueInterface()}
+		}
+		val = d.literalInterface(item)
+	}
+	return
+}
+
+// objectInterface is like object but returns OrderedObject for SEQUENCE
+// and SET values, []interface{} for SEQUENCE OF and SET OF values
+// and ObjectIdentifier for OBJECT IDENTIFIER values. Which one is decided
+// by the first element, except that a value that begins with components
+// and goes on with an element without identifier, as in { a 1, { b 2 } },
+// is a []interface{} holding the components as single-entry maps.
+func (d *decodeState) objectInterface() interface{} {
+	var obj OrderedObject
+	var list []interface{}
+	oid := false
+	d.scanWhile(scanSkipSpace)
+	for first := true; d.opcode != scanEndObject; first = false {
+		kind, name, _ := d.elementHead()
+		if first && kind == elementComponent {
+			obj = OrderedObject{}
+		}
+		if obj != nil && kind != elementComponent && kind != elementChoice {
+			for _, f := range obj {
+				list = append(list, map[string]interface{}{f.Name: f.Value})
+			}
+			obj = nil
+		}
+		switch {
+		case obj != nil:
+			obj = append(obj, Field{Name: d.str(name), Value: d.valueInterface()})
+		case kind == elementContaining:
+			list = append(list, Containing{Value: d.valueInterface()})
+		case kind == elementName:
+			list = append(list, d.literalInterface(name))
+		case kind == elementValue:
+			list = append(list, d.valueInterface())
+		case isOpenType(name):
+			list = append(list, OpenTypeValue{Type: string(name), Value: d.valueInterface()})
+		default:
+			list = append(list, map[string]interface{}{string(name): d.valueInterface()})
+		}
+		if d.nextElement() {
+			oid = true
+		}
+	}
+
+	switch {
+	case obj != nil:
+		return obj
+	case oid:
+		return d.objectIdentifierInterface(list)
+	case list != nil:
+		return list
+	}
+	return OrderedObject{}
+}
+
+// objectIdentifierInterface converts the components of an OBJECT
+// IDENTIFIER value decoded by objectInterface to an ObjectIdentifier.
+func (d *decodeState) objectIdentifierInterface(list []interface{}) interface{} {
+	oid := make(ObjectIdentifier, len(list))
+	for i, c := range list {
+		n, ok := c.(int64)
+		if !ok || int64(int(n)) != n {
+			d.saveError(&UnmarshalTypeError{Value: fmt.Sprintf("object identifier component %v", c), Type: reflect.TypeOf(oid), Offset: int64(d.readIndex())})
+			return nil
+		}
+		oid[i] = int(n)
+	}
+	return oid
+}
+
+// literalInterface consumes and returns a literal from item.
+func (d *decodeState) literalInterface(item []byte) interface{} {
+	if val, ok, err := d.resolveLiteral(item, emptyInterfaceType); ok || err != nil {
+		if err != nil {
+			d.saveError(err)
+			return nil
+		}
+		return val
+	}
+	switch c := item[0]; {
+	case c == '\'': // hstring or bstring
+		digits, kind := stringDigits(item)
+		if kind == 'B' {
+			return parseBitString(digits)
+		}
+		digits, err := d.hexDigits(digits, false)
+		var b []byte
+		if err == nil {
+			b, err = decodeHex(digits)
+		}
+		if err != nil {
+			d.saveError(err)
+			return nil
+		}
+		return b
+
+	case c == '"': // cstring
+		return d.cstring(item)
+
+	case c == '-' || isDigit(c) && !d.isName(item): // number
+		n, err := d.convertNumber(string(item))
+		if err != nil {
+			d.saveError(err)
+		}
+		return n
+	}
+
+	// keyword or identifier
+	if sub := d.reference(item); sub != nil {
+		return d.referenceInterface(sub)
+	}
+	s := d.str(item)
+	switch s {
+	case "NULL":
+		return nil
+	case "TRUE":
+		return true
+	case "FALSE":
+		return false
+	}
+	if f, ok := specialReal(s); ok {
+		return f
+	}
+	return s
+}
+
+// A Number represents an INTEGER or REAL number literal.
+type Number string
+
+// String returns the literal text of the number.
+func (n Number) String() string { return string(n) }
+
+// Float64 returns the number as a float64.
+func (n Number) Float64() (float64, error) {
+	return strconv.ParseFloat(string(n), 64)
+}
+
+// Int64 returns the number as an int64.
+func (n Number) Int64() (int64, error) {
+	return strconv.ParseInt(string(n), 10, 64)
+}
+
+// BigInt returns the number as a *big.Int, for an INTEGER of any size.
+func (n Number) BigInt() (*big.Int, error) {
+	b, ok := new(big.Int).SetString(string(n), 10)
+	if !ok {
+		return nil, &strconv.NumError{Func: "BigInt", Num: string(n), Err: strconv.ErrSyntax}
+	}
+	return b, nil
+}
+
+// convertNumber converts the number literal s to an int64, or to a
+// *big.Int if it is too large for one, or to a float64 if s is a real
+// number, or to a Number if the decoder is to use Number.
+func (d *decodeState) convertNumber(s string) (interface{}, error) {
+	if d.useNumber {
+		return Number(s), nil
+	}
+	if !strings.ContainsAny(s, ".eE") {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			if b, ok := new(big.Int).SetString(s, 10); ok {
+				return b, nil
+			}
+			return nil, &UnmarshalTypeError{Value: "number " + s, Type: reflect.TypeOf(int64(0)), Offset: int64(d.off)}
+		}
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, &UnmarshalTypeError{Value: "number " + s, Type: reflect.TypeOf(0.0), Offset: int64(d.off)}
+	}
+	return f, nil
+}
@@ -0,0 +1,642 @@
+package asn1go
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+type usimHeader struct {
+	Mandated       *struct{}
+	Identification int
+}
+
+func TestUnmarshalEmpty(t *testing.T) {
+	type inner struct{ A *int }
+	tests := []struct {
+		in   string
+		ptr  interface{} // new(type) to decode into
+		want interface{}
+	}{
+		{"{ }", new(interface{}), OrderedObject{}},
+		{"{}", new(map[string]interface{}), map[string]interface{}{}},
+		{"{ }", new([]int), []int{}},
+		{"{ }", new([0]int), [0]int{}},
+		{"{ }", new(inner), inner{}},
+		{"{ }", new(*inner), &inner{}},
+		{
+			"{ p { }, l { }, m { } }",
+			new(struct {
+				P *inner
+				L []int
+				M map[string]int
+			}),
+			struct {
+				P *inner
+				L []int
+				M map[string]int
+			}{&inner{}, []int{}, map[string]int{}},
+		},
+		{
+			"{ usim-header { mandated NULL, identification 8 } }",
+			new(struct{ UsimHeader *usimHeader }),
+			struct{ UsimHeader *usimHeader }{&usimHeader{&struct{}{}, 8}},
+		},
+	}
+	for _, tt := range tests {
+		err := Unmarshal([]byte(tt.in), tt.ptr)
+		if err != nil {
+			t.Errorf("Unmarshal(%q, %T): %v", tt.in, tt.ptr, err)
+			continue
+		}
+		if got := reflect.ValueOf(tt.ptr).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Unmarshal(%q, %T):\nhave %#v\nwant %#v", tt.in, tt.ptr, got, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalAnonymous(t *testing.T) {
+	tests := []struct {
+		in   string
+		ptr  interface{}
+		want interface{}
+		err  bool
+	}{
+		{
+			in:   "{ { } }",
+			ptr:  new(interface{}),
+			want: []interface{}{OrderedObject{}},
+		},
+		{
+			in:   "{ { a 1 }, { } }",
+			ptr:  new([]interface{}),
+			want: []interface{}{OrderedObject{{"a", int64(1)}}, OrderedObject{}},
+		},
+		{
+			in:   "{ { { a 1 } } }",
+			ptr:  new(interface{}),
+			want: []interface{}{[]interface{}{OrderedObject{{"a", int64(1)}}}},
+		},
+		{
+			in:   "{ a 1, { b 2 } }",
+			ptr:  new(interface{}),
+			want: []interface{}{map[string]interface{}{"a": int64(1)}, OrderedObject{{"b", int64(2)}}},
+		},
+		{
+			in:   "{ { a 1 }, { } }",
+			ptr:  new([]struct{ A int }),
+			want: []struct{ A int }{{1}, {0}},
+		},
+		{
+			in:   "{ { a 1 } }",
+			ptr:  new(map[string]interface{}),
+			want: map[string]interface{}{},
+			err:  true,
+		},
+		{
+			in:   "{ a 1, { b 2 } }",
+			ptr:  new(struct{ A int }),
+			want: struct{ A int }{1},
+			err:  true,
+		},
+	}
+	for _, tt := range tests {
+		err := Unmarshal([]byte(tt.in), tt.ptr)
+		if tt.err {
+			if _, ok := err.(*UnmarshalTypeError); !ok {
+				t.Errorf("Unmarshal(%q, %T): error %v, want UnmarshalTypeError", tt.in, tt.ptr, err)
+			}
+		} else if err != nil {
+			t.Errorf("Unmarshal(%q, %T): %v", tt.in, tt.ptr, err)
+			continue
+		}
+		if got := reflect.ValueOf(tt.ptr).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Unmarshal(%q, %T):\nhave %#v\nwant %#v", tt.in, tt.ptr, got, tt.want)
+		}
+	}
+}
+
+type profileHeader struct {
+	MajorVersion int                `asn1:"major-version"`
+	MinorVersion int                `asn1:"minor-version"`
+	ICCID        []byte             `asn1:"iccid"`
+	Profile      string             `asn1:"profileType,omitempty"`
+	Services     map[string]any     `asn1:"eUICC-Mandatory-services"`
+	GFSTE        []ObjectIdentifier `asn1:"eUICC-Mandatory-GFSTEList"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		in   string
+		ptr  interface{}
+		want interface{}
+	}{
+		{"5", new(int), 5},
+		{"-128", new(int8), int8(-128)},
+		{"255", new(uint8), uint8(255)},
+		{"TRUE", new(bool), true},
+		{`"a ""b"""`, new(string), `a "b"`},
+		{"'0A1B'H", new([]byte), []byte{0x0A, 0x1B}},
+		{"'101'B", new(BitString), BitString{Bytes: []byte{0xA0}, BitLength: 3}},
+		{"{ 2 23 143 1 2 1 }", new(ObjectIdentifier), ObjectIdentifier{2, 23, 143, 1, 2, 1}},
+		{"{ 1, 2, 3 }", new([]int), []int{1, 2, 3}},
+		{"{ 1, 2 }", new([3]int), [3]int{1, 2, 0}},
+		{"{ a 1, b 2 }", new(map[string]int), map[string]int{"a": 1, "b": 2}},
+		{"7", new(*int), func() *int { i := 7; return &i }()},
+		{"-- comment\n  5 -- more", new(int), 5},
+		{"5 6", new([]int), []int{5, 6}},
+		{"{ a  1 }", new(RawValue), RawValue("{ a  1 }")},
+		{
+			"{ major-version 2, minor-version 3, iccid '89'H, eUICC-Mandatory-services { usim NULL }, eUICC-Mandatory-GFSTEList { { 2 23 143 1 2 1 } } }",
+			new(profileHeader),
+			profileHeader{
+				MajorVersion: 2,
+				MinorVersion: 3,
+				ICCID:        []byte{0x89},
+				Services:     map[string]any{"usim": nil},
+				GFSTE:        []ObjectIdentifier{{2, 23, 143, 1, 2, 1}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		if err := Unmarshal([]byte(tt.in), tt.ptr); err != nil {
+			t.Errorf("Unmarshal(%q, %T): %v", tt.in, tt.ptr, err)
+			continue
+		}
+		if got := reflect.ValueOf(tt.ptr).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Unmarshal(%q, %T):\nhave %#v\nwant %#v", tt.in, tt.ptr, got, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalError(t *testing.T) {
+	var i int
+	tests := []struct {
+		in   string
+		v    interface{}
+		want interface{} // type of the error
+	}{
+		{"5", nil, &InvalidUnmarshalError{}},
+		{"5", i, &InvalidUnmarshalError{}},
+		{"5", (*int)(nil), &InvalidUnmarshalError{}},
+		{"{ a 1", &i, &SyntaxError{}},
+		{"'0G'H", &i, &SyntaxError{}},
+		{"5 6", &i, &UnmarshalTypeError{}},
+		{"TRUE", &i, &UnmarshalTypeError{}},
+		{"300", new(int8), &UnmarshalTypeError{}},
+		{"-1", new(uint), &UnmarshalTypeError{}},
+		{"{ a 1 }", new([]int), &UnmarshalTypeError{}},
+		{"{ 1 99999999999999999999999 }", new(interface{}), &UnmarshalTypeError{}},
+		{"{ 1 2 a }", new(interface{}), &SyntaxError{}},
+		{"{ a : 1 2 3 }", new(interface{}), &SyntaxError{}},
+		{"{ a : 1 2 3 }", new(ObjectIdentifier), &SyntaxError{}},
+	}
+	for _, tt := range tests {
+		err := Unmarshal([]byte(tt.in), tt.v)
+		if reflect.TypeOf(err) != reflect.TypeOf(tt.want) {
+			t.Errorf("Unmarshal(%q, %T): error %v, want %T", tt.in, tt.v, err, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalErrorOffset(t *testing.T) {
+	var v struct{ A, B int }
+	err := Unmarshal([]byte("{ a 1, b TRUE }"), &v)
+	ute, ok := err.(*UnmarshalTypeError)
+	if !ok {
+		t.Fatalf("Unmarshal: error %v, want UnmarshalTypeError", err)
+	}
+	if ute.Offset != 14 || ute.Field != "b" || v.A != 1 {
+		t.Errorf("Unmarshal: Offset %d, Field %q, A %d, want 14, \"b\", 1", ute.Offset, ute.Field, v.A)
+	}
+	err = Unmarshal([]byte("{ a 1,\n  b }}"), &v)
+	se, ok := err.(*SyntaxError)
+	if !ok {
+		t.Fatalf("Unmarshal: error %v, want SyntaxError", err)
+	}
+	if se.Offset != 13 || se.Line != 2 {
+		t.Errorf("Unmarshal: Offset %d, Line %d, want 13, 2", se.Offset, se.Line)
+	}
+}
+
+func TestUnmarshalIntRange(t *testing.T) {
+	one := big.NewInt(1)
+	for _, zero := range []interface{}{int8(0), int16(0), int32(0), int64(0), int(0), uint8(0), uint16(0), uint32(0), uint64(0), uint(0), uintptr(0)} {
+		typ := reflect.TypeOf(zero)
+		bits := uint(typ.Bits())
+		lo, hi := new(big.Int), new(big.Int).Lsh(one, bits)
+		if typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Int64 {
+			hi.Rsh(hi, 1)
+			lo.Neg(hi)
+		}
+		hi.Sub(hi, one)
+		under, over := new(big.Int).Sub(lo, one), new(big.Int).Add(hi, one)
+
+		// The bounds of the type fit, the numbers either side of them
+		// do not.
+		for _, n := range []*big.Int{lo, hi} {
+			v := reflect.New(typ)
+			if err := Unmarshal([]byte(n.String()), v.Interface()); err != nil {
+				t.Errorf("Unmarshal(%s, %v): %v", n, typ, err)
+			} else if got := fmt.Sprint(v.Elem()); got != n.String() {
+				t.Errorf("Unmarshal(%s, %v) = %s", n, typ, got)
+			}
+		}
+		for _, n := range []*big.Int{under, over} {
+			v := reflect.New(typ)
+			err := Unmarshal([]byte(n.String()), v.Interface())
+			ute, ok := err.(*UnmarshalTypeError)
+			if !ok || ute.Type != typ || ute.Value != "number "+n.String() {
+				t.Errorf("Unmarshal(%s, %v): error %v, want UnmarshalTypeError", n, typ, err)
+			}
+			if !v.Elem().IsZero() {
+				t.Errorf("Unmarshal(%s, %v) stored %v", n, typ, v.Elem())
+			}
+		}
+
+		// So for the big-endian octets of an hstring of an "intfromhex"
+		// field.
+		st := reflect.StructOf([]reflect.StructField{{Name: "N", Type: typ, Tag: `asn1:",intfromhex"`}})
+		hex := func(n *big.Int) string { return fmt.Sprintf("{ n '%X'H }", n.Bytes()) }
+		v := reflect.New(st)
+		if err := Unmarshal([]byte(hex(hi)), v.Interface()); err != nil {
+			t.Errorf("Unmarshal(%s, %v): %v", hex(hi), typ, err)
+		} else if got := fmt.Sprint(v.Elem().Field(0)); got != hi.String() {
+			t.Errorf("Unmarshal(%s, %v) = %s, want %s", hex(hi), typ, got, hi)
+		}
+		v = reflect.New(st)
+		err := Unmarshal([]byte(hex(over)), v.Interface())
+		if ute, ok := err.(*UnmarshalTypeError); !ok || ute.Type != typ || ute.Field != "n" {
+			t.Errorf("Unmarshal(%s, %v): error %v, want UnmarshalTypeError", hex(over), typ, err)
+		}
+		if !v.Elem().IsZero() {
+			t.Errorf("Unmarshal(%s, %v) stored %v", hex(over), typ, v.Elem())
+		}
+	}
+}
+
+// upperString unmarshals a cstring in upper case.
+type upperString string
+
+func (s *upperString) UnmarshalASN1(data []byte) error {
+	var str string
+	if err := Unmarshal(data, &str); err != nil {
+		return err
+	}
+	*s = upperString(strings.ToUpper(str))
+	return nil
+}
+
+func TestUnmarshaler(t *testing.T) {
+	var v struct {
+		Name  upperString
+		Names []upperString
+	}
+	if err := Unmarshal([]byte(`{ name "ab", names { "c", "d" } }`), &v); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if v.Name != "AB" || !reflect.DeepEqual(v.Names, []upperString{"C", "D"}) {
+		t.Errorf("Unmarshal = %+v", v)
+	}
+}
+
+func TestUnmarshalNamed(t *testing.T) {
+	tests := []struct {
+		in   string
+		want namedRecord
+		err  bool // an UnmarshalTypeError
+	}{
+		{"{ version v2, level high, flags { bitA, bitC } }", namedRecord{1, 200, BitString{Bytes: []byte{0xA0}, BitLength: 3}}, false},
+		{"{ version v1, level low, flags { } }", namedRecord{0, 1, BitString{}}, false},
+		{"{ version 7, level 7, flags '101'B }", namedRecord{7, 7, BitString{Bytes: []byte{0xA0}, BitLength: 3}}, false},
+		{"{ version v3 }", namedRecord{}, true},
+		{"{ level v1 }", namedRecord{}, true},
+		{"{ flags { bitA, bitX } }", namedRecord{}, true},
+		{"{ flags { bitA, 1 } }", namedRecord{}, true},
+	}
+	for _, tt := range tests {
+		var v namedRecord
+		err := Unmarshal([]byte(tt.in), &v)
+		if tt.err {
+			if _, ok := err.(*UnmarshalTypeError); !ok {
+				t.Errorf("Unmarshal(%s): error %v, want UnmarshalTypeError", tt.in, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unmarshal(%s): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(v, tt.want) {
+			t.Errorf("Unmarshal(%s) = %+v, want %+v", tt.in, v, tt.want)
+		}
+	}
+
+	// A name is not a value without the option.
+	var plain struct{ Version int }
+	if err := Unmarshal([]byte("{ version v2 }"), &plain); err == nil {
+		t.Error("Unmarshal of a name without named numbers: no error")
+	}
+	type malformed struct {
+		A int `asn1:"a,named:x"`
+	}
+	if _, err := TypeOf(malformed{}); err == nil {
+		t.Error("TypeOf with a malformed named option: no error")
+	}
+}
+
+// errBadLevel is the error of levelText for an unknown level.
+var errBadLevel = errors.New("unknown level")
+
+// levelText unmarshals the text of a level name.
+type levelText int
+
+func (l *levelText) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return errBadLevel
+	}
+	return nil
+}
+
+func TestTextUnmarshaler(t *testing.T) {
+	tests := []struct {
+		in   string
+		ptr  interface{}
+		want interface{}
+	}{
+		{`"192.0.2.1"`, new(net.IP), net.ParseIP("192.0.2.1")},
+		{`"2001:db8::1"`, new(net.IP), net.ParseIP("2001:db8::1")},
+		{`"high"`, new(levelText), levelText(2)},
+		{`{ "low", "high" }`, new([]levelText), []levelText{1, 2}},
+		{`{ a "low" }`, new(struct{ A *levelText }), struct{ A *levelText }{new(levelText)}},
+	}
+	*tests[len(tests)-1].want.(struct{ A *levelText }).A = 1
+	for _, tt := range tests {
+		if err := Unmarshal([]byte(tt.in), tt.ptr); err != nil {
+			t.Errorf("Unmarshal(%s, %T): %v", tt.in, tt.ptr, err)
+			continue
+		}
+		if got := reflect.ValueOf(tt.ptr).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Unmarshal(%s, %T) = %v, want %v", tt.in, tt.ptr, got, tt.want)
+		}
+	}
+}
+
+func TestTextUnmarshalerError(t *testing.T) {
+	var ip net.IP
+	err := Unmarshal([]byte(`"192.0.2"`), &ip)
+	var pe *net.ParseError
+	if !errors.As(err, &pe) {
+		t.Errorf("Unmarshal of an invalid IP address: error %v, want net.ParseError", err)
+	}
+
+	var v struct {
+		A, B levelText
+	}
+	err = Unmarshal([]byte(`{ a "medium", b "high" }`), &v)
+	if !errors.Is(err, errBadLevel) {
+		t.Errorf("Unmarshal of an unknown level: error %v, want %v", err, errBadLevel)
+	}
+	// Decoding goes on after the error, as after an UnmarshalTypeError.
+	if v.B != 2 {
+		t.Errorf("Unmarshal of an unknown level: B = %d, want 2", v.B)
+	}
+
+	var l levelText
+	if err := Unmarshal([]byte("TRUE"), &l); err == nil {
+		t.Error("Unmarshal of a boolean into a TextUnmarshaler: no error")
+	}
+}
+
+func TestUnmarshalConcurrent(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("-- package\n")
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&b, "value%d PE ::= header : { major-version %d, list { 1, 2, %d } }\n", i, i, i)
+	}
+	doc := b.String()
+	var five interface{} = []interface{}{int64(5)}
+	type header struct {
+		MajorVersion int8 `asn1:"major-version"`
+	}
+	tests := []struct {
+		in   string
+		new  func() interface{} // returns a pointer to the target
+		n    int
+		want interface{} // if nil, what Unmarshal stores
+	}{
+		{doc, func() interface{} { return new([]interface{}) }, 4, nil},
+		{doc, func() interface{} { return new(interface{}) }, 0, nil},
+		{doc, func() interface{} { return new(map[string]interface{}) }, 1, nil},
+		{doc, func() interface{} { return new([]map[string]header) }, 8, nil},
+		{doc, func() interface{} { return new([3]map[string]header) }, 2, nil},
+		// A single value is still stored as an element.
+		{"5", func() interface{} { return new([]int) }, 0, &[]int{5}},
+		{"5", func() interface{} { return new(interface{}) }, 0, &five},
+	}
+	for _, tt := range tests {
+		want, got := tt.want, tt.new()
+		if want == nil {
+			want = tt.new()
+			if err := Unmarshal([]byte(tt.in), want); err != nil {
+				t.Fatalf("Unmarshal(%.20q, %T): %v", tt.in, want, err)
+			}
+		}
+		if err := UnmarshalConcurrent([]byte(tt.in), got, tt.n); err != nil {
+			t.Errorf("UnmarshalConcurrent(%.20q, %T, %d): %v", tt.in, got, tt.n, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("UnmarshalConcurrent(%.20q, %T, %d) = %v, want %v", tt.in, got, tt.n, got, want)
+		}
+	}
+}
+
+func TestUnmarshalConcurrentError(t *testing.T) {
+	var i interface{}
+	tests := []struct {
+		in   string
+		v    interface{}
+		want interface{} // type of the error
+	}{
+		{"5", nil, &InvalidUnmarshalError{}},
+		{"5", i, &InvalidUnmarshalError{}},
+		{"5 { 1", new([]int), &SyntaxError{}},
+		{"v1 T ::= { a 1 }\nv2 T ::= { a 300 }\nv3 T ::= { a 999 }", new([]struct{ A int8 }), &UnmarshalTypeError{}},
+	}
+	for _, tt := range tests {
+		err := UnmarshalConcurrent([]byte(tt.in), tt.v, 2)
+		if reflect.TypeOf(err) != reflect.TypeOf(tt.want) {
+			t.Errorf("UnmarshalConcurrent(%q, %T): error %v, want %T", tt.in, tt.v, err, tt.want)
+		}
+	}
+	// The error of the first value in the input wins.
+	var v []struct{ A int8 }
+	err := UnmarshalConcurrent([]byte("v1 T ::= { a 1 }\nv2 T ::= { a 300 }\nv3 T ::= { a 999 }"), &v, 3)
+	if ute, ok := err.(*UnmarshalTypeError); !ok || ute.Value != "number 300" || ute.Offset != 33 {
+		t.Errorf("UnmarshalConcurrent: error %v, want that of 300 at offset 33", err)
+	}
+}
+
+func TestUnmarshalSingle(t *testing.T) {
+	tests := []struct {
+		in   string
+		want interface{}
+		ok   bool
+	}{
+		{"5", int64(5), true},
+		{"{ a 1 }", OrderedObject{{"a", int64(1)}}, true},
+		{"{ a 1 } -- c\n", OrderedObject{{"a", int64(1)}}, true},
+		{"v T ::= 1\n", int64(1), true},
+		{"5 6", nil, false},
+		{"{ a 1 } x", nil, false},
+		{"v T ::= 1 w T ::= 2", nil, false},
+		{"", nil, false},
+	}
+	for _, tt := range tests {
+		var v interface{}
+		err := UnmarshalSingle([]byte(tt.in), &v)
+		if !tt.ok {
+			if _, ok := err.(*SyntaxError); !ok {
+				t.Errorf("UnmarshalSingle(%q): error %v, want SyntaxError", tt.in, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("UnmarshalSingle(%q): %v", tt.in, err)
+		} else if !reflect.DeepEqual(v, tt.want) {
+			t.Errorf("UnmarshalSingle(%q) = %#v, want %#v", tt.in, v, tt.want)
+		}
+	}
+}
+
+func TestSplitValues(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+		ok   bool
+	}{
+		{"5", []string{"5"}, true},
+		{"  -- c\n -5  x T ::= { a { b { 1 2 } } } -- t\n y T ::= v  ", []string{"-5", "x T ::= { a { b { 1 2 } } }", "y T ::= v"}, true},
+		{"v T ::= \"a -- b\"\n", []string{`v T ::= "a -- b"`}, true},
+		{"{ a 1 } { b", nil, false},
+		{"  ", nil, false},
+	}
+	for _, tt := range tests {
+		vs, err := SplitValues([]byte(tt.in))
+		if !tt.ok {
+			if _, ok := err.(*SyntaxError); !ok {
+				t.Errorf("SplitValues(%q): error %v, want SyntaxError", tt.in, err)
+			}
+			continue
+		}
+		var got []string
+		for _, v := range vs {
+			got = append(got, string(v))
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitValues(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalReader(t *testing.T) {
+	type record struct{ A int8 }
+	tests := []struct {
+		in   string
+		ptr  interface{}
+		want interface{}
+		err  interface{} // type of the error, or nil
+	}{
+		{"5", new(int), 5, nil},
+		{"5", new(interface{}), int64(5), nil},
+		{"v T ::= { a 1 }\nw T ::= { a 2 }", new([]record), []record{{1}, {2}}, nil},
+		{"v T ::= { a 1 }\nw T ::= { a 2 }", new(map[string]record), map[string]record{"v": {1}, "w": {2}}, nil},
+		{"5 6", new(int), 0, &UnmarshalTypeError{}},
+		{"{ a 1 } { a 300 }", new([]record), []record{{1}, {0}}, &UnmarshalTypeError{}},
+		// The values before a syntax error are stored.
+		{"{ a 1 } { a 1,, }", new([]record), []record{{1}}, &SyntaxError{}},
+		{"{ a 1 } { a", new([]record), []record{{1}}, &SyntaxError{}},
+		{"{ a 1", new([]record), []record(nil), &SyntaxError{}},
+		{"", new([]record), []record(nil), &SyntaxError{}},
+	}
+	for _, tt := range tests {
+		err := UnmarshalReader(iotest.OneByteReader(strings.NewReader(tt.in)), tt.ptr)
+		if reflect.TypeOf(err) != reflect.TypeOf(tt.err) {
+			t.Errorf("UnmarshalReader(%q, %T): error %v, want %T", tt.in, tt.ptr, err, tt.err)
+		}
+		if got := reflect.ValueOf(tt.ptr).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("UnmarshalReader(%q, %T) = %#v, want %#v", tt.in, tt.ptr, got, tt.want)
+		}
+	}
+	if err := UnmarshalReader(strings.NewReader("5"), nil); err == nil {
+		t.Error("UnmarshalReader into nil: no error")
+	}
+}
+
+func TestUnmarshalBigInt(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	tests := []struct {
+		in   string
+		want *big.Int // nil for an UnmarshalTypeError
+	}{
+		{"0", big.NewInt(0)},
+		{"-1", big.NewInt(-1)},
+		{"9223372036854775807", big.NewInt(math.MaxInt64)},
+		{"-9223372036854775808", big.NewInt(math.MinInt64)},
+		{"123456789012345678901234567890", huge},
+		{"-123456789012345678901234567890", new(big.Int).Neg(huge)},
+		{"1.5", nil},
+		{"TRUE", nil},
+		{"NULL", nil},
+		{"red", nil},
+		{`"5"`, nil},
+		{"'05'H", nil},
+		{"{ a 1 }", nil},
+		{"{ 1 2 }", nil},
+		{"{ }", nil},
+		{"alt : 5", nil},
+	}
+	for _, tt := range tests {
+		n := big.NewInt(7)
+		err := Unmarshal([]byte(tt.in), n)
+		if tt.want == nil {
+			if _, ok := err.(*UnmarshalTypeError); !ok {
+				t.Errorf("Unmarshal(%q, *big.Int): error %v, want UnmarshalTypeError", tt.in, err)
+			}
+			if n.Cmp(big.NewInt(7)) != 0 {
+				t.Errorf("Unmarshal(%q, *big.Int) changed the value to %v", tt.in, n)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unmarshal(%q, *big.Int): %v", tt.in, err)
+			continue
+		}
+		if n.Cmp(tt.want) != 0 {
+			t.Errorf("Unmarshal(%q, *big.Int) = %v, want %v", tt.in, n, tt.want)
+		}
+	}
+
+	var v struct {
+		N    *big.Int
+		Size big.Int `asn1:",intfromhex"`
+	}
+	if err := Unmarshal([]byte("{ n -123456789012345678901234567890, size '0100'H }"), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.N == nil || v.N.Cmp(new(big.Int).Neg(huge)) != 0 || v.Size.Int64() != 256 {
+		t.Errorf("Unmarshal of big.Int fields = %v, %v", v.N, &v.Size)
+	}
+}
@@ -0,0 +1,1021 @@
+package asn1go
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// EncodeDER returns the DER encoding of v as a value of the ASN.1 type t.
+//
+// v can be a value as decoded by Unmarshal into an empty interface, such
+// as an OrderedObject for a SEQUENCE, or a Go value of the types Unmarshal
+// decodes into. Components of a SEQUENCE or SET are looked up by
+// identifier in maps and OrderedObjects, where the last of repeated
+// identifiers counts, and, as Unmarshal matches them, in structs. A
+// component is absent if it is missing, nil or left out by omitempty.
+// A CHOICE value is a ChoiceValue, a map with a single key or a struct
+// with "choice" fields. INTEGER and ENUMERATED values can be given by
+// name, and values of an open type are their DER encoding as a []byte.
+//
+// Components equal to their DEFAULT value are left out, and the elements
+// of a SET OF are sorted, as DER requires. The Extensions of a value of an
+// extensible type are written after its other components.
+func EncodeDER(t *Type, v interface{}) ([]byte, error) {
+	var e derEncoder
+	return e.marshal(t, reflect.ValueOf(v))
+}
+
+// MarshalDER returns the DER encoding of v, as a value of the ASN.1 type
+// TypeOf derives from the type of v.
+func MarshalDER(v interface{}) ([]byte, error) {
+	t, err := TypeOf(v)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeDER(t, v)
+}
+
+// EncodeOptions select the checks that EncodeOptions.Encode makes before
+// encoding a value. The zero EncodeOptions make none beyond those of
+// EncodeDER.
+type EncodeOptions struct {
+	// CheckConstraints rejects values that violate the constraints of
+	// their types, as CheckConstraints reports them.
+	CheckConstraints bool
+}
+
+// Encode is like EncodeDER but makes the checks that o selects.
+func (o EncodeOptions) Encode(t *Type, v interface{}) ([]byte, error) {
+	if o.CheckConstraints {
+		if err := CheckConstraints(t, v); err != nil {
+			return nil, err
+		}
+	}
+	return EncodeDER(t, v)
+}
+
+// A ValueError describes a Go value that cannot be encoded as the ASN.1
+// type it is given for.
+type ValueError struct {
+	Path string // component identifiers leading to the value, such as "header.iccid"
+	Type string // the ASN.1 type
+	Msg  string
+}
+
+func (e *ValueError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "value"
+	}
+	return "asn1go: cannot encode " + path + " as " + e.Type + ": " + e.Msg
+}
+
+// A derEncoder encodes values in DER or CER.
+type derEncoder struct {
+	path []string // identifiers of the components being encoded
+	cer  bool     // encode in CER rather than DER
+}
+
+func (e *derEncoder) marshal(t *Type, v reflect.Value) (b []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if je, ok := r.(asn1Error); ok {
+				err = je.error
+			} else {
+				panic(r)
+			}
+		}
+	}()
+	return e.value(nil, t, v), nil
+}
+
+// error aborts the encoding with a ValueError for the value of type t.
+func (e *derEncoder) error(t *Type, format string, args ...interface{}) {
+	panic(asn1Error{&ValueError{strings.Join(e.path, "."), t.String(), fmt.Sprintf(format, args...)}})
+}
+
+// derIndirect follows pointers and interfaces to the value they refer to.
+// It returns the zero Value for nil, an OrderedObject as the map of its
+// components and a Value as its Interface.
+func derIndirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	if v.IsValid() && v.Type() == valueType {
+		return derIndirect(reflect.ValueOf(v.Interface().(Value).Interface()))
+	}
+	if v.IsValid() && v.Type() == orderedObjectType {
+		v = reflect.ValueOf(v.Interface().(OrderedObject).Map())
+	}
+	return v
+}
+
+// value appends the encoding of v as a value of type t to dst.
+func (e *derEncoder) value(dst []byte, t *Type, v reflect.Value) []byte {
+	v = derIndirect(v)
+	var b []byte
+	switch t.Kind {
+	case KindChoice:
+		b = e.choice(t, v)
+	case KindAny:
+		raw, ok := bytesOf(v)
+		if !ok || !validTLV(raw) {
+			e.error(t, "open type value must be a DER encoding")
+		}
+		b = raw
+	default:
+		content, constructed := e.content(t, v)
+		tag := Tag{ClassUniversal, t.Kind.universalTag()}
+		switch {
+		case !e.cer:
+			b = appendTLV(nil, tag, constructed, content)
+		case constructed:
+			b = appendIndefinite(nil, tag, content)
+		case isStringKind(t.Kind) && len(content) > cerSegmentSize:
+			b = appendSegments(nil, t.Kind, content)
+		default:
+			b = appendTLV(nil, tag, false, content)
+		}
+	}
+	return append(dst, applyTags(t, b, e.cer)...)
+}
+
+// applyTags returns the encoding b of a value of type t with the tags of
+// t applied to it. Explicit tags have the indefinite length if cer is set.
+func applyTags(t *Type, b []byte, cer bool) []byte {
+	for i := len(t.Tags) - 1; i >= 0; i-- {
+		tt := t.Tags[i]
+		// A tag on an untagged CHOICE or open type is always explicit.
+		explicit := tt.Explicit || i == len(t.Tags)-1 && (t.Kind == KindChoice || t.Kind == KindAny)
+		switch {
+		case explicit && cer:
+			b = appendIndefinite(nil, tt.Tag, b)
+		case explicit:
+			b = appendTLV(nil, tt.Tag, true, b)
+		default:
+			b = retag(b, tt.Tag)
+		}
+	}
+	return b
+}
+
+// content returns the contents octets of the encoding of v as a value of
+// type t, and whether the encoding is constructed.
+func (e *derEncoder) content(t *Type, v reflect.Value) ([]byte, bool) {
+	switch t.Kind {
+	case KindBoolean:
+		if v.Kind() != reflect.Bool {
+			e.mismatch(t, v)
+		}
+		if v.Bool() {
+			return []byte{0xff}, false
+		}
+		return []byte{0x00}, false
+
+	case KindInteger, KindEnumerated:
+		return e.integer(t, v), false
+
+	case KindReal:
+		var f float64
+		switch v.Kind() {
+		case reflect.Float32, reflect.Float64:
+			f = v.Float()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f = float64(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			f = float64(v.Uint())
+		default:
+			e.mismatch(t, v)
+		}
+		return appendReal(nil, f), false
+
+	case KindBitString:
+		var bs BitString
+		if b, ok := e.contained(t, v); ok {
+			bs = BitString{Bytes: b, BitLength: 8 * len(b)}
+		} else if v.IsValid() && v.Type() == bitStringType {
+			bs = v.Interface().(BitString)
+		} else if b, ok := bytesOf(v); ok {
+			bs = BitString{Bytes: b, BitLength: 8 * len(b)}
+		} else if names, ok := stringsOf(v); ok {
+			var err error
+			if bs, err = namedBits(t.Named, names); err != nil {
+				e.error(t, "%v", err)
+			}
+		} else {
+			e.mismatch(t, v)
+		}
+		if t.Named != nil && t.Size == nil {
+			bs = trimBits(bs)
+		}
+		return appendBitString(nil, bs), false
+
+	case KindOctetString:
+		if b, ok := e.contained(t, v); ok {
+			return b, false
+		}
+		b, ok := bytesOf(v)
+		if !ok {
+			e.mismatch(t, v)
+		}
+		return b, false
+
+	case KindNull:
+		return nil, false
+
+	case KindObjectIdentifier:
+		oid, ok := objectIdentifierOf(v)
+		if !ok {
+			e.mismatch(t, v)
+		}
+		b, err := appendOID(nil, oid)
+		if err != nil {
+			e.error(t, "%v", err)
+		}
+		return b, false
+
+	case KindRelativeOID:
+		oid, ok := objectIdentifierOf(v)
+		if !ok {
+			e.mismatch(t, v)
+		}
+		return appendRelativeOID(nil, oid), false
+
+	case KindUTF8String, KindNumericString, KindPrintableString, KindIA5String, KindVisibleString,
+		KindUTCTime, KindGeneralizedTime, KindOIDIRI, KindRelativeOIDIRI,
+		KindTime, KindDate, KindTimeOfDay, KindDateTime, KindDuration:
+		if s, ok, err := timeString(t, v); ok {
+			if err != nil {
+				e.error(t, "%v", err)
+			}
+			return []byte(s), false
+		}
+		if v.Kind() != reflect.String {
+			e.mismatch(t, v)
+		}
+		s := v.String()
+		if !validString(t.Kind, s) {
+			e.error(t, "invalid character in %q", s)
+		}
+		return []byte(s), false
+
+	case KindSequence, KindSet:
+		return e.components(t, v), true
+
+	case KindSequenceOf, KindSetOf:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			e.mismatch(t, v)
+		}
+		elems := make([][]byte, v.Len())
+		for i := range elems {
+			e.path = append(e.path, strconv.Itoa(i))
+			elems[i] = e.value(nil, t.Elem, v.Index(i))
+			e.path = e.path[:len(e.path)-1]
+		}
+		if t.Kind == KindSetOf {
+			sort.Slice(elems, func(i, j int) bool { return bytes.Compare(elems[i], elems[j]) < 0 })
+		}
+		return bytes.Join(elems, nil), true
+	}
+	e.error(t, "unsupported type")
+	panic("unreachable")
+}
+
+// mismatch aborts the encoding because v does not fit type t.
+func (e *derEncoder) mismatch(t *Type, v reflect.Value) {
+	if !v.IsValid() {
+		e.error(t, "missing value")
+	}
+	e.error(t, "unsupported Go type %s", v.Type())
+}
+
+func (e *derEncoder) integer(t *Type, v reflect.Value) []byte {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendInt64(nil, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return appendUint64(nil, v.Uint())
+	case reflect.Struct:
+		if v.Type() == bigIntType {
+			return appendBigInt(nil, bigIntOf(v))
+		}
+	case reflect.String:
+		s := v.String()
+		if n, ok := t.NamedValue(s); ok {
+			return appendInt64(nil, n)
+		}
+		if v.Type() == numberType {
+			if n, ok := new(big.Int).SetString(s, 10); ok {
+				return appendBigInt(nil, n)
+			}
+		}
+		e.error(t, "unknown named value %q", s)
+	}
+	e.mismatch(t, v)
+	panic("unreachable")
+}
+
+// components returns the encodings of the components of the SEQUENCE or
+// SET value v.
+func (e *derEncoder) components(t *Type, v reflect.Value) []byte {
+	switch v.Kind() {
+	case reflect.Struct:
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			e.mismatch(t, v)
+		}
+		for _, k := range v.MapKeys() {
+			if t.Component(k.String()) == nil && !(t.Extensible && k.String() == "...") {
+				e.error(t, "unknown component %s", k.String())
+			}
+		}
+	default:
+		e.mismatch(t, v)
+	}
+
+	var elems [][]byte
+	for i := range t.Components {
+		c := &t.Components[i]
+		cv, ok := componentValue(v, c.Name)
+		if !ok {
+			if c.Optional || c.Default != nil || c.Extension {
+				continue
+			}
+			e.error(t, "missing component %s", c.Name)
+		}
+		if c.Default != nil && equalValue(c.Type, cv, reflect.ValueOf(c.Default)) {
+			continue
+		}
+		e.path = append(e.path, c.Name)
+		elems = append(elems, e.value(nil, c.Type, cv))
+		e.path = e.path[:len(e.path)-1]
+	}
+	if ext, ok := e.extensions(t, v); ok {
+		for _, b := range ext {
+			if b != nil {
+				elems = append(elems, e.extension(t, b))
+			}
+		}
+	}
+	if t.Kind == KindSet {
+		// DER orders the components of a SET by their tags.
+		sort.SliceStable(elems, func(i, j int) bool {
+			return tagLess(elems[i], elems[j])
+		})
+	}
+	return bytes.Join(elems, nil)
+}
+
+// componentValue returns the value of the component name in the struct or
+// map v, reporting false if it is absent.
+func componentValue(v reflect.Value, name string) (reflect.Value, bool) {
+	switch v.Kind() {
+	case reflect.Struct:
+		fields := cachedTypeFields(v.Type())
+		f := fields.byName([]byte(name))
+		if f == nil {
+			return reflect.Value{}, false
+		}
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok || f.omitEmpty && isEmptyValue(fv) {
+			return reflect.Value{}, false
+		}
+		fv = derIndirect(fv)
+		if fv.IsValid() && f.choice != "" {
+			return reflect.ValueOf(ChoiceValue{f.choice, fv.Interface()}), true
+		}
+		return fv, fv.IsValid()
+	case reflect.Map:
+		// A nil map entry, as decoded from NULL, is present.
+		mv := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+		return mv, mv.IsValid()
+	}
+	return reflect.Value{}, false
+}
+
+// choice returns the encoding of the CHOICE value v.
+func (e *derEncoder) choice(t *Type, v reflect.Value) []byte {
+	name, av, ok := choiceOf(v)
+	if !ok {
+		e.mismatch(t, v)
+	}
+	c := t.Component(name)
+	if c == nil && t.Extensible && name == "..." {
+		ext, ok := extensionsOf(av)
+		if !ok || len(ext) == 0 || ext[len(ext)-1] == nil {
+			e.error(t, "unknown extension alternative must be Extensions")
+		}
+		return e.extension(t, ext[len(ext)-1])
+	}
+	if c == nil {
+		e.error(t, "unknown alternative %s", name)
+	}
+	e.path = append(e.path, name)
+	b := e.value(nil, c.Type, av)
+	e.path = e.path[:len(e.path)-1]
+	return b
+}
+
+// extensions returns the Extensions of the SEQUENCE or SET value v of type
+// t, reporting false if it has none.
+func (e *derEncoder) extensions(t *Type, v reflect.Value) (Extensions, bool) {
+	if !t.Extensible {
+		return nil, false
+	}
+	ev, ok := componentValue(v, "...")
+	if !ok {
+		return nil, false
+	}
+	ext, ok := extensionsOf(ev)
+	if !ok {
+		e.error(t, "unsupported Go type %s for extensions", ev.Type())
+	}
+	return ext, true
+}
+
+// extension returns the unknown extension addition b of a value of type
+// t, which must be a BER encoding.
+func (e *derEncoder) extension(t *Type, b []byte) []byte {
+	if _, _, l, n, err := parseHeader(b, false); err != nil || l >= 0 && n+l != len(b) {
+		e.error(t, "extension addition must be a BER encoding")
+	}
+	return b
+}
+
+// extensionsOf returns the Extensions v holds.
+func extensionsOf(v reflect.Value) (Extensions, bool) {
+	v = derIndirect(v)
+	if !v.IsValid() || !v.Type().ConvertibleTo(extensionsType) {
+		return nil, false
+	}
+	return v.Convert(extensionsType).Interface().(Extensions), true
+}
+
+// choiceOf returns the alternative and its value of the CHOICE value v:
+// a ChoiceValue, a map with a single entry, or a struct with "choice"
+// fields of which one is set.
+func choiceOf(v reflect.Value) (string, reflect.Value, bool) {
+	if !v.IsValid() {
+		return "", v, false
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == choiceValueType {
+			cv := v.Interface().(ChoiceValue)
+			return cv.Alternative, reflect.ValueOf(cv.Value), true
+		}
+		fields := cachedTypeFields(v.Type())
+		if !fields.choice {
+			return "", v, false
+		}
+		name, av := "", reflect.Value{}
+		for i := range fields.list {
+			f := &fields.list[i]
+			fv, ok := fieldByIndex(v, f.index)
+			if !f.alternative || !ok || fv.IsZero() {
+				continue
+			}
+			if name != "" {
+				return "", v, false
+			}
+			name, av = f.name, fv
+		}
+		return name, av, name != ""
+	case reflect.Map:
+		if v.Len() != 1 || v.Type().Key().Kind() != reflect.String {
+			return "", v, false
+		}
+		iter := v.MapRange()
+		iter.Next()
+		return iter.Key().String(), iter.Value(), true
+	}
+	return "", v, false
+}
+
+// equalValue reports whether the component value v of type t equals the
+// DEFAULT value def, comparing numbers by value and named numbers by
+// their value. Values in different Go forms, such as a [][]byte and the
+// []interface{} of a SEQUENCE OF default, are equal if their DER
+// encodings are.
+func equalValue(t *Type, v, def reflect.Value) bool {
+	v, def = derIndirect(v), derIndirect(def)
+	if !v.IsValid() || !def.IsValid() {
+		return v.IsValid() == def.IsValid()
+	}
+	if x, ok := integerValue(t, v); ok {
+		y, ok := integerValue(t, def)
+		return ok && x == y
+	}
+	if reflect.DeepEqual(v.Interface(), def.Interface()) {
+		return true
+	}
+	a, err := EncodeDER(t, v.Interface())
+	if err != nil {
+		return false
+	}
+	b, err := EncodeDER(t, def.Interface())
+	return err == nil && bytes.Equal(a, b)
+}
+
+// integerValue returns the integer v holds, or the number of the named
+// value v of the INTEGER or ENUMERATED type t.
+func integerValue(t *Type, v reflect.Value) (int64, bool) {
+	if v.Kind() == reflect.String && (t.Kind == KindInteger || t.Kind == KindEnumerated) {
+		return t.NamedValue(v.String())
+	}
+	return integerOf(v)
+}
+
+// integerOf returns the integer v holds.
+func integerOf(v reflect.Value) (int64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() <= math.MaxInt64 {
+			return int64(v.Uint()), true
+		}
+	case reflect.Struct:
+		if v.Type() == bigIntType && bigIntOf(v).IsInt64() {
+			return bigIntOf(v).Int64(), true
+		}
+	}
+	return 0, false
+}
+
+// bigIntOf returns the big.Int v holds.
+func bigIntOf(v reflect.Value) *big.Int {
+	if v.CanAddr() {
+		return v.Addr().Interface().(*big.Int)
+	}
+	n := v.Interface().(big.Int)
+	return &n
+}
+
+// stringsOf returns the strings of v, a slice or array of strings or of
+// interfaces holding strings, such as the identifiers of a named bit list
+// as Unmarshal decodes it into an empty interface.
+func stringsOf(v reflect.Value) ([]string, bool) {
+	if !v.IsValid() || v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, false
+	}
+	s := make([]string, v.Len())
+	for i := range s {
+		ev := derIndirect(v.Index(i))
+		if !ev.IsValid() || ev.Kind() != reflect.String {
+			return nil, false
+		}
+		s[i] = ev.String()
+	}
+	return s, true
+}
+
+// contained returns the encoding of the value contained in v, if t has a
+// contents constraint and v is a Containing or a value other than the
+// octets of the string.
+func (e *derEncoder) contained(t *Type, v reflect.Value) ([]byte, bool) {
+	if t.Contains == nil || !v.IsValid() {
+		return nil, false
+	}
+	if v.Type() == containingType {
+		v = reflect.ValueOf(v.Interface().(Containing).Value)
+	} else if _, ok := bytesOf(v); ok || v.Type() == bitStringType {
+		return nil, false
+	}
+	if err := checkEncodedBy(t); err != nil {
+		e.error(t, "%v", err)
+	}
+	return e.value(nil, t.Contains, v), true
+}
+
+// Object identifiers of the encoding rules a contents constraint may name.
+var (
+	oidBER = ObjectIdentifier{2, 1, 1}
+	oidCER = ObjectIdentifier{2, 1, 2, 0}
+	oidDER = ObjectIdentifier{2, 1, 2, 1}
+)
+
+// checkEncodedBy reports an error if the value contained in a value of
+// type t is not to be encoded in BER, CER or DER, which the codecs encode
+// it in.
+func checkEncodedBy(t *Type) error {
+	if t.EncodedBy == nil || t.EncodedBy.Equal(oidBER) || t.EncodedBy.Equal(oidCER) || t.EncodedBy.Equal(oidDER) {
+		return nil
+	}
+	return fmt.Errorf("contents encoded by %v are not supported", t.EncodedBy)
+}
+
+// bytesOf returns the bytes of the byte slice or array v.
+func bytesOf(v reflect.Value) ([]byte, bool) {
+	if !v.IsValid() {
+		return nil, false
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Bytes(), true
+		}
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return b, true
+		}
+	case reflect.String:
+		return []byte(v.String()), true
+	}
+	return nil, false
+}
+
+// objectIdentifierOf returns the components of the OBJECT IDENTIFIER
+// value v, an ObjectIdentifier or a slice of integers.
+func objectIdentifierOf(v reflect.Value) (ObjectIdentifier, bool) {
+	if !v.IsValid() || v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, false
+	}
+	oid := make(ObjectIdentifier, v.Len())
+	for i := range oid {
+		n, ok := integerOf(derIndirect(v.Index(i)))
+		if !ok || n < 0 || int64(int(n)) != n {
+			return nil, false
+		}
+		oid[i] = int(n)
+	}
+	return oid, true
+}
+
+// appendTLV appends the tag, length and contents octets of an encoding to
+// dst.
+func appendTLV(dst []byte, tag Tag, constructed bool, content []byte) []byte {
+	dst = appendTag(dst, tag, constructed)
+	dst = appendLength(dst, len(content))
+	return append(dst, content...)
+}
+
+// appendTag appends the identifier octets of tag to dst.
+func appendTag(dst []byte, tag Tag, constructed bool) []byte {
+	b := byte(tag.Class) << 6
+	if constructed {
+		b |= 0x20
+	}
+	if tag.Number < 31 {
+		return append(dst, b|byte(tag.Number))
+	}
+	dst = append(dst, b|0x1f)
+	return appendBase128(dst, uint64(tag.Number))
+}
+
+// appendLength appends the definite length octets for n to dst.
+func appendLength(dst []byte, n int) []byte {
+	if n < 0x80 {
+		return append(dst, byte(n))
+	}
+	l := 0
+	for i := n; i > 0; i >>= 8 {
+		l++
+	}
+	dst = append(dst, 0x80|byte(l))
+	for ; l > 0; l-- {
+		dst = append(dst, byte(n>>(uint(l-1)*8)))
+	}
+	return dst
+}
+
+// appendBase128 appends n as a base-128 number with continuation bits, as
+// used in tag numbers and object identifier components.
+func appendBase128(dst []byte, n uint64) []byte {
+	l := 1
+	for i := n; i >= 0x80; i >>= 7 {
+		l++
+	}
+	for i := l - 1; i >= 0; i-- {
+		b := byte(n>>(uint(i)*7)) & 0x7f
+		if i != 0 {
+			b |= 0x80
+		}
+		dst = append(dst, b)
+	}
+	return dst
+}
+
+// appendInt64 appends the minimal two's complement encoding of n to dst.
+func appendInt64(dst []byte, n int64) []byte {
+	l := 1
+	for i := n; i > 127 || i < -128; i >>= 8 {
+		l++
+	}
+	for ; l > 0; l-- {
+		dst = append(dst, byte(n>>(uint(l-1)*8)))
+	}
+	return dst
+}
+
+// appendUint64 appends the minimal two's complement encoding of n to dst.
+func appendUint64(dst []byte, n uint64) []byte {
+	if n <= math.MaxInt64 {
+		return appendInt64(dst, int64(n))
+	}
+	dst = append(dst, 0)
+	for i := 7; i >= 0; i-- {
+		dst = append(dst, byte(n>>(uint(i)*8)))
+	}
+	return dst
+}
+
+// appendBigInt appends the minimal two's complement encoding of n to dst.
+func appendBigInt(dst []byte, n *big.Int) []byte {
+	if n.Sign() >= 0 {
+		b := n.Bytes()
+		if len(b) == 0 || b[0]&0x80 != 0 {
+			dst = append(dst, 0)
+		}
+		return append(dst, b...)
+	}
+	// The two's complement of n is the complement of -n-1.
+	b := new(big.Int).Not(n).Bytes()
+	if len(b) == 0 || b[0]&0x80 != 0 {
+		dst = append(dst, 0xff)
+	}
+	for _, c := range b {
+		dst = append(dst, ^c)
+	}
+	return dst
+}
+
+// appendReal appends the DER contents octets of the REAL value f to dst:
+// nothing for zero, one octet for the special values and the binary form
+// with an odd mantissa otherwise.
+func appendReal(dst []byte, f float64) []byte {
+	switch {
+	case f == 0 && !math.Signbit(f):
+		return dst
+	case f == 0:
+		return append(dst, 0x43)
+	case math.IsInf(f, 1):
+		return append(dst, 0x40)
+	case math.IsInf(f, -1):
+		return append(dst, 0x41)
+	case math.IsNaN(f):
+		return append(dst, 0x42)
+	}
+	first := byte(0x80)
+	if f < 0 {
+		first |= 0x40
+		f = -f
+	}
+	frac, exp := math.Frexp(f)
+	mantissa := uint64(math.Ldexp(frac, 53))
+	exp -= 53
+	for mantissa&1 == 0 {
+		mantissa >>= 1
+		exp++
+	}
+	e := appendInt64(nil, int64(exp))
+	switch len(e) {
+	case 1, 2, 3:
+		first |= byte(len(e) - 1)
+		dst = append(dst, first)
+	default:
+		dst = append(dst, first|0x03, byte(len(e)))
+	}
+	dst = append(dst, e...)
+	m := appendUint64(nil, mantissa)
+	if m[0] == 0 && len(m) > 1 {
+		m = m[1:]
+	}
+	return append(dst, m...)
+}
+
+// appendBitString appends the contents octets of bs to dst, with the
+// unused bits of the last octet set to zero.
+func appendBitString(dst []byte, bs BitString) []byte {
+	n := (bs.BitLength + 7) / 8
+	unused := 8*n - bs.BitLength
+	dst = append(dst, byte(unused))
+	start := len(dst)
+	dst = append(dst, bs.Bytes[:n]...)
+	if n > 0 {
+		dst[start+n-1] &= 0xff << uint(unused)
+	}
+	return dst
+}
+
+// appendOID appends the contents octets of oid to dst.
+func appendOID(dst []byte, oid ObjectIdentifier) ([]byte, error) {
+	if len(oid) < 2 || oid[0] > 2 || oid[0] < 2 && oid[1] >= 40 {
+		return nil, fmt.Errorf("invalid object identifier %v", oid)
+	}
+	dst = appendBase128(dst, uint64(oid[0]*40+oid[1]))
+	for _, c := range oid[2:] {
+		dst = appendBase128(dst, uint64(c))
+	}
+	return dst, nil
+}
+
+// appendRelativeOID appends the contents octets of the RELATIVE-OID value
+// oid to dst.
+func appendRelativeOID(dst []byte, oid ObjectIdentifier) []byte {
+	for _, c := range oid {
+		dst = appendBase128(dst, uint64(c))
+	}
+	return dst
+}
+
+// validString reports whether s only contains characters of the
+// character string type of kind k, or is an OID-IRI, RELATIVE-OID-IRI or
+// time value of kind k.
+func validString(k Kind, s string) bool {
+	switch k {
+	case KindUTF8String:
+		return utf8.ValidString(s)
+	case KindOIDIRI, KindRelativeOIDIRI:
+		return validIRI(s, k == KindRelativeOIDIRI)
+	case KindTime:
+		// Any of the forms of ISO 8601.
+		return s != "" && strings.Trim(s, "0123456789-:.,+/TZPYMWDHSRC") == ""
+	case KindDate, KindTimeOfDay, KindDateTime, KindDuration:
+		_, err := parseDate(k, s)
+		return err == nil
+	case KindUTCTime, KindGeneralizedTime:
+		return strings.Trim(s, "0123456789.,+-Z") == ""
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch k {
+		case KindNumericString:
+			if c != ' ' && !isDigit(c) {
+				return false
+			}
+		case KindPrintableString:
+			if !isLetter(c) && !isDigit(c) && strings.IndexByte(" '()+,-./:=?", c) < 0 {
+				return false
+			}
+		case KindIA5String:
+			if c >= 0x80 {
+				return false
+			}
+		case KindVisibleString:
+			if c < 0x20 || c > 0x7e {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// retag replaces the outermost tag of the encoding b with tag, keeping
+// its constructed bit, for implicit tagging.
+func retag(b []byte, tag Tag) []byte {
+	n := tagLen(b)
+	out := appendTag(nil, tag, b[0]&0x20 != 0)
+	return append(out, b[n:]...)
+}
+
+// tagLen returns the length of the identifier octets at the start of b.
+func tagLen(b []byte) int {
+	if b[0]&0x1f != 0x1f {
+		return 1
+	}
+	n := 1
+	for n < len(b) && b[n]&0x80 != 0 {
+		n++
+	}
+	return n + 1
+}
+
+// tagLess orders encodings by their tags, class first, as DER orders the
+// components of a SET.
+func tagLess(a, b []byte) bool {
+	ta, _, _, _ := parseIdentifier(a, true)
+	tb, _, _, _ := parseIdentifier(b, true)
+	if ta.Class != tb.Class {
+		return ta.Class < tb.Class
+	}
+	return ta.Number < tb.Number
+}
+
+// validTLV reports whether b is a single DER encoded value.
+func validTLV(b []byte) bool {
+	_, _, _, n, err := parseTLV(b)
+	return err == nil && n == len(b)
+}
+
+// A DERSyntaxError describes malformed DER input, or BER input that the
+// decoding options do not accept. It wraps the cause of the error,
+// ErrUnexpectedEOF for truncated input or ErrTrailingData, if it has one
+// of them.
+type DERSyntaxError struct {
+	Msg    string
+	Offset int64 // error occurred after reading Offset bytes
+	err    error // cause of the error, or nil
+}
+
+func (e *DERSyntaxError) Error() string {
+	return "asn1go: DER syntax error at offset " + strconv.FormatInt(e.Offset, 10) + ": " + e.Msg
+}
+
+// Unwrap returns the cause of the error, or nil.
+func (e *DERSyntaxError) Unwrap() error { return e.err }
+
+// parseIdentifier parses the identifier octets at the start of b and
+// returns the tag, whether the encoding is constructed and the number of
+// octets read. If strict is set, tag numbers must be encoded in as few
+// octets as possible, as DER and CER require.
+func parseIdentifier(b []byte, strict bool) (tag Tag, constructed bool, n int, err error) {
+	if len(b) == 0 {
+		return Tag{}, false, 0, &DERSyntaxError{Msg: "truncated tag", Offset: 0, err: ErrUnexpectedEOF}
+	}
+	tag.Class = Class(b[0] >> 6)
+	constructed = b[0]&0x20 != 0
+	if b[0]&0x1f != 0x1f {
+		tag.Number = int(b[0] & 0x1f)
+		return tag, constructed, 1, nil
+	}
+	n = 1
+	for {
+		if n >= len(b) {
+			return Tag{}, false, 0, &DERSyntaxError{Msg: "truncated tag", Offset: int64(n), err: ErrUnexpectedEOF}
+		}
+		c := b[n]
+		n++
+		if strict && tag.Number == 0 && c == 0x80 {
+			return Tag{}, false, 0, &DERSyntaxError{Msg: "non-minimal tag", Offset: int64(n)}
+		}
+		if tag.Number > math.MaxInt32>>7 {
+			return Tag{}, false, 0, &DERSyntaxError{Msg: "tag number too large", Offset: int64(n)}
+		}
+		tag.Number = tag.Number<<7 | int(c&0x7f)
+		if c&0x80 == 0 {
+			break
+		}
+	}
+	if strict && tag.Number < 0x1f {
+		return Tag{}, false, 0, &DERSyntaxError{Msg: "non-minimal tag", Offset: int64(n)}
+	}
+	return tag, constructed, n, nil
+}
+
+// parseHeader parses the identifier and length octets at the start of b.
+// It returns the tag, whether the encoding is constructed, the length of
+// the contents octets, or -1 for the indefinite length, and the number of
+// octets read. If strict is set, tags and lengths must be encoded in as
+// few octets as possible.
+func parseHeader(b []byte, strict bool) (tag Tag, constructed bool, length, n int, err error) {
+	tag, constructed, n, err = parseIdentifier(b, strict)
+	if err != nil {
+		return
+	}
+	if n >= len(b) {
+		return tag, constructed, 0, 0, &DERSyntaxError{Msg: "truncated length", Offset: int64(n), err: ErrUnexpectedEOF}
+	}
+	l := int(b[n])
+	n++
+	if l&0x80 == 0 {
+		return tag, constructed, l, n, nil
+	}
+	k := l & 0x7f
+	if k == 0 {
+		return tag, constructed, -1, n, nil
+	}
+	if n+k > len(b) {
+		return tag, constructed, 0, 0, &DERSyntaxError{Msg: "truncated length", Offset: int64(n), err: ErrUnexpectedEOF}
+	}
+	l = 0
+	for _, c := range b[n : n+k] {
+		if l > math.MaxInt32>>8 {
+			return tag, constructed, 0, 0, &DERSyntaxError{Msg: "length too large", Offset: int64(n)}
+		}
+		l = l<<8 | int(c)
+	}
+	if strict && (b[n] == 0 || l < 0x80) {
+		return tag, constructed, 0, 0, &DERSyntaxError{Msg: "non-minimal length", Offset: int64(n)}
+	}
+	return tag, constructed, l, n + k, nil
+}
+
+// parseTLV parses the DER encoding at the start of b. It returns the tag,
+// whether the encoding is constructed, the contents octets and the length
+// of the whole encoding.
+func parseTLV(b []byte) (tag Tag, constructed bool, content []byte, n int, err error) {
+	tag, constructed, l, n, err := parseHeader(b, true)
+	if err != nil {
+		return tag, constructed, nil, 0, err
+	}
+	if l < 0 {
+		return tag, constructed, nil, 0, &DERSyntaxError{Msg: "indefinite length", Offset: int64(n)}
+	}
+	if l > len(b)-n {
+		return tag, constructed, nil, 0, &DERSyntaxError{Msg: "truncated contents", Offset: int64(len(b)), err: ErrUnexpectedEOF}
+	}
+	return tag, constructed, b[n : n+l], n + l, nil
+}
@@ -0,0 +1,254 @@
+package asn1go
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncodeDER(t *testing.T) {
+	implicit := func(n int, t *Type) *Type {
+		c := *t
+		c.Tags = []TypeTag{{Tag: Tag{ClassContextSpecific, n}}}
+		return &c
+	}
+	integer := &Type{Kind: KindInteger}
+	boolean := &Type{Kind: KindBoolean}
+	seq := &Type{Kind: KindSequence, Components: []Component{
+		{Name: "a", Type: integer},
+		{Name: "b", Type: implicit(0, boolean), Optional: true},
+		{Name: "c", Type: integer, Default: int64(3)},
+	}}
+	set := &Type{Kind: KindSet, Components: []Component{
+		{Name: "z", Type: implicit(1, integer)},
+		{Name: "y", Type: implicit(0, integer)},
+	}}
+	choice := &Type{Kind: KindChoice, Components: []Component{
+		{Name: "a", Type: implicit(0, integer)},
+		{Name: "b", Type: implicit(1, boolean)},
+	}}
+	color := &Type{Kind: KindEnumerated, Named: []NamedNumber{{"red", 0, false}, {"green", 1, false}}}
+	tests := []struct {
+		t    *Type
+		v    interface{}
+		want string
+	}{
+		{boolean, true, "01 01 FF"},
+		{boolean, false, "01 01 00"},
+		{integer, 0, "02 01 00"},
+		{integer, int64(127), "02 01 7F"},
+		{integer, int64(128), "02 02 00 80"},
+		{integer, int8(-128), "02 01 80"},
+		{integer, -129, "02 02 FF 7F"},
+		{integer, uint16(256), "02 02 01 00"},
+		{color, "green", "0A 01 01"},
+		{color, 0, "0A 01 00"},
+		{&Type{Kind: KindNull}, nil, "05 00"},
+		{&Type{Kind: KindOctetString}, []byte{1, 2}, "04 02 01 02"},
+		{&Type{Kind: KindOctetString}, make([]byte, 200), "04 81 C8" + strings.Repeat(" 00", 200)},
+		{&Type{Kind: KindBitString}, BitString{Bytes: []byte{0x60}, BitLength: 4}, "03 02 04 60"},
+		{&Type{Kind: KindBitString}, BitString{}, "03 01 00"},
+		{&Type{Kind: KindObjectIdentifier}, ObjectIdentifier{1, 2, 840, 113549}, "06 06 2A 86 48 86 F7 0D"},
+		{&Type{Kind: KindObjectIdentifier}, ObjectIdentifier{2, 23, 143}, "06 03 67 81 0F"},
+		{&Type{Kind: KindRelativeOID}, RelativeOID{4, 3, 2}, "0D 03 04 03 02"},
+		{&Type{Kind: KindOIDIRI}, OIDIRI("/ISO/a"), "1F 23 06 2F 49 53 4F 2F 61"},
+		{&Type{Kind: KindRelativeOIDIRI}, OIDIRI("a/b"), "1F 24 03 61 2F 62"},
+		{&Type{Kind: KindUTF8String}, "hi", "0C 02 68 69"},
+		{&Type{Kind: KindIA5String}, "a", "16 01 61"},
+		{&Type{Kind: KindReal}, 0.0, "09 00"},
+		{implicit(2, integer), 5, "82 01 05"},
+		{&Type{Kind: KindInteger, Tags: []TypeTag{{Tag: Tag{ClassContextSpecific, 1}, Explicit: true}}}, 5, "A1 03 02 01 05"},
+		{seq, map[string]interface{}{"a": 1}, "30 03 02 01 01"},
+		{seq, OrderedObject{{"a", 1}, {"b", true}, {"c", 3}}, "30 06 02 01 01 80 01 FF"},
+		{seq, OrderedObject{{"a", 1}, {"c", 4}}, "30 06 02 01 01 02 01 04"},
+		{set, map[string]interface{}{"z": 1, "y": 2}, "31 06 80 01 02 81 01 01"},
+		{&Type{Kind: KindSetOf, Elem: integer}, []int{2, 1, 256}, "31 0A 02 01 01 02 01 02 02 02 01 00"},
+		{&Type{Kind: KindSequenceOf, Elem: integer}, []int{2, 1}, "30 06 02 01 02 02 01 01"},
+		{choice, ChoiceValue{"b", true}, "81 01 FF"},
+		{choice, map[string]interface{}{"a": 5}, "80 01 05"},
+		{&Type{Kind: KindChoice, Tags: []TypeTag{{Tag: Tag{ClassContextSpecific, 3}}}, Components: choice.Components}, ChoiceValue{"a", 5}, "A3 03 80 01 05"},
+	}
+	for _, tt := range tests {
+		want := fromHex(tt.want)
+		b, err := EncodeDER(tt.t, tt.v)
+		if err != nil {
+			t.Errorf("EncodeDER(%v, %v): %v", tt.t, tt.v, err)
+			continue
+		}
+		if !bytes.Equal(b, want) {
+			t.Errorf("EncodeDER(%v, %v) = % X, want % X", tt.t, tt.v, b, want)
+		}
+	}
+}
+
+func TestEncodeDERError(t *testing.T) {
+	seq := &Type{Kind: KindSequence, Components: []Component{{Name: "a", Type: &Type{Kind: KindInteger}}}}
+	tests := []struct {
+		t *Type
+		v interface{}
+	}{
+		{&Type{Kind: KindBoolean}, 1},
+		{&Type{Kind: KindInteger}, "x"},
+		{&Type{Kind: KindEnumerated, Named: []NamedNumber{{"red", 0, false}}}, "blue"},
+		{&Type{Kind: KindIA5String}, "é"},
+		{&Type{Kind: KindObjectIdentifier}, ObjectIdentifier{3, 1}},
+		{&Type{Kind: KindOIDIRI}, OIDIRI("ISO/a")},
+		{&Type{Kind: KindRelativeOIDIRI}, OIDIRI("/a")},
+		{&Type{Kind: KindAny}, []byte{0x02}},
+		{seq, map[string]interface{}{}},
+		{seq, map[string]interface{}{"a": true}},
+	}
+	for _, tt := range tests {
+		_, err := EncodeDER(tt.t, tt.v)
+		if _, ok := err.(*ValueError); !ok {
+			t.Errorf("EncodeDER(%v, %v): error %v, want ValueError", tt.t, tt.v, err)
+		}
+	}
+}
+
+func TestMarshalDER(t *testing.T) {
+	type record struct {
+		Flag   bool
+		Number int
+		Data   []byte `asn1:",omitempty"`
+	}
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{true, "01 01 FF"},
+		{-1, "02 01 FF"},
+		{"ab", "0C 02 61 62"},
+		{[]int{1}, "30 03 02 01 01"},
+		{record{true, 5, []byte{0xAB}}, "30 09 01 01 FF 02 01 05 04 01 AB"},
+		{record{Number: 256}, "30 07 01 01 00 02 02 01 00"},
+		{&record{Flag: true}, "30 06 01 01 FF 02 01 00"},
+	}
+	for _, tt := range tests {
+		want := fromHex(tt.want)
+		b, err := MarshalDER(tt.v)
+		if err != nil {
+			t.Errorf("MarshalDER(%#v): %v", tt.v, err)
+			continue
+		}
+		if !bytes.Equal(b, want) {
+			t.Errorf("MarshalDER(%#v) = % X, want % X", tt.v, b, want)
+		}
+	}
+	if _, err := MarshalDER(make(chan int)); err == nil {
+		t.Error("MarshalDER of a channel: no error")
+	}
+}
+
+func TestTypeNamedValue(t *testing.T) {
+	color := &Type{Kind: KindEnumerated, Named: []NamedNumber{{"red", 0, false}, {"green", 1, false}, {"blue", 5, true}}}
+	tests := []struct {
+		name string
+		want int64
+		ok   bool
+	}{
+		{"red", 0, true},
+		{"green", 1, true},
+		{"blue", 5, true},
+		{"black", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		if n, ok := color.NamedValue(tt.name); n != tt.want || ok != tt.ok {
+			t.Errorf("NamedValue(%q) = %d, %v, want %d, %v", tt.name, n, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestMarshalDERTagClass(t *testing.T) {
+	type context struct {
+		A int `asn1:",tag:1"`
+	}
+	type application struct {
+		A int `asn1:",tag:1,application"`
+	}
+	type private struct {
+		A int `asn1:",tag:1,private"`
+	}
+	type contextExplicit struct {
+		A int `asn1:",tag:1,explicit"`
+	}
+	type applicationExplicit struct {
+		A int `asn1:",tag:1,application,explicit"`
+	}
+	type privateExplicit struct {
+		A int `asn1:",tag:1,private,explicit"`
+	}
+	type highNumber struct {
+		A int `asn1:",tag:31,private"`
+	}
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{context{5}, "30 03 81 01 05"},
+		{application{5}, "30 03 41 01 05"},
+		{private{5}, "30 03 C1 01 05"},
+		{contextExplicit{5}, "30 05 A1 03 02 01 05"},
+		{applicationExplicit{5}, "30 05 61 03 02 01 05"},
+		{privateExplicit{5}, "30 05 E1 03 02 01 05"},
+		{highNumber{5}, "30 04 DF 1F 01 05"},
+	}
+	for _, tt := range tests {
+		want := fromHex(tt.want)
+		b, err := MarshalDER(tt.v)
+		if err != nil {
+			t.Errorf("MarshalDER(%#v): %v", tt.v, err)
+			continue
+		}
+		if !bytes.Equal(b, want) {
+			t.Errorf("MarshalDER(%#v) = % X, want % X", tt.v, b, want)
+		}
+		back := reflect.New(reflect.TypeOf(tt.v))
+		if err := UnmarshalDER(want, back.Interface()); err != nil {
+			t.Errorf("UnmarshalDER(%s, %T): %v", tt.want, tt.v, err)
+			continue
+		}
+		if got := back.Elem().Interface(); got != tt.v {
+			t.Errorf("UnmarshalDER(%s, %T) = %#v, want %#v", tt.want, tt.v, got, tt.v)
+		}
+	}
+
+	// A tag of another class does not decode.
+	if err := UnmarshalDER(fromHex("30 03 41 01 05"), new(private)); err == nil {
+		t.Error("UnmarshalDER of an APPLICATION tag for a PRIVATE one: no error")
+	}
+}
+
+func TestMarshalDERTagOptionError(t *testing.T) {
+	type applicationPrivate struct {
+		A int `asn1:",tag:1,application,private"`
+	}
+	type explicitImplicit struct {
+		A int `asn1:",tag:1,explicit,implicit"`
+	}
+	type classWithoutTag struct {
+		A int `asn1:",application"`
+	}
+	type negativeTag struct {
+		A int `asn1:",tag:-1"`
+	}
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{applicationPrivate{}, "both application and private"},
+		{explicitImplicit{}, "both explicit and implicit"},
+		{classWithoutTag{}, "without tag:<number>"},
+		{negativeTag{}, "invalid tag number"},
+	}
+	for _, tt := range tests {
+		if _, err := MarshalDER(tt.v); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("MarshalDER(%T): error %v, want %q", tt.v, err, tt.want)
+		}
+		if err := UnmarshalDER(fromHex("30 00"), reflect.New(reflect.TypeOf(tt.v)).Interface()); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("UnmarshalDER(%T): error %v, want %q", tt.v, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,584 @@
+package asn1go
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// UnmarshalDER parses the DER encoded data and stores the result in the
+// value pointed to by v, which must be a non-nil pointer. The ASN.1 type
+// of the encoding is read from the Go type of v as TypeOf describes, so
+// the struct types and asn1 tags that Unmarshal fills from value notation
+// also read the binary encoding of the same values.
+func UnmarshalDER(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+	t, err := TypeOf(v)
+	if err != nil {
+		return err
+	}
+	return DecodeDER(t, data, v)
+}
+
+// DecodeDER parses data as the DER encoding of a value of the ASN.1 type t
+// and stores the result in the value pointed to by v, which must be a
+// non-nil pointer.
+//
+// DecodeDER stores values in Go values as Unmarshal stores the value
+// notation of the same values; a string receives the hexadecimal digits
+// of an OCTET STRING, and the identifier of a named INTEGER or ENUMERATED
+// value. Values of an open type are stored as their encoding in a []byte.
+// A component left out because it equals its DEFAULT value is set to the
+// default. Extension additions of an extensible type that t does not know
+// are stored as Extensions, which EncodeDER writes back.
+//
+// To decode into an interface value, DecodeDER stores the forms EncodeDER
+// accepts: map[string]interface{} for SEQUENCE and SET values,
+// []interface{} for SEQUENCE OF and SET OF values, a single-entry
+// map[string]interface{} for CHOICE values, []byte for OCTET STRING and
+// open type values, BitString, ObjectIdentifier, RelativeOID, string for
+// character strings, OID-IRI values and ENUMERATED values, int64 for
+// integers, float64, bool and nil for NULL.
+//
+// Malformed input, encodings that do not match t and BER encodings that
+// are not valid DER are reported as a DERSyntaxError; DecodeBER and
+// DecodeOptions accept those. If a value is not appropriate for a given
+// target type, DecodeDER skips that value and completes the decoding as
+// best it can, and then returns an UnmarshalTypeError describing the
+// earliest such error.
+func DecodeDER(t *Type, data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+	d := derDecoder{data: data, opts: derDecodeOptions}
+	return d.unmarshal(t, rv)
+}
+
+// A derDecoder decodes BER encodings, of which DER and CER ones are
+// special cases.
+type derDecoder struct {
+	storer
+	data []byte // the whole input, for error offsets
+	opts DecodeOptions
+}
+
+// A tlv is an encoding found by parseTLV.
+type tlv struct {
+	tag         Tag
+	constructed bool
+	content     []byte
+	raw         []byte // the whole encoding
+	off         int    // offset of the encoding in the input
+}
+
+func (d *derDecoder) unmarshal(t *Type, v reflect.Value) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if je, ok := r.(asn1Error); ok {
+				err = je.error
+			} else {
+				panic(r)
+			}
+		}
+	}()
+	x, n := d.parse(d.data, 0)
+	if n != len(d.data) {
+		d.syntaxErrorOf(ErrTrailingData, n, "trailing data after top-level value")
+	}
+	d.value(t, x, v)
+	return d.savedError
+}
+
+// parse parses the encoding at the start of b, which is at offset off in
+// the input, and returns it with its length.
+func (d *derDecoder) parse(b []byte, off int) (tlv, int) {
+	tag, constructed, l, n, err := parseHeader(b, d.opts.RejectNonCanonical)
+	if err != nil {
+		se := err.(*DERSyntaxError)
+		se.Offset += int64(off)
+		panic(asn1Error{se})
+	}
+	if l >= 0 {
+		if l > len(b)-n {
+			d.syntaxErrorOf(ErrUnexpectedEOF, off+len(b), "truncated contents")
+		}
+		return tlv{tag, constructed, b[n : n+l], b[:n+l], off}, n + l
+	}
+
+	// The contents of an indefinite-length encoding are encodings up to
+	// the end-of-contents octets 00 00.
+	switch {
+	case !constructed:
+		d.syntaxError(off+n, "indefinite length of primitive encoding")
+	case d.opts.RejectIndefiniteLength:
+		d.syntaxError(off+n, "indefinite length")
+	}
+	end := n
+	for {
+		if end+2 <= len(b) && b[end] == 0 && b[end+1] == 0 {
+			return tlv{tag, constructed, b[n:end], b[:end+2], off}, end + 2
+		}
+		if end >= len(b) {
+			d.syntaxErrorOf(ErrUnexpectedEOF, off+end, "missing end-of-contents")
+		}
+		_, m := d.parse(b[end:], off+end)
+		end += m
+	}
+}
+
+// elements returns the encodings in the contents octets of x.
+func (d *derDecoder) elements(x tlv) []tlv {
+	b, off := x.content, x.off+len(x.raw)-len(x.content)
+	var elems []tlv
+	for len(b) > 0 {
+		e, n := d.parse(b, off)
+		elems = append(elems, e)
+		b, off = b[n:], off+n
+	}
+	return elems
+}
+
+// syntaxError aborts the decoding with a DERSyntaxError at offset off.
+func (d *derDecoder) syntaxError(off int, format string, args ...interface{}) {
+	d.syntaxErrorOf(nil, off, format, args...)
+}
+
+// syntaxErrorOf is like syntaxError but records err as the cause of the
+// error.
+func (d *derDecoder) syntaxErrorOf(err error, off int, format string, args ...interface{}) {
+	panic(asn1Error{&DERSyntaxError{Msg: fmt.Sprintf(format, args...), Offset: int64(off), err: err}})
+}
+
+// value decodes the encoding x of a value of type t into v.
+func (d *derDecoder) value(t *Type, x tlv, v reflect.Value) {
+	if v = target(v); v.IsValid() && v.Type() == valueType {
+		d.dynamic(t, x.off, v, func(v reflect.Value) { d.value(t, x, v) })
+		return
+	}
+	x, want := d.untag(t, x)
+	switch t.Kind {
+	case KindChoice:
+		d.choice(t, x, v)
+	case KindAny:
+		d.store(t, x.off, append([]byte(nil), x.raw...), v)
+	case KindSequence, KindSet:
+		d.expect(x, want, true)
+		d.components(t, x, v)
+	case KindSequenceOf, KindSetOf:
+		d.expect(x, want, true)
+		d.elementsOf(t, x, v)
+	default:
+		if x.constructed && isStringKind(t.Kind) && !d.opts.RejectConstructedStrings {
+			x = d.joinSegments(t, x)
+		}
+		d.expect(x, want, false)
+		d.store(t, x.off, d.primitive(t, x), v)
+	}
+}
+
+// untag takes the explicit tags of type t off the encoding x. It returns
+// the encoding inside them and the tag it must have, which is the
+// universal tag of t unless an implicit tag replaces it. The tag is
+// meaningless for CHOICE and open types.
+func (d *derDecoder) untag(t *Type, x tlv) (tlv, Tag) {
+	// An implicit tag stands in for the tag of the next explicit tag or
+	// of the value itself.
+	var implicit Tag
+	haveImplicit := false
+	for i, tt := range t.Tags {
+		if !tt.Explicit && !(i == len(t.Tags)-1 && (t.Kind == KindChoice || t.Kind == KindAny)) {
+			if !haveImplicit {
+				implicit, haveImplicit = tt.Tag, true
+			}
+			continue
+		}
+		want := tt.Tag
+		if haveImplicit {
+			want, haveImplicit = implicit, false
+		}
+		d.expect(x, want, true)
+		inner, n := d.parse(x.content, x.off+len(x.raw)-len(x.content))
+		if n != len(x.content) {
+			d.syntaxErrorOf(ErrTrailingData, inner.off+n, "trailing data in explicitly tagged value")
+		}
+		x = inner
+	}
+	if haveImplicit {
+		return x, implicit
+	}
+	return x, Tag{ClassUniversal, t.Kind.universalTag()}
+}
+
+// expect checks that x has the tag want and the given form.
+func (d *derDecoder) expect(x tlv, want Tag, constructed bool) {
+	if x.tag != want {
+		d.syntaxError(x.off, "unexpected tag %v, want %v", x.tag, want)
+	}
+	if x.constructed != constructed {
+		if constructed {
+			d.syntaxError(x.off, "primitive encoding of constructed type %v", want)
+		}
+		d.syntaxError(x.off, "constructed encoding of primitive type %v", want)
+	}
+}
+
+// matches reports whether an encoding with the given tag can be a value
+// of type t.
+func matches(t *Type, tag Tag) bool {
+	if tt, ok := t.Tag(); ok {
+		return tt == tag
+	}
+	if t.Kind == KindChoice {
+		for i := range t.Components {
+			if matches(t.Components[i].Type, tag) {
+				return true
+			}
+		}
+		return false
+	}
+	return true // an untagged open type
+}
+
+// primitive returns the value of the primitive encoding x of type t, in
+// the form DecodeDER stores in an interface.
+func (d *derDecoder) primitive(t *Type, x tlv) interface{} {
+	b := x.content
+	switch t.Kind {
+	case KindBoolean:
+		if len(b) != 1 || d.opts.RejectNonCanonical && b[0] != 0x00 && b[0] != 0xff {
+			d.syntaxError(x.off, "invalid BOOLEAN")
+		}
+		return b[0] != 0
+
+	case KindInteger, KindEnumerated:
+		if len(b) == 0 {
+			d.syntaxError(x.off, "empty %v", t.Kind)
+		}
+		if nonMinimal := len(b) > 1 && (b[0] == 0 && b[1]&0x80 == 0 || b[0] == 0xff && b[1]&0x80 != 0); nonMinimal {
+			if d.opts.RejectNonCanonical {
+				d.syntaxError(x.off, "non-minimal %v", t.Kind)
+			}
+			b = trimInteger(b)
+		}
+		return parseInteger(b)
+
+	case KindReal:
+		f, err := parseReal(b)
+		if err != nil {
+			d.syntaxError(x.off, "%v", err)
+		}
+		return f
+
+	case KindBitString:
+		if len(b) == 0 || b[0] > 7 || len(b) == 1 && b[0] != 0 {
+			d.syntaxError(x.off, "invalid BIT STRING")
+		}
+		bs := BitString{Bytes: append([]byte(nil), b[1:]...), BitLength: 8*(len(b)-1) - int(b[0])}
+		if last := len(bs.Bytes) - 1; last >= 0 && bs.Bytes[last]&(1<<b[0]-1) != 0 {
+			if d.opts.RejectNonCanonical {
+				d.syntaxError(x.off, "BIT STRING with unused bits set")
+			}
+			bs.Bytes[last] &^= 1<<b[0] - 1
+		}
+		return bs
+
+	case KindOctetString:
+		return append([]byte(nil), b...)
+
+	case KindNull:
+		if len(b) != 0 {
+			d.syntaxError(x.off, "invalid NULL")
+		}
+		return nil
+
+	case KindObjectIdentifier:
+		oid, ok := parseOID(b)
+		if !ok {
+			d.syntaxError(x.off, "invalid OBJECT IDENTIFIER")
+		}
+		return oid
+
+	case KindRelativeOID:
+		oid, ok := parseRelativeOID(b)
+		if !ok {
+			d.syntaxError(x.off, "invalid RELATIVE-OID")
+		}
+		return oid
+
+	case KindUTF8String, KindNumericString, KindPrintableString, KindIA5String, KindVisibleString,
+		KindUTCTime, KindGeneralizedTime, KindOIDIRI, KindRelativeOIDIRI,
+		KindTime, KindDate, KindTimeOfDay, KindDateTime, KindDuration:
+		s := string(b)
+		if !validString(t.Kind, s) {
+			d.syntaxError(x.off, "invalid character in %v", t.Kind)
+		}
+		return s
+	}
+	d.syntaxError(x.off, "unsupported type %v", t)
+	panic("unreachable")
+}
+
+// components decodes the components of the SEQUENCE or SET encoding x
+// into the struct or map v.
+func (d *derDecoder) components(t *Type, x tlv, v reflect.Value) {
+	ct := d.composite(t, x.off, v)
+	elems, index, found := d.componentsOf(t, x)
+	var ext Extensions
+	for i, e := range elems {
+		if index[i] < 0 {
+			ext = append(ext, append([]byte(nil), e.raw...))
+			continue
+		}
+		c := &t.Components[index[i]]
+		d.component(ct, c, e.off, func(v reflect.Value) { d.value(c.Type, e, v) })
+	}
+	if ext != nil {
+		d.component(ct, &extensionComponent, x.off, func(v reflect.Value) { d.storeExtensions(t, x.off, ext, v) })
+	}
+	d.finish(ct, t, x.off, found)
+}
+
+// componentsOf returns the elements of the SEQUENCE or SET encoding x of
+// type t, the index of the component each is a value of and which
+// components are present. Elements of unknown extension additions of an
+// extensible type have the index -1. It checks that no other components
+// are missing than OPTIONAL and DEFAULT ones.
+func (d *derDecoder) componentsOf(t *Type, x tlv) (elems []tlv, index []int, found []bool) {
+	elems = d.elements(x)
+	index = make([]int, len(elems))
+	found = make([]bool, len(t.Components))
+	next := 0 // the first component of a SEQUENCE an element can be
+	for i, e := range elems {
+		j := -1
+		if t.Kind == KindSet {
+			j = componentFor(t, e.tag, found)
+		} else {
+			for k := next; k < len(t.Components); k++ {
+				if matches(t.Components[k].Type, e.tag) {
+					j, next = k, k+1
+					break
+				}
+			}
+		}
+		if j < 0 && !t.Extensible {
+			d.syntaxError(e.off, "unexpected component with tag %v in %v", e.tag, t)
+		}
+		if index[i] = j; j >= 0 {
+			found[j] = true
+		}
+	}
+	for i := range t.Components {
+		if c := &t.Components[i]; !found[i] && !c.Optional && c.Default == nil && !c.Extension {
+			d.syntaxError(x.off, "missing component %s of %v", c.Name, t)
+		}
+	}
+	return elems, index, found
+}
+
+// componentFor returns the index of the component of the SET type t that
+// an encoding with the given tag is a value of, skipping those already
+// found, or -1.
+func componentFor(t *Type, tag Tag, found []bool) int {
+	for i := range t.Components {
+		if !found[i] && matches(t.Components[i].Type, tag) {
+			return i
+		}
+	}
+	return -1
+}
+
+// elementsOf decodes the elements of the SEQUENCE OF or SET OF encoding x
+// into the slice or array v.
+func (d *derDecoder) elementsOf(t *Type, x tlv, v reflect.Value) {
+	l := d.list(t, x.off, v)
+	for _, e := range d.elements(x) {
+		d.value(t.Elem, e, l.next())
+	}
+	l.finish()
+}
+
+// choice decodes the CHOICE encoding x into the ChoiceValue, the struct
+// with "choice" fields or the map v.
+func (d *derDecoder) choice(t *Type, x tlv, v reflect.Value) {
+	c := d.alternative(t, x)
+	if c == nil {
+		ext := Extensions{append([]byte(nil), x.raw...)}
+		d.storeChoice(t, &extensionComponent, x.off, v, func(v reflect.Value) { d.storeExtensions(t, x.off, ext, v) })
+		return
+	}
+	d.storeChoice(t, c, x.off, v, func(v reflect.Value) { d.value(c.Type, x, v) })
+}
+
+// alternative returns the alternative of the CHOICE type t that the
+// encoding x is a value of, or nil for an unknown extension addition of
+// an extensible type.
+func (d *derDecoder) alternative(t *Type, x tlv) *Component {
+	for i := range t.Components {
+		if matches(t.Components[i].Type, x.tag) {
+			return &t.Components[i]
+		}
+	}
+	if t.Extensible {
+		return nil
+	}
+	d.syntaxError(x.off, "unexpected tag %v for %v", x.tag, t)
+	panic("unreachable")
+}
+
+// parseInteger parses the minimal two's complement integer b as an int64,
+// a uint64 if it is too large for one, or a *big.Int if it is too large
+// for either.
+func parseInteger(b []byte) interface{} {
+	if len(b) == 9 && b[0] == 0 {
+		var n uint64
+		for _, c := range b[1:] {
+			n = n<<8 | uint64(c)
+		}
+		return n
+	}
+	if len(b) > 8 {
+		n := new(big.Int).SetBytes(b)
+		if b[0]&0x80 != 0 {
+			n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(b))*8))
+		}
+		return n
+	}
+	n := int64(int8(b[0]))
+	for _, c := range b[1:] {
+		n = n<<8 | int64(c)
+	}
+	return n
+}
+
+// parseReal parses the contents octets of a REAL value.
+func parseReal(b []byte) (float64, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	switch first := b[0]; {
+	case first&0x80 != 0: // binary
+		var baseBits int // log2 of the base
+		switch first >> 4 & 0x03 {
+		case 0:
+			baseBits = 1
+		case 1:
+			baseBits = 3
+		case 2:
+			baseBits = 4
+		default:
+			return 0, fmt.Errorf("invalid REAL base")
+		}
+		scale := int(first >> 2 & 0x03)
+		b = b[1:]
+		l := int(first & 0x03)
+		if l == 3 {
+			if len(b) == 0 {
+				return 0, fmt.Errorf("truncated REAL")
+			}
+			l, b = int(b[0]), b[1:]
+		} else {
+			l++
+		}
+		if l == 0 || l > 4 || len(b) <= l {
+			return 0, fmt.Errorf("invalid REAL exponent")
+		}
+		exp := int(int8(b[0]))
+		for _, c := range b[1:l] {
+			exp = exp<<8 | int(c)
+		}
+		b = b[l:]
+		if len(b) > 8 {
+			return 0, fmt.Errorf("REAL mantissa too large")
+		}
+		var mantissa uint64
+		for _, c := range b {
+			mantissa = mantissa<<8 | uint64(c)
+		}
+		f := math.Ldexp(float64(mantissa), exp*baseBits+scale)
+		if first&0x40 != 0 {
+			f = -f
+		}
+		return f, nil
+
+	case first&0x40 != 0: // special
+		if len(b) != 1 {
+			return 0, fmt.Errorf("invalid special REAL")
+		}
+		switch first {
+		case 0x40:
+			return math.Inf(1), nil
+		case 0x41:
+			return math.Inf(-1), nil
+		case 0x42:
+			return math.NaN(), nil
+		case 0x43:
+			return math.Copysign(0, -1), nil
+		}
+		return 0, fmt.Errorf("invalid special REAL")
+
+	default: // decimal, in ISO 6093 form NR1, NR2 or NR3
+		if form := first & 0x3f; form < 1 || form > 3 {
+			return 0, fmt.Errorf("invalid decimal REAL form")
+		}
+		s := strings.TrimLeft(string(b[1:]), " ")
+		s = strings.Replace(s, ",", ".", 1)
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid decimal REAL %q", s)
+		}
+		return f, nil
+	}
+}
+
+// parseOID parses the contents octets of an OBJECT IDENTIFIER value.
+func parseOID(b []byte) (ObjectIdentifier, bool) {
+	arcs, ok := parseRelativeOID(b)
+	if !ok {
+		return nil, false
+	}
+	// The first subidentifier holds the first two arcs.
+	n := arcs[0]
+	var oid ObjectIdentifier
+	switch {
+	case n < 40:
+		oid = append(oid, 0, n)
+	case n < 80:
+		oid = append(oid, 1, n-40)
+	default:
+		oid = append(oid, 2, n-80)
+	}
+	return append(oid, arcs[1:]...), true
+}
+
+// parseRelativeOID parses the contents octets of a RELATIVE-OID value.
+func parseRelativeOID(b []byte) (RelativeOID, bool) {
+	var oid RelativeOID
+	for len(b) > 0 {
+		if b[0] == 0x80 {
+			return nil, false // non-minimal
+		}
+		var n uint64
+		i := 0
+		for {
+			if i == len(b) || n > math.MaxInt32>>7 {
+				return nil, false
+			}
+			c := b[i]
+			i++
+			n = n<<7 | uint64(c&0x7f)
+			if c&0x80 == 0 {
+				break
+			}
+		}
+		b = b[i:]
+		oid = append(oid, int(n))
+	}
+	return oid, oid != nil
+}
@@ -0,0 +1,118 @@
+package asn1go
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeDER(t *testing.T) {
+	integer := &Type{Kind: KindInteger}
+	seq := &Type{Kind: KindSequence, Components: []Component{
+		{Name: "a", Type: integer},
+		{Name: "b", Type: &Type{Kind: KindBoolean, Tags: []TypeTag{{Tag: Tag{ClassContextSpecific, 0}}}}, Optional: true},
+		{Name: "c", Type: integer, Default: int64(3)},
+	}}
+	choice := &Type{Kind: KindChoice, Components: []Component{
+		{Name: "a", Type: &Type{Kind: KindInteger, Tags: []TypeTag{{Tag: Tag{ClassContextSpecific, 0}}}}},
+		{Name: "b", Type: &Type{Kind: KindBoolean, Tags: []TypeTag{{Tag: Tag{ClassContextSpecific, 1}}}}},
+	}}
+	color := &Type{Kind: KindEnumerated, Named: []NamedNumber{{"red", 0, false}, {"green", 1, false}}}
+	tests := []struct {
+		t    *Type
+		data string
+		want interface{}
+	}{
+		{&Type{Kind: KindBoolean}, "01 01 FF", true},
+		{integer, "02 02 FF 7F", int64(-129)},
+		{integer, "02 02 00 80", int64(128)},
+		{color, "0A 01 01", "green"},
+		{&Type{Kind: KindNull}, "05 00", nil},
+		{&Type{Kind: KindOctetString}, "04 02 01 02", []byte{1, 2}},
+		{&Type{Kind: KindBitString}, "03 02 04 60", BitString{Bytes: []byte{0x60}, BitLength: 4}},
+		{&Type{Kind: KindObjectIdentifier}, "06 06 2A 86 48 86 F7 0D", ObjectIdentifier{1, 2, 840, 113549}},
+		{&Type{Kind: KindRelativeOID}, "0D 03 04 03 02", RelativeOID{4, 3, 2}},
+		{&Type{Kind: KindOIDIRI}, "1F 23 06 2F 49 53 4F 2F 61", "/ISO/a"},
+		{&Type{Kind: KindUTF8String}, "0C 02 68 69", "hi"},
+		{&Type{Kind: KindReal}, "09 00", 0.0},
+		{&Type{Kind: KindInteger, Tags: []TypeTag{{Tag: Tag{ClassContextSpecific, 1}, Explicit: true}}}, "A1 03 02 01 05", int64(5)},
+		{seq, "30 03 02 01 01", map[string]interface{}{"a": int64(1), "c": int64(3)}},
+		{seq, "30 09 02 01 01 80 01 FF 02 01 04", map[string]interface{}{"a": int64(1), "b": true, "c": int64(4)}},
+		{&Type{Kind: KindSetOf, Elem: integer}, "31 06 02 01 01 02 01 02", []interface{}{int64(1), int64(2)}},
+		{choice, "81 01 00", map[string]interface{}{"b": false}},
+	}
+	for _, tt := range tests {
+		var got interface{}
+		if err := DecodeDER(tt.t, fromHex(tt.data), &got); err != nil {
+			t.Errorf("DecodeDER(%v, %s): %v", tt.t, tt.data, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("DecodeDER(%v, %s) = %#v, want %#v", tt.t, tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeDERError(t *testing.T) {
+	integer := &Type{Kind: KindInteger}
+	tests := []struct {
+		t    *Type
+		data string
+	}{
+		{&Type{Kind: KindBoolean}, "01 01 01"},        // not DER
+		{integer, "02 02 00 05"},                      // not minimal
+		{integer, "02 81 01 05"},                      // long form length
+		{integer, "02 02 05"},                         // truncated
+		{integer, "02 01 05 00"},                      // trailing data
+		{integer, "01 01 FF"},                         // wrong tag
+		{&Type{Kind: KindOctetString}, "24 80 00 00"}, // constructed
+		{&Type{Kind: KindSequence, Components: []Component{{Name: "a", Type: integer}}}, "30 00"},
+	}
+	for _, tt := range tests {
+		var v interface{}
+		err := DecodeDER(tt.t, fromHex(tt.data), &v)
+		if _, ok := err.(*DERSyntaxError); !ok {
+			t.Errorf("DecodeDER(%v, %s): error %v, want DERSyntaxError", tt.t, tt.data, err)
+		}
+	}
+	var b bool
+	if err := DecodeDER(integer, fromHex("02 01 05"), &b); err == nil {
+		t.Error("DecodeDER of an INTEGER into a bool: no error")
+	} else if _, ok := err.(*UnmarshalTypeError); !ok {
+		t.Errorf("DecodeDER of an INTEGER into a bool: error %v, want UnmarshalTypeError", err)
+	}
+	if err := DecodeDER(integer, fromHex("02 01 05"), b); err == nil {
+		t.Error("DecodeDER into a non-pointer: no error")
+	}
+}
+
+func TestUnmarshalDER(t *testing.T) {
+	type record struct {
+		Flag   bool
+		Number int
+		Data   []byte `asn1:",omitempty"`
+	}
+	tests := []struct {
+		data string
+		ptr  interface{}
+		want interface{}
+	}{
+		{"01 01 FF", new(bool), true},
+		{"02 01 FF", new(int), -1},
+		{"0C 02 61 62", new(string), "ab"},
+		{"30 06 02 01 01 02 01 02", new([]int), []int{1, 2}},
+		{"30 09 01 01 FF 02 01 05 04 01 AB", new(record), record{true, 5, []byte{0xAB}}},
+		{"30 06 01 01 00 02 01 07", new(record), record{Number: 7}},
+	}
+	for _, tt := range tests {
+		if err := UnmarshalDER(fromHex(tt.data), tt.ptr); err != nil {
+			t.Errorf("UnmarshalDER(%s, %T): %v", tt.data, tt.ptr, err)
+			continue
+		}
+		if got := reflect.ValueOf(tt.ptr).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("UnmarshalDER(%s, %T) = %#v, want %#v", tt.data, tt.ptr, got, tt.want)
+		}
+	}
+	if err := UnmarshalDER(fromHex("01 01 FF"), nil); err == nil {
+		t.Error("UnmarshalDER into nil: no error")
+	}
+}
@@ -0,0 +1,346 @@
+package asn1go
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/openesim/asn1go/ast"
+)
+
+// A ChangeKind is the kind of a Change.
+type ChangeKind int
+
+const (
+	ChangeAdded    ChangeKind = iota // a value only the new document has
+	ChangeRemoved                    // a value only the old document has
+	ChangeModified                   // a value the documents write differently
+)
+
+var changeKindNames = []string{"added", "removed", "modified"}
+
+func (k ChangeKind) String() string {
+	if k >= 0 && int(k) < len(changeKindNames) {
+		return changeKindNames[k]
+	}
+	return "ChangeKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// A Change is a difference between two value notation documents, as Diff
+// reports it.
+type Change struct {
+	Kind ChangeKind
+
+	// Path is the path of the value in the style of the paths of a
+	// Document, such as "value4.genericFileManagement.fileManagementCMD[0]".
+	// Indexes of elements are those of the old document, except for
+	// added elements. A top-level value without a value assignment is
+	// addressed by its index among those values, as in "[0]".
+	Path string
+
+	// Old and New are the value in the old and the new document, in
+	// compact value notation, or "" for an added or a removed value.
+	Old, New string
+}
+
+// String returns the change as a line of a report: the path with the new
+// value after "+" for an added value, with the old value after "-" for a
+// removed one, and with both, "old -> new", after "~" for a modified one.
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return "+ " + c.Path + " " + c.New
+	case ChangeRemoved:
+		return "- " + c.Path + " " + c.Old
+	}
+	return "~ " + c.Path + " " + c.Old + " -> " + c.New
+}
+
+// Diff compares the value notation documents a and b, such as two
+// versions of a profile template, and returns the values that were added,
+// removed or modified from a to b. It compares values rather than text:
+// space, comments, line breaks in strings and the case of hex digits do
+// not count, nor does the order of components, which are matched by their
+// identifiers, and numbers are compared by value. Value assignments are
+// matched by their value references, and the elements of SEQUENCE OF
+// values by their positions.
+//
+// Malformed value notation is reported as a SyntaxError.
+func Diff(a, b []byte) ([]Change, error) {
+	return DiffSchema(nil, a, b)
+}
+
+// DiffSchema is like Diff but looks up the types of the value assignments
+// in the module schema, to match the elements of SET OF values regardless
+// of their order: equal elements are not reported wherever they are, and
+// the others are compared in the order they are left in.
+func DiffSchema(schema *Module, a, b []byte) ([]Change, error) {
+	as, err := parse(a, nil)
+	if err != nil {
+		return nil, err
+	}
+	bs, err := parse(b, nil)
+	if err != nil {
+		return nil, err
+	}
+	d := differ{schema: schema, a: a, b: b}
+	d.assignments(as, bs)
+	return d.changes, nil
+}
+
+// A differ compares two documents, collecting their changes.
+type differ struct {
+	schema  *Module
+	a, b    []byte
+	changes []Change
+}
+
+// assignments compares the top-level values as, of the old document, and
+// bs, of the new one.
+func (d *differ) assignments(as, bs []*ast.Assignment) {
+	named := make(map[string]*ast.Assignment)
+	var plain []*ast.Assignment
+	for _, y := range bs {
+		if y.Name == nil {
+			plain = append(plain, y)
+		} else {
+			named[y.Name.Name] = y
+		}
+	}
+
+	seen := make(map[string]bool)
+	i := 0
+	for _, x := range as {
+		if x.Name == nil {
+			path := "[" + strconv.Itoa(i) + "]"
+			if i < len(plain) {
+				d.assignment(path, x, plain[i])
+			} else {
+				d.removed(path, x.Value)
+			}
+			i++
+			continue
+		}
+		seen[x.Name.Name] = true
+		if y, ok := named[x.Name.Name]; ok {
+			d.assignment(x.Name.Name, x, y)
+		} else {
+			d.removed(x.Name.Name, x.Value)
+		}
+	}
+	for ; i < len(plain); i++ {
+		d.added("["+strconv.Itoa(i)+"]", plain[i].Value)
+	}
+	for _, y := range bs {
+		if y.Name != nil && !seen[y.Name.Name] {
+			seen[y.Name.Name] = true
+			d.added(y.Name.Name, y.Value)
+		}
+	}
+}
+
+// assignment compares the top-level values x and y at path. A value whose
+// type changed is modified as a whole, with its type.
+func (d *differ) assignment(path string, x, y *ast.Assignment) {
+	if typeName(x) != typeName(y) {
+		d.changes = append(d.changes, Change{
+			Kind: ChangeModified,
+			Path: path,
+			Old:  typeName(x) + " ::= " + d.text(d.a, x.Value),
+			New:  typeName(y) + " ::= " + d.text(d.b, y.Value),
+		})
+		return
+	}
+	var t *Type
+	if d.schema != nil && x.Type != nil {
+		t = d.schema.Type(x.Type.Name)
+	}
+	d.value(path, t, x.Value, y.Value)
+}
+
+// typeName returns the type of the value assignment a, or "" for a plain
+// value.
+func typeName(a *ast.Assignment) string {
+	if a.Type == nil {
+		return ""
+	}
+	return a.Type.Name
+}
+
+// value compares the values x and y at path, of type t if it is known.
+func (d *differ) value(path string, t *Type, x, y ast.Node) {
+	switch x := x.(type) {
+	case *ast.ObjectNode:
+		if y, ok := y.(*ast.ObjectNode); ok {
+			if isComponents(x) && isComponents(y) {
+				d.components(path, t, x, y)
+			} else {
+				d.elements(path, t, x.Elements, y.Elements)
+			}
+			return
+		}
+	case *ast.FieldNode:
+		// A component among the elements of a list.
+		if y, ok := y.(*ast.FieldNode); ok && x.Name.Name == y.Name.Name {
+			d.value(path, t, x.Value, y.Value)
+			return
+		}
+	case *ast.ChoiceNode:
+		if y, ok := y.(*ast.ChoiceNode); ok && x.Name.Name == y.Name.Name {
+			d.value(path+"."+x.Name.Name, componentType(t, x.Name.Name), x.Value, y.Value)
+			return
+		}
+	case *ast.ContainingNode:
+		if y, ok := y.(*ast.ContainingNode); ok {
+			var ct *Type
+			if t != nil {
+				ct = t.Contains
+			}
+			d.value(path, ct, x.Value, y.Value)
+			return
+		}
+	default:
+		if nodesEqual(x, y) {
+			return
+		}
+	}
+	d.changes = append(d.changes, Change{
+		Kind: ChangeModified,
+		Path: path,
+		Old:  d.text(d.a, x),
+		New:  d.text(d.b, y),
+	})
+}
+
+// isComponents reports whether obj is a SEQUENCE or SET value, whose
+// elements are all components, or empty.
+func isComponents(obj *ast.ObjectNode) bool {
+	for _, el := range obj.Elements {
+		if _, ok := el.(*ast.FieldNode); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// components compares the components of the SEQUENCE or SET values x and
+// y at path, matching them by identifier. The nth component of x with an
+// identifier is matched with the nth of y with the same identifier.
+func (d *differ) components(path string, t *Type, x, y *ast.ObjectNode) {
+	ys := make(map[string][]*ast.FieldNode)
+	for _, el := range y.Elements {
+		f := el.(*ast.FieldNode)
+		ys[f.Name.Name] = append(ys[f.Name.Name], f)
+	}
+	matched := make(map[*ast.FieldNode]bool)
+	for _, el := range x.Elements {
+		f := el.(*ast.FieldNode)
+		name := f.Name.Name
+		if len(ys[name]) == 0 {
+			d.removed(path+"."+name, f.Value)
+			continue
+		}
+		g := ys[name][0]
+		ys[name] = ys[name][1:]
+		matched[g] = true
+		d.value(path+"."+name, componentType(t, name), f.Value, g.Value)
+	}
+	for _, el := range y.Elements {
+		if g := el.(*ast.FieldNode); !matched[g] {
+			d.added(path+"."+g.Name.Name, g.Value)
+		}
+	}
+}
+
+// componentType returns the type of the component or alternative name of
+// t, or nil if it is not known.
+func componentType(t *Type, name string) *Type {
+	if t == nil {
+		return nil
+	}
+	if c := t.Component(name); c != nil {
+		return c.Type
+	}
+	return nil
+}
+
+// elements compares the elements xs and ys of two SEQUENCE OF or SET OF
+// values at path, by position, except that those of a SET OF are first
+// matched with equal ones anywhere.
+func (d *differ) elements(path string, t *Type, xs, ys []ast.Node) {
+	var elem *Type
+	if t != nil {
+		elem = t.Elem
+	}
+	xi := make([]int, len(xs)) // indexes of the elements still to compare
+	for i := range xi {
+		xi[i] = i
+	}
+	yi := make([]int, len(ys))
+	for j := range yi {
+		yi[j] = j
+	}
+	if t != nil && t.Kind == KindSetOf {
+		xi, yi = unmatched(xs, ys)
+	}
+
+	n := len(xi)
+	if len(yi) < n {
+		n = len(yi)
+	}
+	for k := 0; k < n; k++ {
+		d.value(path+"["+strconv.Itoa(xi[k])+"]", elem, xs[xi[k]], ys[yi[k]])
+	}
+	for _, i := range xi[n:] {
+		d.removed(path+"["+strconv.Itoa(i)+"]", xs[i])
+	}
+	for _, j := range yi[n:] {
+		d.added(path+"["+strconv.Itoa(j)+"]", ys[j])
+	}
+}
+
+// unmatched pairs off the equal elements of xs and ys and returns the
+// indexes of those left in each.
+func unmatched(xs, ys []ast.Node) (xi, yi []int) {
+	used := make([]bool, len(ys))
+Elements:
+	for i, x := range xs {
+		for j, y := range ys {
+			if !used[j] && nodesEqual(x, y) {
+				used[j] = true
+				continue Elements
+			}
+		}
+		xi = append(xi, i)
+	}
+	for j := range ys {
+		if !used[j] {
+			yi = append(yi, j)
+		}
+	}
+	return xi, yi
+}
+
+// added records the value n of the new document at path as added.
+func (d *differ) added(path string, n ast.Node) {
+	d.changes = append(d.changes, Change{Kind: ChangeAdded, Path: path, New: d.text(d.b, n)})
+}
+
+// removed records the value n of the old document at path as removed.
+func (d *differ) removed(path string, n ast.Node) {
+	d.changes = append(d.changes, Change{Kind: ChangeRemoved, Path: path, Old: d.text(d.a, n)})
+}
+
+// text returns the node n of the document src in compact value notation.
+// A component among the elements of a list, which is no value by itself,
+// is its identifier followed by its value.
+func (d *differ) text(src []byte, n ast.Node) string {
+	if f, ok := n.(*ast.FieldNode); ok {
+		return f.Name.Name + " " + d.text(src, f.Value)
+	}
+	raw := src[n.Pos().Offset:n.End().Offset]
+	var buf bytes.Buffer
+	if err := Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
@@ -0,0 +1,98 @@
+package asn1go
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want []string // the changes, as Change.String writes them
+	}{
+		{"v T ::= { a 1, b 2 }", "v T ::= { b 2, a 1 }", nil},
+		{"v T ::= { a 'ab'H, s \"x\" } -- c", "v T ::= {\n  a 'AB'H, s \"x\" }", nil},
+		{"v T ::= 1.0", "v T ::= 1.00", nil},
+		{"v T ::= { a 1, b 2 }", "v T ::= { a 1, b 3, c 4 }", []string{"~ v.b 2 -> 3", "+ v.c 4"}},
+		{"v T ::= { a 1, b 2 }", "v T ::= { a 1 }", []string{"- v.b 2"}},
+		{"v T ::= { a 1, a 2 }", "v T ::= { a 1, a 3 }", []string{"~ v.a 2 -> 3"}},
+		{"v T ::= { 1, 2, 3 }", "v T ::= { 1, 5 }", []string{"~ v[1] 2 -> 5", "- v[2] 3"}},
+		{"v T ::= { 1, 2, 3 }", "v T ::= { 3, 1, 2 }", []string{"~ v[0] 1 -> 3", "~ v[1] 2 -> 1", "~ v[2] 3 -> 2"}},
+		{"v T ::= 1\nw T ::= 2", "w T ::= 2\nx T ::= 3", []string{"- v 1", "+ x 3"}},
+		{"v T ::= 1", "v U ::= 1", []string{"~ v T ::= 1 -> U ::= 1"}},
+		{"5 6", "5 7", []string{"~ [1] 6 -> 7"}},
+		{"5", "5 { a 1 }", []string{"+ [1] {a 1}"}},
+		{"v T ::= alt : { a 1 }", "v T ::= alt : { a 2 }", []string{"~ v.alt.a 1 -> 2"}},
+		{"v T ::= alt : 1", "v T ::= other : 1", []string{"~ v alt:1 -> other:1"}},
+		{"v T ::= { 1 }", "v T ::= { a 1 }", []string{"~ v[0] 1 -> a 1"}},
+	}
+	for _, tt := range tests {
+		cs, err := Diff([]byte(tt.a), []byte(tt.b))
+		if err != nil {
+			t.Errorf("Diff(%q, %q): %v", tt.a, tt.b, err)
+			continue
+		}
+		var got []string
+		for _, c := range cs {
+			got = append(got, c.String())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Diff(%q, %q):\nhave %q\nwant %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+	for _, in := range [][2]string{{"{", "1"}, {"1", "{ a"}} {
+		if _, err := Diff([]byte(in[0]), []byte(in[1])); err == nil {
+			t.Errorf("Diff(%q, %q): no error", in[0], in[1])
+		} else if _, ok := err.(*SyntaxError); !ok {
+			t.Errorf("Diff(%q, %q): error %v, want SyntaxError", in[0], in[1], err)
+		}
+	}
+}
+
+func TestDiffSchema(t *testing.T) {
+	m, err := ParseModule([]byte(`M DEFINITIONS ::= BEGIN
+S ::= SET OF INTEGER
+Q ::= SEQUENCE OF INTEGER
+R ::= SEQUENCE { s SET OF INTEGER }
+END`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		a, b string
+		want []Change
+	}{
+		{"v S ::= { 1, 2, 3 }", "v S ::= { 3, 1, 2 }", nil},
+		{"v S ::= { 1, 2, 3 }", "v S ::= { 3, 4, 1 }", []Change{{ChangeModified, "v[1]", "2", "4"}}},
+		{"v S ::= { 1, 2, 2 }", "v S ::= { 2, 1 }", []Change{{ChangeRemoved, "v[2]", "2", ""}}},
+		{"v Q ::= { 1, 2 }", "v Q ::= { 2, 1 }", []Change{{ChangeModified, "v[0]", "1", "2"}, {ChangeModified, "v[1]", "2", "1"}}},
+		{"v R ::= { s { 1, 2 } }", "v R ::= { s { 2, 1, 3 } }", []Change{{ChangeAdded, "v.s[2]", "", "3"}}},
+	}
+	for _, tt := range tests {
+		got, err := DiffSchema(m, []byte(tt.a), []byte(tt.b))
+		if err != nil {
+			t.Errorf("DiffSchema(%q, %q): %v", tt.a, tt.b, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("DiffSchema(%q, %q):\nhave %+v\nwant %+v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestChangeKindString(t *testing.T) {
+	tests := []struct {
+		k    ChangeKind
+		want string
+	}{
+		{ChangeAdded, "added"},
+		{ChangeRemoved, "removed"},
+		{ChangeModified, "modified"},
+		{ChangeKind(7), "ChangeKind(7)"},
+	}
+	for _, tt := range tests {
+		if got := tt.k.String(); got != tt.want {
+			t.Errorf("ChangeKind(%d).String() = %q, want %q", int(tt.k), got, tt.want)
+		}
+	}
+}
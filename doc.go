@@ -0,0 +1,23 @@
+// Package asn1go decodes ASN.1 value notation, as used by the eSIM profile
+// packages of the SIMalliance/TCA eUICC Profile Package specification.
+//
+// A profile package is written as a sequence of value assignments:
+//
+//	value1 ProfileElement ::= header : {
+//	  major-version 2,
+//	  minor-version 3,
+//	  profileType "GSMA Generic eUICC Test Profile",
+//	  iccid '89000123456789012341'H,
+//	  eUICC-Mandatory-services {
+//	    usim NULL,
+//	    milenage NULL
+//	  },
+//	  eUICC-Mandatory-GFSTEList {
+//	    { 2 23 143 1 2 1 }
+//	  }
+//	}
+//
+// The API mirrors encoding/json: Valid checks a document for well-formedness
+// and Unmarshal stores its values in Go values, guided by `asn1:"name"`
+// struct tags.
+package asn1go
@@ -0,0 +1,147 @@
+package asn1go
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DumpDER writes the BER or DER encoded values in src to w in a pseudo
+// value notation, without a schema, as dumpasn1 does: each encoding on a
+// line of its own, with its tag and a comment giving its offset in src
+// and its length. The encodings a constructed encoding holds are written
+// inside braces below it, as in
+//
+//	[APPLICATION 15] {  -- offset 0, length 8
+//	  INTEGER 5,  -- offset 2, length 1
+//	  [0] '6869'H  -- offset 5, length 2
+//	}
+//
+// The contents of a primitive encoding with a universal tag are written
+// as the value notation of the built-in type of the tag, such as INTEGER 5
+// or UTF8String "hi", and those of the other primitive encodings, or
+// contents that are not valid for their type, as hstrings.
+//
+// Malformed BER is reported as a DERSyntaxError.
+func DumpDER(w io.Writer, src []byte) error {
+	e := newEncodeState()
+	defer encodeStatePool.Put(e)
+
+	d := derDecoder{data: src}
+	for off := 0; off < len(src); {
+		x, err := d.parseTop(off)
+		if err != nil {
+			return err
+		}
+		if err := e.dumpTLV(&d, x, 0, false); err != nil {
+			return err
+		}
+		off += len(x.raw)
+		if _, err := w.Write(e.Bytes()); err != nil {
+			return err
+		}
+		e.Reset()
+	}
+	return nil
+}
+
+// dumpTLV writes the line of the encoding x, indented by depth, and those
+// of the encodings it holds, if it is constructed. comma reports whether
+// another encoding follows x in the encoding that holds it.
+func (e *encodeState) dumpTLV(d *derDecoder, x tlv, depth int, comma bool) error {
+	e.WriteString(strings.Repeat("  ", depth))
+	k, universal := universalKind(x.tag)
+	if universal {
+		e.WriteString(k.String())
+	} else {
+		e.WriteString(x.tag.String())
+	}
+	if !x.constructed {
+		if k != KindNull || len(x.content) > 0 {
+			e.WriteByte(' ')
+			e.dumpPrimitive(d, k, universal, x)
+		}
+		e.dumpComment(x, comma)
+		return nil
+	}
+
+	e.WriteString(" {")
+	e.dumpComment(x, false)
+	var elems []tlv
+	if err := derError(func() { elems = d.elements(x) }); err != nil {
+		return err
+	}
+	for i, el := range elems {
+		if err := e.dumpTLV(d, el, depth+1, i < len(elems)-1); err != nil {
+			return err
+		}
+	}
+	e.WriteString(strings.Repeat("  ", depth))
+	e.WriteByte('}')
+	if comma {
+		e.WriteByte(',')
+	}
+	e.WriteByte('\n')
+	return nil
+}
+
+// dumpPrimitive writes the contents of the primitive encoding x as a
+// value of the built-in type of kind k, if universal is set and they are
+// valid for it, and as an hstring otherwise.
+func (e *encodeState) dumpPrimitive(d *derDecoder, k Kind, universal bool, x tlv) {
+	if universal {
+		mark := e.Len()
+		if derError(func() { e.derValue(d, &Type{Kind: k}, x) }) == nil {
+			return
+		}
+		e.Truncate(mark)
+	}
+	e.writeHex(x.content)
+}
+
+// dumpComment ends the line of the encoding x with its offset and length,
+// after a comma if comma is set.
+func (e *encodeState) dumpComment(x tlv, comma bool) {
+	if comma {
+		e.WriteByte(',')
+	}
+	e.WriteString("  -- offset ")
+	e.WriteString(strconv.Itoa(x.off))
+	if _, _, l, _, _ := parseHeader(x.raw, false); l < 0 {
+		e.WriteString(", indefinite length\n")
+		return
+	}
+	e.WriteString(", length ")
+	e.WriteString(strconv.Itoa(len(x.content)))
+	e.WriteByte('\n')
+}
+
+// derError calls f and returns the error of the asn1Error it panics with,
+// if it does.
+func derError(f func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if je, ok := r.(asn1Error); ok {
+				err = je.error
+			} else {
+				panic(r)
+			}
+		}
+	}()
+	f()
+	return nil
+}
+
+// universalKind returns the kind of the built-in type whose tag is tag, and
+// false if tag is not the universal tag of such a type.
+func universalKind(tag Tag) (Kind, bool) {
+	if tag.Class != ClassUniversal {
+		return KindInvalid, false
+	}
+	for k, info := range kindInfo {
+		if info.tag == tag.Number {
+			return Kind(k), true
+		}
+	}
+	return KindInvalid, false
+}
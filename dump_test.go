@@ -0,0 +1,37 @@
+package asn1go
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDumpDER(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"", ""},
+		{"01 01 FF 02 01 05", "BOOLEAN TRUE  -- offset 0, length 1\nINTEGER 5  -- offset 3, length 1\n"},
+		{"04 01 AB", "OCTET STRING 'AB'H  -- offset 0, length 1\n"},
+		{"30 06 02 01 05 0C 01 61", "SEQUENCE {  -- offset 0, length 6\n  INTEGER 5,  -- offset 2, length 1\n  UTF8String \"a\"  -- offset 5, length 1\n}\n"},
+		{"6F 07 80 01 02 81 02 68 69", "[APPLICATION 15] {  -- offset 0, length 7\n  [0] '02'H,  -- offset 2, length 1\n  [1] '6869'H  -- offset 5, length 2\n}\n"},
+		{"02 00", "INTEGER ''H  -- offset 0, length 0\n"},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		if err := DumpDER(&b, fromHex(tt.data)); err != nil {
+			t.Errorf("DumpDER(%s): %v", tt.data, err)
+			continue
+		}
+		if b.String() != tt.want {
+			t.Errorf("DumpDER(%s):\nhave %q\nwant %q", tt.data, b.String(), tt.want)
+		}
+	}
+	for _, data := range []string{"61 03 80 01", "30 03 02 05 00"} {
+		var b bytes.Buffer
+		err := DumpDER(&b, fromHex(data))
+		if _, ok := err.(*DERSyntaxError); !ok {
+			t.Errorf("DumpDER(%s): error %v, want DERSyntaxError", data, err)
+		}
+	}
+}
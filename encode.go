@@ -0,0 +1,1324 @@
+package asn1go
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openesim/asn1go/ast"
+)
+
+// Marshal returns the ASN.1 value notation encoding of v.
+//
+// Marshal traverses the value v recursively and encodes it as follows,
+// producing input that Unmarshal accepts:
+//
+//   - Struct values encode as SEQUENCE values, { a 1, b 2 }, with one
+//     component per exported field in field order. The component identifier
+//     is the field's asn1 tag name or, without one, the field name starting
+//     with a lower case letter. Fields holding a nil pointer or interface
+//     are absent OPTIONAL components and are left out.
+//   - The "omitempty" tag option leaves out the field if it has an empty
+//     value, defined as false, 0, an empty array, slice, map, string or
+//     BitString. For example, `asn1:"fillFileContent,omitempty"` omits an
+//     empty fillFileContent rather than writing an empty hstring.
+//   - A struct with fields tagged with the "choice" option is a CHOICE,
+//     see below.
+//   - The "choice:<alt>" tag option writes the field's value as the
+//     CHOICE alternative alt, as in fileContent alt : value.
+//   - ChoiceValue values encode as Alternative : Value, and OpenTypeValue
+//     values as Type : Value.
+//   - Fields tagged "..." and map entries with the key "..." hold the
+//     Extensions of a value, which the value notation cannot carry, and
+//     are left out. An unknown CHOICE alternative "..." cannot be encoded.
+//   - Map values encode as SEQUENCE values too. The map's key type must be
+//     a string kind and the keys are used as component identifiers, in
+//     the order given by the map's ComponentOrder method if it implements
+//     ComponentOrderer and sorted otherwise, so that the output is the same
+//     on every run.
+//   - OrderedObject values encode as SEQUENCE values with the components
+//     in their order, repeated identifiers included.
+//   - Slice and array values encode as SEQUENCE OF values, { 1, 2 }, except
+//     that []byte and byte arrays encode as hstrings, '0A1B'H.
+//   - ObjectIdentifier values encode as OBJECT IDENTIFIER values,
+//     { 2 23 143 1 2 1 }, and RelativeOID values as RELATIVE-OID values,
+//     { 4 3 2 }.
+//   - BitString values encode as hstrings when they hold whole octets and
+//     as bstrings, '0110'B, otherwise.
+//   - Boolean values encode as TRUE or FALSE.
+//   - Integer values encode as INTEGER numbers, float values as REAL
+//     numbers or PLUS-INFINITY, MINUS-INFINITY and NOT-A-NUMBER.
+//   - big.Int values encode as INTEGER numbers of any size, and Number
+//     values as their literal text.
+//   - Integer and big.Int fields with the "intfromhex" tag option encode
+//     as hstrings of their unsigned big-endian octets, '0100'H for 256,
+//     as Unmarshal reads them. Negative values cannot be encoded.
+//   - String values encode as cstrings, "text", with each quotation mark
+//     doubled. Strings containing control characters other than tabs
+//     cannot be encoded.
+//   - time.Time values encode as cstrings holding a GeneralizedTime,
+//     "20230115120000.5+0100", with any fraction of a second and the
+//     offset from UTC, or Z for UTC. Offsets that are not whole minutes
+//     cannot be encoded. Fields with the "utc" tag option encode as a
+//     UTCTime, "230115120000Z", which has no fraction.
+//   - Date, TimeOfDay, DateTime and Duration values encode as cstrings
+//     holding a DATE, TIME-OF-DAY, DATE-TIME or DURATION value, such as
+//     "2023-01-15", "12:30:00", "2023-01-15T12:30:00" and "P1Y2M3D".
+//   - Fields with the "named:<id>=<n>|..." tag option, such as
+//     `asn1:"version,named:v1=0|v2=1"`, encode integers that have a name
+//     as the identifier, v2, and BitStrings whose bits set all have names
+//     as a named bit list, { bitA, bitC }.
+//   - Empty structs, nil pointers and nil interface values outside of
+//     struct fields encode as NULL.
+//
+// The fields of a CHOICE struct tagged "choice" are its alternatives, and
+// the struct encodes as alt : value for the single one that is not zero.
+// Having none or several set is an error; note that an empty but non-nil
+// slice is not zero. For example,
+//
+//	type ProfileElement struct {
+//		Header *PEHeader `asn1:"header,choice"`
+//		MF     *PEMF     `asn1:"mf,choice"`
+//	}
+//
+// encodes as header : { ... } when Header is set.
+//
+// A ValueAssignment encodes as name Type ::= value. A top-level slice or
+// array of ValueAssignment encodes as one assignment per line, which is
+// the format of SAIP profile packages. Assignments cannot be nested inside
+// other values.
+//
+// The nodes of the syntax trees that ParseValue and ParseAssignments
+// return encode as the values they stand for, so that a rewritten tree can
+// be written back, together with the comments attached to its top-level
+// values and to the elements of its brace-delimited values. Without
+// indentation, comments that run to the end of their line are closed
+// with -- to keep the output on one line. An *ast.Assignment, or a
+// top-level slice of them, encodes like a ValueAssignment.
+//
+// If a value implements the Marshaler interface and is not a nil pointer,
+// Marshal calls its MarshalASN1 method to produce the value notation.
+// Values whose pointer implements Marshaler are handled likewise when they
+// are addressable.
+//
+// Channel, complex, and function values cannot be encoded. Attempting to
+// encode such a value causes Marshal to return an UnsupportedTypeError.
+//
+// ASN.1 value notation cannot represent cyclic data structures and Marshal
+// does not handle them. Passing cyclic structures to Marshal will result in
+// an error.
+func Marshal(v interface{}) ([]byte, error) {
+	e := newEncodeState()
+	defer encodeStatePool.Put(e)
+
+	err := e.marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	buf := append([]byte(nil), e.Bytes()...)
+
+	return buf, nil
+}
+
+// MarshalIndent is like Marshal but applies indentation to format the
+// output, in the style of SAIP profile samples. Each component and element
+// begins on a new line starting with prefix followed by one or more copies
+// of indent according to the nesting depth, and closing braces go on lines
+// of their own. Object identifiers and empty values stay on one line.
+//
+//	{
+//	  major-version 2,
+//	  eUICC-Mandatory-services {
+//	    usim NULL
+//	  }
+//	}
+func MarshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
+	e := newEncodeState()
+	defer encodeStatePool.Put(e)
+
+	e.prefix, e.indent = prefix, indent
+	err := e.marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	buf := append([]byte(nil), e.Bytes()...)
+
+	return buf, nil
+}
+
+// sampleHexLine is the number of octets per line of the long hstrings of
+// the sample profiles.
+const sampleHexLine = 32
+
+// MarshalSample is like MarshalIndent(v, "", "  ") but follows the layout
+// of the TCA (formerly SIMalliance) sample profiles to the letter, so that
+// a sample regenerated from its values differs from the original only
+// where the values do. An hstring of more than 32 octets is broken into
+// lines of 64 digits, each continuation line indented one level deeper
+// than the line the hstring starts on:
+//
+//	fillFileContent : '00112233445566778899AABBCCDDEEFF00112233445566778899AABBCCDDEEFF
+//	  FFFFFFFF'H
+//
+// The white space between the digits is insignificant; Unmarshal and the
+// other decoders of this package skip it.
+func MarshalSample(v interface{}) ([]byte, error) {
+	e := newEncodeState()
+	defer encodeStatePool.Put(e)
+
+	e.indent, e.hexLine = "  ", sampleHexLine
+	err := e.marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), e.Bytes()...), nil
+}
+
+// MarshalAssignment returns the value notation encoding of the value
+// assignment name typeName ::= v.
+func MarshalAssignment(name, typeName string, v interface{}) ([]byte, error) {
+	return Marshal(ValueAssignment{Name: name, Type: typeName, Value: v})
+}
+
+// ComponentOrderer is the interface implemented by map types whose keys
+// are the components of a SEQUENCE type, to have Marshal write them in the
+// order of the type definition rather than sorted. ComponentOrder returns
+// the component identifiers in definition order; map keys not in the list
+// follow the listed ones, sorted.
+type ComponentOrderer interface {
+	ComponentOrder() []string
+}
+
+// Marshaler is the interface implemented by types that can marshal
+// themselves into a valid ASN.1 value notation value.
+type Marshaler interface {
+	MarshalASN1() ([]byte, error)
+}
+
+// An UnsupportedTypeError is returned by Marshal when attempting
+// to encode an unsupported value type.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return "asn1go: unsupported type: " + e.Type.String()
+}
+
+// An UnsupportedValueError is returned by Marshal when attempting
+// to encode an unsupported value.
+type UnsupportedValueError struct {
+	Value reflect.Value
+	Str   string
+}
+
+func (e *UnsupportedValueError) Error() string {
+	return "asn1go: unsupported value: " + e.Str
+}
+
+// A MarshalerError represents an error from calling a MarshalASN1 method.
+type MarshalerError struct {
+	Type reflect.Type
+	Err  error
+}
+
+func (e *MarshalerError) Error() string {
+	return "asn1go: error calling MarshalASN1 for type " + e.Type.String() + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *MarshalerError) Unwrap() error { return e.Err }
+
+// An encodeState encodes value notation into a bytes.Buffer.
+type encodeState struct {
+	bytes.Buffer // accumulated output
+	scratch      [64]byte
+
+	// Keep track of what pointers we've seen in the current recursive call
+	// path, to avoid cycles that could lead to a stack overflow. Only do
+	// the relatively expensive map operations if ptrLevel is larger than
+	// startDetectingCyclesAfter, so that we skip the work if we're within a
+	// reasonable amount of nested pointers deep.
+	ptrLevel uint
+	ptrSeen  map[interface{}]struct{}
+
+	// prefix and indent are the MarshalIndent settings; with both empty
+	// values are written on a single line.
+	prefix      string
+	indent      string
+	indentLevel int
+
+	// hexLine, if positive, is the number of octets per line of a longer
+	// hstring, as MarshalSample writes it.
+	hexLine int
+
+	// utcTime reports that the field being encoded has the "utc" tag
+	// option, which writes a time.Time as a UTCTime.
+	utcTime bool
+
+	// intFromHex reports that the field being encoded has the
+	// "intfromhex" tag option, which writes an integer as an hstring.
+	intFromHex bool
+
+	// named holds the named numbers or bits of the "named" tag option of
+	// the field being encoded, which integers and BitStrings are written
+	// with.
+	named []NamedNumber
+}
+
+const startDetectingCyclesAfter = 1000
+
+var encodeStatePool sync.Pool
+
+func newEncodeState() *encodeState {
+	if v := encodeStatePool.Get(); v != nil {
+		e := v.(*encodeState)
+		e.Reset()
+		if len(e.ptrSeen) > 0 {
+			panic("encodeState should have emptied ptrSeen via defers")
+		}
+		e.ptrLevel = 0
+		e.prefix, e.indent, e.indentLevel = "", "", 0
+		e.hexLine = 0
+		e.utcTime = false
+		e.intFromHex = false
+		e.named = nil
+		return e
+	}
+	return &encodeState{ptrSeen: make(map[interface{}]struct{})}
+}
+
+// asn1Error is an error wrapper type for internal use only.
+// Panics with errors are wrapped in asn1Error so that the top-level recover
+// can distinguish intentional panics from this package.
+type asn1Error struct{ error }
+
+func (e *encodeState) marshal(v interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if je, ok := r.(asn1Error); ok {
+				err = je.error
+			} else {
+				panic(r)
+			}
+		}
+	}()
+	e.topValue(reflect.ValueOf(v))
+	return nil
+}
+
+var (
+	valueAssignmentType = reflect.TypeOf(ValueAssignment{})
+	choiceValueType     = reflect.TypeOf(ChoiceValue{})
+	astAssignmentType   = reflect.TypeOf((*ast.Assignment)(nil))
+)
+
+// topValue encodes v, which may be a value assignment or a list of them.
+func (e *encodeState) topValue(v reflect.Value) {
+	for v.Kind() == reflect.Pointer && !v.IsNil() && v.Type().Elem() == valueAssignmentType {
+		v = v.Elem()
+	}
+	switch {
+	case v.IsValid() && v.Type() == valueAssignmentType:
+		e.assignment(v.Interface().(ValueAssignment))
+	case v.IsValid() && v.Type() == astAssignmentType && !v.IsNil():
+		e.astAssignment(v.Interface().(*ast.Assignment))
+	case (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem() == valueAssignmentType:
+		for i, n := 0, v.Len(); i < n; i++ {
+			if i > 0 {
+				e.WriteByte('\n')
+				e.WriteString(e.prefix)
+			}
+			e.assignment(v.Index(i).Interface().(ValueAssignment))
+		}
+	case (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem() == astAssignmentType:
+		for i, n := 0, v.Len(); i < n; i++ {
+			if i > 0 {
+				e.WriteByte('\n')
+				e.WriteString(e.prefix)
+			}
+			e.astAssignment(v.Index(i).Interface().(*ast.Assignment))
+		}
+	case v.IsValid() && v.Type().Implements(astNodeType) && !(v.Kind() == reflect.Pointer && v.IsNil()):
+		n := v.Interface().(ast.Node)
+		e.docComments(n.Attached().Doc)
+		e.node(n)
+		e.trailingComments(n, n.Attached().Trailing)
+	default:
+		e.reflectValue(v)
+	}
+}
+
+func (e *encodeState) assignment(a ValueAssignment) {
+	if !isValidIdentifier(a.Name) {
+		e.error(&UnsupportedValueError{reflect.ValueOf(a), "value reference " + strconv.Quote(a.Name) + " is not an identifier"})
+	}
+	if !isValidTypeReference(a.Type) {
+		e.error(&UnsupportedValueError{reflect.ValueOf(a), "type reference " + strconv.Quote(a.Type) + " is not a type reference"})
+	}
+	e.WriteString(a.Name)
+	e.WriteByte(' ')
+	e.WriteString(a.Type)
+	e.WriteString(" ::= ")
+	e.reflectValue(reflect.ValueOf(a.Value))
+}
+
+// astAssignment encodes the top-level value a of a syntax tree with its
+// comments.
+func (e *encodeState) astAssignment(a *ast.Assignment) {
+	e.docComments(a.Doc)
+	switch {
+	case a.Name == nil && a.Type == nil:
+		e.docComments(a.Value.Attached().Doc)
+		e.node(a.Value)
+		e.trailingComments(a.Value, a.Value.Attached().Trailing)
+	case a.Name == nil || a.Type == nil:
+		e.error(&UnsupportedValueError{reflect.ValueOf(a), "value assignment without a value reference or type"})
+	default:
+		e.assignment(ValueAssignment{Name: a.Name.Name, Type: a.Type.Name, Value: a.Value})
+	}
+	e.trailingComments(a, a.Trailing)
+}
+
+// docComments writes the comments cs, which come before a node on lines of
+// their own.
+func (e *encodeState) docComments(cs []*ast.Comment) {
+	for _, c := range cs {
+		e.comment(c)
+		if e.indent == "" && e.prefix == "" {
+			e.WriteByte(' ')
+		} else {
+			e.newline(e.indentLevel)
+		}
+	}
+}
+
+// trailingComments writes the comments cs, which follow the node n on its
+// line or, when parsed from a later line, on lines of their own.
+func (e *encodeState) trailingComments(n ast.Node, cs []*ast.Comment) {
+	line := n.End().Line
+	for _, c := range cs {
+		if c.Pos().Line > line && (e.indent != "" || e.prefix != "") {
+			e.newline(e.indentLevel)
+		} else {
+			e.WriteByte(' ')
+		}
+		line = c.End().Line
+		e.comment(c)
+	}
+}
+
+// comment writes the comment c. Without indentation a comment that runs
+// up to the end of its line is closed with -- instead.
+func (e *encodeState) comment(c *ast.Comment) {
+	text := c.Text
+	body := strings.TrimPrefix(text, "--")
+	closed := len(body) >= 2 && strings.HasSuffix(body, "--")
+	if closed {
+		body = body[:len(body)-2]
+	}
+	if !strings.HasPrefix(text, "--") || strings.Contains(body, "--") || strings.ContainsAny(body, "\n\r") {
+		e.error(&UnsupportedValueError{reflect.ValueOf(c), "comment " + strconv.Quote(text) + " is not a value notation comment"})
+	}
+	e.WriteString(text)
+	if !closed && e.indent == "" && e.prefix == "" {
+		e.WriteString(" --")
+	}
+}
+
+// node encodes the syntax tree node n.
+func (e *encodeState) node(n ast.Node) {
+	switch n := n.(type) {
+	case nil:
+		e.error(&UnsupportedValueError{reflect.ValueOf(n), "nil syntax tree node"})
+	case *ast.Assignment:
+		e.error(&UnsupportedValueError{reflect.ValueOf(n), "value assignment inside a value"})
+	case *ast.ObjectNode:
+		// The trailing comments of an element follow its comma.
+		e.beginBrace()
+		for i, el := range n.Elements {
+			if i > 0 {
+				e.WriteByte(',')
+				prev := n.Elements[i-1]
+				e.trailingComments(prev, prev.Attached().Trailing)
+			}
+			e.elementSeparator(0)
+			e.docComments(el.Attached().Doc)
+			e.node(el)
+		}
+		if k := len(n.Elements); k > 0 {
+			last := n.Elements[k-1]
+			e.trailingComments(last, last.Attached().Trailing)
+		}
+		e.endBrace(len(n.Elements))
+	case *ast.FieldNode:
+		if n.Name == nil || !isValidReference(n.Name.Name) {
+			e.error(&UnsupportedValueError{reflect.ValueOf(n), "component without an identifier"})
+		}
+		e.WriteString(n.Name.Name)
+		e.WriteByte(' ')
+		e.node(n.Value)
+	case *ast.ChoiceNode:
+		if n.Name == nil {
+			e.error(&UnsupportedValueError{reflect.ValueOf(n), "CHOICE value without an alternative"})
+		}
+		e.choice(n.Name.Name, reflect.ValueOf(n.Value))
+	case *ast.ContainingNode:
+		e.containing(reflect.ValueOf(n.Value))
+	case *ast.OIDNode:
+		e.WriteByte('{')
+		for _, c := range n.Components {
+			e.WriteByte(' ')
+			e.node(c)
+		}
+		e.WriteString(" }")
+	case *ast.Ident:
+		if !isValidReference(n.Name) {
+			e.error(&UnsupportedValueError{reflect.ValueOf(n), strconv.Quote(n.Name) + " is not an identifier"})
+		}
+		e.WriteString(n.Name)
+	case *ast.NullNode:
+		e.WriteString("NULL")
+	case *ast.BoolNode:
+		if n.Value {
+			e.WriteString("TRUE")
+		} else {
+			e.WriteString("FALSE")
+		}
+	case *ast.IntNode:
+		if !isIntegerLiteral(n.Text) {
+			e.error(&UnsupportedValueError{reflect.ValueOf(n), strconv.Quote(n.Text) + " is not an integer"})
+		}
+		e.WriteString(n.Text)
+	case *ast.RealNode:
+		if !isRealLiteral(n.Text) {
+			e.error(&UnsupportedValueError{reflect.ValueOf(n), strconv.Quote(n.Text) + " is not a real number"})
+		}
+		e.WriteString(n.Text)
+	case *ast.HexNode:
+		if len(n.Digits)%2 == 1 && strings.Trim(n.Digits, "0123456789ABCDEFabcdef") == "" {
+			// An hstring of a BIT STRING value, which has no octets.
+			e.WriteByte('\'')
+			e.WriteString(n.Digits)
+			e.WriteString("'H")
+			return
+		}
+		b, err := n.Bytes()
+		if err != nil {
+			e.error(&UnsupportedValueError{reflect.ValueOf(n), "hstring '" + n.Digits + "'H: " + err.Error()})
+		}
+		e.writeHex(b)
+	case *ast.BitsNode:
+		if strings.Trim(n.Digits, "01") != "" {
+			e.error(&UnsupportedValueError{reflect.ValueOf(n), "bstring '" + n.Digits + "'B has digits other than 0 and 1"})
+		}
+		e.WriteByte('\'')
+		e.WriteString(n.Digits)
+		e.WriteString("'B")
+	case *ast.StringNode:
+		e.cstring(reflect.ValueOf(n.Value))
+	default:
+		e.error(&UnsupportedTypeError{reflect.TypeOf(n)})
+	}
+}
+
+// isIntegerLiteral reports whether s is an INTEGER number in value
+// notation.
+func isIntegerLiteral(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	return s != "" && strings.TrimLeft(s, "0123456789") == ""
+}
+
+// isRealLiteral reports whether s is a REAL number in value notation, such
+// as 1.5, -2.0E-3 or 1E10.
+func isRealLiteral(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	mant, exp, hasExp := strings.Cut(strings.ToUpper(s), "E")
+	whole, frac, _ := strings.Cut(mant, ".")
+	if whole == "" || strings.TrimLeft(whole, "0123456789") != "" || strings.TrimLeft(frac, "0123456789") != "" {
+		return false
+	}
+	if hasExp {
+		return isIntegerLiteral(exp)
+	}
+	return true
+}
+
+// error aborts the encoding by panicking with err wrapped in asn1Error.
+func (e *encodeState) error(err error) {
+	panic(asn1Error{err})
+}
+
+var (
+	objectIdentifierType = reflect.TypeOf(ObjectIdentifier(nil))
+	relativeOIDType      = reflect.TypeOf(RelativeOID(nil))
+	oidIRIType           = reflect.TypeOf(OIDIRI(""))
+	bigIntType           = reflect.TypeOf(big.Int{})
+	marshalerType        = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	astNodeType          = reflect.TypeOf((*ast.Node)(nil)).Elem()
+	componentOrdererType = reflect.TypeOf((*ComponentOrderer)(nil)).Elem()
+)
+
+func (e *encodeState) reflectValue(v reflect.Value) {
+	if !v.IsValid() {
+		e.WriteString("NULL")
+		return
+	}
+	t := v.Type()
+	if t.Implements(astNodeType) {
+		if t.Kind() == reflect.Pointer && v.IsNil() {
+			e.WriteString("NULL")
+			return
+		}
+		e.node(v.Interface().(ast.Node))
+		return
+	}
+	if t.Implements(marshalerType) {
+		if t.Kind() == reflect.Pointer && v.IsNil() {
+			e.WriteString("NULL")
+			return
+		}
+		e.marshaler(v)
+		return
+	}
+	if v.CanAddr() && reflect.PointerTo(t).Implements(marshalerType) {
+		e.marshaler(v.Addr())
+		return
+	}
+	switch t {
+	case valueAssignmentType:
+		e.error(&UnsupportedValueError{v, "value assignment inside a value"})
+	case bitStringType:
+		e.bitString(v.Interface().(BitString))
+		return
+	case choiceValueType:
+		cv := v.Interface().(ChoiceValue)
+		e.choice(cv.Alternative, reflect.ValueOf(cv.Value))
+		return
+	case openTypeValueType:
+		ov := v.Interface().(OpenTypeValue)
+		if !isValidOpenType(ov.Type) {
+			e.error(&UnsupportedValueError{v, "open type " + strconv.Quote(ov.Type) + " is not a type reference"})
+		}
+		e.WriteString(ov.Type)
+		e.WriteString(" : ")
+		e.reflectValue(reflect.ValueOf(ov.Value))
+		return
+	case orderedObjectType:
+		e.orderedObject(v)
+		return
+	case valueType:
+		e.dynamicValue(v.Interface().(Value))
+		return
+	case containingType:
+		e.containing(reflect.ValueOf(v.Interface().(Containing).Value))
+		return
+	case objectIdentifierType, relativeOIDType:
+		e.objectIdentifier(v)
+		return
+	case timeType:
+		e.time(v)
+		return
+	case dateType, timeOfDayType, dateTimeType, durationType:
+		s, err := formatDate(v.Interface())
+		if err != nil {
+			e.error(&UnsupportedValueError{v, err.Error()})
+		}
+		e.WriteByte('"')
+		e.WriteString(s)
+		e.WriteByte('"')
+		return
+	case bigIntType:
+		if e.intFromHex {
+			e.hexInteger(v, bigIntOf(v))
+			return
+		}
+		e.WriteString(bigIntOf(v).String())
+		return
+	case numberType:
+		s := v.String()
+		if !isValidNumber(s) {
+			e.error(&UnsupportedValueError{v, "invalid number literal " + strconv.Quote(s)})
+		}
+		e.WriteString(s)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.WriteString("TRUE")
+		} else {
+			e.WriteString("FALSE")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if e.intFromHex {
+			e.hexInteger(v, big.NewInt(v.Int()))
+			return
+		}
+		if name, ok := numberName(e.named, v.Int()); ok {
+			e.WriteString(name)
+			return
+		}
+		e.Write(strconv.AppendInt(e.scratch[:0], v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if e.intFromHex {
+			e.hexInteger(v, new(big.Int).SetUint64(v.Uint()))
+			return
+		}
+		if n := v.Uint(); n <= math.MaxInt64 {
+			if name, ok := numberName(e.named, int64(n)); ok {
+				e.WriteString(name)
+				return
+			}
+		}
+		e.Write(strconv.AppendUint(e.scratch[:0], v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		e.real(v.Float(), v.Type().Bits())
+	case reflect.String:
+		e.cstring(v)
+	case reflect.Interface:
+		if v.IsNil() {
+			e.WriteString("NULL")
+			return
+		}
+		e.reflectValue(v.Elem())
+	case reflect.Pointer:
+		e.pointer(v)
+	case reflect.Struct:
+		e.structValue(v)
+	case reflect.Map:
+		e.mapValue(v)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.hstring(v)
+			return
+		}
+		e.array(v)
+	default:
+		e.error(&UnsupportedTypeError{v.Type()})
+	}
+}
+
+// isValidNumber reports whether s is a number literal that the scanner
+// accepts: an integer, 1 or -12, or a real number, 3.14 or 314e-2.
+func isValidNumber(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	i := digits(s)
+	if i == 0 {
+		return false
+	}
+	s = s[i:]
+	if strings.HasPrefix(s, ".") {
+		i = digits(s[1:])
+		if i == 0 {
+			return false
+		}
+		s = s[1+i:]
+	}
+	if s != "" && (s[0] == 'e' || s[0] == 'E') {
+		s = s[1:]
+		if s != "" && (s[0] == '+' || s[0] == '-') {
+			s = s[1:]
+		}
+		i = digits(s)
+		if i == 0 {
+			return false
+		}
+		s = s[i:]
+	}
+	return s == ""
+}
+
+// digits returns the number of leading decimal digits of s.
+func digits(s string) int {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return i
+}
+
+// marshaler writes the output of v's MarshalASN1 method after checking
+// that it is a single valid value, not a value assignment.
+func (e *encodeState) marshaler(v reflect.Value) {
+	m, ok := v.Interface().(Marshaler)
+	if !ok {
+		e.WriteString("NULL")
+		return
+	}
+	b, err := m.MarshalASN1()
+	if err == nil {
+		scan := newScanner()
+		var n int
+		n, err = checkValid(b, scan)
+		switch {
+		case err != nil:
+		case n != 1:
+			err = errors.New("output is not a single value")
+		case isValueAssignment(b, scan):
+			err = errors.New("output is a value assignment, not a value")
+		}
+		freeScanner(scan)
+	}
+	if err != nil {
+		e.error(&MarshalerError{v.Type(), err})
+	}
+	e.Write(b)
+	if bytes.Contains(b, []byte("--")) && b[len(b)-1] != '\n' {
+		// The output may end in a comment, which would swallow
+		// whatever follows on the same line.
+		e.WriteByte('\n')
+	}
+}
+
+// isValueAssignment reports whether the valid data, a single top-level
+// value, is a value assignment such as v T ::= 5.
+func isValueAssignment(data []byte, scan *scanner) bool {
+	scan.reset()
+	for _, c := range data {
+		switch scan.step(scan, c) {
+		case scanBeginTypeReference, scanAssignment:
+			return true
+		case scanBeginObject, scanBeginLiteral, scanChoiceTag:
+			// The value itself has begun.
+			return false
+		}
+	}
+	return false
+}
+
+func (e *encodeState) pointer(v reflect.Value) {
+	if v.IsNil() {
+		e.WriteString("NULL")
+		return
+	}
+	if e.ptrLevel++; e.ptrLevel > startDetectingCyclesAfter {
+		// We're a large number of nested pointers deep;
+		// start checking if we've run into a pointer cycle.
+		ptr := v.Interface()
+		if _, ok := e.ptrSeen[ptr]; ok {
+			e.error(&UnsupportedValueError{v, fmt.Sprintf("encountered a cycle via %s", v.Type())})
+		}
+		e.ptrSeen[ptr] = struct{}{}
+		defer delete(e.ptrSeen, ptr)
+	}
+	e.reflectValue(v.Elem())
+	e.ptrLevel--
+}
+
+func (e *encodeState) structValue(v reflect.Value) {
+	if v.NumField() == 0 {
+		e.WriteString("NULL")
+		return
+	}
+	fields := cachedTypeFields(v.Type())
+	if fields.choice {
+		e.choiceStruct(v, &fields)
+		return
+	}
+	e.beginBrace()
+	n := 0
+	for i := range fields.list {
+		f := &fields.list[i]
+		if f.name == "..." {
+			continue
+		}
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok || (fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface) && fv.IsNil() {
+			// An absent OPTIONAL component.
+			continue
+		}
+		if f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+
+		e.elementSeparator(n)
+		n++
+		e.WriteString(f.name)
+		e.WriteByte(' ')
+		utcTime, intFromHex, named := e.utcTime, e.intFromHex, e.named
+		e.utcTime, e.intFromHex, e.named = f.options.Contains("utc"), f.options.Contains("intfromhex"), f.named
+		if f.choice != "" {
+			e.choice(f.choice, fv)
+		} else {
+			e.reflectValue(fv)
+		}
+		e.utcTime, e.intFromHex, e.named = utcTime, intFromHex, named
+	}
+	e.endBrace(n)
+}
+
+// choiceStruct encodes the struct v, whose fields include CHOICE
+// alternatives, as its single chosen alternative.
+func (e *encodeState) choiceStruct(v reflect.Value, fields *structFields) {
+	var chosen *field
+	var cv reflect.Value
+	for i := range fields.list {
+		f := &fields.list[i]
+		if !f.alternative {
+			continue
+		}
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok || fv.IsZero() {
+			continue
+		}
+		if chosen != nil {
+			e.error(&UnsupportedValueError{v, "CHOICE " + v.Type().String() + " has both " + chosen.name + " and " + f.name + " set"})
+		}
+		chosen, cv = f, fv
+	}
+	if chosen == nil {
+		e.error(&UnsupportedValueError{v, "CHOICE " + v.Type().String() + " has no alternative set"})
+	}
+	utcTime, intFromHex, named := e.utcTime, e.intFromHex, e.named
+	e.utcTime, e.intFromHex, e.named = chosen.options.Contains("utc"), chosen.options.Contains("intfromhex"), chosen.named
+	e.choice(chosen.name, cv)
+	e.utcTime, e.intFromHex, e.named = utcTime, intFromHex, named
+}
+
+// choice encodes v as the CHOICE alternative alt.
+func (e *encodeState) choice(alt string, v reflect.Value) {
+	if alt == "..." {
+		e.error(&UnsupportedValueError{v, "unknown extension addition has no value notation"})
+	}
+	if alt == "*" {
+		for v.Kind() == reflect.Pointer {
+			v = v.Elem()
+		}
+		rc := v.Interface().(RawChoice)
+		alt, v = rc.Alternative, reflect.ValueOf(rc.Value)
+	}
+	if !isValidReference(alt) {
+		e.error(&UnsupportedValueError{v, "CHOICE alternative " + strconv.Quote(alt) + " is not an identifier"})
+	}
+	e.WriteString(alt)
+	e.WriteString(" : ")
+	e.reflectValue(v)
+}
+
+// containing writes the containing value of the value contained v.
+func (e *encodeState) containing(v reflect.Value) {
+	e.WriteString(containingKeyword)
+	e.WriteByte(' ')
+	e.reflectValue(v)
+}
+
+// fieldByIndex returns the nested field of the struct v at index. It
+// reports false if the field is reached through a nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for _, i := range index {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v, true
+}
+
+func (e *encodeState) mapValue(v reflect.Value) {
+	if v.Type().Key().Kind() != reflect.String {
+		e.error(&UnsupportedTypeError{v.Type()})
+	}
+	if e.ptrLevel++; e.ptrLevel > startDetectingCyclesAfter {
+		// We're a large number of nested pointers deep;
+		// start checking if we've run into a pointer cycle.
+		ptr := v.UnsafePointer()
+		if _, ok := e.ptrSeen[ptr]; ok {
+			e.error(&UnsupportedValueError{v, fmt.Sprintf("encountered a cycle via %s", v.Type())})
+		}
+		e.ptrSeen[ptr] = struct{}{}
+		defer delete(e.ptrSeen, ptr)
+	}
+
+	keys := make([]string, 0, v.Len())
+	for _, k := range v.MapKeys() {
+		if k.String() != "..." {
+			keys = append(keys, k.String())
+		}
+	}
+	sort.Strings(keys)
+	if v.Type().Implements(componentOrdererType) && !v.IsNil() {
+		orderKeys(keys, v.Interface().(ComponentOrderer).ComponentOrder())
+	}
+
+	e.beginBrace()
+	for i, k := range keys {
+		if !isValidReference(k) {
+			e.error(&UnsupportedValueError{v, "map key " + strconv.Quote(k) + " is not an identifier"})
+		}
+		e.elementSeparator(i)
+		e.WriteString(k)
+		e.WriteByte(' ')
+		e.reflectValue(v.MapIndex(reflect.ValueOf(k).Convert(v.Type().Key())))
+	}
+	e.endBrace(len(keys))
+	e.ptrLevel--
+}
+
+// orderedObject writes the OrderedObject v with its components in order.
+func (e *encodeState) orderedObject(v reflect.Value) {
+	if e.ptrLevel++; e.ptrLevel > startDetectingCyclesAfter && v.Len() > 0 {
+		// We're a large number of nested pointers deep;
+		// start checking if we've run into a cycle, as array does.
+		ptr := struct {
+			ptr interface{}
+			len int
+		}{v.UnsafePointer(), v.Len()}
+		if _, ok := e.ptrSeen[ptr]; ok {
+			e.error(&UnsupportedValueError{v, fmt.Sprintf("encountered a cycle via %s", v.Type())})
+		}
+		e.ptrSeen[ptr] = struct{}{}
+		defer delete(e.ptrSeen, ptr)
+	}
+
+	e.beginBrace()
+	n := 0
+	for _, f := range v.Interface().(OrderedObject) {
+		if f.Name == "..." {
+			continue
+		}
+		if !isValidReference(f.Name) {
+			e.error(&UnsupportedValueError{v, "component name " + strconv.Quote(f.Name) + " is not an identifier"})
+		}
+		e.elementSeparator(n)
+		e.WriteString(f.Name)
+		e.WriteByte(' ')
+		e.reflectValue(reflect.ValueOf(f.Value))
+		n++
+	}
+	e.endBrace(n)
+	e.ptrLevel--
+}
+
+// orderKeys moves the sorted keys that appear in order to the front, in the
+// order they appear there.
+func orderKeys(keys []string, order []string) {
+	rank := make(map[string]int, len(order))
+	for i, k := range order {
+		if _, ok := rank[k]; !ok {
+			rank[k] = i
+		}
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		ri, iok := rank[keys[i]]
+		rj, jok := rank[keys[j]]
+		if iok && jok {
+			return ri < rj
+		}
+		return iok && !jok
+	})
+}
+
+func (e *encodeState) array(v reflect.Value) {
+	if v.Kind() == reflect.Slice {
+		if e.ptrLevel++; e.ptrLevel > startDetectingCyclesAfter {
+			// We're a large number of nested pointers deep;
+			// start checking if we've run into a pointer cycle.
+			// Here we use a struct to memorize the pointer to the first element of the slice
+			// and its length.
+			ptr := struct {
+				ptr interface{} // always an unsafe.Pointer, but avoids a dependency on package unsafe
+				len int
+			}{v.UnsafePointer(), v.Len()}
+			if _, ok := e.ptrSeen[ptr]; ok {
+				e.error(&UnsupportedValueError{v, fmt.Sprintf("encountered a cycle via %s", v.Type())})
+			}
+			e.ptrSeen[ptr] = struct{}{}
+			defer delete(e.ptrSeen, ptr)
+		}
+		defer func() { e.ptrLevel-- }()
+	}
+
+	e.beginBrace()
+	n := v.Len()
+	for i := 0; i < n; i++ {
+		e.elementSeparator(i)
+		e.reflectValue(v.Index(i))
+	}
+	e.endBrace(n)
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Type() {
+	case bitStringType:
+		return v.Field(1).Int() == 0
+	case timeType:
+		return v.Interface().(time.Time).IsZero()
+	case dateType:
+		return v.Interface().(Date).IsZero()
+	case dateTimeType:
+		return v.Interface().(DateTime).IsZero()
+	case durationType:
+		return v.Interface().(Duration) == Duration{}
+	}
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+// beginBrace starts a brace-delimited SEQUENCE or SEQUENCE OF value.
+func (e *encodeState) beginBrace() {
+	e.WriteByte('{')
+	e.indentLevel++
+}
+
+// elementSeparator starts the element after n preceding ones, putting it on
+// a line of its own when indenting.
+func (e *encodeState) elementSeparator(n int) {
+	if n > 0 {
+		e.WriteByte(',')
+	}
+	if e.indent == "" && e.prefix == "" {
+		e.WriteByte(' ')
+		return
+	}
+	e.newline(e.indentLevel)
+}
+
+// endBrace ends a brace-delimited value holding n elements.
+func (e *encodeState) endBrace(n int) {
+	e.indentLevel--
+	if n > 0 && (e.indent != "" || e.prefix != "") {
+		e.newline(e.indentLevel)
+		e.WriteByte('}')
+		return
+	}
+	e.WriteString(" }")
+}
+
+func (e *encodeState) newline(depth int) {
+	e.WriteByte('\n')
+	e.WriteString(e.prefix)
+	for i := 0; i < depth; i++ {
+		e.WriteString(e.indent)
+	}
+}
+
+func (e *encodeState) objectIdentifier(v reflect.Value) {
+	e.WriteByte('{')
+	for i, n := 0, v.Len(); i < n; i++ {
+		e.WriteByte(' ')
+		e.Write(strconv.AppendInt(e.scratch[:0], v.Index(i).Int(), 10))
+	}
+	e.WriteString(" }")
+}
+
+const hexDigits = "0123456789ABCDEF"
+
+// hstring encodes the byte slice or array v as an hstring.
+func (e *encodeState) hstring(v reflect.Value) {
+	var b []byte
+	if v.Kind() == reflect.Slice {
+		b = v.Bytes()
+	} else {
+		b = make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+	}
+	e.writeHex(b)
+}
+
+// hexInteger encodes the integer n that v holds as an hstring of its
+// unsigned big-endian octets, at least one, for a field with the
+// "intfromhex" tag option.
+func (e *encodeState) hexInteger(v reflect.Value, n *big.Int) {
+	if n.Sign() < 0 {
+		e.error(&UnsupportedValueError{v, "negative integer " + n.String() + " with intfromhex"})
+	}
+	b := n.Bytes()
+	if len(b) == 0 {
+		b = []byte{0}
+	}
+	e.writeHex(b)
+}
+
+func (e *encodeState) writeHex(b []byte) {
+	e.WriteByte('\'')
+	for i, c := range b {
+		if e.hexLine > 0 && i > 0 && i%e.hexLine == 0 {
+			e.newline(e.indentLevel + 1)
+		}
+		e.WriteByte(hexDigits[c>>4])
+		e.WriteByte(hexDigits[c&0xF])
+	}
+	e.WriteString("'H")
+}
+
+func (e *encodeState) bitString(bs BitString) {
+	if names, ok := bitNames(e.named, bs); ok && e.named != nil {
+		// A named bit list, { bitA, bitC }.
+		e.beginBrace()
+		for i, name := range names {
+			e.elementSeparator(i)
+			e.WriteString(name)
+		}
+		e.endBrace(len(names))
+		return
+	}
+	if bs.BitLength%8 == 0 && len(bs.Bytes) == bs.BitLength/8 {
+		e.writeHex(bs.Bytes)
+		return
+	}
+	e.WriteByte('\'')
+	for i := 0; i < bs.BitLength; i++ {
+		e.WriteByte(byte('0' + bs.At(i)))
+	}
+	e.WriteString("'B")
+}
+
+func (e *encodeState) real(f float64, bits int) {
+	switch {
+	case math.IsInf(f, 1):
+		e.WriteString("PLUS-INFINITY")
+		return
+	case math.IsInf(f, -1):
+		e.WriteString("MINUS-INFINITY")
+		return
+	case math.IsNaN(f):
+		e.WriteString("NOT-A-NUMBER")
+		return
+	case f == 0 && math.Signbit(f):
+		// -0 would read back as the INTEGER 0.
+		e.WriteString("-0.0")
+		return
+	}
+	b := strconv.AppendFloat(e.scratch[:0], f, 'g', -1, bits)
+	// Value notation has no plus sign in exponents: 1e+21 is 1e21.
+	if i := bytes.IndexByte(b, '+'); i >= 0 {
+		b = append(b[:i], b[i+1:]...)
+	}
+	e.Write(b)
+}
+
+// time writes the time.Time v as a cstring holding a GeneralizedTime, or
+// a UTCTime for a field with the "utc" tag option.
+func (e *encodeState) time(v reflect.Value) {
+	k := KindGeneralizedTime
+	if e.utcTime {
+		k = KindUTCTime
+	}
+	s, err := formatTime(k, v.Interface().(time.Time))
+	if err != nil {
+		e.error(&UnsupportedValueError{v, err.Error()})
+	}
+	e.WriteByte('"')
+	e.WriteString(s)
+	e.WriteByte('"')
+}
+
+func (e *encodeState) cstring(v reflect.Value) {
+	s := v.String()
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 && c != '\t' {
+			e.error(&UnsupportedValueError{v, "string " + strconv.Quote(s) + " cannot be written as a cstring"})
+		}
+	}
+	e.WriteByte('"')
+	e.WriteString(strings.ReplaceAll(s, `"`, `""`))
+	e.WriteByte('"')
+}
+
+// isValidIdentifier reports whether s can be written as an identifier in
+// value notation.
+func isValidIdentifier(s string) bool {
+	return s != "" && isLetter(s[0]) && isValidName(s)
+}
+
+// isValidReference reports whether s can be written where value notation
+// takes an identifier, as the default dialect reads it: an identifier,
+// possibly with parts after it separated by dots, such as the value of
+// the external value reference Module.value.
+func isValidReference(s string) bool {
+	for i, part := range strings.Split(s, ".") {
+		switch {
+		case i == 0:
+			if !isValidIdentifier(part) {
+				return false
+			}
+		case strings.HasPrefix(part, "&"):
+			part = part[1:]
+			if part == "" || !isLetter(part[0]) || !isValidName(part) {
+				return false
+			}
+		case part == "" || !isNameChar(part[0]) || !isValidName(part):
+			return false
+		}
+	}
+	return true
+}
+
+// isValidTypeReference reports whether s can be written as the type of a
+// value assignment: a type reference, which begins with an upper case
+// letter, or several words such as OCTET STRING separated by single
+// spaces.
+func isValidTypeReference(s string) bool {
+	for _, w := range strings.Split(s, " ") {
+		if w == "" || w[0] < 'A' || 'Z' < w[0] || !isValidName(w) {
+			return false
+		}
+	}
+	return true
+}
+
+// isValidOpenType reports whether s can be written as the type of an open
+// type value: a type reference, possibly with a module reference before it
+// and field references such as &id after it, separated by dots.
+func isValidOpenType(s string) bool {
+	for i, part := range strings.Split(s, ".") {
+		if i > 0 && strings.HasPrefix(part, "&") {
+			part = part[1:]
+			if part == "" || !isLetter(part[0]) || !isValidName(part) {
+				return false
+			}
+			continue
+		}
+		if part == "" || part[0] < 'A' || 'Z' < part[0] || !isValidName(part) {
+			return false
+		}
+	}
+	return true
+}
+
+// isValidName reports whether the letters, digits and hyphens of s form a
+// name, with no trailing or repeated hyphens.
+func isValidName(s string) bool {
+	if s[len(s)-1] == '-' {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if c == '-' {
+			if s[i-1] == '-' {
+				return false
+			}
+			continue
+		}
+		if !isNameChar(c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,567 @@
+package asn1go
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestMarshalReference(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want string // "" for an UnsupportedValueError
+	}{
+		{NewIdentifier("Module.value"), "Module.value"},
+		{NewIdentifier("A.0"), "A.0"},
+		{NewIdentifier("Module.&id"), "Module.&id"},
+		{NewChoice("Module.alt", NewInt(1)), "Module.alt : 1"},
+		{map[string]int{"a.b": 1}, "{ a.b 1 }"},
+		{OrderedObject{{"a.b", 1}}, "{ a.b 1 }"},
+		{NewIdentifier("Module."), ""},
+		{NewIdentifier(".value"), ""},
+		{NewIdentifier("Module..value"), ""},
+		{NewIdentifier("Module.&1"), ""},
+		{NewIdentifier("1.value"), ""},
+		{map[string]int{"a.-b": 1}, ""},
+	}
+	for _, tt := range tests {
+		b, err := Marshal(tt.v)
+		if tt.want == "" {
+			if _, ok := err.(*UnsupportedValueError); !ok {
+				t.Errorf("Marshal(%#v) = %q, %v, want UnsupportedValueError", tt.v, b, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Marshal(%#v): %v", tt.v, err)
+			continue
+		}
+		if string(b) != tt.want {
+			t.Errorf("Marshal(%#v) = %q, want %q", tt.v, b, tt.want)
+		}
+		if !Valid(b) {
+			t.Errorf("Marshal(%#v) = %q, not valid", tt.v, b)
+		}
+	}
+}
+
+func TestMarshalReal(t *testing.T) {
+	tests := []struct {
+		f    float64
+		want string
+	}{
+		{1.5, "1.5"},
+		{-2.25, "-2.25"},
+		{1e21, "1e21"},
+		{1e-7, "1e-07"},
+		{math.Copysign(0, -1), "-0.0"},
+		{math.Inf(1), "PLUS-INFINITY"},
+		{math.Inf(-1), "MINUS-INFINITY"},
+		{math.NaN(), "NOT-A-NUMBER"},
+	}
+	for _, tt := range tests {
+		b, err := Marshal(tt.f)
+		if err != nil {
+			t.Errorf("Marshal(%v): %v", tt.f, err)
+			continue
+		}
+		if string(b) != tt.want {
+			t.Errorf("Marshal(%v) = %q, want %q", tt.f, b, tt.want)
+		}
+		var v interface{}
+		if err := Unmarshal(b, &v); err != nil {
+			t.Errorf("Unmarshal(%q): %v", b, err)
+			continue
+		}
+		if f, ok := v.(float64); !ok || math.Float64bits(f) != math.Float64bits(tt.f) && !math.IsNaN(tt.f) {
+			t.Errorf("Unmarshal(%q) = %#v, want %v", b, v, tt.f)
+		}
+	}
+}
+
+type peHeader struct {
+	MajorVersion int    `asn1:"major-version"`
+	MinorVersion int    `asn1:"minor-version"`
+	ProfileType  string `asn1:"profileType,omitempty"`
+	ICCID        []byte `asn1:"iccid"`
+	Services     *struct {
+		USIM *struct{} `asn1:"usim"`
+	} `asn1:"eUICC-Mandatory-services"`
+}
+
+type profileElement struct {
+	Header *peHeader `asn1:"header,choice"`
+	End    *struct{} `asn1:"end,choice"`
+}
+
+func TestMarshal(t *testing.T) {
+	i := 5
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{5, "5"},
+		{int8(-128), "-128"},
+		{uint64(1 << 63), "9223372036854775808"},
+		{true, "TRUE"},
+		{"a \"b\"", `"a ""b"""`},
+		{[]byte{0x0A, 0x1B}, "'0A1B'H"},
+		{[2]byte{0xFF, 0x00}, "'FF00'H"},
+		{BitString{Bytes: []byte{0x60}, BitLength: 4}, "'0110'B"},
+		{BitString{Bytes: []byte{0x60}, BitLength: 8}, "'60'H"},
+		{ObjectIdentifier{2, 23, 143, 1, 2, 1}, "{ 2 23 143 1 2 1 }"},
+		{[]int{1, 2}, "{ 1, 2 }"},
+		{[]int{}, "{ }"},
+		{map[string]int{"b": 2, "a": 1}, "{ a 1, b 2 }"},
+		{OrderedObject{{"b", 2}, {"a", 1}, {"b", 3}}, "{ b 2, a 1, b 3 }"},
+		{ChoiceValue{"alt", 1}, "alt : 1"},
+		{&i, "5"},
+		{(*int)(nil), "NULL"},
+		{nil, "NULL"},
+		{struct{}{}, "NULL"},
+		{
+			peHeader{MajorVersion: 2, MinorVersion: 3, ICCID: []byte{0x89}},
+			"{ major-version 2, minor-version 3, iccid '89'H }",
+		},
+		{
+			profileElement{Header: &peHeader{MajorVersion: 2, ProfileType: "x", Services: &struct {
+				USIM *struct{} `asn1:"usim"`
+			}{USIM: &struct{}{}}}},
+			`header : { major-version 2, minor-version 0, profileType "x", iccid ''H, eUICC-Mandatory-services { usim NULL } }`,
+		},
+		{profileElement{End: &struct{}{}}, "end : NULL"},
+		{struct {
+			A int
+			B *int
+			C []int `asn1:"c,omitempty"`
+		}{A: 1}, "{ a 1 }"},
+	}
+	for _, tt := range tests {
+		b, err := Marshal(tt.v)
+		if err != nil {
+			t.Errorf("Marshal(%#v): %v", tt.v, err)
+			continue
+		}
+		if string(b) != tt.want {
+			t.Errorf("Marshal(%#v):\nhave %s\nwant %s", tt.v, b, tt.want)
+		}
+	}
+}
+
+func TestMarshalError(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want interface{} // type of the error
+	}{
+		{make(chan int), &UnsupportedTypeError{}},
+		{func() {}, &UnsupportedTypeError{}},
+		{complex(1, 2), &UnsupportedTypeError{}},
+		{map[int]int{1: 1}, &UnsupportedTypeError{}},
+		{"line\nbreak", &UnsupportedValueError{}},
+		{"carriage\rreturn", &UnsupportedValueError{}},
+		{map[string]int{"not an identifier": 1}, &UnsupportedValueError{}},
+		{profileElement{}, &UnsupportedValueError{}},
+		{profileElement{Header: &peHeader{}, End: &struct{}{}}, &UnsupportedValueError{}},
+	}
+	for _, tt := range tests {
+		_, err := Marshal(tt.v)
+		if reflect.TypeOf(err) != reflect.TypeOf(tt.want) {
+			t.Errorf("Marshal(%#v): error %v, want %T", tt.v, err, tt.want)
+		}
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	in := peHeader{MajorVersion: 2, MinorVersion: 3, ProfileType: "operational", ICCID: []byte{0x89, 0x01}}
+	b, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var out peHeader
+	if err := Unmarshal(b, &out); err != nil {
+		t.Fatalf("Unmarshal(%q): %v", b, err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("Unmarshal(Marshal(%+v)) = %+v", in, out)
+	}
+}
+
+func TestMarshalGenericRoundTrip(t *testing.T) {
+	// An empty interface loses CHOICE values and identifiers, a Value
+	// keeps them.
+	tests := []struct {
+		in    string
+		iface string // Marshal of the value decoded into an empty interface
+	}{
+		{"5", "5"},
+		{"{ a 1, b { 2 23 }, c '0A'H, d NULL }", "{ a 1, b { 2 23 }, c '0A'H, d NULL }"},
+		{"alt : 5", "{ alt 5 }"},
+		{"{ a alt : TRUE }", "{ a { alt TRUE } }"},
+		{"{ alt : 1, b : 2 }", "{ { alt 1 }, { b 2 } }"},
+		{"enabled", `"enabled"`},
+		{"{ a enabled }", `{ a "enabled" }`},
+		{"{ a T : 1 }", "{ a T : 1 }"}, // an OpenTypeValue
+	}
+	for _, tt := range tests {
+		var v interface{}
+		if err := Unmarshal([]byte(tt.in), &v); err != nil {
+			t.Errorf("Unmarshal(%q, interface): %v", tt.in, err)
+			continue
+		}
+		if b, err := Marshal(v); err != nil || string(b) != tt.iface {
+			t.Errorf("Marshal of %q decoded into an interface = %s, %v, want %s", tt.in, b, err, tt.iface)
+		}
+		var val Value
+		if err := Unmarshal([]byte(tt.in), &val); err != nil {
+			t.Errorf("Unmarshal(%q, Value): %v", tt.in, err)
+			continue
+		}
+		if b, err := Marshal(val); err != nil || string(b) != tt.in {
+			t.Errorf("Marshal of %q decoded into a Value = %s, %v, want the input", tt.in, b, err)
+		}
+	}
+}
+
+func TestMarshalCStringRoundTrip(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"", `""`},
+		{`"`, `""""`},
+		{`""`, `""""""`},
+		{`"a`, `"""a"`},
+		{`a"`, `"a"""`},
+		{`"a"`, `"""a"""`},
+		{"a\tb", "\"a\tb\""},
+	}
+	for _, tt := range tests {
+		b, err := Marshal(tt.s)
+		if err != nil || string(b) != tt.want {
+			t.Errorf("Marshal(%q) = %s, %v, want %s", tt.s, b, err, tt.want)
+			continue
+		}
+		var s string
+		if err := Unmarshal(b, &s); err != nil || s != tt.s {
+			t.Errorf("Unmarshal(%s) = %q, %v, want %q", b, s, err, tt.s)
+		}
+	}
+}
+
+func TestMarshalIndent(t *testing.T) {
+	tests := []struct {
+		v              interface{}
+		prefix, indent string
+		want           string
+	}{
+		{5, "", "  ", "5"},
+		{[]int{}, "", "  ", "{ }"},
+		{ObjectIdentifier{2, 23, 143}, "", "  ", "{ 2 23 143 }"},
+		{[]int{1, 2}, "", "\t", "{\n\t1,\n\t2\n}"},
+		{
+			peHeader{MajorVersion: 2, ICCID: []byte{0x89}, Services: &struct {
+				USIM *struct{} `asn1:"usim"`
+			}{USIM: &struct{}{}}},
+			"", "  ",
+			"{\n  major-version 2,\n  minor-version 0,\n  iccid '89'H,\n  eUICC-Mandatory-services {\n    usim NULL\n  }\n}",
+		},
+		{ChoiceValue{"alt", []int{1}}, "> ", "  ", "alt : {\n>   1\n> }"},
+	}
+	for _, tt := range tests {
+		b, err := MarshalIndent(tt.v, tt.prefix, tt.indent)
+		if err != nil {
+			t.Errorf("MarshalIndent(%#v): %v", tt.v, err)
+			continue
+		}
+		if string(b) != tt.want {
+			t.Errorf("MarshalIndent(%#v, %q, %q):\nhave %q\nwant %q", tt.v, tt.prefix, tt.indent, b, tt.want)
+		}
+	}
+}
+
+func TestMarshalAssignment(t *testing.T) {
+	tests := []struct {
+		name, typ string
+		v         interface{}
+		want      string
+		err       bool
+	}{
+		{"value1", "INTEGER", 5, "value1 INTEGER ::= 5", false},
+		{"v", "OCTET STRING", []byte{1}, "v OCTET STRING ::= '01'H", false},
+		{"v", "ProfileElement", profileElement{End: &struct{}{}}, "v ProfileElement ::= end : NULL", false},
+		{"1v", "INTEGER", 5, "", true},
+		{"v", "integer", 5, "", true},
+		{"v", "", 5, "", true},
+	}
+	for _, tt := range tests {
+		b, err := MarshalAssignment(tt.name, tt.typ, tt.v)
+		if tt.err {
+			if _, ok := err.(*UnsupportedValueError); !ok {
+				t.Errorf("MarshalAssignment(%q, %q, %#v) = %q, %v, want UnsupportedValueError", tt.name, tt.typ, tt.v, b, err)
+			}
+			continue
+		}
+		if err != nil || string(b) != tt.want {
+			t.Errorf("MarshalAssignment(%q, %q, %#v) = %q, %v, want %q", tt.name, tt.typ, tt.v, b, err, tt.want)
+		}
+	}
+}
+
+func TestValueAssignment(t *testing.T) {
+	in := []ValueAssignment{
+		{"value1", "ProfileElement", profileElement{Header: &peHeader{MajorVersion: 2}}},
+		{"value2", "ProfileElement", profileElement{End: &struct{}{}}},
+	}
+	b, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	want := "value1 ProfileElement ::= header : { major-version 2, minor-version 0, iccid ''H }\n" +
+		"value2 ProfileElement ::= end : NULL"
+	if string(b) != want {
+		t.Errorf("Marshal:\nhave %q\nwant %q", b, want)
+	}
+	out := [2]ValueAssignment{{Value: new(profileElement)}, {Value: new(profileElement)}}
+	if err := Unmarshal(b, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	for i := range out {
+		if out[i].Name != in[i].Name || out[i].Type != in[i].Type {
+			t.Errorf("Unmarshal: assignment %d is %s %s, want %s %s", i, out[i].Name, out[i].Type, in[i].Name, in[i].Type)
+		}
+	}
+	if pe := out[0].Value.(*profileElement); pe.Header == nil || pe.Header.MajorVersion != 2 {
+		t.Errorf("Unmarshal: value1 = %+v", pe)
+	}
+	if pe := out[1].Value.(*profileElement); pe.End == nil {
+		t.Errorf("Unmarshal: value2 = %+v", pe)
+	}
+}
+
+func TestMarshalOmitEmpty(t *testing.T) {
+	type omit struct {
+		B bool               `asn1:"b,omitempty"`
+		I int                `asn1:"i,omitempty"`
+		U uint8              `asn1:"u,omitempty"`
+		F float64            `asn1:"f,omitempty"`
+		S string             `asn1:"s,omitempty"`
+		H []byte             `asn1:"h,omitempty"`
+		L []int              `asn1:"l,omitempty"`
+		A [0]int             `asn1:"a,omitempty"`
+		M map[string]int     `asn1:"m,omitempty"`
+		P *int               `asn1:"p,omitempty"`
+		X BitString          `asn1:"x,omitempty"`
+		O ObjectIdentifier   `asn1:"o,omitempty"`
+		V interface{}        `asn1:"v,omitempty"`
+		N struct{ Z int }    `asn1:"n,omitempty"`
+		K map[string]float64 `asn1:"k"`
+	}
+	zero := 0
+	tests := []struct {
+		v    omit
+		want string
+	}{
+		{omit{}, "{ n { z 0 }, k { } }"},
+		{omit{L: []int{}, M: map[string]int{}, H: []byte{}}, "{ n { z 0 }, k { } }"},
+		{omit{B: true, I: -1, U: 1, F: 0.5, S: "x"}, `{ b TRUE, i -1, u 1, f 0.5, s "x", n { z 0 }, k { } }`},
+		{omit{H: []byte{0}, L: []int{0}, P: &zero}, "{ h '00'H, l { 0 }, p 0, n { z 0 }, k { } }"},
+		{omit{X: BitString{Bytes: []byte{0}, BitLength: 1}, O: ObjectIdentifier{1, 2}}, "{ x '0'B, o { 1 2 }, n { z 0 }, k { } }"},
+		{omit{V: 0}, "{ v 0, n { z 0 }, k { } }"},
+	}
+	for _, tt := range tests {
+		b, err := Marshal(tt.v)
+		if err != nil {
+			t.Errorf("Marshal(%+v): %v", tt.v, err)
+			continue
+		}
+		if string(b) != tt.want {
+			t.Errorf("Marshal(%+v):\nhave %s\nwant %s", tt.v, b, tt.want)
+		}
+	}
+}
+
+func TestMarshalChoice(t *testing.T) {
+	type fileContent struct {
+		Offset  int    `asn1:"offset,choice:fillFileOffset"`
+		Content []byte `asn1:"content,choice:fillFileContent"`
+	}
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{ChoiceValue{"genericFileManagement", []int{1}}, "genericFileManagement : { 1 }"},
+		{ChoiceValue{"alt", ChoiceValue{"inner", true}}, "alt : inner : TRUE"},
+		{[]ChoiceValue{{"a", 1}, {"b", nil}}, "{ a : 1, b : NULL }"},
+		{OpenTypeValue{"INTEGER", 5}, "INTEGER : 5"},
+		{OpenTypeValue{"Module.Type", []int{}}, "Module.Type : { }"},
+		{fileContent{Offset: 2, Content: []byte{0}}, "{ offset fillFileOffset : 2, content fillFileContent : '00'H }"},
+		{profileElement{End: &struct{}{}}, "end : NULL"},
+		{[]profileElement{{End: &struct{}{}}, {End: &struct{}{}}}, "{ end : NULL, end : NULL }"},
+	}
+	for _, tt := range tests {
+		b, err := Marshal(tt.v)
+		if err != nil {
+			t.Errorf("Marshal(%#v): %v", tt.v, err)
+			continue
+		}
+		if string(b) != tt.want {
+			t.Errorf("Marshal(%#v):\nhave %s\nwant %s", tt.v, b, tt.want)
+		}
+		var v interface{}
+		if err := Unmarshal(b, &v); err != nil {
+			t.Errorf("Unmarshal(%q): %v", b, err)
+		}
+	}
+}
+
+// headerMap is a map of the components of a PE-Header, in the order of
+// its type definition.
+type headerMap map[string]interface{}
+
+func (headerMap) ComponentOrder() []string {
+	return []string{"major-version", "minor-version", "profileType", "iccid"}
+}
+
+func TestMarshalOrder(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{map[string]int{"c": 3, "a": 1, "b": 2}, "{ a 1, b 2, c 3 }"},
+		{headerMap{"iccid": []byte{0x89}, "minor-version": 3, "major-version": 2}, "{ major-version 2, minor-version 3, iccid '89'H }"},
+		{headerMap{"zz": 1, "iccid": []byte{}, "aa": 2, "major-version": 2}, "{ major-version 2, iccid ''H, aa 2, zz 1 }"},
+		{struct{ Z, A, M int }{1, 2, 3}, "{ z 1, a 2, m 3 }"},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 3; i++ {
+			b, err := Marshal(tt.v)
+			if err != nil {
+				t.Fatalf("Marshal(%#v): %v", tt.v, err)
+			}
+			if string(b) != tt.want {
+				t.Fatalf("Marshal(%#v):\nhave %s\nwant %s", tt.v, b, tt.want)
+			}
+		}
+	}
+}
+
+// rawMarshaler marshals to its own text.
+type rawMarshaler string
+
+func (m rawMarshaler) MarshalASN1() ([]byte, error) { return []byte(m), nil }
+
+// failingMarshaler fails to marshal.
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalASN1() ([]byte, error) { return nil, errors.New("no value") }
+
+func TestMarshaler(t *testing.T) {
+	tests := []struct {
+		m    rawMarshaler
+		want string // "" if the output is rejected
+	}{
+		{"5", "{ m 5 }"},
+		{"{ a 1, b TRUE }", "{ m { a 1, b TRUE } }"},
+		{"alt : 'AB'H", "{ m alt : 'AB'H }"},
+		{"red", "{ m red }"},
+		{"{ 2 23 143 }", "{ m { 2 23 143 } }"},
+		{"5 -- five", "{ m 5 -- five\n }"},
+		{"", ""},
+		{"{ a 1", ""},
+		{"5 6", ""},
+		{"a T ::= 5", ""},
+		{"a INTEGER ::= 5", ""},
+		{"a T ::= { b 1 }", ""},
+	}
+	for _, tt := range tests {
+		v := struct{ M rawMarshaler }{tt.m}
+		b, err := Marshal(v)
+		if tt.want == "" {
+			if _, ok := err.(*MarshalerError); !ok {
+				t.Errorf("Marshal(%q): error %v, want MarshalerError", tt.m, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Marshal(%q): %v", tt.m, err)
+			continue
+		}
+		if string(b) != tt.want {
+			t.Errorf("Marshal(%q):\nhave %s\nwant %s", tt.m, b, tt.want)
+		}
+	}
+	_, err := Marshal(failingMarshaler{})
+	if me, ok := err.(*MarshalerError); !ok || me.Err == nil || me.Err.Error() != "no value" {
+		t.Errorf("Marshal of a failing marshaler: error %v, want MarshalerError of \"no value\"", err)
+	}
+}
+
+func TestMarshalBigInt(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	type sized struct {
+		Size *big.Int `asn1:",intfromhex"`
+	}
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{big.NewInt(0), "0"},
+		{big.NewInt(-1), "-1"},
+		{huge, "123456789012345678901234567890"},
+		{new(big.Int).Neg(huge), "-123456789012345678901234567890"},
+		{*huge, "123456789012345678901234567890"},
+		{struct{ N *big.Int }{huge}, "{ n 123456789012345678901234567890 }"},
+		{sized{big.NewInt(256)}, "{ size '0100'H }"},
+	}
+	for _, tt := range tests {
+		b, err := Marshal(tt.v)
+		if err != nil {
+			t.Errorf("Marshal(%v): %v", tt.v, err)
+			continue
+		}
+		if string(b) != tt.want {
+			t.Errorf("Marshal(%v) = %s, want %s", tt.v, b, tt.want)
+		}
+		if n, ok := tt.v.(*big.Int); ok {
+			back := new(big.Int)
+			if err := Unmarshal(b, back); err != nil || back.Cmp(n) != 0 {
+				t.Errorf("Unmarshal(%s) = %v, %v, want %v", b, back, err, n)
+			}
+		}
+	}
+	if _, err := Marshal(sized{big.NewInt(-1)}); err == nil {
+		t.Error("Marshal of a negative intfromhex big.Int: no error")
+	}
+}
+
+// namedRecord has fields with named numbers and bits.
+type namedRecord struct {
+	Version int       `asn1:"version,named:v1=0|v2=1"`
+	Level   uint8     `asn1:"level,named:low=1|high=200"`
+	Flags   BitString `asn1:"flags,named:bitA=0|bitB=1|bitC=2"`
+}
+
+func TestMarshalNamed(t *testing.T) {
+	tests := []struct {
+		v    namedRecord
+		want string
+	}{
+		{namedRecord{1, 200, BitString{Bytes: []byte{0xA0}, BitLength: 3}}, "{ version v2, level high, flags { bitA, bitC } }"},
+		{namedRecord{0, 1, BitString{}}, "{ version v1, level low, flags { } }"},
+		// Numbers and bits without a name.
+		{namedRecord{5, 7, BitString{Bytes: []byte{0x10}, BitLength: 4}}, "{ version 5, level 7, flags '0001'B }"},
+		{namedRecord{-1, 0, BitString{Bytes: []byte{0x90}, BitLength: 4}}, "{ version -1, level 0, flags '1001'B }"},
+	}
+	for _, tt := range tests {
+		b, err := Marshal(tt.v)
+		if err != nil {
+			t.Errorf("Marshal(%+v): %v", tt.v, err)
+			continue
+		}
+		if string(b) != tt.want {
+			t.Errorf("Marshal(%+v):\nhave %s\nwant %s", tt.v, b, tt.want)
+		}
+	}
+}
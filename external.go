@@ -0,0 +1,69 @@
+package asn1go
+
+import "reflect"
+
+// The EXTERNAL and EMBEDDED PDV types carry a value of a type defined
+// elsewhere, identified by an object identifier or a presentation
+// context. Their values are written in the value notation of their
+// associated types (X.680 clauses 36 and 37), such as
+//
+//	{ identification syntax : { 2 1 1 }, data-value '0102'H }
+//
+// which decodes into an External or an EmbeddedPDV.
+
+// External is the Go representation of an EXTERNAL value. Its
+// identification is syntax, presentation-context-id or
+// context-negotiation. The schema-driven encoding rules encode EXTERNAL
+// in a form of its own rather than as its associated type, which TypeOf
+// does not support.
+type External struct {
+	Identification      Identification `asn1:"identification,tag:0"`
+	DataValueDescriptor string         `asn1:"data-value-descriptor,omitempty,tag:1"`
+	DataValue           []byte         `asn1:"data-value,tag:2"`
+}
+
+// EmbeddedPDV is the Go representation of an EMBEDDED PDV value, which
+// TypeOf and the module parser give the associated type, tagged
+// [UNIVERSAL 11] as the encoding rules require.
+type EmbeddedPDV struct {
+	Identification Identification `asn1:"identification,tag:0"`
+	DataValue      []byte         `asn1:"data-value,tag:2"`
+}
+
+// Identification identifies the type and encoding of the data value of
+// an External or EmbeddedPDV. It is a CHOICE: exactly one field is set.
+type Identification struct {
+	Syntaxes              *Syntaxes           `asn1:"syntaxes,choice,tag:0"`
+	Syntax                *ObjectIdentifier   `asn1:"syntax,choice,tag:1"`
+	PresentationContextID *int64              `asn1:"presentation-context-id,choice,tag:2"`
+	ContextNegotiation    *ContextNegotiation `asn1:"context-negotiation,choice,tag:3"`
+	TransferSyntax        *ObjectIdentifier   `asn1:"transfer-syntax,choice,tag:4"`
+	Fixed                 *struct{}           `asn1:"fixed,choice,tag:5"`
+}
+
+// Syntaxes are the abstract and transfer syntax of a data value.
+type Syntaxes struct {
+	Abstract ObjectIdentifier `asn1:"abstract,tag:0"`
+	Transfer ObjectIdentifier `asn1:"transfer,tag:1"`
+}
+
+// ContextNegotiation is a presentation context of a data value together
+// with its transfer syntax.
+type ContextNegotiation struct {
+	PresentationContextID int64            `asn1:"presentation-context-id,tag:0"`
+	TransferSyntax        ObjectIdentifier `asn1:"transfer-syntax,tag:1"`
+}
+
+var (
+	externalType    = reflect.TypeOf(External{})
+	embeddedPDVType = reflect.TypeOf(EmbeddedPDV{})
+)
+
+// embeddedPDV returns the type of EMBEDDED PDV: its associated type st,
+// with the tag of EMBEDDED PDV in place of that of SEQUENCE.
+func embeddedPDV(st *Type) *Type {
+	pdv := *st
+	pdv.Name = ""
+	pdv.Tags = []TypeTag{{Tag: Tag{ClassUniversal, TagEmbeddedPDV}}}
+	return &pdv
+}
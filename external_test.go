@@ -0,0 +1,120 @@
+package asn1go
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// identifications returns an Identification of each alternative.
+func identifications() []Identification {
+	oid := ObjectIdentifier{2, 1, 1}
+	ber := ObjectIdentifier{2, 1, 1, 0}
+	id := int64(3)
+	return []Identification{
+		{Syntaxes: &Syntaxes{Abstract: oid, Transfer: ber}},
+		{Syntax: &oid},
+		{PresentationContextID: &id},
+		{ContextNegotiation: &ContextNegotiation{PresentationContextID: id, TransferSyntax: ber}},
+		{TransferSyntax: &ber},
+		{Fixed: &struct{}{}},
+	}
+}
+
+func TestExternalRoundTrip(t *testing.T) {
+	want := []string{
+		"{ identification syntaxes : { abstract { 2 1 1 }, transfer { 2 1 1 0 } }, data-value '0102'H }",
+		"{ identification syntax : { 2 1 1 }, data-value '0102'H }",
+		"{ identification presentation-context-id : 3, data-value '0102'H }",
+		"{ identification context-negotiation : { presentation-context-id 3, transfer-syntax { 2 1 1 0 } }, data-value '0102'H }",
+		"{ identification transfer-syntax : { 2 1 1 0 }, data-value '0102'H }",
+		"{ identification fixed : NULL, data-value '0102'H }",
+	}
+	for i, id := range identifications() {
+		v := External{Identification: id, DataValue: []byte{1, 2}}
+		b, err := Marshal(v)
+		if err != nil {
+			t.Errorf("Marshal(%+v): %v", v, err)
+			continue
+		}
+		if string(b) != want[i] {
+			t.Errorf("Marshal(%+v):\nhave %s\nwant %s", v, b, want[i])
+		}
+		var back External
+		if err := Unmarshal(b, &back); err != nil {
+			t.Errorf("Unmarshal(%s): %v", b, err)
+			continue
+		}
+		if !reflect.DeepEqual(back, v) {
+			t.Errorf("Unmarshal(%s) = %+v, want %+v", b, back, v)
+		}
+	}
+
+	in := `{ identification syntax : { 2 1 1 }, data-value-descriptor "text", data-value '0102'H }`
+	var v External
+	if err := Unmarshal([]byte(in), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.DataValueDescriptor != "text" || v.Identification.Syntax == nil {
+		t.Errorf("Unmarshal(%s) = %+v", in, v)
+	}
+	if b, err := Marshal(v); err != nil || string(b) != in {
+		t.Errorf("Marshal(%+v) = %s, %v, want %s", v, b, err, in)
+	}
+	if _, err := MarshalDER(v); err == nil {
+		t.Error("MarshalDER of an External: no error")
+	}
+}
+
+func TestEmbeddedPDVRoundTrip(t *testing.T) {
+	for _, id := range identifications() {
+		v := EmbeddedPDV{Identification: id, DataValue: []byte{1, 2}}
+		b, err := Marshal(v)
+		if err != nil {
+			t.Errorf("Marshal(%+v): %v", v, err)
+			continue
+		}
+		var back EmbeddedPDV
+		if err := Unmarshal(b, &back); err != nil {
+			t.Errorf("Unmarshal(%s): %v", b, err)
+		} else if !reflect.DeepEqual(back, v) {
+			t.Errorf("Unmarshal(%s) = %+v, want %+v", b, back, v)
+		}
+
+		der, err := MarshalDER(v)
+		if err != nil {
+			t.Errorf("MarshalDER(%+v): %v", v, err)
+			continue
+		}
+		// EMBEDDED PDV is [UNIVERSAL 11], constructed.
+		if der[0] != 0x2B {
+			t.Errorf("MarshalDER(%+v) = % X, want tag 2B", v, der)
+		}
+		var fromDER EmbeddedPDV
+		if err := UnmarshalDER(der, &fromDER); err != nil {
+			t.Errorf("UnmarshalDER(% X): %v", der, err)
+		} else if !reflect.DeepEqual(fromDER, v) {
+			t.Errorf("UnmarshalDER(% X) = %+v, want %+v", der, fromDER, v)
+		}
+	}
+
+	v := EmbeddedPDV{Identification: Identification{Fixed: &struct{}{}}, DataValue: []byte{1, 2}}
+	want := fromHex("2B 08 A0 02 85 00 82 02 01 02")
+	if der, err := MarshalDER(v); err != nil || !bytes.Equal(der, want) {
+		t.Errorf("MarshalDER(%+v) = % X, %v, want % X", v, der, err, want)
+	}
+}
+
+func TestIdentificationError(t *testing.T) {
+	tests := []string{
+		"{ identification syntax : { 2 1 1 }, data-value TRUE }",
+		"{ identification syntax : TRUE, data-value '01'H }",
+		"{ identification presentation-context-id : { 1 2 }, data-value '01'H }",
+	}
+	for _, in := range tests {
+		var v External
+		if err := Unmarshal([]byte(in), &v); err == nil {
+			t.Errorf("Unmarshal(%s) = %+v, want an error", in, v)
+		}
+	}
+}
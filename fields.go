@@ -0,0 +1,272 @@
+package asn1go
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A field represents a single field found in a struct.
+type field struct {
+	name      string
+	nameBytes []byte // []byte(name)
+	fold      string // foldName(nameBytes)
+
+	tag       bool
+	index     []int
+	typ       reflect.Type
+	omitEmpty bool
+
+	alternative bool   // the field is an alternative of a CHOICE struct
+	choice      string // CHOICE alternative the value is written as
+
+	options tagOptions    // all options of the asn1 tag
+	named   []NamedNumber // named numbers or bits of the "named" option
+}
+
+// structFields holds the fields of a struct type in field order, with
+// indexes for looking them up by component identifier.
+type structFields struct {
+	list      []field
+	nameIndex map[string]int
+	foldIndex map[string]int
+	choice    bool // some field is tagged as a CHOICE alternative
+	set       bool // a blank field tagged asn1:",set" marks a SET struct
+}
+
+// byName returns the field for the component identifier name, or nil.
+// An exact match is preferred over one that ignores case and hyphens.
+func (fs *structFields) byName(name []byte) *field {
+	if i, ok := fs.nameIndex[string(name)]; ok {
+		return &fs.list[i]
+	}
+	if i, ok := fs.foldIndex[foldName(name)]; ok {
+		return &fs.list[i]
+	}
+	return nil
+}
+
+// identifierName returns the Go field name with its first letter in lower
+// case, as ASN.1 identifiers begin with a lower case letter.
+func identifierName(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[n:]
+}
+
+// foldName returns name in lower case with hyphens and underscores
+// removed, so that the struct field MajorVersion matches the ASN.1
+// identifier major-version.
+func foldName(name []byte) string {
+	b := make([]byte, 0, len(name))
+	for _, c := range name {
+		switch {
+		case c == '-' || c == '_':
+			continue
+		case 'A' <= c && c <= 'Z':
+			c += 'a' - 'A'
+		}
+		b = append(b, c)
+	}
+	return string(b)
+}
+
+// typeFields returns a list of fields that the asn1go codec should
+// recognize for the given type. The algorithm is breadth-first search
+// over the set of structs to include - the top struct and then any
+// reachable anonymous structs, as in encoding/json.
+func typeFields(t reflect.Type) structFields {
+	type entry struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	// Anonymous fields to explore at the current level and the next.
+	current := []entry{}
+	next := []entry{{typ: t}}
+
+	// Types already visited at an earlier level.
+	visited := map[reflect.Type]bool{}
+
+	var fields []field
+	set := false
+	for len(next) > 0 {
+		current, next = next, current[:0]
+
+		for _, f := range current {
+			if visited[f.typ] {
+				continue
+			}
+			visited[f.typ] = true
+
+			for i := 0; i < f.typ.NumField(); i++ {
+				sf := f.typ.Field(i)
+				if sf.Anonymous {
+					t := sf.Type
+					if t.Kind() == reflect.Pointer {
+						t = t.Elem()
+					}
+					if !sf.IsExported() && t.Kind() != reflect.Struct {
+						// Ignore embedded fields of unexported non-struct types.
+						continue
+					}
+					// Do not ignore embedded fields of unexported struct types
+					// since they may have exported fields.
+				} else if !sf.IsExported() {
+					// Ignore unexported non-embedded fields, except that
+					// a blank field of the struct itself may mark it as
+					// a SET.
+					if sf.Name == "_" && len(f.index) == 0 {
+						_, opts := parseTag(sf.Tag.Get("asn1"))
+						set = set || opts.Contains("set")
+					}
+					continue
+				}
+				tag := sf.Tag.Get("asn1")
+				if tag == "-" {
+					continue
+				}
+				name, opts := parseTag(tag)
+
+				index := make([]int, len(f.index)+1)
+				copy(index, f.index)
+				index[len(f.index)] = i
+
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Pointer {
+					// Follow pointer.
+					ft = ft.Elem()
+				}
+
+				// Record found field and index sequence.
+				if name != "" || !sf.Anonymous || ft.Kind() != reflect.Struct {
+					tagged := name != ""
+					if name == "" {
+						name = identifierName(sf.Name)
+					}
+					choice, _ := opts.Get("choice")
+					// TypeOf reports a malformed "named" option;
+					// the codecs read the field without it.
+					named, _ := opts.namedNumbers()
+					fields = append(fields, field{
+						name:        name,
+						tag:         tagged,
+						index:       index,
+						typ:         ft,
+						omitEmpty:   opts.Contains("omitempty"),
+						alternative: opts.Contains("choice"),
+						choice:      choice,
+						options:     opts,
+						named:       named,
+					})
+					continue
+				}
+
+				// Record new anonymous struct to explore in next round.
+				next = append(next, entry{ft, index})
+			}
+		}
+	}
+
+	// Sort by name, breaking ties with depth, then with "name came from
+	// asn1 tag", then breaking ties with index sequence.
+	sort.Slice(fields, func(i, j int) bool {
+		x := fields
+		if x[i].name != x[j].name {
+			return x[i].name < x[j].name
+		}
+		if len(x[i].index) != len(x[j].index) {
+			return len(x[i].index) < len(x[j].index)
+		}
+		if x[i].tag != x[j].tag {
+			return x[i].tag
+		}
+		return indexLess(x[i].index, x[j].index)
+	})
+
+	// Delete all fields that are hidden by the Go rules for embedded
+	// fields, except that fields with asn1 tags are promoted.
+	out := fields[:0]
+	for advance, i := 0, 0; i < len(fields); i += advance {
+		// One iteration per name.
+		// Find the sequence of fields with the name of this first field.
+		fi := fields[i]
+		name := fi.name
+		for advance = 1; i+advance < len(fields); advance++ {
+			fj := fields[i+advance]
+			if fj.name != name {
+				break
+			}
+		}
+		if advance == 1 { // Only one field with this name
+			out = append(out, fi)
+			continue
+		}
+		if dominant, ok := dominantField(fields[i : i+advance]); ok {
+			out = append(out, dominant)
+		}
+	}
+	fields = out
+
+	// Restore field order, which is the order of the components.
+	sort.Slice(fields, func(i, j int) bool {
+		return indexLess(fields[i].index, fields[j].index)
+	})
+
+	nameIndex := make(map[string]int, len(fields))
+	foldIndex := make(map[string]int, len(fields))
+	choice := false
+	for i := range fields {
+		f := &fields[i]
+		choice = choice || f.alternative
+		f.nameBytes = []byte(f.name)
+		f.fold = foldName(f.nameBytes)
+		nameIndex[f.name] = i
+		if _, ok := foldIndex[f.fold]; !ok {
+			foldIndex[f.fold] = i
+		}
+	}
+	return structFields{fields, nameIndex, foldIndex, choice, set}
+}
+
+var fieldCache sync.Map // map[reflect.Type]structFields
+
+// cachedTypeFields is like typeFields but uses a cache to avoid repeated
+// work. The structFields returned are shared and must not be modified.
+func cachedTypeFields(t reflect.Type) structFields {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.(structFields)
+	}
+	f, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return f.(structFields)
+}
+
+// indexLess orders index sequences lexicographically.
+func indexLess(a, b []int) bool {
+	for k, ak := range a {
+		if k >= len(b) {
+			return false
+		}
+		if ak != b[k] {
+			return ak < b[k]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// dominantField looks through the fields, all of which are known to
+// have the same name, to find the single field that dominates the
+// others using Go's embedding rules, modified by the presence of
+// asn1 tags. If there are multiple top-level fields, the boolean
+// will be false: This condition is an error in Go and we skip all
+// the fields.
+func dominantField(fields []field) (field, bool) {
+	// The fields are sorted in increasing index-length order, then by presence of tag.
+	// That means that the first field is the dominant one. We need only check
+	// for error cases: two fields at top level, either both tagged or neither tagged.
+	if len(fields) > 1 && len(fields[0].index) == len(fields[1].index) && fields[0].tag == fields[1].tag {
+		return field{}, false
+	}
+	return fields[0], true
+}
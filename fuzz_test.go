@@ -0,0 +1,213 @@
+package asn1go
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// Native fuzz targets for the scanner, the decoder and the converters:
+//
+//	go test -fuzz FuzzUnmarshal
+//
+// Without -fuzz, go test runs them on their seed corpus, fuzzSeeds and
+// any inputs that failed before, in testdata/fuzz.
+
+var fuzzSeeds = []string{
+	``,
+	`5`,
+	`-12.5e3`,
+	`"a ""quoted"" string"`,
+	`'0A1B'H`,
+	`'101'B`,
+	`{ }`,
+	`{ 2 23 143 1 2 1 }`,
+	`{ 0 0 }`,
+	`{ 1 99999999999999999999999 }`,
+	`{ 2 -1 }`,
+	`{ a : 1 2 3 }`,
+	`{A:0 0}`,
+	`{ x alt : 1 2 }`,
+	`{ a : 1, b : { 2 3 } }`,
+	`v T ::= { a : -1, 2 }`,
+	`{ a 1, b TRUE, c NULL, d "x", e '00'H }`,
+	`{ { a 1 }, { }, b }`,
+	`{ a 1, { b 2 } }`,
+	`alt : { x 1 }`,
+	`{ fillFileOffset : 2, fillFileContent : '00'H }`,
+	`v INTEGER ::= 5`,
+	"-- comment\nv T ::= { a 1 } /* block */ w T ::= b : 'FF'H",
+	"value1 ProfileElement ::= header : {\n  major-version 2,\n  minor-version 3,\n  iccid '89000000000000000000'H,\n  eUICC-Mandatory-services {\n    usim NULL\n  },\n  eUICC-Mandatory-GFSTEList {\n    { 2 23 143 1 2 1 }\n  }\n}\n",
+	`{ a 1`,
+	`{ a 1,, }`,
+	`'0G'H`,
+}
+
+func addFuzzSeeds(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add([]byte(s))
+	}
+}
+
+// FuzzValid checks data with Valid and also splits it into top-level
+// values and pushes it to a PushDecoder a byte at a time, which must
+// agree on whether it is valid and on the number of values.
+func FuzzValid(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		valid := Valid(data)
+		bounds, err := splitTopValues(data)
+		if (err == nil) != valid {
+			t.Fatalf("Valid(%q) = %v, splitTopValues: %v", data, valid, err)
+		}
+		n := 0
+		p := NewPushDecoder(func(RawValue) error {
+			n++
+			return nil
+		})
+		for i := range data {
+			if err = p.Feed(data[i : i+1]); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = p.Finish()
+		}
+		// Unlike Valid, a PushDecoder accepts input without any value.
+		if (err == nil && n > 0) != valid {
+			t.Fatalf("Valid(%q) = %v, PushDecoder: %v after %d values", data, valid, err, n)
+		}
+		if valid && n != len(bounds)-1 {
+			t.Fatalf("splitTopValues(%q) finds %d values, PushDecoder %d", data, len(bounds)-1, n)
+		}
+	})
+}
+
+// FuzzUnmarshal decodes data into an empty interface and checks that
+// encoding the result with Marshal and decoding it again reaches a fixed
+// point: the first round may lose what an empty interface does not keep,
+// such as the names of value assignments, but a second round must give
+// the same encoding and the same value.
+func FuzzUnmarshal(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var v interface{}
+		if err := Unmarshal(data, &v); err != nil {
+			return
+		}
+		b, err := Marshal(v)
+		if err != nil {
+			return
+		}
+		var w interface{}
+		if err := Unmarshal(b, &w); err != nil {
+			t.Fatalf("Unmarshal(%q): %v", b, err)
+		}
+		b2, err := Marshal(w)
+		if err != nil {
+			t.Fatalf("Marshal(%#v): %v", w, err)
+		}
+		if !bytes.Equal(b, b2) {
+			t.Fatalf("Marshal gives %q, then %q", b, b2)
+		}
+		var x interface{}
+		if err := Unmarshal(b2, &x); err != nil {
+			t.Fatalf("Unmarshal(%q): %v", b2, err)
+		}
+		if !reflect.DeepEqual(w, x) {
+			t.Fatalf("Unmarshal(%q) gives %#v, then %#v", b, w, x)
+		}
+	})
+}
+
+// FuzzIndent checks that Indent and Compact accept exactly the input
+// that Valid does, and that they only change the layout: indenting
+// valid input gives valid input that compacts to the same result.
+func FuzzIndent(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		valid := Valid(data)
+		var indented, compact bytes.Buffer
+		err := Indent(&indented, data, "", "\t")
+		if (err == nil) != valid {
+			t.Fatalf("Valid(%q) = %v, Indent: %v", data, valid, err)
+		}
+		if err := Compact(&compact, data); (err == nil) != valid {
+			t.Fatalf("Valid(%q) = %v, Compact: %v", data, valid, err)
+		}
+		if !valid {
+			return
+		}
+		if !Valid(indented.Bytes()) {
+			t.Fatalf("Indent(%q) = %q, not valid", data, indented.Bytes())
+		}
+		var again bytes.Buffer
+		if err := Compact(&again, indented.Bytes()); err != nil {
+			t.Fatalf("Compact(%q): %v", indented.Bytes(), err)
+		}
+		if !bytes.Equal(again.Bytes(), compact.Bytes()) {
+			t.Fatalf("Compact(%q) = %q, but %q after Indent", data, compact.Bytes(), again.Bytes())
+		}
+	})
+}
+
+// FuzzToJSON checks that ToJSON gives valid JSON that FromJSON reads
+// back to valid value notation.
+func FuzzToJSON(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		out, err := ToJSON(data)
+		if err != nil {
+			return
+		}
+		if !json.Valid(out) {
+			t.Fatalf("ToJSON(%q) = %q, not valid JSON", data, out)
+		}
+		back, err := FromJSON(out, nil)
+		if err != nil {
+			t.Fatalf("FromJSON(%q): %v", out, err)
+		}
+		if !Valid(back) {
+			t.Fatalf("FromJSON(%q) = %q, not valid", out, back)
+		}
+	})
+}
+
+// FuzzToYAML checks that FromYAML reads the output of ToYAML back to
+// valid value notation.
+func FuzzToYAML(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		out, err := ToYAML(data)
+		if err != nil {
+			return
+		}
+		back, err := FromYAML(out, nil)
+		if err != nil {
+			t.Fatalf("FromYAML(%q): %v", out, err)
+		}
+		if !Valid(back) {
+			t.Fatalf("FromYAML(%q) = %q, not valid", out, back)
+		}
+	})
+}
+
+// FuzzToCBOR checks that FromCBOR reads the output of ToCBOR back to
+// valid value notation.
+func FuzzToCBOR(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		out, err := ToCBOR(data)
+		if err != nil {
+			return
+		}
+		back, err := FromCBOR(out, nil)
+		if err != nil {
+			t.Fatalf("FromCBOR(% x): %v", out, err)
+		}
+		if !Valid(back) {
+			t.Fatalf("FromCBOR(% x) = %q, not valid", out, back)
+		}
+	})
+}
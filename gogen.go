@@ -0,0 +1,367 @@
+package asn1go
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GenerateGo returns the source of a Go file in package pkg that declares
+// a Go type for each type of the module m, for use with Unmarshal, Marshal
+// and the schema-driven encoding rules:
+//
+//   - A SEQUENCE or SET is a struct with one field per component, named
+//     after the identifier in Go style with an asn1 tag holding the
+//     identifier. OPTIONAL components are pointers, or slices tagged
+//     omitempty. Slices with a DEFAULT value are tagged omitempty too, so
+//     that an empty one stands for the default. A SET struct begins with
+//     a blank field _ struct{} `asn1:",set"` marking it as one.
+//   - A CHOICE is a struct with one pointer field per alternative, tagged
+//     "choice", of which a value sets one.
+//   - Extensible SEQUENCE, SET and CHOICE types have an Extensions field
+//     tagged "...", for the extension additions the module does not know,
+//     and their extension additions are optional like OPTIONAL components.
+//   - An ENUMERATED type, or an INTEGER with named numbers, is an int64
+//     type with a constant for each named value, such as ColorRed for red
+//     of Color. Its String and MarshalASN1 methods write values as their
+//     identifiers and UnmarshalASN1 reads them back.
+//   - SEQUENCE OF and SET OF are slices, and the other built-in types are
+//     the Go types Unmarshal stores them in.
+//
+// Types written inside other types, such as the type of a component that
+// is a SEQUENCE, are declared too, named after the type and component
+// they belong to. References to imported types are written as the Go name
+// of the imported type, which must be declared in the same package.
+//
+// The tag options of the fields carry the character string types, the
+// named bits of BIT STRING types and the outermost tag of each component,
+// so that TypeOf derives the module's types from the Go types where the
+// options can express them.
+func GenerateGo(m *Module, pkg string) ([]byte, error) {
+	g := goGenerator{m: m, decl: make(map[*Type]string), used: make(map[string]bool)}
+	for _, t := range m.Types {
+		g.decl[t] = g.unique(goName(t.Name))
+	}
+	for _, t := range m.Types {
+		g.declare(t, g.decl[t], "is the ASN.1 type "+t.Name+", "+article(t.Kind.String())+" "+t.Kind.String()+".")
+	}
+	for len(g.queue) > 0 {
+		d := g.queue[0]
+		g.queue = g.queue[1:]
+		g.declare(d.t, d.name, d.doc)
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by asn1go-gen from module %s. DO NOT EDIT.\n\npackage %s\n\n", m.Name, pkg)
+	var imports []string
+	if g.enums {
+		imports = append(imports, `"fmt"`, `"strconv"`)
+	}
+	if g.asn1go {
+		if len(imports) > 0 {
+			imports = append(imports, "")
+		}
+		imports = append(imports, `"github.com/openesim/asn1go"`)
+	}
+	if len(imports) > 0 {
+		fmt.Fprintf(&src, "import (\n%s\n)\n\n", strings.Join(imports, "\n"))
+	}
+	src.Write(g.buf.Bytes())
+	b, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("asn1go: generated invalid Go code for module %s: %v", m.Name, err)
+	}
+	return b, nil
+}
+
+// A goGenerator is the state of GenerateGo.
+type goGenerator struct {
+	m     *Module
+	buf   bytes.Buffer     // declarations
+	decl  map[*Type]string // Go names of the module's types
+	used  map[string]bool  // declared Go names
+	queue []goDecl         // types inside other types, to be declared
+
+	enums  bool // some type has named values, which need fmt and strconv
+	asn1go bool // some field refers to the asn1go package
+}
+
+// A goDecl is a type to be declared as name.
+type goDecl struct {
+	t    *Type
+	name string
+	doc  string // the doc comment, without the name
+}
+
+// goName returns the ASN.1 identifier or reference s as an exported Go
+// identifier, such as EUICCMandatoryServices for
+// eUICC-Mandatory-services.
+func goName(s string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '-' || r == '&' || r == '.' }) {
+		r := []rune(part)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	return b.String()
+}
+
+// article returns the indefinite article for the name of a type.
+func article(name string) string {
+	if strings.IndexByte("AEIO", name[0]) >= 0 {
+		return "an"
+	}
+	return "a"
+}
+
+// unique returns name, or name with a number appended if it is taken.
+func (g *goGenerator) unique(name string) string {
+	s := name
+	for i := 2; g.used[s]; i++ {
+		s = name + strconv.Itoa(i)
+	}
+	g.used[s] = true
+	return s
+}
+
+// declare writes the declaration of the Go type name for t.
+func (g *goGenerator) declare(t *Type, name, doc string) {
+	fmt.Fprintf(&g.buf, "// %s %s\n", name, doc)
+	switch t.Kind {
+	case KindSequence, KindSet, KindChoice:
+		if len(t.Components) == 0 && !t.Extensible {
+			fmt.Fprintf(&g.buf, "type %s struct{}\n\n", name)
+			return
+		}
+		fmt.Fprintf(&g.buf, "type %s struct {\n", name)
+		if t.Kind == KindSet {
+			g.buf.WriteString("\t_ struct{} `asn1:\",set\"`\n")
+		}
+		fields := make(map[string]bool)
+		for i := range t.Components {
+			c := &t.Components[i]
+			field := goName(c.Name)
+			for j := 2; fields[field]; j++ {
+				field = goName(c.Name) + strconv.Itoa(j)
+			}
+			fields[field] = true
+			g.field(t, c, field, name)
+		}
+		if t.Extensible {
+			g.asn1go = true
+			field := "Extensions"
+			for j := 2; fields[field]; j++ {
+				field = "Extensions" + strconv.Itoa(j)
+			}
+			tag := "..."
+			if t.Kind == KindChoice {
+				tag = "...,choice"
+			}
+			fmt.Fprintf(&g.buf, "\t%s asn1go.Extensions `asn1:%s`\n", field, strconv.Quote(tag))
+		}
+		g.buf.WriteString("}\n\n")
+	case KindEnumerated, KindInteger:
+		if len(t.Named) == 0 {
+			fmt.Fprintf(&g.buf, "type %s int64\n\n", name)
+			return
+		}
+		g.namedValues(t, name)
+	default:
+		fmt.Fprintf(&g.buf, "type %s %s\n\n", name, g.builtinType(t, name, doc))
+	}
+}
+
+// field writes the struct field for the component c of t, which is
+// declared as the Go type parent.
+func (g *goGenerator) field(t *Type, c *Component, field, parent string) {
+	ct := c.Type
+	typ := g.goType(ct, parent+goName(c.Name), "is the type of the "+c.Name+" component of "+parent+".")
+	list := strings.HasPrefix(typ, "[]")
+	opts := []string{c.Name}
+	if t.Kind == KindChoice {
+		opts = append(opts, "choice")
+	}
+	opts = append(opts, typeOptions(ct)...)
+
+	// The tags of a reference to a type of the module are those in
+	// front of the type's own.
+	tags := ct.Tags
+	if ct.Name != "" {
+		if mt := g.m.Type(ct.Name); mt != nil && len(mt.Tags) <= len(tags) {
+			tags = tags[:len(tags)-len(mt.Tags)]
+		}
+	}
+	if len(tags) > 0 {
+		tt := tags[0]
+		opts = append(opts, "tag:"+strconv.Itoa(tt.Number))
+		switch tt.Class {
+		case ClassApplication:
+			opts = append(opts, "application")
+		case ClassPrivate:
+			opts = append(opts, "private")
+		}
+		if tt.Explicit {
+			opts = append(opts, "explicit")
+		}
+	}
+	if c.Optional || c.Extension || t.Kind == KindChoice {
+		if list {
+			if t.Kind != KindChoice {
+				opts = append(opts, "omitempty")
+			}
+		} else {
+			typ = "*" + typ
+		}
+	}
+	if c.Default != nil && list {
+		opts = append(opts, "omitempty")
+	}
+	fmt.Fprintf(&g.buf, "\t%s %s `asn1:%s`\n", field, typ, strconv.Quote(strings.Join(opts, ",")))
+}
+
+// typeOptions returns the tag options that select the ASN.1 type of t
+// among those of its Go type.
+func typeOptions(t *Type) []string {
+	var opts []string
+	for t.Kind == KindSequenceOf || t.Kind == KindSetOf {
+		if t.Kind == KindSetOf {
+			opts = append(opts, "set")
+		}
+		t = t.Elem
+	}
+	switch t.Kind {
+	case KindNumericString:
+		opts = append(opts, "numeric")
+	case KindPrintableString:
+		opts = append(opts, "printable")
+	case KindIA5String:
+		opts = append(opts, "ia5")
+	case KindVisibleString:
+		opts = append(opts, "visible")
+	case KindUTCTime:
+		opts = append(opts, "utc")
+	case KindGeneralizedTime:
+		opts = append(opts, "generalized")
+	case KindRelativeOIDIRI:
+		opts = append(opts, "relative")
+	case KindEnumerated:
+		opts = append(opts, "enumerated")
+	case KindBitString:
+		if len(t.Named) > 0 {
+			bits := make([]string, len(t.Named))
+			for i, n := range t.Named {
+				bits[i] = n.Name + "=" + strconv.FormatInt(n.Value, 10)
+			}
+			opts = append(opts, "named:"+strings.Join(bits, "|"))
+		}
+	case KindSet:
+		if len(opts) == 0 {
+			opts = append(opts, "set")
+		}
+	}
+	return opts
+}
+
+// goType returns the Go type for values of t. Types that need a
+// declaration of their own are queued to be declared as hint, with the doc
+// comment doc.
+func (g *goGenerator) goType(t *Type, hint, doc string) string {
+	if t.Name != "" {
+		if mt := g.m.Type(t.Name); mt != nil {
+			return g.decl[mt]
+		}
+		if t.Kind == KindInvalid {
+			return goName(t.Name)
+		}
+	}
+	return g.builtinType(t, hint, doc)
+}
+
+// builtinType is goType for t itself, rather than for the type of the
+// module it is named after.
+func (g *goGenerator) builtinType(t *Type, hint, doc string) string {
+	switch t.Kind {
+	case KindBoolean:
+		return "bool"
+	case KindInteger:
+		if len(t.Named) == 0 {
+			return "int64"
+		}
+	case KindReal:
+		return "float64"
+	case KindNull:
+		return "struct{}"
+	case KindBitString:
+		g.asn1go = true
+		return "asn1go.BitString"
+	case KindObjectIdentifier:
+		g.asn1go = true
+		return "asn1go.ObjectIdentifier"
+	case KindRelativeOID:
+		g.asn1go = true
+		return "asn1go.RelativeOID"
+	case KindOIDIRI, KindRelativeOIDIRI:
+		g.asn1go = true
+		return "asn1go.OIDIRI"
+	case KindOctetString, KindAny:
+		return "[]byte"
+	case KindUTF8String, KindNumericString, KindPrintableString, KindIA5String, KindVisibleString,
+		KindUTCTime, KindGeneralizedTime, KindTime:
+		return "string"
+	case KindDate:
+		g.asn1go = true
+		return "asn1go.Date"
+	case KindTimeOfDay:
+		g.asn1go = true
+		return "asn1go.TimeOfDay"
+	case KindDateTime:
+		g.asn1go = true
+		return "asn1go.DateTime"
+	case KindDuration:
+		g.asn1go = true
+		return "asn1go.Duration"
+	case KindSequenceOf, KindSetOf:
+		return "[]" + g.goType(t.Elem, hint+"Item", "is the type of the elements of "+hint+".")
+	case KindInvalid:
+		return "struct{}"
+	}
+	name := g.unique(hint)
+	g.queue = append(g.queue, goDecl{t, name, doc})
+	return name
+}
+
+// namedValues writes the declaration of the Go type name for the
+// ENUMERATED or INTEGER type t with named values, with its constants and
+// methods.
+func (g *goGenerator) namedValues(t *Type, name string) {
+	g.enums = true
+	fmt.Fprintf(&g.buf, "type %s int64\n\n", name)
+	consts := make([]string, len(t.Named))
+	fmt.Fprintf(&g.buf, "// Values of %s.\nconst (\n", name)
+	for i, n := range t.Named {
+		consts[i] = g.unique(name + goName(n.Name))
+		fmt.Fprintf(&g.buf, "\t%s %s = %d\n", consts[i], name, n.Value)
+	}
+	g.buf.WriteString(")\n\n")
+
+	fmt.Fprintf(&g.buf, "// String returns the identifier of v, or its number if it has none.\n")
+	fmt.Fprintf(&g.buf, "func (v %s) String() string {\n\tswitch v {\n", name)
+	for i, n := range t.Named {
+		fmt.Fprintf(&g.buf, "\tcase %s:\n\t\treturn %q\n", consts[i], n.Name)
+	}
+	g.buf.WriteString("\t}\n\treturn strconv.FormatInt(int64(v), 10)\n}\n\n")
+
+	fmt.Fprintf(&g.buf, "// MarshalASN1 writes v as its identifier.\n")
+	fmt.Fprintf(&g.buf, "func (v %s) MarshalASN1() ([]byte, error) {\n\treturn []byte(v.String()), nil\n}\n\n", name)
+
+	fmt.Fprintf(&g.buf, "// UnmarshalASN1 reads v from its identifier or its number.\n")
+	fmt.Fprintf(&g.buf, "func (v *%s) UnmarshalASN1(b []byte) error {\n\tswitch string(b) {\n", name)
+	for i, n := range t.Named {
+		fmt.Fprintf(&g.buf, "\tcase %q:\n\t\t*v = %s\n", n.Name, consts[i])
+	}
+	fmt.Fprintf(&g.buf, "\tdefault:\n\t\tn, err := strconv.ParseInt(string(b), 10, 64)\n\t\tif err != nil {\n")
+	fmt.Fprintf(&g.buf, "\t\t\treturn fmt.Errorf(\"invalid %s value %%s\", b)\n\t\t}\n\t\t*v = %s(n)\n\t}\n\treturn nil\n}\n\n", name, name)
+}
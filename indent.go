@@ -0,0 +1,216 @@
+package asn1go
+
+import "bytes"
+
+// Compact appends to dst the value notation-encoded src with insignificant
+// space characters and comments elided. Single spaces are kept only where
+// they separate two tokens, as in { a 1,b { 2 23 143 } }, which compacts
+// to {a 1,b{2 23 143}}. Several top-level values are separated by
+// newlines.
+func Compact(dst *bytes.Buffer, src []byte) error {
+	return reformat(dst, src, false, "", "")
+}
+
+// Indent appends to dst an indented form of the value notation-encoded
+// src, in the layout of MarshalIndent. Each component and element of a
+// brace-delimited value begins on a new indented line beginning with
+// prefix followed by one or more copies of indent according to the
+// nesting depth, and closing braces go on lines of their own. Object
+// identifiers and empty values stay on one line. The data appended to dst
+// does not begin with the prefix nor any indentation, to make it easier
+// to embed inside other formatted value notation. Comments are dropped.
+func Indent(dst *bytes.Buffer, src []byte, prefix, indent string) error {
+	return reformat(dst, src, true, prefix, indent)
+}
+
+// reformat implements Compact and Indent.
+func reformat(dst *bytes.Buffer, src []byte, indented bool, prefix, indent string) error {
+	origLen := dst.Len()
+	scan := newScanner()
+	defer freeScanner(scan)
+
+	var oids map[int]bool
+	if indented {
+		var err error
+		if oids, err = objectIdentifierBraces(src, scan); err != nil {
+			return err
+		}
+		scan.bytes = 0
+		scan.reset()
+	}
+
+	newline := func(depth int) {
+		dst.WriteByte('\n')
+		dst.WriteString(prefix)
+		for i := 0; i < depth; i++ {
+			dst.WriteString(indent)
+		}
+	}
+
+	var (
+		last   byte   // last byte written for the current top-level value, or 0
+		space  bool   // space or comment since the last byte written
+		opened bool   // just wrote the '{' of a brace-delimited value
+		depth  int    // nesting depth of indented braces
+		header bool   // in the type of a value assignment
+		assign bool   // just wrote the "::=" of a value assignment
+		braces []bool // open braces, true for object identifiers
+		quoted bool   // between the quotes of a bstring or hstring
+	)
+	for i, c := range src {
+		scan.bytes++
+		op := scan.step(scan, c)
+		if op == scanEnd {
+			// c begins the next top-level value.
+			scan.restart()
+			op = scan.step(scan, c)
+			newline(0)
+			last, space = 0, false
+		}
+		switch op {
+		case scanError:
+			dst.Truncate(origLen)
+			return scan.err
+		case scanSkipSpace:
+			space = true
+			continue
+		case scanObjectValue:
+			dst.WriteByte(',')
+			last, space = ',', false
+			if indented {
+				newline(depth)
+				last = '\n'
+			}
+			continue
+		case scanEndObject:
+			oid := braces[len(braces)-1]
+			braces = braces[:len(braces)-1]
+			switch {
+			case !indented:
+			case oid || opened:
+				dst.WriteByte(' ')
+			default:
+				newline(depth - 1)
+			}
+			if indented && !oid {
+				depth--
+			}
+			dst.WriteByte('}')
+			last, space, opened = '}', false, false
+			continue
+		case scanChoiceTag:
+			// The space after the colon is written before the value,
+			// and only once however much white space the source has.
+			if indented {
+				dst.WriteString(" :")
+			} else {
+				dst.WriteByte(':')
+			}
+			last, space = ':', indented
+			continue
+		}
+
+		if quoted {
+			// The digits of a bstring or hstring are written without
+			// the white space that may break them over several lines.
+			if c == '\'' {
+				quoted = false
+			}
+			if !isSpace(c) {
+				dst.WriteByte(c)
+				last = c
+			}
+			continue
+		}
+		quoted = op == scanBeginLiteral && c == '\''
+
+		// c is part of a token; separate it from the previous one.
+		colon := header && c == ':' && last != ':'
+		switch op {
+		case scanBeginTypeReference:
+			header = true
+		case scanAssignment:
+			header = false
+		}
+		switch {
+		case last == 0 || last == '\n':
+		case opened:
+			newline(depth)
+			opened = false
+		case indented && (space || assign || colon):
+			dst.WriteByte(' ')
+		case space && needsSpace(last, c):
+			dst.WriteByte(' ')
+		}
+		if op == scanBeginLiteral && scan.minus && isDigit(c) {
+			// The minus sign before a number was reported as space.
+			dst.WriteByte('-')
+		}
+		dst.WriteByte(c)
+		last, space, assign = c, false, op == scanAssignment
+
+		if op == scanBeginObject {
+			oid := oids[i]
+			braces = append(braces, oid)
+			if oid {
+				// The components of an object identifier stay on the
+				// line of its opening brace.
+				space = true
+			} else if indented {
+				depth++
+				opened = true
+			}
+		}
+	}
+	if scan.eof() == scanError {
+		dst.Truncate(origLen)
+		return scan.err
+	}
+	return nil
+}
+
+// needsSpace reports whether a token ending in the byte last must be
+// separated by a space from a token beginning with c in compact value
+// notation.
+func needsSpace(last, c byte) bool {
+	switch last {
+	case '{', ',', ':', '=':
+		return false
+	}
+	switch c {
+	case '{', '}', ',', ':':
+		return false
+	}
+	return true
+}
+
+// objectIdentifierBraces returns the offsets in src of the opening braces of
+// OBJECT IDENTIFIER values.
+func objectIdentifierBraces(src []byte, scan *scanner) (map[int]bool, error) {
+	scan.reset()
+	oids := make(map[int]bool)
+	var open []int
+	for i, c := range src {
+		scan.bytes++
+		op := scan.step(scan, c)
+		if op == scanEnd {
+			scan.restart()
+			op = scan.step(scan, c)
+		}
+		switch op {
+		case scanError:
+			return nil, scan.err
+		case scanBeginObject:
+			open = append(open, i)
+		case scanEndObject:
+			open = open[:len(open)-1]
+		}
+		if n := len(scan.parseState); n > 0 && scan.parseState[n-1] == parseObjectIdentifier {
+			oids[open[len(open)-1]] = true
+		}
+	}
+	if scan.eof() == scanError {
+		return nil, scan.err
+	}
+	return oids, nil
+}
@@ -0,0 +1,65 @@
+package asn1go
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestIndent(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"sequence", "v T ::= { a 1, b TRUE }", "v T ::= {\n  a 1,\n  b TRUE\n}"},
+		{"empty", "v T ::= {}", "v T ::= { }"},
+		{"object identifier", "v OBJECT IDENTIFIER ::= {2 23 143}", "v OBJECT IDENTIFIER ::= { 2 23 143 }"},
+		{"choice", "v T ::= alt : 5", "v T ::= alt : 5"},
+		{"choice without spaces", "v T ::= alt:5", "v T ::= alt : 5"},
+		{"choice with spaces", "v T ::= alt  :\n  5", "v T ::= alt : 5"},
+		{"choice of sequence", "v T ::= header : { major 2 }", "v T ::= header : {\n  major 2\n}"},
+		{"nested choice", "v T ::= { a b : -1 }", "v T ::= {\n  a b : -1\n}"},
+		{"hstring", "v T ::= { a '0A 1B'H }", "v T ::= {\n  a '0A1B'H\n}"},
+		{"cstring", "v T ::= { a \"x=y\" }", "v T ::= {\n  a \"x=y\"\n}"},
+		{"several values", "a T ::= 1 b T ::= x : 2", "a T ::= 1\nb T ::= x : 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Indent(&buf, []byte(tt.in), "", "  "); err != nil {
+				t.Fatalf("Indent(%q): %v", tt.in, err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Indent(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompact(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"{ a 1, b { 2 23 143 } }", "{a 1,b{2 23 143}}"},
+		{"alt : 5", "alt:5"},
+		{"{ a b : { c -- comment\n 1 } }", "{a b:{c 1}}"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := Compact(&buf, []byte(tt.in)); err != nil {
+			t.Fatalf("Compact(%q): %v", tt.in, err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("Compact(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestIndentError(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("kept")
+	if err := Indent(&buf, []byte("v T ::= { a 1,"), "", "  "); err == nil {
+		t.Fatal("Indent of a truncated value: no error")
+	}
+	if buf.String() != "kept" {
+		t.Errorf("Indent left %q in dst after an error, want %q", buf.String(), "kept")
+	}
+}
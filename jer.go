@@ -0,0 +1,695 @@
+package asn1go
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// EncodeJER returns the JER encoding of v as a value of the ASN.1 type t,
+// the JSON form of X.697. It takes the same values as EncodeDER, except
+// that the encoding a value of an open type holds is written as a string
+// of hexadecimal digits.
+//
+// BOOLEAN, INTEGER and REAL values are JSON literals and numbers, with the
+// strings "INF", "-INF", "NaN" and "-0" for the special REAL values.
+// ENUMERATED values are their identifiers, and character strings, OID-IRI
+// values and OBJECT IDENTIFIER and RELATIVE-OID values, such as "1.2.840"
+// and "4.3.2", are strings. OCTET STRING
+// values are strings of hexadecimal digits; so are BIT STRING values of a
+// fixed size, and others are objects such as {"value":"A0","length":4}.
+// SEQUENCE and SET values are objects with a member per component present,
+// CHOICE values are objects with a single member for the alternative and
+// SEQUENCE OF and SET OF values are arrays. NULL is null.
+func EncodeJER(t *Type, v interface{}) ([]byte, error) {
+	var e jerEncoder
+	return e.marshal(t, reflect.ValueOf(v))
+}
+
+// MarshalJER returns the JER encoding of v, as a value of the ASN.1 type
+// TypeOf derives from the type of v.
+func MarshalJER(v interface{}) ([]byte, error) {
+	t, err := TypeOf(v)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeJER(t, v)
+}
+
+// DecodeJER parses data as the JER encoding of a value of the ASN.1 type t
+// and stores the result in the value pointed to by v, which must be a
+// non-nil pointer. It stores values as DecodeDER does, with the encoding
+// a value of an open type holds in a []byte.
+//
+// Malformed JSON and JSON that does not match t are reported as a
+// JERSyntaxError. If a value is not appropriate for a given target type,
+// DecodeJER skips that value and completes the decoding as best it can,
+// and then returns an UnmarshalTypeError describing the earliest such
+// error.
+func DecodeJER(t *Type, data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+	d := jerDecoder{dec: json.NewDecoder(bytes.NewReader(data))}
+	d.dec.UseNumber()
+	return d.unmarshal(t, rv)
+}
+
+// UnmarshalJER parses the JER encoded data and stores the result in the
+// value pointed to by v, which must be a non-nil pointer. The ASN.1 type
+// of the encoding is read from the Go type of v as TypeOf describes.
+func UnmarshalJER(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+	t, err := TypeOf(v)
+	if err != nil {
+		return err
+	}
+	return DecodeJER(t, data, v)
+}
+
+// A JERSyntaxError describes malformed JER input, or JSON that is not a
+// value of the ASN.1 type it is decoded as.
+type JERSyntaxError struct {
+	Msg    string
+	Offset int64 // error occurred after reading Offset bytes
+}
+
+func (e *JERSyntaxError) Error() string {
+	return "asn1go: JER syntax error at offset " + strconv.FormatInt(e.Offset, 10) + ": " + e.Msg
+}
+
+// A jerEncoder encodes values in JER.
+type jerEncoder struct {
+	binaryEncoder
+	buf []byte
+}
+
+func (e *jerEncoder) marshal(t *Type, v reflect.Value) (b []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if je, ok := r.(asn1Error); ok {
+				err = je.error
+			} else {
+				panic(r)
+			}
+		}
+	}()
+	e.value(t, v)
+	return e.buf, nil
+}
+
+// value writes the encoding of v as a value of type t.
+func (e *jerEncoder) value(t *Type, v reflect.Value) {
+	v = derIndirect(v)
+	switch t.Kind {
+	case KindBoolean:
+		if v.Kind() != reflect.Bool {
+			e.mismatch(t, v)
+		}
+		e.buf = strconv.AppendBool(e.buf, v.Bool())
+
+	case KindInteger:
+		if v.Kind() == reflect.Struct && v.Type() == bigIntType {
+			e.buf = bigIntOf(v).Append(e.buf, 10)
+			break
+		}
+		e.buf = strconv.AppendInt(e.buf, e.integerOf(t, v), 10)
+
+	case KindEnumerated:
+		name, ok := t.nameOf(e.integerOf(t, v))
+		if !ok {
+			e.error(t, "unknown value %v", v)
+		}
+		e.buf = appendJSONString(e.buf, name)
+
+	case KindReal:
+		switch f := e.realOf(t, v); {
+		case math.IsInf(f, 1):
+			e.buf = append(e.buf, `"INF"`...)
+		case math.IsInf(f, -1):
+			e.buf = append(e.buf, `"-INF"`...)
+		case math.IsNaN(f):
+			e.buf = append(e.buf, `"NaN"`...)
+		case f == 0 && math.Signbit(f):
+			e.buf = append(e.buf, `"-0"`...)
+		default:
+			e.buf = strconv.AppendFloat(e.buf, f, 'g', -1, 64)
+		}
+
+	case KindBitString:
+		bs := e.bitStringOf(t, v)
+		b := appendBitString(nil, bs)[1:]
+		if n, ok := fixedSize(t); ok {
+			if bs.BitLength != n {
+				e.error(t, "size %d out of range %v", bs.BitLength, t.Size)
+			}
+			e.hex(b)
+			break
+		}
+		e.buf = append(e.buf, `{"value":`...)
+		e.hex(b)
+		e.buf = append(e.buf, `,"length":`...)
+		e.buf = strconv.AppendInt(e.buf, int64(bs.BitLength), 10)
+		e.buf = append(e.buf, '}')
+
+	case KindOctetString:
+		b, ok := bytesOf(v)
+		if !ok {
+			e.mismatch(t, v)
+		}
+		e.hex(b)
+
+	case KindNull:
+		e.buf = append(e.buf, "null"...)
+
+	case KindObjectIdentifier:
+		oid, ok := objectIdentifierOf(v)
+		if !ok {
+			e.mismatch(t, v)
+		}
+		if _, err := appendOID(nil, oid); err != nil {
+			e.error(t, "%v", err)
+		}
+		e.buf = append(e.buf, '"')
+		for i, c := range oid {
+			if i > 0 {
+				e.buf = append(e.buf, '.')
+			}
+			e.buf = strconv.AppendInt(e.buf, int64(c), 10)
+		}
+		e.buf = append(e.buf, '"')
+
+	case KindRelativeOID:
+		oid, ok := objectIdentifierOf(v)
+		if !ok {
+			e.mismatch(t, v)
+		}
+		e.buf = appendJSONString(e.buf, oid.String())
+
+	case KindUTF8String, KindNumericString, KindPrintableString, KindIA5String, KindVisibleString,
+		KindUTCTime, KindGeneralizedTime, KindOIDIRI, KindRelativeOIDIRI,
+		KindTime, KindDate, KindTimeOfDay, KindDateTime, KindDuration:
+		e.buf = appendJSONString(e.buf, e.stringOf(t, v))
+
+	case KindSequence, KindSet:
+		e.components(t, v)
+
+	case KindSequenceOf, KindSetOf:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			e.mismatch(t, v)
+		}
+		e.buf = append(e.buf, '[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				e.buf = append(e.buf, ',')
+			}
+			e.path = append(e.path, strconv.Itoa(i))
+			e.value(t.Elem, v.Index(i))
+			e.path = e.path[:len(e.path)-1]
+		}
+		e.buf = append(e.buf, ']')
+
+	case KindChoice:
+		name, av, ok := choiceOf(v)
+		if !ok {
+			e.mismatch(t, v)
+		}
+		c := t.Component(name)
+		if c == nil {
+			e.error(t, "unknown alternative %s", name)
+		}
+		e.buf = append(e.buf, '{')
+		e.buf = appendJSONString(e.buf, name)
+		e.buf = append(e.buf, ':')
+		e.path = append(e.path, name)
+		e.value(c.Type, av)
+		e.path = e.path[:len(e.path)-1]
+		e.buf = append(e.buf, '}')
+
+	case KindAny:
+		raw, ok := bytesOf(v)
+		if !ok || v.Kind() == reflect.String {
+			e.error(t, "open type value must be an encoding")
+		}
+		e.hex(raw)
+
+	default:
+		e.error(t, "unsupported type")
+	}
+}
+
+// hex writes b as a string of uppercase hexadecimal digits.
+func (e *jerEncoder) hex(b []byte) {
+	e.buf = append(e.buf, '"')
+	e.buf = append(e.buf, strings.ToUpper(hex.EncodeToString(b))...)
+	e.buf = append(e.buf, '"')
+}
+
+// components writes the SEQUENCE or SET value v as an object with a
+// member for each component present, in the order of the components.
+func (e *jerEncoder) components(t *Type, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			e.mismatch(t, v)
+		}
+		for _, k := range v.MapKeys() {
+			if t.Component(k.String()) == nil && !(t.Extensible && k.String() == "...") {
+				e.error(t, "unknown component %s", k.String())
+			}
+		}
+	default:
+		e.mismatch(t, v)
+	}
+
+	e.buf = append(e.buf, '{')
+	n := 0
+	for i := range t.Components {
+		c := &t.Components[i]
+		cv, ok := componentValue(v, c.Name)
+		if !ok {
+			if c.Optional || c.Default != nil || c.Extension {
+				continue
+			}
+			e.error(t, "missing component %s", c.Name)
+		}
+		if c.Default != nil && equalValue(c.Type, cv, reflect.ValueOf(c.Default)) {
+			continue
+		}
+		if n > 0 {
+			e.buf = append(e.buf, ',')
+		}
+		n++
+		e.buf = appendJSONString(e.buf, c.Name)
+		e.buf = append(e.buf, ':')
+		e.path = append(e.path, c.Name)
+		e.value(c.Type, cv)
+		e.path = e.path[:len(e.path)-1]
+	}
+	e.buf = append(e.buf, '}')
+}
+
+// appendJSONString appends s to dst as a JSON string.
+func appendJSONString(dst []byte, s string) []byte {
+	const hexDigits = "0123456789abcdef"
+	dst = append(dst, '"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			dst = append(dst, '\\', byte(r))
+		case r == '\n':
+			dst = append(dst, `\n`...)
+		case r == '\r':
+			dst = append(dst, `\r`...)
+		case r == '\t':
+			dst = append(dst, `\t`...)
+		case r < 0x20:
+			dst = append(dst, '\\', 'u', '0', '0', hexDigits[r>>4], hexDigits[r&0xf])
+		default:
+			dst = utf8.AppendRune(dst, r)
+		}
+	}
+	return append(dst, '"')
+}
+
+// A jerDecoder decodes JER encodings.
+type jerDecoder struct {
+	storer
+	dec *json.Decoder
+	off int // offset of the token last read
+}
+
+func (d *jerDecoder) unmarshal(t *Type, v reflect.Value) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if je, ok := r.(asn1Error); ok {
+				err = je.error
+			} else {
+				panic(r)
+			}
+		}
+	}()
+	d.value(t, v)
+	if _, err := d.dec.Token(); err != io.EOF {
+		d.off = int(d.dec.InputOffset())
+		d.syntaxError("trailing data after top-level value")
+	}
+	return d.savedError
+}
+
+// syntaxError aborts the decoding with a JERSyntaxError at the token last
+// read.
+func (d *jerDecoder) syntaxError(format string, args ...interface{}) {
+	panic(asn1Error{&JERSyntaxError{fmt.Sprintf(format, args...), int64(d.off)}})
+}
+
+// token reads the next JSON token.
+func (d *jerDecoder) token() json.Token {
+	d.off = int(d.dec.InputOffset())
+	tok, err := d.dec.Token()
+	if err != nil {
+		var se *json.SyntaxError
+		switch {
+		case err == io.EOF:
+			d.syntaxError("unexpected end of JSON input")
+		case errors.As(err, &se):
+			d.off = int(se.Offset)
+		}
+		d.syntaxError("%v", err)
+	}
+	return tok
+}
+
+// skip reads the next JSON value and discards it.
+func (d *jerDecoder) skip() {
+	depth := 0
+	for {
+		switch d.token() {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return
+		}
+	}
+}
+
+// tokenError aborts the decoding because tok is not a value of type t.
+func (d *jerDecoder) tokenError(t *Type, tok json.Token) {
+	switch tok := tok.(type) {
+	case json.Delim:
+		d.syntaxError("unexpected %v for %v", tok, t)
+	case nil:
+		d.syntaxError("unexpected null for %v", t)
+	case string:
+		d.syntaxError("unexpected string %q for %v", tok, t)
+	}
+	d.syntaxError("unexpected %v for %v", tok, t)
+}
+
+// delim reads the JSON delimiter want.
+func (d *jerDecoder) delim(t *Type, want json.Delim) {
+	if tok := d.token(); tok != want {
+		d.tokenError(t, tok)
+	}
+}
+
+// str reads a JSON string of a value of type t.
+func (d *jerDecoder) str(t *Type) string {
+	tok := d.token()
+	s, ok := tok.(string)
+	if !ok {
+		d.tokenError(t, tok)
+	}
+	return s
+}
+
+// hex reads a string of hexadecimal digits of a value of type t.
+func (d *jerDecoder) hex(t *Type) []byte {
+	b, err := hex.DecodeString(d.str(t))
+	if err != nil {
+		d.syntaxError("invalid hexadecimal string for %v", t)
+	}
+	return b
+}
+
+// value decodes a value of type t into v.
+func (d *jerDecoder) value(t *Type, v reflect.Value) {
+	v = target(v)
+	if v.IsValid() && v.Type() == valueType {
+		d.dynamic(t, d.off, v, func(v reflect.Value) { d.value(t, v) })
+		return
+	}
+	switch t.Kind {
+	case KindBoolean:
+		tok := d.token()
+		b, ok := tok.(bool)
+		if !ok {
+			d.tokenError(t, tok)
+		}
+		d.store(t, d.off, b, v)
+
+	case KindInteger:
+		tok := d.token()
+		num, ok := tok.(json.Number)
+		if !ok {
+			d.tokenError(t, tok)
+		}
+		if n, err := strconv.ParseInt(string(num), 10, 64); err == nil {
+			d.store(t, d.off, n, v)
+		} else if u, err := strconv.ParseUint(string(num), 10, 64); err == nil {
+			d.store(t, d.off, u, v)
+		} else if b, ok := new(big.Int).SetString(string(num), 10); ok {
+			d.store(t, d.off, b, v)
+		} else {
+			d.syntaxError("invalid INTEGER %s", num)
+		}
+
+	case KindEnumerated:
+		s := d.str(t)
+		n, ok := t.NamedValue(s)
+		if !ok {
+			d.syntaxError("unknown value %s of %v", s, t)
+		}
+		d.store(t, d.off, n, v)
+
+	case KindReal:
+		var f float64
+		switch tok := d.token().(type) {
+		case json.Number:
+			var err error
+			if f, err = strconv.ParseFloat(string(tok), 64); err != nil {
+				d.syntaxError("invalid REAL %s", tok)
+			}
+		case string:
+			switch tok {
+			case "INF":
+				f = math.Inf(1)
+			case "-INF":
+				f = math.Inf(-1)
+			case "NaN":
+				f = math.NaN()
+			case "-0":
+				f = math.Copysign(0, -1)
+			default:
+				d.syntaxError("invalid REAL %q", tok)
+			}
+		default:
+			d.tokenError(t, tok)
+		}
+		d.store(t, d.off, f, v)
+
+	case KindBitString:
+		d.store(t, d.off, d.bitString(t), v)
+
+	case KindOctetString:
+		b := d.hex(t)
+		d.store(t, d.off, b, v)
+
+	case KindNull:
+		if tok := d.token(); tok != nil {
+			d.tokenError(t, tok)
+		}
+		d.store(t, d.off, nil, v)
+
+	case KindObjectIdentifier:
+		s := d.str(t)
+		oid, ok := parseDottedOID(s)
+		if !ok {
+			d.syntaxError("invalid OBJECT IDENTIFIER %q", s)
+		}
+		d.store(t, d.off, oid, v)
+
+	case KindRelativeOID:
+		s := d.str(t)
+		oid, ok := parseDottedRelativeOID(s)
+		if !ok {
+			d.syntaxError("invalid RELATIVE-OID %q", s)
+		}
+		d.store(t, d.off, oid, v)
+
+	case KindUTF8String, KindNumericString, KindPrintableString, KindIA5String, KindVisibleString,
+		KindUTCTime, KindGeneralizedTime, KindOIDIRI, KindRelativeOIDIRI,
+		KindTime, KindDate, KindTimeOfDay, KindDateTime, KindDuration:
+		s := d.str(t)
+		if !validString(t.Kind, s) {
+			d.syntaxError("invalid character in %v", t.Kind)
+		}
+		d.store(t, d.off, s, v)
+
+	case KindSequence, KindSet:
+		d.components(t, v)
+
+	case KindSequenceOf, KindSetOf:
+		d.delim(t, '[')
+		l := d.list(t, d.off, v)
+		for d.dec.More() {
+			d.value(t.Elem, l.next())
+		}
+		d.delim(t, ']')
+		l.finish()
+
+	case KindChoice:
+		d.delim(t, '{')
+		off := d.off
+		if !d.dec.More() {
+			d.syntaxError("missing alternative of %v", t)
+		}
+		name := d.str(t)
+		c := t.Component(name)
+		if c == nil {
+			d.syntaxError("unknown alternative %s of %v", name, t)
+		}
+		d.storeChoice(t, c, off, v, func(v reflect.Value) { d.value(c.Type, v) })
+		if d.dec.More() {
+			d.syntaxError("more than one alternative of %v", t)
+		}
+		d.delim(t, '}')
+
+	case KindAny:
+		b := d.hex(t)
+		d.store(t, d.off, b, v)
+
+	default:
+		d.syntaxError("unsupported type %v", t)
+	}
+}
+
+// bitString reads a BIT STRING value of type t: a string of hexadecimal
+// digits if the type has a fixed size, and an object with the members
+// "value" and "length" otherwise.
+func (d *jerDecoder) bitString(t *Type) BitString {
+	if n, ok := fixedSize(t); ok {
+		b := d.hex(t)
+		if len(b) != (n+7)/8 {
+			d.syntaxError("BIT STRING of %d octets, want %d", len(b), (n+7)/8)
+		}
+		return BitString{Bytes: b, BitLength: n}
+	}
+
+	d.delim(t, '{')
+	var bs BitString
+	haveValue, haveLength := false, false
+	for d.dec.More() {
+		switch key := d.str(t); {
+		case key == "value" && !haveValue:
+			bs.Bytes, haveValue = d.hex(t), true
+		case key == "length" && !haveLength:
+			tok := d.token()
+			num, ok := tok.(json.Number)
+			if !ok {
+				d.tokenError(t, tok)
+			}
+			n, err := strconv.Atoi(string(num))
+			if err != nil || n < 0 {
+				d.syntaxError("invalid BIT STRING length %s", num)
+			}
+			bs.BitLength, haveLength = n, true
+		default:
+			d.syntaxError("unexpected member %q of BIT STRING", key)
+		}
+	}
+	if !haveValue || !haveLength {
+		d.syntaxError("BIT STRING without value or length")
+	}
+	if len(bs.Bytes) != (bs.BitLength+7)/8 {
+		d.syntaxError("BIT STRING of %d octets for %d bits", len(bs.Bytes), bs.BitLength)
+	}
+	d.delim(t, '}')
+	if unused := 8*len(bs.Bytes) - bs.BitLength; unused > 0 {
+		bs.Bytes[len(bs.Bytes)-1] &^= 1<<uint(unused) - 1
+	}
+	return bs
+}
+
+// components decodes the SEQUENCE or SET object of type t into the struct
+// or map v.
+func (d *jerDecoder) components(t *Type, v reflect.Value) {
+	d.delim(t, '{')
+	off := d.off
+	ct := d.composite(t, off, v)
+	found := make([]bool, len(t.Components))
+	for d.dec.More() {
+		name := d.str(t)
+		c := t.Component(name)
+		if c == nil && t.Extensible {
+			// An extension addition that t does not know.
+			d.skip()
+			continue
+		}
+		if c == nil {
+			d.syntaxError("unknown component %s of %v", name, t)
+		}
+		i := c.index(t)
+		if found[i] {
+			d.syntaxError("duplicate component %s of %v", name, t)
+		}
+		found[i] = true
+		d.component(ct, c, d.off, func(v reflect.Value) { d.value(c.Type, v) })
+	}
+	d.delim(t, '}')
+	for i := range t.Components {
+		if c := &t.Components[i]; !found[i] && !c.Optional && c.Default == nil && !c.Extension {
+			d.syntaxError("missing component %s of %v", c.Name, t)
+		}
+	}
+	d.finish(ct, t, off, found)
+}
+
+// index returns the index of the component c among the components of t.
+func (c *Component) index(t *Type) int {
+	for i := range t.Components {
+		if &t.Components[i] == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseDottedOID parses an OBJECT IDENTIFIER value in the dotted form
+// JER uses, such as "1.2.840".
+func parseDottedOID(s string) (ObjectIdentifier, bool) {
+	arcs, ok := parseDottedRelativeOID(s)
+	if !ok {
+		return nil, false
+	}
+	oid := ObjectIdentifier(arcs)
+	if _, err := appendOID(nil, oid); err != nil {
+		return nil, false
+	}
+	return oid, true
+}
+
+// parseDottedRelativeOID parses a RELATIVE-OID value in the dotted form,
+// such as "4.3.2".
+func parseDottedRelativeOID(s string) (RelativeOID, bool) {
+	parts := strings.Split(s, ".")
+	oid := make(RelativeOID, len(parts))
+	for i, p := range parts {
+		if p == "" || len(p) > 1 && p[0] == '0' || strings.Trim(p, "0123456789") != "" {
+			return nil, false
+		}
+		n, err := strconv.Atoi(p)
+		if err != nil || n > math.MaxInt32 {
+			return nil, false
+		}
+		oid[i] = n
+	}
+	return oid, true
+}
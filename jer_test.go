@@ -0,0 +1,135 @@
+package asn1go
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestJER(t *testing.T) {
+	boolean := &Type{Kind: KindBoolean}
+	seq := &Type{Kind: KindSequence, Components: []Component{
+		{Name: "b", Type: boolean},
+		{Name: "i", Type: &Type{Kind: KindInteger}, Default: int64(3)},
+		{Name: "o", Type: &Type{Kind: KindOctetString}, Optional: true},
+	}}
+	choice := &Type{Kind: KindChoice, Components: []Component{
+		{Name: "x", Type: &Type{Kind: KindNull}},
+		{Name: "y", Type: boolean},
+	}}
+	tests := []struct {
+		t    *Type
+		v    interface{}
+		want string
+	}{
+		{boolean, true, `true`},
+		{&Type{Kind: KindInteger}, int64(-129), `-129`},
+		{&Type{Kind: KindEnumerated, Named: []NamedNumber{{"off", 0, false}, {"on", 1, false}}}, "on", `"on"`},
+		{&Type{Kind: KindReal}, 1.5, `1.5`},
+		{&Type{Kind: KindReal}, math.Inf(1), `"INF"`},
+		{&Type{Kind: KindReal}, math.Inf(-1), `"-INF"`},
+		{&Type{Kind: KindNull}, nil, `null`},
+		{&Type{Kind: KindOctetString}, []byte{0xDE, 0xAD}, `"DEAD"`},
+		{&Type{Kind: KindBitString}, BitString{Bytes: []byte{0xA0}, BitLength: 3}, `{"value":"A0","length":3}`},
+		{&Type{Kind: KindBitString, Size: &Range{Min: 4, Max: 4}}, BitString{Bytes: []byte{0xF0}, BitLength: 4}, `"F0"`},
+		{&Type{Kind: KindObjectIdentifier}, ObjectIdentifier{1, 2, 840}, `"1.2.840"`},
+		{&Type{Kind: KindUTF8String}, "h\"é\n", `"h\"é\n"`},
+		{&Type{Kind: KindSequenceOf, Elem: boolean}, []interface{}{true, false}, `[true,false]`},
+		{seq, map[string]interface{}{"b": true, "i": int64(3)}, `{"b":true}`},
+		{seq, map[string]interface{}{"b": false, "i": int64(4), "o": []byte{0xAB}}, `{"b":false,"i":4,"o":"AB"}`},
+		{choice, map[string]interface{}{"x": nil}, `{"x":null}`},
+		{choice, map[string]interface{}{"y": true}, `{"y":true}`},
+	}
+	for _, tt := range tests {
+		b, err := EncodeJER(tt.t, tt.v)
+		if err != nil {
+			t.Errorf("EncodeJER(%v, %v): %v", tt.t, tt.v, err)
+			continue
+		}
+		if string(b) != tt.want {
+			t.Errorf("EncodeJER(%v, %v) = %s, want %s", tt.t, tt.v, b, tt.want)
+		}
+		var got interface{}
+		if err := DecodeJER(tt.t, []byte(tt.want), &got); err != nil {
+			t.Errorf("DecodeJER(%v, %s): %v", tt.t, tt.want, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.v) {
+			t.Errorf("DecodeJER(%v, %s) = %#v, want %#v", tt.t, tt.want, got, tt.v)
+		}
+	}
+}
+
+func TestDecodeJERReal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{`0`, 0},
+		{`-2.5e-1`, -0.25},
+		{`"-0"`, math.Copysign(0, -1)},
+		{`"NaN"`, math.NaN()},
+	}
+	for _, tt := range tests {
+		var f float64
+		if err := DecodeJER(&Type{Kind: KindReal}, []byte(tt.in), &f); err != nil {
+			t.Errorf("DecodeJER(%s): %v", tt.in, err)
+			continue
+		}
+		if f != tt.want && !(math.IsNaN(f) && math.IsNaN(tt.want)) || math.Signbit(f) != math.Signbit(tt.want) {
+			t.Errorf("DecodeJER(%s) = %v, want %v", tt.in, f, tt.want)
+		}
+	}
+}
+
+func TestDecodeJERError(t *testing.T) {
+	seq := &Type{Kind: KindSequence, Components: []Component{
+		{Name: "b", Type: &Type{Kind: KindBoolean}},
+		{Name: "e", Type: &Type{Kind: KindEnumerated, Named: []NamedNumber{{"on", 1, false}}}, Optional: true},
+	}}
+	tests := []string{
+		`{}`,
+		`{"b":1}`,
+		`{"b":true,"e":"off"}`,
+		`[1]`,
+		`{"b":true,"zz":1}`,
+		`{"b":true} x`,
+		`{"b":tru`,
+	}
+	for _, in := range tests {
+		var v interface{}
+		err := DecodeJER(seq, []byte(in), &v)
+		if _, ok := err.(*JERSyntaxError); !ok {
+			t.Errorf("DecodeJER(%s): error %v, want JERSyntaxError", in, err)
+		}
+	}
+}
+
+func TestMarshalJER(t *testing.T) {
+	type record struct {
+		A int
+		B []string
+		C *bool `asn1:",omitempty"`
+	}
+	b, err := MarshalJER(record{A: 1, B: []string{"x"}})
+	if err != nil {
+		t.Fatalf("MarshalJER: %v", err)
+	}
+	if want := `{"a":1,"b":["x"]}`; string(b) != want {
+		t.Errorf("MarshalJER = %s, want %s", b, want)
+	}
+	var r record
+	if err := UnmarshalJER([]byte(`{"a": 7, "b": ["q", "r"], "c": false}`), &r); err != nil {
+		t.Fatalf("UnmarshalJER: %v", err)
+	}
+	if r.A != 7 || !reflect.DeepEqual(r.B, []string{"q", "r"}) || r.C == nil || *r.C {
+		t.Errorf("UnmarshalJER = %+v", r)
+	}
+	if _, ok := UnmarshalJER([]byte(`{"a": "x", "b": []}`), &r).(*JERSyntaxError); !ok {
+		t.Error("UnmarshalJER of a string as an INTEGER: want JERSyntaxError")
+	}
+	var i int8
+	if _, ok := UnmarshalJER([]byte(`300`), &i).(*UnmarshalTypeError); !ok {
+		t.Error("UnmarshalJER of 300 into an int8: want UnmarshalTypeError")
+	}
+}
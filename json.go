@@ -0,0 +1,482 @@
+package asn1go
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/openesim/asn1go/ast"
+)
+
+// ToJSON converts the ASN.1 value notation document data to plain JSON,
+// without a schema. A document of value assignments becomes a JSON object
+// with a member per value reference, in order, and a document of a single
+// value becomes that value; a document of several values becomes an
+// array. The values become:
+//
+//	SEQUENCE, SET     an object with a member per component, in order
+//	SEQUENCE OF, SET OF, { }
+//	                  an array
+//	CHOICE            an object with a single member for the alternative
+//	BOOLEAN, NULL     true, false and null
+//	INTEGER, REAL     a number, or the identifier of a special REAL value
+//	hstring           a string of hexadecimal digits, "4F3A"
+//	bstring           a string of binary digits, "0101"
+//	cstring           a string
+//	identifier        a string, such as the name of an ENUMERATED value
+//	OBJECT IDENTIFIER a string of the components separated by dots, "2.23.143"
+//
+// The type of each value assignment and the comments are dropped, and so
+// is the difference between the strings and between a SEQUENCE value of
+// one component and a CHOICE value; see FromJSON and JSONHintsOf to
+// convert the JSON back. A containing value, CONTAINING value, is written
+// as the value contained.
+func ToJSON(data []byte) ([]byte, error) {
+	as, err := ParseAssignments(data)
+	if err != nil {
+		return nil, err
+	}
+	unwrapContaining(as)
+	named, err := namedAssignments(as, "ToJSON")
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	switch {
+	case named:
+		buf.WriteByte('{')
+		for i, a := range as {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			jsonString(&buf, a.Name.Name)
+			buf.WriteByte(':')
+			nodeJSON(&buf, a.Value)
+		}
+		buf.WriteByte('}')
+	case len(as) == 1:
+		nodeJSON(&buf, as[0].Value)
+	default:
+		buf.WriteByte('[')
+		for i, a := range as {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			nodeJSON(&buf, a.Value)
+		}
+		buf.WriteByte(']')
+	}
+	return buf.Bytes(), nil
+}
+
+// namedAssignments reports whether the top-level values as are value
+// assignments rather than plain values, which fn does not mix.
+func namedAssignments(as []*ast.Assignment, fn string) (bool, error) {
+	named := as[0].Name != nil
+	for _, a := range as[1:] {
+		if (a.Name != nil) != named {
+			return false, errors.New("asn1go: " + fn + " of a document mixing value assignments and plain values")
+		}
+	}
+	return named, nil
+}
+
+// unwrapContaining replaces the containing values in the top-level values
+// as by the values contained, which the JSON, YAML and CBOR forms write in
+// their place.
+func unwrapContaining(as []*ast.Assignment) {
+	var unwrap func(n ast.Node) ast.Node
+	unwrap = func(n ast.Node) ast.Node {
+		for {
+			c, ok := n.(*ast.ContainingNode)
+			if !ok {
+				break
+			}
+			n = c.Value
+		}
+		switch n := n.(type) {
+		case *ast.ObjectNode:
+			for i, el := range n.Elements {
+				n.Elements[i] = unwrap(el)
+			}
+		case *ast.FieldNode:
+			n.Value = unwrap(n.Value)
+		case *ast.ChoiceNode:
+			n.Value = unwrap(n.Value)
+		}
+		return n
+	}
+	for _, a := range as {
+		a.Value = unwrap(a.Value)
+	}
+}
+
+// componentsOf returns the elements of obj if it is a SEQUENCE or SET
+// value, whose elements are all components. It reports false for an
+// empty value and for one with elements without identifiers, which
+// converts to a list.
+func componentsOf(obj *ast.ObjectNode) ([]*ast.FieldNode, bool) {
+	if len(obj.Elements) == 0 {
+		return nil, false
+	}
+	fields := make([]*ast.FieldNode, len(obj.Elements))
+	for i, el := range obj.Elements {
+		f, ok := el.(*ast.FieldNode)
+		if !ok {
+			return nil, false
+		}
+		fields[i] = f
+	}
+	return fields, true
+}
+
+// nodeJSON writes the JSON form of the value n to buf.
+func nodeJSON(buf *bytes.Buffer, n ast.Node) {
+	switch n := n.(type) {
+	case *ast.ObjectNode:
+		fields, ok := componentsOf(n)
+		if !ok {
+			buf.WriteByte('[')
+			for i, el := range n.Elements {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				nodeJSON(buf, el)
+			}
+			buf.WriteByte(']')
+			return
+		}
+		buf.WriteByte('{')
+		for i, f := range fields {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			jsonString(buf, f.Name.Name)
+			buf.WriteByte(':')
+			nodeJSON(buf, f.Value)
+		}
+		buf.WriteByte('}')
+	case *ast.FieldNode:
+		// A component among elements without identifiers, as in
+		// { a 1, { b 2 } }, is a single-entry object, as Unmarshal
+		// decodes it into a single-entry map.
+		buf.WriteByte('{')
+		jsonString(buf, n.Name.Name)
+		buf.WriteByte(':')
+		nodeJSON(buf, n.Value)
+		buf.WriteByte('}')
+	case *ast.ChoiceNode:
+		buf.WriteByte('{')
+		jsonString(buf, n.Name.Name)
+		buf.WriteByte(':')
+		nodeJSON(buf, n.Value)
+		buf.WriteByte('}')
+	case *ast.OIDNode:
+		parts := make([]string, len(n.Components))
+		for i, c := range n.Components {
+			switch c := c.(type) {
+			case *ast.IntNode:
+				parts[i] = c.Text
+			case *ast.Ident:
+				parts[i] = c.Name
+			}
+		}
+		jsonString(buf, strings.Join(parts, "."))
+	case *ast.NullNode:
+		buf.WriteString("null")
+	case *ast.BoolNode:
+		if n.Value {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case *ast.IntNode:
+		i, _ := new(big.Int).SetString(n.Text, 10)
+		buf.WriteString(i.String())
+	case *ast.RealNode:
+		buf.WriteString(jsonReal(n.Text))
+	case *ast.HexNode:
+		jsonString(buf, strings.ToUpper(n.Digits))
+	case *ast.BitsNode:
+		jsonString(buf, n.Digits)
+	case *ast.StringNode:
+		jsonString(buf, n.Value)
+	case *ast.Ident:
+		jsonString(buf, n.Name)
+	}
+}
+
+// jsonReal returns the real number text as a JSON number.
+func jsonReal(text string) string {
+	if json.Valid([]byte(text)) {
+		return text
+	}
+	// Such as 1. or 01.5, which JSON does not allow.
+	f, _, _ := big.ParseFloat(text, 10, 256, big.ToNearestEven)
+	return f.Text('g', -1)
+}
+
+func jsonString(buf *bytes.Buffer, s string) {
+	b, _ := json.Marshal(s)
+	buf.Write(b)
+}
+
+// JSONHints tells FromJSON the value notation of the JSON values that
+// ToJSON writes the same way for different values.
+//
+// Types holds the types of the value assignments of a document, by value
+// reference. Kinds holds the kinds of values by name: the name of a
+// component, of a CHOICE alternative or of a value assignment applies to
+// its value, and to the elements of its value at any depth if that is an
+// array.
+type JSONHints struct {
+	Types map[string]string
+	Kinds map[string]JSONKind
+}
+
+// A JSONKind is the value notation of a JSON value, see JSONHints.
+type JSONKind int
+
+const (
+	JSONDefault JSONKind = iota // a cstring for a string and a SEQUENCE value for an object
+	JSONHex                     // an hstring for a string of hexadecimal digits
+	JSONBits                    // a bstring for a string of binary digits
+	JSONIdent                   // an identifier, such as an ENUMERATED value, for a string
+	JSONOID                     // an OBJECT IDENTIFIER value for a string such as "2.23.143"
+	JSONChoice                  // a CHOICE value for an object of a single member
+)
+
+// JSONHintsOf returns the hints for FromJSON to convert the JSON that
+// ToJSON returns for the value notation document data back to data, less
+// its comments and layout. If a name is used for values of different
+// kinds, the first one given wins.
+func JSONHintsOf(data []byte) (*JSONHints, error) {
+	as, err := ParseAssignments(data)
+	if err != nil {
+		return nil, err
+	}
+	unwrapContaining(as)
+	h := &JSONHints{Types: make(map[string]string), Kinds: make(map[string]JSONKind)}
+	var kind func(n ast.Node) JSONKind
+	name := func(name string, n ast.Node) {
+		if k := kind(n); k != JSONDefault {
+			if _, ok := h.Kinds[name]; !ok {
+				h.Kinds[name] = k
+			}
+		}
+	}
+	kind = func(n ast.Node) JSONKind {
+		switch n := n.(type) {
+		case *ast.HexNode:
+			return JSONHex
+		case *ast.BitsNode:
+			return JSONBits
+		case *ast.Ident:
+			return JSONIdent
+		case *ast.OIDNode:
+			return JSONOID
+		case *ast.ChoiceNode:
+			name(n.Name.Name, n.Value)
+			return JSONChoice
+		case *ast.ObjectNode:
+			k := JSONDefault
+			for _, el := range n.Elements {
+				if f, ok := el.(*ast.FieldNode); ok {
+					name(f.Name.Name, f.Value)
+				} else if ek := kind(el); k == JSONDefault {
+					k = ek
+				}
+			}
+			return k
+		}
+		return JSONDefault
+	}
+	for _, a := range as {
+		if a.Name == nil {
+			kind(a.Value)
+			continue
+		}
+		h.Types[a.Name.Name] = a.Type.Name
+		name(a.Name.Name, a.Value)
+	}
+	return h, nil
+}
+
+// FromJSON converts the plain JSON jsonData, such as that returned by
+// ToJSON, to ASN.1 value notation, with the kinds of values hints gives;
+// hints may be nil. A JSON object whose members all have a type in hints
+// becomes a document of value assignments, and any other JSON value
+// becomes a single value. JSON numbers with a fraction or exponent become
+// REAL values and other numbers INTEGER values.
+func FromJSON(jsonData []byte, hints *JSONHints) ([]byte, error) {
+	if hints == nil {
+		hints = new(JSONHints)
+	}
+	dec := json.NewDecoder(bytes.NewReader(jsonData))
+	dec.UseNumber()
+	v, err := readJSON(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("asn1go: FromJSON of more than one JSON value")
+	}
+	return marshalGeneric(v, hints)
+}
+
+// marshalGeneric returns the value notation document of v, which readJSON
+// or the YAML parser returned, with the kinds and types hints gives.
+func marshalGeneric(v interface{}, hints *JSONHints) ([]byte, error) {
+	members, ok := v.(jsonObject)
+	if ok && len(members) > 0 {
+		var as []*ast.Assignment
+		for _, m := range members {
+			typ, ok := hints.Types[m.name]
+			if !ok {
+				as = nil
+				break
+			}
+			n, err := jsonNode(m.value, hints, hints.Kinds[m.name])
+			if err != nil {
+				return nil, err
+			}
+			as = append(as, &ast.Assignment{Name: &ast.Ident{Name: m.name}, Type: &ast.Ident{Name: typ}, Value: n})
+		}
+		if as != nil {
+			return Marshal(as)
+		}
+	}
+	n, err := jsonNode(v, hints, JSONDefault)
+	if err != nil {
+		return nil, err
+	}
+	return Marshal(n)
+}
+
+// A jsonObject is a JSON object with the order of its members.
+type jsonObject []jsonMember
+
+type jsonMember struct {
+	name  string
+	value interface{}
+}
+
+// A taggedValue is a value of the YAML or CBOR form of value notation
+// tagged with the kind of its value notation.
+type taggedValue struct {
+	kind  JSONKind
+	value interface{}
+}
+
+// readJSON reads the next JSON value from dec, with UseNumber, as
+// encoding/json decodes it into an empty interface except that objects are
+// jsonObjects.
+func readJSON(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('['):
+		list := []interface{}{}
+		for dec.More() {
+			el, err := readJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, el)
+		}
+		_, err := dec.Token()
+		return list, err
+	case json.Delim('{'):
+		obj := jsonObject{}
+		for dec.More() {
+			name, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			el, err := readJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, jsonMember{name.(string), el})
+		}
+		_, err := dec.Token()
+		return obj, err
+	}
+	return tok, nil
+}
+
+// jsonNode returns the value notation of the JSON value v, which readJSON
+// returned, as a value of the kind k.
+func jsonNode(v interface{}, hints *JSONHints, k JSONKind) (ast.Node, error) {
+	switch v := v.(type) {
+	case taggedValue:
+		return jsonNode(v.value, hints, v.kind)
+	case nil:
+		return &ast.NullNode{}, nil
+	case bool:
+		return &ast.BoolNode{Value: v}, nil
+	case json.Number:
+		s := strings.Replace(v.String(), "+", "", 1)
+		if strings.ContainsAny(s, ".eE") {
+			return &ast.RealNode{Text: s}, nil
+		}
+		return &ast.IntNode{Text: s}, nil
+	case string:
+		switch k {
+		case JSONHex:
+			return &ast.HexNode{Digits: v}, nil
+		case JSONBits:
+			return &ast.BitsNode{Digits: v}, nil
+		case JSONIdent:
+			return &ast.Ident{Name: v}, nil
+		case JSONOID:
+			oid := new(ast.OIDNode)
+			for _, c := range strings.Split(v, ".") {
+				if isIntegerLiteral(c) {
+					oid.Components = append(oid.Components, &ast.IntNode{Text: c})
+				} else {
+					oid.Components = append(oid.Components, &ast.Ident{Name: c})
+				}
+			}
+			return oid, nil
+		}
+		return &ast.StringNode{Value: v}, nil
+	case []interface{}:
+		obj := &ast.ObjectNode{Elements: []ast.Node{}}
+		for _, el := range v {
+			n, err := jsonNode(el, hints, k)
+			if err != nil {
+				return nil, err
+			}
+			obj.Elements = append(obj.Elements, n)
+		}
+		return obj, nil
+	case jsonObject:
+		if k == JSONChoice {
+			if len(v) != 1 {
+				return nil, errors.New("asn1go: FromJSON of a CHOICE value with " + strconv.Itoa(len(v)) + " members")
+			}
+			n, err := jsonNode(v[0].value, hints, hints.Kinds[v[0].name])
+			if err != nil {
+				return nil, err
+			}
+			return &ast.ChoiceNode{Name: &ast.Ident{Name: v[0].name}, Value: n}, nil
+		}
+		obj := &ast.ObjectNode{Elements: []ast.Node{}}
+		for _, m := range v {
+			n, err := jsonNode(m.value, hints, hints.Kinds[m.name])
+			if err != nil {
+				return nil, err
+			}
+			obj.Elements = append(obj.Elements, &ast.FieldNode{Name: &ast.Ident{Name: m.name}, Value: n})
+		}
+		return obj, nil
+	}
+	panic(phasePanicMsg)
+}
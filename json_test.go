@@ -0,0 +1,132 @@
+package asn1go
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestToJSON(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"sequence", "v T ::= { a 1, b TRUE }", `{"v":{"a":1,"b":true}}`},
+		{"list", "v T ::= { 1, 2, 3 }", `{"v":[1,2,3]}`},
+		{"empty", "v T ::= { }", `{"v":[]}`},
+		{"choice", "v T ::= alt : 'AB'H", `{"v":{"alt":"AB"}}`},
+		{"component then value", "v T ::= { a 1, { b 2 } }", `{"v":[{"a":1},{"b":2}]}`},
+		{"component then identifier", "v T ::= { a 1, b }", `{"v":[{"a":1},"b"]}`},
+		{"value then component", "v T ::= { 1, a 2 }", `{"v":[1,{"a":2}]}`},
+		{"single value", "{ a 1 }", `{"a":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToJSON([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("ToJSON(%q): %v", tt.in, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ToJSON(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONHintsOf(t *testing.T) {
+	tests := []struct {
+		in   string
+		want JSONHints
+	}{
+		{"v T ::= { a 1, b TRUE }", JSONHints{Types: map[string]string{"v": "T"}, Kinds: map[string]JSONKind{}}},
+		{"v OCTET STRING ::= '01'H", JSONHints{Types: map[string]string{"v": "OCTET STRING"}, Kinds: map[string]JSONKind{"v": JSONHex}}},
+		{"v T ::= { a '01'B, b red, c { 2 23 143 } }", JSONHints{
+			Types: map[string]string{"v": "T"},
+			Kinds: map[string]JSONKind{"a": JSONBits, "b": JSONIdent, "c": JSONOID},
+		}},
+		{"v T ::= alt : { x 'AB'H }", JSONHints{
+			Types: map[string]string{"v": "T"},
+			Kinds: map[string]JSONKind{"v": JSONChoice, "x": JSONHex},
+		}},
+		{"v T ::= { 'AB'H, 'CD'H }", JSONHints{Types: map[string]string{"v": "T"}, Kinds: map[string]JSONKind{"v": JSONHex}}},
+		{"v T ::= { a 'AB'H }\nw T ::= { a red }", JSONHints{
+			Types: map[string]string{"v": "T", "w": "T"},
+			Kinds: map[string]JSONKind{"a": JSONHex},
+		}},
+		{"{ a red }", JSONHints{Types: map[string]string{}, Kinds: map[string]JSONKind{"a": JSONIdent}}},
+	}
+	for _, tt := range tests {
+		got, err := JSONHintsOf([]byte(tt.in))
+		if err != nil {
+			t.Errorf("JSONHintsOf(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(*got, tt.want) {
+			t.Errorf("JSONHintsOf(%q) = %+v, want %+v", tt.in, *got, tt.want)
+		}
+	}
+	if _, err := JSONHintsOf([]byte("v T ::= {")); err == nil {
+		t.Error("JSONHintsOf of invalid data: no error")
+	}
+}
+
+func TestFromJSON(t *testing.T) {
+	hints := &JSONHints{
+		Types: map[string]string{"v": "T", "w": "INTEGER"},
+		Kinds: map[string]JSONKind{"h": JSONHex, "b": JSONBits, "e": JSONIdent, "o": JSONOID, "c": JSONChoice},
+	}
+	tests := []struct {
+		in    string
+		hints *JSONHints
+		want  string
+	}{
+		{`{"a":[1,2.5e3],"b":null}`, nil, `{ a { 1, 2.5e3 }, b NULL }`},
+		{`"x y"`, nil, `"x y"`},
+		{`true`, nil, `TRUE`},
+		{`[]`, nil, `{ }`},
+		{`{"v":{"h":"ab01","b":"0110","e":"red","o":"2.23.143"},"w":5}`, hints,
+			"v T ::= { h 'AB01'H, b '0110'B, e red, o { 2 23 143 } }\nw INTEGER ::= 5"},
+		{`{"v":{"c":{"alt":1}}}`, hints, "v T ::= { c alt : 1 }"},
+		{`{"v":1,"x":2}`, hints, `{ v 1, x 2 }`},
+		{`{"h":["01","02"]}`, hints, `{ h { '01'H, '02'H } }`},
+	}
+	for _, tt := range tests {
+		got, err := FromJSON([]byte(tt.in), tt.hints)
+		if err != nil {
+			t.Errorf("FromJSON(%s): %v", tt.in, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("FromJSON(%s):\nhave %q\nwant %q", tt.in, got, tt.want)
+		}
+	}
+	for _, in := range []string{`{`, `1 2`, `{"a":}`} {
+		if _, err := FromJSON([]byte(in), nil); err == nil {
+			t.Errorf("FromJSON(%s): no error", in)
+		}
+	}
+}
+
+func TestFromJSONRoundTrip(t *testing.T) {
+	tests := []string{
+		"v T ::= { a 1, b TRUE }",
+		"v T ::= alt : { x 'AB'H, y '01'B, z red, o { 2 23 143 } }",
+		"v T ::= { { a 1 }, { a 2 } }\nw INTEGER ::= -5",
+	}
+	for _, in := range tests {
+		j, err := ToJSON([]byte(in))
+		if err != nil {
+			t.Fatalf("ToJSON(%q): %v", in, err)
+		}
+		h, err := JSONHintsOf([]byte(in))
+		if err != nil {
+			t.Fatalf("JSONHintsOf(%q): %v", in, err)
+		}
+		got, err := FromJSON(j, h)
+		if err != nil {
+			t.Errorf("FromJSON(%s): %v", j, err)
+			continue
+		}
+		if string(got) != in {
+			t.Errorf("FromJSON(ToJSON(%q)) = %q", in, got)
+		}
+	}
+}
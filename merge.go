@@ -0,0 +1,279 @@
+package asn1go
+
+import (
+	"strconv"
+
+	"github.com/openesim/asn1go/ast"
+)
+
+// A ConflictPolicy says what Merge does with a value that the base and
+// the overlay document give differently.
+type ConflictPolicy int
+
+const (
+	// ConflictOverlay, the default, takes the value of the overlay.
+	ConflictOverlay ConflictPolicy = iota
+	// ConflictBase keeps the value of the base.
+	ConflictBase
+	// ConflictError stops the merge with a MergeConflictError.
+	ConflictError
+)
+
+// A ListPolicy says how Merge combines the SEQUENCE OF and SET OF values
+// of the base and the overlay document.
+type ListPolicy int
+
+const (
+	// ListReplace, the default, merges lists as single values: lists
+	// that differ conflict, and the ConflictPolicy decides which one is
+	// kept.
+	ListReplace ListPolicy = iota
+	// ListAppend appends the elements of the overlay list to the base
+	// list.
+	ListAppend
+	// ListMerge merges the elements of the lists position by position
+	// and appends the elements of the overlay list beyond the end of the
+	// base list.
+	ListMerge
+)
+
+// MergeOptions are the options of Merge. The zero MergeOptions takes the
+// values of the overlay and replaces lists.
+type MergeOptions struct {
+	Conflicts ConflictPolicy
+	Lists     ListPolicy
+}
+
+// A MergeConflictError describes a value that the base and the overlay
+// document of Merge give differently, under ConflictError.
+type MergeConflictError struct {
+	Path string // path of the value, as Document addresses it
+}
+
+func (e *MergeConflictError) Error() string {
+	return "asn1go: merge conflict at " + strconv.Quote(e.Path)
+}
+
+// Merge overlays the value notation document overlay onto the document
+// base, such as the subscriber-specific values of a profile onto a
+// template, and returns the merged document. The text of base is edited
+// as a Document would edit it, so its comments and layout are kept.
+//
+// The top-level values of overlay are matched with those of base by value
+// reference, and values without assignment by position; those that base
+// does not have are appended to it. Matching values are merged deeply:
+// the components of SEQUENCE and SET values by identifier, with those
+// only the overlay has inserted after the component before them in the
+// overlay, or first, and the values of the same CHOICE alternative.
+// Lists are combined as opts.Lists says, and other values, and values of
+// different forms, that differ conflict and are resolved as
+// opts.Conflicts says. A nil opts selects the zero MergeOptions.
+//
+// Malformed value notation is reported as a SyntaxError.
+func Merge(base, overlay []byte, opts *MergeOptions) ([]byte, error) {
+	if opts == nil {
+		opts = new(MergeOptions)
+	}
+	doc, err := ParseDocument(base)
+	if err != nil {
+		return nil, err
+	}
+	as, err := parse(overlay, nil)
+	if err != nil {
+		return nil, err
+	}
+	m := merger{doc: doc, overlay: overlay, opts: opts}
+	plain := 0
+	for _, a := range as {
+		var path string
+		if a.Name == nil {
+			path = "[" + strconv.Itoa(plain) + "]"
+			plain++
+		} else {
+			path = a.Name.Name
+		}
+		if err := m.assignment(path, a); err != nil {
+			return nil, err
+		}
+	}
+	return doc.Bytes(), nil
+}
+
+// A merger merges an overlay document into the Document of its base.
+type merger struct {
+	doc     *Document
+	overlay []byte
+	opts    *MergeOptions
+}
+
+// assignment merges the top-level value a of the overlay into the value
+// at path.
+func (m *merger) assignment(path string, a *ast.Assignment) error {
+	text := m.overlay[a.Pos().Offset:a.End().Offset]
+	t, err := m.doc.lookup(path)
+	if err != nil {
+		src := m.doc.src
+		var add []byte
+		if len(src) > 0 && src[len(src)-1] != '\n' {
+			add = append(add, '\n')
+		}
+		add = append(append(add, text...), '\n')
+		return m.doc.splice(path, len(src), len(src), add)
+	}
+	b := m.doc.assignments[t.assignment]
+	if typeName(b) != typeName(a) {
+		return m.conflict(path, func() error {
+			return m.doc.splice(path, b.Pos().Offset, b.End().Offset, text)
+		})
+	}
+	return m.value(path, b.Value, a.Value)
+}
+
+// value merges the value o of the overlay into the value b at path.
+func (m *merger) value(path string, b, o ast.Node) error {
+	switch b := b.(type) {
+	case *ast.ObjectNode:
+		o, ok := o.(*ast.ObjectNode)
+		if !ok {
+			break
+		}
+		switch {
+		case isComponents(b) && isComponents(o):
+			return m.components(path, b, o)
+		case (isList(b) || len(b.Elements) == 0) && (isList(o) || len(o.Elements) == 0):
+			return m.lists(path, b, o)
+		}
+	case *ast.ChoiceNode:
+		if o, ok := o.(*ast.ChoiceNode); ok && b.Name.Name == o.Name.Name {
+			return m.value(path+"."+b.Name.Name, b.Value, o.Value)
+		}
+	}
+	if nodesEqual(b, o) {
+		return nil
+	}
+	return m.conflict(path, func() error { return m.doc.Replace(path, o) })
+}
+
+// components merges the components of the SEQUENCE or SET value o of the
+// overlay into the value b at path.
+func (m *merger) components(path string, b, o *ast.ObjectNode) error {
+	// names holds the identifiers of the components at path as edited.
+	names := make([]string, len(b.Elements))
+	for i, el := range b.Elements {
+		names[i] = el.(*ast.FieldNode).Name.Name
+	}
+	pos := 0 // where a component only the overlay has goes
+	for _, el := range o.Elements {
+		f := el.(*ast.FieldNode)
+		name := f.Name.Name
+		j := indexOf(names, name)
+		if j < 0 {
+			if err := m.doc.Insert(path, pos, name, f.Value); err != nil {
+				return err
+			}
+			names = append(names[:pos], append([]string{name}, names[pos:]...)...)
+			pos++
+			continue
+		}
+		if err := m.value(path+"."+name, fieldNamed(b, name).Value, f.Value); err != nil {
+			return err
+		}
+		pos = j + 1
+	}
+	return nil
+}
+
+// indexOf returns the index of the first s in list, or -1.
+func indexOf(list []string, s string) int {
+	for i, x := range list {
+		if x == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// fieldNamed returns the first component of obj with identifier name.
+func fieldNamed(obj *ast.ObjectNode, name string) *ast.FieldNode {
+	for _, el := range obj.Elements {
+		if f := el.(*ast.FieldNode); f.Name.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// lists merges the elements of the list o of the overlay into the list b
+// at path.
+func (m *merger) lists(path string, b, o *ast.ObjectNode) error {
+	n := 0 // elements merged position by position
+	switch m.opts.Lists {
+	case ListAppend:
+	case ListMerge:
+		n = len(b.Elements)
+		if len(o.Elements) < n {
+			n = len(o.Elements)
+		}
+	default:
+		if nodesEqual(b, o) {
+			return nil
+		}
+		return m.conflict(path, func() error { return m.doc.Replace(path, o) })
+	}
+
+	for i := 0; i < n; i++ {
+		x, y := b.Elements[i], o.Elements[i]
+		ep := path + "[" + strconv.Itoa(i) + "]"
+		xf, xok := x.(*ast.FieldNode)
+		yf, yok := y.(*ast.FieldNode)
+		var err error
+		switch {
+		case xok && yok && xf.Name.Name == yf.Name.Name:
+			err = m.value(ep, xf.Value, yf.Value)
+		case xok || yok:
+			// A path leads to the value of a component, so a component
+			// that changes its identifier is replaced as an element.
+			if nodesEqual(x, y) {
+				break
+			}
+			err = m.conflict(ep, func() error {
+				if err := m.doc.Remove(ep); err != nil {
+					return err
+				}
+				return m.insert(path, i, y)
+			})
+		default:
+			err = m.value(ep, x, y)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, y := range o.Elements[n:] {
+		if err := m.insert(path, -1, y); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insert inserts the element el of the overlay into the value at path as
+// its i'th element, or as its last if i is -1.
+func (m *merger) insert(path string, i int, el ast.Node) error {
+	if f, ok := el.(*ast.FieldNode); ok {
+		return m.doc.Insert(path, i, f.Name.Name, f.Value)
+	}
+	return m.doc.Insert(path, i, "", el)
+}
+
+// conflict resolves the conflict at path as the ConflictPolicy says,
+// calling overlay to take the value of the overlay.
+func (m *merger) conflict(path string, overlay func() error) error {
+	switch m.opts.Conflicts {
+	case ConflictBase:
+		return nil
+	case ConflictError:
+		return &MergeConflictError{Path: path}
+	}
+	return overlay()
+}
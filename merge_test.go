@@ -0,0 +1,80 @@
+package asn1go
+
+import "testing"
+
+const mergeBase = `-- template
+v1 PE ::= {
+  a 1, -- keep
+  b { 1, 2 },
+  c alt : { x 'AB'H }
+}
+v2 INTEGER ::= 5
+`
+
+func TestMerge(t *testing.T) {
+	keepBase := &MergeOptions{Conflicts: ConflictBase}
+	tests := []struct {
+		overlay  string
+		opts     *MergeOptions
+		old, new string // the edit of mergeBase, if any
+	}{
+		{"v2 INTEGER ::= 5", nil, "", ""},
+		{"v2 INTEGER ::= 6", nil, "::= 5", "::= 6"},
+		{"v2 INTEGER ::= 6", keepBase, "", ""},
+		{"v1 PE ::= { a 2 }", nil, "a 1,", "a 2,"},
+		{"v1 PE ::= { a 2 }", keepBase, "", ""},
+		{"v1 PE ::= { n 9 }", nil, "{\n  a 1", "{\n  n 9,\n  a 1"},
+		{"v1 PE ::= { a 1, n 9 }", &MergeOptions{Conflicts: ConflictError}, "-- keep\n", "-- keep\n  n 9,\n"},
+		{"v1 PE ::= { b { 3 } }", nil, "b { 1, 2 }", "b {\n    3\n  }"},
+		{"v1 PE ::= { b { 3 } }", keepBase, "", ""},
+		{"v1 PE ::= { b { 3 } }", &MergeOptions{Lists: ListAppend}, "{ 1, 2 }", "{ 1, 2, 3 }"},
+		{"v1 PE ::= { b { 3 } }", &MergeOptions{Lists: ListMerge}, "{ 1, 2 }", "{ 3, 2 }"},
+		{"v1 PE ::= { b { 3, 4, 5 } }", &MergeOptions{Lists: ListMerge}, "{ 1, 2 }", "{ 3, 4, 5 }"},
+		{"v1 PE ::= { c alt : { y 1 } }", nil, "{ x", "{ y 1, x"},
+		{"v1 PE ::= { c other : 1 }", nil, "alt : { x 'AB'H }", "other : 1"},
+		{"v1 PE ::= { c other : 1 }", keepBase, "", ""},
+		{"v3 INTEGER ::= 7", nil, "::= 5\n", "::= 5\nv3 INTEGER ::= 7\n"},
+	}
+	for _, tt := range tests {
+		want := mergeBase
+		if tt.old != "" {
+			want = replaceOnce(want, tt.old, tt.new)
+		}
+		got, err := Merge([]byte(mergeBase), []byte(tt.overlay), tt.opts)
+		if err != nil {
+			t.Errorf("Merge(%q, %+v): %v", tt.overlay, tt.opts, err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("Merge(%q, %+v):\nhave %s\nwant %s", tt.overlay, tt.opts, got, want)
+		}
+	}
+	if got, err := Merge([]byte("1 2"), []byte("3 4 5"), nil); err != nil || string(got) != "3 4\n5\n" {
+		t.Errorf("Merge of values without assignment = %q, %v", got, err)
+	}
+}
+
+func TestMergeError(t *testing.T) {
+	fail := &MergeOptions{Conflicts: ConflictError}
+	tests := []struct {
+		base, overlay string
+		path          string // of the MergeConflictError, or "" for a SyntaxError
+	}{
+		{mergeBase, "v2 INTEGER ::= 6", "v2"},
+		{mergeBase, "v1 PE ::= { a 2 }", "v1.a"},
+		{mergeBase, "v1 PE ::= { b { 3 } }", "v1.b"},
+		{mergeBase, "v1 PE ::= { c other : 1 }", "v1.c"},
+		{"{", "1", ""},
+		{mergeBase, "v1 PE ::= {", ""},
+	}
+	for _, tt := range tests {
+		_, err := Merge([]byte(tt.base), []byte(tt.overlay), fail)
+		if tt.path == "" {
+			if _, ok := err.(*SyntaxError); !ok {
+				t.Errorf("Merge(%q, %q): error %v, want SyntaxError", tt.base, tt.overlay, err)
+			}
+		} else if mce, ok := err.(*MergeConflictError); !ok || mce.Path != tt.path {
+			t.Errorf("Merge(%q): error %v, want MergeConflictError at %q", tt.overlay, err, tt.path)
+		}
+	}
+}
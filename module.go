@@ -0,0 +1,176 @@
+package asn1go
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// A Module is an ASN.1 module definition,
+//
+//	ModuleName DEFINITIONS AUTOMATIC TAGS ::= BEGIN ... END
+//
+// as parsed by ParseModule. Its types are ready for the schema-driven
+// encoding rules, such as EncodeDER.
+type Module struct {
+	Name       string
+	Identifier ObjectIdentifier // definitive identifier, or nil
+	TagDefault TagDefault
+
+	// ExtensibilityImplied is set for modules declared with
+	// EXTENSIBILITY IMPLIED.
+	ExtensibilityImplied bool
+
+	// Exports lists the symbols the module exports. ExportsAll is set if
+	// it has no EXPORTS clause or declares EXPORTS ALL; an empty
+	// EXPORTS; exports nothing.
+	Exports    []string
+	ExportsAll bool
+
+	Imports []Import
+
+	// Types holds the assigned types, in the order of their assignments,
+	// and the value sets, which are types too. Parameterized types, which
+	// become types with their actual parameters, are not among them.
+	// References to the module's types are resolved; references to
+	// imported types are left as types of KindInvalid named after the
+	// imported symbol.
+	Types []*Type
+
+	// Values holds the value assignments, other than those of objects,
+	// with each value in the form Unmarshal decodes its value notation to,
+	// except that OBJECT IDENTIFIER values are ObjectIdentifier values
+	// with their names and value references resolved.
+	Values []ValueAssignment
+}
+
+// A TagDefault is the tagging environment of a module, which determines
+// whether tags are implicit or explicit when neither IMPLICIT nor
+// EXPLICIT is given.
+type TagDefault uint8
+
+// Tag defaults.
+const (
+	ExplicitTags TagDefault = iota
+	ImplicitTags
+	AutomaticTags // implicit tags, and components are numbered [0], [1], ...
+)
+
+var tagDefaultNames = [...]string{"EXPLICIT TAGS", "IMPLICIT TAGS", "AUTOMATIC TAGS"}
+
+func (d TagDefault) String() string {
+	if int(d) < len(tagDefaultNames) {
+		return tagDefaultNames[d]
+	}
+	return "TagDefault(" + strconv.Itoa(int(d)) + ")"
+}
+
+// An Import is one clause of the IMPORTS of a module, such as
+// Certificate FROM PKIX1Explicit88 { iso(1) ... }.
+type Import struct {
+	Symbols    []string
+	Module     string
+	Identifier ObjectIdentifier // assigned identifier of the module, or nil
+}
+
+// Type returns the type assigned to the type reference name, or nil if
+// the module has none.
+func (m *Module) Type(name string) *Type {
+	for _, t := range m.Types {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// Value returns the value assigned to the value reference name.
+func (m *Module) Value(name string) (interface{}, bool) {
+	for _, va := range m.Values {
+		if va.Name == name {
+			return va.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Enums returns the named values of the ENUMERATED types assigned in the
+// module, by type reference, each a map from identifier to number, so
+// that applications can translate between the identifiers and numbers of
+// enumerations at run time without generated Go types. Extension
+// additions are included.
+func (m *Module) Enums() map[string]map[string]int64 {
+	enums := make(map[string]map[string]int64)
+	for _, t := range m.Types {
+		if t.Kind != KindEnumerated {
+			continue
+		}
+		names := make(map[string]int64, len(t.Named))
+		for _, n := range t.Named {
+			names[n.Name] = n.Value
+		}
+		enums[t.Name] = names
+	}
+	return enums
+}
+
+// ParseModule parses an ASN.1 module definition in the notation of
+// X.680. The module's types are resolved: references to its own types are
+// replaced by the types, tags are implicit or explicit according to the
+// module's tag default and, for AUTOMATIC TAGS, the components of a
+// SEQUENCE, SET or CHOICE without tags are tagged [0], [1] and so on.
+//
+// SIZE constraints, value ranges and permitted alphabets are recorded in
+// Type.Size, Type.Range and Type.From, for CheckConstraints, and contents
+// constraints, CONTAINING Type ENCODED BY Value, in Type.Contains and
+// Type.EncodedBy; other constraints, including table constraints, are
+// checked for syntax only.
+//
+// Extension markers make SEQUENCE, SET, CHOICE and ENUMERATED types
+// extensible, as EXTENSIBILITY IMPLIED makes all of them, which
+// Type.Extensible records. Extension additions, including those in the
+// version brackets [[ ]] of addition groups, are components with
+// Component.Extension set, or named numbers with NamedNumber.Extension
+// set, and the codecs decode the additions they do not know as Extensions.
+// COMPONENTS OF is not supported.
+//
+// Each use of a parameterized type, such as SIGNED{TBSCertificate}, is a
+// type of its own, with the actual parameters in place of the formal ones.
+// Information object classes, objects and object sets are checked for
+// syntax only, except that a field of a class used as a type is the type
+// of a fixed-type value field, as in ATTRIBUTE.&id, or an open type of
+// KindAny for a type field, as in ATTRIBUTE.&Type. INSTANCE OF is the
+// SEQUENCE that X.681 defines for it.
+//
+// References to imported types are left unresolved, and values imported
+// from other modules cannot be used; ParseModules resolves them.
+//
+// Malformed modules, and references to undefined types or values, are
+// reported as a ModuleSyntaxError.
+func ParseModule(src []byte) (*Module, error) {
+	ms, err := parseModules([][]byte{src}, false, true)
+	if err != nil {
+		return nil, err
+	}
+	return ms[0], nil
+}
+
+// A ModuleSyntaxError describes malformed ASN.1 module definitions.
+type ModuleSyntaxError struct {
+	Msg    string
+	Module string // name of the module, if it is known
+	Offset int64  // error occurred after reading Offset bytes
+	Line   int    // line of the offset, starting at 1
+}
+
+func (e *ModuleSyntaxError) Error() string {
+	at := "at line " + strconv.Itoa(e.Line)
+	if e.Module != "" {
+		at = "in " + e.Module + " " + at
+	}
+	return "asn1go: module syntax error " + at + ": " + e.Msg
+}
+
+// newModuleSyntaxError returns a ModuleSyntaxError at offset off in src.
+func newModuleSyntaxError(src []byte, off int, msg string) *ModuleSyntaxError {
+	return &ModuleSyntaxError{Msg: msg, Offset: int64(off), Line: 1 + bytes.Count(src[:off], []byte{'\n'})}
+}
@@ -0,0 +1,229 @@
+package asn1go
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+const testModuleSrc = `
+Test-Module { iso(1) identified-organization(3) dod(6) 42 id-mod(0) } -- a comment
+DEFINITIONS AUTOMATIC TAGS ::=
+BEGIN
+EXPORTS Person, maxNameLen;
+IMPORTS Certificate FROM PKIX1 { iso 3 6 1 } Other FROM Mod3;
+
+/* nested /* block */ comment */
+maxNameLen INTEGER ::= 32
+id-test OBJECT IDENTIFIER ::= { iso(1) member-body(2) 840 }
+id-sub OBJECT IDENTIFIER ::= { id-test sub(5) maxNameLen }
+
+Age ::= INTEGER (0..150)
+Name ::= UTF8String (SIZE (1..maxNameLen))
+Color ::= ENUMERATED { red, green(5), blue, ... }
+Person ::= SEQUENCE {
+	name Name,
+	age Age OPTIONAL,
+	color Color DEFAULT green,
+	kind Kind,
+	next Person OPTIONAL,
+	cert Certificate OPTIONAL
+}
+Kind ::= CHOICE { a NULL, b BOOLEAN }
+Tagged ::= [PRIVATE 7] Person
+END
+`
+
+func TestParseModule(t *testing.T) {
+	m, err := ParseModule([]byte(testModuleSrc))
+	if err != nil {
+		t.Fatalf("ParseModule: %v", err)
+	}
+	if m.Name != "Test-Module" || !reflect.DeepEqual(m.Identifier, ObjectIdentifier{1, 3, 6, 42, 0}) || m.TagDefault != AutomaticTags {
+		t.Errorf("header = %s %v %v", m.Name, m.Identifier, m.TagDefault)
+	}
+	if !reflect.DeepEqual(m.Exports, []string{"Person", "maxNameLen"}) || m.ExportsAll {
+		t.Errorf("Exports = %v, ExportsAll = %v", m.Exports, m.ExportsAll)
+	}
+	wantImports := []Import{
+		{Symbols: []string{"Certificate"}, Module: "PKIX1", Identifier: ObjectIdentifier{1, 3, 6, 1}},
+		{Symbols: []string{"Other"}, Module: "Mod3"},
+	}
+	if !reflect.DeepEqual(m.Imports, wantImports) {
+		t.Errorf("Imports = %+v, want %+v", m.Imports, wantImports)
+	}
+
+	values := []struct {
+		name string
+		want interface{}
+	}{
+		{"maxNameLen", int64(32)},
+		{"id-test", ObjectIdentifier{1, 2, 840}},
+		{"id-sub", ObjectIdentifier{1, 2, 840, 5, 32}},
+	}
+	for _, tt := range values {
+		if v, ok := m.Value(tt.name); !ok || !reflect.DeepEqual(v, tt.want) {
+			t.Errorf("Value(%q) = %#v, %v, want %#v", tt.name, v, ok, tt.want)
+		}
+	}
+	if _, ok := m.Value("none"); ok {
+		t.Error(`Value("none") found`)
+	}
+
+	types := []struct {
+		name string
+		kind Kind
+		tags []TypeTag
+	}{
+		{"Age", KindInteger, nil},
+		{"Name", KindUTF8String, nil},
+		{"Color", KindEnumerated, nil},
+		{"Person", KindSequence, nil},
+		{"Kind", KindChoice, nil},
+		{"Tagged", KindSequence, []TypeTag{{Tag: Tag{ClassPrivate, 7}}}},
+	}
+	for _, tt := range types {
+		typ := m.Type(tt.name)
+		if typ == nil {
+			t.Errorf("Type(%q) = nil", tt.name)
+			continue
+		}
+		if typ.Kind != tt.kind || len(typ.Tags) != len(tt.tags) || len(tt.tags) > 0 && !reflect.DeepEqual(typ.Tags, tt.tags) {
+			t.Errorf("Type(%q) = %v with tags %v, want %v with tags %v", tt.name, typ.Kind, typ.Tags, tt.kind, tt.tags)
+		}
+	}
+	if m.Type("Missing") != nil {
+		t.Error(`Type("Missing") != nil`)
+	}
+
+	if r := m.Type("Age").Range; r == nil || r.Min != 0 || r.Max != 150 {
+		t.Errorf("Age range = %v, want 0..150", r)
+	}
+	if s := m.Type("Name").Size; s == nil || s.Min != 1 || s.Max != 32 {
+		t.Errorf("Name size = %v, want 1..32", s)
+	}
+	color := m.Type("Color")
+	wantNamed := []NamedNumber{{"red", 0, false}, {"green", 5, false}, {"blue", 1, false}}
+	if !reflect.DeepEqual(color.Named, wantNamed) || !color.Extensible {
+		t.Errorf("Color = %v, extensible %v, want %v", color.Named, color.Extensible, wantNamed)
+	}
+
+	person := m.Type("Person")
+	if c := person.Component("next"); c == nil || c.Type.Name != "Person" || c.Type.Kind != KindSequence || !c.Optional {
+		t.Error("Person.next is not an OPTIONAL Person")
+	}
+	if c := person.Component("color"); c == nil || c.Default != "green" {
+		t.Error("Person.color has no DEFAULT green")
+	}
+	if c := person.Component("cert"); c == nil || c.Type.Kind != KindInvalid || c.Type.Name != "Certificate" {
+		t.Error("Person.cert is not the unresolved imported Certificate")
+	}
+	// AUTOMATIC TAGS numbers the components of a type without tags; the
+	// tag of the CHOICE is explicit when it is encoded.
+	if c := person.Component("kind"); c == nil || !reflect.DeepEqual(c.Type.Tags, []TypeTag{{Tag: Tag{ClassContextSpecific, 3}}}) {
+		t.Errorf("Person.kind tags = %v, want [3]", c.Type.Tags)
+	}
+	if c := m.Type("Kind").Component("b"); c == nil || !reflect.DeepEqual(c.Type.Tags, []TypeTag{{Tag: Tag{ClassContextSpecific, 1}}}) {
+		t.Errorf("Kind.b tags = %v, want [1]", c.Type.Tags)
+	}
+
+	b, err := EncodeDER(m.Type("Tagged"), map[string]interface{}{"name": "Ann", "kind": map[string]interface{}{"b": true}})
+	if want := fromHex("E7 0A 80 03 41 6E 6E A3 03 81 01 FF"); err != nil || !bytes.Equal(b, want) {
+		t.Errorf("EncodeDER(Tagged) = % X, %v, want % X", b, err, want)
+	}
+}
+
+func TestParseModuleTagDefault(t *testing.T) {
+	tests := []struct {
+		header string
+		want   TagDefault
+		tags   []TypeTag // of the component a
+	}{
+		{"DEFINITIONS ::=", ExplicitTags, []TypeTag{{Tag: Tag{ClassContextSpecific, 0}, Explicit: true}}},
+		{"DEFINITIONS EXPLICIT TAGS ::=", ExplicitTags, []TypeTag{{Tag: Tag{ClassContextSpecific, 0}, Explicit: true}}},
+		{"DEFINITIONS IMPLICIT TAGS ::=", ImplicitTags, []TypeTag{{Tag: Tag{ClassContextSpecific, 0}}}},
+		{"DEFINITIONS AUTOMATIC TAGS ::=", AutomaticTags, []TypeTag{{Tag: Tag{ClassContextSpecific, 0}}}},
+	}
+	for _, tt := range tests {
+		src := "M " + tt.header + " BEGIN S ::= SEQUENCE { a [0] INTEGER } END"
+		m, err := ParseModule([]byte(src))
+		if err != nil {
+			t.Errorf("ParseModule(%q): %v", src, err)
+			continue
+		}
+		if m.TagDefault != tt.want {
+			t.Errorf("ParseModule(%q).TagDefault = %v, want %v", src, m.TagDefault, tt.want)
+		}
+		if tags := m.Type("S").Component("a").Type.Tags; !reflect.DeepEqual(tags, tt.tags) {
+			t.Errorf("ParseModule(%q): tags of a = %v, want %v", src, tags, tt.tags)
+		}
+	}
+}
+
+func TestParseModuleError(t *testing.T) {
+	tests := []struct {
+		src  string
+		line int
+	}{
+		{"M DEFINITIONS ::= BEGIN A ::= B END", 1},
+		{"M DEFINITIONS ::= BEGIN A ::= B B ::= A END", 1},
+		{"M DEFINITIONS ::= BEGIN A ::= SEQUENCE { a INTEGER a BOOLEAN } END", 1},
+		{"M DEFINITIONS ::= BEGIN\n A ::= INTEGER (0..x) END", 2},
+		{"M DEFINITIONS ::= BEGIN A ::= INTEGER", 1},
+		{"M DEFINITIONS ::= BEGIN a INTEGER ::= a END", 1},
+		{"", 1},
+	}
+	for _, tt := range tests {
+		_, err := ParseModule([]byte(tt.src))
+		se, ok := err.(*ModuleSyntaxError)
+		if !ok {
+			t.Errorf("ParseModule(%q): error %v, want ModuleSyntaxError", tt.src, err)
+			continue
+		}
+		if se.Line != tt.line {
+			t.Errorf("ParseModule(%q): error at line %d, want %d", tt.src, se.Line, tt.line)
+		}
+	}
+}
+
+func TestTagDefaultString(t *testing.T) {
+	tests := []struct {
+		d    TagDefault
+		want string
+	}{
+		{ExplicitTags, "EXPLICIT TAGS"},
+		{ImplicitTags, "IMPLICIT TAGS"},
+		{AutomaticTags, "AUTOMATIC TAGS"},
+		{TagDefault(9), "TagDefault(9)"},
+	}
+	for _, tt := range tests {
+		if got := tt.d.String(); got != tt.want {
+			t.Errorf("TagDefault(%d).String() = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestModuleEnums(t *testing.T) {
+	tests := []struct {
+		src  string
+		want map[string]map[string]int64
+	}{
+		{testModuleSrc, map[string]map[string]int64{"Color": {"red": 0, "green": 5, "blue": 1}}},
+		{`M DEFINITIONS ::= BEGIN
+A ::= ENUMERATED { x(2), y(1), ..., z(7) }
+B ::= SEQUENCE { e ENUMERATED { p, q } }
+C ::= A
+N ::= INTEGER { one(1) }
+END`, map[string]map[string]int64{"A": {"x": 2, "y": 1, "z": 7}, "C": {"x": 2, "y": 1, "z": 7}}},
+		{"M DEFINITIONS ::= BEGIN\nI ::= INTEGER\nEND", map[string]map[string]int64{}},
+	}
+	for _, tt := range tests {
+		m, err := ParseModule([]byte(tt.src))
+		if err != nil {
+			t.Fatalf("ParseModule: %v", err)
+		}
+		if got := m.Enums(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Enums of %s = %v, want %v", m.Name, got, tt.want)
+		}
+	}
+}
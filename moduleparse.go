@@ -0,0 +1,2290 @@
+package asn1go
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A moduleTokenKind is the kind of a lexical item of module notation.
+type moduleTokenKind uint8
+
+const (
+	tokEOF    moduleTokenKind = iota
+	tokWord                   // identifier, reference or reserved word
+	tokNumber                 // number or real number
+	tokCString
+	tokBString
+	tokHString
+	tokSymbol
+)
+
+// A moduleToken is a lexical item of module notation.
+type moduleToken struct {
+	kind     moduleTokenKind
+	text     string
+	off, end int // offsets of the item in the input
+}
+
+func (tok moduleToken) String() string {
+	if tok.kind == tokEOF {
+		return "end of input"
+	}
+	return strconv.Quote(tok.text)
+}
+
+// isTypeReference reports whether tok is a type reference, such as
+// ProfileElement, rather than an identifier or a reserved word.
+func (tok moduleToken) isTypeReference() bool {
+	return tok.kind == tokWord && isUpper(tok.text[0]) && !reservedWords[tok.text]
+}
+
+// isIdentifier reports whether tok is an identifier or a value reference,
+// such as major-version.
+func (tok moduleToken) isIdentifier() bool {
+	return tok.kind == tokWord && tok.text[0] >= 'a' && tok.text[0] <= 'z'
+}
+
+func isUpper(c byte) bool { return 'A' <= c && c <= 'Z' }
+
+// reservedWords are the reserved words of X.680 that may not be used as
+// type references.
+var reservedWords = map[string]bool{
+	"ABSENT": true, "ABSTRACT-SYNTAX": true, "ALL": true, "APPLICATION": true,
+	"AUTOMATIC": true, "BEGIN": true, "BIT": true, "BMPString": true,
+	"BOOLEAN": true, "BY": true, "CHARACTER": true, "CHOICE": true,
+	"CLASS": true, "COMPONENT": true, "COMPONENTS": true, "CONSTRAINED": true,
+	"CONTAINING": true, "DATE": true, "DATE-TIME": true, "DEFAULT": true,
+	"DEFINITIONS": true, "DURATION": true, "EMBEDDED": true, "ENCODED": true,
+	"ENCODING-CONTROL": true, "END": true, "ENUMERATED": true, "EXCEPT": true,
+	"EXPLICIT": true, "EXPORTS": true, "EXTENSIBILITY": true, "EXTERNAL": true,
+	"FALSE": true, "FROM": true, "GeneralizedTime": true, "GeneralString": true,
+	"GraphicString": true, "IA5String": true, "IDENTIFIER": true, "IMPLICIT": true,
+	"IMPLIED": true, "IMPORTS": true, "INCLUDES": true, "INSTANCE": true,
+	"INSTRUCTIONS": true, "INTEGER": true, "INTERSECTION": true, "ISO646String": true,
+	"MAX": true, "MIN": true, "MINUS-INFINITY": true, "NOT-A-NUMBER": true,
+	"NULL": true, "NumericString": true, "OBJECT": true, "ObjectDescriptor": true,
+	"OCTET": true, "OF": true, "OID-IRI": true, "OPTIONAL": true,
+	"PATTERN": true, "PDV": true, "PLUS-INFINITY": true, "PRESENT": true,
+	"PrintableString": true, "PRIVATE": true, "REAL": true, "RELATIVE-OID": true,
+	"RELATIVE-OID-IRI": true, "SEQUENCE": true, "SET": true, "SETTINGS": true,
+	"SIZE": true, "STRING": true, "SYNTAX": true, "T61String": true,
+	"TAGS": true, "TeletexString": true, "TIME": true, "TIME-OF-DAY": true,
+	"TRUE": true, "TYPE-IDENTIFIER": true, "UNION": true, "UNIQUE": true,
+	"UNIVERSAL": true, "UniversalString": true, "UTCTime": true, "UTF8String": true,
+	"VideotexString": true, "VisibleString": true, "WITH": true,
+}
+
+// stringKinds are the kinds of the character string and time types, by
+// the name of their type.
+var stringKinds = map[string]Kind{
+	"UTF8String":      KindUTF8String,
+	"NumericString":   KindNumericString,
+	"PrintableString": KindPrintableString,
+	"IA5String":       KindIA5String,
+	"VisibleString":   KindVisibleString,
+	"ISO646String":    KindVisibleString,
+	"UTCTime":         KindUTCTime,
+	"GeneralizedTime": KindGeneralizedTime,
+	"TIME":            KindTime,
+	"DATE":            KindDate,
+	"TIME-OF-DAY":     KindTimeOfDay,
+	"DATE-TIME":       KindDateTime,
+	"DURATION":        KindDuration,
+}
+
+// unsupportedTypes are the built-in types that have no Kind.
+var unsupportedTypes = map[string]bool{
+	"BMPString": true, "GeneralString": true, "GraphicString": true, "T61String": true,
+	"TeletexString": true, "UniversalString": true, "VideotexString": true,
+	"ObjectDescriptor": true, "EXTERNAL": true, "CHARACTER": true,
+}
+
+// lex splits the input into lexical items, dropping white space and
+// comments. The last item is always a tokEOF.
+func (p *moduleParser) lex() []moduleToken {
+	var toks []moduleToken
+	src := p.src
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		start := i
+		kind := tokSymbol
+		switch {
+		case isSpace(c):
+			i++
+			continue
+
+		case c == '-' && i+1 < len(src) && src[i+1] == '-':
+			// A comment runs to the next -- or the end of the line.
+			for i += 2; i < len(src) && src[i] != '\n' && src[i] != '\r'; i++ {
+				if src[i] == '-' && i+1 < len(src) && src[i+1] == '-' {
+					i += 2
+					break
+				}
+			}
+			continue
+
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			// Block comments nest.
+			depth := 0
+			for i < len(src) {
+				if bytes.HasPrefix(src[i:], []byte("/*")) {
+					depth++
+					i += 2
+				} else if bytes.HasPrefix(src[i:], []byte("*/")) {
+					depth--
+					i += 2
+					if depth == 0 {
+						break
+					}
+				} else {
+					i++
+				}
+			}
+			if depth > 0 {
+				p.errorf(start, "unterminated comment")
+			}
+			continue
+
+		case isLetter(c) || c == '&' && i+1 < len(src) && isLetter(src[i+1]):
+			kind = tokWord
+			for i++; i < len(src); i++ {
+				if isLetter(src[i]) || isDigit(src[i]) {
+					continue
+				}
+				// A hyphen may not end an identifier, nor follow another.
+				if src[i] == '-' && i+1 < len(src) && (isLetter(src[i+1]) || isDigit(src[i+1])) {
+					continue
+				}
+				break
+			}
+
+		case isDigit(c):
+			kind = tokNumber
+			for i < len(src) && isDigit(src[i]) {
+				i++
+			}
+			if i+1 < len(src) && src[i] == '.' && isDigit(src[i+1]) {
+				for i++; i < len(src) && isDigit(src[i]); i++ {
+				}
+			}
+			if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+				j := i + 1
+				if j < len(src) && (src[j] == '-' || src[j] == '+') {
+					j++
+				}
+				if j < len(src) && isDigit(src[j]) {
+					for i = j; i < len(src) && isDigit(src[i]); i++ {
+					}
+				}
+			}
+
+		case c == '"':
+			kind = tokCString
+			for i++; ; i++ {
+				if i >= len(src) {
+					p.errorf(start, "unterminated cstring")
+				}
+				if src[i] == '"' {
+					if i+1 < len(src) && src[i+1] == '"' {
+						i++
+						continue
+					}
+					i++
+					break
+				}
+			}
+
+		case c == '\'':
+			for i++; i < len(src) && src[i] != '\''; i++ {
+			}
+			if i+1 >= len(src) || src[i+1] != 'B' && src[i+1] != 'H' {
+				p.errorf(start, "unterminated bstring or hstring")
+			}
+			kind = tokBString
+			if src[i+1] == 'H' {
+				kind = tokHString
+			}
+			i += 2
+
+		case bytes.HasPrefix(src[i:], []byte("::=")),
+			bytes.HasPrefix(src[i:], []byte("...")):
+			i += 3
+
+		case bytes.HasPrefix(src[i:], []byte("..")),
+			bytes.HasPrefix(src[i:], []byte("[[")),
+			bytes.HasPrefix(src[i:], []byte("]]")):
+			i += 2
+
+		case strings.IndexByte("{}()[],;:.|^<>@!-+*=", c) >= 0:
+			i++
+
+		default:
+			p.errorf(i, "invalid character %s", quoteChar(c))
+		}
+		toks = append(toks, moduleToken{kind, string(src[start:i]), start, i})
+	}
+	return append(toks, moduleToken{tokEOF, "", len(src), len(src)})
+}
+
+// A moduleLinker is the state shared by the parsers of the modules that
+// ParseModule or ParseModules parses together.
+type moduleLinker struct {
+	parsers  map[string]*moduleParser // by module name
+	refs     map[*Type]typeRef        // types that are type references
+	resolved map[*Type]bool           // types whose references fix has replaced
+
+	instances []*instance      // uses of parameterized types to be instantiated
+	depth     int              // nesting of the instance being instantiated
+	instanced map[string]*Type // types of the instances, by instanceKey
+
+	// complete is set if the imported modules must be among the parsed
+	// ones. Otherwise references to imported types are left unresolved.
+	complete bool
+}
+
+// A moduleParser is the state of parsing one module.
+type moduleParser struct {
+	l    *moduleLinker
+	src  []byte
+	toks []moduleToken
+	pos  int // index of the next item in toks
+	m    *Module
+
+	types    map[string]*Type     // assigned types, by type reference
+	names    []string             // type references, in order
+	values   map[string]*valueDef // assigned values, by value reference
+	defs     []*valueDef          // assigned values, in order
+	imported map[string]string    // modules of the imported symbols
+	offsets  map[string]int       // offsets of the assigned and imported symbols
+	busy     map[string]bool      // aliases and imported symbols being resolved
+
+	classes    map[string]*objectClass // information object classes, by reference
+	paramTypes map[string]*paramType   // parameterized types, by reference
+	sets       []valueSet              // value set and object set assignments
+
+	// params holds the actual parameters while the type of a
+	// parameterized type is parsed.
+	params map[string]actualParam
+
+	// later holds the work that needs the values of the module, such as
+	// resolving the bounds of constraints; it runs before the type
+	// references are resolved. defaults holds the DEFAULT values, which
+	// are converted once they are.
+	later    []func()
+	defaults []pendingDefault
+	idents   []pendingIdent
+}
+
+// A typeRef is where a type reference of the module p refers to.
+type typeRef struct {
+	p      *moduleParser
+	name   string
+	module string // for an external reference, Module.Type
+	field  string // for a field of a class, as in ATTRIBUTE.&id
+	off    int
+	target *Type // for an instance, its type
+}
+
+// An objectClass is an information object class. Its fields map to the
+// types, or classes, given for them in its definition, and to nil for the
+// type fields and the value fields of the type of a type field; a class
+// of a module that is not known has no fields.
+type objectClass struct {
+	p      *moduleParser
+	fields map[string]*Type
+}
+
+// A paramType is a parameterized type assignment, whose type, in the
+// items toks, is parsed anew for each instance.
+type paramType struct {
+	name   string
+	params []string
+	toks   []moduleToken
+}
+
+// An actualParam is the actual parameter of an instance of a
+// parameterized type: items that the module p parses with the parameters
+// of the instance they are written in. It is zero for the formal
+// parameters while the parameterized type is checked.
+type actualParam struct {
+	p      *moduleParser
+	toks   []moduleToken
+	params map[string]actualParam
+}
+
+// An instance is a use of a parameterized type, which the type t stands
+// for until it is instantiated.
+type instance struct {
+	p      *moduleParser
+	t      *Type
+	r      typeRef
+	args   [][]moduleToken
+	params map[string]actualParam // of the instance the use is written in
+	depth  int
+}
+
+// maxInstanceDepth limits the nesting of instances, which is unbounded for
+// parameterized types that use themselves with other parameters.
+const maxInstanceDepth = 64
+
+// A valueSet is a value set or object set assignment, with the set in
+// braces in toks; t is its governor.
+type valueSet struct {
+	name moduleToken
+	t    *Type
+	toks []moduleToken
+}
+
+// A valueDef is a value assignment, with the value's notation in the
+// items toks until it is converted.
+type valueDef struct {
+	name   string
+	typ    *Type
+	toks   []moduleToken
+	value  interface{}
+	state  int  // 0 before, 1 during and 2 after the conversion
+	object bool // the value is an information object
+}
+
+// A pendingDefault is the DEFAULT value of component i of t, in the items
+// toks.
+type pendingDefault struct {
+	t    *Type
+	i    int
+	toks []moduleToken
+}
+
+// A pendingIdent is the assigned identifier of import i.
+type pendingIdent struct {
+	i    int
+	toks []moduleToken
+}
+
+// errorf aborts the parsing with a ModuleSyntaxError at offset off.
+func (p *moduleParser) errorf(off int, format string, args ...interface{}) {
+	e := newModuleSyntaxError(p.src, off, fmt.Sprintf(format, args...))
+	if p.m != nil {
+		e.Module = p.m.Name
+	}
+	panic(asn1Error{e})
+}
+
+// parseModules parses the modules in srcs and resolves their references,
+// to each other's types and values if complete is set. If single is set,
+// each source must hold exactly one module.
+func parseModules(srcs [][]byte, complete, single bool) (ms []*Module, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if je, ok := r.(asn1Error); ok {
+				ms, err = nil, je.error
+			} else {
+				panic(r)
+			}
+		}
+	}()
+	l := &moduleLinker{
+		parsers:   make(map[string]*moduleParser),
+		refs:      make(map[*Type]typeRef),
+		resolved:  make(map[*Type]bool),
+		instanced: make(map[string]*Type),
+		complete:  complete,
+	}
+	var ps []*moduleParser
+	for _, src := range srcs {
+		lexer := moduleParser{src: src}
+		toks := lexer.lex()
+		for pos := 0; pos == 0 || toks[pos].kind != tokEOF; {
+			p := &moduleParser{
+				l:          l,
+				src:        src,
+				toks:       toks,
+				pos:        pos,
+				m:          &Module{ExportsAll: true},
+				types:      make(map[string]*Type),
+				values:     make(map[string]*valueDef),
+				imported:   make(map[string]string),
+				offsets:    make(map[string]int),
+				busy:       make(map[string]bool),
+				classes:    make(map[string]*objectClass),
+				paramTypes: make(map[string]*paramType),
+			}
+			off := p.peek().off
+			p.module()
+			if l.parsers[p.m.Name] != nil {
+				p.errorf(off, "duplicate module %s", p.m.Name)
+			}
+			l.parsers[p.m.Name] = p
+			ps = append(ps, p)
+			if pos = p.pos; single && toks[pos].kind != tokEOF {
+				p.errorf(toks[pos].off, "unexpected %s after END", toks[pos])
+			}
+		}
+	}
+
+	// The classes of all modules are known before the object sets are
+	// told apart from the value sets, and the parameterized types before
+	// they are instantiated. The constraints and named numbers of all
+	// modules are complete before any type is copied, and the types before
+	// any value is converted.
+	for _, p := range ps {
+		p.classify()
+	}
+	l.instantiate()
+	for _, p := range ps {
+		p.checkSymbols()
+		for _, f := range p.later {
+			f()
+		}
+	}
+	for _, p := range ps {
+		p.linkTypes()
+	}
+	for _, p := range ps {
+		p.linkValues()
+		ms = append(ms, p.m)
+	}
+	return ms, nil
+}
+
+// within runs f to parse the items toks, such as those of an actual
+// parameter, in place of the module's, with the actual parameters params.
+// The items must be used up.
+func (p *moduleParser) within(toks []moduleToken, params map[string]actualParam, f func()) {
+	saved, pos, outer := p.toks, p.pos, p.params
+	defer func() { p.toks, p.pos, p.params = saved, pos, outer }()
+	end := toks[len(toks)-1].end
+	p.toks = append(toks[:len(toks):len(toks)], moduleToken{tokEOF, "", end, end})
+	p.pos, p.params = 0, params
+	f()
+	if tok := p.peek(); tok.kind != tokEOF {
+		p.errorf(tok.off, "unexpected %s", tok)
+	}
+}
+
+func (p *moduleParser) peek() moduleToken { return p.toks[p.pos] }
+
+// peekAt returns the item n items after the next one.
+func (p *moduleParser) peekAt(n int) moduleToken {
+	if p.pos+n >= len(p.toks) {
+		return p.toks[len(p.toks)-1]
+	}
+	return p.toks[p.pos+n]
+}
+
+func (p *moduleParser) next() moduleToken {
+	tok := p.toks[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// is reports whether the next item is the reserved word or symbol s.
+func (p *moduleParser) is(s string) bool {
+	tok := p.peek()
+	return (tok.kind == tokWord || tok.kind == tokSymbol) && tok.text == s
+}
+
+// accept consumes the next item if it is s.
+func (p *moduleParser) accept(s string) bool {
+	if p.is(s) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *moduleParser) expect(s string) moduleToken {
+	if !p.is(s) {
+		p.unexpected("expected " + s)
+	}
+	return p.next()
+}
+
+// unexpected reports the next item as unexpected.
+func (p *moduleParser) unexpected(context string) {
+	tok := p.peek()
+	p.errorf(tok.off, "%s, found %s", context, tok)
+}
+
+func (p *moduleParser) typeReference() moduleToken {
+	if !p.peek().isTypeReference() {
+		p.unexpected("expected type reference")
+	}
+	return p.next()
+}
+
+func (p *moduleParser) identifier() moduleToken {
+	if !p.peek().isIdentifier() {
+		p.unexpected("expected identifier")
+	}
+	return p.next()
+}
+
+// number parses a number, with an optional minus sign.
+func (p *moduleParser) number() int64 {
+	off := p.peek().off
+	s := ""
+	if p.accept("-") {
+		s = "-"
+	}
+	tok := p.next()
+	if tok.kind != tokNumber {
+		p.errorf(tok.off, "expected number, found %s", tok)
+	}
+	n, err := strconv.ParseInt(s+tok.text, 10, 64)
+	if err != nil {
+		p.errorf(off, "invalid number %s", s+tok.text)
+	}
+	return n
+}
+
+// module parses the module definition.
+func (p *moduleParser) module() {
+	m := p.m
+	m.Name = p.typeReference().text
+	if p.is("{") {
+		m.Identifier = p.objectIdentifier()
+	}
+	p.expect("DEFINITIONS")
+	for p.is("EXPLICIT") || p.is("IMPLICIT") || p.is("AUTOMATIC") {
+		switch p.next().text {
+		case "IMPLICIT":
+			m.TagDefault = ImplicitTags
+		case "AUTOMATIC":
+			m.TagDefault = AutomaticTags
+		}
+		p.expect("TAGS")
+	}
+	if p.accept("EXTENSIBILITY") {
+		p.expect("IMPLIED")
+		m.ExtensibilityImplied = true
+	}
+	p.expect("::=")
+	p.expect("BEGIN")
+	if p.accept("EXPORTS") {
+		if !p.accept("ALL") {
+			m.ExportsAll = false
+			m.Exports = p.symbols(";")
+			if m.Exports == nil {
+				m.Exports = []string{}
+			}
+		}
+		p.expect(";")
+	}
+	if p.accept("IMPORTS") {
+		p.imports()
+	}
+	for !p.accept("END") {
+		if p.peek().kind == tokEOF {
+			p.unexpected("expected END")
+		}
+		p.assignment()
+	}
+}
+
+// symbols parses a list of symbols, separated by commas, up to the
+// reserved word or symbol end.
+func (p *moduleParser) symbols(end string) []string {
+	var syms []string
+	for !p.is(end) {
+		tok := p.next()
+		if tok.kind != tokWord {
+			p.errorf(tok.off, "expected symbol, found %s", tok)
+		}
+		// A parameterized reference is written Name{}.
+		if p.accept("{") {
+			p.expect("}")
+		}
+		syms = append(syms, tok.text)
+		if !p.accept(",") {
+			break
+		}
+	}
+	return syms
+}
+
+// imports parses the symbols imported from other modules.
+func (p *moduleParser) imports() {
+	for !p.accept(";") {
+		first := p.pos
+		syms := p.symbols("FROM")
+		for _, tok := range p.toks[first:p.pos] {
+			if _, ok := p.offsets[tok.text]; !ok && tok.kind == tokWord {
+				p.offsets[tok.text] = tok.off
+			}
+		}
+		if len(syms) == 0 {
+			p.unexpected("expected symbol")
+		}
+		p.expect("FROM")
+		imp := Import{Symbols: syms, Module: p.typeReference().text}
+
+		// The module may be followed by its object identifier, or by a
+		// value reference to it, which is told apart from the first
+		// symbol of the next import by what follows it.
+		start := p.pos
+		switch {
+		case p.is("{"):
+			p.skipBalanced()
+		case p.peek().isIdentifier() && !(p.peekAt(1).kind == tokSymbol && p.peekAt(1).text == ",") &&
+			!(p.peekAt(1).kind == tokWord && p.peekAt(1).text == "FROM"):
+			p.next()
+		}
+		if p.pos > start {
+			p.idents = append(p.idents, pendingIdent{len(p.m.Imports), p.toks[start:p.pos]})
+		}
+		for _, s := range syms {
+			if _, dup := p.imported[s]; !dup {
+				p.imported[s] = imp.Module
+			}
+		}
+		p.m.Imports = append(p.m.Imports, imp)
+	}
+}
+
+// skipBalanced skips the next item, an opening brace or parenthesis, up to
+// the matching closing one.
+func (p *moduleParser) skipBalanced() {
+	var stack []string
+	for {
+		tok := p.next()
+		switch tok.text {
+		case "{":
+			stack = append(stack, "}")
+		case "(":
+			stack = append(stack, ")")
+		case "}", ")":
+			if len(stack) == 0 || stack[len(stack)-1] != tok.text {
+				p.errorf(tok.off, "unexpected %s", tok)
+			}
+			stack = stack[:len(stack)-1]
+		}
+		if tok.kind == tokEOF {
+			p.errorf(tok.off, "unexpected end of input")
+		}
+		if len(stack) == 0 {
+			return
+		}
+	}
+}
+
+// assignment parses a type or value assignment.
+func (p *moduleParser) assignment() {
+	tok := p.next()
+	switch {
+	case tok.isTypeReference():
+		if p.is("{") {
+			p.paramAssignment(tok)
+			return
+		}
+		if !p.is("::=") {
+			p.setAssignment(tok)
+			return
+		}
+		p.next()
+		p.define(tok)
+		if p.is("CLASS") || p.is("TYPE-IDENTIFIER") || p.is("ABSTRACT-SYNTAX") {
+			p.classes[tok.text] = p.objectClass()
+			return
+		}
+		t := p.typ()
+		t.Name = tok.text
+		p.types[tok.text] = t
+		p.names = append(p.names, tok.text)
+
+	case tok.isIdentifier():
+		if p.is("{") {
+			p.errorf(tok.off, "parameterized value %s is not supported", tok.text)
+		}
+		if _, dup := p.values[tok.text]; dup {
+			p.errorf(tok.off, "duplicate value assignment %s", tok.text)
+		}
+		// Objects are assigned like values, with a class for their type.
+		def := &valueDef{name: tok.text, typ: p.typ()}
+		p.expect("::=")
+		start := p.pos
+		p.skipValue()
+		def.toks = p.toks[start:p.pos]
+		p.values[tok.text] = def
+		p.offsets[tok.text] = tok.off
+		p.defs = append(p.defs, def)
+
+	default:
+		p.errorf(tok.off, "expected assignment, found %s", tok)
+	}
+}
+
+// define records the assignment of the type, class or set reference tok.
+func (p *moduleParser) define(tok moduleToken) {
+	_, dup := p.types[tok.text]
+	if _, ok := p.classes[tok.text]; ok {
+		dup = true
+	}
+	if _, ok := p.paramTypes[tok.text]; ok {
+		dup = true
+	}
+	if dup {
+		p.errorf(tok.off, "duplicate type assignment %s", tok.text)
+	}
+	p.offsets[tok.text] = tok.off
+}
+
+// setAssignment parses a value set or object set assignment, such as
+// SupportedAttributes ATTRIBUTE ::= { name | surname }, after its
+// reference tok. Which of the two it is depends on whether the governor is
+// a class, and is settled by classify.
+func (p *moduleParser) setAssignment(tok moduleToken) {
+	t := p.typ()
+	p.expect("::=")
+	p.define(tok)
+	start := p.pos
+	if !p.is("{") {
+		p.unexpected("expected {")
+	}
+	p.skipBalanced()
+	t.Name = tok.text
+	p.types[tok.text] = t
+	p.names = append(p.names, tok.text)
+	p.sets = append(p.sets, valueSet{tok, t, p.toks[start:p.pos]})
+}
+
+// paramAssignment parses a parameterized type assignment after its
+// reference tok. The type is parsed with its formal parameters unbound, to
+// check it, and again for each instance.
+func (p *moduleParser) paramAssignment(tok moduleToken) {
+	pt := &paramType{name: tok.text}
+	p.expect("{")
+	for {
+		// A parameter may have a governor, as in INTEGER:ub or
+		// ATTRIBUTE:AttrSet.
+		start := p.pos
+		for !p.is(",") && !p.is("}") {
+			if p.peek().kind == tokEOF {
+				p.unexpected("expected }")
+			}
+			p.next()
+		}
+		toks := p.toks[start:p.pos]
+		if len(toks) == 0 {
+			p.unexpected("expected parameter")
+		}
+		param := toks[len(toks)-1]
+		if param.kind != tokWord || len(toks) > 1 && toks[len(toks)-2].text != ":" {
+			p.errorf(param.off, "expected parameter, found %s", param)
+		}
+		for _, name := range pt.params {
+			if name == param.text {
+				p.errorf(param.off, "duplicate parameter %s", name)
+			}
+		}
+		pt.params = append(pt.params, param.text)
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect("}")
+	if !p.is("::=") {
+		p.errorf(tok.off, "parameterized value set and object set assignments are not supported")
+	}
+	p.next()
+	p.define(tok)
+
+	formal := make(map[string]actualParam)
+	for _, name := range pt.params {
+		formal[name] = actualParam{}
+	}
+	later, defaults, instances := len(p.later), len(p.defaults), len(p.l.instances)
+	start := p.pos
+	p.params = formal
+	p.typ()
+	p.params = nil
+	p.later, p.defaults, p.l.instances = p.later[:later], p.defaults[:defaults], p.l.instances[:instances]
+	pt.toks = p.toks[start:p.pos]
+	p.paramTypes[tok.text] = pt
+}
+
+// objectClass parses an information object class definition, or one of the
+// classes TYPE-IDENTIFIER and ABSTRACT-SYNTAX of X.681. The syntax of its
+// objects, WITH SYNTAX, is checked for syntax only.
+func (p *moduleParser) objectClass() *objectClass {
+	c := &objectClass{p: p, fields: make(map[string]*Type)}
+	switch p.next().text {
+	case "TYPE-IDENTIFIER":
+		c.fields["&id"] = &Type{Kind: KindObjectIdentifier}
+		c.fields["&Type"] = nil
+		return c
+	case "ABSTRACT-SYNTAX":
+		c.fields["&id"] = &Type{Kind: KindObjectIdentifier}
+		c.fields["&Type"] = nil
+		c.fields["&property"] = &Type{Kind: KindBitString}
+		return c
+	}
+	p.expect("{")
+	for {
+		tok := p.next()
+		if tok.kind != tokWord || tok.text[0] != '&' {
+			p.errorf(tok.off, "expected field, found %s", tok)
+		}
+		if _, dup := c.fields[tok.text]; dup {
+			p.errorf(tok.off, "duplicate field %s", tok.text)
+		}
+		var t *Type
+		switch next := p.peek(); {
+		case p.is(",") || p.is("}") || p.is("OPTIONAL") || p.is("DEFAULT"):
+			// A type field.
+		case next.kind == tokWord && next.text[0] == '&':
+			// A value field of the type of a type field.
+			p.next()
+		default:
+			t = p.typ()
+		}
+		p.accept("UNIQUE")
+		switch {
+		case p.accept("OPTIONAL"):
+		case p.accept("DEFAULT"):
+			if t == nil && isUpper(tok.text[1]) {
+				p.typ()
+			} else {
+				p.skipValue()
+			}
+		}
+		c.fields[tok.text] = t
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect("}")
+	if p.accept("WITH") {
+		p.expect("SYNTAX")
+		if !p.is("{") {
+			p.unexpected("expected {")
+		}
+		p.skipBalanced()
+	}
+	return c
+}
+
+// skipValue skips the notation of a value.
+func (p *moduleParser) skipValue() {
+	switch tok := p.peek(); {
+	case p.is("{"):
+		p.skipBalanced()
+	case p.accept("-"):
+		if tok := p.next(); tok.kind != tokNumber {
+			p.errorf(tok.off, "expected number, found %s", tok)
+		}
+	case tok.kind == tokWord:
+		p.next()
+		// A CHOICE value is written alternative : value.
+		if tok.isIdentifier() && p.accept(":") {
+			p.skipValue()
+		}
+	case tok.kind == tokNumber || tok.kind == tokCString || tok.kind == tokBString || tok.kind == tokHString:
+		p.next()
+	default:
+		p.unexpected("expected value")
+	}
+}
+
+// explicit reports whether a tag is explicit, given the reserved word that
+// follows it, if any.
+func (p *moduleParser) explicit() bool {
+	switch {
+	case p.accept("EXPLICIT"):
+		return true
+	case p.accept("IMPLICIT"):
+		return false
+	}
+	return p.m.TagDefault == ExplicitTags
+}
+
+// typ parses a type, with its tags and constraints.
+func (p *moduleParser) typ() *Type {
+	if p.is("[") {
+		p.next()
+		tt := TypeTag{Tag: Tag{Class: ClassContextSpecific}}
+		switch {
+		case p.accept("UNIVERSAL"):
+			tt.Class = ClassUniversal
+		case p.accept("APPLICATION"):
+			tt.Class = ClassApplication
+		case p.accept("PRIVATE"):
+			tt.Class = ClassPrivate
+		}
+		off := p.peek().off
+		n := p.number()
+		if n < 0 || n > 1<<31-1 {
+			p.errorf(off, "invalid tag number %d", n)
+		}
+		tt.Number = int(n)
+		p.expect("]")
+		tt.Explicit = p.explicit()
+		t := p.typ()
+		t.Tags = append([]TypeTag{tt}, t.Tags...)
+		return t
+	}
+	t := p.builtinType()
+	for p.is("(") {
+		p.constraint(t)
+	}
+	return t
+}
+
+// builtinType parses a built-in type or a type reference.
+func (p *moduleParser) builtinType() *Type {
+	tok := p.next()
+	if tok.kind != tokWord {
+		p.errorf(tok.off, "expected type, found %s", tok)
+	}
+	switch tok.text {
+	case "BOOLEAN":
+		return &Type{Kind: KindBoolean}
+	case "NULL":
+		return &Type{Kind: KindNull}
+	case "REAL":
+		return &Type{Kind: KindReal}
+	case "INTEGER":
+		t := &Type{Kind: KindInteger}
+		if p.is("{") {
+			p.namedNumbers(t)
+		}
+		return t
+	case "ENUMERATED":
+		t := &Type{Kind: KindEnumerated}
+		p.namedNumbers(t)
+		return t
+	case "BIT":
+		p.expect("STRING")
+		t := &Type{Kind: KindBitString}
+		if p.is("{") {
+			p.namedNumbers(t)
+		}
+		return t
+	case "OCTET":
+		p.expect("STRING")
+		return &Type{Kind: KindOctetString}
+	case "OBJECT":
+		p.expect("IDENTIFIER")
+		return &Type{Kind: KindObjectIdentifier}
+	case "EMBEDDED":
+		p.expect("PDV")
+		st, err := TypeOf(EmbeddedPDV{})
+		if err != nil {
+			panic(err)
+		}
+		return st
+	case "RELATIVE-OID":
+		return &Type{Kind: KindRelativeOID}
+	case "OID-IRI":
+		return &Type{Kind: KindOIDIRI}
+	case "RELATIVE-OID-IRI":
+		return &Type{Kind: KindRelativeOIDIRI}
+	case "ANY":
+		if p.accept("DEFINED") {
+			p.expect("BY")
+			p.identifier()
+		}
+		return &Type{Kind: KindAny}
+	case "SEQUENCE", "SET":
+		kind, collection := KindSequence, KindSequenceOf
+		if tok.text == "SET" {
+			kind, collection = KindSet, KindSetOf
+		}
+		if p.is("{") {
+			t := &Type{Kind: kind}
+			p.components(t)
+			return t
+		}
+		t := &Type{Kind: collection}
+		switch {
+		case p.is("SIZE"):
+			p.sizeConstraint(t)
+		case p.is("("):
+			p.constraint(t)
+		}
+		p.expect("OF")
+		// The elements may be named, as in SEQUENCE OF item Item.
+		if p.peek().isIdentifier() && !(p.peekAt(1).kind == tokSymbol && p.peekAt(1).text == "<") {
+			p.next()
+		}
+		t.Elem = p.typ()
+		return t
+	case "CHOICE":
+		t := &Type{Kind: KindChoice}
+		p.components(t)
+		return t
+	case "INSTANCE":
+		// INSTANCE OF is the type X.681 gives it, with the type-id
+		// identifying the type of the value.
+		p.expect("OF")
+		class := p.typeReference()
+		value := &Type{Kind: KindAny, Tags: []TypeTag{{Tag{ClassContextSpecific, 0}, true}}}
+		return &Type{
+			Kind: KindSequence,
+			Tags: []TypeTag{{Tag: Tag{ClassUniversal, 8}}},
+			Components: []Component{
+				{Name: "type-id", Type: p.fieldType(class, moduleToken{kind: tokWord, text: "&id", off: class.off})},
+				{Name: "value", Type: value},
+			},
+		}
+	case "CLASS", "TYPE-IDENTIFIER", "ABSTRACT-SYNTAX":
+		p.errorf(tok.off, "information object class must be assigned to a reference")
+	}
+	if kind, ok := stringKinds[tok.text]; ok {
+		return &Type{Kind: kind}
+	}
+	if unsupportedTypes[tok.text] {
+		p.errorf(tok.off, "type %s is not supported", tok.text)
+	}
+	if tok.isIdentifier() && p.is("<") {
+		p.errorf(tok.off, "selection types are not supported")
+	}
+	if !tok.isTypeReference() {
+		p.errorf(tok.off, "expected type, found %s", tok)
+	}
+	r := typeRef{p: p, name: tok.text, off: tok.off}
+	if p.is(".") {
+		p.next()
+		if p.peek().kind == tokWord && p.peek().text[0] == '&' {
+			return p.fieldType(tok, p.next())
+		}
+		r.module, r.name = r.name, p.typeReference().text
+	} else if a, ok := p.params[tok.text]; ok {
+		return p.actualType(a)
+	}
+	if p.is("{") {
+		return p.instance(r)
+	}
+	t := &Type{Name: r.name}
+	p.l.refs[t] = r
+	return t
+}
+
+// fieldType returns the type of the field of the class, as in
+// ATTRIBUTE.&id. A type field, or a value field of the type of one, is an
+// open type of KindAny; the type of a fixed-type value field is resolved
+// with the type references.
+func (p *moduleParser) fieldType(class, field moduleToken) *Type {
+	if p.is(".") {
+		p.errorf(p.peek().off, "fields of object fields are not supported")
+	}
+	if a, ok := p.params[class.text]; ok {
+		// A class given as a parameter.
+		if a = a.resolved(); a.p == nil {
+			return &Type{}
+		}
+		if len(a.toks) != 1 || !a.toks[0].isTypeReference() {
+			p.errorf(a.toks[0].off, "expected class, found %s", a.toks[0])
+		}
+		return a.p.fieldType(a.toks[0], field)
+	}
+	if isUpper(field.text[1]) {
+		return &Type{Kind: KindAny}
+	}
+	t := &Type{Name: class.text + "." + field.text}
+	p.l.refs[t] = typeRef{p: p, name: class.text, field: field.text, off: field.off}
+	return t
+}
+
+// actualType parses the actual parameter a, written where a type is
+// expected.
+func (p *moduleParser) actualType(a actualParam) *Type {
+	if a.p == nil {
+		return &Type{}
+	}
+	var t *Type
+	a.p.within(a.toks, a.params, func() { t = a.p.typ() })
+	return t
+}
+
+// resolved returns the actual parameter that a stands for, following
+// actual parameters that are the formal parameters of the instance they
+// are written in.
+func (a actualParam) resolved() actualParam {
+	for a.p != nil && len(a.toks) == 1 {
+		outer, ok := a.params[a.toks[0].text]
+		if !ok {
+			break
+		}
+		a = outer
+	}
+	return a
+}
+
+// instance parses the actual parameters of a use of the parameterized type
+// r, and returns the type that stands for the instance until instantiate
+// replaces it.
+func (p *moduleParser) instance(r typeRef) *Type {
+	in := &instance{p: p, r: r, params: p.params, depth: p.l.depth}
+	p.expect("{")
+	for {
+		// An actual parameter is a type, a value, or an object set or
+		// value set in braces.
+		start := p.pos
+		for !p.is(",") && !p.is("}") {
+			switch {
+			case p.is("{"), p.is("("):
+				p.skipBalanced()
+			case p.peek().kind == tokEOF:
+				p.unexpected("expected }")
+			default:
+				p.next()
+			}
+		}
+		if p.pos == start {
+			p.unexpected("expected actual parameter")
+		}
+		in.args = append(in.args, p.toks[start:p.pos])
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect("}")
+	in.t = &Type{Name: r.name}
+	p.l.instances = append(p.l.instances, in)
+	return in.t
+}
+
+// components parses the components of a SEQUENCE or SET, or the
+// alternatives of a CHOICE, with their extension markers and extension
+// additions.
+func (p *moduleParser) components(t *Type) {
+	p.expect("{")
+	t.Extensible = p.m.ExtensibilityImplied
+	tagged := false
+	markers := 0 // extension markers so far
+	groups := 0  // version brackets so far
+	for !p.is("}") {
+		switch tok := p.peek(); {
+		case p.accept("..."):
+			// The components after a second extension marker are
+			// components of the root again.
+			if markers++; markers > 2 {
+				p.errorf(tok.off, "more than two extension markers")
+			}
+			t.Extensible = true
+			if markers == 1 && p.accept("!") {
+				p.exception()
+			}
+		case p.accept("[["):
+			if markers != 1 {
+				p.errorf(tok.off, "version brackets outside the extension additions")
+			}
+			groups++
+			if p.peek().kind == tokNumber {
+				p.next()
+				p.expect(":")
+			}
+			for {
+				tagged = p.component(t, true, groups) || tagged
+				if !p.accept(",") {
+					break
+				}
+			}
+			p.expect("]]")
+		case p.is("COMPONENTS"):
+			p.errorf(tok.off, "COMPONENTS OF is not supported")
+		default:
+			tagged = p.component(t, markers == 1, 0) || tagged
+		}
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect("}")
+	if p.m.TagDefault == AutomaticTags && !tagged {
+		// The components of the root are numbered first, and then the
+		// extension additions.
+		n := 0
+		for _, extension := range []bool{false, true} {
+			for i := range t.Components {
+				if c := &t.Components[i]; c.Extension == extension {
+					c.Type.Tags = append([]TypeTag{{Tag: Tag{ClassContextSpecific, n}}}, c.Type.Tags...)
+					n++
+				}
+			}
+		}
+	}
+}
+
+// component parses a component of a SEQUENCE or SET, or an alternative of
+// a CHOICE, and appends it to those of t as an extension addition in the
+// version brackets group, if extension is set. It reports whether the
+// component is tagged.
+func (p *moduleParser) component(t *Type, extension bool, group int) bool {
+	name := p.identifier()
+	if t.Component(name.text) != nil {
+		p.errorf(name.off, "duplicate identifier %s", name.text)
+	}
+	tagged := p.is("[")
+	c := Component{Name: name.text, Type: p.typ(), Extension: extension}
+	if extension {
+		c.Group = group
+	}
+	if t.Kind != KindChoice {
+		switch {
+		case p.accept("OPTIONAL"):
+			c.Optional = true
+		case p.accept("DEFAULT"):
+			start := p.pos
+			p.skipValue()
+			p.defaults = append(p.defaults, pendingDefault{t, len(t.Components), p.toks[start:p.pos]})
+		}
+	}
+	t.Components = append(t.Components, c)
+	return tagged
+}
+
+// exception skips the exception identification after the ! of an
+// exception specification: a number, a value reference or Type : Value.
+func (p *moduleParser) exception() {
+	if tok := p.peek(); tok.kind == tokWord && tok.isTypeReference() || reservedWords[tok.text] {
+		p.typ()
+		p.expect(":")
+	}
+	p.skipValue()
+}
+
+// A namedItem is a named number or enumeration item, whose value is a
+// number, a value reference or, for enumeration items, omitted.
+type namedItem struct {
+	name      moduleToken
+	val       []moduleToken
+	extension bool // an extension addition of an ENUMERATED type
+}
+
+// namedNumbers parses the named numbers of an INTEGER or BIT STRING, or
+// the items of an ENUMERATED type.
+func (p *moduleParser) namedNumbers(t *Type) {
+	p.expect("{")
+	var items []namedItem
+	extension := false
+	if t.Kind == KindEnumerated {
+		t.Extensible = p.m.ExtensibilityImplied
+	}
+	for {
+		if t.Kind == KindEnumerated && !extension && p.accept("...") {
+			extension = true
+			t.Extensible = true
+			if p.accept("!") {
+				p.exception()
+			}
+			if !p.accept(",") {
+				break
+			}
+			continue
+		}
+		item := namedItem{name: p.identifier(), extension: extension}
+		for _, prev := range items {
+			if prev.name.text == item.name.text {
+				p.errorf(item.name.off, "duplicate identifier %s", item.name.text)
+			}
+		}
+		if p.accept("(") {
+			start := p.pos
+			if !p.accept("-") && !p.peek().isIdentifier() && p.peek().kind != tokNumber {
+				p.unexpected("expected number")
+			}
+			p.next()
+			item.val = p.toks[start:p.pos]
+			p.expect(")")
+		} else if t.Kind != KindEnumerated {
+			p.unexpected("expected (")
+		}
+		items = append(items, item)
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect("}")
+
+	p.later = append(p.later, func() {
+		used := make(map[int64]bool)
+		for _, item := range items {
+			if item.val != nil {
+				n := p.integerOf(item.val)
+				if used[n] {
+					p.errorf(item.name.off, "duplicate value %d of %s", n, item.name.text)
+				}
+				used[n] = true
+				t.Named = append(t.Named, NamedNumber{item.name.text, n, item.extension})
+			} else {
+				t.Named = append(t.Named, NamedNumber{Name: item.name.text, Value: -1, Extension: item.extension})
+			}
+		}
+		// Enumeration items of the root without numbers take the
+		// smallest numbers not otherwise used, in order. Extension
+		// additions without numbers take the smallest unused number
+		// above that of the addition before them.
+		next := int64(0)
+		prev := int64(-1)
+		for i, item := range items {
+			if item.extension {
+				if item.val == nil {
+					n := prev + 1
+					for used[n] {
+						n++
+					}
+					t.Named[i].Value = n
+					used[n] = true
+				}
+				prev = t.Named[i].Value
+				continue
+			}
+			if item.val == nil {
+				for used[next] {
+					next++
+				}
+				t.Named[i].Value = next
+				used[next] = true
+			}
+		}
+	})
+}
+
+// integerOf returns the value of an integer number, written as a number,
+// a negative number or a value reference.
+func (p *moduleParser) integerOf(toks []moduleToken) int64 {
+	tok := toks[0]
+	if tok.kind == tokWord && tok.isIdentifier() {
+		return p.integerValue(tok)
+	}
+	s := tok.text
+	if tok.text == "-" {
+		tok = toks[1]
+		s += tok.text
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		p.errorf(tok.off, "invalid number %s", s)
+	}
+	return n
+}
+
+// integerValue returns the value of the INTEGER value reference ref. It
+// looks no further than the notation of the value, so that it can be used
+// before the types are resolved.
+func (p *moduleParser) integerValue(ref moduleToken) int64 {
+	q, def := p.valueDef(ref)
+	toks := def.toks
+	if toks[0].isIdentifier() && len(toks) == 1 {
+		if def.state == 1 {
+			p.errorf(ref.off, "circular definition of %s", def.name)
+		}
+		def.state = 1
+		defer func() { def.state = 0 }()
+	} else if toks[0].kind != tokNumber && toks[0].text != "-" {
+		p.errorf(ref.off, "%s is not an INTEGER value", ref.text)
+	}
+	return q.integerOf(toks)
+}
+
+// A bound is a bound of a value range or SIZE constraint: a number, a
+// value reference, MIN or MAX.
+type bound struct {
+	toks []moduleToken
+	open bool          // the bound is excluded, as in 0<..10
+	p    *moduleParser // module of the items, if it is not the constraint's
+}
+
+// A rangeSpec is a single value, with hi unset, or a value range of a
+// constraint.
+type rangeSpec struct {
+	lo, hi bound
+}
+
+// constraint parses a constraint in parentheses after a type.
+func (p *moduleParser) constraint(t *Type) {
+	p.expect("(")
+	p.constraintSpec(t, false)
+	p.expect(")")
+}
+
+// constraintSpec parses a constraint specification with an optional
+// extension marker and records the value range, or SIZE range if size is
+// set, and the permitted alphabet that it permits in t.
+func (p *moduleParser) constraintSpec(t *Type, size bool) {
+	var ranges []rangeSpec
+	var from alphabetSpec
+	sized := false
+	extensible := false
+	if p.accept("...") {
+		extensible = true
+	} else {
+		sized = p.elementSet(t, size, &ranges, &from)
+		if p.accept(",") {
+			p.expect("...")
+			extensible = true
+		}
+	}
+	if extensible && p.accept(",") {
+		var additions []rangeSpec
+		p.elementSet(t, size, &additions, nil)
+	}
+	if p.accept("!") {
+		p.errorf(p.peek().off, "exception specifications are not supported")
+	}
+	// An extensible alphabet permits any character, and so does a union
+	// with an alphabet that is not a set of characters.
+	if from.chars != nil && !from.unknown && !extensible && !size {
+		t.From = from.chars
+	}
+	p.later = append(p.later, func() {
+		if len(ranges) == 0 {
+			// An extensible constraint on a SIZE constraint makes the
+			// latter extensible.
+			if extensible && sized && t.Size != nil {
+				t.Size.Extensible = true
+			}
+			return
+		}
+		r := p.hull(t, ranges)
+		r.Extensible = extensible
+		if size {
+			t.Size = r
+		} else {
+			t.Range = r
+		}
+	})
+}
+
+// elementSet parses the union, intersection or exclusion of constraint
+// elements. Values and value ranges are appended to ranges and permitted
+// alphabets to from; it reports whether there is a SIZE constraint among
+// the elements.
+func (p *moduleParser) elementSet(t *Type, size bool, ranges *[]rangeSpec, from *alphabetSpec) bool {
+	sized := false
+	for {
+		if p.accept("ALL") {
+			p.expect("EXCEPT")
+			p.element(t, size, nil, nil)
+		} else {
+			sized = p.element(t, size, ranges, from) || sized
+		}
+		switch {
+		case p.accept("|"), p.accept("UNION"):
+		case p.is("^"), p.is("INTERSECTION"), p.is("EXCEPT"):
+			// Only the first operand of an intersection or exclusion
+			// shapes the recorded ranges. The alphabets of the operands
+			// of an intersection are recorded as their union, which
+			// permits more characters than their intersection does.
+			for {
+				if p.accept("^") || p.accept("INTERSECTION") {
+					p.element(t, size, nil, from)
+				} else if p.accept("EXCEPT") {
+					p.element(t, size, nil, nil)
+				} else {
+					break
+				}
+			}
+			if !p.accept("|") && !p.accept("UNION") {
+				return sized
+			}
+		default:
+			return sized
+		}
+	}
+}
+
+// encodedBy parses the OBJECT IDENTIFIER value of the encoding rules of a
+// contents constraint on t, recording it if record is set.
+func (p *moduleParser) encodedBy(t *Type, record bool) {
+	if !p.is("{") && !p.peek().isIdentifier() {
+		p.unexpected("expected OBJECT IDENTIFIER value")
+	}
+	if p.is("{") {
+		oid := p.objectIdentifier()
+		if record {
+			t.EncodedBy = oid
+		}
+		return
+	}
+	ref := p.next()
+	if record {
+		// The value may be defined further down the module.
+		p.later = append(p.later, func() {
+			t.EncodedBy = p.objectIdentifierRef(ref)
+		})
+	}
+}
+
+// sizeConstraint parses a SIZE constraint on t.
+func (p *moduleParser) sizeConstraint(t *Type) {
+	p.expect("SIZE")
+	p.expect("(")
+	p.constraintSpec(t, true)
+	p.expect(")")
+}
+
+// element parses a constraint element. Values and value ranges are
+// appended to ranges, or dropped if ranges is nil; it reports whether the
+// element is a SIZE constraint.
+func (p *moduleParser) element(t *Type, size bool, ranges *[]rangeSpec, from *alphabetSpec) bool {
+	tok := p.peek()
+	switch {
+	case p.is("SIZE"):
+		p.sizeConstraint(t)
+		return true
+	case p.is("FROM"):
+		p.next()
+		if !p.is("(") {
+			p.unexpected("expected (")
+		}
+		chars := p.alphabet()
+		if from != nil {
+			from.chars = append(from.chars, chars...)
+			from.unknown = from.unknown || chars == nil
+		}
+		return false
+	case p.is("WITH"):
+		p.next()
+		if !p.accept("COMPONENT") {
+			p.expect("COMPONENTS")
+		}
+		if !p.is("(") && !p.is("{") {
+			p.unexpected("expected ( or {")
+		}
+		p.skipBalanced()
+		return false
+	case p.is("CONTAINING"):
+		p.next()
+		contains := p.typ()
+		if ranges != nil && (t.Kind == KindOctetString || t.Kind == KindBitString) {
+			t.Contains = contains
+		}
+		if p.accept("ENCODED") {
+			p.expect("BY")
+			p.encodedBy(t, ranges != nil)
+		}
+		return false
+	case p.is("ENCODED"):
+		p.next()
+		p.expect("BY")
+		p.encodedBy(t, ranges != nil)
+		return false
+	case p.is("PATTERN"):
+		p.next()
+		p.skipValue()
+		return false
+	case p.is("INCLUDES"):
+		p.next()
+		p.typ()
+		return false
+	case p.is("("):
+		p.next()
+		sized := p.elementSet(t, size, ranges, from)
+		p.expect(")")
+		return sized
+	case p.is("{"):
+		// A table constraint, as in ({SupportedAttributes}{@type}), is
+		// checked for syntax only.
+		p.skipBalanced()
+		if p.is("{") {
+			p.skipBalanced()
+		}
+		return false
+	case p.is("CONSTRAINED"):
+		p.next()
+		p.expect("BY")
+		if !p.is("{") {
+			p.unexpected("expected {")
+		}
+		p.skipBalanced()
+		return false
+	case tok.kind == tokWord && tok.isTypeReference():
+		// A contained subtype, as in (INCLUDES Type) without INCLUDES.
+		p.typ()
+		return false
+	}
+
+	var r rangeSpec
+	r.lo = p.boundValue()
+	if p.accept("<") {
+		r.lo.open = true
+	}
+	if p.accept("..") {
+		open := p.accept("<")
+		r.hi = p.boundValue()
+		r.hi.open = open
+	} else if r.lo.open {
+		p.unexpected("expected ..")
+	}
+	if r.lo.toks != nil && ranges != nil && (r.hi.toks != nil || !r.lo.open) {
+		*ranges = append(*ranges, r)
+	}
+	return false
+}
+
+// An alphabetSpec collects the permitted alphabets of a constraint.
+type alphabetSpec struct {
+	chars   []CharRange
+	unknown bool // some alphabet is not a set of characters
+}
+
+// alphabet parses the permitted alphabet of a FROM constraint, in
+// parentheses, and returns its characters. Alphabets other than unions of
+// cstrings and ranges of single characters, such as those given by value
+// references, are checked for syntax only and return nil.
+func (p *moduleParser) alphabet() []CharRange {
+	start := p.pos
+	p.expect("(")
+	chars := []CharRange{}
+	for {
+		tok := p.next()
+		if tok.kind != tokCString {
+			break
+		}
+		s := []rune(cstringText(tok))
+		if p.accept("..") {
+			hi := p.next()
+			h := []rune(cstringText(hi))
+			if len(s) != 1 || hi.kind != tokCString || len(h) != 1 {
+				break
+			}
+			chars = append(chars, CharRange{s[0], h[0]})
+		} else {
+			for _, r := range s {
+				chars = append(chars, CharRange{r, r})
+			}
+		}
+		switch {
+		case p.accept("|"), p.accept("UNION"):
+			continue
+		case p.accept(")"):
+			return chars
+		}
+		break
+	}
+	p.pos = start
+	p.skipBalanced()
+	return nil
+}
+
+// cstringText returns the characters of the cstring tok.
+func cstringText(tok moduleToken) string {
+	if tok.kind != tokCString {
+		return ""
+	}
+	return unquoteCString([]byte(tok.text))
+}
+
+// boundValue parses a value of a constraint, returning it if it is a bound
+// of a range: a number, a value reference, MIN or MAX.
+func (p *moduleParser) boundValue() bound {
+	start := p.pos
+	switch tok := p.peek(); {
+	case p.is("MIN"), p.is("MAX"):
+		p.next()
+	case p.is("-"):
+		p.next()
+		if p.peek().kind != tokNumber {
+			p.unexpected("expected number")
+		}
+		p.next()
+	case tok.kind == tokNumber && !strings.ContainsAny(tok.text, ".eE"):
+		p.next()
+	case tok.isIdentifier() && !(p.peekAt(1).kind == tokSymbol && p.peekAt(1).text == ":"):
+		p.next()
+		if a, ok := p.params[tok.text]; ok {
+			// The bound is given by the instance, and is dropped while
+			// the parameterized type is checked.
+			a = a.resolved()
+			return bound{toks: a.toks, p: a.p}
+		}
+	default:
+		// Other values, such as the strings of a permitted alphabet,
+		// are checked for syntax only.
+		p.skipValue()
+		return bound{}
+	}
+	return bound{toks: p.toks[start:p.pos]}
+}
+
+// hull returns the smallest range that includes the values and ranges rs
+// of a constraint on t.
+func (p *moduleParser) hull(t *Type, rs []rangeSpec) *Range {
+	var h *Range
+	for _, r := range rs {
+		lo, loInf := p.bound(t, r.lo, "MIN")
+		hi, hiInf := lo, loInf
+		if r.hi.toks != nil {
+			hi, hiInf = p.bound(t, r.hi, "MAX")
+		}
+		if r.lo.open {
+			lo++
+		}
+		if r.hi.open {
+			hi--
+		}
+		if h == nil {
+			h = &Range{Min: lo, Max: hi, NoMin: loInf, NoMax: hiInf}
+			continue
+		}
+		if loInf || !h.NoMin && lo < h.Min {
+			h.Min, h.NoMin = lo, loInf
+		}
+		if hiInf || !h.NoMax && hi > h.Max {
+			h.Max, h.NoMax = hi, hiInf
+		}
+	}
+	return h
+}
+
+// bound returns the value of a bound of a constraint on t. It reports true
+// for the unbounded end inf, MIN or MAX.
+func (p *moduleParser) bound(t *Type, b bound, inf string) (int64, bool) {
+	tok := b.toks[0]
+	switch {
+	case tok.text == "MIN" || tok.text == "MAX":
+		if tok.text != inf {
+			p.errorf(tok.off, "%s is not a valid bound here", tok.text)
+		}
+		return 0, true
+	case tok.isIdentifier():
+		if n, ok := t.NamedValue(tok.text); ok {
+			return n, false
+		}
+	}
+	if b.p != nil {
+		return b.p.integerOf(b.toks), false
+	}
+	return p.integerOf(b.toks), false
+}
+
+// checkSymbols checks that the module defines the symbols it exports and,
+// if the imported modules are known, that they export the symbols it
+// imports.
+func (p *moduleParser) checkSymbols() {
+	for _, sym := range p.m.Exports {
+		if _, ok := p.offsets[sym]; !ok {
+			p.errorf(p.toks[0].off, "exported symbol %s is not defined", sym)
+		}
+	}
+	if !p.l.complete {
+		return
+	}
+	for sym, mod := range p.imported {
+		p.exporter(mod, sym, p.offsets[sym])
+	}
+}
+
+// exporter returns the parser of the module mod, which the module imports
+// the symbol sym from at offset off, or nil if it is not known.
+func (p *moduleParser) exporter(mod, sym string, off int) *moduleParser {
+	q := p.l.parsers[mod]
+	if q == nil {
+		if p.l.complete {
+			p.errorf(off, "module %s of imported symbol %s is missing", mod, sym)
+		}
+		return nil
+	}
+	if _, ok := q.offsets[sym]; !ok {
+		p.errorf(off, "module %s has no symbol %s", mod, sym)
+	}
+	if !q.m.ExportsAll && !contains(q.m.Exports, sym) {
+		p.errorf(off, "module %s does not export %s", mod, sym)
+	}
+	return q
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// linkTypes resolves the type references of the module and fills in its
+// types.
+func (p *moduleParser) linkTypes() {
+	for _, name := range p.names {
+		t := p.lookup(typeRef{p: p, name: name, off: p.offsets[name]})
+		p.fix(t)
+		p.m.Types = append(p.m.Types, t)
+	}
+}
+
+// linkValues converts the values of the module, which may refer to the
+// types and values of the other modules, and fills them in.
+func (p *moduleParser) linkValues() {
+	for _, def := range p.defs {
+		if !def.object {
+			p.valueOf(def)
+		}
+	}
+	for _, d := range p.defaults {
+		c := &d.t.Components[d.i]
+		c.Default = p.value(c.Type, d.toks)
+	}
+	for _, id := range p.idents {
+		p.m.Imports[id.i].Identifier = p.objectIdentifierValue(id.toks)
+	}
+	for _, def := range p.defs {
+		if !def.object {
+			p.m.Values = append(p.m.Values, ValueAssignment{def.name, def.typ.String(), def.value})
+		}
+	}
+}
+
+// lookup returns the type a type reference of the module refers to, or
+// nil if it refers to an imported type of a module that is not known.
+func (p *moduleParser) lookup(r typeRef) *Type {
+	if r.target != nil {
+		return r.target
+	}
+	if r.field != "" {
+		return p.classField(r)
+	}
+	mod := r.module
+	t, ok := p.types[r.name]
+	if mod == p.m.Name {
+		mod = ""
+	} else if mod == "" && !ok {
+		mod, ok = p.imported[r.name]
+		if !ok {
+			if _, ok := p.paramTypes[r.name]; ok {
+				p.errorf(r.off, "parameterized type %s is used without parameters", r.name)
+			}
+			p.errorf(r.off, "undefined type %s", r.name)
+		}
+	}
+	if mod != "" {
+		q := p.exporter(mod, r.name, r.off)
+		if q == nil {
+			return nil
+		}
+		if p.busy[r.name] {
+			p.errorf(r.off, "circular import of %s", r.name)
+		}
+		p.busy[r.name] = true
+		defer delete(p.busy, r.name)
+		return q.lookup(typeRef{p: q, name: r.name, off: q.offsets[r.name]})
+	}
+	if _, alias := p.l.refs[t]; alias {
+		// Resolve the alias in its place.
+		if p.busy[r.name] {
+			p.errorf(r.off, "circular definition of %s", r.name)
+		}
+		p.busy[r.name] = true
+		t = p.resolve(t)
+		delete(p.busy, r.name)
+		p.types[r.name] = t
+	}
+	return t
+}
+
+// resolve returns the type t refers to if it is a type reference, and t
+// otherwise. A reference with tags, constraints or a name of its own
+// becomes a copy of the type it refers to.
+func (p *moduleParser) resolve(t *Type) *Type {
+	r, ok := p.l.refs[t]
+	if !ok {
+		return t
+	}
+	target := r.p.lookup(r)
+	if target == nil {
+		return t
+	}
+	if t.Name == r.name && len(t.Tags) == 0 && t.Size == nil && t.Range == nil && t.From == nil && t.Contains == nil {
+		return target
+	}
+	c := *target
+	c.Name = t.Name
+	c.Tags = append(t.Tags[:len(t.Tags):len(t.Tags)], target.Tags...)
+	if t.Size != nil {
+		c.Size = t.Size
+	}
+	if t.Range != nil {
+		c.Range = t.Range
+	}
+	if t.From != nil {
+		c.From = t.From
+	}
+	if t.Contains != nil {
+		c.Contains, c.EncodedBy = t.Contains, t.EncodedBy
+	}
+	return &c
+}
+
+// fix replaces the type references among the components and elements of
+// t, and of the types they contain, by the types they refer to.
+func (p *moduleParser) fix(t *Type) {
+	if p.l.resolved[t] {
+		return
+	}
+	p.l.resolved[t] = true
+	for i := range t.Components {
+		c := &t.Components[i]
+		c.Type = p.resolve(c.Type)
+		p.fix(c.Type)
+	}
+	if t.Elem != nil {
+		t.Elem = p.resolve(t.Elem)
+		p.fix(t.Elem)
+	}
+	if t.Contains != nil {
+		t.Contains = p.resolve(t.Contains)
+		p.fix(t.Contains)
+	}
+}
+
+// classify tells the classes, objects and object sets of the module apart
+// from its types, values and value sets, which are assigned in the same
+// notation, and parses the value sets.
+func (p *moduleParser) classify() {
+	names := p.names[:0]
+	for _, name := range p.names {
+		if c := p.class(name, p.offsets[name]); c != nil {
+			// A class assigned another class, as in X ::= ATTRIBUTE.
+			p.classes[name] = c
+			delete(p.types, name)
+		} else {
+			names = append(names, name)
+		}
+	}
+	p.names = names
+	for _, def := range p.defs {
+		if r, ok := p.l.refs[def.typ]; ok && r.field == "" {
+			def.object = p.isClass(r.name, r.off)
+		}
+	}
+	for _, set := range p.sets {
+		if r, ok := p.l.refs[set.t]; ok && r.field == "" && p.isClass(r.name, r.off) {
+			// Object sets are checked for syntax only.
+			delete(p.types, set.name.text)
+			names := p.names[:0]
+			for _, name := range p.names {
+				if name != set.name.text {
+					names = append(names, name)
+				}
+			}
+			p.names = names
+			continue
+		}
+		// A value set is its governor, constrained to the values of the
+		// set.
+		p.within(set.toks, nil, func() {
+			p.expect("{")
+			p.constraintSpec(set.t, false)
+			p.expect("}")
+		})
+	}
+}
+
+// class returns the information object class that name refers to, or nil
+// if it is not a class.
+func (p *moduleParser) class(name string, off int) *objectClass {
+	if c, ok := p.classes[name]; ok {
+		return c
+	}
+	var q *moduleParser
+	if t, ok := p.types[name]; ok {
+		r, ok := p.l.refs[t]
+		if !ok || r.field != "" || r.module != "" || len(t.Tags) > 0 {
+			return nil
+		}
+		q, name, off = r.p, r.name, r.off
+	} else if mod, ok := p.imported[name]; ok {
+		if q = p.exporter(mod, name, off); q == nil {
+			return nil
+		}
+		off = q.offsets[name]
+	} else {
+		return nil
+	}
+	if p.busy[name] {
+		return nil
+	}
+	p.busy[name] = true
+	defer delete(p.busy, name)
+	return q.class(name, off)
+}
+
+// isClass reports whether name refers to an information object class. A
+// symbol imported from a module that is not known is taken for a class if
+// it has no lower-case letters, as X.681 requires of class references.
+func (p *moduleParser) isClass(name string, off int) bool {
+	if p.class(name, off) != nil {
+		return true
+	}
+	mod, ok := p.imported[name]
+	return ok && p.types[name] == nil && p.l.parsers[mod] == nil && strings.ToUpper(name) == name
+}
+
+// classField returns the type of the value field of a class that r refers
+// to, or nil if the class is imported from a module that is not known.
+func (p *moduleParser) classField(r typeRef) *Type {
+	c := p.class(r.name, r.off)
+	if c == nil {
+		if p.isClass(r.name, r.off) {
+			return nil
+		}
+		p.errorf(r.off, "undefined class %s", r.name)
+	}
+	if c.fields == nil {
+		return nil
+	}
+	t, ok := c.fields[r.field]
+	if !ok {
+		p.errorf(r.off, "class %s has no field %s", r.name, r.field)
+	}
+	if t == nil {
+		return &Type{Kind: KindAny}
+	}
+	return c.p.resolve(t)
+}
+
+// instantiate parses the types of the instances of parameterized types,
+// including those of the instances it parses.
+func (l *moduleLinker) instantiate() {
+	for len(l.instances) > 0 {
+		in := l.instances[0]
+		l.instances = l.instances[1:]
+		in.p.instantiate(in)
+	}
+}
+
+// instantiate parses the type of the instance in of the module, with the
+// formal parameters bound to the actual ones.
+func (p *moduleParser) instantiate(in *instance) {
+	q, pt := p.paramType(in.r)
+	if pt == nil {
+		return
+	}
+	if len(in.args) != len(pt.params) {
+		p.errorf(in.r.off, "%s takes %d parameters, not %d", pt.name, len(pt.params), len(in.args))
+	}
+	params := make(map[string]actualParam, len(pt.params))
+	for i, name := range pt.params {
+		params[name] = actualParam{p, in.args[i], in.params}
+	}
+	// Instances with the same actual parameters share their type, so that
+	// a parameterized type may use itself, as types do.
+	key := instanceKey(pt, params)
+	t := p.l.instanced[key]
+	if t == nil {
+		if in.depth >= maxInstanceDepth {
+			p.errorf(in.r.off, "instances of %s are nested too deeply", pt.name)
+		}
+		p.l.depth = in.depth + 1
+		defer func() { p.l.depth = 0 }()
+		q.within(pt.toks, params, func() { t = q.typ() })
+		p.l.instanced[key] = t
+	}
+	p.l.refs[in.t] = typeRef{p: p, name: in.r.name, off: in.r.off, target: t}
+}
+
+// instanceKey identifies the instance of the parameterized type pt with
+// the actual parameters params.
+func instanceKey(pt *paramType, params map[string]actualParam) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%p", pt)
+	for _, name := range pt.params {
+		a := params[name].resolved()
+		fmt.Fprintf(&b, "|%p %p", a.p, a.params)
+		for _, tok := range a.toks {
+			b.WriteString(" " + tok.text)
+		}
+	}
+	return b.String()
+}
+
+// paramType returns the parameterized type r refers to, and the parser of
+// its module, or nil if it is imported from a module that is not known.
+func (p *moduleParser) paramType(r typeRef) (*moduleParser, *paramType) {
+	mod := r.module
+	pt, ok := p.paramTypes[r.name]
+	if mod == p.m.Name {
+		mod = ""
+	} else if mod == "" && !ok {
+		if mod, ok = p.imported[r.name]; !ok {
+			if _, ok := p.types[r.name]; ok {
+				p.errorf(r.off, "type %s is not parameterized", r.name)
+			}
+			p.errorf(r.off, "undefined type %s", r.name)
+		}
+	}
+	if mod == "" {
+		if pt == nil {
+			p.errorf(r.off, "type %s is not parameterized", r.name)
+		}
+		return p, pt
+	}
+	q := p.exporter(mod, r.name, r.off)
+	if q == nil {
+		return nil, nil
+	}
+	if p.busy[r.name] {
+		p.errorf(r.off, "circular import of %s", r.name)
+	}
+	p.busy[r.name] = true
+	defer delete(p.busy, r.name)
+	return q.paramType(typeRef{p: q, name: r.name, off: q.offsets[r.name]})
+}
+
+// isValue reports whether name is assigned a value in the module, or is
+// imported.
+func (p *moduleParser) isValue(name string) bool {
+	if _, ok := p.values[name]; ok {
+		return true
+	}
+	_, ok := p.imported[name]
+	return ok && name[0] >= 'a' && name[0] <= 'z'
+}
+
+// valueDef returns the value assignment of the value reference ref, and
+// the parser of the module it is in.
+func (p *moduleParser) valueDef(ref moduleToken) (*moduleParser, *valueDef) {
+	if def, ok := p.values[ref.text]; ok {
+		return p, def
+	}
+	mod, ok := p.imported[ref.text]
+	if !ok {
+		p.errorf(ref.off, "undefined value %s", ref.text)
+	}
+	q := p.exporter(mod, ref.text, ref.off)
+	if q == nil {
+		p.errorf(ref.off, "value %s is imported from module %s, which is not parsed with it", ref.text, mod)
+	}
+	if p.busy[ref.text] {
+		p.errorf(ref.off, "circular import of %s", ref.text)
+	}
+	p.busy[ref.text] = true
+	defer delete(p.busy, ref.text)
+	return q.valueDef(moduleToken{kind: tokWord, text: ref.text, off: q.offsets[ref.text]})
+}
+
+// valueOf returns the value of a value assignment, converting it on first
+// use.
+func (p *moduleParser) valueOf(def *valueDef) interface{} {
+	switch def.state {
+	case 1:
+		p.errorf(def.toks[0].off, "circular definition of %s", def.name)
+	case 2:
+		return def.value
+	}
+	def.state = 1
+	t := p.resolve(def.typ)
+	p.fix(t)
+	def.value = p.value(t, def.toks)
+	def.state = 2
+	return def.value
+}
+
+// value converts the notation of a value of type t in the items toks.
+func (p *moduleParser) value(t *Type, toks []moduleToken) interface{} {
+	switch t.Kind {
+	case KindObjectIdentifier:
+		return p.objectIdentifierValue(toks)
+	case KindRelativeOID:
+		return RelativeOID(p.objectIdentifierValue(toks))
+	}
+	tok := toks[0]
+	if len(toks) == 1 && tok.isIdentifier() {
+		if _, named := t.NamedValue(tok.text); !named && p.isValue(tok.text) {
+			q, def := p.valueDef(tok)
+			return q.valueOf(def)
+		}
+	}
+	var v interface{}
+	if err := Unmarshal(p.src[tok.off:toks[len(toks)-1].end], &v); err != nil {
+		p.errorf(tok.off, "invalid value of %s: %s", t, strings.TrimPrefix(err.Error(), "asn1go: "))
+	}
+	return v
+}
+
+// objectIdentifierValue returns the OBJECT IDENTIFIER value, or value
+// reference to one, in the items toks.
+func (p *moduleParser) objectIdentifierValue(toks []moduleToken) (oid ObjectIdentifier) {
+	p.within(toks, nil, func() {
+		if tok := p.peek(); tok.isIdentifier() {
+			p.next()
+			if _, ok := p.values[tok.text]; !ok && p.exporter(p.imported[tok.text], tok.text, tok.off) == nil {
+				p.pos = len(toks)
+				return
+			}
+			oid = p.objectIdentifierRef(tok)
+		} else {
+			oid = p.objectIdentifier()
+		}
+		if p.peek().kind != tokEOF {
+			p.unexpected("expected end of OBJECT IDENTIFIER value")
+		}
+	})
+	return oid
+}
+
+// objectIdentifierRef returns the value of the OBJECT IDENTIFIER value
+// reference ref.
+func (p *moduleParser) objectIdentifierRef(ref moduleToken) ObjectIdentifier {
+	q, def := p.valueDef(ref)
+	oid, ok := q.valueOf(def).(ObjectIdentifier)
+	if !ok {
+		p.errorf(ref.off, "%s is not an OBJECT IDENTIFIER value", ref.text)
+	}
+	return oid
+}
+
+// wellKnownArcs are the names of the top arcs of the object identifier
+// tree, and of the arcs below them that X.660 names.
+var wellKnownArcs = map[string]int{
+	"itu-t": 0, "ccitt": 0, "iso": 1, "joint-iso-itu-t": 2, "joint-iso-ccitt": 2,
+}
+
+var wellKnownSubArcs = [...]map[string]int{
+	0: {"recommendation": 0, "question": 1, "administration": 2, "network-operator": 3, "identified-organization": 4},
+	1: {"standard": 0, "registration-authority": 1, "member-body": 2, "identified-organization": 3},
+}
+
+// objectIdentifier parses an OBJECT IDENTIFIER value in braces. Its arcs
+// are numbers, names with numbers, as in iso(1), well-known names and
+// INTEGER value references, and it may start with an OBJECT IDENTIFIER
+// value reference.
+func (p *moduleParser) objectIdentifier() ObjectIdentifier {
+	p.expect("{")
+	var oid ObjectIdentifier
+	for !p.accept("}") {
+		tok := p.next()
+		switch {
+		case tok.kind == tokNumber:
+			oid = append(oid, int(p.integerOf([]moduleToken{tok})))
+		case !tok.isIdentifier():
+			p.errorf(tok.off, "expected OBJECT IDENTIFIER component, found %s", tok)
+		case p.accept("("):
+			off := p.peek().off
+			var n int64
+			if p.peek().isIdentifier() {
+				n = p.integerValue(p.next())
+			} else {
+				n = p.number()
+			}
+			if n < 0 {
+				p.errorf(off, "negative OBJECT IDENTIFIER component %d", n)
+			}
+			oid = append(oid, int(n))
+			p.expect(")")
+		default:
+			n, ok := wellKnownArcs[tok.text]
+			if len(oid) == 1 && oid[0] < len(wellKnownSubArcs) {
+				n, ok = wellKnownSubArcs[oid[0]][tok.text]
+			}
+			switch {
+			case ok && len(oid) <= 1:
+				oid = append(oid, n)
+			case !p.isValue(tok.text):
+				p.errorf(tok.off, "undefined value %s", tok.text)
+			case len(oid) == 0:
+				oid = append(oid, p.objectIdentifierRef(tok)...)
+			default:
+				oid = append(oid, int(p.integerValue(tok)))
+			}
+		}
+	}
+	return oid
+}
@@ -0,0 +1,46 @@
+package asn1go
+
+// A ModuleSet is a set of ASN.1 modules that import from each other, as
+// parsed by ParseModules.
+type ModuleSet struct {
+	// Modules holds the modules in the order they were parsed.
+	Modules []*Module
+}
+
+// ParseModules parses the ASN.1 module definitions in srcs, each of which
+// may hold several modules, like ParseModule. The modules' imports are
+// resolved against each other: references to imported types are replaced
+// by the types of the modules they are imported from, and imported values
+// may be used like the module's own.
+//
+// An imported symbol must be defined in, or itself imported into, the
+// module it is imported from, and be exported by it. Imports from a module
+// that is not among srcs, of symbols the module lacks or does not export,
+// and imports that lead back to the symbol they import are reported as a
+// ModuleSyntaxError, as are two modules with the same name.
+func ParseModules(srcs ...[]byte) (*ModuleSet, error) {
+	ms, err := parseModules(srcs, true, false)
+	if err != nil {
+		return nil, err
+	}
+	return &ModuleSet{ms}, nil
+}
+
+// Module returns the module named name, or nil if the set has none.
+func (s *ModuleSet) Module(name string) *Module {
+	for _, m := range s.Modules {
+		if m.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// Type returns the type assigned to the type reference name in the module
+// named module, or nil if there is none.
+func (s *ModuleSet) Type(module, name string) *Type {
+	if m := s.Module(module); m != nil {
+		return m.Type(name)
+	}
+	return nil
+}
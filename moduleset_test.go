@@ -0,0 +1,113 @@
+package asn1go
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const pkixSrc = `
+PKIX-A { iso(1) 3 } DEFINITIONS IMPLICIT TAGS ::= BEGIN
+EXPORTS Name, id-pkix, Version;
+IMPORTS Ext, id-ce FROM PKIX-B { id-pkix 2 };
+id-pkix OBJECT IDENTIFIER ::= { iso(1) identified-organization(3) 6 }
+id-a OBJECT IDENTIFIER ::= { id-ce 9 }
+Version ::= INTEGER { v1(0), v2(1) }
+Name ::= SEQUENCE { cn UTF8String, exts [0] SEQUENCE OF Ext OPTIONAL }
+END
+PKIX-B DEFINITIONS EXPLICIT TAGS ::= BEGIN
+IMPORTS Name, id-pkix, Version FROM PKIX-A;
+id-ce OBJECT IDENTIFIER ::= { id-pkix 29 }
+Ext ::= SEQUENCE { v Version DEFAULT v2, owner [1] Name OPTIONAL }
+Cert ::= SEQUENCE { subject Name, n INTEGER (0..10) }
+END
+`
+
+func TestParseModules(t *testing.T) {
+	// The same modules in one source and in two.
+	srcs := [][][]byte{
+		{[]byte(pkixSrc)},
+		{[]byte(strings.SplitAfter(pkixSrc, "END\n")[0]), []byte(strings.SplitAfter(pkixSrc, "END\n")[1])},
+	}
+	for _, src := range srcs {
+		s, err := ParseModules(src...)
+		if err != nil {
+			t.Fatalf("ParseModules: %v", err)
+		}
+		if len(s.Modules) != 2 || s.Modules[0].Name != "PKIX-A" || s.Modules[1].Name != "PKIX-B" {
+			t.Fatalf("ParseModules: modules %v", s.Modules)
+		}
+		if s.Module("PKIX-C") != nil || s.Type("PKIX-A", "Cert") != nil || s.Type("PKIX-C", "Cert") != nil {
+			t.Error("ModuleSet finds what it does not hold")
+		}
+
+		values := []struct {
+			module, name string
+			want         ObjectIdentifier
+		}{
+			{"PKIX-A", "id-pkix", ObjectIdentifier{1, 3, 6}},
+			{"PKIX-B", "id-ce", ObjectIdentifier{1, 3, 6, 29}},
+			{"PKIX-A", "id-a", ObjectIdentifier{1, 3, 6, 29, 9}},
+		}
+		for _, tt := range values {
+			if v, ok := s.Module(tt.module).Value(tt.name); !ok || !reflect.DeepEqual(v, tt.want) {
+				t.Errorf("%s.%s = %v, want %v", tt.module, tt.name, v, tt.want)
+			}
+		}
+		if id := s.Module("PKIX-A").Imports[0].Identifier; !reflect.DeepEqual(id, ObjectIdentifier{1, 3, 6, 2}) {
+			t.Errorf("identifier of PKIX-B in PKIX-A = %v, want 1.3.6.2", id)
+		}
+
+		// Imported types are the types of the modules they come from.
+		ext, name := s.Type("PKIX-B", "Ext"), s.Type("PKIX-A", "Name")
+		if s.Type("PKIX-B", "Cert").Component("subject").Type != name {
+			t.Error("Cert.subject is not PKIX-A.Name")
+		}
+		if name.Component("exts").Type.Elem != ext {
+			t.Error("Name.exts is not a SEQUENCE OF PKIX-B.Ext")
+		}
+		if v := ext.Component("v"); v.Type.Name != "Version" || v.Default != "v2" {
+			t.Errorf("Ext.v = %v DEFAULT %v, want Version DEFAULT v2", v.Type, v.Default)
+		}
+	}
+}
+
+func TestParseModulesError(t *testing.T) {
+	const a = "A DEFINITIONS ::= BEGIN IMPORTS X FROM B; T ::= X END "
+	tests := []struct {
+		src string
+		msg string
+	}{
+		{a, "module B of imported symbol X is missing"},
+		{a + "B DEFINITIONS ::= BEGIN Y ::= INTEGER END", "module B has no symbol X"},
+		{a + "B DEFINITIONS ::= BEGIN EXPORTS Y; Y ::= INTEGER X ::= BOOLEAN END", "module B does not export X"},
+		{a + "B DEFINITIONS ::= BEGIN IMPORTS X FROM A; END", "circular import of X"},
+		{"A DEFINITIONS ::= BEGIN EXPORTS Z; END", "exported symbol Z is not defined"},
+		{"A DEFINITIONS ::= BEGIN END A DEFINITIONS ::= BEGIN END", "duplicate module A"},
+	}
+	for _, tt := range tests {
+		_, err := ParseModules([]byte(tt.src))
+		se, ok := err.(*ModuleSyntaxError)
+		if !ok {
+			t.Errorf("ParseModules(%q): error %v, want ModuleSyntaxError", tt.src, err)
+			continue
+		}
+		if se.Module != "A" || se.Msg != tt.msg {
+			t.Errorf("ParseModules(%q): error %q in %s, want %q in A", tt.src, se.Msg, se.Module, tt.msg)
+		}
+	}
+}
+
+func TestParseModuleImportedValue(t *testing.T) {
+	const src = "A DEFINITIONS ::= BEGIN IMPORTS x FROM B; T ::= INTEGER (0..x) END "
+	if _, err := ParseModule([]byte(src)); err == nil {
+		t.Error("ParseModule with an imported value: no error")
+	}
+	s, err := ParseModules([]byte(src + "B DEFINITIONS ::= BEGIN x INTEGER ::= 7 END"))
+	if err != nil {
+		t.Fatalf("ParseModules: %v", err)
+	}
+	if r := s.Type("A", "T").Range; r == nil || r.Max != 7 {
+		t.Errorf("range of T = %v, want 0..7", r)
+	}
+}
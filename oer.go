@@ -0,0 +1,908 @@
+package asn1go
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// EncodeOER returns the OER encoding of v as a value of the ASN.1 type t.
+// It takes the same values as EncodeUPER. The encoding is the canonical
+// one of COER, which is also valid OER.
+//
+// The Size and Range constraints of t select fixed-size encodings where
+// OER has them: an INTEGER whose range fits 1, 2, 4 or 8 octets takes
+// that many, and a string of a fixed size has no length. Extensible
+// ranges of INTEGER values are ignored, as OER requires. A CHOICE value
+// is encoded with the tag of its alternative, and other tags only matter
+// for the order of the components of a SET.
+func EncodeOER(t *Type, v interface{}) ([]byte, error) {
+	var e oerEncoder
+	return e.marshal(t, reflect.ValueOf(v))
+}
+
+// MarshalOER returns the OER encoding of v, as a value of the ASN.1 type
+// TypeOf derives from the type of v.
+func MarshalOER(v interface{}) ([]byte, error) {
+	t, err := TypeOf(v)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeOER(t, v)
+}
+
+// DecodeOER parses data as the OER encoding of a value of the ASN.1 type t
+// and stores the result in the value pointed to by v, which must be a
+// non-nil pointer. It stores values as DecodeUPER does.
+//
+// Malformed input and encodings that do not match t are reported as an
+// OERSyntaxError. If a value is not appropriate for a given target type,
+// DecodeOER skips that value and completes the decoding as best it can,
+// and then returns an UnmarshalTypeError describing the earliest such
+// error.
+func DecodeOER(t *Type, data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+	d := oerDecoder{data: data}
+	return d.unmarshal(t, rv)
+}
+
+// DecodeCOER is like DecodeOER but accepts canonical encodings only. It
+// rejects lengths, ENUMERATED values and SEQUENCE OF quantities in more
+// octets than needed, BOOLEAN true values other than FF, and nonzero
+// padding bits.
+func DecodeCOER(t *Type, data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+	d := oerDecoder{data: data, canonical: true}
+	return d.unmarshal(t, rv)
+}
+
+// UnmarshalOER parses the OER encoded data and stores the result in the
+// value pointed to by v, which must be a non-nil pointer. The ASN.1 type
+// of the encoding is read from the Go type of v as TypeOf describes.
+func UnmarshalOER(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+	t, err := TypeOf(v)
+	if err != nil {
+		return err
+	}
+	return DecodeOER(t, data, v)
+}
+
+// An OERSyntaxError describes malformed OER input.
+type OERSyntaxError struct {
+	Msg    string
+	Offset int64 // error occurred after reading Offset bytes
+}
+
+func (e *OERSyntaxError) Error() string {
+	return "asn1go: OER syntax error at offset " + strconv.FormatInt(e.Offset, 10) + ": " + e.Msg
+}
+
+// An oerEncoder encodes values in OER.
+type oerEncoder struct {
+	binaryEncoder
+	buf []byte
+}
+
+func (e *oerEncoder) marshal(t *Type, v reflect.Value) (b []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if je, ok := r.(asn1Error); ok {
+				err = je.error
+			} else {
+				panic(r)
+			}
+		}
+	}()
+	e.value(t, v)
+	return e.buf, nil
+}
+
+// octets writes b after a length determinant, which takes the form of
+// the definite length of BER.
+func (e *oerEncoder) octets(b []byte) {
+	e.buf = appendLength(e.buf, len(b))
+	e.buf = append(e.buf, b...)
+}
+
+// fixedSize returns the size of the values of type t if its size
+// constraint allows one size only, and is not extensible.
+func fixedSize(t *Type) (int, bool) {
+	r := t.Size
+	if r == nil || r.Extensible || r.NoMin || r.NoMax || r.Min != r.Max {
+		return 0, false
+	}
+	return int(r.Min), true
+}
+
+// value writes the encoding of v as a value of type t.
+func (e *oerEncoder) value(t *Type, v reflect.Value) {
+	v = derIndirect(v)
+	switch t.Kind {
+	case KindBoolean:
+		if v.Kind() != reflect.Bool {
+			e.mismatch(t, v)
+		}
+		if v.Bool() {
+			e.buf = append(e.buf, 0xff)
+		} else {
+			e.buf = append(e.buf, 0x00)
+		}
+
+	case KindInteger:
+		e.integer(t, e.integerOf(t, v))
+
+	case KindEnumerated:
+		n := e.integerOf(t, v)
+		if _, ok := t.nameOf(n); !ok && !t.Extensible {
+			e.error(t, "unknown value %d", n)
+		}
+		if n >= 0 && n < 0x80 {
+			e.buf = append(e.buf, byte(n))
+			break
+		}
+		b := appendInt64(nil, n)
+		e.buf = append(e.buf, 0x80|byte(len(b)))
+		e.buf = append(e.buf, b...)
+
+	case KindReal:
+		e.octets(appendReal(nil, e.realOf(t, v)))
+
+	case KindBitString:
+		bs := e.bitStringOf(t, v)
+		if n, ok := fixedSize(t); ok {
+			if bs.BitLength != n {
+				e.error(t, "size %d out of range %v", bs.BitLength, t.Size)
+			}
+			e.buf = append(e.buf, appendBitString(nil, bs)[1:]...)
+			break
+		}
+		e.checkSize(t, bs.BitLength)
+		e.octets(appendBitString(nil, bs))
+
+	case KindOctetString:
+		b, ok := bytesOf(v)
+		if !ok {
+			e.mismatch(t, v)
+		}
+		e.sized(t, b)
+
+	case KindNull:
+
+	case KindObjectIdentifier:
+		oid, ok := objectIdentifierOf(v)
+		if !ok {
+			e.mismatch(t, v)
+		}
+		b, err := appendOID(nil, oid)
+		if err != nil {
+			e.error(t, "%v", err)
+		}
+		e.octets(b)
+
+	case KindRelativeOID:
+		oid, ok := objectIdentifierOf(v)
+		if !ok {
+			e.mismatch(t, v)
+		}
+		e.octets(appendRelativeOID(nil, oid))
+
+	case KindUTF8String, KindUTCTime, KindGeneralizedTime, KindOIDIRI, KindRelativeOIDIRI:
+		// The size of a UTF8String counts characters, not octets, so it
+		// does not allow leaving out the length.
+		e.octets([]byte(e.stringOf(t, v)))
+
+	case KindNumericString, KindPrintableString, KindIA5String, KindVisibleString:
+		e.sized(t, []byte(e.stringOf(t, v)))
+
+	case KindSequence, KindSet:
+		e.components(t, v)
+
+	case KindSequenceOf, KindSetOf:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			e.mismatch(t, v)
+		}
+		e.checkSize(t, v.Len())
+		q := appendUint64(nil, uint64(v.Len()))
+		if len(q) > 1 && q[0] == 0 {
+			q = q[1:]
+		}
+		e.octets(q)
+		for i := 0; i < v.Len(); i++ {
+			e.path = append(e.path, strconv.Itoa(i))
+			e.value(t.Elem, v.Index(i))
+			e.path = e.path[:len(e.path)-1]
+		}
+
+	case KindChoice:
+		e.choice(t, v)
+
+	case KindAny:
+		raw, ok := bytesOf(v)
+		if !ok || v.Kind() == reflect.String {
+			e.error(t, "open type value must be an OER encoding")
+		}
+		e.octets(raw)
+
+	default:
+		e.error(t, "unsupported type")
+	}
+}
+
+// checkSize checks the size n of a value of type t against its size
+// constraint, unless that is extensible.
+func (e *oerEncoder) checkSize(t *Type, n int) {
+	if r := t.Size; r != nil && !r.Extensible && !r.contains(int64(n)) {
+		e.error(t, "size %d out of range %v", n, r)
+	}
+}
+
+// sized writes the octets b of a string value of type t, with a length
+// determinant unless the type has a fixed size.
+func (e *oerEncoder) sized(t *Type, b []byte) {
+	if n, ok := fixedSize(t); ok {
+		if len(b) != n {
+			e.error(t, "size %d out of range %v", len(b), t.Size)
+		}
+		e.buf = append(e.buf, b...)
+		return
+	}
+	e.checkSize(t, len(b))
+	e.octets(b)
+}
+
+// integerSize returns the number of octets of the fixed-size encoding of
+// the values of the INTEGER range r, and whether they are encoded
+// unsigned. A size of 0 means the values take a length determinant.
+func integerSize(r *Range) (size int, unsigned bool) {
+	if r == nil || r.Extensible || r.NoMin {
+		return 0, false
+	}
+	if r.Min >= 0 {
+		switch {
+		case r.NoMax:
+			return 0, true
+		case r.Max <= math.MaxUint8:
+			return 1, true
+		case r.Max <= math.MaxUint16:
+			return 2, true
+		case r.Max <= math.MaxUint32:
+			return 4, true
+		}
+		return 8, true
+	}
+	switch {
+	case r.NoMax:
+		return 0, false
+	case r.Min >= math.MinInt8 && r.Max <= math.MaxInt8:
+		return 1, false
+	case r.Min >= math.MinInt16 && r.Max <= math.MaxInt16:
+		return 2, false
+	case r.Min >= math.MinInt32 && r.Max <= math.MaxInt32:
+		return 4, false
+	}
+	return 8, false
+}
+
+// integer writes the INTEGER value n: in the fixed number of octets its
+// range allows, or after a length determinant, unsigned if the range has
+// a lower bound of at least zero and in two's complement otherwise.
+func (e *oerEncoder) integer(t *Type, n int64) {
+	r := t.Range
+	if r != nil && !r.Extensible && !r.contains(n) {
+		e.error(t, "value %d out of range %v", n, r)
+	}
+	size, unsigned := integerSize(r)
+	switch {
+	case size > 0:
+		for i := size - 1; i >= 0; i-- {
+			e.buf = append(e.buf, byte(n>>(uint(i)*8)))
+		}
+	case unsigned:
+		b := appendUint64(nil, uint64(n))
+		if len(b) > 1 && b[0] == 0 {
+			b = b[1:]
+		}
+		e.octets(b)
+	default:
+		e.octets(appendInt64(nil, n))
+	}
+}
+
+// components writes the SEQUENCE or SET value v: a preamble with a bit for
+// each OPTIONAL and DEFAULT component of the root telling whether it is
+// present, and then the present components. The preamble of an extensible
+// type starts with a bit telling whether there are extension additions,
+// which follow the root as a bitmap of the additions that are present and
+// the additions as open types.
+func (e *oerEncoder) components(t *Type, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			e.mismatch(t, v)
+		}
+		for _, k := range v.MapKeys() {
+			if t.Component(k.String()) == nil && !(t.Extensible && k.String() == "...") {
+				e.error(t, "unknown component %s", k.String())
+			}
+		}
+	default:
+		e.mismatch(t, v)
+	}
+
+	values := make([]reflect.Value, len(t.Components))
+	for i := range t.Components {
+		c := &t.Components[i]
+		cv, ok := componentValue(v, c.Name)
+		switch {
+		case !ok && !c.Optional && c.Default == nil && !c.Extension:
+			e.error(t, "missing component %s", c.Name)
+		case ok && c.Default != nil && equalValue(c.Type, cv, reflect.ValueOf(c.Default)):
+			ok = false
+		}
+		if ok {
+			values[i] = cv
+		}
+	}
+	additions := extensionOrder(t)
+	var bitmap []bool
+	extended := false
+	for _, a := range additions {
+		present := false
+		for _, i := range a {
+			present = present || values[i].IsValid()
+		}
+		bitmap = append(bitmap, present)
+		extended = extended || present
+	}
+	ext, _ := e.extensions(t, v)
+	for _, b := range ext {
+		bitmap = append(bitmap, b != nil)
+		extended = extended || b != nil
+	}
+
+	var lead []bool
+	if t.Extensible {
+		lead = []bool{extended}
+	}
+	e.present(t, lead, componentOrder(t), values)
+	if !extended {
+		return
+	}
+	b := packBits(bitmap)
+	e.octets(append([]byte{byte(8*len(b) - len(bitmap))}, b...))
+	for k, a := range additions {
+		if !bitmap[k] {
+			continue
+		}
+		c := &t.Components[a[0]]
+		e.openType(func(sub *oerEncoder) {
+			if c.Group == 0 {
+				sub.component(c, values[a[0]])
+				return
+			}
+			// The components of an extension addition group are
+			// encoded as those of a SEQUENCE.
+			for _, i := range a {
+				if c := &t.Components[i]; !values[i].IsValid() && !c.Optional && c.Default == nil {
+					sub.error(t, "missing component %s", c.Name)
+				}
+			}
+			sub.present(t, nil, a, values)
+		})
+	}
+	for _, b := range ext {
+		if b != nil {
+			e.octets(b)
+		}
+	}
+}
+
+// present writes a preamble of the bits lead followed by a bit for each of
+// the OPTIONAL and DEFAULT components among the components order of t
+// telling whether it is present, and then the present components, whose
+// values are given by values.
+func (e *oerEncoder) present(t *Type, lead []bool, order []int, values []reflect.Value) {
+	bits := lead
+	for _, i := range order {
+		if c := &t.Components[i]; c.Optional || c.Default != nil {
+			bits = append(bits, values[i].IsValid())
+		}
+	}
+	e.buf = append(e.buf, packBits(bits)...)
+	for _, i := range order {
+		if values[i].IsValid() {
+			e.component(&t.Components[i], values[i])
+		}
+	}
+}
+
+// component writes the value v of the component c.
+func (e *oerEncoder) component(c *Component, v reflect.Value) {
+	e.path = append(e.path, c.Name)
+	e.value(c.Type, v)
+	e.path = e.path[:len(e.path)-1]
+}
+
+// openType writes what encode writes, with an encoder of its own, as an
+// open type: its octets after a length determinant.
+func (e *oerEncoder) openType(encode func(e *oerEncoder)) {
+	sub := oerEncoder{binaryEncoder: binaryEncoder{path: e.path}}
+	encode(&sub)
+	e.octets(sub.buf)
+}
+
+// packBits returns bits in octets, most significant bit first, with the
+// last octet padded with zero bits.
+func packBits(bits []bool) []byte {
+	b := make([]byte, (len(bits)+7)/8)
+	for i, set := range bits {
+		if set {
+			b[i/8] |= 0x80 >> uint(i%8)
+		}
+	}
+	return b
+}
+
+// choice writes the CHOICE value v: the tag of its alternative, and then
+// the value of the alternative, as an open type for an extension
+// addition. The tag of an untagged CHOICE alternative is that of its own
+// alternative, which it writes itself.
+func (e *oerEncoder) choice(t *Type, v reflect.Value) {
+	name, av, ok := choiceOf(v)
+	if !ok {
+		e.mismatch(t, v)
+	}
+	c := t.Component(name)
+	if c == nil && t.Extensible && name == "..." {
+		ext, ok := extensionsOf(av)
+		if !ok || len(ext) == 0 || ext[len(ext)-1] == nil {
+			e.error(t, "unknown extension alternative must be Extensions")
+		}
+		e.buf = append(e.buf, ext[len(ext)-1]...)
+		return
+	}
+	if c == nil {
+		e.error(t, "unknown alternative %s", name)
+	}
+	e.path = append(e.path, name)
+	if tag, ok := c.Type.Tag(); ok {
+		e.buf = appendOERTag(e.buf, tag)
+	} else if c.Type.Kind != KindChoice {
+		e.error(c.Type, "untagged open type alternative")
+	}
+	if c.Extension {
+		e.openType(func(sub *oerEncoder) { sub.value(c.Type, av) })
+	} else {
+		e.value(c.Type, av)
+	}
+	e.path = e.path[:len(e.path)-1]
+}
+
+// appendOERTag appends the OER encoding of tag to dst: the class in the
+// two high bits and the number in the other six, or in the base-128
+// octets that follow if it is 63 or more.
+func appendOERTag(dst []byte, tag Tag) []byte {
+	b := byte(tag.Class) << 6
+	if tag.Number < 0x3f {
+		return append(dst, b|byte(tag.Number))
+	}
+	dst = append(dst, b|0x3f)
+	return appendBase128(dst, uint64(tag.Number))
+}
+
+// An oerDecoder decodes OER encodings.
+type oerDecoder struct {
+	storer
+	data      []byte
+	pos       int  // octets read
+	canonical bool // accept COER encodings only
+}
+
+func (d *oerDecoder) unmarshal(t *Type, v reflect.Value) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if je, ok := r.(asn1Error); ok {
+				err = je.error
+			} else {
+				panic(r)
+			}
+		}
+	}()
+	d.value(t, v)
+	if d.pos != len(d.data) {
+		d.syntaxError("trailing data after top-level value")
+	}
+	return d.savedError
+}
+
+// syntaxError aborts the decoding with an OERSyntaxError at the current
+// octet.
+func (d *oerDecoder) syntaxError(format string, args ...interface{}) {
+	panic(asn1Error{&OERSyntaxError{fmt.Sprintf(format, args...), int64(d.pos)}})
+}
+
+// read reads n octets.
+func (d *oerDecoder) read(n int) []byte {
+	if n > len(d.data)-d.pos {
+		d.syntaxError("truncated encoding")
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b
+}
+
+// length reads a length determinant.
+func (d *oerDecoder) length() int {
+	b := d.read(1)[0]
+	if b&0x80 == 0 {
+		return int(b)
+	}
+	k := int(b & 0x7f)
+	if k == 0 {
+		d.syntaxError("invalid length determinant")
+	}
+	lb := d.read(k)
+	n := 0
+	for _, c := range lb {
+		if n > math.MaxInt32>>8 {
+			d.syntaxError("length too large")
+		}
+		n = n<<8 | int(c)
+	}
+	if d.canonical && (lb[0] == 0 || n < 0x80) {
+		d.syntaxError("non-minimal length")
+	}
+	return n
+}
+
+// octets reads octets after a length determinant.
+func (d *oerDecoder) octets() []byte {
+	return append([]byte{}, d.read(d.length())...)
+}
+
+// value decodes a value of type t into v.
+func (d *oerDecoder) value(t *Type, v reflect.Value) {
+	v = target(v)
+	if v.IsValid() && v.Type() == valueType {
+		d.dynamic(t, d.pos, v, func(v reflect.Value) { d.value(t, v) })
+		return
+	}
+	off := d.pos
+	switch t.Kind {
+	case KindBoolean:
+		b := d.read(1)[0]
+		if d.canonical && b != 0x00 && b != 0xff {
+			d.syntaxError("invalid BOOLEAN")
+		}
+		d.store(t, off, b != 0, v)
+
+	case KindInteger:
+		d.store(t, off, d.integer(t), v)
+
+	case KindEnumerated:
+		var n int64
+		b := d.read(1)[0]
+		if b&0x80 == 0 {
+			n = int64(b)
+		} else {
+			l := int(b & 0x7f)
+			if l == 0 || l > 8 {
+				d.syntaxError("invalid ENUMERATED length %d", l)
+			}
+			b := d.read(l)
+			if d.canonical && len(trimInteger(b)) != l {
+				d.syntaxError("non-minimal ENUMERATED")
+			}
+			n = int64(int8(b[0]))
+			for _, c := range b[1:] {
+				n = n<<8 | int64(c)
+			}
+			if d.canonical && n >= 0 && n < 0x80 {
+				d.syntaxError("non-minimal ENUMERATED")
+			}
+		}
+		if _, ok := t.nameOf(n); !ok && !t.Extensible {
+			d.syntaxError("unknown value %d of %v", n, t)
+		}
+		d.store(t, off, n, v)
+
+	case KindReal:
+		f, err := parseReal(d.octets())
+		if err != nil {
+			d.syntaxError("%v", err)
+		}
+		d.store(t, off, f, v)
+
+	case KindBitString:
+		var b []byte
+		unused := 0
+		if n, ok := fixedSize(t); ok {
+			b = append([]byte{}, d.read((n+7)/8)...)
+			unused = 8*len(b) - n
+		} else {
+			b = d.octets()
+			if len(b) == 0 || b[0] > 7 || len(b) == 1 && b[0] != 0 {
+				d.syntaxError("invalid BIT STRING")
+			}
+			unused, b = int(b[0]), b[1:]
+		}
+		if last := len(b) - 1; last >= 0 && b[last]&(1<<uint(unused)-1) != 0 {
+			if d.canonical {
+				d.syntaxError("BIT STRING with unused bits set")
+			}
+			b[last] &^= 1<<uint(unused) - 1
+		}
+		d.store(t, off, BitString{Bytes: b, BitLength: 8*len(b) - unused}, v)
+
+	case KindOctetString:
+		d.store(t, off, d.sized(t), v)
+
+	case KindNull:
+		d.store(t, off, nil, v)
+
+	case KindObjectIdentifier:
+		oid, ok := parseOID(d.octets())
+		if !ok {
+			d.syntaxError("invalid OBJECT IDENTIFIER")
+		}
+		d.store(t, off, oid, v)
+
+	case KindRelativeOID:
+		oid, ok := parseRelativeOID(d.octets())
+		if !ok {
+			d.syntaxError("invalid RELATIVE-OID")
+		}
+		d.store(t, off, oid, v)
+
+	case KindUTF8String, KindUTCTime, KindGeneralizedTime, KindOIDIRI, KindRelativeOIDIRI,
+		KindNumericString, KindPrintableString, KindIA5String, KindVisibleString:
+		var s string
+		switch t.Kind {
+		case KindNumericString, KindPrintableString, KindIA5String, KindVisibleString:
+			s = string(d.sized(t))
+		default:
+			s = string(d.octets())
+		}
+		if !validString(t.Kind, s) {
+			d.syntaxError("invalid character in %v", t.Kind)
+		}
+		d.store(t, off, s, v)
+
+	case KindSequence, KindSet:
+		d.components(t, v)
+
+	case KindSequenceOf, KindSetOf:
+		q := d.octets()
+		if len(q) == 0 || len(q) > 8 || d.canonical && len(q) > 1 && q[0] == 0 {
+			d.syntaxError("invalid quantity")
+		}
+		var n uint64
+		for _, c := range q {
+			n = n<<8 | uint64(c)
+		}
+		if n > uint64(len(d.data)) && t.Elem.Kind != KindNull {
+			d.syntaxError("quantity %d too large", n)
+		}
+		l := d.list(t, off, v)
+		for ; n > 0; n-- {
+			d.value(t.Elem, l.next())
+		}
+		l.finish()
+
+	case KindChoice:
+		d.choice(t, off, v)
+
+	case KindAny:
+		d.store(t, off, d.octets(), v)
+
+	default:
+		d.syntaxError("unsupported type %v", t)
+	}
+}
+
+// sized reads the octets of a string value of type t, which have a
+// length determinant unless the type has a fixed size.
+func (d *oerDecoder) sized(t *Type) []byte {
+	if n, ok := fixedSize(t); ok {
+		return append([]byte{}, d.read(n)...)
+	}
+	return d.octets()
+}
+
+// integer reads an INTEGER value of type t, in the form DecodeDER stores
+// in an interface.
+func (d *oerDecoder) integer(t *Type) interface{} {
+	size, unsigned := integerSize(t.Range)
+	var b []byte
+	if size > 0 {
+		b = d.read(size)
+	} else {
+		b = d.octets()
+		if len(b) == 0 {
+			d.syntaxError("empty INTEGER")
+		}
+	}
+	if !unsigned {
+		return parseInteger(trimInteger(b))
+	}
+	for len(b) > 1 && b[0] == 0 {
+		b = b[1:]
+	}
+	if len(b) > 8 {
+		d.syntaxError("INTEGER too large")
+	}
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	if u > math.MaxInt64 {
+		return u
+	}
+	return int64(u)
+}
+
+// components decodes the SEQUENCE or SET value of type t into the struct
+// or map v.
+func (d *oerDecoder) components(t *Type, v reflect.Value) {
+	ct := d.composite(t, d.pos, v)
+	found := make([]bool, len(t.Components))
+	lead := 0
+	if t.Extensible {
+		lead = 1
+	}
+	extended := d.present(ct, t, lead, componentOrder(t), found)
+	if extended {
+		b := d.octets()
+		if len(b) == 0 || b[0] > 7 || len(b) == 1 && b[0] != 0 {
+			d.syntaxError("invalid extension bitmap")
+		}
+		if d.canonical && b[len(b)-1]&(1<<b[0]-1) != 0 {
+			d.syntaxError("nonzero padding bits in extension bitmap")
+		}
+		additions := extensionOrder(t)
+		var ext Extensions
+		for k := 0; k < 8*(len(b)-1)-int(b[0]); k++ {
+			present := b[1+k/8]&(0x80>>uint(k%8)) != 0
+			switch {
+			case k >= len(additions):
+				var e []byte
+				if present {
+					e = d.octets()
+				}
+				ext = append(ext, e)
+			case present:
+				a := additions[k]
+				d.openType(func() {
+					if t.Components[a[0]].Group == 0 {
+						found[a[0]] = true
+						d.component(ct, &t.Components[a[0]], d.pos, func(v reflect.Value) { d.value(t.Components[a[0]].Type, v) })
+						return
+					}
+					d.present(ct, t, 0, a, found)
+				})
+			}
+		}
+		if ext != nil {
+			d.component(ct, &extensionComponent, d.pos, func(v reflect.Value) { d.storeExtensions(t, d.pos, ext, v) })
+		}
+	}
+	d.finish(ct, t, d.pos, found)
+}
+
+// present reads a preamble of lead bits, of which it returns the first,
+// followed by a bit for each of the OPTIONAL and DEFAULT components among
+// the components order of t telling whether it is present, and then the
+// present components into ct, recording them in found.
+func (d *oerDecoder) present(ct *composite, t *Type, lead int, order []int, found []bool) bool {
+	var optional []int
+	for _, i := range order {
+		if c := &t.Components[i]; c.Optional || c.Default != nil {
+			optional = append(optional, i)
+		} else {
+			found[i] = true
+		}
+	}
+	n := lead + len(optional)
+	preamble := d.read((n + 7) / 8)
+	for k, i := range optional {
+		k += lead
+		found[i] = preamble[k/8]&(0x80>>uint(k%8)) != 0
+	}
+	if pad := n % 8; d.canonical && pad != 0 && preamble[len(preamble)-1]&(0xff>>uint(pad)) != 0 {
+		d.syntaxError("nonzero padding bits in preamble")
+	}
+	for _, i := range order {
+		if !found[i] {
+			continue
+		}
+		c := &t.Components[i]
+		d.component(ct, c, d.pos, func(v reflect.Value) { d.value(c.Type, v) })
+	}
+	return lead > 0 && preamble[0]&0x80 != 0
+}
+
+// openType decodes the open type encoding that follows with decode, which
+// must read its octets exactly.
+func (d *oerDecoder) openType(decode func()) {
+	n := d.length()
+	if n > len(d.data)-d.pos {
+		d.syntaxError("truncated encoding")
+	}
+	end := d.pos + n
+	decode()
+	if d.pos != end {
+		d.syntaxError("open type of %d octets holds %d", n, d.pos-end+n)
+	}
+}
+
+// choice decodes the CHOICE value of type t at offset off into v.
+func (d *oerDecoder) choice(t *Type, off int, v reflect.Value) {
+	c := d.alternative(t)
+	switch {
+	case c == nil:
+		d.read(d.length())
+		ext := Extensions{append([]byte(nil), d.data[off:d.pos]...)}
+		d.storeChoice(t, &extensionComponent, off, v, func(v reflect.Value) { d.storeExtensions(t, off, ext, v) })
+	case c.Extension:
+		d.storeChoice(t, c, off, v, func(v reflect.Value) { d.openType(func() { d.value(c.Type, v) }) })
+	default:
+		d.storeChoice(t, c, off, v, func(v reflect.Value) { d.value(c.Type, v) })
+	}
+}
+
+// alternative reads the tag of the alternative of a value of the CHOICE
+// type t, and returns the alternative, or nil for an unknown extension
+// addition of an extensible type. The tag of an untagged CHOICE
+// alternative is left for it to read.
+func (d *oerDecoder) alternative(t *Type) *Component {
+	start := d.pos
+	b := d.read(1)[0]
+	tag := Tag{Class(b >> 6), int(b & 0x3f)}
+	if tag.Number == 0x3f {
+		tag.Number = 0
+		for {
+			c := d.read(1)[0]
+			if d.canonical && tag.Number == 0 && c == 0x80 {
+				d.syntaxError("non-minimal tag")
+			}
+			if tag.Number > math.MaxInt32>>7 {
+				d.syntaxError("tag number too large")
+			}
+			tag.Number = tag.Number<<7 | int(c&0x7f)
+			if c&0x80 == 0 {
+				break
+			}
+		}
+		if d.canonical && tag.Number < 0x3f {
+			d.syntaxError("non-minimal tag")
+		}
+	}
+	for i := range t.Components {
+		c := &t.Components[i]
+		if !matches(c.Type, tag) {
+			continue
+		}
+		if _, ok := c.Type.Tag(); !ok {
+			d.pos = start
+		}
+		return c
+	}
+	if t.Extensible {
+		return nil
+	}
+	d.pos = start
+	d.syntaxError("unexpected tag %v for %v", tag, t)
+	panic("unreachable")
+}
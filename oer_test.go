@@ -0,0 +1,156 @@
+package asn1go
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestOER(t *testing.T) {
+	boolean := &Type{Kind: KindBoolean}
+	ctx := func(n int, t *Type) *Type {
+		c := *t
+		c.Tags = []TypeTag{{Tag: Tag{ClassContextSpecific, n}}}
+		return &c
+	}
+	enum := &Type{Kind: KindEnumerated, Named: []NamedNumber{{"a", 1, false}, {"b", 200, false}, {"c", -1, false}}}
+	tests := []struct {
+		t    *Type
+		v    interface{}
+		want string
+	}{
+		{boolean, true, "FF"},
+		{&Type{Kind: KindInteger}, int64(128), "02 00 80"},
+		{&Type{Kind: KindInteger}, int64(-1), "01 FF"},
+		{&Type{Kind: KindInteger, Range: &Range{Min: 0, Max: 255}}, int64(5), "05"},
+		{&Type{Kind: KindInteger, Range: &Range{Min: 0, Max: 65535}}, int64(5), "00 05"},
+		{&Type{Kind: KindInteger, Range: &Range{Min: -5, Max: 100}}, int64(-5), "FB"},
+		{&Type{Kind: KindInteger, Range: &Range{Min: 0, Max: 255, Extensible: true}}, int64(300), "02 01 2C"},
+		{&Type{Kind: KindInteger, Range: &Range{Min: 0, NoMax: true}}, int64(200), "01 C8"},
+		{&Type{Kind: KindInteger, Range: &Range{Min: 0, Max: 1 << 40}}, int64(1), "00 00 00 00 00 00 00 01"},
+		{enum, "a", "01"},
+		{enum, "b", "82 00 C8"},
+		{enum, "c", "81 FF"},
+		{&Type{Kind: KindNull}, nil, ""},
+		{&Type{Kind: KindOctetString, Size: &Range{Min: 2, Max: 2}}, []byte{1, 2}, "01 02"},
+		{&Type{Kind: KindOctetString}, []byte{1, 2}, "02 01 02"},
+		{&Type{Kind: KindBitString, Size: &Range{Min: 4, Max: 4}}, BitString{Bytes: []byte{0xA0}, BitLength: 4}, "A0"},
+		{&Type{Kind: KindBitString}, BitString{Bytes: []byte{0xA0}, BitLength: 4}, "02 04 A0"},
+		{&Type{Kind: KindIA5String, Size: &Range{Min: 3, Max: 3}}, "abc", "61 62 63"},
+		{&Type{Kind: KindUTF8String}, "abc", "03 61 62 63"},
+		{&Type{Kind: KindSequenceOf, Elem: boolean}, []interface{}{true, false, true}, "01 03 FF 00 FF"},
+		{
+			&Type{Kind: KindChoice, Components: []Component{{Name: "a", Type: ctx(1, boolean)}, {Name: "b", Type: ctx(100, boolean)}}},
+			map[string]interface{}{"b": true},
+			"BF 64 FF",
+		},
+		{
+			&Type{Kind: KindSequence, Components: []Component{
+				{Name: "a", Type: boolean, Optional: true},
+				{Name: "b", Type: boolean},
+				{Name: "c", Type: &Type{Kind: KindInteger}, Default: int64(3)},
+			}},
+			map[string]interface{}{"b": true, "c": int64(4)},
+			"40 FF 01 04",
+		},
+		{&Type{Kind: KindObjectIdentifier}, ObjectIdentifier{1, 2, 840}, "03 2A 86 48"},
+	}
+	for _, tt := range tests {
+		want := fromHex(tt.want)
+		b, err := EncodeOER(tt.t, tt.v)
+		if err != nil {
+			t.Errorf("EncodeOER(%v, %v): %v", tt.t, tt.v, err)
+			continue
+		}
+		if !bytes.Equal(b, want) {
+			t.Errorf("EncodeOER(%v, %v) = % X, want % X", tt.t, tt.v, b, want)
+		}
+		for _, decode := range []func(*Type, []byte, interface{}) error{DecodeOER, DecodeCOER} {
+			var got interface{}
+			if err := decode(tt.t, want, &got); err != nil {
+				t.Errorf("decoding %v from % X: %v", tt.t, want, err)
+				continue
+			}
+			if !reflect.DeepEqual(got, tt.v) {
+				t.Errorf("decoding %v from % X = %#v, want %#v", tt.t, want, got, tt.v)
+			}
+		}
+	}
+}
+
+func TestDecodeOERCanonical(t *testing.T) {
+	octets := &Type{Kind: KindOctetString}
+	tests := []struct {
+		t    *Type
+		data string
+		want interface{} // DecodeOER result; DecodeCOER rejects the data
+	}{
+		{&Type{Kind: KindBoolean}, "01", true},
+		{octets, "81 01 AB", []byte{0xAB}},
+		{&Type{Kind: KindSequenceOf, Elem: &Type{Kind: KindBoolean}}, "02 00 01 FF", []interface{}{true}},
+		{&Type{Kind: KindEnumerated, Named: []NamedNumber{{"a", 1, false}}}, "81 01", "a"},
+	}
+	for _, tt := range tests {
+		data := fromHex(tt.data)
+		var got interface{}
+		if err := DecodeOER(tt.t, data, &got); err != nil {
+			t.Errorf("DecodeOER(%v, %s): %v", tt.t, tt.data, err)
+		} else if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("DecodeOER(%v, %s) = %#v, want %#v", tt.t, tt.data, got, tt.want)
+		}
+		err := DecodeCOER(tt.t, data, &got)
+		if _, ok := err.(*OERSyntaxError); !ok {
+			t.Errorf("DecodeCOER(%v, %s): error %v, want OERSyntaxError", tt.t, tt.data, err)
+		}
+	}
+}
+
+func TestDecodeOERError(t *testing.T) {
+	tests := []struct {
+		t    *Type
+		data string
+	}{
+		{&Type{Kind: KindBoolean}, ""},
+		{&Type{Kind: KindInteger}, "02 01"},
+		{&Type{Kind: KindOctetString}, "05 AB"},
+		{&Type{Kind: KindInteger, Range: &Range{Min: 0, Max: 255}}, "05 06"},
+		{&Type{Kind: KindChoice, Components: []Component{{Name: "a", Type: &Type{Kind: KindBoolean, Tags: []TypeTag{{Tag: Tag{ClassContextSpecific, 1}}}}}}}, "82 FF"},
+	}
+	for _, tt := range tests {
+		var v interface{}
+		err := DecodeOER(tt.t, fromHex(tt.data), &v)
+		if _, ok := err.(*OERSyntaxError); !ok {
+			t.Errorf("DecodeOER(%v, %s): error %v, want OERSyntaxError", tt.t, tt.data, err)
+		}
+	}
+	if err := DecodeOER(&Type{Kind: KindBoolean}, []byte{0xFF}, true); err == nil {
+		t.Error("DecodeOER into a non-pointer: no error")
+	}
+}
+
+func TestMarshalOER(t *testing.T) {
+	type record struct {
+		Flag   bool
+		Number int
+		Data   []byte
+	}
+	v := record{true, 5, []byte{0xAB}}
+	want := fromHex("FF 01 05 01 AB")
+	b, err := MarshalOER(v)
+	if err != nil {
+		t.Fatalf("MarshalOER: %v", err)
+	}
+	if !bytes.Equal(b, want) {
+		t.Errorf("MarshalOER = % X, want % X", b, want)
+	}
+	var got record
+	if err := UnmarshalOER(want, &got); err != nil {
+		t.Fatalf("UnmarshalOER: %v", err)
+	}
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("UnmarshalOER = %+v, want %+v", got, v)
+	}
+	if err := UnmarshalOER(want, got); err == nil {
+		t.Error("UnmarshalOER into a non-pointer: no error")
+	}
+}
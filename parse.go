@@ -0,0 +1,407 @@
+package asn1go
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/openesim/asn1go/ast"
+)
+
+// ParseValue parses the ASN.1 value notation in data, which holds a single
+// value, and returns its syntax tree. If the value is written as a value
+// assignment, ParseValue returns the value assigned; use ParseAssignments
+// to keep its name and type.
+//
+// ParseValue checks the syntax of data only: unlike Unmarshal, it does not
+// decode the literals, so that an hstring with an odd number of digits or
+// a number too large for any Go type is returned as written.
+func ParseValue(data []byte) (ast.Node, error) {
+	return parseValue(data, nil)
+}
+
+// parseValue is ParseValue with the nodes allocated in a, or on the heap
+// if a is nil.
+func parseValue(data []byte, a *Arena) (ast.Node, error) {
+	as, err := parse(data, a)
+	if err != nil {
+		return nil, err
+	}
+	if len(as) > 1 {
+		return nil, &SyntaxError{msg: "more than one top-level value", err: ErrTrailingData, Offset: int64(as[1].Pos().Offset)}
+	}
+	// The comments of the assignment go with its value.
+	v := as[0].Value
+	c := v.Attached()
+	c.Doc = append(as[0].Doc, c.Doc...)
+	c.Trailing = append(c.Trailing, as[0].Trailing...)
+	return v, nil
+}
+
+// ParseAssignments parses the ASN.1 value notation in data, a sequence of
+// top-level values such as the value assignments of a profile package, and
+// returns their syntax trees.
+func ParseAssignments(data []byte) ([]*ast.Assignment, error) {
+	return parse(data, nil)
+}
+
+// parse is ParseAssignments with the nodes allocated in a, or on the heap
+// if a is nil.
+func parse(data []byte, a *Arena) ([]*ast.Assignment, error) {
+	p := newParser(a)
+	defer freeParser(p)
+	n, err := checkValid(data, &p.scan)
+	if err != nil {
+		return nil, err
+	}
+	p.init(data)
+	p.lines = lineStarts(p.lines[:0], data)
+
+	p.scan.reset()
+	p.scanWhile(scanSkipSpace)
+	as := make([]*ast.Assignment, n)
+	for i := range as {
+		as[i] = p.assignment()
+		p.nextTopValue()
+	}
+	p.attachComments(as)
+	return as, nil
+}
+
+// A parser builds the syntax tree of value notation, scanning it with the
+// methods of decodeState.
+type parser struct {
+	decodeState
+	lines []int // offsets of the beginnings of the lines of data
+	arena *Arena
+
+	// elements holds the elements of the brace-delimited values being
+	// parsed, innermost last, until they are copied to their nodes.
+	elements []ast.Node
+}
+
+var parserPool = sync.Pool{
+	New: func() interface{} {
+		return new(parser)
+	},
+}
+
+// newParser returns a parser from the pool that allocates nodes in a, or
+// on the heap if a is nil.
+func newParser(a *Arena) *parser {
+	p := parserPool.Get().(*parser)
+	p.arena = a
+	if a == nil {
+		p.arena = heapArena
+	}
+	return p
+}
+
+// freeParser returns p to the pool, keeping its buffers unless they grew
+// too large, but not the data it parsed.
+func freeParser(p *parser) {
+	p.clear()
+	if len(p.lines) > 1<<16 {
+		p.lines = nil
+	}
+	for i := range p.elements {
+		p.elements[i] = nil
+	}
+	p.elements = p.elements[:0]
+	p.arena = nil
+	parserPool.Put(p)
+}
+
+// lineStarts appends the offsets at which the lines of data begin to
+// lines.
+func lineStarts(lines []int, data []byte) []int {
+	lines = append(lines, 0)
+	for i, c := range data {
+		if c == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+	return lines
+}
+
+// pos returns the position of the byte at offset off.
+func (p *parser) pos(off int) ast.Pos {
+	i := sort.SearchInts(p.lines, off+1) - 1
+	return ast.Pos{Offset: off, Line: i + 1, Column: off - p.lines[i] + 1}
+}
+
+func (p *parser) rangeOf(start, end int) ast.Range {
+	return ast.Range{From: p.pos(start), To: p.pos(end)}
+}
+
+func (p *parser) ident(name []byte, start int) *ast.Ident {
+	return alloc(p.arena, &p.arena.idents, ast.Ident{Range: p.rangeOf(start, start+len(name)), Name: string(name)})
+}
+
+// assignment parses the top-level value that begins with the current
+// opcode, and the value assignment header in front of it.
+func (p *parser) assignment() *ast.Assignment {
+	a := alloc(p.arena, &p.arena.assignments, ast.Assignment{})
+	if p.opcode != scanBeginValueReference {
+		a.Value = p.value()
+		a.Range = ast.Range{From: a.Value.Pos(), To: a.Value.End()}
+		return a
+	}
+	start := p.readIndex()
+	name := p.name()
+	switch p.opcode {
+	case scanBeginTypeReference:
+		a.Name = p.ident(name, start)
+		a.Type = p.typeIdent()
+		p.scanWhile(scanSkipSpace)
+		a.Value = p.value()
+	case scanChoiceTag:
+		p.scanWhile(scanSkipSpace)
+		a.Value = p.choice(p.ident(name, start))
+	case scanBeginObject, scanBeginLiteral:
+		// The identifier was CONTAINING.
+		a.Value = p.containing(start)
+	default:
+		// A lone identifier, such as NULL.
+		a.Value = p.literal(name, start)
+	}
+	a.Range = ast.Range{From: p.pos(start), To: a.Value.End()}
+	return a
+}
+
+// typeIdent is like typeReference, but returns the type with its
+// position.
+func (p *parser) typeIdent() *ast.Ident {
+	start := p.readIndex()
+	b := []byte{p.data[start]}
+	end := start + 1
+	space := false
+	for {
+		p.scanNext()
+		switch p.opcode {
+		case scanAssignment:
+			return alloc(p.arena, &p.arena.idents, ast.Ident{Range: p.rangeOf(start, end), Name: strings.TrimRight(string(b), " :")})
+		case scanSkipSpace:
+			if !space {
+				// A hyphen before a comment is not part of the word.
+				for bytes.HasSuffix(b, []byte("-")) {
+					b = b[:len(b)-1]
+					end--
+				}
+			}
+			space = true
+		case scanContinue:
+			if space {
+				b = append(b, ' ')
+				space = false
+			}
+			c := p.data[p.readIndex()]
+			b = append(b, c)
+			if c != ':' {
+				end = p.readIndex() + 1
+			}
+		}
+	}
+}
+
+// value parses the value that begins with the current opcode, and reads
+// the following byte ahead.
+func (p *parser) value() ast.Node {
+	switch p.opcode {
+	default:
+		panic(phasePanicMsg)
+	case scanBeginObject:
+		v := p.object()
+		p.scanNext()
+		return v
+	case scanBeginLiteral:
+		item, start, choice := p.decodeState.literal()
+		if choice {
+			return p.choice(p.ident(item, start))
+		}
+		if string(item) == containingKeyword {
+			return p.containing(start)
+		}
+		return p.literal(item, start)
+	}
+}
+
+// choice parses the value of the CHOICE alternative name, which begins
+// with the current opcode.
+func (p *parser) choice(name *ast.Ident) *ast.ChoiceNode {
+	v := p.value()
+	return alloc(p.arena, &p.arena.choices, ast.ChoiceNode{Range: ast.Range{From: name.Pos(), To: v.End()}, Name: name, Value: v})
+}
+
+// containing parses the value contained in a containing value, which
+// begins with the current opcode. start is the offset of CONTAINING.
+func (p *parser) containing(start int) *ast.ContainingNode {
+	v := p.value()
+	return alloc(p.arena, &p.arena.containings, ast.ContainingNode{Range: ast.Range{From: p.pos(start), To: v.End()}, Value: v})
+}
+
+// literal returns the node of the literal item, which starts at offset
+// start.
+func (p *parser) literal(item []byte, start int) ast.Node {
+	r := p.rangeOf(start, start+len(item))
+	switch c := item[0]; {
+	case c == '\'':
+		digits, kind := stringDigits(item)
+		if kind == 'B' {
+			return alloc(p.arena, &p.arena.bits, ast.BitsNode{Range: r, Digits: string(digits)})
+		}
+		return alloc(p.arena, &p.arena.hexes, ast.HexNode{Range: r, Digits: string(digits)})
+	case c == '"':
+		return alloc(p.arena, &p.arena.strings, ast.StringNode{Range: r, Value: unquoteCString(item)})
+	case c == '-' || isDigit(c):
+		if bytes.ContainsAny(item, ".eE") {
+			return alloc(p.arena, &p.arena.reals, ast.RealNode{Range: r, Text: string(item)})
+		}
+		return alloc(p.arena, &p.arena.ints, ast.IntNode{Range: r, Text: string(item)})
+	}
+	switch s := string(item); s {
+	case "NULL":
+		return alloc(p.arena, &p.arena.nulls, ast.NullNode{Range: r})
+	case "TRUE", "FALSE":
+		return alloc(p.arena, &p.arena.bools, ast.BoolNode{Range: r, Value: s == "TRUE"})
+	default:
+		return alloc(p.arena, &p.arena.idents, ast.Ident{Range: r, Name: s})
+	}
+}
+
+// object parses a brace-delimited value. The opening brace has been read
+// already; object returns with the closing brace read.
+func (p *parser) object() ast.Node {
+	start := p.readIndex()
+	base := len(p.elements)
+	oid := false
+	p.scanWhile(scanSkipSpace)
+	for p.opcode != scanEndObject {
+		kind, name, start := p.elementHead()
+		var el ast.Node
+		switch kind {
+		case elementValue:
+			el = p.value()
+		case elementName:
+			el = p.literal(name, start)
+		case elementComponent:
+			f := alloc(p.arena, &p.arena.fields, ast.FieldNode{Name: p.ident(name, start), Value: p.value()})
+			f.Range = ast.Range{From: f.Name.Pos(), To: f.Value.End()}
+			el = f
+		case elementChoice:
+			el = p.choice(p.ident(name, start))
+		case elementContaining:
+			el = p.containing(start)
+		}
+		p.elements = append(p.elements, el)
+		if p.nextElement() {
+			oid = true
+		}
+	}
+	elements := p.arena.elements(p.elements[base:])
+	for i := base; i < len(p.elements); i++ {
+		p.elements[i] = nil
+	}
+	p.elements = p.elements[:base]
+	r := p.rangeOf(start, p.readIndex()+1)
+	if oid {
+		return alloc(p.arena, &p.arena.oids, ast.OIDNode{Range: r, Components: elements})
+	}
+	return alloc(p.arena, &p.arena.objects, ast.ObjectNode{Range: r, Elements: elements})
+}
+
+// attachComments attaches the comments of p.data to the top-level values
+// as and the elements of their brace-delimited values, as described for
+// ast.Comments.
+func (p *parser) attachComments(as []*ast.Assignment) {
+	comments, code := p.comments()
+	if len(comments) == 0 {
+		return
+	}
+
+	// The nodes that take comments, by position and by end.
+	var byPos []ast.Node
+	for _, a := range as {
+		byPos = append(byPos, a)
+		ast.Inspect(a, func(n ast.Node) bool {
+			if obj, ok := n.(*ast.ObjectNode); ok {
+				byPos = append(byPos, obj.Elements...)
+			}
+			return true
+		})
+	}
+	sort.SliceStable(byPos, func(i, j int) bool { return byPos[i].Pos().Offset < byPos[j].Pos().Offset })
+	byEnd := append([]ast.Node(nil), byPos...)
+	sort.SliceStable(byEnd, func(i, j int) bool { return byEnd[i].End().Offset < byEnd[j].End().Offset })
+
+	// between reports whether only the bytes of spaces occur in code
+	// between the offsets start and end.
+	between := func(start, end int, spaces string) bool {
+		return start <= end && len(bytes.Trim(code[start:end], spaces)) == 0
+	}
+	for _, c := range comments {
+		start, end := c.Pos().Offset, c.End().Offset
+		i := sort.Search(len(byEnd), func(i int) bool { return byEnd[i].End().Offset > start }) - 1
+		j := sort.Search(len(byPos), func(i int) bool { return byPos[i].Pos().Offset >= end })
+		var prev, next ast.Node
+		if i >= 0 {
+			prev = byEnd[i]
+		}
+		if j < len(byPos) {
+			next = byPos[j]
+		}
+		switch {
+		case prev != nil && between(prev.End().Offset, start, " \t,"):
+			prev.Attached().Trailing = append(prev.Attached().Trailing, c)
+		case next != nil && between(end, next.Pos().Offset, " \t\r\n\f\v"):
+			next.Attached().Doc = append(next.Attached().Doc, c)
+		case prev != nil && between(prev.End().Offset, start, " \t\r\n\f\v,"):
+			prev.Attached().Trailing = append(prev.Attached().Trailing, c)
+		default:
+			holder := ast.Node(as[len(as)-1])
+			for _, n := range byPos[:j] {
+				if n.End().Offset >= end {
+					holder = n
+				}
+			}
+			holder.Attached().Trailing = append(holder.Attached().Trailing, c)
+		}
+	}
+}
+
+// comments returns the comments of p.data, and a copy of p.data with the
+// comments blanked out.
+func (p *parser) comments() ([]*ast.Comment, []byte) {
+	var comments []*ast.Comment
+	var code []byte
+	data := p.data
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; {
+		case c == '"' || c == '\'':
+			// Skip the cstring, bstring or hstring.
+			if n := bytes.IndexByte(data[i+1:], c); n >= 0 {
+				i += n + 1
+			}
+		case c == '-' && i+1 < len(data) && data[i+1] == '-':
+			end := i + 2
+			for end < len(data) && data[end] != '\n' {
+				if data[end] == '-' && end+1 < len(data) && data[end+1] == '-' {
+					end += 2
+					break
+				}
+				end++
+			}
+			text := bytes.TrimRight(data[i:end], "\r")
+			comments = append(comments, &ast.Comment{Range: p.rangeOf(i, i+len(text)), Text: string(text)})
+			if code == nil {
+				code = append([]byte(nil), data...)
+			}
+			for k := i; k < end; k++ {
+				code[k] = ' '
+			}
+			i = end - 1
+		}
+	}
+	return comments, code
+}
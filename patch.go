@@ -0,0 +1,459 @@
+package asn1go
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/openesim/asn1go/ast"
+)
+
+// A Document is a value notation document being edited, such as a profile
+// package to customize. Its edits change the text of the nodes they touch
+// only: comments and the layout of the rest of the document are kept.
+//
+// The nodes of a Document are addressed by paths of component and CHOICE
+// alternative identifiers separated by dots, and zero-based indexes of the
+// elements of SEQUENCE OF values in brackets. The first identifier of a
+// path is the value reference of a value assignment, or the first index
+// that of a value without assignment among those values, as in "[0].a":
+//
+//	d, err := asn1go.ParseDocument(src)
+//	...
+//	err = d.Replace("value4.genericFileManagement.fileManagementCMD[0][1].createFCP.fileID", []byte{0x6F, 0x07})
+//	...
+//	src = d.Bytes()
+//
+// Here fileManagementCMD[0] is the first element of fileManagementCMD,
+// [1] the second element of that one, a SEQUENCE OF CHOICE, and
+// createFCP the value of that CHOICE. Similarly, the identifier of a
+// component not found in a brace-delimited value is looked up among the
+// alternatives of its CHOICE elements.
+//
+// Each edit returns an error, leaving the document unchanged, if its path
+// does not lead to a node or the document would no longer be valid value
+// notation. The nodes returned by Find and Assignments describe the
+// document as it was at the time of the call.
+type Document struct {
+	src         []byte
+	assignments []*ast.Assignment
+
+	// Indent is the indentation unit of the values written by the edits.
+	// ParseDocument sets it to the indentation of the first indented line
+	// of the document, or to two spaces.
+	Indent string
+}
+
+// ParseDocument parses the ASN.1 value notation document data, a sequence
+// of value assignments, for editing. The Document keeps data, which the
+// caller must not modify.
+func ParseDocument(data []byte) (*Document, error) {
+	as, err := parse(data, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Document{src: data, assignments: as, Indent: detectIndent(data)}, nil
+}
+
+// detectIndent returns the leading white space of the first indented line
+// of data, or two spaces.
+func detectIndent(data []byte) string {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		lead := line[:len(line)-len(bytes.TrimLeft(line, " \t"))]
+		if len(lead) > 0 && len(lead) < len(line) {
+			return string(lead)
+		}
+	}
+	return "  "
+}
+
+// Bytes returns the text of the document with its edits. The caller must
+// not modify it.
+func (d *Document) Bytes() []byte {
+	return d.src
+}
+
+// Assignments returns the syntax trees of the top-level values of the
+// document.
+func (d *Document) Assignments() []*ast.Assignment {
+	return d.assignments
+}
+
+// Find returns the node at path.
+func (d *Document) Find(path string) (ast.Node, error) {
+	t, err := d.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+	return t.node, nil
+}
+
+// Replace replaces the value at path with v, which is encoded as Marshal
+// encodes it. v may be a node of a syntax tree, such as one returned by
+// Find, whose attached comments are written with it. The comments of the
+// value replaced, which lie outside of it, are kept.
+func (d *Document) Replace(path string, v interface{}) error {
+	t, err := d.lookup(path)
+	if err != nil {
+		return err
+	}
+	n := t.node
+	text, err := d.marshal(v, d.lineIndent(n.Pos().Offset), d.indentOf(t.parent))
+	if err != nil {
+		return err
+	}
+	return d.splice(path, n.Pos().Offset, n.End().Offset, text)
+}
+
+// Insert inserts the component name v, or the element v if name is "",
+// into the brace-delimited value at path so that it becomes its i'th
+// element. An i of -1 appends the new element. v is encoded as Marshal
+// encodes it.
+func (d *Document) Insert(path string, i int, name string, v interface{}) error {
+	t, err := d.lookup(path)
+	if err != nil {
+		return err
+	}
+	obj, ok := t.node.(*ast.ObjectNode)
+	if !ok {
+		return &PathError{Path: path, Msg: "is not a brace-delimited value"}
+	}
+	n := len(obj.Elements)
+	if i == -1 {
+		i = n
+	}
+	if i < 0 || i > n {
+		return &PathError{Path: path, Msg: "has no element " + strconv.Itoa(i)}
+	}
+	if name != "" && !isValidIdentifier(name) {
+		return &PathError{Path: path, Msg: "cannot take a component " + strconv.Quote(name)}
+	}
+
+	// The new element starts a line of its own if its neighbour does, or
+	// if it goes into an empty value at the end of a line.
+	indent := d.indentOf(obj)
+	outer := d.lineIndent(obj.Pos().Offset)
+	var prefix, sep string
+	switch {
+	case n == 0:
+		prefix = outer + indent
+	case i < n:
+		prefix = d.lineIndent(obj.Elements[i].Pos().Offset)
+	default:
+		prefix = d.lineIndent(obj.Elements[n-1].Pos().Offset)
+	}
+	text, err := d.marshal(v, prefix, indent)
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		text = append([]byte(name+" "), text...)
+	}
+
+	switch {
+	case n == 0 && d.endsLine(obj.End().Offset):
+		text = append(append([]byte("{\n"+prefix), text...), "\n"+outer+"}"...)
+		return d.splice(path, obj.Pos().Offset, obj.End().Offset, text)
+	case n == 0:
+		text = append(append([]byte("{ "), text...), " }"...)
+		return d.splice(path, obj.Pos().Offset, obj.End().Offset, text)
+	case i < n:
+		sep = d.separator(obj.Elements[i])
+		off := obj.Elements[i].Pos().Offset
+		return d.splice(path, off, off, append(text, ","+sep...))
+	default:
+		sep = d.separator(obj.Elements[n-1])
+		off := obj.Elements[n-1].End().Offset
+		return d.splice(path, off, off, append([]byte(","+sep), text...))
+	}
+}
+
+// Remove removes the component, CHOICE element or element at path from
+// the brace-delimited value that holds it, or the top-level value that
+// path is the value reference or index of. Comments that follow the node
+// up to the next element go with it.
+func (d *Document) Remove(path string) error {
+	t, err := d.lookup(path)
+	if err != nil {
+		return err
+	}
+	if t.parent == nil {
+		if t.assignment < 0 {
+			return &PathError{Path: path, Msg: "is not an element of a brace-delimited value"}
+		}
+		start, end := d.assignments[t.assignment].Pos().Offset, len(d.src)
+		if t.assignment+1 < len(d.assignments) {
+			end = d.assignments[t.assignment+1].Pos().Offset
+		}
+		return d.splice(path, start, end, nil)
+	}
+
+	els := t.parent.Elements
+	switch {
+	case len(els) == 1:
+		return d.splice(path, t.parent.Pos().Offset, t.parent.End().Offset, []byte("{ }"))
+	case t.elem+1 < len(els):
+		return d.splice(path, els[t.elem].Pos().Offset, els[t.elem+1].Pos().Offset, nil)
+	default:
+		// The last element goes from the white space before it to its
+		// trailing comments, and the comma before it with it; the comments
+		// between the comma and the element stay.
+		prev, el := els[t.elem-1], els[t.elem]
+		comma := d.comma(prev, el)
+		cut := el.Pos().Offset
+		for cut > comma+1 && isSpace(d.src[cut-1]) {
+			cut--
+		}
+		end := el.End().Offset
+		if tr := el.Attached().Trailing; len(tr) > 0 {
+			end = tr[len(tr)-1].End().Offset
+		}
+		return d.splice(path, comma, end, d.src[comma+1:cut])
+	}
+}
+
+// comma returns the offset of the comma that separates the element el of
+// a brace-delimited value from the element prev before it, skipping the
+// comments between them.
+func (d *Document) comma(prev, el ast.Node) int {
+	var comments []*ast.Comment
+	comments = append(comments, prev.Attached().Trailing...)
+	comments = append(comments, el.Attached().Doc...)
+	off := prev.End().Offset
+next:
+	for off < el.Pos().Offset && d.src[off] != ',' {
+		for _, c := range comments {
+			if off == c.Pos().Offset {
+				off = c.End().Offset
+				continue next
+			}
+		}
+		off++
+	}
+	return off
+}
+
+// A PathError describes a path that does not lead to a node of a Document,
+// or to a node that the edit cannot be applied to.
+type PathError struct {
+	Path string
+	Msg  string
+}
+
+func (e *PathError) Error() string {
+	return "asn1go: path " + strconv.Quote(e.Path) + " " + e.Msg
+}
+
+// A pathTarget is the node a path leads to, with the brace-delimited value
+// that holds it as its element elem, or the index of the value assignment
+// whose value it is.
+type pathTarget struct {
+	node       ast.Node
+	parent     *ast.ObjectNode
+	elem       int
+	assignment int
+}
+
+// lookup returns the target of path.
+func (d *Document) lookup(path string) (pathTarget, error) {
+	steps, err := splitPath(path)
+	if err != nil {
+		return pathTarget{}, err
+	}
+	t := pathTarget{assignment: -1}
+	if steps[0][0] == '[' {
+		n, _ := strconv.Atoi(steps[0][1 : len(steps[0])-1])
+		for i, a := range d.assignments {
+			if a.Name != nil {
+				continue
+			}
+			if n == 0 {
+				t = pathTarget{node: a.Value, assignment: i}
+				break
+			}
+			n--
+		}
+		if t.node == nil {
+			return pathTarget{}, &PathError{Path: path, Msg: "has no value without assignment " + steps[0]}
+		}
+	} else {
+		for i, a := range d.assignments {
+			if a.Name != nil && a.Name.Name == steps[0] {
+				t = pathTarget{node: a.Value, assignment: i}
+				break
+			}
+		}
+		if t.node == nil {
+			return pathTarget{}, &PathError{Path: path, Msg: "has no value assignment " + steps[0]}
+		}
+	}
+	for _, step := range steps[1:] {
+		next, ok := t.step(step)
+		if !ok {
+			return pathTarget{}, &PathError{Path: path, Msg: "has no element " + step}
+		}
+		t = next
+	}
+	return t, nil
+}
+
+// step returns the target of the path step from t.node: an identifier or
+// an index in brackets.
+func (t pathTarget) step(step string) (pathTarget, bool) {
+	node := t.node
+	if c, ok := node.(*ast.ContainingNode); ok {
+		// A path goes through a containing value to the value contained.
+		node = c.Value
+	}
+	if step[0] == '[' {
+		i, err := strconv.Atoi(step[1 : len(step)-1])
+		switch n := node.(type) {
+		case *ast.ObjectNode:
+			if err == nil && i < len(n.Elements) {
+				el := n.Elements[i]
+				if f, ok := el.(*ast.FieldNode); ok {
+					el = f.Value
+				}
+				return pathTarget{node: el, parent: n, elem: i, assignment: -1}, true
+			}
+		case *ast.OIDNode:
+			if err == nil && i < len(n.Components) {
+				return pathTarget{node: n.Components[i], assignment: -1}, true
+			}
+		}
+		return pathTarget{}, false
+	}
+
+	switch n := node.(type) {
+	case *ast.ChoiceNode:
+		if n.Name.Name == step {
+			return pathTarget{node: n.Value, assignment: -1}, true
+		}
+	case *ast.ObjectNode:
+		for i, el := range n.Elements {
+			if f, ok := el.(*ast.FieldNode); ok && f.Name.Name == step {
+				return pathTarget{node: f.Value, parent: n, elem: i, assignment: -1}, true
+			}
+		}
+		for i, el := range n.Elements {
+			if c, ok := el.(*ast.ChoiceNode); ok && c.Name.Name == step {
+				return pathTarget{node: c.Value, parent: n, elem: i, assignment: -1}, true
+			}
+		}
+	}
+	return pathTarget{}, false
+}
+
+// splitPath splits path into identifiers and bracketed indexes.
+func splitPath(path string) ([]string, error) {
+	var steps []string
+	for rest := path; rest != ""; {
+		var step string
+		if rest[0] == '[' {
+			end := 1
+			for end < len(rest) && isDigit(rest[end]) {
+				end++
+			}
+			if end == 1 || end == len(rest) || rest[end] != ']' {
+				return nil, &PathError{Path: path, Msg: "has a malformed index"}
+			}
+			step, rest = rest[:end+1], rest[end+1:]
+		} else {
+			if len(steps) > 0 {
+				if rest[0] != '.' {
+					return nil, &PathError{Path: path, Msg: "is malformed"}
+				}
+				rest = rest[1:]
+			}
+			end := 0
+			for end < len(rest) && rest[end] != '.' && rest[end] != '[' {
+				end++
+			}
+			step, rest = rest[:end], rest[end:]
+			if !isValidIdentifier(step) {
+				return nil, &PathError{Path: path, Msg: "has a malformed identifier " + strconv.Quote(step)}
+			}
+		}
+		steps = append(steps, step)
+	}
+	if len(steps) == 0 {
+		return nil, &PathError{Path: path, Msg: "does not begin with a value reference"}
+	}
+	return steps, nil
+}
+
+// marshal encodes v for a line of the document indented by prefix, with
+// the indentation unit indent.
+func (d *Document) marshal(v interface{}, prefix, indent string) ([]byte, error) {
+	e := newEncodeState()
+	defer encodeStatePool.Put(e)
+
+	e.prefix, e.indent = prefix, indent
+	if err := e.marshal(v); err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), e.Bytes()...), nil
+}
+
+// lineIndent returns the leading white space of the line holding the byte
+// at offset off.
+func (d *Document) lineIndent(off int) string {
+	start := bytes.LastIndexByte(d.src[:off], '\n') + 1
+	line := d.src[start:]
+	return string(line[:len(line)-len(bytes.TrimLeft(line, " \t"))])
+}
+
+// indentOf returns the indentation unit of the elements of obj: the
+// indentation that the line of its first element adds to the line of its
+// opening brace, or d.Indent if there is none.
+func (d *Document) indentOf(obj *ast.ObjectNode) string {
+	if obj == nil || len(obj.Elements) == 0 {
+		return d.Indent
+	}
+	el := obj.Elements[0].Pos()
+	if el.Line == obj.Pos().Line || d.separator(obj.Elements[0]) == " " {
+		return d.Indent
+	}
+	outer, inner := d.lineIndent(obj.Pos().Offset), d.lineIndent(el.Offset)
+	if len(inner) <= len(outer) || inner[:len(outer)] != outer {
+		return d.Indent
+	}
+	return inner[len(outer):]
+}
+
+// endsLine reports whether only a comma, white space and comments follow
+// the byte before offset off on its line.
+func (d *Document) endsLine(off int) bool {
+	rest := d.src[off:]
+	if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+		rest = rest[:i]
+	}
+	rest = bytes.TrimLeft(rest, " \t,")
+	return len(rest) == 0 || bytes.HasPrefix(rest, []byte("--"))
+}
+
+// separator returns the white space that separates the element n from the
+// one before it: a newline and its indentation if it begins a line, and a
+// space otherwise.
+func (d *Document) separator(n ast.Node) string {
+	off := n.Pos().Offset
+	start := bytes.LastIndexByte(d.src[:off], '\n') + 1
+	if len(bytes.TrimLeft(d.src[start:off], " \t")) == 0 {
+		return "\n" + string(d.src[start:off])
+	}
+	return " "
+}
+
+// splice replaces d.src[start:end] with text, and parses the result.
+func (d *Document) splice(path string, start, end int, text []byte) error {
+	src := make([]byte, 0, len(d.src)-(end-start)+len(text))
+	src = append(src, d.src[:start]...)
+	src = append(src, text...)
+	src = append(src, d.src[end:]...)
+	as, err := parse(src, nil)
+	if err != nil {
+		return fmt.Errorf("asn1go: edit of %q makes the document invalid: %w", path, err)
+	}
+	d.src, d.assignments = src, as
+	return nil
+}
@@ -0,0 +1,197 @@
+package asn1go
+
+import (
+	"testing"
+
+	"github.com/openesim/asn1go/ast"
+)
+
+const patchDoc = `value1 PE ::= header : {
+  major-version 2, -- keep me
+  iccid '8901'H
+}
+-- between
+value2 PE ::= gfm : {
+    cmds {
+        {
+            createFCP : {
+                fileID '6F07'H
+            }
+        }
+    }
+}
+value3 End ::= end : { list { } }
+`
+
+func TestDocumentEdit(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(d *Document) error
+		want string // text of the edited lines, replacing those of old
+		old  string
+	}{
+		{
+			"replace integer",
+			func(d *Document) error { return d.Replace("value1.header.major-version", 3) },
+			"  major-version 3, -- keep me\n",
+			"  major-version 2, -- keep me\n",
+		},
+		{
+			"replace in SEQUENCE OF CHOICE",
+			func(d *Document) error {
+				return d.Replace("value2.gfm.cmds[0][0].createFCP.fileID", []byte{0x6F, 0x08})
+			},
+			"                fileID '6F08'H\n",
+			"                fileID '6F07'H\n",
+		},
+		{
+			"insert last",
+			func(d *Document) error { return d.Insert("value1.header", -1, "profileType", "x") },
+			"  iccid '8901'H,\n  profileType \"x\"\n",
+			"  iccid '8901'H\n",
+		},
+		{
+			"insert first",
+			func(d *Document) error {
+				return d.Insert("value2.gfm.cmds[0][0].createFCP", 0, "lcsi", []byte{5})
+			},
+			"                lcsi '05'H,\n                fileID '6F07'H\n",
+			"                fileID '6F07'H\n",
+		},
+		{
+			"insert structured",
+			func(d *Document) error {
+				return d.Insert("value2.gfm.cmds[0][0].createFCP", -1, "x", OrderedObject{{"a", 1}})
+			},
+			"                fileID '6F07'H,\n                x {\n                    a 1\n                }\n",
+			"                fileID '6F07'H\n",
+		},
+		{
+			"insert into empty",
+			func(d *Document) error { return d.Insert("value3.end.list", 0, "", 6) },
+			"value3 End ::= end : { list { 6 } }\n",
+			"value3 End ::= end : { list { } }\n",
+		},
+		{
+			"remove first component",
+			func(d *Document) error { return d.Remove("value1.header.major-version") },
+			"  iccid '8901'H\n",
+			"  major-version 2, -- keep me\n  iccid '8901'H\n",
+		},
+		{
+			"remove last component with its comments",
+			func(d *Document) error { return d.Remove("value1.header.iccid") },
+			"  major-version 2 -- keep me\n",
+			"  major-version 2, -- keep me\n  iccid '8901'H\n",
+		},
+		{
+			"remove only element",
+			func(d *Document) error { return d.Remove("value2.gfm.cmds[0][0].createFCP.fileID") },
+			"            createFCP : { }\n",
+			"            createFCP : {\n                fileID '6F07'H\n            }\n",
+		},
+		{
+			"remove value",
+			func(d *Document) error { return d.Remove("value3") },
+			"",
+			"value3 End ::= end : { list { } }\n",
+		},
+	}
+	for _, tt := range tests {
+		d, err := ParseDocument([]byte(patchDoc))
+		if err != nil {
+			t.Fatalf("ParseDocument: %v", err)
+		}
+		if err := tt.edit(d); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		want := replaceOnce(patchDoc, tt.old, tt.want)
+		if got := string(d.Bytes()); got != want {
+			t.Errorf("%s:\nhave %s\nwant %s", tt.name, got, want)
+		}
+		if _, err := ParseDocument(d.Bytes()); err != nil {
+			t.Errorf("%s: ParseDocument of the result: %v", tt.name, err)
+		}
+	}
+}
+
+// replaceOnce replaces the first occurrence of old in s with new.
+func replaceOnce(s, old, new string) string {
+	for i := 0; i+len(old) <= len(s); i++ {
+		if s[i:i+len(old)] == old {
+			return s[:i] + new + s[i+len(old):]
+		}
+	}
+	panic("replaceOnce: " + old + " not found")
+}
+
+func TestDocumentEditError(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(d *Document) error
+	}{
+		{"no assignment", func(d *Document) error { return d.Replace("value9", 1) }},
+		{"no component", func(d *Document) error { return d.Replace("value1.header.nope", 1) }},
+		{"no element", func(d *Document) error { return d.Replace("value2.gfm.cmds[5]", 1) }},
+		{"empty identifier", func(d *Document) error { return d.Replace("value1..x", 1) }},
+		{"bad index", func(d *Document) error { return d.Replace("value1.header[x]", 1) }},
+		{"empty path", func(d *Document) error { return d.Replace("", 1) }},
+		{"insert into non-brace", func(d *Document) error { return d.Insert("value1.header.iccid", 0, "a", 1) }},
+		{"insert out of range", func(d *Document) error { return d.Insert("value1.header", 3, "a", 1) }},
+		{"insert bad name", func(d *Document) error { return d.Insert("value1.header", 0, "1a", 1) }},
+		{"remove CHOICE value", func(d *Document) error { return d.Remove("value1.header") }},
+	}
+	for _, tt := range tests {
+		d, err := ParseDocument([]byte(patchDoc))
+		if err != nil {
+			t.Fatalf("ParseDocument: %v", err)
+		}
+		err = tt.edit(d)
+		if _, ok := err.(*PathError); !ok {
+			t.Errorf("%s: error %v, want PathError", tt.name, err)
+		}
+		if string(d.Bytes()) != patchDoc {
+			t.Errorf("%s: document changed", tt.name)
+		}
+	}
+	d, _ := ParseDocument([]byte(patchDoc))
+	if err := d.Replace("value1.header.major-version", ChoiceValue{"-bad", 1}); err == nil {
+		t.Error("Replace with an invalid value: no error")
+	}
+}
+
+func TestDocumentFind(t *testing.T) {
+	d, err := ParseDocument([]byte(patchDoc))
+	if err != nil {
+		t.Fatalf("ParseDocument: %v", err)
+	}
+	if d.Indent != "  " {
+		t.Errorf("Indent = %q, want two spaces", d.Indent)
+	}
+	if as := d.Assignments(); len(as) != 3 || as[1].Name.Name != "value2" || as[1].Type.Name != "PE" {
+		t.Errorf("Assignments = %v", as)
+	}
+	n, err := d.Find("value2.gfm.cmds[0][0].createFCP.fileID")
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if h, ok := n.(*ast.HexNode); !ok || h.Digits != "6F07" {
+		t.Errorf("Find = %#v, want the HexNode 6F07", n)
+	}
+	plain, err := ParseDocument([]byte("v T ::= 1\n{ a 1 }\nheader : { b 2 }\n"))
+	if err != nil {
+		t.Fatalf("ParseDocument: %v", err)
+	}
+	if n, err := plain.Find("[1].header.b"); err != nil {
+		t.Errorf("Find by index: %v", err)
+	} else if i, ok := n.(*ast.IntNode); !ok || i.Text != "2" {
+		t.Errorf("Find by index = %#v, want the IntNode 2", n)
+	}
+	if _, err := plain.Find("[2]"); err == nil {
+		t.Error("Find of a missing index: no error")
+	}
+	if _, err := ParseDocument([]byte("v T ::= { a")); err == nil {
+		t.Error("ParseDocument of invalid value notation: no error")
+	}
+}
@@ -0,0 +1,1189 @@
+package asn1go
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EncodeUPER returns the unaligned PER encoding of v as a value of the
+// ASN.1 type t. It takes the same values as EncodeDER, except that values
+// of an open type are their complete PER encoding as a []byte.
+//
+// The Size and Range constraints of t select the encodings of lengths and
+// integers: a value within a constrained range takes the fewest bits that
+// can tell its range apart, and a string of a fixed size up to 64K has no
+// length at all. Values outside a range that is not extensible cannot be
+// encoded. Tags only matter for the order of the components of a SET and
+// the numbering of the alternatives of a CHOICE.
+//
+// Components equal to their DEFAULT value are left out, as the canonical
+// PER requires. The elements of a SET OF are encoded in the order given.
+// Extension additions of an extensible type are encoded as open types,
+// and the Extensions of a value follow the additions that the type knows.
+func EncodeUPER(t *Type, v interface{}) ([]byte, error) {
+	var e perEncoder
+	return e.marshal(t, reflect.ValueOf(v))
+}
+
+// MarshalUPER returns the unaligned PER encoding of v, as a value of the
+// ASN.1 type TypeOf derives from the type of v.
+func MarshalUPER(v interface{}) ([]byte, error) {
+	t, err := TypeOf(v)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeUPER(t, v)
+}
+
+// DecodeUPER parses data as the unaligned PER encoding of a value of the
+// ASN.1 type t and stores the result in the value pointed to by v, which
+// must be a non-nil pointer. It stores values as DecodeDER does; values
+// of an open type are stored as their PER encoding in a []byte, and so are
+// unknown extension additions in Extensions.
+//
+// Malformed input and encodings that do not match t are reported as a
+// PERSyntaxError. If a value is not appropriate for a given target type,
+// DecodeUPER skips that value and completes the decoding as best it can,
+// and then returns an UnmarshalTypeError describing the earliest such
+// error.
+func DecodeUPER(t *Type, data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+	d := perDecoder{data: data}
+	return d.unmarshal(t, rv)
+}
+
+// UnmarshalUPER parses the unaligned PER encoded data and stores the
+// result in the value pointed to by v, which must be a non-nil pointer.
+// The ASN.1 type of the encoding is read from the Go type of v as TypeOf
+// describes.
+func UnmarshalUPER(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+	t, err := TypeOf(v)
+	if err != nil {
+		return err
+	}
+	return DecodeUPER(t, data, v)
+}
+
+// A PERSyntaxError describes malformed PER input.
+type PERSyntaxError struct {
+	Msg    string
+	Offset int64 // error occurred after reading Offset bits
+}
+
+func (e *PERSyntaxError) Error() string {
+	return "asn1go: PER syntax error at bit " + strconv.FormatInt(e.Offset, 10) + ": " + e.Msg
+}
+
+// perFragment is the number of octets, bits, characters or elements in a
+// fragment of a value whose length does not fit a single length
+// determinant, and perMaxFixed the size below which a constrained length
+// is encoded as a constrained whole number.
+const (
+	perFragment = 16 << 10
+	perMaxFixed = 64 << 10
+)
+
+// numericAlphabet holds the characters of NumericString in the order PER
+// numbers them.
+const numericAlphabet = " 0123456789"
+
+// A binaryEncoder holds what the PER and OER encoders share: the path to
+// the value being encoded, for ValueErrors.
+type binaryEncoder struct {
+	path []string // identifiers of the components being encoded
+}
+
+// error aborts the encoding with a ValueError for the value of type t.
+func (e *binaryEncoder) error(t *Type, format string, args ...interface{}) {
+	panic(asn1Error{&ValueError{strings.Join(e.path, "."), t.String(), fmt.Sprintf(format, args...)}})
+}
+
+// mismatch aborts the encoding because v does not fit type t.
+func (e *binaryEncoder) mismatch(t *Type, v reflect.Value) {
+	if !v.IsValid() {
+		e.error(t, "missing value")
+	}
+	e.error(t, "unsupported Go type %s", v.Type())
+}
+
+// integerOf returns the INTEGER or ENUMERATED value v, which may also be
+// given by name.
+func (e *binaryEncoder) integerOf(t *Type, v reflect.Value) int64 {
+	if v.Kind() == reflect.String {
+		s := v.String()
+		if n, ok := t.NamedValue(s); ok {
+			return n
+		}
+		if v.Type() == numberType {
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return n
+			}
+		}
+		e.error(t, "unknown named value %q", s)
+	}
+	n, ok := integerOf(v)
+	if !ok {
+		switch v.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			e.error(t, "value %d too large", v.Uint())
+		case reflect.Struct:
+			if v.Type() == bigIntType {
+				e.error(t, "value %s too large", bigIntOf(v))
+			}
+		}
+		e.mismatch(t, v)
+	}
+	return n
+}
+
+// realOf returns the REAL value v.
+func (e *binaryEncoder) realOf(t *Type, v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint())
+	}
+	e.mismatch(t, v)
+	panic("unreachable")
+}
+
+// bitStringOf returns the BIT STRING value v, a BitString, the octets of
+// a byte slice or array or the identifiers of the named bits set.
+func (e *binaryEncoder) bitStringOf(t *Type, v reflect.Value) BitString {
+	if v.IsValid() && v.Type() == bitStringType {
+		return v.Interface().(BitString)
+	}
+	b, ok := bytesOf(v)
+	if names, isList := stringsOf(v); !ok && isList {
+		bs, err := namedBits(t.Named, names)
+		if err != nil {
+			e.error(t, "%v", err)
+		}
+		return bs
+	}
+	if !ok || v.Kind() == reflect.String {
+		e.mismatch(t, v)
+	}
+	return BitString{Bytes: b, BitLength: 8 * len(b)}
+}
+
+// stringOf returns the character string value v of type t.
+func (e *binaryEncoder) stringOf(t *Type, v reflect.Value) string {
+	if s, ok, err := timeString(t, v); ok {
+		if err != nil {
+			e.error(t, "%v", err)
+		}
+		return s
+	}
+	if v.Kind() != reflect.String {
+		e.mismatch(t, v)
+	}
+	s := v.String()
+	if !validString(t.Kind, s) {
+		e.error(t, "invalid character in %q", s)
+	}
+	return s
+}
+
+// A perEncoder encodes values in unaligned PER.
+type perEncoder struct {
+	binaryEncoder
+	buf []byte
+	n   int // bits written
+}
+
+func (e *perEncoder) marshal(t *Type, v reflect.Value) (b []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if je, ok := r.(asn1Error); ok {
+				err = je.error
+			} else {
+				panic(r)
+			}
+		}
+	}()
+	e.value(t, v)
+	if e.n == 0 {
+		// An empty encoding is replaced by a single zero octet.
+		return []byte{0}, nil
+	}
+	return e.buf, nil
+}
+
+// writeBits writes the low n bits of u, most significant first.
+func (e *perEncoder) writeBits(u uint64, n int) {
+	for i := n - 1; i >= 0; i-- {
+		if e.n%8 == 0 {
+			e.buf = append(e.buf, 0)
+		}
+		if u>>uint(i)&1 != 0 {
+			e.buf[len(e.buf)-1] |= 0x80 >> uint(e.n%8)
+		}
+		e.n++
+	}
+}
+
+// writeBytes writes the first n bits of b.
+func (e *perEncoder) writeBytes(b []byte, n int) {
+	for i := 0; n > 0; i++ {
+		k := 8
+		if n < 8 {
+			k = n
+		}
+		e.writeBits(uint64(b[i]>>uint(8-k)), k)
+		n -= k
+	}
+}
+
+// writeBit writes a single bit.
+func (e *perEncoder) writeBit(set bool) {
+	if set {
+		e.writeBits(1, 1)
+	} else {
+		e.writeBits(0, 1)
+	}
+}
+
+// constrained writes n as a constrained whole number in the range
+// lb..ub, in the fewest bits that hold ub-lb.
+func (e *perEncoder) constrained(n, lb, ub int64) {
+	e.writeBits(uint64(n)-uint64(lb), bits.Len64(uint64(ub)-uint64(lb)))
+}
+
+// lengths writes a length determinant for n items, constrained by size,
+// and the items themselves with item, which writes the items from i to j.
+// Lengths of 16K items or more are split into fragments, each with its
+// own length determinant.
+func (e *perEncoder) lengths(t *Type, n int, size *Range, item func(i, j int)) {
+	if size != nil {
+		inRoot := size.contains(int64(n))
+		if size.Extensible {
+			e.writeBit(!inRoot)
+		} else if !inRoot {
+			e.error(t, "size %d out of range %v", n, size)
+		}
+		if inRoot && !size.NoMax && size.Max < perMaxFixed {
+			lb := size.Min
+			if size.NoMin {
+				lb = 0
+			}
+			e.constrained(int64(n), lb, size.Max)
+			item(0, n)
+			return
+		}
+	}
+	for i := 0; ; {
+		switch rest := n - i; {
+		case rest < 128:
+			e.writeBits(uint64(rest), 8)
+			item(i, n)
+			return
+		case rest < perFragment:
+			e.writeBits(0x8000|uint64(rest), 16)
+			item(i, n)
+			return
+		default:
+			m := rest / perFragment
+			if m > 4 {
+				m = 4
+			}
+			e.writeBits(0xc0|uint64(m), 8)
+			item(i, i+m*perFragment)
+			i += m * perFragment
+		}
+	}
+}
+
+// octets writes b after a length determinant in octets.
+func (e *perEncoder) octets(t *Type, b []byte, size *Range) {
+	e.lengths(t, len(b), size, func(i, j int) { e.writeBytes(b[i:j], 8*(j-i)) })
+}
+
+// value writes the encoding of v as a value of type t.
+func (e *perEncoder) value(t *Type, v reflect.Value) {
+	v = derIndirect(v)
+	switch t.Kind {
+	case KindBoolean:
+		if v.Kind() != reflect.Bool {
+			e.mismatch(t, v)
+		}
+		e.writeBit(v.Bool())
+
+	case KindInteger:
+		e.integer(t, e.integerOf(t, v))
+
+	case KindEnumerated:
+		n := e.integerOf(t, v)
+		if i, ok := enumeratedIndex(t, n, false); ok {
+			if t.Extensible {
+				e.writeBit(false)
+			}
+			e.constrained(int64(i), 0, int64(len(enumeratedValues(t, false))-1))
+		} else if i, ok := enumeratedIndex(t, n, true); ok {
+			e.writeBit(true)
+			e.smallNumber(t, i)
+		} else {
+			e.error(t, "unknown value %d", n)
+		}
+
+	case KindReal:
+		e.octets(t, appendReal(nil, e.realOf(t, v)), nil)
+
+	case KindBitString:
+		bs := e.bitStringOf(t, v)
+		e.lengths(t, bs.BitLength, t.Size, func(i, j int) {
+			// Fragments start on an octet of bs.
+			e.writeBytes(bs.Bytes[i/8:], j-i)
+		})
+
+	case KindOctetString:
+		b, ok := bytesOf(v)
+		if !ok {
+			e.mismatch(t, v)
+		}
+		e.octets(t, b, t.Size)
+
+	case KindNull:
+
+	case KindObjectIdentifier:
+		oid, ok := objectIdentifierOf(v)
+		if !ok {
+			e.mismatch(t, v)
+		}
+		b, err := appendOID(nil, oid)
+		if err != nil {
+			e.error(t, "%v", err)
+		}
+		e.octets(t, b, nil)
+
+	case KindRelativeOID:
+		oid, ok := objectIdentifierOf(v)
+		if !ok {
+			e.mismatch(t, v)
+		}
+		e.octets(t, appendRelativeOID(nil, oid), nil)
+
+	case KindUTF8String, KindOIDIRI, KindRelativeOIDIRI:
+		s := e.stringOf(t, v)
+		// The size of a UTF8String counts characters, not the octets PER
+		// encodes, so it does not apply.
+		e.octets(t, []byte(s), nil)
+
+	case KindNumericString, KindPrintableString, KindIA5String, KindVisibleString,
+		KindUTCTime, KindGeneralizedTime:
+		s := e.stringOf(t, v)
+		size := t.Size
+		if t.Kind == KindUTCTime || t.Kind == KindGeneralizedTime {
+			size = nil
+		}
+		e.lengths(t, len(s), size, func(i, j int) {
+			for _, c := range []byte(s[i:j]) {
+				if t.Kind == KindNumericString {
+					e.writeBits(uint64(strings.IndexByte(numericAlphabet, c)), 4)
+				} else {
+					e.writeBits(uint64(c), 7)
+				}
+			}
+		})
+
+	case KindSequence, KindSet:
+		e.components(t, v)
+
+	case KindSequenceOf, KindSetOf:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			e.mismatch(t, v)
+		}
+		e.lengths(t, v.Len(), t.Size, func(i, j int) {
+			for ; i < j; i++ {
+				e.path = append(e.path, strconv.Itoa(i))
+				e.value(t.Elem, v.Index(i))
+				e.path = e.path[:len(e.path)-1]
+			}
+		})
+
+	case KindChoice:
+		e.choice(t, v)
+
+	case KindAny:
+		raw, ok := bytesOf(v)
+		if !ok || v.Kind() == reflect.String {
+			e.error(t, "open type value must be a PER encoding")
+		}
+		e.octets(t, raw, nil)
+
+	default:
+		e.error(t, "unsupported type")
+	}
+}
+
+// integer writes the INTEGER value n: as a constrained whole number if t
+// has a range with both bounds, as the octets of n less the lower bound
+// if it has only that one, and in two's complement otherwise. A value
+// outside an extensible range is encoded as if there was no range.
+func (e *perEncoder) integer(t *Type, n int64) {
+	r := t.Range
+	if r != nil {
+		inRoot := r.contains(n)
+		if r.Extensible {
+			e.writeBit(!inRoot)
+		} else if !inRoot {
+			e.error(t, "value %d out of range %v", n, r)
+		}
+		if !inRoot {
+			r = nil
+		}
+	}
+	switch {
+	case r == nil || r.NoMin:
+		e.octets(t, appendInt64(nil, n), nil)
+	case !r.NoMax:
+		e.constrained(n, r.Min, r.Max)
+	default:
+		u := uint64(n) - uint64(r.Min)
+		b := appendUint64(nil, u)
+		if len(b) > 1 && b[0] == 0 {
+			b = b[1:]
+		}
+		e.octets(t, b, nil)
+	}
+}
+
+// enumeratedValues returns the values of the root of the ENUMERATED type
+// t, or its extension additions if extension is set, in ascending order,
+// which is the order PER numbers them in.
+func enumeratedValues(t *Type, extension bool) []int64 {
+	var values []int64
+	for _, n := range t.Named {
+		if n.Extension == extension {
+			values = append(values, n.Value)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	return values
+}
+
+// enumeratedIndex returns the index of the value n among the values of
+// the root of the ENUMERATED type t, or among its extension additions if
+// extension is set.
+func enumeratedIndex(t *Type, n int64, extension bool) (int, bool) {
+	values := enumeratedValues(t, extension)
+	i := sort.Search(len(values), func(i int) bool { return values[i] >= n })
+	return i, i < len(values) && values[i] == n
+}
+
+// smallNumber writes n as a normally small non-negative whole number: in
+// 6 bits after a zero bit if it is less than 64, and in octets after a
+// one bit and a length otherwise.
+func (e *perEncoder) smallNumber(t *Type, n int) {
+	if n < 64 {
+		e.writeBits(uint64(n), 7)
+		return
+	}
+	e.writeBit(true)
+	b := appendUint64(nil, uint64(n))
+	if len(b) > 1 && b[0] == 0 {
+		b = b[1:]
+	}
+	e.octets(t, b, nil)
+}
+
+// smallLength writes the length n, at least 1, of the bitmap of the
+// extension additions of a SEQUENCE or SET as a normally small length: in
+// 6 bits after a zero bit if it is 64 or less, and as a length determinant
+// after a one bit otherwise.
+func (e *perEncoder) smallLength(t *Type, n int) {
+	if n <= 64 {
+		e.writeBits(uint64(n-1), 7)
+		return
+	}
+	e.writeBit(true)
+	e.lengths(t, n, nil, func(i, j int) {})
+}
+
+// openType writes what encode writes, with an encoder of its own, as an
+// open type: its octets after a length.
+func (e *perEncoder) openType(t *Type, encode func(e *perEncoder)) {
+	sub := perEncoder{binaryEncoder: binaryEncoder{path: e.path}}
+	encode(&sub)
+	if sub.n == 0 {
+		sub.buf = []byte{0}
+	}
+	e.octets(t, sub.buf, nil)
+}
+
+// components writes the SEQUENCE or SET value v: a bit for each OPTIONAL
+// and DEFAULT component of the root telling whether it is present, and
+// then the present components. The root of an extensible type is preceded
+// by a bit telling whether there are extension additions, and followed by
+// a bitmap of the additions that are present and the additions as open
+// types.
+func (e *perEncoder) components(t *Type, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			e.mismatch(t, v)
+		}
+		for _, k := range v.MapKeys() {
+			if t.Component(k.String()) == nil && !(t.Extensible && k.String() == "...") {
+				e.error(t, "unknown component %s", k.String())
+			}
+		}
+	default:
+		e.mismatch(t, v)
+	}
+
+	values := make([]reflect.Value, len(t.Components))
+	for i := range t.Components {
+		c := &t.Components[i]
+		cv, ok := componentValue(v, c.Name)
+		switch {
+		case !ok && !c.Optional && c.Default == nil && !c.Extension:
+			e.error(t, "missing component %s", c.Name)
+		case ok && c.Default != nil && equalValue(c.Type, cv, reflect.ValueOf(c.Default)):
+			ok = false
+		}
+		if ok {
+			values[i] = cv
+		}
+	}
+	additions := extensionOrder(t)
+	present := make([]bool, len(additions))
+	extended := false
+	for k, a := range additions {
+		for _, i := range a {
+			present[k] = present[k] || values[i].IsValid()
+		}
+		extended = extended || present[k]
+	}
+	ext, _ := e.extensions(t, v)
+	for _, b := range ext {
+		extended = extended || b != nil
+	}
+
+	if t.Extensible {
+		e.writeBit(extended)
+	}
+	order := componentOrder(t)
+	e.present(t, order, values)
+	if !extended {
+		return
+	}
+	e.smallLength(t, len(additions)+len(ext))
+	for _, p := range present {
+		e.writeBit(p)
+	}
+	for _, b := range ext {
+		e.writeBit(b != nil)
+	}
+	for k, a := range additions {
+		if !present[k] {
+			continue
+		}
+		c := &t.Components[a[0]]
+		e.openType(t, func(sub *perEncoder) {
+			if c.Group == 0 {
+				sub.component(c, values[a[0]])
+				return
+			}
+			// The components of an extension addition group are
+			// encoded as those of a SEQUENCE.
+			for _, i := range a {
+				if c := &t.Components[i]; !values[i].IsValid() && !c.Optional && c.Default == nil {
+					sub.error(t, "missing component %s", c.Name)
+				}
+			}
+			sub.present(t, a, values)
+		})
+	}
+	for _, b := range ext {
+		if b != nil {
+			e.octets(t, b, nil)
+		}
+	}
+}
+
+// present writes the bits telling which of the OPTIONAL and DEFAULT
+// components among the components order of t are present, and then the
+// present ones, whose values are given by values.
+func (e *perEncoder) present(t *Type, order []int, values []reflect.Value) {
+	for _, i := range order {
+		if c := &t.Components[i]; c.Optional || c.Default != nil {
+			e.writeBit(values[i].IsValid())
+		}
+	}
+	for _, i := range order {
+		if values[i].IsValid() {
+			e.component(&t.Components[i], values[i])
+		}
+	}
+}
+
+// component writes the value v of the component c.
+func (e *perEncoder) component(c *Component, v reflect.Value) {
+	e.path = append(e.path, c.Name)
+	e.value(c.Type, v)
+	e.path = e.path[:len(e.path)-1]
+}
+
+// choice writes the CHOICE value v: the index of its alternative, and
+// then the value of the alternative. The index of an alternative of the
+// root of an extensible type is preceded by a zero bit; that of an
+// extension addition is a normally small number after a one bit, and its
+// value an open type.
+func (e *perEncoder) choice(t *Type, v reflect.Value) {
+	name, av, ok := choiceOf(v)
+	if !ok {
+		e.mismatch(t, v)
+	}
+	order := componentOrder(t)
+	for k, i := range order {
+		c := &t.Components[i]
+		if c.Name != name {
+			continue
+		}
+		if t.Extensible {
+			e.writeBit(false)
+		}
+		e.constrained(int64(k), 0, int64(len(order)-1))
+		e.component(c, av)
+		return
+	}
+	additions := extensionOrder(t)
+	for k, a := range additions {
+		c := &t.Components[a[0]]
+		if c.Name != name {
+			continue
+		}
+		e.writeBit(true)
+		e.smallNumber(t, k)
+		e.openType(t, func(sub *perEncoder) { sub.component(c, av) })
+		return
+	}
+	if t.Extensible && name == "..." {
+		ext, ok := extensionsOf(av)
+		if !ok || len(ext) == 0 || ext[len(ext)-1] == nil {
+			e.error(t, "unknown extension alternative must be Extensions")
+		}
+		e.writeBit(true)
+		e.smallNumber(t, len(additions)+len(ext)-1)
+		e.octets(t, ext[len(ext)-1], nil)
+		return
+	}
+	e.error(t, "unknown alternative %s", name)
+}
+
+// extensions returns the Extensions of the SEQUENCE or SET value v of type
+// t, reporting false if it has none.
+func (e *binaryEncoder) extensions(t *Type, v reflect.Value) (Extensions, bool) {
+	if !t.Extensible {
+		return nil, false
+	}
+	ev, ok := componentValue(v, "...")
+	if !ok {
+		return nil, false
+	}
+	ext, ok := extensionsOf(ev)
+	if !ok {
+		e.error(t, "unsupported Go type %s for extensions", ev.Type())
+	}
+	return ext, true
+}
+
+// componentOrder returns the indexes of the components of the root of t
+// in the order PER encodes them in: as defined for a SEQUENCE, and in the
+// canonical order of their tags for a SET or CHOICE.
+func componentOrder(t *Type) []int {
+	var order []int
+	for i := range t.Components {
+		if !t.Components[i].Extension {
+			order = append(order, i)
+		}
+	}
+	if t.Kind == KindSet || t.Kind == KindChoice {
+		sort.SliceStable(order, func(i, j int) bool {
+			return canonicalTag(t.Components[order[i]].Type).less(canonicalTag(t.Components[order[j]].Type))
+		})
+	}
+	return order
+}
+
+// extensionOrder returns the extension additions of t in the order PER
+// encodes them in, each as the indexes of its components: one component
+// for an addition of its own, and those of the group for an extension
+// addition group of a SEQUENCE or SET. The alternatives of a CHOICE are
+// additions of their own, in the canonical order of their tags.
+func extensionOrder(t *Type) [][]int {
+	var additions [][]int
+	for i := range t.Components {
+		c := &t.Components[i]
+		if !c.Extension {
+			continue
+		}
+		if n := len(additions); n > 0 && c.Group != 0 && t.Kind != KindChoice && t.Components[additions[n-1][0]].Group == c.Group {
+			additions[n-1] = append(additions[n-1], i)
+			continue
+		}
+		additions = append(additions, []int{i})
+	}
+	if t.Kind == KindChoice {
+		sort.SliceStable(additions, func(i, j int) bool {
+			return canonicalTag(t.Components[additions[i][0]].Type).less(canonicalTag(t.Components[additions[j][0]].Type))
+		})
+	}
+	return additions
+}
+
+// canonicalTag returns the tag that orders values of type t among the
+// components of a SET or alternatives of a CHOICE: its outermost tag,
+// or the least tag of the alternatives of an untagged CHOICE.
+func canonicalTag(t *Type) Tag {
+	if tag, ok := t.Tag(); ok {
+		return tag
+	}
+	var least Tag
+	for i := range t.Components {
+		tag := canonicalTag(t.Components[i].Type)
+		if i == 0 || tag.less(least) {
+			least = tag
+		}
+	}
+	return least
+}
+
+// less orders tags by class first, and then by number.
+func (t Tag) less(u Tag) bool {
+	if t.Class != u.Class {
+		return t.Class < u.Class
+	}
+	return t.Number < u.Number
+}
+
+// A perDecoder decodes unaligned PER encodings.
+type perDecoder struct {
+	storer
+	data []byte
+	pos  int // bits read
+	base int // offset of data in the input, in bits, for open types
+}
+
+func (d *perDecoder) unmarshal(t *Type, v reflect.Value) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if je, ok := r.(asn1Error); ok {
+				err = je.error
+			} else {
+				panic(r)
+			}
+		}
+	}()
+	d.value(t, v)
+	if n := (d.pos + 7) / 8; n != len(d.data) && !(d.pos == 0 && len(d.data) == 1 && d.data[0] == 0) {
+		d.syntaxError("trailing data after top-level value")
+	}
+	return d.savedError
+}
+
+// syntaxError aborts the decoding with a PERSyntaxError at the current
+// bit.
+func (d *perDecoder) syntaxError(format string, args ...interface{}) {
+	panic(asn1Error{&PERSyntaxError{fmt.Sprintf(format, args...), int64(d.base + d.pos)}})
+}
+
+// off returns the offset of the octet being read, for UnmarshalTypeErrors.
+func (d *perDecoder) off() int {
+	return (d.base + d.pos) / 8
+}
+
+// readBits reads n bits, most significant first.
+func (d *perDecoder) readBits(n int) uint64 {
+	if n > 8*len(d.data)-d.pos {
+		d.syntaxError("truncated encoding")
+	}
+	var u uint64
+	for i := 0; i < n; i++ {
+		u = u<<1 | uint64(d.data[d.pos/8]>>uint(7-d.pos%8)&1)
+		d.pos++
+	}
+	return u
+}
+
+// readBytes reads n bits into octets, the last of which is padded with
+// zero bits.
+func (d *perDecoder) readBytes(dst []byte, n int) []byte {
+	for ; n >= 8; n -= 8 {
+		dst = append(dst, byte(d.readBits(8)))
+	}
+	if n > 0 {
+		dst = append(dst, byte(d.readBits(n)<<uint(8-n)))
+	}
+	return dst
+}
+
+// constrained reads a constrained whole number in the range lb..ub.
+func (d *perDecoder) constrained(lb, ub int64) int64 {
+	u := d.readBits(bits.Len64(uint64(ub) - uint64(lb)))
+	if u > uint64(ub)-uint64(lb) {
+		d.syntaxError("value out of range %d..%d", lb, ub)
+	}
+	return int64(uint64(lb) + u)
+}
+
+// lengths reads a length determinant constrained by size, and the items
+// it counts with item, which reads n items. Fragmented lengths call item
+// for each fragment.
+func (d *perDecoder) lengths(size *Range, item func(n int)) {
+	if size != nil {
+		inRoot := true
+		if size.Extensible {
+			inRoot = d.readBits(1) == 0
+		}
+		if inRoot && !size.NoMax && size.Max < perMaxFixed {
+			lb := size.Min
+			if size.NoMin {
+				lb = 0
+			}
+			item(int(d.constrained(lb, size.Max)))
+			return
+		}
+	}
+	for {
+		switch b := d.readBits(8); {
+		case b&0x80 == 0:
+			item(int(b))
+			return
+		case b&0x40 == 0:
+			item(int(b&0x3f<<8 | d.readBits(8)))
+			return
+		default:
+			m := int(b & 0x3f)
+			if m < 1 || m > 4 {
+				d.syntaxError("invalid length determinant")
+			}
+			item(m * perFragment)
+		}
+	}
+}
+
+// octets reads octets after a length determinant.
+func (d *perDecoder) octets(size *Range) []byte {
+	b := []byte{}
+	d.lengths(size, func(n int) {
+		if n > len(d.data) {
+			d.syntaxError("truncated encoding")
+		}
+		b = d.readBytes(b, 8*n)
+	})
+	return b
+}
+
+// value decodes a value of type t into v.
+func (d *perDecoder) value(t *Type, v reflect.Value) {
+	v = target(v)
+	if v.IsValid() && v.Type() == valueType {
+		d.dynamic(t, d.off(), v, func(v reflect.Value) { d.value(t, v) })
+		return
+	}
+	off := d.off()
+	switch t.Kind {
+	case KindBoolean:
+		d.store(t, off, d.readBits(1) != 0, v)
+
+	case KindInteger:
+		d.store(t, off, d.integer(t), v)
+
+	case KindEnumerated:
+		if t.Extensible && d.readBits(1) != 0 {
+			values := enumeratedValues(t, true)
+			i := d.smallNumber()
+			if i >= len(values) {
+				d.syntaxError("unknown extension addition %d of %v", i, t)
+			}
+			d.store(t, off, values[i], v)
+			break
+		}
+		values := enumeratedValues(t, false)
+		if len(values) == 0 {
+			d.syntaxError("%v without values", t)
+		}
+		d.store(t, off, values[d.constrained(0, int64(len(values)-1))], v)
+
+	case KindReal:
+		f, err := parseReal(d.octets(nil))
+		if err != nil {
+			d.syntaxError("%v", err)
+		}
+		d.store(t, off, f, v)
+
+	case KindBitString:
+		var bs BitString
+		bs.Bytes = []byte{}
+		d.lengths(t.Size, func(n int) {
+			if n > 8*len(d.data) {
+				d.syntaxError("truncated encoding")
+			}
+			bs.Bytes = d.readBytes(bs.Bytes, n)
+			bs.BitLength += n
+		})
+		d.store(t, off, bs, v)
+
+	case KindOctetString:
+		d.store(t, off, d.octets(t.Size), v)
+
+	case KindNull:
+		d.store(t, off, nil, v)
+
+	case KindObjectIdentifier:
+		oid, ok := parseOID(d.octets(nil))
+		if !ok {
+			d.syntaxError("invalid OBJECT IDENTIFIER")
+		}
+		d.store(t, off, oid, v)
+
+	case KindRelativeOID:
+		oid, ok := parseRelativeOID(d.octets(nil))
+		if !ok {
+			d.syntaxError("invalid RELATIVE-OID")
+		}
+		d.store(t, off, oid, v)
+
+	case KindUTF8String, KindOIDIRI, KindRelativeOIDIRI:
+		s := string(d.octets(nil))
+		if !validString(t.Kind, s) {
+			d.syntaxError("invalid character in %v", t.Kind)
+		}
+		d.store(t, off, s, v)
+
+	case KindNumericString, KindPrintableString, KindIA5String, KindVisibleString,
+		KindUTCTime, KindGeneralizedTime:
+		size := t.Size
+		if t.Kind == KindUTCTime || t.Kind == KindGeneralizedTime {
+			size = nil
+		}
+		var b []byte
+		d.lengths(size, func(n int) {
+			if n > 8*len(d.data) {
+				d.syntaxError("truncated encoding")
+			}
+			for ; n > 0; n-- {
+				if t.Kind != KindNumericString {
+					b = append(b, byte(d.readBits(7)))
+					continue
+				}
+				i := d.readBits(4)
+				if i >= uint64(len(numericAlphabet)) {
+					d.syntaxError("invalid character in %v", t.Kind)
+				}
+				b = append(b, numericAlphabet[i])
+			}
+		})
+		s := string(b)
+		if !validString(t.Kind, s) {
+			d.syntaxError("invalid character in %v", t.Kind)
+		}
+		d.store(t, off, s, v)
+
+	case KindSequence, KindSet:
+		d.components(t, v)
+
+	case KindSequenceOf, KindSetOf:
+		l := d.list(t, off, v)
+		d.lengths(t.Size, func(n int) {
+			if n > 8*len(d.data) {
+				d.syntaxError("truncated encoding")
+			}
+			for ; n > 0; n-- {
+				d.value(t.Elem, l.next())
+			}
+		})
+		l.finish()
+
+	case KindChoice:
+		d.choice(t, off, v)
+
+	case KindAny:
+		d.store(t, off, d.octets(nil), v)
+
+	default:
+		d.syntaxError("unsupported type %v", t)
+	}
+}
+
+// integer reads an INTEGER value of type t, in the form DecodeDER stores
+// in an interface.
+func (d *perDecoder) integer(t *Type) interface{} {
+	r := t.Range
+	if r != nil && r.Extensible && d.readBits(1) != 0 {
+		r = nil
+	}
+	switch {
+	case r == nil || r.NoMin:
+		b := d.octets(nil)
+		if len(b) == 0 {
+			d.syntaxError("empty INTEGER")
+		}
+		return parseInteger(b)
+	case !r.NoMax:
+		return d.constrained(r.Min, r.Max)
+	}
+
+	b := d.octets(nil)
+	if len(b) == 0 || len(b) > 8 {
+		d.syntaxError("invalid INTEGER length %d", len(b))
+	}
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	if r.Min < 0 {
+		if u > math.MaxInt64 {
+			d.syntaxError("INTEGER too large")
+		}
+		return r.Min + int64(u)
+	}
+	n, carry := bits.Add64(uint64(r.Min), u, 0)
+	switch {
+	case carry != 0:
+		d.syntaxError("INTEGER too large")
+	case n > math.MaxInt64:
+		return n
+	}
+	return int64(n)
+}
+
+// components decodes the SEQUENCE or SET value of type t into the struct
+// or map v.
+func (d *perDecoder) components(t *Type, v reflect.Value) {
+	ct := d.composite(t, d.off(), v)
+	found := make([]bool, len(t.Components))
+	extended := t.Extensible && d.readBits(1) != 0
+	d.present(ct, t, componentOrder(t), found)
+	if extended {
+		additions := extensionOrder(t)
+		present := make([]bool, d.smallLength())
+		for i := range present {
+			present[i] = d.readBits(1) != 0
+		}
+		var ext Extensions
+		for k, p := range present {
+			switch {
+			case k >= len(additions):
+				var b []byte
+				if p {
+					b = d.octets(nil)
+				}
+				ext = append(ext, b)
+			case p:
+				a := additions[k]
+				d.openType(func(sub *perDecoder) {
+					if t.Components[a[0]].Group == 0 {
+						found[a[0]] = true
+						sub.component(ct, &t.Components[a[0]], sub.off(), func(v reflect.Value) { sub.value(t.Components[a[0]].Type, v) })
+						return
+					}
+					sub.present(ct, t, a, found)
+				})
+			}
+		}
+		if ext != nil {
+			d.component(ct, &extensionComponent, d.off(), func(v reflect.Value) { d.storeExtensions(t, d.off(), ext, v) })
+		}
+	}
+	d.finish(ct, t, d.off(), found)
+}
+
+// present reads the bits telling which of the OPTIONAL and DEFAULT
+// components among the components order of t are present, and then the
+// present ones into ct, recording them in found.
+func (d *perDecoder) present(ct *composite, t *Type, order []int, found []bool) {
+	for _, i := range order {
+		c := &t.Components[i]
+		found[i] = !c.Optional && c.Default == nil || d.readBits(1) != 0
+	}
+	for _, i := range order {
+		if !found[i] {
+			continue
+		}
+		c := &t.Components[i]
+		d.component(ct, c, d.off(), func(v reflect.Value) { d.value(c.Type, v) })
+	}
+}
+
+// choice decodes the CHOICE value of type t at offset off into v.
+func (d *perDecoder) choice(t *Type, off int, v reflect.Value) {
+	if t.Extensible && d.readBits(1) != 0 {
+		additions := extensionOrder(t)
+		k := d.smallNumber()
+		if k >= len(additions) {
+			ext := make(Extensions, k-len(additions)+1)
+			ext[len(ext)-1] = d.octets(nil)
+			d.storeChoice(t, &extensionComponent, off, v, func(v reflect.Value) { d.storeExtensions(t, off, ext, v) })
+			return
+		}
+		c := &t.Components[additions[k][0]]
+		d.openType(func(sub *perDecoder) {
+			sub.storeChoice(t, c, off, v, func(v reflect.Value) { sub.value(c.Type, v) })
+		})
+		return
+	}
+	order := componentOrder(t)
+	if len(order) == 0 {
+		d.syntaxError("%v without alternatives", t)
+	}
+	c := &t.Components[order[d.constrained(0, int64(len(order)-1))]]
+	d.storeChoice(t, c, off, v, func(v reflect.Value) { d.value(c.Type, v) })
+}
+
+// smallNumber reads a normally small non-negative whole number.
+func (d *perDecoder) smallNumber() int {
+	if d.readBits(1) == 0 {
+		return int(d.readBits(6))
+	}
+	b := d.octets(nil)
+	if len(b) == 0 || len(b) > 4 {
+		d.syntaxError("invalid normally small number")
+	}
+	n := 0
+	for _, c := range b {
+		n = n<<8 | int(c)
+	}
+	return n
+}
+
+// smallLength reads the normally small length of the bitmap of the
+// extension additions of a SEQUENCE or SET.
+func (d *perDecoder) smallLength() int {
+	if d.readBits(1) == 0 {
+		return int(d.readBits(6)) + 1
+	}
+	n := 0
+	d.lengths(nil, func(m int) { n += m })
+	if n == 0 || n > 8*len(d.data) {
+		d.syntaxError("invalid extension bitmap length %d", n)
+	}
+	return n
+}
+
+// openType decodes the open type encoding that follows with decode, given
+// a decoder of its octets of its own, which it must not read beyond.
+func (d *perDecoder) openType(decode func(sub *perDecoder)) {
+	b := d.octets(nil)
+	sub := perDecoder{storer: d.storer, data: b, base: d.base + d.pos - 8*len(b)}
+	decode(&sub)
+	d.storer = sub.storer
+}
@@ -0,0 +1,178 @@
+package asn1go
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func fromHex(s string) []byte {
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// personnelRecord is the type of the example of X.691 Annex A.1.
+func personnelRecord() *Type {
+	vs := &Type{Kind: KindVisibleString}
+	explicit := func(n int, t *Type) *Type {
+		c := *t
+		c.Tags = append([]TypeTag{{Tag: Tag{ClassContextSpecific, n}, Explicit: true}}, t.Tags...)
+		return &c
+	}
+	name := &Type{Name: "Name", Kind: KindSequence, Tags: []TypeTag{{Tag: Tag{ClassApplication, 1}}}, Components: []Component{
+		{Name: "givenName", Type: vs},
+		{Name: "initial", Type: vs},
+		{Name: "familyName", Type: vs},
+	}}
+	date := &Type{Name: "Date", Kind: KindVisibleString, Tags: []TypeTag{{Tag: Tag{ClassApplication, 3}}}}
+	child := &Type{Name: "ChildInformation", Kind: KindSet, Components: []Component{
+		{Name: "name", Type: name},
+		{Name: "dateOfBirth", Type: explicit(0, date)},
+	}}
+	return &Type{Name: "PersonnelRecord", Kind: KindSet, Tags: []TypeTag{{Tag: Tag{ClassApplication, 0}}}, Components: []Component{
+		{Name: "name", Type: name},
+		{Name: "title", Type: explicit(0, vs)},
+		{Name: "number", Type: &Type{Kind: KindInteger, Tags: []TypeTag{{Tag: Tag{ClassApplication, 2}}}}},
+		{Name: "dateOfHire", Type: explicit(1, date)},
+		{Name: "nameOfSpouse", Type: explicit(2, name)},
+		{Name: "children", Type: &Type{Kind: KindSequenceOf, Elem: child, Tags: []TypeTag{{Tag: Tag{ClassContextSpecific, 3}}}}, Default: []interface{}{}},
+	}}
+}
+
+func TestUPERPersonnelRecord(t *testing.T) {
+	name := func(given, initial, family string) map[string]interface{} {
+		return map[string]interface{}{"givenName": given, "initial": initial, "familyName": family}
+	}
+	v := map[string]interface{}{
+		"name":         name("John", "P", "Smith"),
+		"title":        "Director",
+		"number":       int64(51),
+		"dateOfHire":   "19710917",
+		"nameOfSpouse": name("Mary", "T", "Smith"),
+		"children": []interface{}{
+			map[string]interface{}{"name": name("Ralph", "T", "Smith"), "dateOfBirth": "19571111"},
+			map[string]interface{}{"name": name("Susan", "B", "Jones"), "dateOfBirth": "19590717"},
+		},
+	}
+	// X.691 A.1.3, unaligned PER.
+	want := fromHex("82 4A DF A3 70 0D 00 5A 7B 74 F4 D0 02 66 11 13 4F 2C B8 FA 6F E4 10 C5 CB 76 2C 1C B1 6E 09 37 0F 2F 20 35 01 69 ED D3 D3 40 10 2D 2C 3B 38 68 01 A8 0B 4F 6E 9E 9A 02 18 B9 6A DD 8B 16 2C 41 69 F5 E7 87 70 0C 20 59 5B F7 65 E6 10 C5 CB 57 2C 1B B1 6E")
+
+	pr := personnelRecord()
+	b, err := EncodeUPER(pr, v)
+	if err != nil {
+		t.Fatalf("EncodeUPER: %v", err)
+	}
+	if !bytes.Equal(b, want) {
+		t.Errorf("EncodeUPER:\nhave % X\nwant % X", b, want)
+	}
+	var got interface{}
+	if err := DecodeUPER(pr, want, &got); err != nil {
+		t.Fatalf("DecodeUPER: %v", err)
+	}
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("DecodeUPER:\nhave %v\nwant %v", got, v)
+	}
+}
+
+func TestUPER(t *testing.T) {
+	choice := &Type{Kind: KindChoice, Components: []Component{
+		{Name: "a", Type: &Type{Kind: KindBoolean, Tags: []TypeTag{{Tag: Tag{ClassContextSpecific, 1}}}}},
+		{Name: "b", Type: &Type{Kind: KindBoolean, Tags: []TypeTag{{Tag: Tag{ClassContextSpecific, 0}}}}},
+	}}
+	tests := []struct {
+		t    *Type
+		v    interface{}
+		want string
+	}{
+		{&Type{Kind: KindBoolean}, true, "80"},
+		{&Type{Kind: KindNull}, nil, "00"},
+		{&Type{Kind: KindInteger}, int64(32768), "03 00 80 00"},
+		{&Type{Kind: KindInteger}, int64(-1), "01 FF"},
+		{&Type{Kind: KindInteger, Range: &Range{Min: 0, Max: 255}}, int64(5), "05"},
+		{&Type{Kind: KindInteger, Range: &Range{Min: 0, Max: 7}}, int64(3), "60"},
+		{&Type{Kind: KindInteger, Range: &Range{Min: 0, Max: 255, Extensible: true}}, int64(256), "81 00 80 00"},
+		{&Type{Kind: KindInteger, Range: &Range{Min: 0, NoMax: true}}, int64(128), "01 80"},
+		{&Type{Kind: KindBitString, Size: &Range{Min: 4, Max: 4}}, BitString{Bytes: []byte{0xA0}, BitLength: 4}, "A0"},
+		{&Type{Kind: KindOctetString, Size: &Range{Min: 2, Max: 2}}, []byte{0xAB, 0xCD}, "AB CD"},
+		{&Type{Kind: KindOctetString}, []byte{0xAB}, "01 AB"},
+		{&Type{Kind: KindIA5String}, "abc", "03 C3 8B 18"},
+		{&Type{Kind: KindSequenceOf, Elem: &Type{Kind: KindBoolean}, Size: &Range{Min: 0, Max: 3}}, []interface{}{true, false, true}, "E8"},
+		{choice, map[string]interface{}{"a": true}, "C0"},
+		{&Type{Kind: KindObjectIdentifier}, ObjectIdentifier{1, 2, 840}, "03 2A 86 48"},
+	}
+	for _, tt := range tests {
+		want := fromHex(tt.want)
+		b, err := EncodeUPER(tt.t, tt.v)
+		if err != nil {
+			t.Errorf("EncodeUPER(%v, %v): %v", tt.t, tt.v, err)
+			continue
+		}
+		if !bytes.Equal(b, want) {
+			t.Errorf("EncodeUPER(%v, %v) = % X, want % X", tt.t, tt.v, b, want)
+		}
+		var got interface{}
+		if err := DecodeUPER(tt.t, want, &got); err != nil {
+			t.Errorf("DecodeUPER(%v, % X): %v", tt.t, want, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.v) {
+			t.Errorf("DecodeUPER(%v, % X) = %#v, want %#v", tt.t, want, got, tt.v)
+		}
+	}
+}
+
+func TestMarshalUPER(t *testing.T) {
+	type record struct {
+		Flag   bool
+		Number int
+		Data   []byte
+	}
+	v := record{true, 5, []byte{0xAB}}
+	// Flag 1, Number 00000001 00000101, Data 00000001 10101011.
+	want := fromHex("80 82 80 D5 80")
+	b, err := MarshalUPER(v)
+	if err != nil {
+		t.Fatalf("MarshalUPER: %v", err)
+	}
+	if !bytes.Equal(b, want) {
+		t.Errorf("MarshalUPER = % X, want % X", b, want)
+	}
+	var got record
+	if err := UnmarshalUPER(want, &got); err != nil {
+		t.Fatalf("UnmarshalUPER: %v", err)
+	}
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("UnmarshalUPER = %+v, want %+v", got, v)
+	}
+}
+
+func TestUPERError(t *testing.T) {
+	small := &Type{Kind: KindInteger, Range: &Range{Min: 0, Max: 7}}
+	if _, err := EncodeUPER(small, 9); err == nil {
+		t.Error("EncodeUPER of a value out of range: no error")
+	}
+	tests := []struct {
+		t    *Type
+		data string
+	}{
+		{small, "FF 00"},                     // trailing data
+		{&Type{Kind: KindInteger}, "02 01"},  // truncated
+		{&Type{Kind: KindOctetString}, "05"}, // truncated
+		{&Type{Kind: KindBoolean}, ""},       // empty
+	}
+	for _, tt := range tests {
+		var v interface{}
+		err := DecodeUPER(tt.t, fromHex(tt.data), &v)
+		if _, ok := err.(*PERSyntaxError); !ok {
+			t.Errorf("DecodeUPER(%v, %s): error %v, want PERSyntaxError", tt.t, tt.data, err)
+		}
+	}
+	if err := UnmarshalUPER([]byte{0x80}, true); err == nil {
+		t.Error("UnmarshalUPER into a non-pointer: no error")
+	}
+}
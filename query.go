@@ -0,0 +1,606 @@
+package asn1go
+
+import (
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openesim/asn1go/ast"
+)
+
+// Query parses the ASN.1 value notation document data and returns the
+// nodes of its syntax tree that the query expression path selects, in
+// document order. Its expressions are those of JSONPath, over the
+// components, CHOICE alternatives and elements of the values:
+//
+//	$                 the document, whose components are its value assignments
+//	.name             the component or CHOICE alternative name of each node
+//	..name            name below each node, at any depth
+//	.*                all components, alternatives and elements of each node
+//	[n]               the n'th element of each node, from 0, or from the end if negative
+//	[*]               all elements of each node, like .*
+//	[?(cond)]         the elements of each SEQUENCE OF value, and the other
+//	                  nodes themselves, for which cond holds
+//
+// As in the paths of a Document, the alternative of a CHOICE element of a
+// SEQUENCE OF value is a component of the SEQUENCE OF value too. A
+// condition compares the nodes another expression selects, starting at
+// the node tested, @, with ==, !=, <, <=, > or >= and a value notation
+// literal, and holds if one of them matches; without a comparison it holds
+// if the expression selects a node. Conditions combine with && and ||. For
+// example,
+//
+//	$..createFCP[?(@.fileID=='4F3A'H)].efFileSize
+//
+// selects the file sizes in the FCP of the files with identifier 4F3A.
+// Numbers compare by value, hstrings regardless of the case of their
+// digits and other values as written; < and the other orderings compare
+// numbers only.
+func Query(data []byte, path string) ([]ast.Node, error) {
+	q, err := parseQuery(path)
+	if err != nil {
+		return nil, err
+	}
+	as, err := ParseAssignments(data)
+	if err != nil {
+		return nil, err
+	}
+	return q.evalDocument(newQueryDocument(as)), nil
+}
+
+// A queryDocument is the root of a query: the top-level values of the
+// document, with their value references as component names. Unlike a
+// SEQUENCE value, a filter tests its values rather than itself.
+type queryDocument struct {
+	*ast.ObjectNode
+}
+
+// newQueryDocument returns the root of a query over the top-level values
+// as.
+func newQueryDocument(as []*ast.Assignment) queryDocument {
+	root := queryDocument{new(ast.ObjectNode)}
+	for _, a := range as {
+		if a.Name != nil {
+			root.Elements = append(root.Elements, &ast.FieldNode{Name: a.Name, Value: a.Value})
+		} else {
+			root.Elements = append(root.Elements, a.Value)
+		}
+	}
+	return root
+}
+
+// evalDocument returns the nodes that q selects from the document root,
+// with its top-level values in place of the root itself.
+func (q query) evalDocument(root queryDocument) []ast.Node {
+	nodes := q.eval([]ast.Node{root})
+	if len(nodes) == 1 && nodes[0] == ast.Node(root) {
+		nodes = children(root, nil)
+	}
+	return nodes
+}
+
+// Kinds of the steps of a query.
+const (
+	stepChild     = iota // .name or .*
+	stepRecursive        // ..name or ..*
+	stepIndex            // [n] or [*]
+	stepFilter           // [?(cond)]
+)
+
+// A queryStep is a step of a query expression. An empty name selects all
+// children.
+type queryStep struct {
+	kind  int
+	name  string
+	index int
+	all   bool
+	cond  *queryCond
+}
+
+type query []queryStep
+
+// A queryCond is the condition of a filter: its alternatives, which hold if
+// all of their terms do.
+type queryCond struct {
+	or [][]queryTerm
+}
+
+// A queryTerm tests the nodes that path selects from the node tested,
+// against value with op, or for existence if op is "".
+type queryTerm struct {
+	path  query
+	op    string
+	value ast.Node
+}
+
+// eval returns the nodes that q selects from nodes, each once, in document
+// order.
+func (q query) eval(nodes []ast.Node) []ast.Node {
+	for _, s := range q {
+		var next []ast.Node
+		for _, n := range nodes {
+			next = s.eval(n, next)
+		}
+		// The alternative of a CHOICE element is selected both from the
+		// element and from the SEQUENCE OF value holding it.
+		seen := make(map[ast.Node]bool, len(next))
+		nodes = next[:0]
+		for _, n := range next {
+			if !seen[n] {
+				seen[n] = true
+				nodes = append(nodes, n)
+			}
+		}
+	}
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].Pos().Offset < nodes[j].Pos().Offset })
+	return nodes
+}
+
+// eval appends the nodes that s selects from n to dst.
+func (s *queryStep) eval(n ast.Node, dst []ast.Node) []ast.Node {
+	switch s.kind {
+	case stepChild:
+		return children(n, s.nameFilter(), dst...)
+	case stepRecursive:
+		return descendants(n, s.nameFilter(), dst)
+	case stepIndex:
+		els := elements(n)
+		if s.all {
+			return append(dst, els...)
+		}
+		i := s.index
+		if i < 0 {
+			i += len(els)
+		}
+		if 0 <= i && i < len(els) {
+			dst = append(dst, els[i])
+		}
+		return dst
+	}
+	obj, ok := n.(*ast.ObjectNode)
+	if doc, isDoc := n.(queryDocument); isDoc || ok && isList(obj) {
+		if isDoc {
+			obj = doc.ObjectNode
+		}
+		for _, el := range elements(obj) {
+			if s.cond.holds(el) {
+				dst = append(dst, el)
+			}
+		}
+		return dst
+	}
+	if s.cond.holds(n) {
+		dst = append(dst, n)
+	}
+	return dst
+}
+
+// nameFilter returns the name that the children of s must have, or nil.
+func (s *queryStep) nameFilter() *string {
+	if s.name == "" {
+		return nil
+	}
+	return &s.name
+}
+
+// children appends to dst the values of the components, alternatives and
+// elements of n, or those named *name if name is not nil.
+func children(n ast.Node, name *string, dst ...ast.Node) []ast.Node {
+	switch n := n.(type) {
+	case queryDocument:
+		return children(n.ObjectNode, name, dst...)
+	case *ast.ContainingNode:
+		// The components of a containing value are those of the value
+		// contained.
+		return children(n.Value, name, dst...)
+	case *ast.ChoiceNode:
+		if name == nil || n.Name.Name == *name {
+			dst = append(dst, n.Value)
+		}
+	case *ast.ObjectNode:
+		for _, el := range n.Elements {
+			switch el := el.(type) {
+			case *ast.FieldNode:
+				if name == nil || el.Name.Name == *name {
+					dst = append(dst, el.Value)
+				}
+			case *ast.ChoiceNode:
+				if name == nil {
+					dst = append(dst, el)
+				} else if el.Name.Name == *name {
+					dst = append(dst, el.Value)
+				}
+			default:
+				if name == nil {
+					dst = append(dst, el)
+				}
+			}
+		}
+	case *ast.OIDNode:
+		if name == nil {
+			dst = append(dst, n.Components...)
+		}
+	}
+	return dst
+}
+
+// descendants appends to dst the nodes that children selects from n and
+// from the nodes below it.
+func descendants(n ast.Node, name *string, dst []ast.Node) []ast.Node {
+	dst = children(n, name, dst...)
+	for _, c := range children(n, nil) {
+		dst = descendants(c, name, dst)
+	}
+	return dst
+}
+
+// elements returns the elements of n, with the values of its components.
+func elements(n ast.Node) []ast.Node {
+	switch n := n.(type) {
+	case queryDocument:
+		return elements(n.ObjectNode)
+	case *ast.ObjectNode:
+		els := make([]ast.Node, len(n.Elements))
+		for i, el := range n.Elements {
+			if f, ok := el.(*ast.FieldNode); ok {
+				el = f.Value
+			}
+			els[i] = el
+		}
+		return els
+	case *ast.OIDNode:
+		return n.Components
+	}
+	return nil
+}
+
+// isList reports whether obj is a SEQUENCE OF or SET OF value rather than
+// a SEQUENCE or SET value.
+func isList(obj *ast.ObjectNode) bool {
+	for _, el := range obj.Elements {
+		if _, ok := el.(*ast.FieldNode); ok {
+			return false
+		}
+	}
+	return len(obj.Elements) > 0
+}
+
+// holds reports whether the condition holds for n.
+func (c *queryCond) holds(n ast.Node) bool {
+	for _, and := range c.or {
+		ok := true
+		for i := range and {
+			if !and[i].holds(n) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *queryTerm) holds(n ast.Node) bool {
+	nodes := t.path.eval([]ast.Node{n})
+	if t.op == "" {
+		return len(nodes) > 0
+	}
+	for _, m := range nodes {
+		if compareNodes(m, t.op, t.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareNodes reports whether a op b holds.
+func compareNodes(a ast.Node, op string, b ast.Node) bool {
+	x, y := numberValue(a), numberValue(b)
+	if x != nil && y != nil {
+		c := x.Cmp(y)
+		switch op {
+		case "==":
+			return c == 0
+		case "!=":
+			return c != 0
+		case "<":
+			return c < 0
+		case "<=":
+			return c <= 0
+		case ">":
+			return c > 0
+		case ">=":
+			return c >= 0
+		}
+	}
+	switch op {
+	case "==":
+		return nodesEqual(a, b)
+	case "!=":
+		return !nodesEqual(a, b)
+	}
+	return false
+}
+
+// numberValue returns the value of the number node n, or nil if n is not
+// a number.
+func numberValue(n ast.Node) *big.Float {
+	var text string
+	switch n := n.(type) {
+	case *ast.IntNode:
+		text = n.Text
+	case *ast.RealNode:
+		text = n.Text
+	default:
+		return nil
+	}
+	f, _, err := big.ParseFloat(text, 10, 256, big.ToNearestEven)
+	if err != nil {
+		return nil
+	}
+	return f
+}
+
+// nodesEqual reports whether the nodes a and b stand for the same value.
+func nodesEqual(a, b ast.Node) bool {
+	if x, y := numberValue(a), numberValue(b); x != nil && y != nil {
+		return x.Cmp(y) == 0
+	}
+	switch a := a.(type) {
+	case *ast.ObjectNode:
+		b, ok := b.(*ast.ObjectNode)
+		return ok && nodeListsEqual(a.Elements, b.Elements)
+	case *ast.OIDNode:
+		b, ok := b.(*ast.OIDNode)
+		return ok && nodeListsEqual(a.Components, b.Components)
+	case *ast.FieldNode:
+		b, ok := b.(*ast.FieldNode)
+		return ok && a.Name.Name == b.Name.Name && nodesEqual(a.Value, b.Value)
+	case *ast.ChoiceNode:
+		b, ok := b.(*ast.ChoiceNode)
+		return ok && a.Name.Name == b.Name.Name && nodesEqual(a.Value, b.Value)
+	case *ast.ContainingNode:
+		b, ok := b.(*ast.ContainingNode)
+		return ok && nodesEqual(a.Value, b.Value)
+	case *ast.Ident:
+		b, ok := b.(*ast.Ident)
+		return ok && a.Name == b.Name
+	case *ast.NullNode:
+		_, ok := b.(*ast.NullNode)
+		return ok
+	case *ast.BoolNode:
+		b, ok := b.(*ast.BoolNode)
+		return ok && a.Value == b.Value
+	case *ast.HexNode:
+		b, ok := b.(*ast.HexNode)
+		return ok && strings.EqualFold(a.Digits, b.Digits)
+	case *ast.BitsNode:
+		b, ok := b.(*ast.BitsNode)
+		return ok && a.Digits == b.Digits
+	case *ast.StringNode:
+		b, ok := b.(*ast.StringNode)
+		return ok && a.Value == b.Value
+	}
+	return false
+}
+
+func nodeListsEqual(a, b []ast.Node) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !nodesEqual(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// A queryParser parses a query expression.
+type queryParser struct {
+	src string
+	off int
+}
+
+func parseQuery(path string) (query, error) {
+	p := &queryParser{src: path}
+	if !p.consume("$") {
+		return nil, p.errorf("does not begin with $")
+	}
+	q, err := p.steps(false)
+	if err == nil && p.off < len(p.src) {
+		err = p.unexpected()
+	}
+	return q, err
+}
+
+func (p *queryParser) errorf(msg string) error {
+	return &PathError{Path: p.src, Msg: msg}
+}
+
+func (p *queryParser) unexpected() error {
+	if p.off >= len(p.src) {
+		return p.errorf("ends unexpectedly")
+	}
+	return p.errorf("has an unexpected " + quoteChar(p.src[p.off]) + " at offset " + strconv.Itoa(p.off))
+}
+
+// consume skips s if the rest of the expression begins with it.
+func (p *queryParser) consume(s string) bool {
+	if strings.HasPrefix(p.src[p.off:], s) {
+		p.off += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) skipSpace() {
+	for p.off < len(p.src) && isSpace(p.src[p.off]) {
+		p.off++
+	}
+}
+
+// steps parses the steps of a query, up to the end of the expression or,
+// in a condition, up to the first byte that cannot continue a path.
+func (p *queryParser) steps(inCond bool) (query, error) {
+	var q query
+	for p.off < len(p.src) {
+		switch {
+		case p.consume(".."):
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			q = append(q, queryStep{kind: stepRecursive, name: name})
+		case p.consume("."):
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			q = append(q, queryStep{kind: stepChild, name: name})
+		case p.consume("[?("):
+			cond, err := p.cond()
+			if err != nil {
+				return nil, err
+			}
+			if !p.consume(")]") {
+				return nil, p.unexpected()
+			}
+			q = append(q, queryStep{kind: stepFilter, cond: cond})
+		case p.consume("["):
+			s := queryStep{kind: stepIndex}
+			if p.consume("*") {
+				s.all = true
+			} else {
+				start := p.off
+				p.consume("-")
+				for p.off < len(p.src) && isDigit(p.src[p.off]) {
+					p.off++
+				}
+				i, err := strconv.Atoi(p.src[start:p.off])
+				if err != nil {
+					p.off = start
+					return nil, p.unexpected()
+				}
+				s.index = i
+			}
+			if !p.consume("]") {
+				return nil, p.unexpected()
+			}
+			q = append(q, s)
+		case inCond:
+			return q, nil
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	return q, nil
+}
+
+// name parses the identifier or * after a dot. It returns "" for *.
+func (p *queryParser) name() (string, error) {
+	if p.consume("*") {
+		return "", nil
+	}
+	start := p.off
+	for p.off < len(p.src) && (isNameChar(p.src[p.off]) || p.src[p.off] == '-') {
+		p.off++
+	}
+	name := p.src[start:p.off]
+	if !isValidIdentifier(name) {
+		p.off = start
+		return "", p.unexpected()
+	}
+	return name, nil
+}
+
+// cond parses the condition of a filter.
+func (p *queryParser) cond() (*queryCond, error) {
+	c := new(queryCond)
+	for {
+		var and []queryTerm
+		for {
+			t, err := p.term()
+			if err != nil {
+				return nil, err
+			}
+			and = append(and, t)
+			p.skipSpace()
+			if !p.consume("&&") {
+				break
+			}
+		}
+		c.or = append(c.or, and)
+		if !p.consume("||") {
+			return c, nil
+		}
+	}
+}
+
+// term parses a term of a condition: @, a path and optionally a comparison.
+func (p *queryParser) term() (queryTerm, error) {
+	var t queryTerm
+	p.skipSpace()
+	if !p.consume("@") {
+		return t, p.unexpected()
+	}
+	var err error
+	if t.path, err = p.steps(true); err != nil {
+		return t, err
+	}
+	p.skipSpace()
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(op) {
+			t.op = op
+			break
+		}
+	}
+	if t.op == "" {
+		return t, nil
+	}
+	p.skipSpace()
+	t.value, err = p.literal()
+	return t, err
+}
+
+// literal parses the value notation literal of a comparison.
+func (p *queryParser) literal() (ast.Node, error) {
+	start := p.off
+	switch {
+	case p.off >= len(p.src):
+		return nil, p.unexpected()
+	case p.src[p.off] == '"' || p.src[p.off] == '\'':
+		for {
+			end := strings.IndexByte(p.src[p.off+1:], p.src[start])
+			if end < 0 {
+				return nil, p.errorf("has an unterminated literal at offset " + strconv.Itoa(start))
+			}
+			p.off += end + 2
+			// A quotation mark in a cstring is doubled.
+			if p.src[start] == '\'' || p.off == len(p.src) || p.src[p.off] != '"' {
+				break
+			}
+		}
+		if p.src[start] == '\'' && p.off < len(p.src) {
+			p.off++ // B or H
+		}
+	default:
+		for p.off < len(p.src) && (isNameChar(p.src[p.off]) || strings.IndexByte("-+.", p.src[p.off]) >= 0) {
+			p.off++
+		}
+	}
+	v, err := ParseValue([]byte(p.src[start:p.off]))
+	if err != nil || len(p.src[start:p.off]) == 0 {
+		p.off = start
+		return nil, p.errorf("has an invalid literal at offset " + strconv.Itoa(start))
+	}
+	switch v.(type) {
+	case *ast.ObjectNode, *ast.OIDNode, *ast.ChoiceNode, *ast.ContainingNode:
+		p.off = start
+		return nil, p.errorf("has an invalid literal at offset " + strconv.Itoa(start))
+	}
+	return v, nil
+}
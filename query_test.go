@@ -0,0 +1,84 @@
+package asn1go
+
+import (
+	"reflect"
+	"testing"
+)
+
+const queryDoc = `value1 ProfileElement ::= genericFileManagement : {
+  fileManagementCMD {
+    {
+      filePath : '7FF0'H,
+      createFCP : { fileDescriptor '4121'H, fileID '4f3a'H, efFileSize '0100'H },
+      fillFileContent : '00'H
+    },
+    {
+      createFCP : { fileID '6F07'H, efFileSize '0009'H }
+    }
+  }
+}
+value2 INTEGER ::= 5
+`
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		path string
+		want []string // the nodes selected, as Marshal writes them
+	}{
+		{"$.value2", []string{"5"}},
+		{"$[1]", []string{"5"}},
+		{"$[-1]", []string{"5"}},
+		{"$[2]", nil},
+		{"$..createFCP[?(@.fileID=='4F3A'H)].efFileSize", []string{"'0100'H"}},
+		{"$..createFCP.fileID", []string{"'4F3A'H", "'6F07'H"}},
+		{"$..filePath", []string{"'7FF0'H"}},
+		{"$..fileManagementCMD[1][*]", []string{"createFCP : { fileID '6F07'H, efFileSize '0009'H }"}},
+		{"$..fileManagementCMD[0].*", []string{"filePath : '7FF0'H", "createFCP : { fileDescriptor '4121'H, fileID '4F3A'H, efFileSize '0100'H }", "fillFileContent : '00'H"}},
+		{"$..createFCP[?(@.fileDescriptor)].fileID", []string{"'4F3A'H"}},
+		{"$.value1.genericFileManagement.fileManagementCMD[1].createFCP.efFileSize", []string{"'0009'H"}},
+		{"$[?(@ > 4)]", []string{"5"}},
+		{"$[?(@ < 5)]", nil},
+		{"$[?(@ >= 6 || @ == 5)]", []string{"5"}},
+		{"$..createFCP[?(@.fileID=='6F07'H && @.efFileSize != '0009'H)]", nil},
+		{"$..createFCP[?(@.fileID=='6F07'H && @.efFileSize == '0009'H)].fileID", []string{"'6F07'H"}},
+		{"$..nothing", nil},
+	}
+	for _, tt := range tests {
+		nodes, err := Query([]byte(queryDoc), tt.path)
+		if err != nil {
+			t.Errorf("Query(%q): %v", tt.path, err)
+			continue
+		}
+		var got []string
+		for _, n := range nodes {
+			b, err := Marshal(n)
+			if err != nil {
+				t.Fatalf("Marshal(%#v): %v", n, err)
+			}
+			got = append(got, string(b))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Query(%q):\nhave %q\nwant %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestQueryError(t *testing.T) {
+	tests := []string{
+		"value",
+		"$.",
+		"$[x]",
+		"$[?(@.a==)]",
+		"$[?(@.a=='12)]",
+		"$[?(@.a=={1})]",
+	}
+	for _, path := range tests {
+		_, err := Query([]byte(queryDoc), path)
+		if _, ok := err.(*PathError); !ok {
+			t.Errorf("Query(%q): error %v, want PathError", path, err)
+		}
+	}
+	if _, err := Query([]byte("v T ::= { a"), "$"); err == nil {
+		t.Error("Query of invalid data: no error")
+	}
+}
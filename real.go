@@ -0,0 +1,98 @@
+package asn1go
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// realSequence is the sequence form of a REAL value,
+// { mantissa 314159, base 10, exponent -5 }.
+type realSequence struct {
+	Mantissa *int64 `asn1:"mantissa"`
+	Base     *int64 `asn1:"base"`
+	Exponent *int64 `asn1:"exponent"`
+}
+
+// real decodes a REAL value written in its sequence form into the float v.
+// The first byte ('{') of the value has been read already.
+func (d *decodeState) real(v reflect.Value) error {
+	start := d.readIndex()
+	var r realSequence
+	if err := d.components(reflect.ValueOf(&r).Elem()); err != nil {
+		return err
+	}
+	if r.Mantissa == nil || r.Base == nil || r.Exponent == nil {
+		d.saveError(&UnmarshalTypeError{Value: "object", Type: v.Type(), Offset: int64(start)})
+		return nil
+	}
+	f, err := realFromParts(*r.Mantissa, *r.Base, *r.Exponent)
+	if err != nil || v.OverflowFloat(f) {
+		d.saveError(&UnmarshalTypeError{Value: "REAL " + formatRealSequence(r), Type: v.Type(), Offset: int64(start)})
+		return nil
+	}
+	v.SetFloat(f)
+	return nil
+}
+
+func formatRealSequence(r realSequence) string {
+	return "{ mantissa " + strconv.FormatInt(*r.Mantissa, 10) +
+		", base " + strconv.FormatInt(*r.Base, 10) +
+		", exponent " + strconv.FormatInt(*r.Exponent, 10) + " }"
+}
+
+var (
+	errRealBase  = errors.New("asn1go: REAL base must be 2 or 10")
+	errRealRange = errors.New("asn1go: REAL value out of range")
+)
+
+// realFromParts returns mantissa * base^exponent, where base is 2 or 10.
+func realFromParts(mantissa, base, exponent int64) (float64, error) {
+	switch base {
+	case 2:
+		if mantissa == 0 {
+			return 0, nil
+		}
+		// Ldexp saturates to ±Inf or 0; clamping keeps int(exponent)
+		// well defined on 32-bit platforms.
+		if exponent > math.MaxInt32 {
+			exponent = math.MaxInt32
+		} else if exponent < math.MinInt32 {
+			exponent = math.MinInt32
+		}
+		f := math.Ldexp(float64(mantissa), int(exponent))
+		if math.IsInf(f, 0) {
+			return 0, errRealRange
+		}
+		return f, nil
+	case 10:
+		// Let strconv do the rounding, so that the result is the float
+		// closest to the decimal value.
+		s := strconv.FormatInt(mantissa, 10) + "e" + strconv.FormatInt(exponent, 10)
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			if errors.Is(err, strconv.ErrRange) && !math.IsInf(f, 0) {
+				// Underflow to zero.
+				return f, nil
+			}
+			return 0, errRealRange
+		}
+		return f, nil
+	}
+	return 0, errRealBase
+}
+
+// specialReal returns the float for the special REAL values PLUS-INFINITY,
+// MINUS-INFINITY and NOT-A-NUMBER.
+func specialReal(s string) (float64, bool) {
+	switch s {
+	case "PLUS-INFINITY":
+		return math.Inf(1), true
+	case "MINUS-INFINITY":
+		return math.Inf(-1), true
+	case "NOT-A-NUMBER":
+		return math.NaN(), true
+	}
+	return 0, false
+}
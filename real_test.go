@@ -0,0 +1,93 @@
+package asn1go
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUnmarshalReal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"1.5", 1.5},
+		{"1.5e3", 1500},
+		{"-2.5E-1", -0.25},
+		{"0.0", 0},
+		{"42", 42},
+		{"{ mantissa 314159, base 10, exponent -5 }", 3.14159},
+		{"{ mantissa 3, base 2, exponent 4 }", 48},
+		{"{ mantissa -1, base 2, exponent -1 }", -0.5},
+		{"{ exponent 2, mantissa 5, base 10 }", 500},
+		{"{ mantissa 1, base 10, exponent -400 }", 0},
+		{"PLUS-INFINITY", math.Inf(1)},
+		{"MINUS-INFINITY", math.Inf(-1)},
+		{"NOT-A-NUMBER", math.NaN()},
+	}
+	for _, tt := range tests {
+		var f float64
+		if err := Unmarshal([]byte(tt.in), &f); err != nil {
+			t.Errorf("Unmarshal(%q): %v", tt.in, err)
+			continue
+		}
+		if f != tt.want && !(math.IsNaN(f) && math.IsNaN(tt.want)) {
+			t.Errorf("Unmarshal(%q) = %v, want %v", tt.in, f, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalRealInto(t *testing.T) {
+	var f32 float32
+	if err := Unmarshal([]byte("{ mantissa 5, base 10, exponent -1 }"), &f32); err != nil || f32 != 0.5 {
+		t.Errorf("Unmarshal into float32 = %v, %v, want 0.5", f32, err)
+	}
+	var v interface{}
+	if err := Unmarshal([]byte("1.5e3"), &v); err != nil || v != 1500.0 {
+		t.Errorf("Unmarshal into interface{} = %#v, %v, want 1500.0", v, err)
+	}
+	if err := Unmarshal([]byte("PLUS-INFINITY"), &v); err != nil || v != math.Inf(1) {
+		t.Errorf("Unmarshal into interface{} = %#v, %v, want +Inf", v, err)
+	}
+}
+
+func TestUnmarshalRealError(t *testing.T) {
+	tests := []struct {
+		in  string
+		ptr interface{}
+	}{
+		{"{ mantissa 1, base 3, exponent 1 }", new(float64)},
+		{"{ mantissa 1, base 10 }", new(float64)},
+		{"{ mantissa 1, base 2, exponent 2000 }", new(float64)},
+		{"{ mantissa 1, base 10, exponent 39 }", new(float32)},
+		{"1.5", new(int)},
+		{"1.5", new(string)},
+	}
+	for _, tt := range tests {
+		err := Unmarshal([]byte(tt.in), tt.ptr)
+		if _, ok := err.(*UnmarshalTypeError); !ok {
+			t.Errorf("Unmarshal(%q, %T): error %v, want UnmarshalTypeError", tt.in, tt.ptr, err)
+		}
+	}
+}
+
+func TestRealFromParts(t *testing.T) {
+	tests := []struct {
+		mantissa, base, exponent int64
+		want                     float64
+		err                      error
+	}{
+		{314159, 10, -5, 3.14159, nil},
+		{1, 2, 10, 1024, nil},
+		{0, 2, math.MaxInt64, 0, nil},
+		{1, 2, math.MinInt64, 0, nil},
+		{1, 2, math.MaxInt64, 0, errRealRange},
+		{1, 10, 400, 0, errRealRange},
+		{1, 16, 1, 0, errRealBase},
+	}
+	for _, tt := range tests {
+		f, err := realFromParts(tt.mantissa, tt.base, tt.exponent)
+		if f != tt.want || err != tt.err {
+			t.Errorf("realFromParts(%d, %d, %d) = %v, %v, want %v, %v", tt.mantissa, tt.base, tt.exponent, f, err, tt.want, tt.err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package asn1go
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/openesim/asn1go/ast"
+)
+
+// Redact returns a copy of the value notation document data with the
+// values that the query expressions paths select, such as "$..ki" or
+// "$..pinValue", replaced by placeholders, so that a profile package can
+// be logged or shared without its secrets. It works on the syntax tree of
+// data and needs no Go types for its values:
+//
+//   - The digits of an hstring or a bstring are replaced by zeros, so
+//     that the value keeps its length, and its layout over several lines.
+//   - The characters of a cstring are replaced by as many asterisks.
+//   - A number is replaced by 0.
+//   - The values of a SEQUENCE, SET, SEQUENCE OF, SET OF, CHOICE or
+//     containing value are redacted in turn.
+//
+// Other values, such as identifiers and object identifiers, are kept. The
+// rest of the document, comments included, is left as it was. See Query
+// for the expressions; a malformed one is reported as a PathError
+// and malformed value notation as a SyntaxError.
+func Redact(data []byte, paths []string) ([]byte, error) {
+	qs := make([]query, len(paths))
+	for i, path := range paths {
+		q, err := parseQuery(path)
+		if err != nil {
+			return nil, err
+		}
+		qs[i] = q
+	}
+	as, err := ParseAssignments(data)
+	if err != nil {
+		return nil, err
+	}
+	root := newQueryDocument(as)
+	r := redactor{data: data, edits: make(map[int]redaction)}
+	for _, q := range qs {
+		for _, n := range q.evalDocument(root) {
+			r.node(n)
+		}
+	}
+
+	offs := make([]int, 0, len(r.edits))
+	for off := range r.edits {
+		offs = append(offs, off)
+	}
+	sort.Ints(offs)
+	out := make([]byte, 0, len(data))
+	last := 0
+	for _, off := range offs {
+		e := r.edits[off]
+		out = append(out, data[last:off]...)
+		out = append(out, e.text...)
+		last = e.end
+	}
+	return append(out, data[last:]...), nil
+}
+
+// A redactor collects the redactions of the values of a document.
+type redactor struct {
+	data  []byte
+	edits map[int]redaction // by offset of the value redacted
+}
+
+// A redaction replaces a value, up to offset end, with text.
+type redaction struct {
+	end  int
+	text string
+}
+
+// node redacts the value n.
+func (r *redactor) node(n ast.Node) {
+	var text string
+	switch n := n.(type) {
+	case *ast.ObjectNode:
+		for _, el := range n.Elements {
+			r.node(el)
+		}
+		return
+	case *ast.FieldNode:
+		r.node(n.Value)
+		return
+	case *ast.ChoiceNode:
+		r.node(n.Value)
+		return
+	case *ast.ContainingNode:
+		r.node(n.Value)
+		return
+	case *ast.HexNode, *ast.BitsNode:
+		// Keep the quotes, the white space and the letter after the
+		// closing quote.
+		src := string(r.data[n.Pos().Offset:n.End().Offset])
+		end := strings.LastIndexByte(src, '\'')
+		text = "'" + strings.Map(func(c rune) rune {
+			if isSpace(byte(c)) {
+				return c
+			}
+			return '0'
+		}, src[1:end]) + src[end:]
+	case *ast.StringNode:
+		text = `"` + strings.Repeat("*", utf8.RuneCountInString(n.Value)) + `"`
+	case *ast.IntNode, *ast.RealNode:
+		text = "0"
+	default:
+		return
+	}
+	r.edits[n.Pos().Offset] = redaction{n.End().Offset, text}
+}
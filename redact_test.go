@@ -0,0 +1,64 @@
+package asn1go
+
+import "testing"
+
+const redactDoc = `value1 PE ::= akaParameter : {
+  algoConfiguration algoParameter : {
+    key '000102030405060708090A0B0C0D0E0F'H, -- Ki
+    opc '0F0E0D0C0B0A0908
+         0706050403020100'H,
+    rotationConstants '4000204060'H
+  },
+  pin "1234",
+  count 17,
+  mode '0101'B,
+  alg milenage,
+  oid { 2 23 143 }
+}
+`
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		paths    []string
+		old, new string // the edit of redactDoc, if any
+	}{
+		{nil, "", ""},
+		{[]string{"$..nothing"}, "", ""},
+		{[]string{"$..key"}, "'000102030405060708090A0B0C0D0E0F'H", "'00000000000000000000000000000000'H"},
+		{[]string{"$..opc"}, "'0F0E0D0C0B0A0908\n         0706050403020100'H", "'0000000000000000\n         0000000000000000'H"},
+		{[]string{"$..pin"}, `"1234"`, `"****"`},
+		{[]string{"$..count"}, "count 17", "count 0"},
+		{[]string{"$..mode"}, "'0101'B", "'0000'B"},
+		{[]string{"$..alg", "$..oid"}, "", ""},
+		{[]string{"$..pin", "$..count"}, "\"1234\",\n  count 17", "\"****\",\n  count 0"},
+		{[]string{"$..algoParameter"}, "'000102030405060708090A0B0C0D0E0F'H, -- Ki\n    opc '0F0E0D0C0B0A0908\n         0706050403020100'H,\n    rotationConstants '4000204060'H",
+			"'00000000000000000000000000000000'H, -- Ki\n    opc '0000000000000000\n         0000000000000000'H,\n    rotationConstants '0000000000'H"},
+	}
+	for _, tt := range tests {
+		want := redactDoc
+		if tt.old != "" {
+			want = replaceOnce(want, tt.old, tt.new)
+		}
+		got, err := Redact([]byte(redactDoc), tt.paths)
+		if err != nil {
+			t.Errorf("Redact(%q): %v", tt.paths, err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("Redact(%q):\nhave %s\nwant %s", tt.paths, got, want)
+		}
+	}
+}
+
+func TestRedactError(t *testing.T) {
+	if _, err := Redact([]byte(redactDoc), []string{"$..key", "key"}); err == nil {
+		t.Error("Redact with a malformed path: no error")
+	} else if _, ok := err.(*PathError); !ok {
+		t.Errorf("Redact with a malformed path: error %v, want PathError", err)
+	}
+	if _, err := Redact([]byte("v T ::= {"), []string{"$..key"}); err == nil {
+		t.Error("Redact of invalid data: no error")
+	} else if _, ok := err.(*SyntaxError); !ok {
+		t.Errorf("Redact of invalid data: error %v, want SyntaxError", err)
+	}
+}
@@ -0,0 +1,75 @@
+package asn1go
+
+import (
+	"reflect"
+	"sync"
+)
+
+// A Registry maps ASN.1 type references and CHOICE alternative identifiers
+// to the Go types to decode their values into, for a Decoder that uses it,
+// see Decoder.UseRegistry. It lets an interface hold the right concrete
+// value: with
+//
+//	reg.Register("genericFileManagement", &GenericFileManagement{})
+//
+// the value genericFileManagement : { ... } decodes into a new
+// *GenericFileManagement stored in the interface it is decoded into, and
+// with reg.Register("ProfileElement", &ProfileElement{}) the value of an
+// assignment value1 ProfileElement ::= ... does so too, as does the
+// open type value ProfileElement : ..., see OpenTypeValue.
+//
+// The zero Registry is empty and ready to use. A Registry may be used by
+// several Decoders at once.
+type Registry struct {
+	mu    sync.RWMutex
+	types map[string]reflect.Type
+}
+
+// Register records the Go type of v as the type of the values of the ASN.1
+// type reference or CHOICE alternative name. A value is decoded into a
+// new value of that type, into what it points to if it is a pointer type.
+// Register panics if v is nil or name already has another type.
+func (r *Registry) Register(name string, v interface{}) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		panic("asn1go: Register of nil value for " + name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if old, ok := r.types[name]; ok && old != t {
+		panic("asn1go: Register of " + t.String() + " for " + name + ", registered as " + old.String())
+	}
+	if r.types == nil {
+		r.types = make(map[string]reflect.Type)
+	}
+	r.types[name] = t
+}
+
+// lookup returns the Go type registered for name.
+func (r *Registry) lookup(name string) (reflect.Type, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.types[name]
+	return t, ok
+}
+
+// registered returns the interface v is or points to and a new value of
+// the Go type d.registry holds for the type reference or alternative name,
+// to decode into and then store in the interface. It reports false if d
+// uses no registry, the name has no type, or v is not such an interface
+// or one that can hold the type. An interface that holds a non-nil
+// pointer is decoded into as usual.
+func (d *decodeState) registered(name string, v reflect.Value) (iface, nv reflect.Value, ok bool) {
+	if d.registry == nil || !v.IsValid() {
+		return
+	}
+	t, ok := d.registry.lookup(name)
+	if !ok {
+		return
+	}
+	u, iface := indirect(v)
+	if u != nil || iface.Kind() != reflect.Interface || !t.AssignableTo(iface.Type()) {
+		return reflect.Value{}, reflect.Value{}, false
+	}
+	return iface, reflect.New(t).Elem(), true
+}
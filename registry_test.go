@@ -0,0 +1,68 @@
+package asn1go
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type regHeader struct {
+	MajorVersion int `asn1:"major-version"`
+}
+
+type regFile struct {
+	FilePath []byte `asn1:"filePath"`
+}
+
+func TestDecoderUseRegistry(t *testing.T) {
+	var reg Registry
+	reg.Register("PE-Header", &regHeader{})
+	reg.Register("genericFileManagement", regFile{})
+	tests := []struct {
+		in   string
+		ptr  func() interface{}
+		want interface{}
+	}{
+		{"v1 PE-Header ::= { major-version 2 }", func() interface{} { return new(interface{}) }, &regHeader{2}},
+		{"genericFileManagement : { filePath '3F00'H }", func() interface{} { return new(interface{}) }, regFile{[]byte{0x3F, 0x00}}},
+		{"PE-Header : { major-version 3 }", func() interface{} { return new(OpenTypeValue) }, OpenTypeValue{"PE-Header", &regHeader{3}}},
+		{"v1 Other ::= { major-version 2 }", func() interface{} { return new(interface{}) }, OrderedObject{{"major-version", int64(2)}}},
+		{"v1 PE-Header ::= { major-version 2 }", func() interface{} { return new(regHeader) }, regHeader{2}},
+	}
+	for _, tt := range tests {
+		dec := NewDecoder(strings.NewReader(tt.in))
+		dec.UseRegistry(&reg)
+		ptr := tt.ptr()
+		if err := dec.Decode(ptr); err != nil {
+			t.Errorf("Decode(%q, %T): %v", tt.in, ptr, err)
+			continue
+		}
+		if got := reflect.ValueOf(ptr).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Decode(%q, %T) = %#v, want %#v", tt.in, ptr, got, tt.want)
+		}
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	tests := []struct {
+		name  string
+		v     interface{}
+		panic bool
+	}{
+		{"PE-Header", &regHeader{}, false},
+		{"PE-Header", &regHeader{1}, false}, // the same type again
+		{"PE-Header", regHeader{}, true},
+		{"Nothing", nil, true},
+	}
+	var reg Registry
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if r := recover(); (r != nil) != tt.panic {
+					t.Errorf("Register(%q, %#v): panic %v, want panic %v", tt.name, tt.v, r, tt.panic)
+				}
+			}()
+			reg.Register(tt.name, tt.v)
+		}()
+	}
+}
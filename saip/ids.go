@@ -0,0 +1,169 @@
+package saip
+
+import (
+	"errors"
+	"strconv"
+)
+
+// An ICCID is the identification number of a profile, in the form of the
+// iccid of a ProfileHeader and of the contents of EF-ICCID: its decimal
+// digits in BCD, two to a byte with the first in the low nibble, padded
+// with F nibbles to ten bytes. The hstring of an iccid decodes into an
+// ICCID as into a []byte, and so does a cstring of its digits:
+//
+//	iccid := saip.ICCID(h.Iccid)
+//	fmt.Println(iccid) // 8901234567890123456
+//
+//	iccid.Set("8901234567890123456") // iccid is '981032547698103254F6'H
+type ICCID []byte
+
+// iccidLen is the length of an ICCID in bytes.
+const iccidLen = 10
+
+// String returns the digits of id.
+func (id ICCID) String() string { return swappedDigits(id) }
+
+// Set sets id to the ICCID of the 18 to 20 digits s.
+func (id *ICCID) Set(s string) error {
+	if len(s) < 18 || len(s) > 2*iccidLen {
+		return invalidNumber("ICCID", s)
+	}
+	b, ok := swapDigits(s, iccidLen)
+	if !ok {
+		return invalidNumber("ICCID", s)
+	}
+	*id = b
+	return nil
+}
+
+// MarshalText returns the digits of id.
+func (id ICCID) MarshalText() ([]byte, error) { return []byte(id.String()), nil }
+
+// UnmarshalText sets id to the ICCID of the digits text, as Set does.
+func (id *ICCID) UnmarshalText(text []byte) error { return id.Set(string(text)) }
+
+// An IMSI is the international mobile subscriber identity of a profile,
+// in the form of the contents of EF-IMSI: a length byte, then the digits
+// in BCD after a first nibble of 9 for an odd number of digits or 1 for
+// an even one, two to a byte with the first in the low nibble, padded
+// with F nibbles and 0xFF bytes to nine bytes.
+//
+//	imsi.Set("001010123456789") // imsi is '080910101032547698'H
+type IMSI []byte
+
+// imsiLen is the length of an IMSI in bytes, its length byte included.
+const imsiLen = 9
+
+// String returns the digits of id, or "" if it has no valid length byte.
+func (id IMSI) String() string {
+	if len(id) < 2 || int(id[0]) < 1 || int(id[0]) >= len(id) {
+		return ""
+	}
+	// The digits follow the parity nibble.
+	s := swappedDigits(id[1 : 1+int(id[0])])
+	if s == "" {
+		return ""
+	}
+	return s[1:]
+}
+
+// Set sets id to the IMSI of the 6 to 15 digits s.
+func (id *IMSI) Set(s string) error {
+	if len(s) < 6 || len(s) > 15 {
+		return invalidNumber("IMSI", s)
+	}
+	parity := "1"
+	if len(s)%2 == 1 {
+		parity = "9"
+	}
+	n := (len(s) + 2) / 2
+	b, ok := swapDigits(parity+s, n)
+	if !ok {
+		return invalidNumber("IMSI", s)
+	}
+	b = append([]byte{byte(n)}, b...)
+	for len(b) < imsiLen {
+		b = append(b, 0xff)
+	}
+	*id = b
+	return nil
+}
+
+// MarshalText returns the digits of id.
+func (id IMSI) MarshalText() ([]byte, error) { return []byte(id.String()), nil }
+
+// UnmarshalText sets id to the IMSI of the digits text, as Set does.
+func (id *IMSI) UnmarshalText(text []byte) error { return id.Set(string(text)) }
+
+// An MSISDN is the dialling number of a subscriber, in the form of the
+// dialling number of an EF-MSISDN record without the alpha identifier,
+// length and TON/NPI bytes around it: its digits in BCD, two to a byte
+// with the first in the low nibble, padded with an F nibble to a whole
+// byte.
+type MSISDN []byte
+
+// String returns the digits of n.
+func (n MSISDN) String() string { return swappedDigits(n) }
+
+// Set sets n to the MSISDN of the at most 20 digits s.
+func (n *MSISDN) Set(s string) error {
+	if len(s) == 0 || len(s) > 20 {
+		return invalidNumber("MSISDN", s)
+	}
+	b, ok := swapDigits(s, (len(s)+1)/2)
+	if !ok {
+		return invalidNumber("MSISDN", s)
+	}
+	*n = b
+	return nil
+}
+
+// MarshalText returns the digits of n.
+func (n MSISDN) MarshalText() ([]byte, error) { return []byte(n.String()), nil }
+
+// UnmarshalText sets n to the MSISDN of the digits text, as Set does.
+func (n *MSISDN) UnmarshalText(text []byte) error { return n.Set(string(text)) }
+
+// swappedDigits returns the digits of the swapped-nibble BCD b, up to the
+// first F nibble. Nibbles A to E, which are not digits, are written as
+// hex digits.
+func swappedDigits(b []byte) string {
+	const hexDigits = "0123456789ABCDEF"
+	s := make([]byte, 0, 2*len(b))
+	for _, c := range b {
+		for _, d := range [2]byte{c & 0x0f, c >> 4} {
+			if d == 0x0f {
+				return string(s)
+			}
+			s = append(s, hexDigits[d])
+		}
+	}
+	return string(s)
+}
+
+// swapDigits returns the n bytes of the swapped-nibble BCD of the digits
+// s, padded with F nibbles. It reports false if s is not all digits.
+func swapDigits(s string, n int) ([]byte, bool) {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = 0xff
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < '0' || c > '9' {
+			return nil, false
+		}
+		if i%2 == 0 {
+			b[i/2] = 0xf0 | (c - '0')
+		} else {
+			b[i/2] = b[i/2]&0x0f | (c-'0')<<4
+		}
+	}
+	return b, true
+}
+
+// invalidNumber returns the error for the invalid digits s of an ICCID,
+// IMSI or MSISDN.
+func invalidNumber(kind, s string) error {
+	return errors.New("saip: invalid " + kind + " " + strconv.Quote(s))
+}
@@ -0,0 +1,134 @@
+package saip
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/openesim/asn1go"
+)
+
+func TestICCID(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []byte
+	}{
+		{"8901234567890123456", []byte{0x98, 0x10, 0x32, 0x54, 0x76, 0x98, 0x10, 0x32, 0x54, 0xF6}},
+		{"89012345678901234567", []byte{0x98, 0x10, 0x32, 0x54, 0x76, 0x98, 0x10, 0x32, 0x54, 0x76}},
+		{"890123456789012345", []byte{0x98, 0x10, 0x32, 0x54, 0x76, 0x98, 0x10, 0x32, 0x54, 0xFF}},
+	}
+	for _, tt := range tests {
+		var id ICCID
+		if err := id.Set(tt.s); err != nil {
+			t.Errorf("Set(%q): %v", tt.s, err)
+			continue
+		}
+		if !bytes.Equal(id, tt.want) {
+			t.Errorf("Set(%q) = % X, want % X", tt.s, []byte(id), tt.want)
+		}
+		if got := id.String(); got != tt.s {
+			t.Errorf("ICCID(% X).String() = %q, want %q", tt.want, got, tt.s)
+		}
+		var back ICCID
+		if text, err := id.MarshalText(); err != nil || back.UnmarshalText(text) != nil || !bytes.Equal(back, id) {
+			t.Errorf("ICCID(% X): text round trip = % X, %v", tt.want, []byte(back), err)
+		}
+	}
+	for _, s := range []string{"", "89012345678901234", "890123456789012345678", "89012345678901234A", "8901234567890123 56"} {
+		var id ICCID
+		if err := id.Set(s); err == nil {
+			t.Errorf("Set(%q): no error", s)
+		}
+	}
+}
+
+func TestIMSI(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []byte
+	}{
+		{"001010123456789", []byte{0x08, 0x09, 0x10, 0x10, 0x10, 0x32, 0x54, 0x76, 0x98}},
+		{"00101012345678", []byte{0x08, 0x01, 0x10, 0x10, 0x10, 0x32, 0x54, 0x76, 0xF8}},
+		{"001010", []byte{0x04, 0x01, 0x10, 0x10, 0xF0, 0xFF, 0xFF, 0xFF, 0xFF}},
+	}
+	for _, tt := range tests {
+		var id IMSI
+		if err := id.Set(tt.s); err != nil {
+			t.Errorf("Set(%q): %v", tt.s, err)
+			continue
+		}
+		if !bytes.Equal(id, tt.want) {
+			t.Errorf("Set(%q) = % X, want % X", tt.s, []byte(id), tt.want)
+		}
+		if got := id.String(); got != tt.s {
+			t.Errorf("IMSI(% X).String() = %q, want %q", tt.want, got, tt.s)
+		}
+		var back IMSI
+		if text, err := id.MarshalText(); err != nil || back.UnmarshalText(text) != nil || !bytes.Equal(back, id) {
+			t.Errorf("IMSI(% X): text round trip = % X, %v", tt.want, []byte(back), err)
+		}
+	}
+	for _, s := range []string{"", "00101", "0010101234567890", "00101A"} {
+		var id IMSI
+		if err := id.Set(s); err == nil {
+			t.Errorf("Set(%q): no error", s)
+		}
+	}
+	for _, b := range [][]byte{nil, {0x08}, {0x00, 0x09}, {0x09, 0x09, 0x10}, {0xFF, 0xFF, 0xFF}} {
+		if got := IMSI(b).String(); got != "" {
+			t.Errorf("IMSI(% X).String() = %q, want \"\"", b, got)
+		}
+	}
+}
+
+func TestMSISDN(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []byte
+	}{
+		{"1", []byte{0xF1}},
+		{"12", []byte{0x21}},
+		{"447700900123", []byte{0x44, 0x77, 0x00, 0x09, 0x10, 0x32}},
+		{"12345678901234567890", []byte{0x21, 0x43, 0x65, 0x87, 0x09, 0x21, 0x43, 0x65, 0x87, 0x09}},
+	}
+	for _, tt := range tests {
+		var n MSISDN
+		if err := n.Set(tt.s); err != nil {
+			t.Errorf("Set(%q): %v", tt.s, err)
+			continue
+		}
+		if !bytes.Equal(n, tt.want) {
+			t.Errorf("Set(%q) = % X, want % X", tt.s, []byte(n), tt.want)
+		}
+		if got := n.String(); got != tt.s {
+			t.Errorf("MSISDN(% X).String() = %q, want %q", tt.want, got, tt.s)
+		}
+		var back MSISDN
+		if text, err := n.MarshalText(); err != nil || back.UnmarshalText(text) != nil || !bytes.Equal(back, n) {
+			t.Errorf("MSISDN(% X): text round trip = % X, %v", tt.want, []byte(back), err)
+		}
+	}
+	for _, s := range []string{"", "123456789012345678901", "+44"} {
+		var n MSISDN
+		if err := n.Set(s); err == nil {
+			t.Errorf("Set(%q): no error", s)
+		}
+	}
+	// Nibbles that are not digits are written as hex digits.
+	if got := MSISDN([]byte{0xA1, 0xFB}).String(); got != "1AB" {
+		t.Errorf("String() = %q, want %q", got, "1AB")
+	}
+}
+
+func TestICCIDUnmarshal(t *testing.T) {
+	want := ICCID{0x98, 0x10, 0x32, 0x54, 0x76, 0x98, 0x10, 0x32, 0x54, 0xF6}
+	for _, src := range []string{`'981032547698103254F6'H`, `"8901234567890123456"`} {
+		var id ICCID
+		if err := asn1go.Unmarshal([]byte(src), &id); err != nil {
+			t.Errorf("Unmarshal(%s): %v", src, err)
+			continue
+		}
+		if !bytes.Equal(id, want) {
+			t.Errorf("Unmarshal(%s) = % X, want % X", src, []byte(id), []byte(want))
+		}
+	}
+}
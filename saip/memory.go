@@ -0,0 +1,204 @@
+package saip
+
+import (
+	"reflect"
+)
+
+// MemoryOverhead is the memory an eUICC spends on each file on top of its
+// contents, for its file control parameters and directory entries. It
+// depends on the eUICC; the zero MemoryOverhead counts the contents of
+// the files alone.
+type MemoryOverhead struct {
+	DF int // bytes for each DF or ADF
+	EF int // bytes for each EF
+}
+
+// ElementMemory is the memory that a profile element takes on an eUICC,
+// as EstimateMemory estimates it.
+type ElementMemory struct {
+	Index int    // index of the element in the package
+	Name  string // identifier of the alternative of the element, such as "usim"
+
+	DFs, EFs int // number of DFs and EFs the element creates
+	Files    int // bytes of the contents of the EFs
+	Overhead int // bytes of the overhead of the DFs and EFs
+	Code     int // bytes of the load file of an application element
+}
+
+// Total returns the bytes the element takes in all.
+func (m ElementMemory) Total() int { return m.Files + m.Overhead + m.Code }
+
+// MemoryEstimate is the memory that a profile package takes on an eUICC,
+// element by element.
+type MemoryEstimate struct {
+	Elements []ElementMemory
+}
+
+// Total returns the bytes the package takes in all.
+func (e *MemoryEstimate) Total() int {
+	n := 0
+	for _, m := range e.Elements {
+		n += m.Total()
+	}
+	return n
+}
+
+// EstimateMemory estimates the memory that the profile package takes on
+// an eUICC, to compare it with the free memory an eUICC reports before
+// the package is installed. Each file that an element creates, with a file
+// descriptor, a createFCP or from the template of the element, counts its
+// efFileSize, or the maximumFileSize of a BER-TLV EF, and the overhead o
+// for a DF or an EF. A file whose file control parameters the package
+// does not give, because the template of the element defines them, counts
+// as an EF as large as the contents the package writes to it. Application
+// elements also count the size of their load file.
+//
+// The estimate leaves out the memory of keys, PINs, application instances
+// and the data of the eUICC itself, and is no more than a lower bound of
+// the memory the package needs.
+func (p *ProfilePackage) EstimateMemory(o MemoryOverhead) *MemoryEstimate {
+	e := &MemoryEstimate{Elements: make([]ElementMemory, len(p.Elements))}
+	for i := range p.Elements {
+		m := &e.Elements[i]
+		m.Index = i
+		alt, name := alternativeName(&p.Elements[i])
+		m.Name = name
+		if alt.Kind() != reflect.Pointer || alt.IsNil() || alt.Elem().Kind() != reflect.Struct {
+			continue
+		}
+		var fu fileUsage
+		s := alt.Elem()
+		for j := 0; j < s.NumField(); j++ {
+			switch f := s.Field(j).Interface().(type) {
+			case File:
+				fu.file(f)
+			case *File:
+				if f != nil {
+					fu.file(*f)
+				}
+			case []FileManagement:
+				for _, fm := range f {
+					fu.fileManagement(fm)
+				}
+			}
+		}
+		m.DFs, m.EFs, m.Files = fu.dfs, fu.efs, fu.size
+		m.Overhead = fu.dfs*o.DF + fu.efs*o.EF
+		if app := p.Elements[i].Application; app != nil && app.LoadBlock != nil {
+			m.Code = len(app.LoadBlock.LoadBlockObject)
+		}
+	}
+	return e
+}
+
+// alternativeName returns the chosen alternative of pe and its identifier,
+// or "" if it has none.
+func alternativeName(pe *ProfileElement) (reflect.Value, string) {
+	v := reflect.ValueOf(pe).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if f := v.Field(i); !f.IsZero() {
+			return f, fieldName(v.Type().Field(i))
+		}
+	}
+	return reflect.Value{}, ""
+}
+
+// A fileUsage adds up the files created by the files and file management
+// commands of a profile element.
+type fileUsage struct {
+	dfs, efs int
+	size     int // bytes of the contents of the EFs
+
+	// The file being written: whether it is an EF the element creates,
+	// whether its size is known, its size, and the offset of the next
+	// write.
+	created bool
+	sized   bool
+	cur     int
+	off     int
+}
+
+// file adds the file f, created unless it says doNotCreate.
+func (fu *fileUsage) file(f File) {
+	for _, item := range f {
+		if item.DoNotCreate != nil {
+			return
+		}
+	}
+	created := false
+	for _, item := range f {
+		switch {
+		case item.FileDescriptor != nil:
+			fu.create(item.FileDescriptor)
+			created = true
+		case item.FillFileOffset != nil:
+			if !created {
+				fu.create(&Fcp{})
+				created = true
+			}
+			fu.off += int(*item.FillFileOffset)
+		case item.FillFileContent != nil:
+			if !created {
+				fu.create(&Fcp{})
+				created = true
+			}
+			fu.fill(len(item.FillFileContent))
+		}
+	}
+	if !created {
+		fu.create(&Fcp{})
+	}
+	fu.finish()
+}
+
+// fileManagement adds the files the commands fm create.
+func (fu *fileUsage) fileManagement(fm FileManagement) {
+	for _, cmd := range fm {
+		switch {
+		case cmd.FilePath != nil:
+			// Contents written to a file selected by path do not change
+			// its size.
+			fu.finish()
+		case cmd.CreateFCP != nil:
+			fu.create(cmd.CreateFCP)
+		case cmd.FillFileOffset != nil:
+			fu.off += int(*cmd.FillFileOffset)
+		case cmd.FillFileContent != nil:
+			fu.fill(len(cmd.FillFileContent))
+		}
+	}
+	fu.finish()
+}
+
+// create starts a file with the file control parameters fcp.
+func (fu *fileUsage) create(fcp *Fcp) {
+	fu.finish()
+	if fd := fcp.FileDescriptor; len(fd) > 0 && fd[0]&0xbf == 0x38 {
+		fu.dfs++
+		return
+	}
+	fu.efs++
+	fu.created = true
+	size := fcp.EfFileSize
+	if size == nil && fcp.ProprietaryEFInfo != nil {
+		size = fcp.ProprietaryEFInfo.MaximumFileSize
+	}
+	fu.sized = size != nil
+	for _, c := range size {
+		fu.cur = fu.cur<<8 | int(c)
+	}
+}
+
+// fill writes n bytes of the current file.
+func (fu *fileUsage) fill(n int) {
+	fu.off += n
+	if fu.created && !fu.sized && fu.off > fu.cur {
+		fu.cur = fu.off
+	}
+}
+
+// finish adds the size of the current EF, if the element creates it.
+func (fu *fileUsage) finish() {
+	fu.size += fu.cur
+	fu.created, fu.sized, fu.cur, fu.off = false, false, 0, 0
+}
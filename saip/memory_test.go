@@ -0,0 +1,77 @@
+package saip
+
+import (
+	"testing"
+)
+
+func TestEstimateMemory(t *testing.T) {
+	df := &Fcp{FileDescriptor: []byte{0x78, 0x21}}
+	size := func(n ...byte) *Fcp { return &Fcp{FileDescriptor: []byte{0x41, 0x21}, EfFileSize: n} }
+	offset := UInt16(4)
+	tests := []struct {
+		pe   ProfileElement
+		want ElementMemory
+	}{
+		{ProfileElement{Header: &ProfileHeader{}}, ElementMemory{Name: "header"}},
+		{ProfileElement{Mf: &PEMF{
+			Mf:      File{{FileDescriptor: df}},
+			EfIccid: File{{FileDescriptor: size(10)}, {FillFileContent: make([]byte, 10)}},
+			EfArr:   File{{DoNotCreate: &struct{}{}}},
+			// Created from the template, as large as its contents.
+			EfPl: &File{{FillFileOffset: &offset}, {FillFileContent: []byte{1, 2}}},
+			// A BER-TLV EF.
+			EfDir: &File{{FileDescriptor: &Fcp{ProprietaryEFInfo: &ProprietaryInfo{MaximumFileSize: []byte{0x01, 0x00}}}}},
+		}}, ElementMemory{Name: "mf", DFs: 1, EFs: 3, Files: 10 + 6 + 256, Overhead: 100 + 3*10}},
+		{ProfileElement{GenericFileManagement: &PEGenericFileManagement{FileManagementCMD: []FileManagement{
+			{{CreateFCP: df}, {CreateFCP: size(0, 3)}, {FillFileContent: []byte{1, 2, 3}}},
+			{{FilePath: []byte{0x7F, 0xFF}}, {FillFileContent: make([]byte, 100)}},
+		}}}, ElementMemory{Name: "genericFileManagement", DFs: 1, EFs: 1, Files: 3, Overhead: 110}},
+		{ProfileElement{Application: &PEApplication{LoadBlock: &ApplicationLoadPackage{LoadBlockObject: make([]byte, 1000)}}},
+			ElementMemory{Name: "application", Code: 1000}},
+		{ProfileElement{End: &PEEnd{}}, ElementMemory{Name: "end"}},
+		{ProfileElement{}, ElementMemory{}},
+	}
+	o := MemoryOverhead{DF: 100, EF: 10}
+	for i, tt := range tests {
+		p := &ProfilePackage{Elements: []ProfileElement{tt.pe}}
+		e := p.EstimateMemory(o)
+		if len(e.Elements) != 1 {
+			t.Errorf("%d: EstimateMemory() has %d elements, want 1", i, len(e.Elements))
+			continue
+		}
+		if got := e.Elements[0]; got != tt.want {
+			t.Errorf("%d: EstimateMemory() = %+v, want %+v", i, got, tt.want)
+		}
+		if got, want := e.Total(), tt.want.Files+tt.want.Overhead+tt.want.Code; got != want {
+			t.Errorf("%d: Total() = %d, want %d", i, got, want)
+		}
+		zero := p.EstimateMemory(MemoryOverhead{}).Elements[0]
+		if zero.Overhead != 0 || zero.Total() != tt.want.Files+tt.want.Code {
+			t.Errorf("%d: EstimateMemory without overhead = %+v", i, zero)
+		}
+	}
+}
+
+func TestMemoryEstimateTotal(t *testing.T) {
+	var pes []ProfileElement
+	for _, tt := range []ProfileElement{
+		{Header: &ProfileHeader{}},
+		{Mf: &PEMF{Mf: File{{FileDescriptor: &Fcp{FileDescriptor: []byte{0x78}}}}, EfIccid: File{{FillFileContent: make([]byte, 10)}}, EfArr: File{{DoNotCreate: &struct{}{}}}}},
+		{Application: &PEApplication{LoadBlock: &ApplicationLoadPackage{LoadBlockObject: make([]byte, 50)}}},
+		{End: &PEEnd{}},
+	} {
+		pes = append(pes, tt)
+	}
+	e := (&ProfilePackage{Elements: pes}).EstimateMemory(MemoryOverhead{DF: 100, EF: 10})
+	for i, m := range e.Elements {
+		if m.Index != i {
+			t.Errorf("element %d has index %d", i, m.Index)
+		}
+	}
+	if got, want := e.Total(), 10+110+50; got != want {
+		t.Errorf("Total() = %d, want %d", got, want)
+	}
+	if got := (&MemoryEstimate{}).Total(); got != 0 {
+		t.Errorf("Total() of no elements = %d, want 0", got)
+	}
+}
@@ -0,0 +1,129 @@
+package saip
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// A ProfilePackage assembles the profile elements of a profile package,
+// for MarshalProfile or for the bound profile packages of SGP.22.
+//
+//	p := saip.NewProfilePackage(&header)
+//	p.Add(saip.ProfileElement{Mf: &mf}, saip.ProfileElement{Usim: &usim})
+//	p.Add(saip.ProfileElement{End: &saip.PEEnd{}})
+//	der, err := p.Marshal()
+type ProfilePackage struct {
+	Elements []ProfileElement
+}
+
+// NewProfilePackage returns a profile package that starts with the
+// profile header h.
+func NewProfilePackage(h *ProfileHeader) *ProfilePackage {
+	return &ProfilePackage{Elements: []ProfileElement{{Header: h}}}
+}
+
+// Add appends the profile elements pes to the package.
+func (p *ProfilePackage) Add(pes ...ProfileElement) {
+	p.Elements = append(p.Elements, pes...)
+}
+
+// Number sets the identification in the PEHeader of each profile element
+// to its position in the package: 1 for the element after the profile
+// header, which has no PEHeader, 2 for the next one and so on. Elements
+// without a PEHeader, such as unknown extension additions, take their
+// number too, so that adding them leaves the numbers of the others alone.
+func (p *ProfilePackage) Number() {
+	for i := range p.Elements {
+		if h := peHeader(&p.Elements[i]); h != nil {
+			h.Identification = UInt15(i)
+		}
+	}
+}
+
+// Check reports whether the package is well formed: each element has a
+// single alternative set, the first is the profile header and the last
+// is the end element, and neither appears anywhere else. The problem found
+// first is returned as a *PackageError.
+func (p *ProfilePackage) Check() error {
+	if len(p.Elements) == 0 {
+		return &PackageError{Index: 0, Msg: "missing profile header"}
+	}
+	if len(p.Elements)-1 > maxUInt15 {
+		return &PackageError{Index: maxUInt15 + 1, Msg: "exceeds the largest identification"}
+	}
+	last := len(p.Elements) - 1
+	for i := range p.Elements {
+		pe := &p.Elements[i]
+		switch _, n := alternative(pe); {
+		case n == 0:
+			return &PackageError{Index: i, Msg: "has no alternative set"}
+		case n > 1:
+			return &PackageError{Index: i, Msg: "has several alternatives set"}
+		}
+		switch {
+		case i == 0 && pe.Header == nil:
+			return &PackageError{Index: i, Msg: "is not the profile header"}
+		case i > 0 && pe.Header != nil:
+			return &PackageError{Index: i, Msg: "is a second profile header"}
+		case i == last && pe.End == nil:
+			return &PackageError{Index: i, Msg: "is the last element but is not the end element"}
+		case i < last && pe.End != nil:
+			return &PackageError{Index: i, Msg: "is an end element before the last element"}
+		}
+	}
+	return nil
+}
+
+// Marshal numbers the profile elements, checks the package and returns
+// its encoding, as MarshalProfile returns it.
+func (p *ProfilePackage) Marshal() ([]byte, error) {
+	if err := p.Check(); err != nil {
+		return nil, err
+	}
+	p.Number()
+	return MarshalProfile(p.Elements)
+}
+
+// A PackageError describes a profile package whose elements are not in an
+// order the specification allows.
+type PackageError struct {
+	Index int    // index of the offending element in the package
+	Msg   string // description of the problem
+}
+
+func (e *PackageError) Error() string {
+	return "saip: profile element " + strconv.Itoa(e.Index) + " " + e.Msg
+}
+
+const maxUInt15 = 32767
+
+// alternative returns the chosen alternative of pe, and the number of
+// alternatives set.
+func alternative(pe *ProfileElement) (reflect.Value, int) {
+	v := reflect.ValueOf(pe).Elem()
+	var alt reflect.Value
+	n := 0
+	for i := 0; i < v.NumField(); i++ {
+		if f := v.Field(i); !f.IsZero() {
+			alt = f
+			n++
+		}
+	}
+	return alt, n
+}
+
+var peHeaderType = reflect.TypeOf(PEHeader{})
+
+// peHeader returns the PEHeader of the profile element pe, the first
+// component of its alternative, or nil if it has none.
+func peHeader(pe *ProfileElement) *PEHeader {
+	alt, n := alternative(pe)
+	if n != 1 || alt.Kind() != reflect.Pointer {
+		return nil
+	}
+	s := alt.Elem()
+	if s.Kind() != reflect.Struct || s.NumField() == 0 || s.Field(0).Type() != peHeaderType {
+		return nil
+	}
+	return s.Field(0).Addr().Interface().(*PEHeader)
+}
@@ -0,0 +1,95 @@
+package saip
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestProfilePackageMarshal(t *testing.T) {
+	pes := testElements()
+	p := NewProfilePackage(pes[0].Header)
+	p.Add(pes[1], pes[2])
+	p.Add(ProfileElement{End: &PEEnd{}})
+	// Identifications are set by Marshal, whatever they were before.
+	p.Elements[1].Mf.MfHeader.Identification = 9
+	data, err := p.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := mustMarshal(t, testElements()); !bytes.Equal(data, want) {
+		t.Errorf("Marshal() = % X, want % X", data, want)
+	}
+}
+
+func TestProfilePackageNumber(t *testing.T) {
+	p := NewProfilePackage(&ProfileHeader{})
+	p.Add(
+		ProfileElement{Mf: &PEMF{}},
+		ProfileElement{Extensions: [][]byte{{0xBF, 0x3F, 0x00}}},
+		ProfileElement{PinCodes: &PEPINCodes{}},
+		ProfileElement{End: &PEEnd{}},
+	)
+	p.Number()
+	tests := []struct {
+		i    int
+		want UInt15
+	}{
+		{1, 1},
+		{3, 3},
+		{4, 4},
+	}
+	for _, tt := range tests {
+		if got := peHeader(&p.Elements[tt.i]).Identification; got != tt.want {
+			t.Errorf("element %d: identification %d, want %d", tt.i, got, tt.want)
+		}
+	}
+}
+
+func TestProfilePackageCheck(t *testing.T) {
+	header := ProfileElement{Header: &ProfileHeader{}}
+	mf := ProfileElement{Mf: &PEMF{}}
+	end := ProfileElement{End: &PEEnd{}}
+	tests := []struct {
+		pes   []ProfileElement
+		index int // -1 for a well formed package
+		msg   string
+	}{
+		{[]ProfileElement{header, end}, -1, ""},
+		{[]ProfileElement{header, mf, end}, -1, ""},
+		{nil, 0, "missing profile header"},
+		{[]ProfileElement{mf, end}, 0, "is not the profile header"},
+		{[]ProfileElement{header}, 0, "is the last element but is not the end element"},
+		{[]ProfileElement{header, mf}, 1, "is the last element but is not the end element"},
+		{[]ProfileElement{header, header, end}, 1, "is a second profile header"},
+		{[]ProfileElement{header, end, mf, end}, 1, "is an end element before the last element"},
+		{[]ProfileElement{header, {}, end}, 1, "has no alternative set"},
+		{[]ProfileElement{header, {Mf: &PEMF{}, End: &PEEnd{}}, end}, 1, "has several alternatives set"},
+		{append(append([]ProfileElement{header}, make([]ProfileElement, maxUInt15)...), end), maxUInt15 + 1, "exceeds the largest identification"},
+	}
+	for _, tt := range tests {
+		p := &ProfilePackage{Elements: tt.pes}
+		err := p.Check()
+		if tt.index < 0 {
+			if err != nil {
+				t.Errorf("Check() of %d elements: %v", len(tt.pes), err)
+			}
+			continue
+		}
+		var pe *PackageError
+		if !errors.As(err, &pe) || pe.Index != tt.index || pe.Msg != tt.msg {
+			t.Errorf("Check() of %d elements: error %v, want element %d %s", len(tt.pes), err, tt.index, tt.msg)
+			continue
+		}
+		if _, err := p.Marshal(); err == nil || err.Error() != pe.Error() {
+			t.Errorf("Marshal() of %d elements: error %v, want %v", len(tt.pes), err, pe)
+		}
+	}
+}
+
+func TestPackageError(t *testing.T) {
+	err := &PackageError{Index: 3, Msg: "is an end element before the last element"}
+	if got, want := err.Error(), "saip: profile element 3 is an end element before the last element"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,1111 @@
+// Code generated by asn1go-gen from module PEDefinitions. DO NOT EDIT.
+
+package saip
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/openesim/asn1go"
+)
+
+// UInt8 is the ASN.1 type UInt8, an INTEGER.
+type UInt8 int64
+
+// UInt15 is the ASN.1 type UInt15, an INTEGER.
+type UInt15 int64
+
+// UInt16 is the ASN.1 type UInt16, an INTEGER.
+type UInt16 int64
+
+// UInt31 is the ASN.1 type UInt31, an INTEGER.
+type UInt31 int64
+
+// ApplicationIdentifier is the ASN.1 type ApplicationIdentifier, an OCTET STRING.
+type ApplicationIdentifier []byte
+
+// PEHeader is the ASN.1 type PEHeader, a SEQUENCE.
+type PEHeader struct {
+	Mandated       *struct{}         `asn1:"mandated,tag:0"`
+	Identification UInt15            `asn1:"identification,tag:1"`
+	Extensions     asn1go.Extensions `asn1:"..."`
+}
+
+// ProfileElement is the ASN.1 type ProfileElement, a CHOICE.
+type ProfileElement struct {
+	Header                *ProfileHeader           `asn1:"header,choice,tag:0"`
+	GenericFileManagement *PEGenericFileManagement `asn1:"genericFileManagement,choice,tag:1"`
+	PinCodes              *PEPINCodes              `asn1:"pinCodes,choice,tag:2"`
+	PukCodes              *PEPUKCodes              `asn1:"pukCodes,choice,tag:3"`
+	AkaParameter          *PEAKAParameter          `asn1:"akaParameter,choice,tag:4"`
+	CdmaParameter         *PECDMAParameter         `asn1:"cdmaParameter,choice,tag:5"`
+	SecurityDomain        *PESecurityDomain        `asn1:"securityDomain,choice,tag:6"`
+	Rfm                   *PERFM                   `asn1:"rfm,choice,tag:7"`
+	Application           *PEApplication           `asn1:"application,choice,tag:8"`
+	NonStandard           *PENonStandard           `asn1:"nonStandard,choice,tag:9"`
+	End                   *PEEnd                   `asn1:"end,choice,tag:10"`
+	Rfu1                  *PEDummy                 `asn1:"rfu1,choice,tag:11"`
+	Rfu2                  *PEDummy                 `asn1:"rfu2,choice,tag:12"`
+	Rfu3                  *PEDummy                 `asn1:"rfu3,choice,tag:13"`
+	Rfu4                  *PEDummy                 `asn1:"rfu4,choice,tag:14"`
+	Rfu5                  *PEDummy                 `asn1:"rfu5,choice,tag:15"`
+	Mf                    *PEMF                    `asn1:"mf,choice,tag:16"`
+	Cd                    *PECD                    `asn1:"cd,choice,tag:17"`
+	Telecom               *PETELECOM               `asn1:"telecom,choice,tag:18"`
+	Usim                  *PEUSIM                  `asn1:"usim,choice,tag:19"`
+	OptUsim               *PEOPTUSIM               `asn1:"opt-usim,choice,tag:20"`
+	Isim                  *PEISIM                  `asn1:"isim,choice,tag:21"`
+	OptIsim               *PEOPTISIM               `asn1:"opt-isim,choice,tag:22"`
+	Phonebook             *PEPHONEBOOK             `asn1:"phonebook,choice,tag:23"`
+	GsmAccess             *PEGSMACCESS             `asn1:"gsm-access,choice,tag:24"`
+	Csim                  *PECSIM                  `asn1:"csim,choice,tag:25"`
+	OptCsim               *PEOPTCSIM               `asn1:"opt-csim,choice,tag:26"`
+	Eap                   *PEEAP                   `asn1:"eap,choice,tag:27"`
+	Extensions            asn1go.Extensions        `asn1:"...,choice"`
+}
+
+// PEDummy is the ASN.1 type PE-Dummy, a SEQUENCE.
+type PEDummy struct {
+	Extensions asn1go.Extensions `asn1:"..."`
+}
+
+// ProfileHeader is the ASN.1 type ProfileHeader, a SEQUENCE.
+type ProfileHeader struct {
+	MajorVersion            UInt8                                 `asn1:"major-version,tag:0"`
+	MinorVersion            UInt8                                 `asn1:"minor-version,tag:1"`
+	ProfileType             *string                               `asn1:"profileType,tag:2"`
+	Iccid                   []byte                                `asn1:"iccid,tag:3"`
+	Pol                     []byte                                `asn1:"pol,tag:4,omitempty"`
+	EUICCMandatoryServices  ServicesList                          `asn1:"eUICC-Mandatory-services,tag:5"`
+	EUICCMandatoryGFSTEList []asn1go.ObjectIdentifier             `asn1:"eUICC-Mandatory-GFSTEList,tag:6"`
+	ConnectivityParameters  []byte                                `asn1:"connectivityParameters,tag:7,omitempty"`
+	EUICCMandatoryAIDs      []ProfileHeaderEUICCMandatoryAIDsItem `asn1:"eUICC-Mandatory-AIDs,tag:8,omitempty"`
+	Extensions              asn1go.Extensions                     `asn1:"..."`
+}
+
+// ServicesList is the ASN.1 type ServicesList, a SEQUENCE.
+type ServicesList struct {
+	Contactless       *struct{}         `asn1:"contactless,tag:0"`
+	Usim              *struct{}         `asn1:"usim,tag:1"`
+	Isim              *struct{}         `asn1:"isim,tag:2"`
+	Csim              *struct{}         `asn1:"csim,tag:3"`
+	Milenage          *struct{}         `asn1:"milenage,tag:4"`
+	Tuak128           *struct{}         `asn1:"tuak128,tag:5"`
+	Cave              *struct{}         `asn1:"cave,tag:6"`
+	GbaUsim           *struct{}         `asn1:"gba-usim,tag:7"`
+	GbaIsim           *struct{}         `asn1:"gba-isim,tag:8"`
+	Mbms              *struct{}         `asn1:"mbms,tag:9"`
+	Eap               *struct{}         `asn1:"eap,tag:10"`
+	Javacard          *struct{}         `asn1:"javacard,tag:11"`
+	Multos            *struct{}         `asn1:"multos,tag:12"`
+	MultipleUsim      *struct{}         `asn1:"multiple-usim,tag:13"`
+	MultipleIsim      *struct{}         `asn1:"multiple-isim,tag:14"`
+	MultipleCsim      *struct{}         `asn1:"multiple-csim,tag:15"`
+	Tuak256           *struct{}         `asn1:"tuak256,tag:16"`
+	UsimTestAlgorithm *struct{}         `asn1:"usim-test-algorithm,tag:17"`
+	BerTlv            *struct{}         `asn1:"ber-tlv,tag:18"`
+	DfLink            *struct{}         `asn1:"dfLink,tag:19"`
+	CatTp             *struct{}         `asn1:"cat-tp,tag:20"`
+	GetIdentity       *struct{}         `asn1:"get-identity,tag:21"`
+	ProfileAX25519    *struct{}         `asn1:"profile-a-x25519,tag:22"`
+	ProfileBP256      *struct{}         `asn1:"profile-b-p256,tag:23"`
+	SuciCalculatorApi *struct{}         `asn1:"suciCalculatorApi,tag:24"`
+	Extensions        asn1go.Extensions `asn1:"..."`
+}
+
+// Fcp is the ASN.1 type Fcp, a SEQUENCE.
+type Fcp struct {
+	FileDescriptor               []byte                 `asn1:"fileDescriptor,tag:2,omitempty"`
+	FileID                       []byte                 `asn1:"fileID,tag:3,omitempty"`
+	DfName                       *ApplicationIdentifier `asn1:"dfName,tag:4"`
+	Lcsi                         []byte                 `asn1:"lcsi,tag:10,omitempty"`
+	SecurityAttributesReferenced []byte                 `asn1:"securityAttributesReferenced,tag:11,omitempty"`
+	EfFileSize                   []byte                 `asn1:"efFileSize,tag:0,omitempty"`
+	PinStatusTemplateDO          []byte                 `asn1:"pinStatusTemplateDO,tag:6,private,omitempty"`
+	ShortEFID                    []byte                 `asn1:"shortEFID,tag:8,omitempty"`
+	ProprietaryEFInfo            *ProprietaryInfo       `asn1:"proprietaryEFInfo,tag:5"`
+	LinkPath                     []byte                 `asn1:"linkPath,tag:7,private,omitempty"`
+	Extensions                   asn1go.Extensions      `asn1:"..."`
+}
+
+// ProprietaryInfo is the ASN.1 type ProprietaryInfo, a SEQUENCE.
+type ProprietaryInfo struct {
+	SpecialFileInformation []byte            `asn1:"specialFileInformation,tag:0,private,omitempty"`
+	FillPattern            []byte            `asn1:"fillPattern,tag:1,private,omitempty"`
+	RepeatPattern          []byte            `asn1:"repeatPattern,tag:2,private,omitempty"`
+	MaximumFileSize        []byte            `asn1:"maximumFileSize,tag:6,omitempty"`
+	FileDetails            []byte            `asn1:"fileDetails,tag:4,omitempty"`
+	Extensions             asn1go.Extensions `asn1:"..."`
+}
+
+// File is the ASN.1 type File, a SEQUENCE OF.
+type File []FileItem
+
+// PEMF is the ASN.1 type PE-MF, a SEQUENCE.
+type PEMF struct {
+	MfHeader   PEHeader                `asn1:"mf-header,tag:0"`
+	TemplateID asn1go.ObjectIdentifier `asn1:"templateID,tag:1"`
+	Mf         File                    `asn1:"mf,tag:2"`
+	EfPl       *File                   `asn1:"ef-pl,tag:3"`
+	EfIccid    File                    `asn1:"ef-iccid,tag:4"`
+	EfDir      *File                   `asn1:"ef-dir,tag:5"`
+	EfArr      File                    `asn1:"ef-arr,tag:6"`
+	EfUmpc     *File                   `asn1:"ef-umpc,tag:7"`
+	Extensions asn1go.Extensions       `asn1:"..."`
+}
+
+// PECD is the ASN.1 type PE-CD, a SEQUENCE.
+type PECD struct {
+	CdHeader    PEHeader                `asn1:"cd-header,tag:0"`
+	TemplateID  asn1go.ObjectIdentifier `asn1:"templateID,tag:1"`
+	DfCd        File                    `asn1:"df-cd,tag:2"`
+	EfLaunchpad *File                   `asn1:"ef-launchpad,tag:3"`
+	EfIcon      *File                   `asn1:"ef-icon,tag:4"`
+	Extensions  asn1go.Extensions       `asn1:"..."`
+}
+
+// PETELECOM is the ASN.1 type PE-TELECOM, a SEQUENCE.
+type PETELECOM struct {
+	TelecomHeader PEHeader                `asn1:"telecom-header,tag:0"`
+	TemplateID    asn1go.ObjectIdentifier `asn1:"templateID,tag:1"`
+	DfTelecom     File                    `asn1:"df-telecom,tag:2"`
+	EfArr         *File                   `asn1:"ef-arr,tag:3"`
+	EfRma         *File                   `asn1:"ef-rma,tag:4"`
+	EfSume        *File                   `asn1:"ef-sume,tag:5"`
+	EfIceDn       *File                   `asn1:"ef-ice-dn,tag:6"`
+	EfIceFf       *File                   `asn1:"ef-ice-ff,tag:7"`
+	EfPsismsc     *File                   `asn1:"ef-psismsc,tag:8"`
+	DfGraphics    *File                   `asn1:"df-graphics,tag:9"`
+	EfImg         *File                   `asn1:"ef-img,tag:10"`
+	EfIidf        *File                   `asn1:"ef-iidf,tag:11"`
+	EfIceGraphics *File                   `asn1:"ef-ice-graphics,tag:12"`
+	EfLaunchScws  *File                   `asn1:"ef-launch-scws,tag:13"`
+	EfIcon        *File                   `asn1:"ef-icon,tag:14"`
+	DfPhonebook   *File                   `asn1:"df-phonebook,tag:15"`
+	EfPbr         *File                   `asn1:"ef-pbr,tag:16"`
+	EfExt1        *File                   `asn1:"ef-ext1,tag:17"`
+	EfAas         *File                   `asn1:"ef-aas,tag:18"`
+	EfGas         *File                   `asn1:"ef-gas,tag:19"`
+	EfPsc         *File                   `asn1:"ef-psc,tag:20"`
+	EfCc          *File                   `asn1:"ef-cc,tag:21"`
+	EfPuid        *File                   `asn1:"ef-puid,tag:22"`
+	EfIap         *File                   `asn1:"ef-iap,tag:23"`
+	EfAdn         *File                   `asn1:"ef-adn,tag:24"`
+	DfMultimedia  *File                   `asn1:"df-multimedia,tag:25"`
+	EfMml         *File                   `asn1:"ef-mml,tag:26"`
+	EfMmdf        *File                   `asn1:"ef-mmdf,tag:27"`
+	DfMmss        *File                   `asn1:"df-mmss,tag:28"`
+	EfMlpl        *File                   `asn1:"ef-mlpl,tag:29"`
+	EfMspl        *File                   `asn1:"ef-mspl,tag:30"`
+	EfMmssmode    *File                   `asn1:"ef-mmssmode,tag:31"`
+	Extensions    asn1go.Extensions       `asn1:"..."`
+}
+
+// PEUSIM is the ASN.1 type PE-USIM, a SEQUENCE.
+type PEUSIM struct {
+	UsimHeader  PEHeader                `asn1:"usim-header,tag:0"`
+	TemplateID  asn1go.ObjectIdentifier `asn1:"templateID,tag:1"`
+	AdfUsim     File                    `asn1:"adf-usim,tag:2"`
+	EfImsi      File                    `asn1:"ef-imsi,tag:3"`
+	EfArr       File                    `asn1:"ef-arr,tag:4"`
+	EfKeys      *File                   `asn1:"ef-keys,tag:5"`
+	EfKeysPS    *File                   `asn1:"ef-keysPS,tag:6"`
+	EfHpplmn    *File                   `asn1:"ef-hpplmn,tag:7"`
+	EfUst       File                    `asn1:"ef-ust,tag:8"`
+	EfFdn       *File                   `asn1:"ef-fdn,tag:9"`
+	EfSms       *File                   `asn1:"ef-sms,tag:10"`
+	EfSmsp      *File                   `asn1:"ef-smsp,tag:11"`
+	EfSmss      *File                   `asn1:"ef-smss,tag:12"`
+	EfSpn       *File                   `asn1:"ef-spn,tag:13"`
+	EfEst       File                    `asn1:"ef-est,tag:14"`
+	EfStartHfn  *File                   `asn1:"ef-start-hfn,tag:15"`
+	EfThreshold *File                   `asn1:"ef-threshold,tag:16"`
+	EfPsloci    *File                   `asn1:"ef-psloci,tag:17"`
+	EfAcc       *File                   `asn1:"ef-acc,tag:18"`
+	EfFplmn     *File                   `asn1:"ef-fplmn,tag:19"`
+	EfLoci      *File                   `asn1:"ef-loci,tag:20"`
+	EfAd        *File                   `asn1:"ef-ad,tag:21"`
+	EfEcc       File                    `asn1:"ef-ecc,tag:22"`
+	EfNetpar    *File                   `asn1:"ef-netpar,tag:23"`
+	EfEpsloci   *File                   `asn1:"ef-epsloci,tag:24"`
+	EfEpsnsc    *File                   `asn1:"ef-epsnsc,tag:25"`
+	Extensions  asn1go.Extensions       `asn1:"..."`
+}
+
+// PEOPTUSIM is the ASN.1 type PE-OPT-USIM, a SEQUENCE.
+type PEOPTUSIM struct {
+	OptusimHeader              PEHeader                `asn1:"optusim-header,tag:0"`
+	TemplateID                 asn1go.ObjectIdentifier `asn1:"templateID,tag:1"`
+	EfLi                       *File                   `asn1:"ef-li,tag:2"`
+	EfAcmax                    *File                   `asn1:"ef-acmax,tag:3"`
+	EfAcm                      *File                   `asn1:"ef-acm,tag:4"`
+	EfGid1                     *File                   `asn1:"ef-gid1,tag:5"`
+	EfGid2                     *File                   `asn1:"ef-gid2,tag:6"`
+	EfMsisdn                   *File                   `asn1:"ef-msisdn,tag:7"`
+	EfPuct                     *File                   `asn1:"ef-puct,tag:8"`
+	EfCbmi                     *File                   `asn1:"ef-cbmi,tag:9"`
+	EfCbmid                    *File                   `asn1:"ef-cbmid,tag:10"`
+	EfSdn                      *File                   `asn1:"ef-sdn,tag:11"`
+	EfExt2                     *File                   `asn1:"ef-ext2,tag:12"`
+	EfExt3                     *File                   `asn1:"ef-ext3,tag:13"`
+	EfCbmir                    *File                   `asn1:"ef-cbmir,tag:14"`
+	EfPlmnwact                 *File                   `asn1:"ef-plmnwact,tag:15"`
+	EfOplmnwact                *File                   `asn1:"ef-oplmnwact,tag:16"`
+	EfHplmnwact                *File                   `asn1:"ef-hplmnwact,tag:17"`
+	EfDck                      *File                   `asn1:"ef-dck,tag:18"`
+	EfCnl                      *File                   `asn1:"ef-cnl,tag:19"`
+	EfSmsr                     *File                   `asn1:"ef-smsr,tag:20"`
+	EfBdn                      *File                   `asn1:"ef-bdn,tag:21"`
+	EfExt5                     *File                   `asn1:"ef-ext5,tag:22"`
+	EfCcp2                     *File                   `asn1:"ef-ccp2,tag:23"`
+	EfExt4                     *File                   `asn1:"ef-ext4,tag:24"`
+	EfAcl                      *File                   `asn1:"ef-acl,tag:25"`
+	EfCmi                      *File                   `asn1:"ef-cmi,tag:26"`
+	EfIci                      *File                   `asn1:"ef-ici,tag:27"`
+	EfOci                      *File                   `asn1:"ef-oci,tag:28"`
+	EfIct                      *File                   `asn1:"ef-ict,tag:29"`
+	EfOct                      *File                   `asn1:"ef-oct,tag:30"`
+	EfVgcs                     *File                   `asn1:"ef-vgcs,tag:31"`
+	EfVgcss                    *File                   `asn1:"ef-vgcss,tag:32"`
+	EfVbs                      *File                   `asn1:"ef-vbs,tag:33"`
+	EfVbss                     *File                   `asn1:"ef-vbss,tag:34"`
+	EfEmlpp                    *File                   `asn1:"ef-emlpp,tag:35"`
+	EfAaem                     *File                   `asn1:"ef-aaem,tag:36"`
+	EfHiddenkey                *File                   `asn1:"ef-hiddenkey,tag:37"`
+	EfPnn                      *File                   `asn1:"ef-pnn,tag:38"`
+	EfOpl                      *File                   `asn1:"ef-opl,tag:39"`
+	EfMbdn                     *File                   `asn1:"ef-mbdn,tag:40"`
+	EfExt6                     *File                   `asn1:"ef-ext6,tag:41"`
+	EfMbi                      *File                   `asn1:"ef-mbi,tag:42"`
+	EfMwis                     *File                   `asn1:"ef-mwis,tag:43"`
+	EfCfis                     *File                   `asn1:"ef-cfis,tag:44"`
+	EfExt7                     *File                   `asn1:"ef-ext7,tag:45"`
+	EfSpdi                     *File                   `asn1:"ef-spdi,tag:46"`
+	EfMmsn                     *File                   `asn1:"ef-mmsn,tag:47"`
+	EfExt8                     *File                   `asn1:"ef-ext8,tag:48"`
+	EfMmsicp                   *File                   `asn1:"ef-mmsicp,tag:49"`
+	EfMmsup                    *File                   `asn1:"ef-mmsup,tag:50"`
+	EfMmsucp                   *File                   `asn1:"ef-mmsucp,tag:51"`
+	EfNia                      *File                   `asn1:"ef-nia,tag:52"`
+	EfVgcsca                   *File                   `asn1:"ef-vgcsca,tag:53"`
+	EfVbsca                    *File                   `asn1:"ef-vbsca,tag:54"`
+	EfGbabp                    *File                   `asn1:"ef-gbabp,tag:55"`
+	EfMsk                      *File                   `asn1:"ef-msk,tag:56"`
+	EfMuk                      *File                   `asn1:"ef-muk,tag:57"`
+	EfEhplmn                   *File                   `asn1:"ef-ehplmn,tag:58"`
+	EfGbanl                    *File                   `asn1:"ef-gbanl,tag:59"`
+	EfEhplmnpi                 *File                   `asn1:"ef-ehplmnpi,tag:60"`
+	EfLrplmnsi                 *File                   `asn1:"ef-lrplmnsi,tag:61"`
+	EfNafkca                   *File                   `asn1:"ef-nafkca,tag:62"`
+	EfSpni                     *File                   `asn1:"ef-spni,tag:63"`
+	EfPnni                     *File                   `asn1:"ef-pnni,tag:64"`
+	EfNcpIp                    *File                   `asn1:"ef-ncp-ip,tag:65"`
+	EfUfc                      *File                   `asn1:"ef-ufc,tag:66"`
+	EfNasconfig                *File                   `asn1:"ef-nasconfig,tag:67"`
+	EfUicciari                 *File                   `asn1:"ef-uicciari,tag:68"`
+	EfPws                      *File                   `asn1:"ef-pws,tag:69"`
+	EfFdnuri                   *File                   `asn1:"ef-fdnuri,tag:70"`
+	EfBdnuri                   *File                   `asn1:"ef-bdnuri,tag:71"`
+	EfSdnuri                   *File                   `asn1:"ef-sdnuri,tag:72"`
+	EfIal                      *File                   `asn1:"ef-ial,tag:73"`
+	EfIps                      *File                   `asn1:"ef-ips,tag:74"`
+	EfIpd                      *File                   `asn1:"ef-ipd,tag:75"`
+	EfEpdgid                   *File                   `asn1:"ef-epdgid,tag:76"`
+	EfEpdgselection            *File                   `asn1:"ef-epdgselection,tag:77"`
+	EfEpdgidem                 *File                   `asn1:"ef-epdgidem,tag:78"`
+	EfEpdgselectionem          *File                   `asn1:"ef-epdgselectionem,tag:79"`
+	EfFrompreferred            *File                   `asn1:"ef-frompreferred,tag:80"`
+	EfImsconfigdata            *File                   `asn1:"ef-imsconfigdata,tag:81"`
+	Ef3gpppsdataoff            *File                   `asn1:"ef-3gpppsdataoff,tag:82"`
+	Ef3gpppsdataoffservicelist *File                   `asn1:"ef-3gpppsdataoffservicelist,tag:83"`
+	EfXcapconfigdata           *File                   `asn1:"ef-xcapconfigdata,tag:84"`
+	EfEarfcnlist               *File                   `asn1:"ef-earfcnlist,tag:85"`
+	Extensions                 asn1go.Extensions       `asn1:"..."`
+}
+
+// PEISIM is the ASN.1 type PE-ISIM, a SEQUENCE.
+type PEISIM struct {
+	IsimHeader PEHeader                `asn1:"isim-header,tag:0"`
+	TemplateID asn1go.ObjectIdentifier `asn1:"templateID,tag:1"`
+	AdfIsim    File                    `asn1:"adf-isim,tag:2"`
+	EfImpi     File                    `asn1:"ef-impi,tag:3"`
+	EfImpu     File                    `asn1:"ef-impu,tag:4"`
+	EfDomain   File                    `asn1:"ef-domain,tag:5"`
+	EfIst      *File                   `asn1:"ef-ist,tag:6"`
+	EfAd       File                    `asn1:"ef-ad,tag:7"`
+	EfArr      File                    `asn1:"ef-arr,tag:8"`
+	Extensions asn1go.Extensions       `asn1:"..."`
+}
+
+// PEOPTISIM is the ASN.1 type PE-OPT-ISIM, a SEQUENCE.
+type PEOPTISIM struct {
+	OptisimHeader PEHeader                `asn1:"optisim-header,tag:0"`
+	TemplateID    asn1go.ObjectIdentifier `asn1:"templateID,tag:1"`
+	EfPcscf       *File                   `asn1:"ef-pcscf,tag:2"`
+	EfSms         *File                   `asn1:"ef-sms,tag:3"`
+	EfSmsp        *File                   `asn1:"ef-smsp,tag:4"`
+	EfSmss        *File                   `asn1:"ef-smss,tag:5"`
+	EfSmsr        *File                   `asn1:"ef-smsr,tag:6"`
+	EfGbabp       *File                   `asn1:"ef-gbabp,tag:7"`
+	EfGbanl       *File                   `asn1:"ef-gbanl,tag:8"`
+	EfNafkca      *File                   `asn1:"ef-nafkca,tag:9"`
+	EfUicciari    *File                   `asn1:"ef-uicciari,tag:10"`
+	Extensions    asn1go.Extensions       `asn1:"..."`
+}
+
+// PEPHONEBOOK is the ASN.1 type PE-PHONEBOOK, a SEQUENCE.
+type PEPHONEBOOK struct {
+	PhonebookHeader PEHeader                `asn1:"phonebook-header,tag:0"`
+	TemplateID      asn1go.ObjectIdentifier `asn1:"templateID,tag:1"`
+	DfPhonebook     File                    `asn1:"df-phonebook,tag:2"`
+	EfPbr           File                    `asn1:"ef-pbr,tag:3"`
+	EfExt1          *File                   `asn1:"ef-ext1,tag:4"`
+	EfAas           *File                   `asn1:"ef-aas,tag:5"`
+	EfGas           *File                   `asn1:"ef-gas,tag:6"`
+	EfPsc           File                    `asn1:"ef-psc,tag:7"`
+	EfCc            File                    `asn1:"ef-cc,tag:8"`
+	EfPuid          File                    `asn1:"ef-puid,tag:9"`
+	EfIap           *File                   `asn1:"ef-iap,tag:10"`
+	EfAdn           File                    `asn1:"ef-adn,tag:11"`
+	EfPbc           *File                   `asn1:"ef-pbc,tag:12"`
+	EfAnr           *File                   `asn1:"ef-anr,tag:13"`
+	EfPuri          *File                   `asn1:"ef-puri,tag:14"`
+	EfEmail         *File                   `asn1:"ef-email,tag:15"`
+	EfSne           *File                   `asn1:"ef-sne,tag:16"`
+	EfUid           *File                   `asn1:"ef-uid,tag:17"`
+	EfGrp           *File                   `asn1:"ef-grp,tag:18"`
+	EfCcp1          *File                   `asn1:"ef-ccp1,tag:19"`
+	Extensions      asn1go.Extensions       `asn1:"..."`
+}
+
+// PEGSMACCESS is the ASN.1 type PE-GSM-ACCESS, a SEQUENCE.
+type PEGSMACCESS struct {
+	GsmAccessHeader PEHeader                `asn1:"gsm-access-header,tag:0"`
+	TemplateID      asn1go.ObjectIdentifier `asn1:"templateID,tag:1"`
+	DfGsmAccess     File                    `asn1:"df-gsm-access,tag:2"`
+	EfKc            *File                   `asn1:"ef-kc,tag:3"`
+	EfKcgprs        *File                   `asn1:"ef-kcgprs,tag:4"`
+	EfCpbcch        *File                   `asn1:"ef-cpbcch,tag:5"`
+	EfInvscan       *File                   `asn1:"ef-invscan,tag:6"`
+	Extensions      asn1go.Extensions       `asn1:"..."`
+}
+
+// PECSIM is the ASN.1 type PE-CSIM, a SEQUENCE.
+type PECSIM struct {
+	CsimHeader    PEHeader                `asn1:"csim-header,tag:0"`
+	TemplateID    asn1go.ObjectIdentifier `asn1:"templateID,tag:1"`
+	AdfCsim       File                    `asn1:"adf-csim,tag:2"`
+	EfArr         File                    `asn1:"ef-arr,tag:3"`
+	EfCallCount   File                    `asn1:"ef-call-count,tag:4"`
+	EfImsiM       File                    `asn1:"ef-imsi-m,tag:5"`
+	EfImsiT       File                    `asn1:"ef-imsi-t,tag:6"`
+	EfTmsi        File                    `asn1:"ef-tmsi,tag:7"`
+	EfAh          File                    `asn1:"ef-ah,tag:8"`
+	EfAop         File                    `asn1:"ef-aop,tag:9"`
+	EfAloc        File                    `asn1:"ef-aloc,tag:10"`
+	EfCdmahome    File                    `asn1:"ef-cdmahome,tag:11"`
+	EfZnregi      File                    `asn1:"ef-znregi,tag:12"`
+	EfSnregi      File                    `asn1:"ef-snregi,tag:13"`
+	EfDistregi    File                    `asn1:"ef-distregi,tag:14"`
+	EfAccolc      File                    `asn1:"ef-accolc,tag:15"`
+	EfTerm        File                    `asn1:"ef-term,tag:16"`
+	EfAcp         File                    `asn1:"ef-acp,tag:17"`
+	EfPrl         File                    `asn1:"ef-prl,tag:18"`
+	EfRuimid      File                    `asn1:"ef-ruimid,tag:19"`
+	EfCsimSt      File                    `asn1:"ef-csim-st,tag:20"`
+	EfSpc         File                    `asn1:"ef-spc,tag:21"`
+	EfOtapaspc    File                    `asn1:"ef-otapaspc,tag:22"`
+	EfNamlock     File                    `asn1:"ef-namlock,tag:23"`
+	EfOta         File                    `asn1:"ef-ota,tag:24"`
+	EfSp          File                    `asn1:"ef-sp,tag:25"`
+	EfEsnMeidMe   File                    `asn1:"ef-esn-meid-me,tag:26"`
+	EfLi          File                    `asn1:"ef-li,tag:27"`
+	EfUsgind      File                    `asn1:"ef-usgind,tag:28"`
+	EfAd          File                    `asn1:"ef-ad,tag:29"`
+	EfMaxPrl      File                    `asn1:"ef-max-prl,tag:30"`
+	EfSpcs        File                    `asn1:"ef-spcs,tag:31"`
+	EfMecrp       File                    `asn1:"ef-mecrp,tag:32"`
+	EfHomeTag     *File                   `asn1:"ef-home-tag,tag:33"`
+	EfGroupTag    *File                   `asn1:"ef-group-tag,tag:34"`
+	EfSpecificTag *File                   `asn1:"ef-specific-tag,tag:35"`
+	EfCallPrompt  *File                   `asn1:"ef-call-prompt,tag:36"`
+	Extensions    asn1go.Extensions       `asn1:"..."`
+}
+
+// PEOPTCSIM is the ASN.1 type PE-OPT-CSIM, a SEQUENCE.
+type PEOPTCSIM struct {
+	OptcsimHeader    PEHeader                `asn1:"optcsim-header,tag:0"`
+	TemplateID       asn1go.ObjectIdentifier `asn1:"templateID,tag:1"`
+	EfSsci           *File                   `asn1:"ef-ssci,tag:2"`
+	EfFdn            *File                   `asn1:"ef-fdn,tag:3"`
+	EfSms            *File                   `asn1:"ef-sms,tag:4"`
+	EfSmsp           *File                   `asn1:"ef-smsp,tag:5"`
+	EfSmss           *File                   `asn1:"ef-smss,tag:6"`
+	EfSsfc           *File                   `asn1:"ef-ssfc,tag:7"`
+	EfSpn            *File                   `asn1:"ef-spn,tag:8"`
+	EfMdn            *File                   `asn1:"ef-mdn,tag:9"`
+	EfEcc            *File                   `asn1:"ef-ecc,tag:10"`
+	EfMe3gpdopc      *File                   `asn1:"ef-me3gpdopc,tag:11"`
+	Ef3gpdopm        *File                   `asn1:"ef-3gpdopm,tag:12"`
+	EfSipcap         *File                   `asn1:"ef-sipcap,tag:13"`
+	EfMipcap         *File                   `asn1:"ef-mipcap,tag:14"`
+	EfSipupp         *File                   `asn1:"ef-sipupp,tag:15"`
+	EfMipupp         *File                   `asn1:"ef-mipupp,tag:16"`
+	EfSipsp          *File                   `asn1:"ef-sipsp,tag:17"`
+	EfMipsp          *File                   `asn1:"ef-mipsp,tag:18"`
+	EfSippapss       *File                   `asn1:"ef-sippapss,tag:19"`
+	EfPuzl           *File                   `asn1:"ef-puzl,tag:20"`
+	EfMaxpuzl        *File                   `asn1:"ef-maxpuzl,tag:21"`
+	EfHrpdcap        *File                   `asn1:"ef-hrpdcap,tag:22"`
+	EfHrpdupp        *File                   `asn1:"ef-hrpdupp,tag:23"`
+	EfCsspr          *File                   `asn1:"ef-csspr,tag:24"`
+	EfAtc            *File                   `asn1:"ef-atc,tag:25"`
+	EfEprl           *File                   `asn1:"ef-eprl,tag:26"`
+	EfBcsmscfg       *File                   `asn1:"ef-bcsmscfg,tag:27"`
+	EfBcsmspref      *File                   `asn1:"ef-bcsmspref,tag:28"`
+	EfBcsmstable     *File                   `asn1:"ef-bcsmstable,tag:29"`
+	EfBcsmsp         *File                   `asn1:"ef-bcsmsp,tag:30"`
+	EfBakpara        *File                   `asn1:"ef-bakpara,tag:31"`
+	EfUpbakpara      *File                   `asn1:"ef-upbakpara,tag:32"`
+	EfMmsn           *File                   `asn1:"ef-mmsn,tag:33"`
+	EfExt8           *File                   `asn1:"ef-ext8,tag:34"`
+	EfMmsicp         *File                   `asn1:"ef-mmsicp,tag:35"`
+	EfMmsup          *File                   `asn1:"ef-mmsup,tag:36"`
+	EfMmsucp         *File                   `asn1:"ef-mmsucp,tag:37"`
+	EfAuthCapability *File                   `asn1:"ef-auth-capability,tag:38"`
+	Ef3gcik          *File                   `asn1:"ef-3gcik,tag:39"`
+	EfDck            *File                   `asn1:"ef-dck,tag:40"`
+	EfGid1           *File                   `asn1:"ef-gid1,tag:41"`
+	EfGid2           *File                   `asn1:"ef-gid2,tag:42"`
+	EfCdmacnl        *File                   `asn1:"ef-cdmacnl,tag:43"`
+	EfSfEuimid       *File                   `asn1:"ef-sf-euimid,tag:44"`
+	EfEst            *File                   `asn1:"ef-est,tag:45"`
+	EfHiddenKey      *File                   `asn1:"ef-hidden-key,tag:46"`
+	EfLcsver         *File                   `asn1:"ef-lcsver,tag:47"`
+	EfLcscp          *File                   `asn1:"ef-lcscp,tag:48"`
+	EfSdn            *File                   `asn1:"ef-sdn,tag:49"`
+	EfExt2           *File                   `asn1:"ef-ext2,tag:50"`
+	EfExt3           *File                   `asn1:"ef-ext3,tag:51"`
+	EfIci            *File                   `asn1:"ef-ici,tag:52"`
+	EfOci            *File                   `asn1:"ef-oci,tag:53"`
+	EfExt5           *File                   `asn1:"ef-ext5,tag:54"`
+	EfCcp2           *File                   `asn1:"ef-ccp2,tag:55"`
+	EfApplabels      *File                   `asn1:"ef-applabels,tag:56"`
+	EfModel          *File                   `asn1:"ef-model,tag:57"`
+	EfRc             *File                   `asn1:"ef-rc,tag:58"`
+	EfSmscap         *File                   `asn1:"ef-smscap,tag:59"`
+	EfMipflags       *File                   `asn1:"ef-mipflags,tag:60"`
+	Ef3gpduppext     *File                   `asn1:"ef-3gpduppext,tag:61"`
+	EfIpv6cap        *File                   `asn1:"ef-ipv6cap,tag:62"`
+	EfTcpconfig      *File                   `asn1:"ef-tcpconfig,tag:63"`
+	EfDgc            *File                   `asn1:"ef-dgc,tag:64"`
+	EfWapbrowsercp   *File                   `asn1:"ef-wapbrowsercp,tag:65"`
+	EfWapbrowserbm   *File                   `asn1:"ef-wapbrowserbm,tag:66"`
+	EfMmsconfig      *File                   `asn1:"ef-mmsconfig,tag:67"`
+	EfJdl            *File                   `asn1:"ef-jdl,tag:68"`
+	Extensions       asn1go.Extensions       `asn1:"..."`
+}
+
+// PEEAP is the ASN.1 type PE-EAP, a SEQUENCE.
+type PEEAP struct {
+	EapHeader        PEHeader           `asn1:"eap-header,tag:0"`
+	EapConfiguration []EAPConfiguration `asn1:"eapConfiguration,tag:1"`
+	Extensions       asn1go.Extensions  `asn1:"..."`
+}
+
+// EAPConfiguration is the ASN.1 type EAPConfiguration, a SEQUENCE.
+type EAPConfiguration struct {
+	EapApplicationInstance ApplicationIdentifier `asn1:"eapApplicationInstance,tag:0"`
+	EapLabels              []byte                `asn1:"eapLabels,tag:1,omitempty"`
+	EapParameters          []byte                `asn1:"eapParameters,tag:2,omitempty"`
+	Extensions             asn1go.Extensions     `asn1:"..."`
+}
+
+// PEGenericFileManagement is the ASN.1 type PE-GenericFileManagement, a SEQUENCE.
+type PEGenericFileManagement struct {
+	GfmHeader         PEHeader          `asn1:"gfm-header,tag:0"`
+	FileManagementCMD []FileManagement  `asn1:"fileManagementCMD,tag:1"`
+	Extensions        asn1go.Extensions `asn1:"..."`
+}
+
+// FileManagement is the ASN.1 type FileManagement, a SEQUENCE OF.
+type FileManagement []FileManagementItem
+
+// PINKeyReferenceValue is the ASN.1 type PINKeyReferenceValue, an INTEGER.
+type PINKeyReferenceValue int64
+
+// Values of PINKeyReferenceValue.
+const (
+	PINKeyReferenceValuePinAppl1       PINKeyReferenceValue = 1
+	PINKeyReferenceValuePinAppl2       PINKeyReferenceValue = 2
+	PINKeyReferenceValuePinAppl3       PINKeyReferenceValue = 3
+	PINKeyReferenceValuePinAppl4       PINKeyReferenceValue = 4
+	PINKeyReferenceValuePinAppl5       PINKeyReferenceValue = 5
+	PINKeyReferenceValuePinAppl6       PINKeyReferenceValue = 6
+	PINKeyReferenceValuePinAppl7       PINKeyReferenceValue = 7
+	PINKeyReferenceValuePinAppl8       PINKeyReferenceValue = 8
+	PINKeyReferenceValueAdm1           PINKeyReferenceValue = 10
+	PINKeyReferenceValueAdm2           PINKeyReferenceValue = 11
+	PINKeyReferenceValueAdm3           PINKeyReferenceValue = 12
+	PINKeyReferenceValueAdm4           PINKeyReferenceValue = 13
+	PINKeyReferenceValueAdm5           PINKeyReferenceValue = 14
+	PINKeyReferenceValueSecondPINAppl1 PINKeyReferenceValue = 129
+	PINKeyReferenceValueSecondPINAppl2 PINKeyReferenceValue = 130
+	PINKeyReferenceValueSecondPINAppl3 PINKeyReferenceValue = 131
+	PINKeyReferenceValueSecondPINAppl4 PINKeyReferenceValue = 132
+	PINKeyReferenceValueSecondPINAppl5 PINKeyReferenceValue = 133
+	PINKeyReferenceValueSecondPINAppl6 PINKeyReferenceValue = 134
+	PINKeyReferenceValueSecondPINAppl7 PINKeyReferenceValue = 135
+	PINKeyReferenceValueSecondPINAppl8 PINKeyReferenceValue = 136
+	PINKeyReferenceValueAdm6           PINKeyReferenceValue = 138
+	PINKeyReferenceValueAdm7           PINKeyReferenceValue = 139
+	PINKeyReferenceValueAdm8           PINKeyReferenceValue = 140
+	PINKeyReferenceValueAdm9           PINKeyReferenceValue = 141
+	PINKeyReferenceValueAdm10          PINKeyReferenceValue = 142
+)
+
+// String returns the identifier of v, or its number if it has none.
+func (v PINKeyReferenceValue) String() string {
+	switch v {
+	case PINKeyReferenceValuePinAppl1:
+		return "pinAppl1"
+	case PINKeyReferenceValuePinAppl2:
+		return "pinAppl2"
+	case PINKeyReferenceValuePinAppl3:
+		return "pinAppl3"
+	case PINKeyReferenceValuePinAppl4:
+		return "pinAppl4"
+	case PINKeyReferenceValuePinAppl5:
+		return "pinAppl5"
+	case PINKeyReferenceValuePinAppl6:
+		return "pinAppl6"
+	case PINKeyReferenceValuePinAppl7:
+		return "pinAppl7"
+	case PINKeyReferenceValuePinAppl8:
+		return "pinAppl8"
+	case PINKeyReferenceValueAdm1:
+		return "adm1"
+	case PINKeyReferenceValueAdm2:
+		return "adm2"
+	case PINKeyReferenceValueAdm3:
+		return "adm3"
+	case PINKeyReferenceValueAdm4:
+		return "adm4"
+	case PINKeyReferenceValueAdm5:
+		return "adm5"
+	case PINKeyReferenceValueSecondPINAppl1:
+		return "secondPINAppl1"
+	case PINKeyReferenceValueSecondPINAppl2:
+		return "secondPINAppl2"
+	case PINKeyReferenceValueSecondPINAppl3:
+		return "secondPINAppl3"
+	case PINKeyReferenceValueSecondPINAppl4:
+		return "secondPINAppl4"
+	case PINKeyReferenceValueSecondPINAppl5:
+		return "secondPINAppl5"
+	case PINKeyReferenceValueSecondPINAppl6:
+		return "secondPINAppl6"
+	case PINKeyReferenceValueSecondPINAppl7:
+		return "secondPINAppl7"
+	case PINKeyReferenceValueSecondPINAppl8:
+		return "secondPINAppl8"
+	case PINKeyReferenceValueAdm6:
+		return "adm6"
+	case PINKeyReferenceValueAdm7:
+		return "adm7"
+	case PINKeyReferenceValueAdm8:
+		return "adm8"
+	case PINKeyReferenceValueAdm9:
+		return "adm9"
+	case PINKeyReferenceValueAdm10:
+		return "adm10"
+	}
+	return strconv.FormatInt(int64(v), 10)
+}
+
+// MarshalASN1 writes v as its identifier.
+func (v PINKeyReferenceValue) MarshalASN1() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalASN1 reads v from its identifier or its number.
+func (v *PINKeyReferenceValue) UnmarshalASN1(b []byte) error {
+	switch string(b) {
+	case "pinAppl1":
+		*v = PINKeyReferenceValuePinAppl1
+	case "pinAppl2":
+		*v = PINKeyReferenceValuePinAppl2
+	case "pinAppl3":
+		*v = PINKeyReferenceValuePinAppl3
+	case "pinAppl4":
+		*v = PINKeyReferenceValuePinAppl4
+	case "pinAppl5":
+		*v = PINKeyReferenceValuePinAppl5
+	case "pinAppl6":
+		*v = PINKeyReferenceValuePinAppl6
+	case "pinAppl7":
+		*v = PINKeyReferenceValuePinAppl7
+	case "pinAppl8":
+		*v = PINKeyReferenceValuePinAppl8
+	case "adm1":
+		*v = PINKeyReferenceValueAdm1
+	case "adm2":
+		*v = PINKeyReferenceValueAdm2
+	case "adm3":
+		*v = PINKeyReferenceValueAdm3
+	case "adm4":
+		*v = PINKeyReferenceValueAdm4
+	case "adm5":
+		*v = PINKeyReferenceValueAdm5
+	case "secondPINAppl1":
+		*v = PINKeyReferenceValueSecondPINAppl1
+	case "secondPINAppl2":
+		*v = PINKeyReferenceValueSecondPINAppl2
+	case "secondPINAppl3":
+		*v = PINKeyReferenceValueSecondPINAppl3
+	case "secondPINAppl4":
+		*v = PINKeyReferenceValueSecondPINAppl4
+	case "secondPINAppl5":
+		*v = PINKeyReferenceValueSecondPINAppl5
+	case "secondPINAppl6":
+		*v = PINKeyReferenceValueSecondPINAppl6
+	case "secondPINAppl7":
+		*v = PINKeyReferenceValueSecondPINAppl7
+	case "secondPINAppl8":
+		*v = PINKeyReferenceValueSecondPINAppl8
+	case "adm6":
+		*v = PINKeyReferenceValueAdm6
+	case "adm7":
+		*v = PINKeyReferenceValueAdm7
+	case "adm8":
+		*v = PINKeyReferenceValueAdm8
+	case "adm9":
+		*v = PINKeyReferenceValueAdm9
+	case "adm10":
+		*v = PINKeyReferenceValueAdm10
+	default:
+		n, err := strconv.ParseInt(string(b), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid PINKeyReferenceValue value %s", b)
+		}
+		*v = PINKeyReferenceValue(n)
+	}
+	return nil
+}
+
+// PINConfiguration is the ASN.1 type PINConfiguration, a SEQUENCE.
+type PINConfiguration struct {
+	KeyReference                PINKeyReferenceValue  `asn1:"keyReference,tag:0"`
+	PinValue                    []byte                `asn1:"pinValue,tag:1"`
+	UnblockingPINReference      *PUKKeyReferenceValue `asn1:"unblockingPINReference,tag:2"`
+	PinAttributes               UInt8                 `asn1:"pinAttributes,tag:3"`
+	MaxNumOfAttempsRetryNumLeft UInt8                 `asn1:"maxNumOfAttemps-retryNumLeft,tag:4"`
+	Extensions                  asn1go.Extensions     `asn1:"..."`
+}
+
+// PEPINCodes is the ASN.1 type PE-PINCodes, a SEQUENCE.
+type PEPINCodes struct {
+	PinHeader  PEHeader           `asn1:"pin-Header,tag:0"`
+	PinCodes   PEPINCodesPinCodes `asn1:"pinCodes,tag:1"`
+	Extensions asn1go.Extensions  `asn1:"..."`
+}
+
+// PUKKeyReferenceValue is the ASN.1 type PUKKeyReferenceValue, an INTEGER.
+type PUKKeyReferenceValue int64
+
+// Values of PUKKeyReferenceValue.
+const (
+	PUKKeyReferenceValuePukAppl1       PUKKeyReferenceValue = 1
+	PUKKeyReferenceValuePukAppl2       PUKKeyReferenceValue = 2
+	PUKKeyReferenceValuePukAppl3       PUKKeyReferenceValue = 3
+	PUKKeyReferenceValuePukAppl4       PUKKeyReferenceValue = 4
+	PUKKeyReferenceValuePukAppl5       PUKKeyReferenceValue = 5
+	PUKKeyReferenceValuePukAppl6       PUKKeyReferenceValue = 6
+	PUKKeyReferenceValuePukAppl7       PUKKeyReferenceValue = 7
+	PUKKeyReferenceValuePukAppl8       PUKKeyReferenceValue = 8
+	PUKKeyReferenceValueSecondPUKAppl1 PUKKeyReferenceValue = 129
+	PUKKeyReferenceValueSecondPUKAppl2 PUKKeyReferenceValue = 130
+	PUKKeyReferenceValueSecondPUKAppl3 PUKKeyReferenceValue = 131
+	PUKKeyReferenceValueSecondPUKAppl4 PUKKeyReferenceValue = 132
+	PUKKeyReferenceValueSecondPUKAppl5 PUKKeyReferenceValue = 133
+	PUKKeyReferenceValueSecondPUKAppl6 PUKKeyReferenceValue = 134
+	PUKKeyReferenceValueSecondPUKAppl7 PUKKeyReferenceValue = 135
+	PUKKeyReferenceValueSecondPUKAppl8 PUKKeyReferenceValue = 136
+)
+
+// String returns the identifier of v, or its number if it has none.
+func (v PUKKeyReferenceValue) String() string {
+	switch v {
+	case PUKKeyReferenceValuePukAppl1:
+		return "pukAppl1"
+	case PUKKeyReferenceValuePukAppl2:
+		return "pukAppl2"
+	case PUKKeyReferenceValuePukAppl3:
+		return "pukAppl3"
+	case PUKKeyReferenceValuePukAppl4:
+		return "pukAppl4"
+	case PUKKeyReferenceValuePukAppl5:
+		return "pukAppl5"
+	case PUKKeyReferenceValuePukAppl6:
+		return "pukAppl6"
+	case PUKKeyReferenceValuePukAppl7:
+		return "pukAppl7"
+	case PUKKeyReferenceValuePukAppl8:
+		return "pukAppl8"
+	case PUKKeyReferenceValueSecondPUKAppl1:
+		return "secondPUKAppl1"
+	case PUKKeyReferenceValueSecondPUKAppl2:
+		return "secondPUKAppl2"
+	case PUKKeyReferenceValueSecondPUKAppl3:
+		return "secondPUKAppl3"
+	case PUKKeyReferenceValueSecondPUKAppl4:
+		return "secondPUKAppl4"
+	case PUKKeyReferenceValueSecondPUKAppl5:
+		return "secondPUKAppl5"
+	case PUKKeyReferenceValueSecondPUKAppl6:
+		return "secondPUKAppl6"
+	case PUKKeyReferenceValueSecondPUKAppl7:
+		return "secondPUKAppl7"
+	case PUKKeyReferenceValueSecondPUKAppl8:
+		return "secondPUKAppl8"
+	}
+	return strconv.FormatInt(int64(v), 10)
+}
+
+// MarshalASN1 writes v as its identifier.
+func (v PUKKeyReferenceValue) MarshalASN1() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalASN1 reads v from its identifier or its number.
+func (v *PUKKeyReferenceValue) UnmarshalASN1(b []byte) error {
+	switch string(b) {
+	case "pukAppl1":
+		*v = PUKKeyReferenceValuePukAppl1
+	case "pukAppl2":
+		*v = PUKKeyReferenceValuePukAppl2
+	case "pukAppl3":
+		*v = PUKKeyReferenceValuePukAppl3
+	case "pukAppl4":
+		*v = PUKKeyReferenceValuePukAppl4
+	case "pukAppl5":
+		*v = PUKKeyReferenceValuePukAppl5
+	case "pukAppl6":
+		*v = PUKKeyReferenceValuePukAppl6
+	case "pukAppl7":
+		*v = PUKKeyReferenceValuePukAppl7
+	case "pukAppl8":
+		*v = PUKKeyReferenceValuePukAppl8
+	case "secondPUKAppl1":
+		*v = PUKKeyReferenceValueSecondPUKAppl1
+	case "secondPUKAppl2":
+		*v = PUKKeyReferenceValueSecondPUKAppl2
+	case "secondPUKAppl3":
+		*v = PUKKeyReferenceValueSecondPUKAppl3
+	case "secondPUKAppl4":
+		*v = PUKKeyReferenceValueSecondPUKAppl4
+	case "secondPUKAppl5":
+		*v = PUKKeyReferenceValueSecondPUKAppl5
+	case "secondPUKAppl6":
+		*v = PUKKeyReferenceValueSecondPUKAppl6
+	case "secondPUKAppl7":
+		*v = PUKKeyReferenceValueSecondPUKAppl7
+	case "secondPUKAppl8":
+		*v = PUKKeyReferenceValueSecondPUKAppl8
+	default:
+		n, err := strconv.ParseInt(string(b), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid PUKKeyReferenceValue value %s", b)
+		}
+		*v = PUKKeyReferenceValue(n)
+	}
+	return nil
+}
+
+// PUKConfiguration is the ASN.1 type PUKConfiguration, a SEQUENCE.
+type PUKConfiguration struct {
+	KeyReference                PUKKeyReferenceValue `asn1:"keyReference,tag:0"`
+	PukValue                    []byte               `asn1:"pukValue,tag:1"`
+	MaxNumOfAttempsRetryNumLeft UInt8                `asn1:"maxNumOfAttemps-retryNumLeft,tag:2"`
+	Extensions                  asn1go.Extensions    `asn1:"..."`
+}
+
+// PEPUKCodes is the ASN.1 type PE-PUKCodes, a SEQUENCE.
+type PEPUKCodes struct {
+	PukHeader  PEHeader           `asn1:"puk-Header,tag:0"`
+	PukCodes   []PUKConfiguration `asn1:"pukCodes,tag:1"`
+	Extensions asn1go.Extensions  `asn1:"..."`
+}
+
+// PEAKAParameter is the ASN.1 type PE-AKAParameter, a SEQUENCE.
+type PEAKAParameter struct {
+	AkaHeader         PEHeader          `asn1:"aka-header,tag:0"`
+	AlgoConfiguration AlgoConfiguration `asn1:"algoConfiguration,tag:1"`
+	SqnOptions        []byte            `asn1:"sqnOptions,tag:2,omitempty"`
+	SqnDelta          []byte            `asn1:"sqnDelta,tag:3,omitempty"`
+	SqnAgeLimit       []byte            `asn1:"sqnAgeLimit,tag:4,omitempty"`
+	SqnInit           [][]byte          `asn1:"sqnInit,tag:5,omitempty"`
+	Extensions        asn1go.Extensions `asn1:"..."`
+}
+
+// AlgoConfiguration is the ASN.1 type AlgoConfiguration, a CHOICE.
+type AlgoConfiguration struct {
+	MappingParameter *MappingParameter `asn1:"mappingParameter,choice,tag:0"`
+	AlgoParameter    *AlgoParameter    `asn1:"algoParameter,choice,tag:1"`
+	Extensions       asn1go.Extensions `asn1:"...,choice"`
+}
+
+// MappingParameter is the ASN.1 type MappingParameter, a SEQUENCE.
+type MappingParameter struct {
+	MappingOptions []byte                `asn1:"mappingOptions,tag:0"`
+	MappingSource  ApplicationIdentifier `asn1:"mappingSource,tag:1"`
+	Extensions     asn1go.Extensions     `asn1:"..."`
+}
+
+// AlgoParameter is the ASN.1 type AlgoParameter, a SEQUENCE.
+type AlgoParameter struct {
+	AlgorithmID       AlgoParameterAlgorithmID `asn1:"algorithmID,tag:0"`
+	AlgorithmOptions  []byte                   `asn1:"algorithmOptions,tag:1"`
+	Key               []byte                   `asn1:"key,tag:2"`
+	Opc               []byte                   `asn1:"opc,tag:3"`
+	RotationConstants []byte                   `asn1:"rotationConstants,tag:4,omitempty"`
+	XoringConstants   []byte                   `asn1:"xoringConstants,tag:5,omitempty"`
+	NumberOfKeccak    UInt8                    `asn1:"numberOfKeccak,tag:6"`
+	Extensions        asn1go.Extensions        `asn1:"..."`
+}
+
+// PECDMAParameter is the ASN.1 type PE-CDMAParameter, a SEQUENCE.
+type PECDMAParameter struct {
+	CdmaHeader                   PEHeader          `asn1:"cdma-header,tag:0"`
+	AuthenticationKey            []byte            `asn1:"authenticationKey,tag:1"`
+	Ssd                          []byte            `asn1:"ssd,tag:2,omitempty"`
+	HrpdAccessAuthenticationData []byte            `asn1:"hrpdAccessAuthenticationData,tag:3,omitempty"`
+	SimpleIPAuthenticationData   []byte            `asn1:"simpleIPAuthenticationData,tag:4,omitempty"`
+	MobileIPAuthenticationData   []byte            `asn1:"mobileIPAuthenticationData,tag:5,omitempty"`
+	Extensions                   asn1go.Extensions `asn1:"..."`
+}
+
+// PESecurityDomain is the ASN.1 type PE-SecurityDomain, a SEQUENCE.
+type PESecurityDomain struct {
+	SdHeader        PEHeader                         `asn1:"sd-Header,tag:0"`
+	Instance        ApplicationInstance              `asn1:"instance,tag:1"`
+	KeyList         []KeyObject                      `asn1:"keyList,tag:2,omitempty"`
+	SdPersoData     [][]byte                         `asn1:"sdPersoData,tag:3,omitempty"`
+	OpenPersoData   *PESecurityDomainOpenPersoData   `asn1:"openPersoData,tag:4"`
+	CatTpParameters *PESecurityDomainCatTpParameters `asn1:"catTpParameters,tag:5"`
+	Extensions      asn1go.Extensions                `asn1:"..."`
+}
+
+// KeyObject is the ASN.1 type KeyObject, a SEQUENCE.
+type KeyObject struct {
+	KeyUsageQualifier []byte                        `asn1:"keyUsageQualifier,tag:21"`
+	KeyAccess         []byte                        `asn1:"keyAccess,tag:22,omitempty"`
+	KeyIdentifier     []byte                        `asn1:"keyIdentifier,tag:2"`
+	KeyVersionNumber  []byte                        `asn1:"keyVersionNumber,tag:3"`
+	KeyCounterValue   []byte                        `asn1:"keyCounterValue,tag:5,omitempty"`
+	KeyCompontents    []KeyObjectKeyCompontentsItem `asn1:"keyCompontents"`
+	Extensions        asn1go.Extensions             `asn1:"..."`
+}
+
+// ApplicationInstance is the ASN.1 type ApplicationInstance, a SEQUENCE.
+type ApplicationInstance struct {
+	ApplicationLoadPackageAID       ApplicationIdentifier        `asn1:"applicationLoadPackageAID,tag:15,application"`
+	ClassAID                        ApplicationIdentifier        `asn1:"classAID,tag:15,application"`
+	InstanceAID                     ApplicationIdentifier        `asn1:"instanceAID,tag:15,application"`
+	ExtraditeSecurityDomainAID      *ApplicationIdentifier       `asn1:"extraditeSecurityDomainAID,tag:15,application"`
+	ApplicationPrivileges           []byte                       `asn1:"applicationPrivileges,tag:2"`
+	LifeCycleState                  []byte                       `asn1:"lifeCycleState,tag:3,omitempty"`
+	ApplicationSpecificParametersC9 []byte                       `asn1:"applicationSpecificParametersC9,tag:9,private"`
+	SystemSpecificParameters        *ApplicationSystemParameters `asn1:"systemSpecificParameters,tag:15,private"`
+	ApplicationParameters           *UICCApplicationParameters   `asn1:"applicationParameters,tag:10,private"`
+	ProcessData                     [][]byte                     `asn1:"processData,omitempty"`
+	ControlReferenceTemplate        []byte                       `asn1:"controlReferenceTemplate,tag:2,private,omitempty"`
+	Extensions                      asn1go.Extensions            `asn1:"..."`
+}
+
+// ApplicationSystemParameters is the ASN.1 type ApplicationSystemParameters, a SEQUENCE.
+type ApplicationSystemParameters struct {
+	VolatileMemoryQuotaC7                   []byte                                   `asn1:"volatileMemoryQuotaC7,tag:7,private,omitempty"`
+	NonVolatileMemoryQuotaC8                []byte                                   `asn1:"nonVolatileMemoryQuotaC8,tag:8,private,omitempty"`
+	GlobalServiceParameters                 []byte                                   `asn1:"globalServiceParameters,tag:11,private,omitempty"`
+	ImplicitSelectionParameter              []byte                                   `asn1:"implicitSelectionParameter,tag:15,private,omitempty"`
+	VolatileReservedMemory                  []byte                                   `asn1:"volatileReservedMemory,tag:23,private,omitempty"`
+	NonVolatileReservedMemory               []byte                                   `asn1:"nonVolatileReservedMemory,tag:24,private,omitempty"`
+	Ts102226SIMFileAccessToolkitParameter   []byte                                   `asn1:"ts102226SIMFileAccessToolkitParameter,tag:26,private,omitempty"`
+	Ts102226AdditionalContactlessParameters *TS102226AdditionalContactlessParameters `asn1:"ts102226AdditionalContactlessParameters,tag:0"`
+	ContactlessProtocolParameters           []byte                                   `asn1:"contactlessProtocolParameters,tag:25,private,omitempty"`
+	UserInteractionContactlessParameters    []byte                                   `asn1:"userInteractionContactlessParameters,tag:29,private,omitempty"`
+	CumulativeGrantedVolatileMemory         []byte                                   `asn1:"cumulativeGrantedVolatileMemory,tag:30,private,omitempty"`
+	CumulativeGrantedNonVolatileMemory      []byte                                   `asn1:"cumulativeGrantedNonVolatileMemory,tag:31,private,omitempty"`
+	Extensions                              asn1go.Extensions                        `asn1:"..."`
+}
+
+// TS102226AdditionalContactlessParameters is the ASN.1 type TS102226AdditionalContactlessParameters, a SEQUENCE.
+type TS102226AdditionalContactlessParameters struct {
+	ProtocolParameterData []byte            `asn1:"protocolParameterData,tag:0"`
+	Extensions            asn1go.Extensions `asn1:"..."`
+}
+
+// UICCApplicationParameters is the ASN.1 type UICCApplicationParameters, a SEQUENCE.
+type UICCApplicationParameters struct {
+	UiccToolkitApplicationSpecificParametersField              []byte            `asn1:"uiccToolkitApplicationSpecificParametersField,tag:0,omitempty"`
+	UiccAccessApplicationSpecificParametersField               []byte            `asn1:"uiccAccessApplicationSpecificParametersField,tag:1,omitempty"`
+	UiccAdministrativeAccessApplicationSpecificParametersField []byte            `asn1:"uiccAdministrativeAccessApplicationSpecificParametersField,tag:2,omitempty"`
+	Extensions                                                 asn1go.Extensions `asn1:"..."`
+}
+
+// PERFM is the ASN.1 type PE-RFM, a SEQUENCE.
+type PERFM struct {
+	RfmHeader             PEHeader               `asn1:"rfm-header"`
+	InstanceAID           ApplicationIdentifier  `asn1:"instanceAID,tag:15,application"`
+	SecurityDomainAID     *ApplicationIdentifier `asn1:"securityDomainAID,tag:15,application"`
+	TarList               [][]byte               `asn1:"tarList,tag:0,omitempty"`
+	MinimumSecurityLevel  []byte                 `asn1:"minimumSecurityLevel,tag:1"`
+	UiccAccessDomain      []byte                 `asn1:"uiccAccessDomain"`
+	UiccAdminAccessDomain []byte                 `asn1:"uiccAdminAccessDomain"`
+	AdfRFMAccess          *ADFRFMAccess          `asn1:"adfRFMAccess"`
+	Extensions            asn1go.Extensions      `asn1:"..."`
+}
+
+// ADFRFMAccess is the ASN.1 type ADFRFMAccess, a SEQUENCE.
+type ADFRFMAccess struct {
+	AdfAID               ApplicationIdentifier `asn1:"adfAID,tag:0"`
+	AdfAccessDomain      []byte                `asn1:"adfAccessDomain,tag:1"`
+	AdfAdminAccessDomain []byte                `asn1:"adfAdminAccessDomain,tag:2"`
+	Extensions           asn1go.Extensions     `asn1:"..."`
+}
+
+// PEApplication is the ASN.1 type PE-Application, a SEQUENCE.
+type PEApplication struct {
+	AppHeader    PEHeader                `asn1:"app-Header,tag:0"`
+	LoadBlock    *ApplicationLoadPackage `asn1:"loadBlock,tag:1"`
+	InstanceList []ApplicationInstance   `asn1:"instanceList,tag:2,omitempty"`
+	Extensions   asn1go.Extensions       `asn1:"..."`
+}
+
+// ApplicationLoadPackage is the ASN.1 type ApplicationLoadPackage, a SEQUENCE.
+type ApplicationLoadPackage struct {
+	LoadPackageAID         ApplicationIdentifier  `asn1:"loadPackageAID,tag:15,application"`
+	SecurityDomainAID      *ApplicationIdentifier `asn1:"securityDomainAID,tag:15,application"`
+	NonVolatileCodeLimitC6 []byte                 `asn1:"nonVolatileCodeLimitC6,tag:6,private,omitempty"`
+	VolatileDataLimitC7    []byte                 `asn1:"volatileDataLimitC7,tag:7,private,omitempty"`
+	NonVolatileDataLimitC8 []byte                 `asn1:"nonVolatileDataLimitC8,tag:8,private,omitempty"`
+	HashValue              []byte                 `asn1:"hashValue,tag:1,private,omitempty"`
+	LoadBlockObject        []byte                 `asn1:"loadBlockObject,tag:4,private"`
+	Extensions             asn1go.Extensions      `asn1:"..."`
+}
+
+// PENonStandard is the ASN.1 type PE-NonStandard, a SEQUENCE.
+type PENonStandard struct {
+	NonStandardHeader PEHeader                `asn1:"nonStandard-header,tag:0"`
+	IssuerID          asn1go.ObjectIdentifier `asn1:"issuerID,tag:1"`
+	Content           []byte                  `asn1:"content,tag:2"`
+	Extensions        asn1go.Extensions       `asn1:"..."`
+}
+
+// PEEnd is the ASN.1 type PE-End, a SEQUENCE.
+type PEEnd struct {
+	EndHeader  PEHeader          `asn1:"end-header,tag:0"`
+	Extensions asn1go.Extensions `asn1:"..."`
+}
+
+// ProfileHeaderEUICCMandatoryAIDsItem is the type of the elements of ProfileHeaderEUICCMandatoryAIDs.
+type ProfileHeaderEUICCMandatoryAIDsItem struct {
+	Aid        ApplicationIdentifier `asn1:"aid,tag:0"`
+	Version    []byte                `asn1:"version,tag:1"`
+	Extensions asn1go.Extensions     `asn1:"..."`
+}
+
+// FileItem is the type of the elements of File.
+type FileItem struct {
+	DoNotCreate     *struct{}         `asn1:"doNotCreate,choice,tag:0"`
+	FileDescriptor  *Fcp              `asn1:"fileDescriptor,choice,tag:1"`
+	FillFileOffset  *UInt16           `asn1:"fillFileOffset,choice,tag:2"`
+	FillFileContent []byte            `asn1:"fillFileContent,choice,tag:3"`
+	Extensions      asn1go.Extensions `asn1:"...,choice"`
+}
+
+// FileManagementItem is the type of the elements of FileManagement.
+type FileManagementItem struct {
+	FilePath        []byte            `asn1:"filePath,choice,tag:0"`
+	CreateFCP       *Fcp              `asn1:"createFCP,choice,tag:2,application"`
+	FillFileContent []byte            `asn1:"fillFileContent,choice,tag:1"`
+	FillFileOffset  *UInt16           `asn1:"fillFileOffset,choice"`
+	Extensions      asn1go.Extensions `asn1:"...,choice"`
+}
+
+// PEPINCodesPinCodes is the type of the pinCodes component of PEPINCodes.
+type PEPINCodesPinCodes struct {
+	Pinconfig  []PINConfiguration `asn1:"pinconfig,choice,tag:0"`
+	FilePath   []byte             `asn1:"filePath,choice,tag:1"`
+	Extensions asn1go.Extensions  `asn1:"...,choice"`
+}
+
+// AlgoParameterAlgorithmID is the type of the algorithmID component of AlgoParameter.
+type AlgoParameterAlgorithmID int64
+
+// Values of AlgoParameterAlgorithmID.
+const (
+	AlgoParameterAlgorithmIDMilenage          AlgoParameterAlgorithmID = 1
+	AlgoParameterAlgorithmIDTuak              AlgoParameterAlgorithmID = 2
+	AlgoParameterAlgorithmIDUsimTestAlgorithm AlgoParameterAlgorithmID = 3
+)
+
+// String returns the identifier of v, or its number if it has none.
+func (v AlgoParameterAlgorithmID) String() string {
+	switch v {
+	case AlgoParameterAlgorithmIDMilenage:
+		return "milenage"
+	case AlgoParameterAlgorithmIDTuak:
+		return "tuak"
+	case AlgoParameterAlgorithmIDUsimTestAlgorithm:
+		return "usim-test-algorithm"
+	}
+	return strconv.FormatInt(int64(v), 10)
+}
+
+// MarshalASN1 writes v as its identifier.
+func (v AlgoParameterAlgorithmID) MarshalASN1() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalASN1 reads v from its identifier or its number.
+func (v *AlgoParameterAlgorithmID) UnmarshalASN1(b []byte) error {
+	switch string(b) {
+	case "milenage":
+		*v = AlgoParameterAlgorithmIDMilenage
+	case "tuak":
+		*v = AlgoParameterAlgorithmIDTuak
+	case "usim-test-algorithm":
+		*v = AlgoParameterAlgorithmIDUsimTestAlgorithm
+	default:
+		n, err := strconv.ParseInt(string(b), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid AlgoParameterAlgorithmID value %s", b)
+		}
+		*v = AlgoParameterAlgorithmID(n)
+	}
+	return nil
+}
+
+// PESecurityDomainOpenPersoData is the type of the openPersoData component of PESecurityDomain.
+type PESecurityDomainOpenPersoData struct {
+	RestrictParameter             []byte            `asn1:"restrictParameter,tag:31,private,omitempty"`
+	ContactlessProtocolParameters []byte            `asn1:"contactlessProtocolParameters,tag:25,private,omitempty"`
+	Extensions                    asn1go.Extensions `asn1:"..."`
+}
+
+// PESecurityDomainCatTpParameters is the type of the catTpParameters component of PESecurityDomain.
+type PESecurityDomainCatTpParameters struct {
+	CatTpMaxSduSize UInt16            `asn1:"catTpMaxSduSize,tag:0"`
+	CatTpMaxPduSize UInt16            `asn1:"catTpMaxPduSize,tag:1"`
+	Extensions      asn1go.Extensions `asn1:"..."`
+}
+
+// KeyObjectKeyCompontentsItem is the type of the elements of KeyObjectKeyCompontents.
+type KeyObjectKeyCompontentsItem struct {
+	KeyType    []byte            `asn1:"keyType,tag:0"`
+	KeyData    []byte            `asn1:"keyData,tag:6"`
+	MacLength  UInt8             `asn1:"macLength"`
+	Extensions asn1go.Extensions `asn1:"..."`
+}
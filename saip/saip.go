@@ -0,0 +1,151 @@
+// Package saip reads and writes eUICC profile packages, the profiles that
+// GSMA SGP.22 remote SIM provisioning loads onto an eUICC, in the
+// interoperable format of the eUICC Profile Package Technical
+// Specification.
+//
+// A profile package is a sequence of profile elements, starting with a
+// ProfileHeader and ending with a PEEnd. The Go types of the profile
+// elements, such as ProfileElement, PEMF, PEGenericFileManagement,
+// PEPINCodes and PEPUKCodes, are generated by asn1go-gen from the
+// PEDefinitions module of the specification, which Module returns for use
+// with the schema-driven encoding rules of asn1go:
+//
+//	pes, err := saip.UnmarshalProfile(data)
+//	if err != nil {
+//		return err
+//	}
+//	for _, pe := range pes {
+//		if h := pe.Header; h != nil {
+//			fmt.Println("ICCID", saip.ICCID(h.Iccid))
+//		}
+//	}
+//
+// ProfilePackage assembles new profile packages from their elements,
+// checking their order and numbering them.
+//
+// The module declares EXTENSIBILITY IMPLIED, so that profile elements and
+// components added by later versions of the specification are kept as
+// asn1go.Extensions, which MarshalProfile writes back.
+package saip
+
+import (
+	_ "embed"
+	"strconv"
+	"sync"
+
+	"github.com/openesim/asn1go"
+)
+
+//go:generate go run github.com/openesim/asn1go/cmd/asn1go-gen -o pe.go PEDefinitions.asn
+
+//go:embed PEDefinitions.asn
+var definitions []byte
+
+var (
+	moduleOnce sync.Once
+	module     *asn1go.Module
+)
+
+// Module returns the PEDefinitions module that the types of the package
+// are generated from. It is shared and must not be modified.
+func Module() *asn1go.Module {
+	moduleOnce.Do(func() {
+		m, err := asn1go.ParseModule(definitions)
+		if err != nil {
+			panic("saip: " + err.Error())
+		}
+		module = m
+	})
+	return module
+}
+
+// UnmarshalProfile parses the profile package data, the DER encodings of
+// its profile elements one after the other, and returns the profile
+// elements.
+//
+// An element that cannot be decoded is reported as a ProfileError holding
+// the error of asn1go.DecodeDER, whose offsets are relative to the start
+// of the element.
+func UnmarshalProfile(data []byte) ([]ProfileElement, error) {
+	t := Module().Type("ProfileElement")
+	var pes []ProfileElement
+	for off := 0; off < len(data); {
+		n, err := elementLength(data[off:])
+		if err == nil {
+			var pe ProfileElement
+			if err = asn1go.DecodeDER(t, data[off:off+n], &pe); err == nil {
+				pes = append(pes, pe)
+				off += n
+				continue
+			}
+		}
+		return nil, &ProfileError{Index: len(pes), Offset: int64(off), Err: err}
+	}
+	return pes, nil
+}
+
+// MarshalProfile returns the profile package of the profile elements pes:
+// their DER encodings one after the other.
+func MarshalProfile(pes []ProfileElement) ([]byte, error) {
+	t := Module().Type("ProfileElement")
+	var data []byte
+	for i := range pes {
+		b, err := asn1go.EncodeDER(t, &pes[i])
+		if err != nil {
+			return nil, &ProfileError{Index: i, Offset: int64(len(data)), Err: err}
+		}
+		data = append(data, b...)
+	}
+	return data, nil
+}
+
+// A ProfileError describes a profile element that cannot be decoded or
+// encoded.
+type ProfileError struct {
+	Index  int   // index of the element in the profile package
+	Offset int64 // offset of the element in the profile package
+	Err    error
+}
+
+func (e *ProfileError) Error() string {
+	return "saip: profile element " + strconv.Itoa(e.Index) + " at offset " + strconv.FormatInt(e.Offset, 10) + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ProfileError) Unwrap() error { return e.Err }
+
+// elementLength returns the length of the DER encoding at the start of b,
+// as its identifier and length octets give it.
+func elementLength(b []byte) (int, error) {
+	n := 1
+	if len(b) > 0 && b[0]&0x1f == 0x1f {
+		// A tag number of more than 30 follows in base 128.
+		for n < len(b) && b[n]&0x80 != 0 {
+			n++
+		}
+		n++
+	}
+	if n >= len(b) {
+		return 0, &asn1go.DERSyntaxError{Msg: "truncated header", Offset: int64(len(b))}
+	}
+	l := int(b[n])
+	n++
+	if l&0x80 != 0 {
+		k := l & 0x7f
+		if k == 0 || k > 4 {
+			return 0, &asn1go.DERSyntaxError{Msg: "invalid length", Offset: int64(n - 1)}
+		}
+		if n+k > len(b) {
+			return 0, &asn1go.DERSyntaxError{Msg: "truncated length", Offset: int64(n)}
+		}
+		l = 0
+		for _, c := range b[n : n+k] {
+			l = l<<8 | int(c)
+		}
+		n += k
+	}
+	if l > len(b)-n {
+		return 0, &asn1go.DERSyntaxError{Msg: "truncated encoding", Offset: int64(len(b))}
+	}
+	return n + l, nil
+}
@@ -0,0 +1,134 @@
+package saip
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/openesim/asn1go"
+)
+
+// testElements returns a small profile package for the tests.
+func testElements() []ProfileElement {
+	return []ProfileElement{
+		{Header: &ProfileHeader{
+			MajorVersion: 2, MinorVersion: 3,
+			Iccid:                   []byte{0x98, 0x10, 0x32, 0x54, 0x76, 0x98, 0x10, 0x32, 0x54, 0xF6},
+			EUICCMandatoryServices:  ServicesList{Usim: &struct{}{}, Milenage: &struct{}{}},
+			EUICCMandatoryGFSTEList: []asn1go.ObjectIdentifier{{2, 23, 143, 1, 2, 1}},
+		}},
+		{Mf: &PEMF{
+			MfHeader:   PEHeader{Identification: 1},
+			TemplateID: asn1go.ObjectIdentifier{2, 23, 143, 1, 2, 1},
+			Mf:         File{{FileDescriptor: &Fcp{PinStatusTemplateDO: []byte{1, 2, 10, 11}}}},
+			EfIccid:    File{{FillFileContent: []byte{0x98, 0x00}}},
+			EfArr:      File{{DoNotCreate: &struct{}{}}},
+		}},
+		{PinCodes: &PEPINCodes{PinHeader: PEHeader{Identification: 2}, PinCodes: PEPINCodesPinCodes{
+			Pinconfig: []PINConfiguration{{KeyReference: PINKeyReferenceValuePinAppl1, PinValue: []byte("0000\xff\xff\xff\xff"), PinAttributes: 7, MaxNumOfAttempsRetryNumLeft: 51}},
+		}}},
+		{End: &PEEnd{EndHeader: PEHeader{Identification: 3}}},
+	}
+}
+
+func TestModule(t *testing.T) {
+	m := Module()
+	if m != Module() {
+		t.Error("Module returns a new module each time")
+	}
+	for _, name := range []string{"ProfileElement", "ProfileHeader", "PE-MF", "PE-End", "PEHeader"} {
+		if m.Type(name) == nil {
+			t.Errorf("Module has no type %s", name)
+		}
+	}
+}
+
+func TestProfileRoundTrip(t *testing.T) {
+	pes := testElements()
+	tests := [][]ProfileElement{
+		nil,
+		pes[:1],
+		pes,
+		// An element of a later version of the specification.
+		append(testElements(), ProfileElement{Extensions: [][]byte{{0xBF, 0x3F, 0x03, 0x02, 0x01, 0x01}}}),
+	}
+	for _, pes := range tests {
+		data, err := MarshalProfile(pes)
+		if err != nil {
+			t.Errorf("MarshalProfile(%d elements): %v", len(pes), err)
+			continue
+		}
+		back, err := UnmarshalProfile(data)
+		if err != nil {
+			t.Errorf("UnmarshalProfile(% X): %v", data, err)
+			continue
+		}
+		if len(back) != len(pes) {
+			t.Errorf("UnmarshalProfile(% X) = %d elements, want %d", data, len(back), len(pes))
+			continue
+		}
+		again, err := MarshalProfile(back)
+		if err != nil || !bytes.Equal(again, data) {
+			t.Errorf("MarshalProfile(UnmarshalProfile(% X)) = % X, %v", data, again, err)
+		}
+	}
+
+	back, err := UnmarshalProfile(mustMarshal(t, pes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back[0].Header.EUICCMandatoryServices, pes[0].Header.EUICCMandatoryServices) {
+		t.Errorf("services = %+v, want %+v", back[0].Header.EUICCMandatoryServices, pes[0].Header.EUICCMandatoryServices)
+	}
+	if got := back[2].PinCodes.PinCodes.Pinconfig[0].KeyReference; got != PINKeyReferenceValuePinAppl1 {
+		t.Errorf("key reference = %v, want %v", got, PINKeyReferenceValuePinAppl1)
+	}
+}
+
+func mustMarshal(t *testing.T, pes []ProfileElement) []byte {
+	t.Helper()
+	data, err := MarshalProfile(pes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestUnmarshalProfileError(t *testing.T) {
+	valid := mustMarshal(t, testElements())
+	tests := []struct {
+		data   []byte
+		index  int
+		offset int64
+	}{
+		{[]byte{0xA0}, 0, 0},
+		{[]byte{0xBF, 0x3F}, 0, 0},
+		{[]byte{0xA0, 0x85, 0, 0, 0, 0, 1}, 0, 0},
+		{[]byte{0xA0, 0x82, 0x01}, 0, 0},
+		{append(valid[:len(valid):len(valid)], 0xA0, 0x05, 0x01), 4, int64(len(valid))},
+		{append(valid[:len(valid):len(valid)], 0xA0, 0x01, 0x01), 4, int64(len(valid))},
+	}
+	for _, tt := range tests {
+		_, err := UnmarshalProfile(tt.data)
+		var pe *ProfileError
+		if !errors.As(err, &pe) || pe.Index != tt.index || pe.Offset != tt.offset {
+			t.Errorf("UnmarshalProfile(% .12X): error %v, want ProfileError of element %d at %d", tt.data, err, tt.index, tt.offset)
+			continue
+		}
+		var se *asn1go.DERSyntaxError
+		if !errors.As(err, &se) {
+			t.Errorf("UnmarshalProfile(% .12X): error %v does not wrap a DERSyntaxError", tt.data, err)
+		}
+	}
+}
+
+func TestMarshalProfileError(t *testing.T) {
+	pes := testElements()
+	pes[2] = ProfileElement{}
+	_, err := MarshalProfile(pes)
+	var pe *ProfileError
+	if !errors.As(err, &pe) || pe.Index != 2 || pe.Offset != int64(len(mustMarshal(t, pes[:2]))) {
+		t.Errorf("MarshalProfile with an empty element: error %v, want ProfileError of element 2", err)
+	}
+}
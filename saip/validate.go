@@ -0,0 +1,217 @@
+package saip
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PackageErrors is a list of problems of a profile package found by
+// Validate, in the order of the elements.
+type PackageErrors []*PackageError
+
+func (e PackageErrors) Error() string {
+	switch len(e) {
+	case 0:
+		return "no errors"
+	case 1:
+		return e[0].Error()
+	}
+	return e[0].Error() + " (and " + strconv.Itoa(len(e)-1) + " more errors)"
+}
+
+// Validate checks the package as Check does, and if it is well formed,
+// also checks that its elements are consistent with each other:
+//
+//   - The identification in the PEHeader of each element is its position
+//     in the package, as Number sets it, so that no two elements share
+//     one.
+//   - The usim, isim, csim and eap services are among the mandatory
+//     services of the profile header if and only if the package has an
+//     element of that kind, multiple-usim, multiple-isim and
+//     multiple-csim if it has several, and the milenage, tuak128 or
+//     tuak256 and usim-test-algorithm services if an akaParameter element
+//     uses that algorithm.
+//   - The fillFileContent after a file descriptor or createFCP with an
+//     efFileSize, at the offsets fillFileOffset moves to, fits the size
+//     of the file.
+//
+// It returns all the problems found as PackageErrors, or nil.
+func (p *ProfilePackage) Validate() error {
+	if err := p.Check(); err != nil {
+		return PackageErrors{err.(*PackageError)}
+	}
+	var errs PackageErrors
+	report := func(i int, format string, args ...interface{}) {
+		errs = append(errs, &PackageError{Index: i, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	first := make(map[UInt15]int) // index of the first element with an identification
+	for i := range p.Elements {
+		h := peHeader(&p.Elements[i])
+		if h == nil {
+			continue
+		}
+		if j, ok := first[h.Identification]; ok {
+			report(i, "repeats the identification %d of element %d", h.Identification, j)
+			continue
+		}
+		first[h.Identification] = i
+		if int(h.Identification) != i {
+			report(i, "has identification %d, want %d", h.Identification, i)
+		}
+	}
+
+	p.validateServices(report)
+
+	for i := range p.Elements {
+		alt, _ := alternative(&p.Elements[i])
+		if alt.Kind() != reflect.Pointer || alt.Elem().Kind() != reflect.Struct {
+			continue
+		}
+		s := alt.Elem()
+		for j := 0; j < s.NumField(); j++ {
+			name := fieldName(s.Type().Field(j))
+			switch f := s.Field(j).Interface().(type) {
+			case File:
+				validateFile(i, name, f, report)
+			case *File:
+				if f != nil {
+					validateFile(i, name, *f, report)
+				}
+			case []FileManagement:
+				for k, fm := range f {
+					validateFileManagement(i, name+"["+strconv.Itoa(k)+"]", fm, report)
+				}
+			}
+		}
+	}
+
+	if errs == nil {
+		return nil
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Index < errs[j].Index })
+	return errs
+}
+
+// validateServices checks the mandatory services of the profile header of
+// p against its elements.
+func (p *ProfilePackage) validateServices(report func(int, string, ...interface{})) {
+	services := &p.Elements[0].Header.EUICCMandatoryServices
+	count := make(map[string]int)
+	algorithms := make(map[AlgoParameterAlgorithmID]bool)
+	for i := range p.Elements {
+		pe := &p.Elements[i]
+		switch {
+		case pe.Usim != nil:
+			count["usim"]++
+		case pe.Isim != nil:
+			count["isim"]++
+		case pe.Csim != nil:
+			count["csim"]++
+		case pe.Eap != nil:
+			count["eap"]++
+		case pe.AkaParameter != nil:
+			if ap := pe.AkaParameter.AlgoConfiguration.AlgoParameter; ap != nil {
+				algorithms[ap.AlgorithmID] = true
+			}
+		}
+	}
+
+	check := func(service string, listed, present bool) {
+		switch {
+		case listed && !present:
+			report(0, "lists the mandatory service %s, which no element needs", service)
+		case !listed && present:
+			report(0, "does not list the mandatory service %s", service)
+		}
+	}
+	check("usim", services.Usim != nil, count["usim"] > 0)
+	check("isim", services.Isim != nil, count["isim"] > 0)
+	check("csim", services.Csim != nil, count["csim"] > 0)
+	check("eap", services.Eap != nil, count["eap"] > 0)
+	check("multiple-usim", services.MultipleUsim != nil, count["usim"] > 1)
+	check("multiple-isim", services.MultipleIsim != nil, count["isim"] > 1)
+	check("multiple-csim", services.MultipleCsim != nil, count["csim"] > 1)
+
+	// An algorithm can be mandated for elements other than akaParameter,
+	// so only missing ones are reported.
+	if algorithms[AlgoParameterAlgorithmIDMilenage] && services.Milenage == nil {
+		report(0, "does not list the mandatory service milenage")
+	}
+	if algorithms[AlgoParameterAlgorithmIDTuak] && services.Tuak128 == nil && services.Tuak256 == nil {
+		report(0, "does not list the mandatory service tuak128 or tuak256")
+	}
+	if algorithms[AlgoParameterAlgorithmIDUsimTestAlgorithm] && services.UsimTestAlgorithm == nil {
+		report(0, "does not list the mandatory service usim-test-algorithm")
+	}
+}
+
+// validateFile checks that the contents of the file name of element i fit
+// its size.
+func validateFile(i int, name string, f File, report func(int, string, ...interface{})) {
+	var fw fileWriter
+	for _, item := range f {
+		switch {
+		case item.FileDescriptor != nil:
+			fw.create(item.FileDescriptor)
+		case item.FillFileOffset != nil:
+			fw.off += int(*item.FillFileOffset)
+		case item.FillFileContent != nil:
+			fw.fill(i, name, len(item.FillFileContent), report)
+		}
+	}
+}
+
+// validateFileManagement checks that the contents of the files the
+// commands fm of element i create fit their sizes.
+func validateFileManagement(i int, name string, fm FileManagement, report func(int, string, ...interface{})) {
+	var fw fileWriter
+	for _, cmd := range fm {
+		switch {
+		case cmd.FilePath != nil:
+			// A file selected by path has a size the package does not
+			// give.
+			fw = fileWriter{}
+		case cmd.CreateFCP != nil:
+			fw.create(cmd.CreateFCP)
+		case cmd.FillFileOffset != nil:
+			fw.off += int(*cmd.FillFileOffset)
+		case cmd.FillFileContent != nil:
+			fw.fill(i, name, len(cmd.FillFileContent), report)
+		}
+	}
+}
+
+// A fileWriter follows the writes of contents to a file.
+type fileWriter struct {
+	sized bool // the size of the file is known
+	size  int
+	off   int // offset of the next write
+}
+
+// create starts a file with the file control parameters fcp.
+func (fw *fileWriter) create(fcp *Fcp) {
+	*fw = fileWriter{sized: fcp.EfFileSize != nil}
+	for _, c := range fcp.EfFileSize {
+		fw.size = fw.size<<8 | int(c)
+	}
+}
+
+// fill writes n bytes of the file name of element i, reporting a write
+// beyond the size of the file.
+func (fw *fileWriter) fill(i int, name string, n int, report func(int, string, ...interface{})) {
+	if fw.sized && fw.off+n > fw.size {
+		report(i, "writes %s up to offset %d, beyond its efFileSize %d", name, fw.off+n, fw.size)
+	}
+	fw.off += n
+}
+
+// fieldName returns the ASN.1 identifier of the struct field f, from its
+// asn1 tag.
+func fieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("asn1"), ",")
+	return name
+}
@@ -0,0 +1,120 @@
+package saip
+
+import (
+	"errors"
+	"testing"
+)
+
+// validPackage returns a numbered package that Validate accepts.
+func validPackage() *ProfilePackage {
+	size := func(n byte) *Fcp { return &Fcp{EfFileSize: []byte{0, n}} }
+	offset := UInt16(2)
+	p := NewProfilePackage(&ProfileHeader{EUICCMandatoryServices: ServicesList{Usim: &struct{}{}, Milenage: &struct{}{}}})
+	p.Add(
+		ProfileElement{Mf: &PEMF{
+			EfIccid: File{{FileDescriptor: size(10)}, {FillFileContent: make([]byte, 10)}},
+			EfPl:    &File{{FileDescriptor: size(4)}, {FillFileOffset: &offset}, {FillFileContent: []byte{1, 2}}},
+		}},
+		ProfileElement{Usim: &PEUSIM{EfImsi: File{{FileDescriptor: &Fcp{}}, {FillFileContent: make([]byte, 9)}}}},
+		ProfileElement{AkaParameter: &PEAKAParameter{AlgoConfiguration: AlgoConfiguration{
+			AlgoParameter: &AlgoParameter{AlgorithmID: AlgoParameterAlgorithmIDMilenage},
+		}}},
+		ProfileElement{GenericFileManagement: &PEGenericFileManagement{FileManagementCMD: []FileManagement{{
+			{CreateFCP: size(3)}, {FillFileContent: []byte{1, 2, 3}},
+			{FilePath: []byte{0x7F, 0xFF}}, {FillFileContent: make([]byte, 100)},
+		}}}},
+		ProfileElement{End: &PEEnd{}},
+	)
+	p.Number()
+	return p
+}
+
+func TestProfilePackageValidate(t *testing.T) {
+	type problem struct {
+		index int
+		msg   string
+	}
+	tests := []struct {
+		name   string
+		change func(p *ProfilePackage)
+		want   []problem
+	}{
+		{"valid", func(p *ProfilePackage) {}, nil},
+		{"not well formed", func(p *ProfilePackage) { p.Elements = p.Elements[:2] },
+			[]problem{{1, "is the last element but is not the end element"}}},
+		{"wrong identification", func(p *ProfilePackage) { p.Elements[2].Usim.UsimHeader.Identification = 7 },
+			[]problem{{2, "has identification 7, want 2"}}},
+		{"repeated identification", func(p *ProfilePackage) { p.Elements[2].Usim.UsimHeader.Identification = 1 },
+			[]problem{{2, "repeats the identification 1 of element 1"}}},
+		{"missing usim service", func(p *ProfilePackage) { p.Elements[0].Header.EUICCMandatoryServices.Usim = nil },
+			[]problem{{0, "does not list the mandatory service usim"}}},
+		{"extra isim service", func(p *ProfilePackage) { p.Elements[0].Header.EUICCMandatoryServices.Isim = &struct{}{} },
+			[]problem{{0, "lists the mandatory service isim, which no element needs"}}},
+		{"missing multiple-usim service", func(p *ProfilePackage) {
+			usim := *p.Elements[2].Usim
+			p.Elements = append(p.Elements[:3], p.Elements[2:]...)
+			p.Elements[3].Usim = &usim
+			p.Number()
+		}, []problem{{0, "does not list the mandatory service multiple-usim"}}},
+		{"missing milenage service", func(p *ProfilePackage) { p.Elements[0].Header.EUICCMandatoryServices.Milenage = nil },
+			[]problem{{0, "does not list the mandatory service milenage"}}},
+		{"missing tuak service", func(p *ProfilePackage) {
+			p.Elements[3].AkaParameter.AlgoConfiguration.AlgoParameter.AlgorithmID = AlgoParameterAlgorithmIDTuak
+		}, []problem{{0, "does not list the mandatory service tuak128 or tuak256"}}},
+		{"file contents too long", func(p *ProfilePackage) { p.Elements[1].Mf.EfIccid[1].FillFileContent = make([]byte, 11) },
+			[]problem{{1, "writes ef-iccid up to offset 11, beyond its efFileSize 10"}}},
+		{"file offset too far", func(p *ProfilePackage) { *(*p.Elements[1].Mf.EfPl)[1].FillFileOffset = 3 },
+			[]problem{{1, "writes ef-pl up to offset 5, beyond its efFileSize 4"}}},
+		{"created file contents too long", func(p *ProfilePackage) {
+			p.Elements[4].GenericFileManagement.FileManagementCMD[0][1].FillFileContent = make([]byte, 4)
+		}, []problem{{4, "writes fileManagementCMD[0] up to offset 4, beyond its efFileSize 3"}}},
+		{"several problems", func(p *ProfilePackage) {
+			p.Elements[1].Mf.EfIccid[1].FillFileContent = make([]byte, 11)
+			p.Elements[2].Usim.UsimHeader.Identification = 7
+			p.Elements[0].Header.EUICCMandatoryServices.Usim = nil
+		}, []problem{
+			{0, "does not list the mandatory service usim"},
+			{1, "writes ef-iccid up to offset 11, beyond its efFileSize 10"},
+			{2, "has identification 7, want 2"},
+		}},
+	}
+	for _, tt := range tests {
+		p := validPackage()
+		tt.change(p)
+		err := p.Validate()
+		if tt.want == nil {
+			if err != nil {
+				t.Errorf("%s: Validate() = %v", tt.name, err)
+			}
+			continue
+		}
+		var errs PackageErrors
+		if !errors.As(err, &errs) || len(errs) != len(tt.want) {
+			t.Errorf("%s: Validate() = %v, want %d errors", tt.name, err, len(tt.want))
+			continue
+		}
+		for i, w := range tt.want {
+			if errs[i].Index != w.index || errs[i].Msg != w.msg {
+				t.Errorf("%s: error %d = %v, want element %d %s", tt.name, i, errs[i], w.index, w.msg)
+			}
+		}
+	}
+}
+
+func TestPackageErrors(t *testing.T) {
+	e1 := &PackageError{Index: 0, Msg: "does not list the mandatory service usim"}
+	e2 := &PackageError{Index: 2, Msg: "has identification 7, want 2"}
+	tests := []struct {
+		errs PackageErrors
+		want string
+	}{
+		{nil, "no errors"},
+		{PackageErrors{e1}, "saip: profile element 0 does not list the mandatory service usim"},
+		{PackageErrors{e1, e2}, "saip: profile element 0 does not list the mandatory service usim (and 1 more errors)"},
+	}
+	for _, tt := range tests {
+		if got := tt.errs.Error(); got != tt.want {
+			t.Errorf("Error() of %d errors = %q, want %q", len(tt.errs), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package asn1go
+
+import (
+	"bytes"
+	"testing"
+)
+
+// sampleOctets returns n octets counting up from 0.
+func sampleOctets(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return b
+}
+
+// sampleLine is the hex digits of the first 32 octets of sampleOctets.
+const sampleLine = "000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F"
+
+func TestMarshalSample(t *testing.T) {
+	type file struct {
+		Fill []byte `asn1:"fillFileContent"`
+		N    int
+	}
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{sampleOctets(32), "'" + sampleLine + "'H"},
+		{sampleOctets(33), "'" + sampleLine + "\n  20'H"},
+		{sampleOctets(65), "'" + sampleLine + "\n  202122232425262728292A2B2C2D2E2F303132333435363738393A3B3C3D3E3F\n  40'H"},
+		{ChoiceValue{"fill", sampleOctets(32)}, "fill : '" + sampleLine + "'H"},
+		{
+			struct {
+				A file `asn1:"a"`
+			}{file{sampleOctets(33), 1}},
+			"{\n  a {\n    fillFileContent '" + sampleLine + "\n      20'H,\n    n 1\n  }\n}",
+		},
+	}
+	for _, tt := range tests {
+		b, err := MarshalSample(tt.v)
+		if err != nil {
+			t.Errorf("MarshalSample(%v): %v", tt.v, err)
+			continue
+		}
+		if string(b) != tt.want {
+			t.Errorf("MarshalSample(%v):\nhave %q\nwant %q", tt.v, b, tt.want)
+		}
+		var v interface{}
+		if err := Unmarshal(b, &v); err != nil {
+			t.Errorf("Unmarshal(%q): %v", b, err)
+		}
+	}
+}
+
+func TestEncoderSetSampleLayout(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.SetSampleLayout()
+	if err := enc.Encode(sampleOctets(33)); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	enc.SetIndent("", "")
+	if err := enc.Encode(sampleOctets(33)); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	want := "'" + sampleLine + "\n  20'H\n'" + sampleLine + "20'H\n"
+	if buf.String() != want {
+		t.Errorf("Encode:\nhave %q\nwant %q", buf.String(), want)
+	}
+}
+
+func TestDERToSample(t *testing.T) {
+	m, err := ParseModule([]byte("M DEFINITIONS AUTOMATIC TAGS ::= BEGIN T ::= SEQUENCE { fill OCTET STRING } END"))
+	if err != nil {
+		t.Fatalf("ParseModule: %v", err)
+	}
+	der, err := EncodeDER(m.Type("T"), map[string]interface{}{"fill": sampleOctets(33)})
+	if err != nil {
+		t.Fatalf("EncodeDER: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := DERToSample(&buf, m.Type("T"), der); err != nil {
+		t.Fatalf("DERToSample: %v", err)
+	}
+	want := "value1 T ::= {\n  fill '" + sampleLine + "\n    20'H\n}\n"
+	if buf.String() != want {
+		t.Errorf("DERToSample:\nhave %q\nwant %q", buf.String(), want)
+	}
+	var back bytes.Buffer
+	if err := TextToDER(&back, m.Type("T"), buf.Bytes()); err != nil || !bytes.Equal(back.Bytes(), der) {
+		t.Errorf("TextToDER(%q) = % X, %v, want % X", buf.String(), back.Bytes(), err, der)
+	}
+}
@@ -0,0 +1,1557 @@
+package asn1go
+
+// ASN.1 value notation scanner.
+//
+// The scanner is a state machine in the style of the one in encoding/json.
+// Callers call scan.reset and then pass bytes in one at a time by calling
+// scan.step(&scan, c) for each byte. The return value, referred to as an
+// opcode, tells the caller about significant parsing events like beginning
+// and ending literals, objects, identifiers and value assignments, so that
+// the caller can follow along if it wishes. Callers that only follow along
+// may pass runs of bytes that leave the state unchanged, such as the digits
+// of a long hstring, to scan.skipRun instead.
+// The return value scanEnd indicates that a single top-level value has been
+// completed, *before* the byte that was just passed in. It is returned for
+// the first byte of the next top-level value, or by eof.
+//
+// Value notation does not mark the role of an identifier up front: in
+// "{ mandated NULL }" the identifier names a component, in "{ usim, isim }"
+// the identifiers are the element values themselves and in "header : {...}"
+// it selects a CHOICE alternative. The scanner reports scanBeginIdentifier
+// when such an identifier starts in a brace-delimited value, and
+// scanBeginValueReference when one starts a top-level value, where it is
+// the value reference of a value assignment if scanBeginTypeReference
+// follows, and a value otherwise; its role follows from the opcode of the
+// first significant byte after it. A type reference, which only the type
+// of a value assignment is, starts with scanBeginTypeReference.
+//
+// Comments ("--" up to the end of the line or the next "--") are reported
+// as scanSkipSpace, like white space.
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Valid reports whether data is valid ASN.1 value notation.
+func Valid(data []byte) bool {
+	scan := newScanner()
+	defer freeScanner(scan)
+	_, err := checkValid(data, scan)
+	return err == nil
+}
+
+// validChunk is the size of the chunks ValidReader reads.
+const validChunk = 32 << 10
+
+// ValidReader is like Valid but reads the value notation from r in
+// chunks, rather than all at once, so that checking a large profile
+// package takes no more memory than a chunk. It returns nil if the input
+// is valid, the SyntaxError of the first error, whose Snippet holds no
+// more of the line than the chunk it was found in, or the error of reading
+// r.
+func ValidReader(r io.Reader) error {
+	scan := newScanner()
+	defer freeScanner(scan)
+	scan.reset()
+	buf := make([]byte, validChunk)
+	lines := 0
+	for {
+		n, err := r.Read(buf)
+		if _, serr := scanChunk(buf[:n], scan, lines); serr != nil {
+			return serr
+		}
+		lines += bytes.Count(buf[:n], []byte{'\n'})
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if scan.eof() == scanError {
+		return addSnippet(scan.err, nil, 0, lines)
+	}
+	return nil
+}
+
+// A ValidityReport describes value notation input, as ValidReport checks
+// it.
+type ValidityReport struct {
+	Values   int     // number of top-level values, complete or not
+	MaxDepth int     // deepest nesting of brace-delimited values
+	Offsets  []int64 // offset of the first byte of each top-level value
+	Err      error   // first syntax error, or nil if the input is valid
+}
+
+// ValidReport is like Valid but reports what it found, as a pre-flight
+// check before a full decode: the top-level values, such as the value
+// assignments of a profile package, where they begin, how deeply they
+// nest and the first syntax error. The report covers the input up to the
+// error, if any.
+func ValidReport(data []byte) *ValidityReport {
+	scan := newScanner()
+	defer freeScanner(scan)
+	r := &ValidityReport{}
+	inValue := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		scan.bytes++
+		op := scan.step(scan, c)
+		if op == scanEnd {
+			// c begins the next top-level value.
+			scan.restart()
+			op = scan.step(scan, c)
+			inValue = false
+		}
+		if op == scanError {
+			r.Err = addSnippet(scan.err, data, i, 0)
+			return r
+		}
+		if op != scanSkipSpace && !inValue {
+			inValue = true
+			r.Values++
+			r.Offsets = append(r.Offsets, int64(valueStart(scan, c, i)))
+		}
+		if len(scan.parseState) > r.MaxDepth {
+			r.MaxDepth = len(scan.parseState)
+		}
+		k := scan.skipRun(data[i+1:], c, op)
+		i += k
+		scan.bytes += int64(k)
+	}
+	if scan.eof() == scanError {
+		r.Err = addSnippet(scan.err, data, len(data), 0)
+	}
+	return r
+}
+
+// checkValid verifies that data is valid ASN.1 value notation and returns
+// the number of top-level values it contains.
+func checkValid(data []byte, scan *scanner) (int, error) {
+	scan.reset()
+	return scanValid(data, scan)
+}
+
+// scanValid is checkValid for a scanner that was reset already, with its
+// options set.
+func scanValid(data []byte, scan *scanner) (int, error) {
+	n, err := scanChunk(data, scan, 0)
+	if err != nil {
+		return 0, err
+	}
+	if scan.eof() == scanError {
+		return 0, addSnippet(scan.err, data, len(data), 0)
+	}
+	return n + 1, nil
+}
+
+// scanChunk feeds the next chunk of the input, data, which lines line ends
+// come before, to scan, and returns the number of top-level values that
+// end in it.
+func scanChunk(data []byte, scan *scanner, lines int) (int, error) {
+	n := 0
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		scan.bytes++
+		op := scan.step(scan, c)
+		if op == scanEnd {
+			// c begins the next top-level value.
+			n++
+			scan.restart()
+			op = scan.step(scan, c)
+		}
+		if op == scanError {
+			return 0, addSnippet(scan.err, data, i, lines)
+		}
+		k := scan.skipRun(data[i+1:], c, op)
+		i += k
+		scan.bytes += int64(k)
+	}
+	return n, nil
+}
+
+// The causes of syntax errors that the SyntaxErrors and DERSyntaxErrors of
+// this package wrap, for errors.Is to tell apart.
+var (
+	// ErrUnexpectedEOF is the cause of input that ends in the middle of
+	// a value. It is io.ErrUnexpectedEOF, which a Decoder returns for
+	// such input.
+	ErrUnexpectedEOF = io.ErrUnexpectedEOF
+
+	// ErrDepthExceeded is the cause of values nested deeper than the
+	// nesting depth limit, see Limits.
+	ErrDepthExceeded = errors.New("asn1go: nesting depth exceeded")
+
+	// ErrTrailingData is the cause of input that goes on after a value
+	// where it must hold a single one, as for UnmarshalSingle, ParseValue
+	// and DecodeDER.
+	ErrTrailingData = errors.New("asn1go: trailing data after top-level value")
+
+	// ErrLimitExceeded is the cause of input beyond the other Limits of
+	// a Decoder.
+	ErrLimitExceeded = errors.New("asn1go: input limit exceeded")
+)
+
+// A SyntaxError is a description of an ASN.1 value notation syntax error.
+// It wraps the cause of the error, ErrUnexpectedEOF, ErrDepthExceeded,
+// ErrTrailingData or ErrLimitExceeded, if it has one of them.
+type SyntaxError struct {
+	msg    string // description of error
+	err    error  // cause of the error, or nil
+	Offset int64  // error occurred after reading Offset bytes
+	Line   int    // line of the error, starting at 1, or 0 if not known
+
+	// Context describes the part of the value the error occurred in, by
+	// the names of the values around it, such as "value of component
+	// 'efFileSize'" or "element of the value of CHOICE alternative
+	// 'genericFileManagement'", or is empty at top level. Expected lists
+	// what could have come instead of the invalid character, such as
+	// "','" and "'}'", when known. Snippet holds the input on the line of
+	// the error, when it was at hand.
+	Context  string
+	Expected []string
+	Snippet  string
+}
+
+func (e *SyntaxError) Error() string {
+	msg := e.msg
+	if e.Context != "" {
+		msg += " while parsing " + e.Context
+	}
+	if e.Line > 0 {
+		msg += " at line " + strconv.Itoa(e.Line)
+	}
+	if len(e.Expected) > 0 {
+		msg += ", expecting " + strings.Join(e.Expected, " or ")
+	}
+	return msg
+}
+
+// Unwrap returns the cause of the error, or nil.
+func (e *SyntaxError) Unwrap() error { return e.err }
+
+// A scanner is an ASN.1 value notation scanning state machine.
+// Callers call scan.reset and then pass bytes in one at a time
+// by calling scan.step(&scan, c) for each byte.
+type scanner struct {
+	// The step is a func to be called to execute the next transition.
+	step func(*scanner, byte) int
+
+	// Reached end of top-level value.
+	endTop bool
+
+	// Stack of what we're in the middle of - element, component value,
+	// object identifier.
+	parseState []int
+
+	// names holds the names of the values being read, for the Context of
+	// syntax errors: names[0] those of the top-level value and names[i]
+	// those of the current element of the brace-delimited value of
+	// parseState[i-1].
+	names []valueNames
+
+	// Error that happened, if any.
+	err error
+
+	// total bytes consumed, updated by decoder.Decode (and deliberately
+	// not set to zero by scan.reset)
+	bytes int64
+
+	// resume is the state to return to once a comment ends.
+	resume func(*scanner, byte) int
+
+	// minus reports whether the number literal that began with the last
+	// scanBeginLiteral was preceded by a minus sign. The sign is reported
+	// as scanSkipSpace because "-" may also start a comment.
+	minus bool
+
+	// binary reports whether all digits of the current bstring or hstring
+	// so far are binary digits.
+	binary bool
+
+	// keyword counts the bytes of "CONTAINING" that the current
+	// identifier matches so far, or is -1 if it does not match. The
+	// keyword begins a containing value, CONTAINING value.
+	keyword int
+
+	// allowMultipleTopValues reports whether further top-level values may
+	// follow the first one, as in a profile package made of a sequence of
+	// value assignments.
+	allowMultipleTopValues bool
+
+	// maxDepth is the maximum nesting depth, or 0 for maxNestingDepth.
+	maxDepth int
+
+	// dialect says which identifiers the scanner accepts. name holds the
+	// identifier being read. Under DialectStrict, pending reports whether
+	// the identifier just read waits for the byte after it, which tells a
+	// CHOICE alternative or an open type from other identifiers, to be
+	// checked.
+	dialect Dialect
+	name    []byte
+	pending bool
+
+	// element reports that the number being read began an element of a
+	// brace-delimited value, where under DialectLenient digits may also
+	// begin a component identifier.
+	element bool
+
+	// run is the kind of literal or comment being scanned whose bytes
+	// skipRun may consume in bulk: '\'' inside a bstring or hstring, '"'
+	// inside a cstring and '-' inside a comment, or 0 elsewhere. It is
+	// kept on an error, for a Decoder that resynchronizes after it.
+	run byte
+}
+
+// valueNames are the names of a value being read: the value reference of
+// a value assignment, for the top-level value, or the identifier of the
+// component it is the value of, and the identifier of the CHOICE
+// alternative or the type of the open type value it is, if any.
+type valueNames struct {
+	component   []byte
+	alternative []byte
+}
+
+var scannerPool = sync.Pool{
+	New: func() interface{} {
+		return &scanner{}
+	},
+}
+
+func newScanner() *scanner {
+	scan := scannerPool.Get().(*scanner)
+	// scan.reset by design doesn't set bytes to zero
+	scan.bytes = 0
+	scan.maxDepth = 0
+	scan.dialect = DialectDefault
+	scan.pending = false
+	scan.reset()
+	return scan
+}
+
+func freeScanner(scan *scanner) {
+	// Avoid hanging on to too much memory in extreme cases.
+	if len(scan.parseState) > 1024 {
+		scan.parseState = nil
+		scan.names = nil
+	}
+	scannerPool.Put(scan)
+}
+
+// A Dialect says which identifiers a Decoder accepts, for value notation
+// written by tools that do not follow X.680 to the letter.
+type Dialect int
+
+const (
+	// DialectDefault accepts identifiers that begin with a letter of
+	// either case and go on with letters, digits, hyphens and
+	// underscores, and with dots that begin the parts of external and
+	// field references, such as Module.Type or TYPE-IDENTIFIER.&id. It is
+	// the default.
+	DialectDefault Dialect = iota
+
+	// DialectStrict accepts the identifiers and type references of
+	// X.680 only: identifiers begin with a lower case letter, unless
+	// they are keywords such as TRUE or NULL, and type references with an
+	// upper case letter, as does the type of an open type value,
+	// Type : Value; both hold letters, digits and hyphens and do not end
+	// with a hyphen. Dots only separate the module reference of an
+	// external type reference, Module.Type, or of a value reference in
+	// value position, Module.value. Two hyphens in a row begin a comment,
+	// which ends an identifier under every dialect. The SyntaxError for a
+	// name that breaks a rule says which.
+	DialectStrict
+
+	// DialectLenient accepts what DialectDefault does, and also
+	// identifiers that begin with digits, such as 3gpp-milenage, once a
+	// letter other than the e or E of an exponent, or an underscore,
+	// follows the digits. They may be values, component identifiers and
+	// CHOICE alternatives, as in { 5g-sa 1abc : TRUE }.
+	DialectLenient
+)
+
+// These values are returned by the state transition functions
+// assigned to scanner.state and the method scanner.eof.
+// They give details about the current state of the scan that
+// callers might be interested to know about.
+// It is okay to ignore the return value of any particular
+// call to scanner.state: if one call returns scanError,
+// every subsequent call will return scanError too.
+const (
+	// Continue.
+	scanContinue            = iota // uninteresting byte
+	scanBeginLiteral               // end implied by next result != scanContinue
+	scanBeginObject                // begin brace-delimited value
+	scanBeginValueReference        // begin identifier of a top-level value, a value reference if a type follows
+	scanBeginIdentifier            // begin identifier of an element, whose role is decided by the next result
+	scanBeginTypeReference         // begin type of a value assignment; previous identifier was its name
+	scanAssignment                 // just finished "::=" of a value assignment
+	scanChoiceTag                  // just finished ':' after the identifier of a CHOICE alternative
+	scanObjectValue                // just finished element value (',')
+	scanEndObject                  // end brace-delimited value (implies scanObjectValue if possible)
+	scanSkipSpace                  // space byte or comment; can skip; known to be last "continue" result
+
+	// Stop.
+	scanEnd   // top-level value ended *before* this byte; known to be first "stop" result
+	scanError // hit an error, scanner.err.
+)
+
+// These values are stored in the parseState stack.
+// They give the current state of a brace-delimited value
+// being scanned.
+const (
+	parseFirstElement     = iota // parsing first element of a brace-delimited value
+	parseElement                 // parsing a later element
+	parseComponentValue          // parsing value after a component identifier
+	parseObjectIdentifier        // parsing space-separated object identifier components
+)
+
+// This limits the max nesting depth to prevent stack overflow, unless a
+// Decoder sets another limit.
+const maxNestingDepth = 10000
+
+// reset prepares the scanner for use.
+// It must be called before calling s.step.
+func (s *scanner) reset() {
+	s.restart()
+	s.allowMultipleTopValues = true
+}
+
+// restart prepares the scanner for the next top-level value,
+// keeping the byte count and the scanner options.
+func (s *scanner) restart() {
+	s.step = stateBeginTopValue
+	s.parseState = s.parseState[0:0]
+	s.resetNames(0)
+	s.err = nil
+	s.endTop = false
+	s.minus = false
+	s.element = false
+	s.run = 0
+}
+
+// eof tells the scanner that the end of input has been reached.
+// It returns a scan status just as s.step does.
+func (s *scanner) eof() int {
+	if s.err != nil {
+		return scanError
+	}
+	// Unlike in JSON, a value may be complete while the scanner still
+	// needs to see what follows it, so always feed it the implied space.
+	s.step(s, ' ')
+	if s.err != nil || s.checkPending(' ', true) == scanError {
+		return scanError
+	}
+	if s.endTop {
+		return scanEnd
+	}
+	s.err = &SyntaxError{msg: "unexpected end of ASN.1 input", err: ErrUnexpectedEOF, Offset: s.bytes, Context: s.context()}
+	return scanError
+}
+
+// pushParseState pushes a new parse state newParseState onto the parse stack.
+// an error state is returned if the maximum depth was exceeded, otherwise successState is returned.
+func (s *scanner) pushParseState(c byte, newParseState int, successState int) int {
+	s.parseState = append(s.parseState, newParseState)
+	s.resetNames(len(s.parseState))
+	max := s.maxDepth
+	if max == 0 {
+		max = maxNestingDepth
+	}
+	if len(s.parseState) <= max {
+		return successState
+	}
+	return s.errorOf(ErrDepthExceeded, c, "exceeded max depth")
+}
+
+// resetNames forgets the names of the value at depth i, that of a new
+// element of the brace-delimited value parseState[i-1], or the top-level
+// value for i 0.
+func (s *scanner) resetNames(i int) {
+	if i < len(s.names) {
+		s.names[i].component = s.names[i].component[:0]
+		s.names[i].alternative = s.names[i].alternative[:0]
+		return
+	}
+	s.names = append(s.names, valueNames{})
+}
+
+// popParseState pops a parse state (already obtained) off the stack
+// and updates s.step accordingly.
+func (s *scanner) popParseState() {
+	n := len(s.parseState) - 1
+	s.parseState = s.parseState[0:n]
+	if n == 0 {
+		s.step = stateEndTop
+		s.endTop = true
+	} else {
+		s.step = stateEndValue
+	}
+}
+
+// beginComment handles a '-' in a state where no value can begin, which
+// must be the start of a comment. resume is the state to return to once
+// the comment ends.
+func (s *scanner) beginComment(resume func(*scanner, byte) int) int {
+	s.resume = resume
+	s.step = stateCommentStart
+	return scanSkipSpace
+}
+
+// beginCommentOrNumber handles a '-' in a state where a value may begin,
+// which starts either a comment or a negative number. resume is the state
+// to return to once the comment ends, or to pass the first digit to.
+func (s *scanner) beginCommentOrNumber(resume func(*scanner, byte) int) int {
+	s.resume = resume
+	s.step = stateCommentOrMinus
+	return scanSkipSpace
+}
+
+// skipRun returns the number of bytes at the start of data that follow
+// the byte c, for which the scanner returned op, without changing the state
+// of the scan: the digits of a bstring or hstring, the characters of a
+// cstring, the text of a comment and white space after white space. Each of
+// them would be reported as op, too. Callers skip such runs with a single
+// loop in place of a call of s.step per byte, and count them in s.bytes.
+func (s *scanner) skipRun(data []byte, c byte, op int) int {
+	switch s.run {
+	case '\'':
+		for i, c := range data {
+			switch {
+			case c == '0' || c == '1' || isSpace(c):
+			case isHexDigit(c):
+				s.binary = false
+			default:
+				return i
+			}
+		}
+	case '"':
+		for i, c := range data {
+			if c == '"' || c < 0x20 && c != '\t' && c != '\n' && c != '\r' {
+				return i
+			}
+		}
+	case '-':
+		for i, c := range data {
+			if c == '\n' || c == '\r' || c == '-' {
+				return i
+			}
+		}
+	default:
+		// Once a state skips a blank, it skips any that follow. Line
+		// ends are left to s.step, since they end a comment.
+		if op != scanSkipSpace || c != ' ' && c != '\t' {
+			return 0
+		}
+		for i, c := range data {
+			if c != ' ' && c != '\t' {
+				return i
+			}
+		}
+	}
+	return len(data)
+}
+
+func isSpace(c byte) bool {
+	return c <= ' ' && (c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == '\v')
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// isNameChar reports whether c may continue an identifier or reference.
+// Hyphens are handled by the name states themselves, since "--" starts
+// a comment.
+func isNameChar(c byte) bool {
+	return isLetter(c) || isDigit(c) || c == '_'
+}
+
+// stateBeginTopValue is the state at the beginning of the input or of a
+// later top-level value, which may be a value assignment.
+func stateBeginTopValue(s *scanner, c byte) int {
+	if isSpace(c) {
+		return scanSkipSpace
+	}
+	if c == '-' {
+		return s.beginCommentOrNumber(stateBeginTopValue)
+	}
+	if isLetter(c) {
+		s.step = stateInName
+		s.beginName(c)
+		return scanBeginValueReference
+	}
+	return stateBeginValue(s, c)
+}
+
+// stateBeginElementOrEmpty is the state after reading `{`.
+func stateBeginElementOrEmpty(s *scanner, c byte) int {
+	if isSpace(c) {
+		return scanSkipSpace
+	}
+	if c == '-' {
+		return s.beginCommentOrNumber(stateBeginElementOrEmpty)
+	}
+	if c == '}' {
+		s.popParseState()
+		return scanEndObject
+	}
+	return stateBeginElement(s, c)
+}
+
+// stateBeginElement is the state before an element of a brace-delimited
+// value, such as after reading `,`.
+func stateBeginElement(s *scanner, c byte) int {
+	if isSpace(c) {
+		return scanSkipSpace
+	}
+	if c == '-' {
+		return s.beginCommentOrNumber(stateBeginElement)
+	}
+	if isLetter(c) {
+		s.step = stateInName
+		s.beginName(c)
+		return scanBeginIdentifier
+	}
+	if isDigit(c) {
+		op := stateBeginValue(s, c)
+		s.element = true
+		return op
+	}
+	return stateBeginValue(s, c)
+}
+
+// stateBeginValue is the state at the beginning of a value, such as after
+// a component identifier, a CHOICE alternative or "::=".
+func stateBeginValue(s *scanner, c byte) int {
+	if isSpace(c) {
+		return scanSkipSpace
+	}
+	switch c {
+	case '-':
+		return s.beginCommentOrNumber(stateBeginValue)
+	case '{':
+		s.step = stateBeginElementOrEmpty
+		return s.pushParseState(c, parseFirstElement, scanBeginObject)
+	case '\'':
+		s.step = stateInHexadecimalString
+		s.run = '\''
+		s.binary = true
+		return scanBeginLiteral
+	case '"':
+		s.step = stateInCString
+		s.run = '"'
+		return scanBeginLiteral
+	}
+	if isDigit(c) {
+		s.minus = false
+		s.element = false
+		s.step = state1
+		if s.dialect == DialectLenient {
+			// The digits may begin an identifier; see beginDigitName.
+			s.name = append(s.name[:0], c)
+		}
+		return scanBeginLiteral
+	}
+	if isLetter(c) {
+		s.step = stateInValueName
+		s.beginName(c)
+		return scanBeginLiteral
+	}
+	return s.error(c, "where a value begins", "value")
+}
+
+// stateCommentStart is the state after reading the first '-' of a comment.
+func stateCommentStart(s *scanner, c byte) int {
+	if c == '-' {
+		s.step = stateInComment
+		s.run = '-'
+		return scanSkipSpace
+	}
+	return s.error(c, "after '-'", "'-'")
+}
+
+// stateCommentOrMinus is the state after reading '-' where a value may
+// begin.
+func stateCommentOrMinus(s *scanner, c byte) int {
+	if c == '-' {
+		s.step = stateInComment
+		s.run = '-'
+		return scanSkipSpace
+	}
+	if isDigit(c) {
+		op := s.resume(s, c)
+		s.minus = true
+		return op
+	}
+	return s.error(c, "after '-'", "'-'", "digit")
+}
+
+// stateInComment is the state inside a comment.
+func stateInComment(s *scanner, c byte) int {
+	switch c {
+	case '\n', '\r':
+		s.step = s.resume
+		s.run = 0
+	case '-':
+		s.step = stateInCommentHyphen
+		s.run = 0
+	}
+	return scanSkipSpace
+}
+
+// stateInCommentHyphen is the state after reading '-' inside a comment.
+func stateInCommentHyphen(s *scanner, c byte) int {
+	switch c {
+	case '-', '\n', '\r':
+		s.step = s.resume
+	default:
+		s.step = stateInComment
+		s.run = '-'
+	}
+	return scanSkipSpace
+}
+
+// containingKeyword is the keyword of a containing value, which the value
+// contained follows.
+const containingKeyword = "CONTAINING"
+
+// beginName starts matching the identifier that begins with c against
+// containingKeyword.
+func (s *scanner) beginName(c byte) {
+	s.keyword = 0
+	s.name = s.name[:0]
+	s.nameChar(c)
+}
+
+// recordName records the next character c of the current identifier or
+// type reference.
+func (s *scanner) recordName(c byte) {
+	s.name = append(s.name, c)
+}
+
+// strictKeywords are the keywords that may stand where an identifier does
+// under DialectStrict, although they begin with an upper case letter.
+var strictKeywords = map[string]bool{
+	"NULL": true, "TRUE": true, "FALSE": true, containingKeyword: true,
+	"PLUS-INFINITY": true, "MINUS-INFINITY": true, "NOT-A-NUMBER": true,
+}
+
+// endName ends the identifier just read. If comment is set, its last
+// hyphen began a comment, and is not part of it. Under DialectStrict the
+// identifier is checked by checkPending, once the byte after it is known.
+func (s *scanner) endName(comment bool) {
+	if comment {
+		s.name = s.name[:len(s.name)-1]
+	}
+	s.pending = s.dialect == DialectStrict
+}
+
+// checkPending checks the identifier that endName ended, if it waits to be
+// checked, now that c, which is not white space, follows it. A ':' makes
+// it a CHOICE alternative or the type of an open type value; value
+// reports that the identifier stands for a value rather than naming a
+// component or a value assignment.
+func (s *scanner) checkPending(c byte, value bool) int {
+	if !s.pending {
+		return scanContinue
+	}
+	s.pending = false
+	return s.checkName(false, c == ':', value && c != ':')
+}
+
+// checkName checks the identifier just read under DialectStrict, or with
+// typeRef the word of a type reference. With tag, a ':' follows the
+// identifier, which may then be a type reference too, the type of an open
+// type value. With value, the identifier stands for a value, and may then
+// be an external value reference, Module.value; a dot is not allowed in
+// other identifiers. It returns scanError, with the rule of X.680 the name
+// breaks, for a name that X.680 does not allow, and scanContinue
+// otherwise.
+func (s *scanner) checkName(typeRef, tag, value bool) int {
+	if s.dialect != DialectStrict {
+		return scanContinue
+	}
+	name := s.name
+	kind := "identifier "
+	if typeRef {
+		kind = "type reference "
+	}
+	lower := 'a' <= name[0] && name[0] <= 'z'
+	external := value && isExternalValueReference(name)
+	openType := tag && upperParts(name)
+	var msg string
+	switch {
+	case bytes.IndexByte(name, '_') >= 0:
+		msg = "holds an underscore"
+	case typeRef && !upperParts(name):
+		msg = "does not begin with an upper case letter"
+	case !typeRef && bytes.IndexByte(name, '.') >= 0 && !external && !openType:
+		msg = "holds a dot"
+	case !typeRef && !lower && !strictKeywords[string(name)] && !external && !openType:
+		msg = "does not begin with a lower case letter"
+	case name[len(name)-1] == '-':
+		msg = "ends with a hyphen"
+	default:
+		return scanContinue
+	}
+	s.step = stateError
+	s.err = &SyntaxError{msg: kind + strconv.Quote(string(name)) + " " + msg, Offset: s.bytes, Context: s.context()}
+	return scanError
+}
+
+// isExternalValueReference reports whether name is a value reference of
+// another module, a module reference and a value reference separated by a
+// dot, such as Module.value.
+func isExternalValueReference(name []byte) bool {
+	i := bytes.IndexByte(name, '.')
+	if i <= 0 || i == len(name)-1 || bytes.IndexByte(name[i+1:], '.') >= 0 {
+		return false
+	}
+	return 'A' <= name[0] && name[0] <= 'Z' && 'a' <= name[i+1] && name[i+1] <= 'z'
+}
+
+// upperParts reports whether the parts of the type reference name, a
+// module reference and a type reference separated by a dot, say, all
+// begin with an upper case letter, but for field references such as &id
+// after the first.
+func upperParts(name []byte) bool {
+	for i, part := range bytes.Split(name, []byte{'.'}) {
+		if i > 0 && len(part) > 0 && part[0] == '&' {
+			continue
+		}
+		if len(part) == 0 || part[0] < 'A' || 'Z' < part[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// beginDigitName turns the number being read into an identifier under
+// DialectLenient, when c cannot continue the number: a component
+// identifier or an identifier value if the number began an element, and
+// an identifier value otherwise. It reports false if it does not.
+func (s *scanner) beginDigitName(c byte) bool {
+	if s.dialect != DialectLenient || s.minus || !isLetter(c) && c != '_' {
+		return false
+	}
+	if n := len(s.parseState); n > 0 && s.parseState[n-1] == parseObjectIdentifier {
+		// The components after the first of { 2 23 143 } are numbers.
+		return false
+	}
+	s.keyword = -1
+	s.recordName(c)
+	if s.element {
+		s.step = stateInName
+	} else {
+		s.step = stateInValueName
+	}
+	return true
+}
+
+// nameChar matches the next character c of the current identifier against
+// containingKeyword, and records it.
+func (s *scanner) nameChar(c byte) {
+	s.recordName(c)
+	if s.keyword >= 0 && s.keyword < len(containingKeyword) && containingKeyword[s.keyword] == c {
+		s.keyword++
+	} else {
+		s.keyword = -1
+	}
+}
+
+// isContaining reports whether the identifier just read is the keyword
+// CONTAINING.
+func (s *scanner) isContaining() bool {
+	return s.keyword == len(containingKeyword)
+}
+
+// stateInName is the state inside an identifier in element position, or
+// at the beginning of a top-level value.
+func stateInName(s *scanner, c byte) int {
+	if isNameChar(c) {
+		s.nameChar(c)
+		return scanContinue
+	}
+	if c == '-' {
+		s.recordName(c)
+		s.step = stateInNameHyphen
+		return scanContinue
+	}
+	if c == '.' {
+		s.nameChar(c)
+		s.resume = stateInName
+		s.step = stateInNameDot
+		return scanContinue
+	}
+	s.endName(false)
+	return stateEndName(s, c)
+}
+
+// stateInNameHyphen is the state after reading '-' inside an identifier.
+func stateInNameHyphen(s *scanner, c byte) int {
+	if isNameChar(c) {
+		s.keyword = -1
+		s.nameChar(c)
+		s.step = stateInName
+		return scanContinue
+	}
+	if c == '-' {
+		s.endName(true)
+		s.resume = stateEndName
+		s.step = stateInComment
+		s.run = '-'
+		return scanSkipSpace
+	}
+	s.endName(false)
+	return stateEndName(s, c)
+}
+
+// stateInNameDot is the state after reading '.' inside an identifier,
+// which begins the next part of an external or field reference, such as
+// the Type of Module.Type or the &id of TYPE-IDENTIFIER.&id. s.resume is
+// the state of the identifier.
+func stateInNameDot(s *scanner, c byte) int {
+	if c == '&' {
+		s.nameChar(c)
+		s.step = stateInNameAmpersand
+		return scanContinue
+	}
+	if isNameChar(c) {
+		s.nameChar(c)
+		s.step = s.resume
+		return scanContinue
+	}
+	return s.error(c, "after '.' in identifier", "letter", "digit", "'&'")
+}
+
+// stateInNameAmpersand is the state after reading the '&' that begins a
+// field reference inside an identifier.
+func stateInNameAmpersand(s *scanner, c byte) int {
+	if isLetter(c) {
+		s.nameChar(c)
+		s.step = s.resume
+		return scanContinue
+	}
+	return s.error(c, "in field reference", "letter")
+}
+
+// stateEndName is the state after an identifier in element position, or at
+// the beginning of a top-level value. The byte that follows decides what
+// the identifier was, unless it was CONTAINING, which a value follows.
+func stateEndName(s *scanner, c byte) int {
+	n := len(s.parseState)
+	if s.isContaining() {
+		s.pending = false
+		s.step = stateBeginValue
+		if n > 0 {
+			s.parseState[n-1] = parseComponentValue
+		}
+		return stateBeginValue(s, c)
+	}
+	s.step = stateEndName
+	// The identifier stands for a value unless it names a component,
+	// which a value follows, or a value assignment, which a type follows.
+	// A '-' begins a comment, after which this state resumes, or in
+	// element position a negative number, whose first digit it resumes
+	// with.
+	value := n == 0 && !isLetter(c) || n > 0 && (c == ',' || c == '}')
+	if !isSpace(c) && c != '-' && s.checkPending(c, value) == scanError {
+		return scanError
+	}
+	if n == 0 {
+		// A lone identifier is a complete top-level value, unless a type
+		// reference or a CHOICE value follows.
+		s.endTop = true
+	}
+	if isSpace(c) {
+		return scanSkipSpace
+	}
+	if c == ':' {
+		s.endTop = false
+		s.names[n].alternative = append(s.names[n].alternative[:0], s.name...)
+		if n > 0 {
+			// The value of the alternative is the whole element, not the
+			// first arc of an OBJECT IDENTIFIER.
+			s.parseState[n-1] = parseComponentValue
+		}
+		s.step = stateBeginValue
+		return scanChoiceTag
+	}
+	if n == 0 {
+		if c == '-' {
+			return s.beginComment(stateEndName)
+		}
+		if isLetter(c) {
+			s.endTop = false
+			s.names[0].component = append(s.names[0].component[:0], s.name...)
+			s.name = s.name[:0]
+			s.recordName(c)
+			s.step = stateInTypeReference
+			return scanBeginTypeReference
+		}
+		return stateEndValue(s, c)
+	}
+	switch c {
+	case ',', '}':
+		// The identifier was the element value itself.
+		return stateEndValue(s, c)
+	case '-':
+		return s.beginCommentOrNumber(stateEndName)
+	}
+	// The identifier named a component; c begins its value.
+	s.parseState[n-1] = parseComponentValue
+	s.names[n].component = append(s.names[n].component[:0], s.name...)
+	return stateBeginValue(s, c)
+}
+
+// stateInTypeReference is the state inside the type of a value assignment.
+func stateInTypeReference(s *scanner, c byte) int {
+	if isNameChar(c) || c == '.' {
+		s.recordName(c)
+		return scanContinue
+	}
+	if c == '-' {
+		s.recordName(c)
+		s.step = stateInTypeReferenceHyphen
+		return scanContinue
+	}
+	if s.checkName(true, false, false) == scanError {
+		return scanError
+	}
+	return stateEndTypeReference(s, c)
+}
+
+// stateInTypeReferenceHyphen is the state after reading '-' inside the
+// type of a value assignment.
+func stateInTypeReferenceHyphen(s *scanner, c byte) int {
+	if isNameChar(c) {
+		s.recordName(c)
+		s.step = stateInTypeReference
+		return scanContinue
+	}
+	if c == '-' {
+		if s.dialect == DialectStrict {
+			s.name = s.name[:len(s.name)-1]
+		}
+		if s.checkName(true, false, false) == scanError {
+			return scanError
+		}
+		s.resume = stateEndTypeReference
+		s.step = stateInComment
+		s.run = '-'
+		return scanSkipSpace
+	}
+	if s.checkName(true, false, false) == scanError {
+		return scanError
+	}
+	return stateEndTypeReference(s, c)
+}
+
+// stateEndTypeReference is the state after a word of the type of a value
+// assignment, such as "OCTET" in "value OCTET STRING ::= '00'H".
+func stateEndTypeReference(s *scanner, c byte) int {
+	s.step = stateEndTypeReference
+	if isSpace(c) {
+		return scanSkipSpace
+	}
+	if c == '-' {
+		return s.beginComment(stateEndTypeReference)
+	}
+	if isLetter(c) {
+		s.name = s.name[:0]
+		s.recordName(c)
+		s.step = stateInTypeReference
+		return scanContinue
+	}
+	if c == ':' {
+		s.step = stateAssignColon
+		return scanContinue
+	}
+	return s.error(c, "after type reference", "type reference", `"::="`)
+}
+
+// stateAssignColon is the state after reading the first ':' of "::=".
+func stateAssignColon(s *scanner, c byte) int {
+	if c == ':' {
+		s.step = stateAssignColon2
+		return scanContinue
+	}
+	return s.error(c, "in assignment", "':'")
+}
+
+// stateAssignColon2 is the state after reading "::".
+func stateAssignColon2(s *scanner, c byte) int {
+	if c == '=' {
+		s.step = stateBeginValue
+		return scanAssignment
+	}
+	return s.error(c, "in assignment", "'='")
+}
+
+// stateInValueName is the state inside an identifier or keyword in value
+// position, such as NULL, TRUE or an enumerated value.
+func stateInValueName(s *scanner, c byte) int {
+	if isNameChar(c) {
+		s.nameChar(c)
+		return scanContinue
+	}
+	if c == '-' {
+		s.recordName(c)
+		s.step = stateInValueNameHyphen
+		return scanContinue
+	}
+	if c == '.' {
+		s.nameChar(c)
+		s.resume = stateInValueName
+		s.step = stateInNameDot
+		return scanContinue
+	}
+	s.endName(false)
+	return stateEndValueName(s, c)
+}
+
+// stateInValueNameHyphen is the state after reading '-' inside an
+// identifier in value position.
+func stateInValueNameHyphen(s *scanner, c byte) int {
+	if isNameChar(c) {
+		s.keyword = -1
+		s.nameChar(c)
+		s.step = stateInValueName
+		return scanContinue
+	}
+	if c == '-' {
+		s.endName(true)
+		s.resume = stateEndValueName
+		s.step = stateInComment
+		s.run = '-'
+		return scanSkipSpace
+	}
+	s.endName(false)
+	return stateEndValueName(s, c)
+}
+
+// stateEndValueName is the state after an identifier in value position.
+// It is a complete value unless a ':' makes it a CHOICE alternative or
+// the type of an open type value, or it is CONTAINING, which a value
+// follows.
+func stateEndValueName(s *scanner, c byte) int {
+	if s.isContaining() {
+		s.pending = false
+		s.step = stateBeginValue
+		return stateBeginValue(s, c)
+	}
+	s.step = stateEndValueName
+	if !isSpace(c) && c != '-' && s.checkPending(c, true) == scanError {
+		return scanError
+	}
+	if len(s.parseState) == 0 {
+		s.endTop = true
+	}
+	if isSpace(c) {
+		return scanSkipSpace
+	}
+	if c == ':' {
+		s.endTop = false
+		n := len(s.parseState)
+		s.names[n].alternative = append(s.names[n].alternative[:0], s.name...)
+		if n > 0 {
+			s.parseState[n-1] = parseComponentValue
+		}
+		s.step = stateBeginValue
+		return scanChoiceTag
+	}
+	if c == '-' {
+		return s.beginComment(stateEndValueName)
+	}
+	return stateEndValue(s, c)
+}
+
+// state1 is the state after reading a digit of the integer part of a
+// number, such as after reading `1` or `12`.
+func state1(s *scanner, c byte) int {
+	if isDigit(c) {
+		if s.dialect == DialectLenient {
+			s.recordName(c)
+		}
+		return scanContinue
+	}
+	if c == '.' {
+		s.step = stateDot
+		return scanContinue
+	}
+	if c == 'e' || c == 'E' {
+		s.step = stateE
+		return scanContinue
+	}
+	if s.beginDigitName(c) {
+		return scanContinue
+	}
+	return stateEndNumber(s, c)
+}
+
+// stateDot is the state after reading the integer and decimal point in a number,
+// such as after reading `1.`.
+func stateDot(s *scanner, c byte) int {
+	if isDigit(c) {
+		s.step = stateDot0
+		return scanContinue
+	}
+	return s.error(c, "after decimal point of number", "digit")
+}
+
+// stateDot0 is the state after reading the integer, decimal point, and subsequent
+// digits of a number, such as after reading `3.14`.
+func stateDot0(s *scanner, c byte) int {
+	if isDigit(c) {
+		return scanContinue
+	}
+	if c == 'e' || c == 'E' {
+		s.step = stateE
+		return scanContinue
+	}
+	return stateEndValue(s, c)
+}
+
+// stateE is the state after reading the mantissa and e in a number,
+// such as after reading `314e` or `0.314e`.
+func stateE(s *scanner, c byte) int {
+	if c == '+' || c == '-' {
+		s.step = stateESign
+		return scanContinue
+	}
+	return stateESign(s, c)
+}
+
+// stateESign is the state after reading the mantissa, e, and sign in a number,
+// such as after reading `314e-` or `0.314e+`.
+func stateESign(s *scanner, c byte) int {
+	if isDigit(c) {
+		s.step = stateE0
+		return scanContinue
+	}
+	return s.error(c, "in exponent of number", "digit")
+}
+
+// stateE0 is the state after reading the mantissa, e, optional sign,
+// and at least one digit of the exponent in a number,
+// such as after reading `314e-2` or `0.314e+1` or `3.14e0`.
+func stateE0(s *scanner, c byte) int {
+	if isDigit(c) {
+		return scanContinue
+	}
+	return stateEndValue(s, c)
+}
+
+// stateEndNumber is the state after an integer. A brace-delimited value
+// whose first element is a non-negative integer may continue with further
+// integers without separating commas: that is an OBJECT IDENTIFIER value
+// such as `{ 2 23 143 1 2 1 }`.
+func stateEndNumber(s *scanner, c byte) int {
+	n := len(s.parseState)
+	if n > 0 && !s.minus {
+		if ps := s.parseState[n-1]; ps == parseFirstElement || ps == parseObjectIdentifier {
+			s.step = stateEndNumber
+			if isSpace(c) {
+				return scanSkipSpace
+			}
+			if c == '-' {
+				return s.beginComment(stateEndNumber)
+			}
+			if isDigit(c) {
+				s.parseState[n-1] = parseObjectIdentifier
+				s.element = false
+				s.step = state1
+				return scanBeginLiteral
+			}
+		}
+	}
+	return stateEndValue(s, c)
+}
+
+// stateInHexadecimalString is the state after reading the opening quote of
+// a bstring or hstring, whose digits cannot be told apart until the closing
+// quote and its B or H suffix. White space between the digits, as in a long
+// hstring broken over several lines, is part of the literal.
+func stateInHexadecimalString(s *scanner, c byte) int {
+	if c == '\'' {
+		s.step = stateEndHexadecimalString
+		s.run = 0
+		return scanContinue
+	}
+	if c == '0' || c == '1' || isSpace(c) {
+		return scanContinue
+	}
+	if isHexDigit(c) {
+		s.binary = false
+		return scanContinue
+	}
+	return s.error(c, "in bstring or hstring", "hexadecimal digit", "closing quote")
+}
+
+// stateEndHexadecimalString is the state after reading the closing quote of
+// a bstring or hstring.
+func stateEndHexadecimalString(s *scanner, c byte) int {
+	switch c {
+	case 'H':
+		s.step = stateEndValue
+		return scanContinue
+	case 'B':
+		if !s.binary {
+			return s.error(c, "after hstring digits", "'H'")
+		}
+		s.step = stateEndValue
+		return scanContinue
+	}
+	return s.error(c, "after closing quote of bstring or hstring", "'H'", "'B'")
+}
+
+// stateInCString is the state after reading `"`. A cstring may span
+// several lines, and holds tabs as spacing.
+func stateInCString(s *scanner, c byte) int {
+	if c == '"' {
+		s.step = stateInCStringQuote
+		s.run = 0
+		return scanContinue
+	}
+	if c < 0x20 && c != '\t' && c != '\n' && c != '\r' {
+		return s.error(c, "in cstring")
+	}
+	return scanContinue
+}
+
+// stateInCStringQuote is the state after reading a `"` in a cstring, which
+// either closes it or, doubled, stands for a quotation mark.
+func stateInCStringQuote(s *scanner, c byte) int {
+	if c == '"' {
+		s.step = stateInCString
+		s.run = '"'
+		return scanContinue
+	}
+	return stateEndValue(s, c)
+}
+
+// unquoteCString returns the characters of the cstring item, quotes
+// included, by the rules of X.680: a doubled quotation mark stands for
+// one, and the line ends of a cstring spanning several lines are left out
+// along with the spacing before and after them.
+func unquoteCString(item []byte) string {
+	item = item[1 : len(item)-1]
+	if bytes.IndexByte(item, '"') < 0 && bytes.IndexAny(item, "\r\n") < 0 {
+		return string(item)
+	}
+	b := make([]byte, 0, len(item))
+	for i := 0; i < len(item); i++ {
+		switch c := item[i]; c {
+		case '"':
+			// The scanner only lets doubled quotation marks through.
+			b = append(b, c)
+			i++
+		case '\r', '\n':
+			b = bytes.TrimRight(b, " \t")
+			for i+1 < len(item) && isSpace(item[i+1]) {
+				i++
+			}
+		default:
+			b = append(b, c)
+		}
+	}
+	return string(b)
+}
+
+// stateEndValue is the state after completing a value,
+// such as after reading `{}` or `TRUE` or `'0A'H`.
+func stateEndValue(s *scanner, c byte) int {
+	n := len(s.parseState)
+	if n == 0 {
+		// Completed top-level before the current byte.
+		s.step = stateEndTop
+		s.endTop = true
+		return stateEndTop(s, c)
+	}
+	if isSpace(c) {
+		s.step = stateEndValue
+		return scanSkipSpace
+	}
+	if c == '-' {
+		return s.beginComment(stateEndValue)
+	}
+	ps := s.parseState[n-1]
+	switch ps {
+	case parseFirstElement, parseElement, parseComponentValue:
+		if c == ',' {
+			s.parseState[n-1] = parseElement
+			s.resetNames(n)
+			s.step = stateBeginElement
+			return scanObjectValue
+		}
+		if c == '}' {
+			s.popParseState()
+			return scanEndObject
+		}
+		return s.error(c, "after value", "','", "'}'")
+	case parseObjectIdentifier:
+		if c == '}' {
+			s.popParseState()
+			return scanEndObject
+		}
+		return s.error(c, "after OBJECT IDENTIFIER component", "number", "'}'")
+	}
+	return s.error(c, "after value")
+}
+
+// stateEndTop is the state after finishing the top-level value,
+// such as after reading the closing brace of a value assignment.
+// Only space, comments and, when allowed, further top-level values
+// may follow.
+func stateEndTop(s *scanner, c byte) int {
+	if isSpace(c) {
+		return scanSkipSpace
+	}
+	if c == '-' {
+		return s.beginComment(stateEndTop)
+	}
+	if !s.allowMultipleTopValues {
+		return s.errorOf(ErrTrailingData, c, "after top-level value", "end of input")
+	}
+	return scanEnd
+}
+
+// stateError is the state after reaching a syntax error,
+// such as after reading `{ a 1 ]` or `5.1.2`.
+func stateError(s *scanner, c byte) int {
+	return scanError
+}
+
+// error records an error and switches to the error state. expected lists
+// what could have come in place of c, if the state knows.
+func (s *scanner) error(c byte, context string, expected ...string) int {
+	s.step = stateError
+	s.err = &SyntaxError{
+		msg:      "invalid character " + quoteChar(c) + " " + context,
+		Offset:   s.bytes,
+		Context:  s.context(),
+		Expected: expected,
+	}
+	return scanError
+}
+
+// errorOf is like error but records err as the cause of the error.
+func (s *scanner) errorOf(err error, c byte, context string, expected ...string) int {
+	op := s.error(c, context, expected...)
+	s.err.(*SyntaxError).err = err
+	return op
+}
+
+// context describes the part of the value that the scanner is in, by the
+// innermost parse state and the names of the values around it.
+func (s *scanner) context() string {
+	n := len(s.parseState)
+	if v := s.valueOf(n); v != "" || n == 0 {
+		return v
+	}
+	outer := s.valueOf(n - 1)
+	switch s.parseState[n-1] {
+	case parseComponentValue:
+		// The value of a CONTAINING in element position.
+		return "contained value"
+	case parseObjectIdentifier:
+		if outer != "" {
+			return "OBJECT IDENTIFIER " + outer
+		}
+		return "OBJECT IDENTIFIER value"
+	}
+	if outer != "" {
+		return "element of the " + outer
+	}
+	return "element of brace-delimited value"
+}
+
+// valueOf describes the value at depth i by its names, such as "value of
+// component 'efFileSize'" or "value assignment 'header'", or returns ""
+// if it has none.
+func (s *scanner) valueOf(i int) string {
+	names := s.names[i]
+	var v string
+	switch {
+	case len(names.alternative) > 0 && isUpper(names.alternative[0]):
+		v = "value of type '" + string(names.alternative) + "'"
+	case len(names.alternative) > 0:
+		v = "value of CHOICE alternative '" + string(names.alternative) + "'"
+	}
+	switch {
+	case len(names.component) == 0:
+	case i == 0 && v == "":
+		return "value assignment '" + string(names.component) + "'"
+	case i == 0:
+		return v + " of value assignment '" + string(names.component) + "'"
+	case v == "":
+		return "value of component '" + string(names.component) + "'"
+	default:
+		return v + " of component '" + string(names.component) + "'"
+	}
+	return v
+}
+
+// snippetLen is the number of bytes of input either side of a syntax error
+// kept in its Snippet.
+const snippetLen = 32
+
+// addSnippet sets the Snippet of err, if it is a *SyntaxError without one,
+// to the line of data around index i, where the scanner found the error,
+// and its Line to the number of that line, given that lines line ends
+// come before data in the input. It returns err.
+func addSnippet(err error, data []byte, i, lines int) error {
+	se, ok := err.(*SyntaxError)
+	if !ok || se.Snippet != "" {
+		return err
+	}
+	if i > len(data) {
+		i = len(data)
+	}
+	se.Line = lines + 1 + bytes.Count(data[:i], []byte{'\n'})
+	start := i - snippetLen
+	if start < 0 {
+		start = 0
+	}
+	if j := bytes.LastIndexAny(data[start:i], "\r\n"); j >= 0 {
+		start += j + 1
+	}
+	end := i + snippetLen
+	if end > len(data) {
+		end = len(data)
+	}
+	if j := bytes.IndexAny(data[i:end], "\r\n"); j >= 0 {
+		end = i + j
+	}
+	se.Snippet = string(data[start:end])
+	return err
+}
+
+// quoteChar formats c as a quoted character literal.
+func quoteChar(c byte) string {
+	// special cases - different from quoted strings
+	if c == '\'' {
+		return `'\''`
+	}
+	if c == '"' {
+		return `'"'`
+	}
+
+	// use quoted string with different quotation marks
+	s := strconv.Quote(string(c))
+	return "'" + s[1:len(s)-1] + "'"
+}
@@ -0,0 +1,429 @@
+package asn1go
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/openesim/asn1go/ast"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		in    string
+		valid bool
+	}{
+		{"5", true},
+		{"-1.5e3", true},
+		{"{ mantissa 314159, base 10, exponent -5 }", true},
+		{"PLUS-INFINITY", true},
+		{"{ a 1, b { 2 23 143 }, c alt : '0A'H, d \"x\" }", true},
+		{"v T ::= { } w T ::= 2", true},
+		{"-- only a comment", false},
+		{"", false},
+		{"{ a 1", false},
+		{"{ a 1 }}", false},
+		{"'0G'H", false},
+		{"'102'B", false},
+		{"\"unterminated", false},
+		{"{ a 1,, b 2 }", false},
+		{"{ 1 99999999999999999999999 }", true},
+		{"{ a : 1, b : 2 }", true},
+		{"{ a : 1 2 3 }", false},
+		{"{ x alt : 1 2 }", false},
+	}
+	for _, tt := range tests {
+		if got := Valid([]byte(tt.in)); got != tt.valid {
+			t.Errorf("Valid(%q) = %v, want %v", tt.in, got, tt.valid)
+		}
+	}
+}
+
+func TestChoiceNotObjectIdentifier(t *testing.T) {
+	// The integers after a CHOICE alternative are not the arcs of an
+	// OBJECT IDENTIFIER, so none of these lose the later ones.
+	convs := []struct {
+		name string
+		f    func([]byte) ([]byte, error)
+	}{
+		{"ToJSON", ToJSON},
+		{"ToYAML", ToYAML},
+		{"Canonicalize", Canonicalize},
+	}
+	for _, in := range []string{"{ a : 1 2 3 }", "v T ::= { a : 0 0 }", "{ x { y alt : 1 2 } }"} {
+		for _, c := range convs {
+			if _, err := c.f([]byte(in)); err == nil {
+				t.Errorf("%s(%q): no error", c.name, in)
+			} else if _, ok := err.(*SyntaxError); !ok {
+				t.Errorf("%s(%q): error %v, want SyntaxError", c.name, in, err)
+			}
+		}
+	}
+}
+
+// decodeDialect decodes the single value of in with the dialect dl.
+func decodeDialect(in string, dl Dialect) (interface{}, error) {
+	dec := NewDecoder(strings.NewReader(in))
+	dec.SetDialect(dl)
+	var v interface{}
+	err := dec.Decode(&v)
+	return v, err
+}
+
+func TestDialectStrict(t *testing.T) {
+	tests := []struct {
+		in      string
+		wantErr string // "" if in is valid
+	}{
+		{"v T ::= { a 1, b-c TRUE }", ""},
+		{"v Mod.T ::= { a 1 }", ""},
+		{"v T ::= Mod.value", ""},
+		{"v T ::= { a Mod.value }", ""},
+		{"v T ::= { Mod.value, 2 }", ""},
+		{"v T ::= { a Mod.value -- comment\n }", ""},
+		{"v T ::= Mod.Type : 1", ""},
+		{"v T ::= { a NULL, b TRUE }", ""},
+		{"v T ::= { a -1 }", ""},
+		{"v T ::= { a.b 1 }", `identifier "a.b" holds a dot`},
+		{"v T ::= { a.b -1 }", `identifier "a.b" holds a dot`},
+		{"v T ::= { a x.y }", `identifier "x.y" holds a dot`},
+		{"v T ::= a.b : 1", `identifier "a.b" holds a dot`},
+		{"v T ::= Mod.value.x", `identifier "Mod.value.x" holds a dot`},
+		{"v T ::= { A 1 }", `identifier "A" does not begin with a lower case letter`},
+		{"v T ::= { a_b 1 }", `identifier "a_b" holds an underscore`},
+		{"v T ::= { a- 1 }", `identifier "a-" ends with a hyphen`},
+		{"v t ::= 1", `type reference "t" does not begin with an upper case letter`},
+	}
+	for _, tt := range tests {
+		_, err := decodeDialect(tt.in, DialectStrict)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%q: %v", tt.in, err)
+		case tt.wantErr != "" && err == nil:
+			t.Errorf("%q: no error, want %q", tt.in, tt.wantErr)
+		case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+			t.Errorf("%q: error %q, want %q", tt.in, err, tt.wantErr)
+		}
+		if _, err := decodeDialect(tt.in, DialectDefault); err != nil && !strings.Contains(tt.in, "a- ") {
+			t.Errorf("%q under DialectDefault: %v", tt.in, err)
+		}
+	}
+}
+
+func TestDialectLenient(t *testing.T) {
+	tests := []struct {
+		in   string
+		want interface{}
+	}{
+		{"v T ::= 3gpp", "3gpp"},
+		{"v T ::= { a 3gpp-milenage }", OrderedObject{{"a", "3gpp-milenage"}}},
+		{"v T ::= { 1abc 2 }", OrderedObject{{"1abc", int64(2)}}},
+		{"v T ::= { 1abc 2, 3gpp x }", OrderedObject{{"1abc", int64(2)}, {"3gpp", "x"}}},
+		{"v T ::= { 1abc, 2 }", []interface{}{"1abc", int64(2)}},
+		{"v T ::= { 5g-sa 1abc : TRUE }", OrderedObject{{"5g-sa", map[string]interface{}{"1abc": true}}}},
+		{"v T ::= { 12_x { a 1 } }", OrderedObject{{"12_x", OrderedObject{{"a", int64(1)}}}}},
+		{"v T ::= { 2 23 143 }", ObjectIdentifier{2, 23, 143}},
+		{"v T ::= { -1, 2e3, 1.5 }", []interface{}{int64(-1), float64(2000), 1.5}},
+	}
+	for _, tt := range tests {
+		got, err := decodeDialect(tt.in, DialectLenient)
+		if err != nil {
+			t.Errorf("%q: %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+	for _, in := range []string{
+		"v T ::= { 2 23abc }",
+		"v T ::= { -1abc 2 }",
+		"v T ::= { 1e5abc 2 }",
+	} {
+		if _, err := decodeDialect(in, DialectLenient); err == nil {
+			t.Errorf("%q: no error", in)
+		}
+	}
+}
+
+func TestValidReport(t *testing.T) {
+	tests := []struct {
+		in       string
+		values   int
+		maxDepth int
+		offsets  []int64
+		ok       bool
+	}{
+		{"5", 1, 0, []int64{0}, true},
+		{"  -- c\n -5  x T ::= { a { b { 1 2 } } }", 2, 3, []int64{8, 12}, true},
+		{"v T ::= { } w T ::= 2", 2, 1, []int64{0, 12}, true},
+		{"{ a 1 } { b", 2, 1, []int64{0, 8}, false},
+		{"{ a 1 }} 5", 1, 1, []int64{0}, false},
+		{"", 0, 0, nil, false},
+	}
+	for _, tt := range tests {
+		r := ValidReport([]byte(tt.in))
+		if r.Values != tt.values || r.MaxDepth != tt.maxDepth || !reflect.DeepEqual(r.Offsets, tt.offsets) {
+			t.Errorf("ValidReport(%q) = %d values, depth %d, at %v, want %d, %d, %v", tt.in, r.Values, r.MaxDepth, r.Offsets, tt.values, tt.maxDepth, tt.offsets)
+		}
+		if (r.Err == nil) != tt.ok {
+			t.Errorf("ValidReport(%q): error %v", tt.in, r.Err)
+		}
+		if valid := Valid([]byte(tt.in)); valid != tt.ok {
+			t.Errorf("Valid(%q) = %v, want %v as ValidReport", tt.in, valid, tt.ok)
+		}
+	}
+}
+
+func TestValidReader(t *testing.T) {
+	large := strings.Repeat("v T ::= { a '00'H, b \"x\" }\n", 5000)
+	tests := []struct {
+		in    string
+		valid bool
+	}{
+		{"5", true},
+		{"v T ::= { a 1 }\nw T ::= 2", true},
+		{large, true},
+		{"", false},
+		{"-- only a comment", false},
+		{"{ a 1", false},
+		{"5 { a 1,, }", false},
+		{large + "{ a 1 }}", false},
+	}
+	for _, tt := range tests {
+		err := ValidReader(iotest.HalfReader(strings.NewReader(tt.in)))
+		if tt.valid && err != nil {
+			t.Errorf("ValidReader(%.20q): %v", tt.in, err)
+		} else if _, ok := err.(*SyntaxError); !tt.valid && !ok {
+			t.Errorf("ValidReader(%.20q): error %v, want SyntaxError", tt.in, err)
+		}
+		if valid := Valid([]byte(tt.in)); valid != tt.valid {
+			t.Errorf("Valid(%.20q) = %v, want %v as ValidReader", tt.in, valid, tt.valid)
+		}
+	}
+	failed := errors.New("read failed")
+	if err := ValidReader(iotest.ErrReader(failed)); err != failed {
+		t.Errorf("ValidReader of a failing reader: error %v, want %v", err, failed)
+	}
+}
+
+func TestSyntaxErrorCause(t *testing.T) {
+	decode := func(in string, l Limits) error {
+		dec := NewDecoder(strings.NewReader(in))
+		dec.SetLimits(l)
+		var v interface{}
+		return dec.Decode(&v)
+	}
+	unmarshal := func(in string) error {
+		var v interface{}
+		return Unmarshal([]byte(in), &v)
+	}
+	unmarshalDER := func(in []byte) error {
+		var v int
+		return UnmarshalDER(in, &v)
+	}
+	deep := strings.Repeat("{ ", maxNestingDepth+1) + strings.Repeat("}", maxNestingDepth+1)
+	causes := []error{ErrUnexpectedEOF, ErrDepthExceeded, ErrTrailingData, ErrLimitExceeded}
+	tests := []struct {
+		name string
+		err  error
+		want error // cause, or nil
+	}{
+		{"Unmarshal of a truncated value", unmarshal("{ a 1"), ErrUnexpectedEOF},
+		{"Unmarshal of an empty input", unmarshal(""), ErrUnexpectedEOF},
+		{"Unmarshal of a deep value", unmarshal(deep), ErrDepthExceeded},
+		{"Unmarshal of a bad value", unmarshal("{ a 1,, }"), nil},
+		{"UnmarshalSingle of two values", UnmarshalSingle([]byte("1 2"), new(interface{})), ErrTrailingData},
+		{"UnmarshalReader of a truncated value", UnmarshalReader(strings.NewReader("{ a 1"), new(interface{})), ErrUnexpectedEOF},
+		{"ValidReader of a truncated value", ValidReader(strings.NewReader("v T ::= {")), ErrUnexpectedEOF},
+		{"ParseValue of two values", func() error { _, err := ParseValue([]byte("1 2")); return err }(), ErrTrailingData},
+		{"Decode of a truncated value", decode("{ a 1", Limits{}), ErrUnexpectedEOF},
+		{"Decode beyond MaxDepth", decode("{ { { } } }", Limits{MaxDepth: 2}), ErrDepthExceeded},
+		{"Decode beyond MaxBytes", decode("{ a 1, b 2 }", Limits{MaxBytes: 4}), ErrLimitExceeded},
+		{"Decode beyond MaxLiteral", decode(`"abcdef"`, Limits{MaxLiteral: 3}), ErrLimitExceeded},
+		{"Decode beyond MaxElements", decode("{ 1, 2, 3 }", Limits{MaxElements: 2}), ErrLimitExceeded},
+		{"UnmarshalDER of a truncated tag", unmarshalDER(nil), ErrUnexpectedEOF},
+		{"UnmarshalDER of truncated contents", unmarshalDER([]byte{0x02, 0x02, 0x01}), ErrUnexpectedEOF},
+		{"UnmarshalDER with trailing data", unmarshalDER([]byte{0x02, 0x01, 0x01, 0x00}), ErrTrailingData},
+		{"UnmarshalDER of a non-minimal tag", unmarshalDER([]byte{0x1f, 0x02, 0x01, 0x01}), nil},
+	}
+	for _, tt := range tests {
+		if tt.err == nil {
+			t.Errorf("%s: no error", tt.name)
+			continue
+		}
+		var se *SyntaxError
+		var de *DERSyntaxError
+		if tt.want != io.ErrUnexpectedEOF && !errors.As(tt.err, &se) && !errors.As(tt.err, &de) {
+			t.Errorf("%s: error %T, want SyntaxError or DERSyntaxError", tt.name, tt.err)
+		}
+		// The cause survives wrapping by the caller.
+		wrapped := fmt.Errorf("loading profile: %w", tt.err)
+		for _, cause := range causes {
+			if got, want := errors.Is(wrapped, cause), cause == tt.want; got != want {
+				t.Errorf("%s: errors.Is(%q, %q) = %v, want %v", tt.name, tt.err, cause, got, want)
+			}
+		}
+	}
+}
+
+func TestSyntaxErrorDetails(t *testing.T) {
+	long := strings.Repeat("0123456789", 4)
+	tests := []struct {
+		in       string
+		line     int
+		context  string
+		expected []string
+		snippet  string
+	}{
+		{"{ a 1 ]", 1, "value of component 'a'", []string{"','", "'}'"}, "{ a 1 ]"},
+		{"v T ::= {\n  a 1,\n  b 1.x\n}", 3, "value of component 'b'", []string{"digit"}, "  b 1.x"},
+		{"{ a '0G'H }", 1, "value of component 'a'", []string{"hexadecimal digit", "closing quote"}, "{ a '0G'H }"},
+		{"{ a '01' }", 1, "value of component 'a'", []string{"'H'", "'B'"}, "{ a '01' }"},
+		{"v T : 5", 1, "value assignment 'v'", []string{"':'"}, "v T : 5"},
+		{"v T ::= { a 1 } 2", 1, "value assignment 'v'", []string{"end of input"}, "v T ::= { a 1 } 2"},
+		{"{ a 1,, }", 1, "element of brace-delimited value", []string{"value"}, "{ a 1,, }"},
+		{"{ a { 1 2 x } }", 1, "OBJECT IDENTIFIER value of component 'a'", []string{"number", "'}'"}, "{ a { 1 2 x } }"},
+		{"{ a alt : { b 1 x } }", 1, "value of component 'b'", []string{"','", "'}'"}, "{ a alt : { b 1 x } }"},
+		{"-x", 1, "", []string{"'-'", "digit"}, "-x"},
+		{"1.5e", 1, "", []string{"digit"}, "1.5e"},
+		{"{ a 1 }\r\n{ b ]", 2, "", []string{"end of input"}, "{ b ]"},
+		{"{ a 1", 1, "value of component 'a'", nil, "{ a 1"},
+		// The snippet keeps snippetLen bytes either side of the error.
+		{`{ a "` + long + `" ]` + long, 1, "value of component 'a'", []string{"','", "'}'"}, long[10:] + `" ]` + long[:31]},
+	}
+	for _, tt := range tests {
+		err := UnmarshalSingle([]byte(tt.in), new(interface{}))
+		se, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("UnmarshalSingle(%q): error %v, want SyntaxError", tt.in, err)
+			continue
+		}
+		if se.Line != tt.line || se.Context != tt.context || !reflect.DeepEqual(se.Expected, tt.expected) || se.Snippet != tt.snippet {
+			t.Errorf("UnmarshalSingle(%q): line %d, context %q, expected %q, snippet %q\nwant line %d, context %q, expected %q, snippet %q",
+				tt.in, se.Line, se.Context, se.Expected, se.Snippet, tt.line, tt.context, tt.expected, tt.snippet)
+		}
+		if want := " at line " + strconv.Itoa(tt.line); !strings.Contains(se.Error(), want) {
+			t.Errorf("UnmarshalSingle(%q): error %q, want it to contain %q", tt.in, se.Error(), want)
+		}
+		if len(tt.expected) > 0 && !strings.HasSuffix(se.Error(), ", expecting "+strings.Join(tt.expected, " or ")) {
+			t.Errorf("UnmarshalSingle(%q): error %q, want it to list %q", tt.in, se.Error(), tt.expected)
+		}
+	}
+}
+
+func TestValidReaderSyntaxErrorDetails(t *testing.T) {
+	// ValidReader counts the lines of the chunks before the error, and
+	// keeps no more of the line in the snippet than the chunk holds.
+	in := "v T ::= {\n  a 1,\n  b 1 ]\n}"
+	tests := []struct {
+		name    string
+		err     error
+		snippet string
+	}{
+		{"whole", ValidReader(strings.NewReader(in)), "  b 1 ]"},
+		{"one byte", ValidReader(iotest.OneByteReader(strings.NewReader(in))), "]"},
+	}
+	for _, tt := range tests {
+		se, ok := tt.err.(*SyntaxError)
+		if !ok {
+			t.Errorf("%s: ValidReader error %v, want SyntaxError", tt.name, tt.err)
+			continue
+		}
+		if se.Line != 3 || se.Snippet != tt.snippet || !reflect.DeepEqual(se.Expected, []string{"','", "'}'"}) {
+			t.Errorf("%s: ValidReader line %d, snippet %q, expected %q, want 3, %q, [',' '}']", tt.name, se.Line, se.Snippet, se.Expected, tt.snippet)
+		}
+	}
+	// At the end of the input, there is no chunk left for a snippet.
+	err := ValidReader(strings.NewReader("v T ::= {\n  a 1"))
+	if se, ok := err.(*SyntaxError); !ok || se.Line != 2 || se.Snippet != "" {
+		t.Errorf("ValidReader of a truncated value: error %#v, want SyntaxError at line 2 without snippet", err)
+	}
+}
+
+func TestCString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`""`, ""},
+		{`"a"`, "a"},
+		{`""""`, `"`},
+		{`""""""`, `""`},
+		{`"""a"`, `"a`},
+		{`"a"""`, `a"`},
+		{`"""a"""`, `"a"`},
+		{`"a ""b"" c"`, `a "b" c`},
+		{"\"a\tb\"", "a\tb"},
+		// The line ends of a cstring spanning several lines are left out
+		// with the spacing around them.
+		{"\"abc\n def\"", "abcdef"},
+		{"\"abc  \r\n\t  def\"", "abcdef"},
+		{"\"abc\n\n  def\"", "abcdef"},
+		{"\"\nabc\n\"", "abc"},
+		{"\"a\"\"\n  \"\"b\"", `a""b`},
+		{"\"\"\"\n\"\"\"", `""`},
+	}
+	for _, tt := range tests {
+		if !Valid([]byte(tt.in)) {
+			t.Errorf("Valid(%q) = false", tt.in)
+			continue
+		}
+		var s string
+		if err := Unmarshal([]byte(tt.in), &s); err != nil || s != tt.want {
+			t.Errorf("Unmarshal(%q) = %q, %v, want %q", tt.in, s, err, tt.want)
+		}
+		// As the last component of a value, where the closing quote is
+		// followed by the closing brace.
+		var v struct{ A, B string }
+		in := "{ a " + tt.in + ", b " + tt.in + "}"
+		if err := Unmarshal([]byte(in), &v); err != nil || v.A != tt.want || v.B != tt.want {
+			t.Errorf("Unmarshal(%q) = %q, %v, want %q twice", in, v, err, tt.want)
+		}
+		// Read a byte at a time, a doubled quotation mark spans reads.
+		dec := NewDecoder(iotest.OneByteReader(strings.NewReader(tt.in + " " + tt.in)))
+		for i := 0; i < 2; i++ {
+			s = ""
+			if err := dec.Decode(&s); err != nil || s != tt.want {
+				t.Errorf("Decoder(%q): value %d = %q, %v, want %q", tt.in, i, s, err, tt.want)
+			}
+		}
+		if n, err := ParseValue([]byte(tt.in)); err != nil {
+			t.Errorf("ParseValue(%q): %v", tt.in, err)
+		} else if sn, ok := n.(*ast.StringNode); !ok || sn.Value != tt.want {
+			t.Errorf("ParseValue(%q) = %#v, want StringNode %q", tt.in, n, tt.want)
+		}
+		if tok, err := NewDecoder(strings.NewReader(tt.in)).Token(); err != nil || tok != tt.want {
+			t.Errorf("Token(%q) = %#v, %v, want %q", tt.in, tok, err, tt.want)
+		}
+	}
+}
+
+func TestCStringError(t *testing.T) {
+	for _, in := range []string{
+		`"`,
+		`"a`,
+		`"a""`,
+		`"""`,
+		`"a"b"`,
+		`"a" "b`,
+		"\"a\x00b\"",
+		"\"a\x1bb\"",
+		"{ a \"b\"\"c }",
+	} {
+		if Valid([]byte(in)) {
+			t.Errorf("Valid(%q) = true", in)
+		}
+		var s interface{}
+		if err := Unmarshal([]byte(in), &s); err == nil {
+			t.Errorf("Unmarshal(%q) = %q, want error", in, s)
+		} else if _, ok := err.(*SyntaxError); !ok {
+			t.Errorf("Unmarshal(%q): error %v, want SyntaxError", in, err)
+		}
+	}
+}
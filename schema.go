@@ -0,0 +1,359 @@
+package asn1go
+
+import (
+	"strconv"
+	"strings"
+)
+
+// A Class is the class of an ASN.1 tag.
+type Class uint8
+
+// Tag classes.
+const (
+	ClassUniversal Class = iota
+	ClassApplication
+	ClassContextSpecific
+	ClassPrivate
+)
+
+var classNames = [...]string{"UNIVERSAL", "APPLICATION", "", "PRIVATE"}
+
+func (c Class) String() string {
+	if c == ClassContextSpecific {
+		return "CONTEXT"
+	}
+	if int(c) < len(classNames) {
+		return classNames[c]
+	}
+	return "Class(" + strconv.Itoa(int(c)) + ")"
+}
+
+// Universal tag numbers of the built-in types.
+const (
+	TagBoolean         = 1
+	TagInteger         = 2
+	TagBitString       = 3
+	TagOctetString     = 4
+	TagNull            = 5
+	TagOID             = 6
+	TagReal            = 9
+	TagEnumerated      = 10
+	TagEmbeddedPDV     = 11
+	TagUTF8String      = 12
+	TagRelativeOID     = 13
+	TagTime            = 14
+	TagSequence        = 16
+	TagSet             = 17
+	TagNumericString   = 18
+	TagPrintableString = 19
+	TagIA5String       = 22
+	TagUTCTime         = 23
+	TagGeneralizedTime = 24
+	TagVisibleString   = 26
+	TagDate            = 31
+	TagTimeOfDay       = 32
+	TagDateTime        = 33
+	TagDuration        = 34
+	TagOIDIRI          = 35
+	TagRelativeOIDIRI  = 36
+)
+
+// A Tag identifies the type of an encoded value in the binary encoding
+// rules, such as [UNIVERSAL 2] for INTEGER or [1] for a context-specific
+// tag.
+type Tag struct {
+	Class  Class
+	Number int
+}
+
+// String returns the tag in ASN.1 notation, such as "[APPLICATION 3]".
+func (t Tag) String() string {
+	if t.Class == ClassContextSpecific {
+		return "[" + strconv.Itoa(t.Number) + "]"
+	}
+	return "[" + t.Class.String() + " " + strconv.Itoa(t.Number) + "]"
+}
+
+// A Kind is the built-in type an ASN.1 type is based on.
+type Kind uint8
+
+// Kinds of types.
+const (
+	KindInvalid Kind = iota
+	KindBoolean
+	KindInteger
+	KindBitString
+	KindOctetString
+	KindNull
+	KindObjectIdentifier
+	KindReal
+	KindEnumerated
+	KindUTF8String
+	KindNumericString
+	KindPrintableString
+	KindIA5String
+	KindVisibleString
+	KindUTCTime
+	KindGeneralizedTime
+	KindSequence
+	KindSequenceOf
+	KindSet
+	KindSetOf
+	KindChoice
+	KindAny // an open type, whose values are carried in their encoding
+	KindRelativeOID
+	KindOIDIRI
+	KindRelativeOIDIRI
+	KindTime
+	KindDate
+	KindTimeOfDay
+	KindDateTime
+	KindDuration
+)
+
+var kindInfo = [...]struct {
+	name string
+	tag  int
+}{
+	KindInvalid:          {"invalid", -1},
+	KindBoolean:          {"BOOLEAN", TagBoolean},
+	KindInteger:          {"INTEGER", TagInteger},
+	KindBitString:        {"BIT STRING", TagBitString},
+	KindOctetString:      {"OCTET STRING", TagOctetString},
+	KindNull:             {"NULL", TagNull},
+	KindObjectIdentifier: {"OBJECT IDENTIFIER", TagOID},
+	KindReal:             {"REAL", TagReal},
+	KindEnumerated:       {"ENUMERATED", TagEnumerated},
+	KindUTF8String:       {"UTF8String", TagUTF8String},
+	KindNumericString:    {"NumericString", TagNumericString},
+	KindPrintableString:  {"PrintableString", TagPrintableString},
+	KindIA5String:        {"IA5String", TagIA5String},
+	KindVisibleString:    {"VisibleString", TagVisibleString},
+	KindUTCTime:          {"UTCTime", TagUTCTime},
+	KindGeneralizedTime:  {"GeneralizedTime", TagGeneralizedTime},
+	KindSequence:         {"SEQUENCE", TagSequence},
+	KindSequenceOf:       {"SEQUENCE OF", TagSequence},
+	KindSet:              {"SET", TagSet},
+	KindSetOf:            {"SET OF", TagSet},
+	KindChoice:           {"CHOICE", -1},
+	KindAny:              {"ANY", -1},
+	KindRelativeOID:      {"RELATIVE-OID", TagRelativeOID},
+	KindOIDIRI:           {"OID-IRI", TagOIDIRI},
+	KindRelativeOIDIRI:   {"RELATIVE-OID-IRI", TagRelativeOIDIRI},
+	KindTime:             {"TIME", TagTime},
+	KindDate:             {"DATE", TagDate},
+	KindTimeOfDay:        {"TIME-OF-DAY", TagTimeOfDay},
+	KindDateTime:         {"DATE-TIME", TagDateTime},
+	KindDuration:         {"DURATION", TagDuration},
+}
+
+// String returns the ASN.1 name of the kind, such as "OCTET STRING".
+func (k Kind) String() string {
+	if int(k) < len(kindInfo) {
+		return kindInfo[k].name
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// universalTag returns the universal tag number of types of kind k, or -1
+// for CHOICE and open types, which have no tag of their own.
+func (k Kind) universalTag() int {
+	if int(k) < len(kindInfo) {
+		return kindInfo[k].tag
+	}
+	return -1
+}
+
+// A Type describes an ASN.1 type for the schema-driven encoding rules,
+// such as EncodeDER.
+type Type struct {
+	Name string // type reference the type is known by, or ""
+	Kind Kind
+
+	// Tags are the tags of a tagged type, outermost first. An implicit
+	// tag replaces the tag that follows it; an explicit one is encoded in
+	// addition to it.
+	Tags []TypeTag
+
+	Components []Component   // of a SEQUENCE, SET or CHOICE
+	Elem       *Type         // element type of a SEQUENCE OF or SET OF
+	Named      []NamedNumber // named values of an INTEGER or ENUMERATED, or named bits of a BIT STRING
+
+	// Extensible is set for a SEQUENCE, SET, CHOICE or ENUMERATED type
+	// with an extension marker, whose values may have extension additions
+	// that a later version of the type defines. The schema-driven
+	// decoders keep those they do not know as Extensions.
+	Extensible bool
+
+	// Size is the SIZE constraint of a string type or of a SEQUENCE OF
+	// or SET OF, and Range the value range of an INTEGER, or nil if the
+	// type has none. They shape the PER encoding of the values.
+	Size  *Range
+	Range *Range
+
+	// From is the permitted alphabet of a character string type, as in
+	// IA5String (FROM ("0".."9")), or nil if it has none. It does not
+	// shape any encoding; CheckConstraints enforces it.
+	From []CharRange
+
+	// Contains is the type of the value an OCTET STRING or BIT STRING
+	// holds the encoding of, as in OCTET STRING (CONTAINING Inner), or nil
+	// if it has no contents constraint. EncodedBy is the OBJECT IDENTIFIER
+	// of the encoding rules of ENCODED BY, or nil for those of the value
+	// around it. The codecs encode the contained value in DER, which is
+	// also a BER encoding.
+	Contains  *Type
+	EncodedBy ObjectIdentifier
+}
+
+// A Range is the range of a constraint, such as the 1..8 of
+// SIZE (1..8) or the 0..MAX of INTEGER (0..MAX).
+type Range struct {
+	Min, Max     int64
+	NoMin, NoMax bool // the bound is MIN or MAX
+	Extensible   bool // the range is followed by an extension marker
+}
+
+// String returns the range in ASN.1 notation, such as "0..255".
+func (r *Range) String() string {
+	lower, upper := "MIN", "MAX"
+	if !r.NoMin {
+		lower = strconv.FormatInt(r.Min, 10)
+	}
+	if !r.NoMax {
+		upper = strconv.FormatInt(r.Max, 10)
+	}
+	s := lower + ".." + upper
+	if !r.NoMin && !r.NoMax && r.Min == r.Max {
+		s = lower
+	}
+	if r.Extensible {
+		s += ", ..."
+	}
+	return s
+}
+
+// contains reports whether n is within the bounds of r.
+func (r *Range) contains(n int64) bool {
+	return (r.NoMin || n >= r.Min) && (r.NoMax || n <= r.Max)
+}
+
+// A CharRange is a range of characters of a permitted alphabet, such as
+// the "0".."9" of FROM ("0".."9"), or a single character if Lo and Hi are
+// the same.
+type CharRange struct {
+	Lo, Hi rune
+}
+
+// String returns the range in ASN.1 notation, such as "0".."9".
+func (r CharRange) String() string {
+	s := cstringChar(r.Lo)
+	if r.Hi != r.Lo {
+		s += ".." + cstringChar(r.Hi)
+	}
+	return s
+}
+
+// cstringChar returns the cstring of the single character c.
+func cstringChar(c rune) string {
+	if c == '"' {
+		return `""""`
+	}
+	return `"` + string(c) + `"`
+}
+
+// A TypeTag is one tag of a tagged type, such as [1] EXPLICIT.
+type TypeTag struct {
+	Tag
+	Explicit bool
+}
+
+// A Component is a component of a SEQUENCE or SET, or an alternative of a
+// CHOICE.
+type Component struct {
+	Name     string // identifier
+	Type     *Type
+	Optional bool
+
+	// Extension is set for an extension addition, a component or
+	// alternative after the extension marker. Group numbers the version
+	// brackets [[ ]] the addition is written in, from 1, or is 0 for an
+	// addition of its own.
+	Extension bool
+	Group     int
+
+	// Default is the value of a DEFAULT component, in the form Unmarshal
+	// decodes its value notation to, or nil.
+	Default interface{}
+}
+
+// A NamedNumber is a named value of an INTEGER or ENUMERATED type, such as
+// the enabled(1) in ENUMERATED { disabled(0), enabled(1) }, or a named bit
+// of a BIT STRING type, numbered from the first bit, 0.
+type NamedNumber struct {
+	Name      string
+	Value     int64
+	Extension bool // the value is an extension addition of an ENUMERATED
+}
+
+// Tag returns the outermost tag of values of type t. It reports false for
+// an untagged CHOICE or open type, whose tag is that of its value.
+func (t *Type) Tag() (Tag, bool) {
+	if len(t.Tags) > 0 {
+		return t.Tags[0].Tag, true
+	}
+	if n := t.Kind.universalTag(); n >= 0 {
+		return Tag{ClassUniversal, n}, true
+	}
+	return Tag{}, false
+}
+
+// Component returns the component or alternative of t with identifier
+// name, or nil if there is none.
+func (t *Type) Component(name string) *Component {
+	for i := range t.Components {
+		if t.Components[i].Name == name {
+			return &t.Components[i]
+		}
+	}
+	return nil
+}
+
+// NamedValue returns the number of the named value name of an INTEGER or
+// ENUMERATED type.
+func (t *Type) NamedValue(name string) (int64, bool) {
+	return namedValue(t.Named, name)
+}
+
+// namedValue returns the number of the named value or bit name of named.
+func namedValue(named []NamedNumber, name string) (int64, bool) {
+	for _, n := range named {
+		if n.Name == name {
+			return n.Value, true
+		}
+	}
+	return 0, false
+}
+
+// String returns the type reference of t, or a description of t in ASN.1
+// notation if it has none.
+func (t *Type) String() string {
+	if t.Name != "" {
+		return t.Name
+	}
+	var b strings.Builder
+	for _, tt := range t.Tags {
+		b.WriteString(tt.Tag.String())
+		if tt.Explicit {
+			b.WriteString(" EXPLICIT ")
+		} else {
+			b.WriteString(" IMPLICIT ")
+		}
+	}
+	b.WriteString(t.Kind.String())
+	if (t.Kind == KindSequenceOf || t.Kind == KindSetOf) && t.Elem != nil {
+		b.WriteByte(' ')
+		b.WriteString(t.Elem.String())
+	}
+	return b.String()
+}
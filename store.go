@@ -0,0 +1,551 @@
+package asn1go
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var numberType = reflect.TypeOf(Number(""))
+
+// A storer stores the values the schema-driven decoders decode in Go
+// values, as DecodeDER describes. Offsets are those of the encodings in
+// the input, for error messages.
+type storer struct {
+	savedError error
+
+	// The struct and the fields leading to the value being decoded, for
+	// UnmarshalTypeErrors.
+	errorStruct reflect.Type
+	fieldStack  []string
+}
+
+// saveError saves the first err it is called with,
+// for reporting at the end of the decoding.
+func (s *storer) saveError(err error) {
+	if s.savedError == nil {
+		s.savedError = err
+	}
+}
+
+// typeError saves an UnmarshalTypeError for a value of type t at offset
+// off that does not fit v.
+func (s *storer) typeError(t *Type, off int, v reflect.Value) {
+	err := &UnmarshalTypeError{Value: t.String(), Type: v.Type(), Offset: int64(off)}
+	if s.errorStruct != nil {
+		err.Struct = s.errorStruct.Name()
+		err.Field = strings.Join(s.fieldStack, ".")
+	}
+	s.saveError(err)
+}
+
+// target walks down v allocating pointers as needed, until it gets to a
+// non-pointer. Like indirect, it decodes into the value an interface
+// points to. An invalid v, for a value being skipped, stays invalid.
+func target(v reflect.Value) reflect.Value {
+	for v.IsValid() {
+		if v.Kind() == reflect.Interface && !v.IsNil() {
+			if e := v.Elem(); e.Kind() == reflect.Pointer && !e.IsNil() {
+				v = e
+				continue
+			}
+		}
+		if v.Kind() != reflect.Pointer {
+			break
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// isEmptyInterface reports whether v is an interface{} to store the
+// generic form of a value in.
+func isEmptyInterface(v reflect.Value) bool {
+	return v.Kind() == reflect.Interface && v.NumMethod() == 0
+}
+
+// store stores the value val of the primitive or open type t in v. val
+// is in the form stored in an interface: bool, int64, uint64 or *big.Int,
+// float64, BitString, []byte, ObjectIdentifier, RelativeOID, string or nil.
+func (s *storer) store(t *Type, off int, val interface{}, v reflect.Value) {
+	if !v.IsValid() {
+		return
+	}
+	if isEmptyInterface(v) {
+		if n, ok := val.(int64); ok && t.Kind == KindEnumerated {
+			if name, ok := t.nameOf(n); ok {
+				val = name
+			}
+		}
+		if val == nil {
+			v.Set(reflect.Zero(v.Type()))
+		} else {
+			v.Set(reflect.ValueOf(val))
+		}
+		return
+	}
+
+	ok := false
+	switch val := val.(type) {
+	case bool:
+		if ok = v.Kind() == reflect.Bool; ok {
+			v.SetBool(val)
+		}
+
+	case int64, uint64, *big.Int:
+		ok = storeInteger(t, val, v)
+
+	case float64:
+		switch v.Kind() {
+		case reflect.Float32, reflect.Float64:
+			if ok = !v.OverflowFloat(val); ok {
+				v.SetFloat(val)
+			}
+		}
+
+	case BitString:
+		switch {
+		case v.Type() == bitStringType:
+			v.Set(reflect.ValueOf(val))
+			ok = true
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+			v.SetBytes(val.Bytes)
+			ok = true
+		}
+
+	case []byte:
+		switch {
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+			v.SetBytes(val)
+			ok = true
+		case v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8:
+			if ok = v.Len() == len(val); ok {
+				reflect.Copy(v, reflect.ValueOf(val))
+			}
+		case v.Kind() == reflect.String && t.Kind == KindOctetString:
+			v.SetString(strings.ToUpper(hex.EncodeToString(val)))
+			ok = true
+		}
+
+	case ObjectIdentifier:
+		ok = storeObjectIdentifier(val, v)
+
+	case RelativeOID:
+		ok = storeObjectIdentifier(ObjectIdentifier(val), v)
+
+	case string:
+		switch {
+		case v.Type() == timeType && (t.Kind == KindUTCTime || t.Kind == KindGeneralizedTime):
+			tm, err := parseTime(t.Kind, val)
+			if err != nil {
+				s.saveError(err)
+				return
+			}
+			v.Set(reflect.ValueOf(tm))
+			ok = true
+		case isDateType(v.Type(), t.Kind):
+			dv, err := parseDate(t.Kind, val)
+			if err != nil {
+				s.saveError(err)
+				return
+			}
+			v.Set(reflect.ValueOf(dv))
+			ok = true
+		case v.Kind() == reflect.String:
+			v.SetString(val)
+			ok = true
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+			v.SetBytes([]byte(val))
+			ok = true
+		}
+
+	case nil:
+		switch v.Kind() {
+		case reflect.Bool:
+			v.SetBool(true)
+			ok = true
+		case reflect.Struct:
+			ok = v.NumField() == 0
+		}
+	}
+	if !ok {
+		s.typeError(t, off, v)
+	}
+}
+
+// storeInteger stores the INTEGER or ENUMERATED value val, an int64, a
+// uint64 or a *big.Int, in v.
+func storeInteger(t *Type, val interface{}, v reflect.Value) bool {
+	n, isInt := val.(int64)
+	u, isUint := val.(uint64)
+	b, isBig := val.(*big.Int)
+	if v.Type() == bigIntType {
+		switch {
+		case isInt:
+			b = big.NewInt(n)
+		case isUint:
+			b = new(big.Int).SetUint64(u)
+		}
+		bigIntOf(v).Set(b)
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !isInt || v.OverflowInt(n) {
+			return false
+		}
+		v.SetInt(n)
+		return true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if isBig {
+			return false
+		}
+		if isInt {
+			if n < 0 {
+				return false
+			}
+			u = uint64(n)
+		}
+		if v.OverflowUint(u) {
+			return false
+		}
+		v.SetUint(u)
+		return true
+	case reflect.String:
+		if isInt {
+			if name, ok := t.nameOf(n); ok {
+				v.SetString(name)
+				return true
+			}
+		}
+		if v.Type() == numberType {
+			switch {
+			case isBig:
+				v.SetString(b.String())
+			case isUint:
+				v.SetString(strconv.FormatUint(u, 10))
+			default:
+				v.SetString(strconv.FormatInt(n, 10))
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// storeObjectIdentifier stores oid in the ObjectIdentifier or other slice
+// or array of integers v.
+func storeObjectIdentifier(oid ObjectIdentifier, v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice:
+		switch v.Type().Elem().Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			return false
+		}
+		v.Set(reflect.MakeSlice(v.Type(), len(oid), len(oid)))
+	case reflect.Array:
+		if v.Len() != len(oid) {
+			return false
+		}
+	default:
+		return false
+	}
+	for i, c := range oid {
+		e := v.Index(i)
+		switch e.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if e.OverflowInt(int64(c)) {
+				return false
+			}
+			e.SetInt(int64(c))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if e.OverflowUint(uint64(c)) {
+				return false
+			}
+			e.SetUint(uint64(c))
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// A composite is the struct or map the components of a SEQUENCE or SET
+// value are stored in.
+type composite struct {
+	v      reflect.Value // the struct or map, or invalid to skip the value
+	fields structFields  // of a struct v
+	iface  reflect.Value // interface to store a generic map v in when done
+}
+
+// composite returns the composite to store the components of a SEQUENCE
+// or SET value of type t at offset off in v.
+func (s *storer) composite(t *Type, off int, v reflect.Value) *composite {
+	ct := &composite{v: v}
+	switch {
+	case !v.IsValid():
+	case isEmptyInterface(v):
+		ct.v, ct.iface = reflect.ValueOf(map[string]interface{}{}), v
+	case v.Kind() == reflect.Struct && v.Type() != choiceValueType:
+		ct.fields = cachedTypeFields(v.Type())
+		if !ct.fields.choice {
+			break
+		}
+		fallthrough
+	case v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String:
+		s.typeError(t, off, v)
+		ct.v = reflect.Value{}
+	default:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+	}
+	return ct
+}
+
+// component stores the component c at offset off in ct, with decode
+// decoding its value into the Go value it is given.
+func (s *storer) component(ct *composite, c *Component, off int, decode func(reflect.Value)) {
+	v := ct.v
+	if !v.IsValid() {
+		decode(v)
+		return
+	}
+	if v.Kind() == reflect.Map {
+		elem := reflect.New(v.Type().Elem()).Elem()
+		decode(elem)
+		v.SetMapIndex(reflect.ValueOf(c.Name).Convert(v.Type().Key()), elem)
+		return
+	}
+
+	f := ct.fields.byName([]byte(c.Name))
+	if f == nil {
+		// No field for the component; check and skip it.
+		decode(reflect.Value{})
+		return
+	}
+	fv := s.field(v, f)
+
+	origStruct, depth := s.errorStruct, len(s.fieldStack)
+	s.errorStruct = v.Type()
+	s.fieldStack = append(s.fieldStack, f.name)
+	if f.choice != "" && fv.IsValid() && c.Type.Kind == KindChoice {
+		// The field holds the value of one alternative.
+		cv := ChoiceValue{Value: fv.Addr().Interface()}
+		decode(reflect.ValueOf(&cv).Elem())
+		if cv.Alternative != f.choice {
+			fv.Set(reflect.Zero(fv.Type()))
+			s.typeError(c.Type, off, fv)
+		}
+	} else {
+		decode(fv)
+	}
+	s.errorStruct = origStruct
+	s.fieldStack = s.fieldStack[:depth]
+}
+
+// finish completes the storing of a SEQUENCE or SET value of type t at
+// offset off in ct. found holds which components were present; absent
+// DEFAULT components are set to their default.
+func (s *storer) finish(ct *composite, t *Type, off int, found []bool) {
+	for i := range t.Components {
+		if c := &t.Components[i]; !found[i] && c.Default != nil {
+			s.setDefault(ct, c, off)
+		}
+	}
+	if ct.iface.IsValid() {
+		ct.iface.Set(ct.v)
+	}
+}
+
+// field returns the field f of the struct v, allocating embedded pointers
+// as needed. It returns the zero Value if the field cannot be set.
+func (s *storer) field(v reflect.Value, f *field) reflect.Value {
+	for _, i := range f.index {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				// See the comment in decodeState.component.
+				if !v.CanSet() {
+					s.saveError(fmt.Errorf("asn1go: cannot set embedded pointer to unexported struct: %v", v.Type().Elem()))
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v
+}
+
+// setDefault stores the DEFAULT value of the absent component c of the
+// value at offset off in ct.
+func (s *storer) setDefault(ct *composite, c *Component, off int) {
+	v := ct.v
+	var fv reflect.Value
+	switch {
+	case !v.IsValid():
+		return
+	case v.Kind() == reflect.Map:
+		fv = reflect.New(v.Type().Elem()).Elem()
+	default:
+		f := ct.fields.byName([]byte(c.Name))
+		if f == nil {
+			return
+		}
+		if fv = s.field(v, f); !fv.IsValid() {
+			return
+		}
+	}
+
+	ok := true
+	if isEmptyInterface(fv) {
+		fv.Set(reflect.ValueOf(c.Default))
+	} else if n, isInt := integerValue(c.Type, reflect.ValueOf(c.Default)); isInt {
+		ok = storeInteger(c.Type, n, fv)
+	} else {
+		// The default is in the form Unmarshal decodes value notation
+		// to, so it goes back through value notation to reach the Go
+		// type.
+		b, err := Marshal(c.Default)
+		ok = err == nil && Unmarshal(b, fv.Addr().Interface()) == nil
+	}
+	if !ok {
+		s.saveError(&UnmarshalTypeError{Value: "DEFAULT value of " + c.Name, Type: fv.Type(), Offset: int64(off)})
+		return
+	}
+	if v.Kind() == reflect.Map {
+		v.SetMapIndex(reflect.ValueOf(c.Name).Convert(v.Type().Key()), fv)
+	}
+}
+
+// A list is the slice or array the elements of a SEQUENCE OF or SET OF
+// value are stored in.
+type list struct {
+	v     reflect.Value // the slice or array, or invalid to skip the value
+	n     int           // elements stored so far
+	iface reflect.Value // interface to store a generic slice v in when done
+}
+
+// list returns the list to store the elements of a SEQUENCE OF or SET OF
+// value of type t at offset off in v.
+func (s *storer) list(t *Type, off int, v reflect.Value) *list {
+	l := &list{v: v}
+	switch {
+	case !v.IsValid():
+	case isEmptyInterface(v):
+		l.v, l.iface = reflect.New(reflect.TypeOf([]interface{}{})).Elem(), v
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8:
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+	case v.Kind() == reflect.Array && v.Type().Elem().Kind() != reflect.Uint8:
+	default:
+		s.typeError(t, off, v)
+		l.v = reflect.Value{}
+	}
+	return l
+}
+
+// next returns the Go value to store the next element in. Elements beyond
+// the length of an array are skipped, as Unmarshal does.
+func (l *list) next() reflect.Value {
+	v := l.v
+	i := l.n
+	l.n++
+	switch {
+	case !v.IsValid():
+		return v
+	case v.Kind() == reflect.Slice:
+		v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+	case i >= v.Len():
+		return reflect.Value{}
+	}
+	return v.Index(i)
+}
+
+// finish completes the storing of the elements, zeroing those of an array
+// that were missing.
+func (l *list) finish() {
+	if l.v.IsValid() && l.v.Kind() == reflect.Array {
+		for i := l.n; i < l.v.Len(); i++ {
+			l.v.Index(i).Set(reflect.Zero(l.v.Type().Elem()))
+		}
+	}
+	if l.iface.IsValid() {
+		l.iface.Set(l.v)
+	}
+}
+
+// storeChoice stores the alternative c of a CHOICE value of type t at
+// offset off in the ChoiceValue, the struct with "choice" fields or the
+// map v, with decode decoding the value of the alternative into the Go
+// value it is given.
+func (s *storer) storeChoice(t *Type, c *Component, off int, v reflect.Value, decode func(reflect.Value)) {
+	switch {
+	case !v.IsValid():
+		decode(v)
+	case isEmptyInterface(v):
+		m := reflect.ValueOf(map[string]interface{}{})
+		s.component(&composite{v: m}, c, off, decode)
+		v.Set(m)
+	case v.Type() == choiceValueType:
+		v.Field(0).SetString(c.Name)
+		decode(v.Field(1))
+	case v.Kind() == reflect.Struct:
+		fields := cachedTypeFields(v.Type())
+		if f := fields.byName([]byte(c.Name)); fields.choice && f != nil && f.alternative {
+			s.component(&composite{v: v, fields: fields}, c, off, decode)
+			return
+		}
+		s.typeError(t, off, v)
+		decode(reflect.Value{})
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		s.component(&composite{v: v}, c, off, decode)
+	default:
+		s.typeError(t, off, v)
+		decode(reflect.Value{})
+	}
+}
+
+// extensionComponent is the component that the Extensions of a value
+// are stored as.
+var extensionComponent = Component{Name: "...", Type: &Type{Kind: KindAny}}
+
+// storeExtensions stores the unknown extension additions ext of a value
+// of type t at offset off in v, an Extensions or an empty interface.
+func (s *storer) storeExtensions(t *Type, off int, ext Extensions, v reflect.Value) {
+	v = target(v)
+	switch {
+	case !v.IsValid():
+	case isEmptyInterface(v), v.Type() == extensionsType:
+		v.Set(reflect.ValueOf(ext))
+	default:
+		s.typeError(t, off, v)
+	}
+}
+
+// nameOf returns the identifier of the named value n of an INTEGER or
+// ENUMERATED type.
+func (t *Type) nameOf(n int64) (string, bool) {
+	return numberName(t.Named, n)
+}
+
+// numberName returns the identifier of the named value or bit n of named.
+func numberName(named []NamedNumber, n int64) (string, bool) {
+	for _, nn := range named {
+		if nn.Value == n {
+			return nn.Name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,986 @@
+package asn1go
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// A Decoder reads and decodes ASN.1 value notation values from an input
+// stream.
+type Decoder struct {
+	r       io.Reader
+	buf     []byte
+	d       decodeState
+	scanp   int   // start of unread data in buf
+	scanned int64 // amount of data already scanned before buf
+	scan    scanner
+	err     error
+
+	tokens []TokenInfo // tokens of the current value not yet returned by Token
+
+	lines     int   // line ends in the input before scanp
+	lineStart int64 // offset of the line at scanp
+
+	ctx      context.Context // of DecodeContext, or nil
+	limits   Limits
+	literal  int64 // offset of the literal or identifier being read
+	elements []int // elements after the first of each enclosing value
+
+	maxErrors int          // of CollectErrors, or 0
+	errors    SyntaxErrors // collected so far
+
+	single bool // of RequireSingleValue
+
+	stats     DecoderStats
+	hexState  int // 1 inside the quotes of a bstring or hstring, 2 after them, or 0
+	hexDigits int // digits of the current bstring or hstring
+}
+
+// DecoderStats counts what a Decoder has read of its input so far, for
+// capacity planning and to tell suspicious input, such as uploads that
+// nest unusually deeply or carry large hex payloads, without a second
+// pass over it. Values that Decode skipped after a syntax error in
+// CollectErrors mode are counted up to the error.
+type DecoderStats struct {
+	Values   int   // top-level values read
+	Objects  int   // brace-delimited values
+	Literals int   // numbers, hstrings, bstrings and cstrings
+	MaxDepth int   // deepest nesting of brace-delimited values
+	HexBytes int64 // bytes that the hstrings and bstrings stand for
+	Bytes    int64 // bytes of input scanned
+}
+
+// Stats returns the counts of what the Decoder has read so far.
+func (dec *Decoder) Stats() DecoderStats {
+	st := dec.stats
+	st.Bytes = dec.scan.bytes
+	return st
+}
+
+// Limits bounds the input a Decoder accepts, to protect servers that
+// decode untrusted input, such as uploaded profile packages, from
+// exhausting their memory. A zero field sets no limit, except that the
+// nesting depth is always limited, to 10000 by default. Input beyond a
+// limit is reported as a SyntaxError before it is decoded.
+type Limits struct {
+	MaxDepth    int   // nesting depth of brace-delimited values
+	MaxBytes    int64 // total bytes of input read by the Decoder
+	MaxLiteral  int   // bytes of a literal or identifier, such as an hstring
+	MaxElements int   // elements or components of a brace-delimited value
+}
+
+// NewDecoder returns a new decoder that reads from r.
+//
+// The decoder introduces its own buffering and may
+// read data from r beyond the values requested.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// SetLimits sets the limits on the input of the Decoder.
+func (dec *Decoder) SetLimits(l Limits) {
+	dec.limits = l
+	dec.scan.maxDepth = l.MaxDepth
+	dec.d.scan.maxDepth = l.MaxDepth
+}
+
+// UseNumber causes the Decoder to unmarshal a number into an interface
+// value as a Number instead of as an int64, *big.Int or float64, keeping
+// its literal text.
+func (dec *Decoder) UseNumber() { dec.d.useNumber = true }
+
+// Decode reads the next top-level value, usually a value assignment, from
+// its input and stores it in the value pointed to by v. Decoding into a
+// ValueAssignment also keeps the value reference and type. At the end of
+// the input Decode returns io.EOF.
+//
+// Decode discards the tokens of the current value that Token has not
+// returned yet.
+//
+// See the documentation for Unmarshal for details about
+// the conversion of value notation into a Go value.
+func (dec *Decoder) Decode(v interface{}) error {
+	if dec.err != nil {
+		return dec.err
+	}
+	dec.tokens = dec.tokens[:0]
+
+	n, err := dec.readValue()
+	if err == io.EOF && len(dec.errors) > 0 {
+		dec.err = dec.errors
+		return dec.err
+	}
+	if err != nil {
+		return err
+	}
+	dec.d.init(dec.buf[dec.scanp : dec.scanp+n])
+	dec.consume(n)
+
+	// Don't save err from unmarshal into dec.err:
+	// the connection is still usable since we read a complete value
+	// even if it did not fit v.
+	return dec.d.unmarshal(v, 1)
+}
+
+// RequireSingleValue causes the Decoder to require its input to hold a
+// single top-level value, as UnmarshalSingle does. Decode then reads the
+// input to its end with the value, and returns a SyntaxError for any byte
+// after it other than space and comments.
+func (dec *Decoder) RequireSingleValue() { dec.single = true }
+
+// ResolveReferences causes the Decoder to resolve value references: an
+// identifier in value position, as in "{ maxSize ub-filesize }", that is
+// the value reference of a value assignment the Decoder decoded before, or
+// a key of refs, is decoded as the value it refers to, which may refer to
+// further values in turn. A value that refers back to itself is reported
+// as a ValueReferenceError. Other identifiers are decoded as usual, as
+// enumerated values, say.
+func (dec *Decoder) ResolveReferences(refs map[string]RawValue) {
+	dec.d.refs = make(map[string][]byte, len(refs))
+	for name, v := range refs {
+		dec.d.refs[name] = v
+	}
+}
+
+// CollectErrors causes the Decoder to go on after a syntax error, as a
+// linter would, collecting up to n errors. Decode skips the rest of the
+// value with the error, up to the brace that closes it or the next line
+// that begins with a letter, such as the value reference of the next
+// value assignment, and decodes the value that follows. It returns the
+// errors collected as SyntaxErrors at the end of the input, in place of
+// io.EOF, or as soon as there are n of them. More reports true while there
+// are errors for Decode to return.
+func (dec *Decoder) CollectErrors(n int) { dec.maxErrors = n }
+
+// SyntaxErrors is a list of syntax errors collected by a Decoder in
+// CollectErrors mode, in input order.
+type SyntaxErrors []*SyntaxError
+
+func (e SyntaxErrors) Error() string {
+	switch len(e) {
+	case 0:
+		return "no errors"
+	case 1:
+		return e[0].Error()
+	}
+	return e[0].Error() + " (and " + strconv.Itoa(len(e)-1) + " more errors)"
+}
+
+// Is reports whether any of the errors is target or wraps it.
+func (e SyntaxErrors) Is(target error) bool {
+	for _, se := range e {
+		if errors.Is(se, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// ZeroCopy causes the Decoder to store strings and RawValues that share
+// the memory of its input buffer instead of copies, saving an allocation
+// for each. The Decoder then never reuses a buffer once it has decoded a
+// value from it, so the buffers stay alive as long as any value decoded
+// from them does. The RawValues must not be modified.
+func (dec *Decoder) ZeroCopy() { dec.d.zeroCopy = true }
+
+// SetHexPolicy sets how the Decoder reads hstrings with an odd number of
+// digits, which it reports as an error by default. Whatever the policy,
+// the digits of an hstring stored in a string are in upper case, as
+// Marshal writes them, and padded to whole octets like its value.
+func (dec *Decoder) SetHexPolicy(p HexPolicy) { dec.d.hexPolicy = p }
+
+// SetDialect sets the identifiers the Decoder accepts, those of
+// DialectDefault unless it is called. An identifier that the dialect does
+// not allow is a SyntaxError.
+func (dec *Decoder) SetDialect(dl Dialect) {
+	dec.scan.dialect = dl
+	dec.d.scan.dialect = dl
+}
+
+// DisallowDuplicateComponents causes the Decoder to return a
+// DuplicateComponentError when a component identifier appears twice in a
+// SEQUENCE or SET value decoded into a struct or map, where the second
+// would silently replace the first, as in a profile edited by copy and
+// paste. The value is decoded all the same. Values decoded into an empty
+// interface are OrderedObjects, which keep both components.
+func (dec *Decoder) DisallowDuplicateComponents() { dec.d.noDuplicates = true }
+
+// KeepUnknownAlternatives causes the Decoder to store a CHOICE value
+// whose alternative the struct it is decoded into does not know, such as
+// one that a later version of a specification adds, as a RawChoice in the
+// field of the struct tagged asn1:"*,choice", rather than skipping it, so
+// that it can be told apart from a missing value and written back. A
+// struct without such a field skips it as before.
+func (dec *Decoder) KeepUnknownAlternatives() { dec.d.keepUnknown = true }
+
+// UseRegistry causes the Decoder to decode the values of the type
+// references and CHOICE alternatives registered in r into new values of
+// their Go types, where they are decoded into an interface that can hold
+// them, rather than failing or storing the generic forms Unmarshal uses
+// for an empty interface.
+func (dec *Decoder) UseRegistry(r *Registry) { dec.d.registry = r }
+
+// A LiteralResolver resolves a literal, as written in the value notation,
+// to the Go value to store in a Go value of type t, an empty interface
+// for values decoded into interfaces. It reports false to leave the
+// literal to the Decoder. The value must be assignable to t, or
+// convertible to it with the same kind, or a signed integer that fits a
+// signed integer t; nil stores the zero value. An
+// error stops the decode as that of an Unmarshaler does.
+type LiteralResolver func(lit []byte, t reflect.Type) (val interface{}, ok bool, err error)
+
+// RegisterLiteralResolver causes the Decoder to pass the literals of kind
+// to fn before it stores them in Go values of any type that does not
+// implement Unmarshaler, so that, say, every hstring can be read as a
+// string of lower case hex digits rather than a []byte without an
+// Unmarshaler for each type. The kinds are those of the Tokens the
+// literals are: TokenHexString, TokenBitString, TokenString, TokenNumber,
+// TokenNull, TokenBool, TokenReal for PLUS-INFINITY, MINUS-INFINITY and
+// NOT-A-NUMBER, and TokenNamedValue for other identifiers, value
+// references included. A nil fn removes the resolver of kind. The numbers
+// of OBJECT IDENTIFIER values are not literals of their own.
+func (dec *Decoder) RegisterLiteralResolver(kind TokenKind, fn LiteralResolver) {
+	if fn == nil {
+		delete(dec.d.resolvers, kind)
+		return
+	}
+	if dec.d.resolvers == nil {
+		dec.d.resolvers = make(map[TokenKind]LiteralResolver)
+	}
+	dec.d.resolvers[kind] = fn
+}
+
+// UseSchema causes the Decoder to look up the types of typed value
+// assignments in the module schema when it decodes their values into an
+// empty interface, and to store the values in the forms of those types
+// rather than by their notation alone: a BIT STRING written as an hstring
+// as a BitString, a UTCTime or GeneralizedTime as a time.Time, a named
+// INTEGER value as an int64, a REAL written as an integer as a float64,
+// and so on, as DecodeDER stores them. SEQUENCE and SET values stay
+// OrderedObjects. Values that do not fit their types are stored as
+// without a schema; Validate reports them. A type registered with
+// UseRegistry takes precedence.
+func (dec *Decoder) UseSchema(schema *Module) { dec.d.schema = schema }
+
+// DecodeContext is like Decode but stops early with the error of ctx if
+// ctx is done before the value is decoded, checking it periodically while
+// reading the value and while storing it in v. A value whose reading was
+// cancelled leaves the Decoder unusable, as a read error does; one whose
+// storing was cancelled has been read, and v holds part of it.
+func (dec *Decoder) DecodeContext(ctx context.Context, v interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if ctx.Done() != nil {
+		dec.ctx, dec.d.ctx = ctx, ctx
+		defer func() { dec.ctx, dec.d.ctx = nil, nil }()
+	}
+	return dec.Decode(v)
+}
+
+// ctxCheckBytes is the number of bytes read between checks of the context
+// of a DecodeContext.
+const ctxCheckBytes = 64 << 10
+
+// readValue reads a top-level value into dec.buf.
+// It returns the length of the encoding.
+func (dec *Decoder) readValue() (int, error) {
+	dec.scan.restart()
+	dec.scan.allowMultipleTopValues = !dec.single
+
+	scanp := dec.scanp
+	sawValue := false
+	var err error
+Input:
+	// help the compiler see that scanp is never negative, so it can remove
+	// some bounds checks below.
+	for scanp >= 0 {
+
+		// Look in the buffer for a new value.
+		for ; scanp < len(dec.buf); scanp++ {
+			c := dec.buf[scanp]
+			dec.scan.bytes++
+			if dec.ctx != nil && dec.scan.bytes%ctxCheckBytes == 0 {
+				if err := dec.ctx.Err(); err != nil {
+					dec.err = err
+					return 0, err
+				}
+			}
+			switch op := dec.scan.step(&dec.scan, c); op {
+			case scanEnd:
+				// c begins the next value and is left unread.
+				dec.scan.bytes--
+				break Input
+			case scanError:
+				lines := dec.lines - bytes.Count(dec.buf[:dec.scanp], []byte{'\n'})
+				dec.err = addSnippet(dec.scan.err, dec.buf, scanp, lines)
+				if dec.maxErrors <= 0 {
+					return 0, dec.err
+				}
+				dec.errors = append(dec.errors, dec.err.(*SyntaxError))
+				if len(dec.errors) >= dec.maxErrors {
+					dec.err = dec.errors
+					return 0, dec.err
+				}
+				dec.err = nil
+				scanp, err = dec.resync(scanp, err)
+				dec.scanp = scanp
+				dec.scan.restart()
+				dec.elements = dec.elements[:0]
+				dec.hexState = 0
+				sawValue = false
+				continue Input
+			default:
+				sawValue = sawValue || op != scanSkipSpace
+				if err := dec.checkLimits(op); err != nil {
+					dec.err = err
+					return 0, err
+				}
+				k := dec.scan.skipRun(dec.buf[scanp+1:dec.runEnd(scanp+1, op)], c, op)
+				dec.count(op, c, dec.buf[scanp+1:scanp+1+k])
+				scanp += k
+				dec.scan.bytes += int64(k)
+			}
+		}
+
+		// Did the last read have an error?
+		// Delayed until now to allow buffer scan.
+		if err != nil {
+			if err == io.EOF {
+				if !sawValue {
+					// Nothing but space and comments left.
+					dec.err = err
+					return 0, err
+				}
+				if dec.scan.eof() == scanEnd {
+					break Input
+				}
+				err = io.ErrUnexpectedEOF
+				if dec.scan.err != nil && !errors.Is(dec.scan.err, ErrUnexpectedEOF) {
+					// The input ends in a value that is complete but
+					// not valid, such as an identifier the dialect
+					// does not allow.
+					err = dec.scan.err
+				}
+			}
+			dec.err = err
+			return 0, err
+		}
+
+		n := scanp - dec.scanp
+		err = dec.refill()
+		scanp = dec.scanp + n
+	}
+	dec.stats.Values++
+	return scanp - dec.scanp, nil
+}
+
+// count adds the byte c, for which the scanner returned op, and the run of
+// bytes after it that the scanner skipped to the statistics of dec.
+func (dec *Decoder) count(op int, c byte, run []byte) {
+	st := &dec.stats
+	switch op {
+	case scanBeginObject:
+		st.Objects++
+		if depth := len(dec.scan.parseState); depth > st.MaxDepth {
+			st.MaxDepth = depth
+		}
+	case scanBeginLiteral:
+		if isDigit(c) || c == '\'' || c == '"' {
+			st.Literals++
+		}
+		if c == '\'' {
+			dec.hexState, dec.hexDigits = 1, 0
+			dec.countHexDigits(run)
+			return
+		}
+	case scanContinue:
+		switch dec.hexState {
+		case 1:
+			if c == '\'' {
+				dec.hexState = 2
+			} else {
+				if !isSpace(c) {
+					dec.hexDigits++
+				}
+				dec.countHexDigits(run)
+			}
+			return
+		case 2:
+			// The suffix tells the digits of an hstring from those of a
+			// bstring.
+			if c == 'H' {
+				st.HexBytes += int64(dec.hexDigits+1) / 2
+			} else {
+				st.HexBytes += int64(dec.hexDigits+7) / 8
+			}
+		}
+	}
+	dec.hexState = 0
+}
+
+// countHexDigits counts the digits of run, part of a bstring or hstring.
+func (dec *Decoder) countHexDigits(run []byte) {
+	for _, c := range run {
+		if !isSpace(c) {
+			dec.hexDigits++
+		}
+	}
+}
+
+// resync skips the rest of a value whose syntax error is at dec.buf[i],
+// in CollectErrors mode, up to the brace that closes the enclosing
+// top-level value or the next line that begins with a letter, whichever
+// comes first. It returns the index in dec.buf at which to go on, reading
+// more input as needed, and the error of the last read, err at first.
+func (dec *Decoder) resync(i int, err error) (int, error) {
+	depth := len(dec.scan.parseState)
+	// The scanner may have been inside a literal or comment.
+	quote := dec.scan.run
+	comment := quote == '-'
+	if comment {
+		quote = 0
+	}
+	lineStart := i > 0 && (dec.buf[i-1] == '\n' || dec.buf[i-1] == '\r')
+	prev := byte(0)
+	dec.scan.bytes-- // for dec.buf[i], which was counted already
+	dec.consume(i - dec.scanp)
+	for {
+		for ; i < len(dec.buf); i++ {
+			c := dec.buf[i]
+			if lineStart && quote == 0 && !comment && isLetter(c) {
+				return i, err
+			}
+			dec.scan.bytes++
+			lineStart = c == '\n' || c == '\r'
+			if c == '\n' {
+				dec.lines++
+				dec.lineStart = dec.scan.bytes
+			}
+			switch {
+			case comment:
+				comment = !lineStart
+			case quote != 0:
+				if c == quote {
+					quote = 0
+				}
+			case c == '"' || c == '\'':
+				quote = c
+			case c == '-' && prev == '-':
+				comment = true
+			case c == '{':
+				depth++
+			case c == '}':
+				if depth--; depth <= 0 {
+					return i + 1, err
+				}
+			}
+			prev = c
+		}
+		if err != nil {
+			return i, err
+		}
+		n := i - dec.scanp
+		err = dec.refill()
+		i = dec.scanp + n
+	}
+}
+
+// checkLimits checks the input read so far against the limits of dec,
+// given the opcode op the scanner returned for the last byte.
+func (dec *Decoder) checkLimits(op int) error {
+	l, s := &dec.limits, &dec.scan
+	if l.MaxBytes > 0 && s.bytes > l.MaxBytes {
+		return &SyntaxError{msg: "exceeded max input size of " + strconv.FormatInt(l.MaxBytes, 10) + " bytes", err: ErrLimitExceeded, Offset: s.bytes}
+	}
+	depth := len(s.parseState)
+	element := false
+	switch op {
+	case scanBeginLiteral, scanBeginValueReference, scanBeginIdentifier, scanBeginTypeReference:
+		dec.literal = s.bytes
+		// A further component of an object identifier value follows
+		// without a separating comma.
+		element = op == scanBeginLiteral && depth > 0 && s.parseState[depth-1] == parseObjectIdentifier
+	case scanContinue:
+		if l.MaxLiteral > 0 && s.bytes-dec.literal >= int64(l.MaxLiteral) {
+			return &SyntaxError{msg: "exceeded max literal length of " + strconv.Itoa(l.MaxLiteral) + " bytes", err: ErrLimitExceeded, Offset: s.bytes}
+		}
+	case scanBeginObject:
+		dec.elements = append(dec.elements[:depth-1], 0)
+	case scanObjectValue:
+		element = true
+	}
+	if element && l.MaxElements > 0 {
+		if dec.elements[depth-1]++; dec.elements[depth-1] >= l.MaxElements {
+			return &SyntaxError{msg: "exceeded max element count of " + strconv.Itoa(l.MaxElements), err: ErrLimitExceeded, Offset: s.bytes}
+		}
+	}
+	return nil
+}
+
+// runEnd returns the end of the part of dec.buf from i on that the scanner
+// may skip as a run of bytes reported as op: the byte after it is the next
+// one to be checked against the context and the limits of dec.
+func (dec *Decoder) runEnd(i int, op int) int {
+	n := int64(len(dec.buf) - i)
+	b := dec.scan.bytes
+	if dec.ctx != nil {
+		n = min64(n, ctxCheckBytes-1-b%ctxCheckBytes)
+	}
+	l := &dec.limits
+	if l.MaxBytes > 0 {
+		n = min64(n, l.MaxBytes-b)
+	}
+	if l.MaxLiteral > 0 && op == scanContinue {
+		n = min64(n, dec.literal+int64(l.MaxLiteral)-1-b)
+	}
+	if n < 0 {
+		n = 0
+	}
+	return i + int(n)
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func (dec *Decoder) refill() error {
+	const minRead = 512
+
+	// Values decoded from the buffer may share its memory: read on after
+	// them while there is room, then move the data not yet consumed to a
+	// new buffer as large as the old one, rather than slide it down.
+	if dec.d.zeroCopy {
+		if cap(dec.buf)-len(dec.buf) < minRead && dec.scanp > 0 {
+			dec.scanned += int64(dec.scanp)
+			size := cap(dec.buf)
+			if n := 2*(len(dec.buf)-dec.scanp) + minRead; n > size {
+				size = n
+			}
+			newBuf := make([]byte, len(dec.buf)-dec.scanp, size)
+			copy(newBuf, dec.buf[dec.scanp:])
+			dec.buf = newBuf
+			dec.scanp = 0
+		}
+	}
+
+	// Make room to read more into the buffer.
+	// First slide down data already consumed.
+	if dec.scanp > 0 && !dec.d.zeroCopy {
+		dec.scanned += int64(dec.scanp)
+		n := copy(dec.buf, dec.buf[dec.scanp:])
+		dec.buf = dec.buf[:n]
+		dec.scanp = 0
+	}
+
+	// Grow buffer if not large enough.
+	if cap(dec.buf)-len(dec.buf) < minRead {
+		newBuf := make([]byte, len(dec.buf), 2*cap(dec.buf)+minRead)
+		copy(newBuf, dec.buf)
+		dec.buf = newBuf
+	}
+
+	// Read. Delay error for next iteration (after scan).
+	n, err := dec.r.Read(dec.buf[len(dec.buf):cap(dec.buf)])
+	dec.buf = dec.buf[0 : len(dec.buf)+n]
+
+	return err
+}
+
+// Buffered returns a reader of the data remaining in the Decoder's
+// buffer. The reader is valid until the next call to Decode, Token or
+// More. Together with the rest of the underlying reader, it holds the
+// input after InputOffset, for a protocol that embeds value notation
+// followed by other data.
+func (dec *Decoder) Buffered() io.Reader {
+	return bytes.NewReader(dec.buf[dec.scanp:])
+}
+
+// InputOffset returns the input stream byte offset just past the last
+// value read, by Decode or by Token, which reads a whole top-level value
+// at a time, and the space and comments that follow it. It is the number
+// of bytes of the underlying reader that the Decoder consumed; the bytes
+// it read beyond them are Buffered.
+func (dec *Decoder) InputOffset() int64 {
+	return dec.scanned + int64(dec.scanp)
+}
+
+// More reports whether there is another top-level value in the input,
+// skipping space and comments.
+func (dec *Decoder) More() bool {
+	if len(dec.tokens) > 0 {
+		return true
+	}
+	var scan scanner
+	scan.reset()
+	i := dec.scanp
+	for {
+		for ; i < len(dec.buf); i++ {
+			if scan.step(&scan, dec.buf[i]) != scanSkipSpace {
+				return true
+			}
+		}
+		if dec.err != nil {
+			return false
+		}
+		n := i - dec.scanp
+		err := dec.refill()
+		i = dec.scanp + n
+		if err != nil && i == len(dec.buf) {
+			// Decode returns the errors collected.
+			return len(dec.errors) > 0
+		}
+	}
+}
+
+// ScanValues is a split function for a bufio.Scanner that returns each
+// top-level value read from its input, as SplitValues does, to iterate
+// over the value assignments of a document too large to read at once. A
+// value is complete once the first byte of the next one has been read, or
+// at the end of the input. A syntax error stops the scan; its Offset and Line
+// count from the start of the value. Values longer than the buffer of the
+// bufio.Scanner stop the scan with bufio.ErrTooLong, so profile packages
+// with large files may need a larger buffer, set with its Buffer method.
+func ScanValues(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	scan := newScanner()
+	defer freeScanner(scan)
+	start, end := -1, 0
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		scan.bytes++
+		op := scan.step(scan, c)
+		if op == scanEnd {
+			// c begins the next top-level value.
+			return i, data[start:end], nil
+		}
+		if op == scanError {
+			return 0, nil, addSnippet(scan.err, data, i, 0)
+		}
+		k := scan.skipRun(data[i+1:], c, op)
+		if op != scanSkipSpace {
+			if start < 0 {
+				start = valueStart(scan, c, i)
+			}
+			end = i + 1 + k
+		}
+		i += k
+		scan.bytes += int64(k)
+	}
+	if !atEOF {
+		// Request more data.
+		return 0, nil, nil
+	}
+	if start < 0 {
+		// Nothing but space and comments left.
+		return len(data), nil, nil
+	}
+	if scan.eof() == scanError {
+		return 0, nil, addSnippet(scan.err, data, len(data), 0)
+	}
+	return len(data), data[start:end], nil
+}
+
+// A Token holds a value of one of these types:
+//
+//	ObjectStart       for the opening brace {
+//	ObjectEnd         for the closing brace }
+//	ValueReference    for the value reference of a value assignment
+//	Identifier        for component identifiers
+//	TypeName          for the type of a value assignment
+//	AssignmentOp      for ::=
+//	ChoiceTag         for the alternative identifier of a CHOICE value
+//	ContainingOp      for CONTAINING, before the value contained
+//	HexString         for hstrings
+//	BitString         for bstrings
+//	Number            for numbers
+//	Null              for NULL
+//	bool              for TRUE and FALSE
+//	float64           for PLUS-INFINITY, MINUS-INFINITY and NOT-A-NUMBER
+//	string            for cstrings
+//	NamedValue        for identifiers in value position, such as
+//	                  enumerated values
+//	ObjectIdentifier  for OBJECT IDENTIFIER values, as a single token
+//
+// Commas between elements have no token. A top-level value assignment
+// value1 T ::= mf : { } reads as ValueReference("value1"), TypeName("T"),
+// AssignmentOp{}, ChoiceTag("mf"), ObjectStart{}, ObjectEnd{}.
+type Token interface{}
+
+type (
+	// ObjectStart is the opening brace of a brace-delimited value.
+	ObjectStart struct{}
+	// ObjectEnd is the closing brace of a brace-delimited value.
+	ObjectEnd struct{}
+	// ValueReference is the value reference of a value assignment, the
+	// name the value is assigned to.
+	ValueReference string
+	// Identifier is a component identifier.
+	Identifier string
+	// TypeName is the type of a value assignment, such as
+	// "ProfileElement" or "OCTET STRING".
+	TypeName string
+	// AssignmentOp is the ::= of a value assignment.
+	AssignmentOp struct{}
+	// ChoiceTag is the identifier of the alternative of a CHOICE value.
+	// The alternative's value follows.
+	ChoiceTag string
+	// ContainingOp is the keyword CONTAINING of a containing value. The
+	// value contained follows.
+	ContainingOp struct{}
+	// HexString is the decoded octets of an hstring.
+	HexString []byte
+	// Null is the NULL value.
+	Null struct{}
+	// NamedValue is an identifier in value position, such as an
+	// enumerated value or a value reference.
+	NamedValue string
+)
+
+// Token returns the next token in the input stream.
+// At the end of the input stream, Token returns nil, io.EOF.
+//
+// Token reads a whole top-level value at a time and returns its tokens
+// one by one, so the input must be a sequence of complete values; a
+// syntax error is reported before any token of the value it is in.
+func (dec *Decoder) Token() (Token, error) {
+	t, err := dec.TokenInfo()
+	return t.Value, err
+}
+
+// TokenInfo is like Token but returns the token with its kind and its
+// position in the input.
+func (dec *Decoder) TokenInfo() (TokenInfo, error) {
+	if len(dec.tokens) == 0 {
+		if err := dec.readTokens(); err != nil {
+			return TokenInfo{}, err
+		}
+	}
+	t := dec.tokens[0]
+	dec.tokens = dec.tokens[1:]
+	return t, nil
+}
+
+// readTokens reads the next top-level value and queues its tokens.
+func (dec *Decoder) readTokens() error {
+	if dec.err != nil {
+		return dec.err
+	}
+	n, err := dec.readValue()
+	if err != nil {
+		return err
+	}
+	data := dec.buf[dec.scanp : dec.scanp+n]
+	base := dec.scan.bytes - int64(n)
+	line, lineStart := dec.lines, dec.lineStart
+	dec.d.init(data)
+	dec.consume(n)
+	toks := dec.d.topTokens(nil)
+	if err := dec.d.savedError; err != nil {
+		return err
+	}
+	dec.tokens = dec.tokens[:0]
+	last := 0
+	for k, off := range tokenOffsets(data, toks, dec.scan.dialect) {
+		if j := bytes.LastIndexByte(data[last:off], '\n'); j >= 0 {
+			line += bytes.Count(data[last:off], []byte{'\n'})
+			lineStart = base + int64(last+j+1)
+		}
+		last = off
+		at := base + int64(off)
+		dec.tokens = append(dec.tokens, TokenInfo{tokenKind(toks[k]), toks[k], at, line + 1, int(at-lineStart) + 1})
+	}
+	return nil
+}
+
+// consume marks the next n bytes of dec.buf as read, keeping count of the
+// lines they end.
+func (dec *Decoder) consume(n int) {
+	b := dec.buf[dec.scanp : dec.scanp+n]
+	if j := bytes.LastIndexByte(b, '\n'); j >= 0 {
+		// The offset of dec.buf[dec.scanp+n] is dec.scan.bytes.
+		dec.lines += bytes.Count(b, []byte{'\n'})
+		dec.lineStart = dec.scan.bytes - int64(n-j-1)
+	}
+	dec.scanp += n
+}
+
+// A PushDecoder reads value notation that the caller pushes to it in
+// chunks as they arrive, such as the segments of a bound profile package
+// streamed over the network, and hands each top-level value to a function
+// as soon as it is complete. It keeps only the bytes of the value being
+// read, rather than buffering the whole input as Unmarshal needs it to.
+type PushDecoder struct {
+	fn       func(RawValue) error
+	scan     scanner
+	buf      []byte // bytes of the current top-level value read so far
+	lines    int    // line ends in the chunks fed so far
+	sawValue bool
+	err      error
+}
+
+// NewPushDecoder returns a PushDecoder that calls fn with the text of each
+// top-level value, usually a value assignment, which fn can Unmarshal. The
+// RawValue is only valid until fn returns. An error returned by fn stops
+// the decoding and is returned by Feed or Finish.
+func NewPushDecoder(fn func(RawValue) error) *PushDecoder {
+	p := &PushDecoder{fn: fn}
+	p.scan.reset()
+	return p
+}
+
+// Feed pushes the next chunk b of the input, calling the function of p
+// for each value it completes. A value is complete once the first byte
+// after it has been read, or at Finish. The returned error is that of the
+// first syntax error in the input or the first error of the function; p
+// returns it from then on.
+func (p *PushDecoder) Feed(b []byte) error {
+	if p.err != nil {
+		return p.err
+	}
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		p.scan.bytes++
+		op := p.scan.step(&p.scan, c)
+		if op == scanEnd {
+			// c begins the next top-level value.
+			if err := p.emit(); err != nil {
+				return err
+			}
+			p.scan.restart()
+			op = p.scan.step(&p.scan, c)
+		}
+		if op == scanError {
+			p.err = addSnippet(p.scan.err, b, i, p.lines)
+			return p.err
+		}
+		if op != scanSkipSpace {
+			p.sawValue = true
+		}
+		k := p.scan.skipRun(b[i+1:], c, op)
+		if p.sawValue {
+			p.buf = append(p.buf, b[i:i+1+k]...)
+		}
+		i += k
+		p.scan.bytes += int64(k)
+	}
+	p.lines += bytes.Count(b, []byte{'\n'})
+	return nil
+}
+
+// Finish signals the end of the input, calling the function of p for the
+// last value. It reports an error if the input ends inside a value.
+func (p *PushDecoder) Finish() error {
+	if p.err != nil {
+		return p.err
+	}
+	if !p.sawValue {
+		// Nothing but space and comments since the last value.
+		return nil
+	}
+	if p.scan.eof() != scanEnd {
+		p.err = addSnippet(p.scan.err, p.buf, len(p.buf), p.lines-bytes.Count(p.buf, []byte{'\n'}))
+		return p.err
+	}
+	return p.emit()
+}
+
+// emit hands the value read into p.buf to the function of p.
+func (p *PushDecoder) emit() error {
+	err := p.fn(p.buf)
+	p.buf, p.sawValue = p.buf[:0], false
+	if err != nil {
+		p.err = err
+	}
+	return err
+}
+
+// An Encoder writes ASN.1 value notation values to an output stream.
+type Encoder struct {
+	w   io.Writer
+	err error
+
+	indentPrefix string
+	indentValue  string
+	hexLine      int
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes the value notation encoding of v to the stream,
+// followed by a newline character. Each value is encoded in memory and
+// written before Encode returns, so a long list of value assignments can
+// be written one Encode call at a time.
+//
+// See the documentation for Marshal for details about the
+// conversion of Go values to value notation.
+func (enc *Encoder) Encode(v interface{}) error {
+	if enc.err != nil {
+		return enc.err
+	}
+
+	e := newEncodeState()
+	defer encodeStatePool.Put(e)
+
+	e.prefix, e.indent = enc.indentPrefix, enc.indentValue
+	e.hexLine = enc.hexLine
+	err := e.marshal(v)
+	if err != nil {
+		return err
+	}
+
+	// Terminate each value with a newline, which also ends a comment a
+	// Marshaler may have left at the end of its output.
+	e.WriteByte('\n')
+
+	if _, err = enc.w.Write(e.Bytes()); err != nil {
+		enc.err = err
+	}
+	return err
+}
+
+// SetIndent instructs the encoder to format each subsequent encoded
+// value as if indented by the package-level function MarshalIndent.
+// Calling SetIndent("", "") disables indentation, and the layout of
+// SetSampleLayout with it.
+func (enc *Encoder) SetIndent(prefix, indent string) {
+	enc.indentPrefix = prefix
+	enc.indentValue = indent
+	enc.hexLine = 0
+}
+
+// SetSampleLayout instructs the encoder to format each subsequent encoded
+// value in the layout of the sample profiles, as MarshalSample does.
+func (enc *Encoder) SetSampleLayout() {
+	enc.indentPrefix = ""
+	enc.indentValue = "  "
+	enc.hexLine = sampleHexLine
+}
+
+// SetHexWrap instructs the encoder to break hstrings of more than n
+// hexadecimal digits into lines of n digits, rounded down to whole
+// octets, as MarshalSample does with n = 64. Each continuation line is
+// indented one level deeper than the line the hstring starts on; without
+// indentation, it starts at the beginning of the line. The white space
+// between the digits is insignificant, so the value stays the same.
+// Lines hold at least one octet: a positive n below 2 is taken as 2.
+// SetHexWrap(0) disables wrapping, and so does SetIndent, which should
+// come first.
+func (enc *Encoder) SetHexWrap(n int) {
+	switch {
+	case n <= 0:
+		n = 0
+	case n < 2:
+		n = 2
+	}
+	enc.hexLine = n / 2
+}
@@ -0,0 +1,992 @@
+package asn1go
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func TestEncoderSetHexWrap(t *testing.T) {
+	v := struct {
+		Content []byte `asn1:"content"`
+	}{[]byte{0x01, 0x23, 0x45, 0x67, 0x89}}
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "{\n  content '0123456789'H\n}\n"},
+		{-4, "{\n  content '0123456789'H\n}\n"},
+		{1, "{\n  content '01\n    23\n    45\n    67\n    89'H\n}\n"},
+		{2, "{\n  content '01\n    23\n    45\n    67\n    89'H\n}\n"},
+		{4, "{\n  content '0123\n    4567\n    89'H\n}\n"},
+		{5, "{\n  content '0123\n    4567\n    89'H\n}\n"},
+		{10, "{\n  content '0123456789'H\n}\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		enc.SetHexWrap(tt.n)
+		if err := enc.Encode(v); err != nil {
+			t.Fatalf("SetHexWrap(%d): Encode: %v", tt.n, err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("SetHexWrap(%d): Encode wrote %q, want %q", tt.n, got, tt.want)
+		}
+		var back struct {
+			Content []byte `asn1:"content"`
+		}
+		if err := Unmarshal(buf.Bytes(), &back); err != nil || !bytes.Equal(back.Content, v.Content) {
+			t.Errorf("SetHexWrap(%d): Unmarshal = %x, %v, want %x", tt.n, back.Content, err, v.Content)
+		}
+	}
+}
+
+// failWriter fails every write after the first n.
+type failWriter struct{ n int }
+
+func (w *failWriter) Write(p []byte) (int, error) {
+	if w.n == 0 {
+		return 0, errors.New("write failed")
+	}
+	w.n--
+	return len(p), nil
+}
+
+func TestEncoder(t *testing.T) {
+	values := []interface{}{
+		ValueAssignment{"value1", "INTEGER", 1},
+		ValueAssignment{"value2", "ProfileElement", ChoiceValue{"end", nil}},
+		[]int{1, 2},
+	}
+	tests := []struct {
+		prefix, indent string
+		want           string
+	}{
+		{"", "", "value1 INTEGER ::= 1\nvalue2 ProfileElement ::= end : NULL\n{ 1, 2 }\n"},
+		{"", "  ", "value1 INTEGER ::= 1\nvalue2 ProfileElement ::= end : NULL\n{\n  1,\n  2\n}\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		enc.SetIndent(tt.prefix, tt.indent)
+		for _, v := range values {
+			if err := enc.Encode(v); err != nil {
+				t.Fatalf("Encode(%#v): %v", v, err)
+			}
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("SetIndent(%q, %q): Encode wrote %q, want %q", tt.prefix, tt.indent, got, tt.want)
+		}
+	}
+}
+
+func TestEncoderError(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	if err := enc.Encode(make(chan int)); err == nil {
+		t.Error("Encode(chan): no error")
+	}
+	if err := enc.Encode(1); err != nil || buf.String() != "1\n" {
+		t.Errorf("Encode after an unsupported value wrote %q, %v, want %q", buf.String(), err, "1\n")
+	}
+
+	enc = NewEncoder(&failWriter{1})
+	if err := enc.Encode(1); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	err := enc.Encode(2)
+	if err == nil {
+		t.Fatal("Encode to a failing writer: no error")
+	}
+	if err2 := enc.Encode(3); err2 != err {
+		t.Errorf("Encode after a write error = %v, want %v", err2, err)
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []interface{}
+	}{
+		{"", nil},
+		{"  -- only a comment\n", nil},
+		{"1", []interface{}{int64(1)}},
+		{"1 2\n3", []interface{}{int64(1), int64(2), int64(3)}},
+		{"v1 INTEGER ::= 1\nv2 T ::= { a TRUE }", []interface{}{int64(1), OrderedObject{{"a", true}}}},
+		{"a : 'FF'H -- x\n{ }", []interface{}{map[string]interface{}{"a": []byte{0xFF}}, OrderedObject{}}},
+		{"{ 2 23 143 } \"s\"", []interface{}{ObjectIdentifier{2, 23, 143}, "s"}},
+	}
+	readers := []struct {
+		name string
+		r    func(string) io.Reader
+	}{
+		{"whole", func(s string) io.Reader { return strings.NewReader(s) }},
+		{"byte by byte", func(s string) io.Reader { return iotest.OneByteReader(strings.NewReader(s)) }},
+	}
+	for _, tt := range tests {
+		for _, r := range readers {
+			dec := NewDecoder(r.r(tt.in))
+			var got []interface{}
+			for dec.More() {
+				var v interface{}
+				if err := dec.Decode(&v); err != nil {
+					t.Fatalf("%s: Decode(%q): %v", r.name, tt.in, err)
+				}
+				got = append(got, v)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s: Decode(%q) = %#v, want %#v", r.name, tt.in, got, tt.want)
+			}
+			var v interface{}
+			if err := dec.Decode(&v); err != io.EOF {
+				t.Errorf("%s: Decode(%q) at the end = %v, want io.EOF", r.name, tt.in, err)
+			}
+		}
+	}
+}
+
+func TestDecoderAssignment(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("value1 PE-Header ::= { major-version 2 }\nvalue2 INTEGER ::= 5"))
+	var a ValueAssignment
+	var h struct {
+		MajorVersion int `asn1:"major-version"`
+	}
+	a.Value = &h
+	if err := dec.Decode(&a); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if a.Name != "value1" || a.Type != "PE-Header" || h.MajorVersion != 2 {
+		t.Errorf("Decode = %+v, %+v", a, h)
+	}
+	var n int
+	a = ValueAssignment{Value: &n}
+	if err := dec.Decode(&a); err != nil || a.Name != "value2" || n != 5 {
+		t.Errorf("Decode = %+v, %d, %v", a, n, err)
+	}
+}
+
+func TestDecoderError(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("1 TRUE { a 1,, }"))
+	var n int
+	if err := dec.Decode(&n); err != nil || n != 1 {
+		t.Fatalf("Decode = %d, %v", n, err)
+	}
+	// A value that does not fit leaves the Decoder usable.
+	if _, ok := dec.Decode(&n).(*UnmarshalTypeError); !ok {
+		t.Errorf("Decode(TRUE) into int: want UnmarshalTypeError")
+	}
+	var v interface{}
+	err := dec.Decode(&v)
+	if _, ok := err.(*SyntaxError); !ok {
+		t.Fatalf("Decode of an invalid value: error %v, want SyntaxError", err)
+	}
+	if err2 := dec.Decode(&v); err2 != err {
+		t.Errorf("Decode after a syntax error = %v, want %v", err2, err)
+	}
+
+	dec = NewDecoder(strings.NewReader("{ a 1"))
+	if err := dec.Decode(&v); err != io.ErrUnexpectedEOF {
+		t.Errorf("Decode of a truncated value: error %v, want io.ErrUnexpectedEOF", err)
+	}
+
+	dec = NewDecoder(iotest.ErrReader(errors.New("read failed")))
+	if err := dec.Decode(&v); err == nil || err.Error() != "read failed" {
+		t.Errorf("Decode from a failing reader: %v", err)
+	}
+}
+
+func TestDecoderUseNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		want interface{}
+	}{
+		{"5", Number("5")},
+		{"-7", Number("-7")},
+		{"1.50", Number("1.50")},
+		{"123456789012345678901234567890", Number("123456789012345678901234567890")},
+		{"{ a 2.0e3, b { 1, -1 } }", OrderedObject{{"a", Number("2.0e3")}, {"b", []interface{}{Number("1"), Number("-1")}}}},
+		{"TRUE", true},
+	}
+	for _, tt := range tests {
+		dec := NewDecoder(strings.NewReader(tt.in))
+		dec.UseNumber()
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			t.Errorf("Decode(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(v, tt.want) {
+			t.Errorf("Decode(%q) = %#v, want %#v", tt.in, v, tt.want)
+		}
+		b, err := Marshal(v)
+		if err != nil || string(b) != tt.in {
+			t.Errorf("Marshal(%#v) = %s, %v, want %s", v, b, err, tt.in)
+		}
+	}
+	if _, err := Marshal(Number("1x")); err == nil {
+		t.Error("Marshal(Number(\"1x\")): no error")
+	}
+}
+
+func TestNumber(t *testing.T) {
+	tests := []struct {
+		n      Number
+		i      int64
+		iok    bool
+		f      float64
+		fok    bool
+		bigint string
+	}{
+		{"5", 5, true, 5, true, "5"},
+		{"-7", -7, true, -7, true, "-7"},
+		{"1.5", 0, false, 1.5, true, ""},
+		{"2.0e3", 0, false, 2000, true, ""},
+		{"99999999999999999999999", math.MaxInt64, false, 1e23, true, "99999999999999999999999"},
+	}
+	for _, tt := range tests {
+		i, err := tt.n.Int64()
+		if (err == nil) != tt.iok || i != tt.i {
+			t.Errorf("Number(%q).Int64() = %d, %v", tt.n, i, err)
+		}
+		f, err := tt.n.Float64()
+		if (err == nil) != tt.fok || f != tt.f {
+			t.Errorf("Number(%q).Float64() = %g, %v", tt.n, f, err)
+		}
+		b, err := tt.n.BigInt()
+		if tt.bigint == "" {
+			if err == nil {
+				t.Errorf("Number(%q).BigInt() = %v, want an error", tt.n, b)
+			}
+		} else if err != nil || b.String() != tt.bigint {
+			t.Errorf("Number(%q).BigInt() = %v, %v, want %s", tt.n, b, err, tt.bigint)
+		}
+	}
+}
+
+func TestDecoderSetLimits(t *testing.T) {
+	deep := strings.Repeat("{", 10001) + "1" + strings.Repeat("}", 10001)
+	tests := []struct {
+		in     string
+		limits Limits
+		ok     bool
+	}{
+		{"{ { { 1 } } }", Limits{MaxDepth: 3}, true},
+		{"{ { { { 1 } } } }", Limits{MaxDepth: 3}, false},
+		{"{ 1, 2, 3 }", Limits{MaxElements: 3}, true},
+		{"{ 1, 2, 3, 4 }", Limits{MaxElements: 3}, false},
+		{"{ 1 2 3 4 }", Limits{MaxElements: 3}, false},
+		{"{ a { 1, 2 }, b 2, c 3 }", Limits{MaxElements: 3}, true},
+		{"'0102'H", Limits{MaxLiteral: 7}, true},
+		{"'010203'H", Limits{MaxLiteral: 7}, false},
+		{`"abcde"`, Limits{MaxLiteral: 7}, true},
+		{`"abcdef"`, Limits{MaxLiteral: 7}, false},
+		{"v1 T ::= { a 1 }", Limits{MaxBytes: 16}, true},
+		{"v1 T ::= { a 10 }", Limits{MaxBytes: 16}, false},
+		{deep, Limits{MaxDepth: 20000}, true},
+		{deep, Limits{}, false},
+	}
+	for _, tt := range tests {
+		dec := NewDecoder(strings.NewReader(tt.in))
+		dec.SetLimits(tt.limits)
+		var v interface{}
+		err := dec.Decode(&v)
+		if tt.ok && err != nil {
+			t.Errorf("Decode(%.20q) with %+v: %v", tt.in, tt.limits, err)
+		} else if _, ok := err.(*SyntaxError); !tt.ok && !ok {
+			t.Errorf("Decode(%.20q) with %+v: error %v, want SyntaxError", tt.in, tt.limits, err)
+		}
+	}
+}
+
+// cancelReader calls cancel after its first Read.
+type cancelReader struct {
+	r      io.Reader
+	cancel context.CancelFunc
+}
+
+func (r *cancelReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.cancel()
+	return n, err
+}
+
+func TestDecoderDecodeContext(t *testing.T) {
+	big := "{ " + strings.Repeat("1, ", 100000) + "1 }"
+	tests := []struct {
+		name   string
+		in     string
+		cancel string // "", "before" or "reading"
+		want   error
+	}{
+		{"background", "5", "", nil},
+		{"large", big, "", nil},
+		{"cancelled before", "5", "before", context.Canceled},
+		{"cancelled while reading", big, "reading", context.Canceled},
+	}
+	for _, tt := range tests {
+		ctx, cancel := context.WithCancel(context.Background())
+		var r io.Reader = strings.NewReader(tt.in)
+		switch tt.cancel {
+		case "before":
+			cancel()
+		case "reading":
+			r = &cancelReader{iotest.HalfReader(r), cancel}
+		}
+		dec := NewDecoder(r)
+		var v interface{}
+		if err := dec.DecodeContext(ctx, &v); err != tt.want {
+			t.Errorf("%s: DecodeContext = %v, want %v", tt.name, err, tt.want)
+		}
+		switch tt.cancel {
+		case "before":
+			// Nothing was read, so the Decoder is still usable.
+			if err := dec.Decode(&v); err != nil || v != int64(5) {
+				t.Errorf("%s: Decode after DecodeContext = %v, %v", tt.name, v, err)
+			}
+		case "reading":
+			if err := dec.Decode(&v); err != tt.want {
+				t.Errorf("%s: Decode after DecodeContext = %v, want %v", tt.name, err, tt.want)
+			}
+		}
+		cancel()
+	}
+}
+
+func TestDecoderCollectErrors(t *testing.T) {
+	const lint = `value1 PE ::= {
+  a 1 ]
+  b { c 2 }
+}
+value2 PE ::= { x 'GG'H, y 2 }
+value3 PE ::= { ok 1 }
+value4 PE ::= { broken 1
+value5 PE ::= { z "a
+b" }
+value6 PE ::= { q 1 } }
+value7 PE ::= { -- comment {
+  r 5 }
+`
+	tests := []struct {
+		in      string
+		n       int
+		names   []string // of the values decoded
+		offsets []int64  // of the errors collected
+	}{
+		{"v1 T ::= 1\nv2 T ::= 2\n", 10, []string{"v1", "v2"}, nil},
+		{lint, 10, []string{"value3", "value5", "value6", "value7"}, []int64{23, 58, 118, 166}},
+		{lint, 2, nil, []int64{23, 58}},
+		{lint, 1, nil, []int64{23}},
+	}
+	for _, tt := range tests {
+		dec := NewDecoder(iotest.OneByteReader(strings.NewReader(tt.in)))
+		dec.CollectErrors(tt.n)
+		var names []string
+		var offsets []int64
+		for dec.More() {
+			var v ValueAssignment
+			err := dec.Decode(&v)
+			if err != nil {
+				errs, ok := err.(SyntaxErrors)
+				if !ok {
+					t.Fatalf("Decode(%.20q) with %d: error %v, want SyntaxErrors", tt.in, tt.n, err)
+				}
+				for _, e := range errs {
+					offsets = append(offsets, e.Offset)
+				}
+				break
+			}
+			names = append(names, v.Name)
+		}
+		if !reflect.DeepEqual(names, tt.names) || !reflect.DeepEqual(offsets, tt.offsets) {
+			t.Errorf("Decode(%.20q) with %d: values %q, errors at %v, want %q, %v", tt.in, tt.n, names, offsets, tt.names, tt.offsets)
+		}
+	}
+}
+
+func TestSyntaxErrors(t *testing.T) {
+	a := &SyntaxError{msg: "a", Offset: 1, err: ErrLimitExceeded}
+	b := &SyntaxError{msg: "b", Offset: 2}
+	tests := []struct {
+		errs SyntaxErrors
+		want string
+		is   bool // errors.Is ErrLimitExceeded
+	}{
+		{nil, "no errors", false},
+		{SyntaxErrors{b}, b.Error(), false},
+		{SyntaxErrors{a, b}, a.Error() + " (and 1 more errors)", true},
+		{SyntaxErrors{b, b, a}, b.Error() + " (and 2 more errors)", true},
+	}
+	for _, tt := range tests {
+		if got := tt.errs.Error(); got != tt.want {
+			t.Errorf("SyntaxErrors(%d).Error() = %q, want %q", len(tt.errs), got, tt.want)
+		}
+		if got := errors.Is(tt.errs, ErrLimitExceeded); got != tt.is {
+			t.Errorf("errors.Is(SyntaxErrors(%d), ErrLimitExceeded) = %v, want %v", len(tt.errs), got, tt.is)
+		}
+	}
+}
+
+func TestDecoderRequireSingleValue(t *testing.T) {
+	tests := []struct {
+		in   string
+		want interface{}
+		ok   bool
+	}{
+		{"5", int64(5), true},
+		{"{ a 1 } -- c\n", OrderedObject{{"a", int64(1)}}, true},
+		{"5 6", nil, false},
+		{"{ a 1 } x", nil, false},
+		{"v T ::= 1 w T ::= 2", nil, false},
+	}
+	for _, tt := range tests {
+		dec := NewDecoder(iotest.OneByteReader(strings.NewReader(tt.in)))
+		dec.RequireSingleValue()
+		var v interface{}
+		err := dec.Decode(&v)
+		if !tt.ok {
+			if _, ok := err.(*SyntaxError); !ok {
+				t.Errorf("Decode(%q): error %v, want SyntaxError", tt.in, err)
+			}
+			if err2 := dec.Decode(&v); err2 != err {
+				t.Errorf("Decode(%q) again = %v, want %v", tt.in, err2, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Decode(%q): %v", tt.in, err)
+		} else if !reflect.DeepEqual(v, tt.want) {
+			t.Errorf("Decode(%q) = %#v, want %#v", tt.in, v, tt.want)
+		}
+		if err := dec.Decode(&v); err != io.EOF {
+			t.Errorf("Decode(%q) at the end = %v, want io.EOF", tt.in, err)
+		}
+	}
+}
+
+func TestScanValues(t *testing.T) {
+	tests := []struct {
+		in   string
+		size int // of the buffer of the Scanner, or 0
+		want []string
+		err  bool
+	}{
+		{"5", 0, []string{"5"}, false},
+		{"  -- c\n -5  x T ::= { a { b { 1 2 } } } -- t\n y T ::= v  ", 0, []string{"-5", "x T ::= { a { b { 1 2 } } }", "y T ::= v"}, false},
+		{"  ", 0, nil, false},
+		{"{ a 1 } { b", 0, []string{"{ a 1 }"}, true},
+		{"1 { a 1, b 2, c 3, d 4 } 2", 16, []string{"1"}, true},
+	}
+	for _, tt := range tests {
+		sc := bufio.NewScanner(iotest.OneByteReader(strings.NewReader(tt.in)))
+		if tt.size > 0 {
+			sc.Buffer(nil, tt.size)
+		}
+		sc.Split(ScanValues)
+		var got []string
+		for sc.Scan() {
+			got = append(got, sc.Text())
+		}
+		if !reflect.DeepEqual(got, tt.want) || (sc.Err() != nil) != tt.err {
+			t.Errorf("ScanValues(%q) = %q, %v, want %q", tt.in, got, sc.Err(), tt.want)
+		}
+	}
+}
+
+func TestDecoderResolveReferences(t *testing.T) {
+	const in = `ub-filesize INTEGER ::= 1024
+inner Inner ::= { a ub-filesize }
+l Limits ::= { maxSize ub-filesize, name nm, kind enumv, inner inner }
+bad Limits ::= { maxSize nm }
+c X ::= cyc
+`
+	refs := map[string]RawValue{"nm": RawValue(`"hello"`), "cyc": RawValue("cyc2"), "cyc2": RawValue("{ cyc }")}
+	tests := []struct {
+		name  string
+		want  interface{}
+		cycle []string // of the ValueReferenceError, if any
+	}{
+		{"ub-filesize", int64(1024), nil},
+		{"inner", OrderedObject{{"a", int64(1024)}}, nil},
+		{"l", OrderedObject{{"maxSize", int64(1024)}, {"name", "hello"}, {"kind", "enumv"}, {"inner", OrderedObject{{"a", int64(1024)}}}}, nil},
+		{"bad", OrderedObject{{"maxSize", "hello"}}, nil},
+		{"c", nil, []string{"cyc", "cyc2", "cyc"}},
+	}
+	dec := NewDecoder(strings.NewReader(in))
+	dec.ResolveReferences(refs)
+	for _, tt := range tests {
+		var va ValueAssignment
+		err := dec.Decode(&va)
+		if va.Name != tt.name {
+			t.Fatalf("Decode = %s, want %s", va.Name, tt.name)
+		}
+		if tt.cycle != nil {
+			vre, ok := err.(*ValueReferenceError)
+			if !ok || !reflect.DeepEqual(vre.Cycle, tt.cycle) {
+				t.Errorf("Decode(%s): error %v, want cycle %v", tt.name, err, tt.cycle)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Decode(%s): %v", tt.name, err)
+		} else if !reflect.DeepEqual(va.Value, tt.want) {
+			t.Errorf("Decode(%s) = %#v, want %#v", tt.name, va.Value, tt.want)
+		}
+	}
+
+	// Without ResolveReferences, references are identifiers.
+	dec = NewDecoder(strings.NewReader(in))
+	var v, w interface{}
+	if err := dec.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	if err := dec.Decode(&w); err != nil || !reflect.DeepEqual(w, OrderedObject{{"a", "ub-filesize"}}) {
+		t.Errorf("Decode without ResolveReferences = %#v, %v", w, err)
+	}
+}
+
+func TestDecoderSetHexPolicy(t *testing.T) {
+	type record struct {
+		A []byte
+		B BitString
+		C string
+		D interface{}
+	}
+	const in = `{ a 'abc'H, b 'ABC'H, c 'a bc'H, d 'ABC'H }`
+	tests := []struct {
+		policy HexPolicy
+		want   record
+		ok     bool
+	}{
+		{HexReject, record{}, false},
+		{HexPadLeft, record{[]byte{0x0A, 0xBC}, BitString{[]byte{0x0A, 0xBC}, 16}, "0ABC", []byte{0x0A, 0xBC}}, true},
+		{HexPadRight, record{[]byte{0xAB, 0xC0}, BitString{[]byte{0xAB, 0xC0}, 12}, "ABC0", []byte{0xAB, 0xC0}}, true},
+	}
+	for _, tt := range tests {
+		dec := NewDecoder(strings.NewReader(in))
+		dec.SetHexPolicy(tt.policy)
+		var r record
+		err := dec.Decode(&r)
+		if !tt.ok {
+			if err == nil {
+				t.Errorf("Decode with policy %d: no error", tt.policy)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Decode with policy %d: %v", tt.policy, err)
+		} else if !reflect.DeepEqual(r, tt.want) {
+			t.Errorf("Decode with policy %d = %#v, want %#v", tt.policy, r, tt.want)
+		}
+	}
+}
+
+func TestDecoderDisallowDuplicateComponents(t *testing.T) {
+	tests := []struct {
+		in     string
+		ptr    interface{}
+		want   interface{}
+		offset int64 // of the DuplicateComponentError, or -1
+	}{
+		{"{ a 1, b 2 }", new(struct{ A, B int }), struct{ A, B int }{1, 2}, -1},
+		{"{ a 1, a 2 }", new(struct{ A, B int }), struct{ A, B int }{A: 2}, 7},
+		{"{ b 1, a { a 1, a 2 } }", new(map[string]interface{}), map[string]interface{}{"b": int64(1), "a": OrderedObject{{"a", int64(1)}, {"a", int64(2)}}}, -1},
+		{"{ b 1, b 2 }", new(map[string]int), map[string]int{"b": 2}, 7},
+		{"{ a 1, a 2 }", new(interface{}), OrderedObject{{"a", int64(1)}, {"a", int64(2)}}, -1},
+	}
+	for _, tt := range tests {
+		dec := NewDecoder(strings.NewReader(tt.in))
+		dec.DisallowDuplicateComponents()
+		err := dec.Decode(tt.ptr)
+		if tt.offset < 0 {
+			if err != nil {
+				t.Errorf("Decode(%q, %T): %v", tt.in, tt.ptr, err)
+			}
+		} else if dce, ok := err.(*DuplicateComponentError); !ok || dce.Offset != tt.offset {
+			t.Errorf("Decode(%q, %T): error %v, want DuplicateComponentError at %d", tt.in, tt.ptr, err, tt.offset)
+		}
+		if got := reflect.ValueOf(tt.ptr).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Decode(%q, %T) = %#v, want %#v", tt.in, tt.ptr, got, tt.want)
+		}
+	}
+}
+
+func TestDecoderBuffered(t *testing.T) {
+	tests := []struct {
+		in     string
+		offset int64
+		rest   string
+	}{
+		{"5", 1, ""},
+		{"  5  ", 5, ""},
+		{"5 6", 2, "6"},
+		{"{ a 1 } -- c\n  v T ::= 2", 15, "v T ::= 2"},
+		{"{ a 1 }\n\x00\x01binary", 8, "\x00\x01binary"},
+		{"v T ::= { a 1 }|rest", 15, "|rest"},
+	}
+	for _, tt := range tests {
+		for _, one := range []bool{false, true} {
+			var r io.Reader = strings.NewReader(tt.in)
+			if one {
+				r = iotest.OneByteReader(r)
+			}
+			dec := NewDecoder(r)
+			var v interface{}
+			if err := dec.Decode(&v); err != nil {
+				t.Fatalf("Decode(%q): %v", tt.in, err)
+			}
+			if got := dec.InputOffset(); got != tt.offset {
+				t.Errorf("InputOffset after Decode(%q) = %d, want %d", tt.in, got, tt.offset)
+			}
+			rest, err := io.ReadAll(io.MultiReader(dec.Buffered(), r))
+			if err != nil || string(rest) != tt.rest {
+				t.Errorf("Buffered after Decode(%q) and the rest = %q, %v, want %q", tt.in, rest, err, tt.rest)
+			}
+		}
+	}
+}
+
+func TestDecoderUseSchema(t *testing.T) {
+	m, err := ParseModule([]byte(`M DEFINITIONS AUTOMATIC TAGS ::= BEGIN
+S ::= SEQUENCE { bits BIT STRING, r REAL, n INTEGER { one(1), two(2) }, l SEQUENCE OF BIT STRING, c CHOICE { rel RELATIVE-OID, x INTEGER } }
+F ::= BIT STRING { a(0), b(1), c(2) }
+T ::= GeneralizedTime
+END`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		in   string
+		want interface{}
+	}{
+		{"v S ::= { bits 'A0'H, r 5, n two, l { 'FF'H, { } }, c rel : { 4 3 } }", OrderedObject{
+			{"bits", BitString{[]byte{0xA0}, 8}},
+			{"r", 5.0},
+			{"n", int64(2)},
+			{"l", []interface{}{BitString{[]byte{0xFF}, 8}, BitString{}}},
+			{"c", map[string]interface{}{"rel": RelativeOID{4, 3}}},
+		}},
+		{"v S ::= { n three, c other : 1 }", OrderedObject{{"n", "three"}, {"c", map[string]interface{}{"other": int64(1)}}}},
+		{"w F ::= { a, c }", BitString{[]byte{0xA0}, 3}},
+		{"x T ::= \"20240102030405Z\"", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		// Values that do not fit their types are stored as without a schema.
+		{"y T ::= \"nonsense\"", "nonsense"},
+		{"z Other ::= { a 1 }", OrderedObject{{"a", int64(1)}}},
+		{"5", int64(5)},
+	}
+	for _, tt := range tests {
+		dec := NewDecoder(strings.NewReader(tt.in))
+		dec.UseSchema(m)
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			t.Errorf("Decode(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(v, tt.want) {
+			t.Errorf("Decode(%q) = %#v, want %#v", tt.in, v, tt.want)
+		}
+	}
+}
+
+func TestDecoderKeepUnknownAlternatives(t *testing.T) {
+	type header struct{ Major int }
+	type element struct {
+		Header  *header   `asn1:"header,choice"`
+		Unknown RawChoice `asn1:"*,choice"`
+	}
+	tests := []struct {
+		in      string
+		keep    bool
+		unknown RawChoice
+		out     string // as Marshal writes the value back, or "" if it cannot
+	}{
+		{"header : { major 2 }", true, RawChoice{}, "header : { major 2 }"},
+		{"newThing : { a 1, b { 2 } } -- c", true, RawChoice{"newThing", RawValue("{ a 1, b { 2 } }")}, "newThing : { a 1, b { 2 } }"},
+		{"newThing : 5", true, RawChoice{"newThing", RawValue("5")}, "newThing : 5"},
+		{"newThing : 5", false, RawChoice{}, ""},
+	}
+	for _, tt := range tests {
+		dec := NewDecoder(strings.NewReader(tt.in))
+		if tt.keep {
+			dec.KeepUnknownAlternatives()
+		}
+		var v element
+		if err := dec.Decode(&v); err != nil {
+			t.Errorf("Decode(%q): %v", tt.in, err)
+			continue
+		}
+		if v.Unknown.Alternative != tt.unknown.Alternative || string(v.Unknown.Value) != string(tt.unknown.Value) {
+			t.Errorf("Decode(%q) kept %+v, want %+v", tt.in, v.Unknown, tt.unknown)
+		}
+		b, err := Marshal(v)
+		if tt.out == "" {
+			if err == nil {
+				t.Errorf("Marshal(%+v) = %s, want an error", v, b)
+			}
+		} else if err != nil || string(b) != tt.out {
+			t.Errorf("Marshal(%+v) = %s, %v, want %s", v, b, err, tt.out)
+		}
+	}
+
+	// A struct without a RawChoice field skips unknown alternatives.
+	dec := NewDecoder(strings.NewReader("newThing : 5"))
+	dec.KeepUnknownAlternatives()
+	var v struct {
+		Header *header `asn1:"header,choice"`
+	}
+	if err := dec.Decode(&v); err != nil || v.Header != nil {
+		t.Errorf("Decode without RawChoice = %+v, %v", v, err)
+	}
+}
+
+func TestDecoderStats(t *testing.T) {
+	tests := []struct {
+		in   string
+		want DecoderStats
+	}{
+		{"", DecoderStats{}},
+		{"5", DecoderStats{Values: 1, Literals: 1, Bytes: 1}},
+		{"v T ::= { a 'ABCD'H, b '0101'B, c \"x\", d { { 1 } } }\nw T ::= 2 -- c\n",
+			DecoderStats{Values: 2, Objects: 3, Literals: 5, MaxDepth: 3, HexBytes: 3, Bytes: 68}},
+		{"{ a 'ABC'H", DecoderStats{Objects: 1, Literals: 1, MaxDepth: 1, HexBytes: 2, Bytes: 10}},
+		{"{ a { b 1 ] } } 5", DecoderStats{Values: 1, Objects: 2, Literals: 2, MaxDepth: 2, Bytes: 17}},
+	}
+	for _, tt := range tests {
+		dec := NewDecoder(iotest.OneByteReader(strings.NewReader(tt.in)))
+		dec.CollectErrors(5)
+		for dec.More() {
+			var v interface{}
+			if err := dec.Decode(&v); err != nil {
+				break
+			}
+		}
+		if got := dec.Stats(); got != tt.want {
+			t.Errorf("Stats after Decode(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDecoderRegisterLiteralResolver(t *testing.T) {
+	lowerHex := func(lit []byte, t reflect.Type) (interface{}, bool, error) {
+		if t.Kind() != reflect.String && t.Kind() != reflect.Interface {
+			return nil, false, nil
+		}
+		return strings.ToLower(string(lit[1 : len(lit)-2])), true, nil
+	}
+	seven := func(lit []byte, t reflect.Type) (interface{}, bool, error) { return int8(7), true, nil }
+	zero := func(lit []byte, t reflect.Type) (interface{}, bool, error) { return nil, true, nil }
+	upperRed := func(lit []byte, t reflect.Type) (interface{}, bool, error) {
+		return strings.ToUpper(string(lit)), string(lit) == "red", nil
+	}
+	type record struct {
+		A string
+		B []byte
+		C interface{}
+		D int
+		E bool
+	}
+	const in = "{ a 'ABCD'H, b 'ABCD'H, c 'EF'H, d 5, e TRUE }"
+	tests := []struct {
+		in   string
+		kind TokenKind
+		fn   LiteralResolver
+		ptr  interface{}
+		want interface{}
+	}{
+		{in, TokenHexString, lowerHex, new(record), record{"abcd", []byte{0xAB, 0xCD}, "ef", 5, true}},
+		{in, TokenNumber, seven, new(record), record{"ABCD", []byte{0xAB, 0xCD}, []byte{0xEF}, 7, true}},
+		{in, TokenBool, zero, new(record), record{"ABCD", []byte{0xAB, 0xCD}, []byte{0xEF}, 5, false}},
+		{"{ a red, b ref }", TokenNamedValue, upperRed, new(interface{}), OrderedObject{{"a", "RED"}, {"b", "ref"}}},
+	}
+	for _, tt := range tests {
+		dec := NewDecoder(strings.NewReader(tt.in))
+		dec.RegisterLiteralResolver(tt.kind, tt.fn)
+		if err := dec.Decode(tt.ptr); err != nil {
+			t.Errorf("Decode(%q) with a resolver of %v: %v", tt.in, tt.kind, err)
+			continue
+		}
+		if got := reflect.ValueOf(tt.ptr).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Decode(%q) with a resolver of %v = %#v, want %#v", tt.in, tt.kind, got, tt.want)
+		}
+	}
+
+	failed := errors.New("failed")
+	errTests := []struct {
+		fn   LiteralResolver
+		want error // nil for an UnmarshalTypeError
+	}{
+		{func(lit []byte, t reflect.Type) (interface{}, bool, error) { return nil, false, failed }, failed},
+		{func(lit []byte, t reflect.Type) (interface{}, bool, error) { return "x", true, nil }, nil},
+	}
+	for _, tt := range errTests {
+		dec := NewDecoder(strings.NewReader(in))
+		dec.RegisterLiteralResolver(TokenNumber, tt.fn)
+		var r record
+		err := dec.Decode(&r)
+		if tt.want != nil {
+			if err != tt.want {
+				t.Errorf("Decode with a failing resolver: error %v, want %v", err, tt.want)
+			}
+		} else if _, ok := err.(*UnmarshalTypeError); !ok {
+			t.Errorf("Decode with a resolver of the wrong type: error %v, want UnmarshalTypeError", err)
+		}
+	}
+
+	// A nil resolver removes the one registered.
+	dec := NewDecoder(strings.NewReader(in))
+	dec.RegisterLiteralResolver(TokenNumber, seven)
+	dec.RegisterLiteralResolver(TokenNumber, nil)
+	var r record
+	if err := dec.Decode(&r); err != nil || r.D != 5 {
+		t.Errorf("Decode after removing the resolver = %+v, %v", r, err)
+	}
+}
+
+// pushSplit pushes in to a PushDecoder in the three chunks in[:i], in[i:j]
+// and in[j:], and returns the values it hands over and its first error.
+func pushSplit(in string, i, j int) ([]string, error) {
+	var values []string
+	p := NewPushDecoder(func(v RawValue) error {
+		values = append(values, string(v))
+		return nil
+	})
+	for _, chunk := range []string{in[:i], in[i:j], in[j:]} {
+		if err := p.Feed([]byte(chunk)); err != nil {
+			return values, err
+		}
+	}
+	return values, p.Finish()
+}
+
+func TestPushDecoder(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"  -- only a comment", nil},
+		{"5", []string{"5"}},
+		{"5 6", []string{"5 ", "6"}},
+		{"{ a 1 }{ b 2 }", []string{"{ a 1 }", "{ b 2 }"}},
+		{
+			"-- header\nv T ::= { a '0A'H, b \"say \"\"hi\"\"\" } -- trailing\nw T ::= -12.5e3\n\n{ c TRUE, d { 1 2 } }  ",
+			[]string{
+				"v T ::= { a '0A'H, b \"say \"\"hi\"\"\" } -- trailing\n",
+				"w T ::= -12.5e3\n\n",
+				"{ c TRUE, d { 1 2 } }  ",
+			},
+		},
+	}
+	for _, tt := range tests {
+		// Every split of the input into chunks, including those in the
+		// middle of a token, gives the same values.
+		for i := 0; i <= len(tt.in); i++ {
+			for j := i; j <= len(tt.in); j++ {
+				values, err := pushSplit(tt.in, i, j)
+				if err != nil || !reflect.DeepEqual(values, tt.want) {
+					t.Fatalf("PushDecoder(%q) split at %d, %d: values %q, error %v, want %q", tt.in, i, j, values, err, tt.want)
+				}
+			}
+		}
+	}
+}
+
+func TestPushDecoderSyntaxError(t *testing.T) {
+	tests := []struct {
+		in     string
+		values int // values handed over before the error
+		offset int64
+		line   int
+		eof    bool // whether the input ends inside a value
+	}{
+		{"{ a 1 ]", 0, 7, 1, false},
+		{"v T ::= 1\nw T ::= {\n a 1,\n b 2 ]", 1, 32, 4, false},
+		{"v T : 1", 0, 6, 1, false},
+		{"v T ::= {\n a 1", 0, 14, 2, true},
+		{"5 6 {", 2, 5, 1, true},
+		{"1 \"a\"\"b", 1, 7, 1, true},
+	}
+	for _, tt := range tests {
+		for i := 0; i <= len(tt.in); i++ {
+			for j := i; j <= len(tt.in); j++ {
+				values, err := pushSplit(tt.in, i, j)
+				se, ok := err.(*SyntaxError)
+				if !ok {
+					t.Fatalf("PushDecoder(%q) split at %d, %d: error %v, want SyntaxError", tt.in, i, j, err)
+				}
+				if len(values) != tt.values || se.Offset != tt.offset || se.Line != tt.line || errors.Is(err, ErrUnexpectedEOF) != tt.eof {
+					t.Fatalf("PushDecoder(%q) split at %d, %d: %d values, error at offset %d, line %d: %v\nwant %d values, offset %d, line %d, end of input %v",
+						tt.in, i, j, len(values), se.Offset, se.Line, err, tt.values, tt.offset, tt.line, tt.eof)
+				}
+			}
+		}
+	}
+}
+
+func TestPushDecoderStopsAtError(t *testing.T) {
+	// A syntax error is returned from then on, without further values.
+	// The value before it is complete once the invalid byte is read.
+	calls := 0
+	p := NewPushDecoder(func(RawValue) error {
+		calls++
+		return nil
+	})
+	err := p.Feed([]byte("1 2 ]"))
+	if _, ok := err.(*SyntaxError); !ok {
+		t.Fatalf("Feed: error %v, want SyntaxError", err)
+	}
+	if err2 := p.Feed([]byte(" 3 4")); err2 != err {
+		t.Errorf("Feed after a syntax error: error %v, want %v", err2, err)
+	}
+	if err2 := p.Finish(); err2 != err {
+		t.Errorf("Finish after a syntax error: error %v, want %v", err2, err)
+	}
+	if calls != 2 {
+		t.Errorf("function called %d times, want 2", calls)
+	}
+
+	// So is the error of the function, whether Feed or Finish completes
+	// the value it fails on.
+	errStop := errors.New("stop")
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"1 2 3 4", []string{"1 ", "2 "}},
+		{"1 2", []string{"1 ", "2"}},
+	}
+	for _, tt := range tests {
+		var values []string
+		p := NewPushDecoder(func(v RawValue) error {
+			values = append(values, string(v))
+			if len(values) == 2 {
+				return errStop
+			}
+			return nil
+		})
+		var err error
+		for i := 0; i < len(tt.in) && err == nil; i++ {
+			err = p.Feed([]byte{tt.in[i]})
+		}
+		if err == nil {
+			err = p.Finish()
+		}
+		if err != errStop || !reflect.DeepEqual(values, tt.want) {
+			t.Errorf("PushDecoder(%q): values %q, error %v, want %q, %v", tt.in, values, err, tt.want, errStop)
+		}
+		if err := p.Feed([]byte(" 5")); err != errStop {
+			t.Errorf("PushDecoder(%q): Feed after the error of the function: error %v, want %v", tt.in, err, errStop)
+		}
+		if err := p.Finish(); err != errStop || len(values) != 2 {
+			t.Errorf("PushDecoder(%q): Finish after the error of the function: error %v after %d values, want %v after 2", tt.in, err, len(values), errStop)
+		}
+	}
+}
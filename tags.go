@@ -0,0 +1,71 @@
+package asn1go
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tagOptions is the string following a comma in a struct field's "asn1"
+// tag, or the empty string. It does not include the leading comma.
+type tagOptions string
+
+// parseTag splits a struct field's asn1 tag into its name and
+// comma-separated options.
+func parseTag(tag string) (string, tagOptions) {
+	name, opt, _ := strings.Cut(tag, ",")
+	return name, tagOptions(opt)
+}
+
+// Contains reports whether a comma-separated list of options
+// contains a particular substr flag. substr must be surrounded by a
+// string boundary or commas.
+func (o tagOptions) Contains(optionName string) bool {
+	if len(o) == 0 {
+		return false
+	}
+	s := string(o)
+	for s != "" {
+		var name string
+		name, s, _ = strings.Cut(s, ",")
+		if name == optionName {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the value of the option written as optionName:value, and
+// whether the list contains it.
+func (o tagOptions) Get(optionName string) (string, bool) {
+	s := string(o)
+	for s != "" {
+		var opt string
+		opt, s, _ = strings.Cut(s, ",")
+		if name, value, ok := strings.Cut(opt, ":"); ok && name == optionName {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// namedNumbers returns the named values of an INTEGER or ENUMERATED, or
+// the named bits of a BIT STRING, of the option written as
+// named:identifier=number|identifier=number..., such as named:v1=0|v2=1,
+// or nil if the list does not contain it.
+func (o tagOptions) namedNumbers() ([]NamedNumber, error) {
+	list, ok := o.Get("named")
+	if !ok {
+		return nil, nil
+	}
+	var named []NamedNumber
+	for _, item := range strings.Split(list, "|") {
+		name, num, _ := strings.Cut(item, "=")
+		n, err := strconv.ParseInt(num, 10, 64)
+		if err != nil || !isValidIdentifier(name) {
+			return nil, fmt.Errorf("asn1go: invalid named number %q in tag option named", item)
+		}
+		named = append(named, NamedNumber{Name: name, Value: n})
+	}
+	return named, nil
+}
@@ -0,0 +1,413 @@
+package asn1go
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// timeKind returns the time type the tag options select for a time.Time:
+// UTCTime with the "utc" option and GeneralizedTime otherwise.
+func timeKind(opts tagOptions) Kind {
+	if opts.Contains("utc") {
+		return KindUTCTime
+	}
+	return KindGeneralizedTime
+}
+
+// parseTime parses s as a value of the UTCTime or GeneralizedTime type k:
+//
+//	UTCTime          YYMMDDhhmm[ss], with the year from 1950 to 2049
+//	GeneralizedTime  YYYYMMDDhh[mm[ss]], with a fraction of its last
+//	                 unit after a dot or comma, as in 20230115120000.5
+//
+// followed by Z for UTC or an offset +hhmm or -hhmm from it; a
+// GeneralizedTime may also have an offset of whole hours, +hh, or none
+// for the local time.
+func parseTime(k Kind, s string) (time.Time, error) {
+	invalid := func() (time.Time, error) {
+		return time.Time{}, errors.New("asn1go: invalid " + k.String() + " " + strconv.Quote(s))
+	}
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	digits, rest := s[:i], s[i:]
+	num := func(j, n int) int {
+		v, _ := strconv.Atoi(digits[j : j+n])
+		return v
+	}
+	var year, month, day, hour, min, sec int
+	unit := time.Hour
+	switch {
+	case k == KindUTCTime && (len(digits) == 10 || len(digits) == 12):
+		year = 1900 + num(0, 2)
+		if year < 1950 {
+			year += 100
+		}
+		digits = digits[2:]
+	case k == KindGeneralizedTime && (len(digits) == 10 || len(digits) == 12 || len(digits) == 14):
+		year = num(0, 4)
+		digits = digits[4:]
+	default:
+		return invalid()
+	}
+	month, day, hour = num(0, 2), num(2, 2), num(4, 2)
+	if len(digits) >= 8 {
+		min, unit = num(6, 2), time.Minute
+	}
+	if len(digits) == 10 {
+		sec, unit = num(8, 2), time.Second
+	}
+
+	// A fraction of the last unit, which UTCTime does not have.
+	var frac time.Duration
+	if k == KindGeneralizedTime && rest != "" && (rest[0] == '.' || rest[0] == ',') {
+		j := 1
+		for j < len(rest) && isDigit(rest[j]) {
+			j++
+		}
+		if j == 1 {
+			return invalid()
+		}
+		// Nanoseconds of the fraction of a second, times the
+		// seconds of the unit.
+		ns, _ := strconv.Atoi((rest[1:j] + "000000000")[:9])
+		frac = time.Duration(ns) * (unit / time.Second)
+		rest = rest[j:]
+	}
+
+	var loc *time.Location
+	switch {
+	case rest == "Z":
+		loc = time.UTC
+	case rest == "" && k == KindGeneralizedTime:
+		loc = time.Local
+	case (len(rest) == 5 || len(rest) == 3 && k == KindGeneralizedTime) && (rest[0] == '+' || rest[0] == '-'):
+		if !allDigits(rest[1:]) {
+			return invalid()
+		}
+		h, _ := strconv.Atoi(rest[1:3])
+		m := 0
+		if len(rest) == 5 {
+			m, _ = strconv.Atoi(rest[3:])
+		}
+		if h > 23 || m > 59 {
+			return invalid()
+		}
+		offset := h*3600 + m*60
+		if rest[0] == '-' {
+			offset = -offset
+		}
+		loc = time.FixedZone("", offset)
+	default:
+		return invalid()
+	}
+
+	t := time.Date(year, time.Month(month), day, hour, min, sec, 0, loc)
+	if t.Year() != year || int(t.Month()) != month || t.Day() != day || t.Hour() != hour || t.Minute() != min || t.Second() != sec {
+		return invalid()
+	}
+	return t.Add(frac), nil
+}
+
+func allDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// formatTime returns t as a value of the UTCTime or GeneralizedTime type
+// k, with the seconds and any fraction of them, and with Z for UTC or the
+// offset from it, which must be of whole minutes and less than a day. A
+// UTCTime has no fraction of a second and a year from 1950 to 2049.
+func formatTime(k Kind, t time.Time) (string, error) {
+	if _, offset := t.Zone(); offset%60 != 0 || offset <= -24*3600 || offset >= 24*3600 {
+		return "", errors.New("offset of " + strconv.Itoa(offset) + " seconds from UTC cannot be written as " + k.String())
+	}
+	if k == KindGeneralizedTime {
+		if t.Year() < 0 || t.Year() > 9999 {
+			return "", errors.New("year " + strconv.Itoa(t.Year()) + " cannot be written as GeneralizedTime")
+		}
+		return t.Format("20060102150405.999999999Z0700"), nil
+	}
+	if t.Year() < 1950 || t.Year() > 2049 {
+		return "", errors.New("year " + strconv.Itoa(t.Year()) + " cannot be written as UTCTime")
+	}
+	if t.Nanosecond() != 0 {
+		return "", errors.New("fraction of a second cannot be written as UTCTime")
+	}
+	return t.Format("060102150405Z0700"), nil
+}
+
+// timeString returns the time.Time v as the value of the UTCTime or
+// GeneralizedTime type t in the canonical form of DER, in UTC, if v is a
+// time.Time, and the Date, TimeOfDay, DateTime or Duration v as the value
+// of the type t of its kind.
+func timeString(t *Type, v reflect.Value) (s string, ok bool, err error) {
+	if !v.IsValid() {
+		return "", false, nil
+	}
+	if isDateType(v.Type(), t.Kind) {
+		s, err = formatDate(v.Interface())
+		return s, true, err
+	}
+	if v.Type() != timeType || t.Kind != KindUTCTime && t.Kind != KindGeneralizedTime {
+		return "", false, nil
+	}
+	s, err = formatTime(t.Kind, v.Interface().(time.Time).UTC())
+	return s, true, err
+}
+
+// Date is the Go representation of a value of the DATE type, such as
+// "2023-01-15": the day of Time, which Unmarshal sets to midnight UTC.
+type Date struct {
+	time.Time
+}
+
+// TimeOfDay is the Go representation of a value of the TIME-OF-DAY type,
+// such as "12:30:00", as the time since midnight.
+type TimeOfDay time.Duration
+
+// String returns the time of day as hh:mm:ss, such as "12:30:00".
+func (t TimeOfDay) String() string {
+	s, err := formatDate(t)
+	if err != nil {
+		return time.Duration(t).String()
+	}
+	return s
+}
+
+// DateTime is the Go representation of a value of the DATE-TIME type,
+// such as "2023-01-15T12:30:00": the date and time of day of Time in its
+// location. The value has no offset from UTC, so Unmarshal sets Time in
+// the local time zone, as it does for a GeneralizedTime without one.
+type DateTime struct {
+	time.Time
+}
+
+// Duration is the Go representation of a value of the DURATION type, such
+// as "P1Y2M3DT4H5M6.5S". Years, months and days have no fixed length, so
+// they are kept apart from the hours, minutes and seconds in Time. A
+// number of weeks, as in "P2W", is read as seven days each.
+type Duration struct {
+	Years, Months, Days int
+	Time                time.Duration
+}
+
+var (
+	dateType      = reflect.TypeOf(Date{})
+	timeOfDayType = reflect.TypeOf(TimeOfDay(0))
+	dateTimeType  = reflect.TypeOf(DateTime{})
+	durationType  = reflect.TypeOf(Duration{})
+)
+
+// dateKind returns the kind of the DATE, TIME-OF-DAY, DATE-TIME or DURATION
+// values of the Go type t, if it is Date, TimeOfDay, DateTime or Duration.
+func dateKind(t reflect.Type) (Kind, bool) {
+	switch t {
+	case dateType:
+		return KindDate, true
+	case timeOfDayType:
+		return KindTimeOfDay, true
+	case dateTimeType:
+		return KindDateTime, true
+	case durationType:
+		return KindDuration, true
+	}
+	return KindInvalid, false
+}
+
+// isDateType reports whether t is the Go type of the values of kind k
+// among Date, TimeOfDay, DateTime and Duration.
+func isDateType(t reflect.Type, k Kind) bool {
+	dk, ok := dateKind(t)
+	return ok && dk == k
+}
+
+// parseDate parses s as a value of the DATE, TIME-OF-DAY, DATE-TIME or
+// DURATION type k and returns it as a Date, TimeOfDay, DateTime or
+// Duration:
+//
+//	DATE         YYYY-MM-DD
+//	TIME-OF-DAY  hh:mm:ss
+//	DATE-TIME    YYYY-MM-DDThh:mm:ss
+//	DURATION     P[nY][nM][nD][T[nH][nM][n[.f]S]], or PnW
+func parseDate(k Kind, s string) (interface{}, error) {
+	var val interface{}
+	var err error
+	switch k {
+	case KindDate:
+		var t time.Time
+		t, err = time.ParseInLocation("2006-01-02", s, time.UTC)
+		val = Date{t}
+	case KindTimeOfDay:
+		var t time.Time
+		t, err = time.Parse("15:04:05", s)
+		val = TimeOfDay(t.Sub(time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)))
+	case KindDateTime:
+		var t time.Time
+		t, err = time.ParseInLocation("2006-01-02T15:04:05", s, time.Local)
+		val = DateTime{t}
+	case KindDuration:
+		var ok bool
+		val, ok = parseDuration(s)
+		if !ok {
+			err = errors.New("invalid duration")
+		}
+	}
+	// time.Parse accepts fractions of a second after the seconds.
+	if err != nil || k != KindDuration && strings.ContainsAny(s, ".,") {
+		return nil, errors.New("asn1go: invalid " + k.String() + " " + strconv.Quote(s))
+	}
+	return val, nil
+}
+
+// parseDuration parses the DURATION value s.
+func parseDuration(s string) (Duration, bool) {
+	var d Duration
+	if len(s) < 3 || s[0] != 'P' {
+		return d, false
+	}
+	s = s[1:]
+	units := "YMWD"
+	inTime := false
+	for s != "" {
+		if s[0] == 'T' && !inTime {
+			inTime, units = true, "HMS"
+			if s = s[1:]; s == "" {
+				return d, false
+			}
+		}
+		i := 0
+		for i < len(s) && isDigit(s[i]) {
+			i++
+		}
+		if i == 0 || i > 9 {
+			return d, false
+		}
+		n, _ := strconv.Atoi(s[:i])
+		var frac time.Duration
+		if inTime && i < len(s) && (s[i] == '.' || s[i] == ',') {
+			// A fraction, of the seconds only.
+			j := i + 1
+			for j < len(s) && isDigit(s[j]) {
+				j++
+			}
+			if j == i+1 || j == len(s) || s[j] != 'S' {
+				return d, false
+			}
+			ns, _ := strconv.Atoi((s[i+1:j] + "000000000")[:9])
+			frac, i = time.Duration(ns), j
+		}
+		if i == len(s) {
+			return d, false
+		}
+		u := strings.IndexByte(units, s[i])
+		if u < 0 {
+			return d, false
+		}
+		switch units[u] {
+		case 'Y':
+			d.Years = n
+		case 'M':
+			if inTime {
+				d.Time += time.Duration(n) * time.Minute
+			} else {
+				d.Months = n
+			}
+		case 'W':
+			// Weeks are not combined with other units.
+			if d != (Duration{}) || i+1 != len(s) {
+				return d, false
+			}
+			d.Days = 7 * n
+		case 'D':
+			d.Days = n
+		case 'H':
+			d.Time += time.Duration(n) * time.Hour
+		case 'S':
+			d.Time += time.Duration(n)*time.Second + frac
+		}
+		// Each unit comes at most once, after those before it.
+		units = units[u+1:]
+		s = s[i+1:]
+	}
+	return d, true
+}
+
+// formatDate returns the Date, TimeOfDay, DateTime or Duration val as a
+// value of its type.
+func formatDate(val interface{}) (string, error) {
+	switch val := val.(type) {
+	case Date:
+		if val.Year() < 0 || val.Year() > 9999 {
+			return "", errors.New("year " + strconv.Itoa(val.Year()) + " cannot be written as DATE")
+		}
+		return val.Format("2006-01-02"), nil
+	case TimeOfDay:
+		d := time.Duration(val)
+		if d < 0 || d >= 24*time.Hour || d%time.Second != 0 {
+			return "", errors.New("TIME-OF-DAY " + d.String() + " is not whole seconds within a day")
+		}
+		return time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC).Add(d).Format("15:04:05"), nil
+	case DateTime:
+		if val.Year() < 0 || val.Year() > 9999 {
+			return "", errors.New("year " + strconv.Itoa(val.Year()) + " cannot be written as DATE-TIME")
+		}
+		if val.Nanosecond() != 0 {
+			return "", errors.New("fraction of a second cannot be written as DATE-TIME")
+		}
+		return val.Format("2006-01-02T15:04:05"), nil
+	case Duration:
+		return formatDuration(val)
+	}
+	panic("asn1go: formatDate of " + reflect.TypeOf(val).String())
+}
+
+// formatDuration returns d as a DURATION value, leaving out the units of
+// which there are none, as in "P1DT2H", or "PT0S" for no time at all.
+func formatDuration(d Duration) (string, error) {
+	if d.Years < 0 || d.Months < 0 || d.Days < 0 || d.Time < 0 {
+		return "", errors.New("negative DURATION")
+	}
+	b := []byte{'P'}
+	for _, c := range []struct {
+		n    int
+		unit byte
+	}{{d.Years, 'Y'}, {d.Months, 'M'}, {d.Days, 'D'}} {
+		if c.n > 0 {
+			b = append(strconv.AppendInt(b, int64(c.n), 10), c.unit)
+		}
+	}
+	if d.Time == 0 {
+		if len(b) == 1 {
+			return "PT0S", nil
+		}
+		return string(b), nil
+	}
+	b = append(b, 'T')
+	h, m, s := d.Time/time.Hour, d.Time%time.Hour/time.Minute, d.Time%time.Minute
+	if h > 0 {
+		b = append(strconv.AppendInt(b, int64(h), 10), 'H')
+	}
+	if m > 0 {
+		b = append(strconv.AppendInt(b, int64(m), 10), 'M')
+	}
+	if s > 0 {
+		b = strconv.AppendInt(b, int64(s/time.Second), 10)
+		if ns := s % time.Second; ns > 0 {
+			b = append(b, '.')
+			b = append(b, strings.TrimRight(strconv.Itoa(int(ns + time.Second))[1:], "0")...)
+		}
+		b = append(b, 'S')
+	}
+	return string(b), nil
+}
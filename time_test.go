@@ -0,0 +1,269 @@
+package asn1go
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUnmarshalDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		ptr  interface{}
+		want interface{} // nil for an error
+	}{
+		{`"2023-01-15"`, new(Date), Date{time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)}},
+		{`"0000-01-01"`, new(Date), Date{time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		{`"2024-02-29"`, new(Date), Date{time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)}},
+		{`"2023-02-29"`, new(Date), nil},
+		{`"2023-1-15"`, new(Date), nil},
+		{`"2023-01-15T00:00:00"`, new(Date), nil},
+		{`"12:30:00"`, new(TimeOfDay), TimeOfDay(12*time.Hour + 30*time.Minute)},
+		{`"00:00:00"`, new(TimeOfDay), TimeOfDay(0)},
+		{`"23:59:59"`, new(TimeOfDay), TimeOfDay(24*time.Hour - time.Second)},
+		{`"24:00:00"`, new(TimeOfDay), nil},
+		{`"12:30"`, new(TimeOfDay), nil},
+		{`"12:30:00.5"`, new(TimeOfDay), nil},
+		{`"2023-01-15T12:30:00"`, new(DateTime), DateTime{time.Date(2023, 1, 15, 12, 30, 0, 0, time.Local)}},
+		{`"2023-01-15 12:30:00"`, new(DateTime), nil},
+		{`"2023-01-15T12:30:00Z"`, new(DateTime), nil},
+		{`"2023-01-15T12:30:00,5"`, new(DateTime), nil},
+		{`"P1Y2M3DT4H5M6.5S"`, new(Duration), Duration{1, 2, 3, 4*time.Hour + 5*time.Minute + 6500*time.Millisecond}},
+		{`"P2W"`, new(Duration), Duration{Days: 14}},
+		{`"PT36H"`, new(Duration), Duration{Time: 36 * time.Hour}},
+		{`"P1M"`, new(Duration), Duration{Months: 1}},
+		{`"PT1M"`, new(Duration), Duration{Time: time.Minute}},
+		{`"PT0,25S"`, new(Duration), Duration{Time: 250 * time.Millisecond}},
+		{`"P"`, new(Duration), nil},
+		{`"PT"`, new(Duration), nil},
+		{`"P1DT"`, new(Duration), nil},
+		{`"1D"`, new(Duration), nil},
+		{`"P1D1Y"`, new(Duration), nil},
+		{`"P1Y1Y"`, new(Duration), nil},
+		{`"P1W2D"`, new(Duration), nil},
+		{`"P1.5D"`, new(Duration), nil},
+		{`"PT1.5M"`, new(Duration), nil},
+		{`"PT1234567890S"`, new(Duration), nil},
+		{`5`, new(Duration), nil},
+		{`TRUE`, new(Date), nil},
+	}
+	for _, tt := range tests {
+		v := reflect.New(reflect.TypeOf(tt.ptr).Elem())
+		err := Unmarshal([]byte(tt.in), v.Interface())
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("Unmarshal(%s, %T) = %v, want an error", tt.in, tt.ptr, v.Elem())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unmarshal(%s, %T): %v", tt.in, tt.ptr, err)
+			continue
+		}
+		if got := v.Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Unmarshal(%s, %T) = %v, want %v", tt.in, tt.ptr, got, tt.want)
+		}
+	}
+}
+
+func TestMarshalDate(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want string // "" for an error
+	}{
+		{Date{time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)}, `"2023-01-15"`},
+		{Date{time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)}, ""},
+		{TimeOfDay(12*time.Hour + 30*time.Minute), `"12:30:00"`},
+		{TimeOfDay(0), `"00:00:00"`},
+		{TimeOfDay(24 * time.Hour), ""},
+		{TimeOfDay(-time.Second), ""},
+		{TimeOfDay(time.Millisecond), ""},
+		{DateTime{time.Date(2023, 1, 15, 12, 30, 0, 0, time.UTC)}, `"2023-01-15T12:30:00"`},
+		{DateTime{time.Date(2023, 1, 15, 12, 30, 0, 1, time.UTC)}, ""},
+		{DateTime{time.Date(-1, 1, 15, 12, 30, 0, 0, time.UTC)}, ""},
+		{Duration{1, 2, 3, 4*time.Hour + 5*time.Minute + 6500*time.Millisecond}, `"P1Y2M3DT4H5M6.5S"`},
+		{Duration{}, `"PT0S"`},
+		{Duration{Days: 14}, `"P14D"`},
+		{Duration{Time: 36 * time.Hour}, `"PT36H"`},
+		{Duration{Time: time.Minute + time.Nanosecond}, `"PT1M0.000000001S"`},
+		{Duration{Months: -1}, ""},
+		{Duration{Time: -time.Second}, ""},
+		{struct{ D Date }{Date{time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)}}, `{ d "2023-01-15" }`},
+	}
+	for _, tt := range tests {
+		b, err := Marshal(tt.v)
+		if tt.want == "" {
+			if _, ok := err.(*UnsupportedValueError); !ok {
+				t.Errorf("Marshal(%v) = %s, %v, want UnsupportedValueError", tt.v, b, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Marshal(%v): %v", tt.v, err)
+			continue
+		}
+		if string(b) != tt.want {
+			t.Errorf("Marshal(%v) = %s, want %s", tt.v, b, tt.want)
+		}
+	}
+}
+
+func TestDateRoundTrip(t *testing.T) {
+	tests := []interface{}{
+		Date{time.Date(1999, 12, 31, 0, 0, 0, 0, time.UTC)},
+		TimeOfDay(23*time.Hour + 59*time.Minute + 59*time.Second),
+		DateTime{time.Date(2038, 1, 19, 3, 14, 7, 0, time.Local)},
+		Duration{Years: 100},
+		Duration{Days: 1, Time: time.Hour + 123456789*time.Nanosecond},
+	}
+	for _, v := range tests {
+		b, err := Marshal(v)
+		if err != nil {
+			t.Errorf("Marshal(%v): %v", v, err)
+			continue
+		}
+		back := reflect.New(reflect.TypeOf(v))
+		if err := Unmarshal(b, back.Interface()); err != nil {
+			t.Errorf("Unmarshal(%s): %v", b, err)
+			continue
+		}
+		if got := back.Elem().Interface(); !reflect.DeepEqual(got, v) {
+			t.Errorf("Unmarshal(Marshal(%v)) = %v", v, got)
+		}
+	}
+}
+
+func TestTimeOfDayString(t *testing.T) {
+	tests := []struct {
+		t    TimeOfDay
+		want string
+	}{
+		{0, "00:00:00"},
+		{TimeOfDay(9*time.Hour + 5*time.Second), "09:00:05"},
+		{TimeOfDay(25 * time.Hour), "25h0m0s"},
+		{TimeOfDay(1500 * time.Millisecond), "1.5s"},
+	}
+	for _, tt := range tests {
+		if got := tt.t.String(); got != tt.want {
+			t.Errorf("TimeOfDay(%d).String() = %q, want %q", int64(tt.t), got, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalTime(t *testing.T) {
+	type record struct {
+		G time.Time
+		U time.Time `asn1:",utc"`
+	}
+	tests := []struct {
+		in   string
+		want time.Time // zero for an error
+	}{
+		{`{ g "20230115120000Z" }`, time.Date(2023, 1, 15, 12, 0, 0, 0, time.UTC)},
+		{`{ g "2023011512Z" }`, time.Date(2023, 1, 15, 12, 0, 0, 0, time.UTC)},
+		{`{ g "202301151230Z" }`, time.Date(2023, 1, 15, 12, 30, 0, 0, time.UTC)},
+		{`{ g "20230115120000.5Z" }`, time.Date(2023, 1, 15, 12, 0, 0, 5e8, time.UTC)},
+		{`{ g "20230115120000,123456789Z" }`, time.Date(2023, 1, 15, 12, 0, 0, 123456789, time.UTC)},
+		{`{ g "2023011512.5Z" }`, time.Date(2023, 1, 15, 12, 30, 0, 0, time.UTC)},
+		{`{ g "202301151230.25Z" }`, time.Date(2023, 1, 15, 12, 30, 15, 0, time.UTC)},
+		{`{ g "20230115120000+0530" }`, time.Date(2023, 1, 15, 12, 0, 0, 0, time.FixedZone("", 5*3600+30*60))},
+		{`{ g "20230115120000-03" }`, time.Date(2023, 1, 15, 12, 0, 0, 0, time.FixedZone("", -3*3600))},
+		{`{ g "20230115120000" }`, time.Date(2023, 1, 15, 12, 0, 0, 0, time.Local)},
+		{`{ u "230115120000Z" }`, time.Date(2023, 1, 15, 12, 0, 0, 0, time.UTC)},
+		{`{ u "4912311200Z" }`, time.Date(2049, 12, 31, 12, 0, 0, 0, time.UTC)},
+		{`{ u "500101000000-0100" }`, time.Date(1950, 1, 1, 0, 0, 0, 0, time.FixedZone("", -3600))},
+		{`{ g "20230230120000Z" }`, time.Time{}},
+		{`{ g "20230115120000.Z" }`, time.Time{}},
+		{`{ g "20230115120000+2400" }`, time.Time{}},
+		{`{ g "20230115120000+05:30" }`, time.Time{}},
+		{`{ g "2023-01-15T12:00:00Z" }`, time.Time{}},
+		{`{ u "230115120000" }`, time.Time{}},
+		{`{ u "230115120000.5Z" }`, time.Time{}},
+		{`{ u "230115120000+01" }`, time.Time{}},
+	}
+	for _, tt := range tests {
+		var v record
+		err := Unmarshal([]byte(tt.in), &v)
+		got := v.G
+		if v.G.IsZero() {
+			got = v.U
+		}
+		if tt.want.IsZero() {
+			if err == nil {
+				t.Errorf("Unmarshal(%s) = %v, want error", tt.in, got)
+			}
+			continue
+		}
+		_, wantOffset := tt.want.Zone()
+		if _, offset := got.Zone(); err != nil || !got.Equal(tt.want) || offset != wantOffset {
+			t.Errorf("Unmarshal(%s) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestTimeRoundTrip(t *testing.T) {
+	type record struct {
+		G time.Time
+		U time.Time  `asn1:",utc"`
+		P *time.Time `asn1:",omitempty"`
+	}
+	day := func(ns int, loc *time.Location) time.Time { return time.Date(2023, 1, 15, 12, 34, 56, ns, loc) }
+	zones := []*time.Location{
+		time.UTC,
+		time.Local,
+		time.FixedZone("", 0),
+		time.FixedZone("IST", 5*3600+30*60),
+		time.FixedZone("NST", -(3*3600 + 30*60)),
+		time.FixedZone("", 14*3600),
+		time.FixedZone("", -12*3600),
+	}
+	for _, loc := range zones {
+		for _, ns := range []int{0, 1, 5e8, 123456789, 1e5} {
+			g := day(ns, loc)
+			in := record{G: g, U: day(0, loc), P: &g}
+			b, err := Marshal(in)
+			if err != nil {
+				t.Errorf("Marshal(%v): %v", g, err)
+				continue
+			}
+			var out record
+			if err := Unmarshal(b, &out); err != nil {
+				t.Errorf("Unmarshal(%s): %v", b, err)
+				continue
+			}
+			for _, f := range [][2]time.Time{{out.G, in.G}, {out.U, in.U}, {*out.P, *in.P}} {
+				_, haveOffset := f[0].Zone()
+				_, wantOffset := f[1].Zone()
+				if !f[0].Equal(f[1]) || haveOffset != wantOffset {
+					t.Errorf("Unmarshal(Marshal(%v)) via %s = %v", f[1], b, f[0])
+				}
+			}
+		}
+	}
+}
+
+func TestMarshalTimeError(t *testing.T) {
+	tests := []interface{}{
+		struct {
+			U time.Time `asn1:",utc"`
+		}{time.Date(2023, 1, 15, 12, 0, 0, 1, time.UTC)},
+		struct {
+			U time.Time `asn1:",utc"`
+		}{time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC)},
+		struct {
+			U time.Time `asn1:",utc"`
+		}{time.Date(1949, 12, 31, 23, 59, 59, 0, time.UTC)},
+		struct{ G time.Time }{time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// An offset with seconds, as of local mean time, has no UTC
+		// offset of hours and minutes to write.
+		struct{ G time.Time }{time.Date(2023, 1, 15, 12, 0, 0, 0, time.FixedZone("LMT", 3600+30))},
+		struct{ G time.Time }{time.Date(2023, 1, 15, 12, 0, 0, 0, time.FixedZone("", 24*3600))},
+	}
+	for _, v := range tests {
+		if b, err := Marshal(v); err == nil {
+			t.Errorf("Marshal(%v) = %s, want error", v, b)
+		} else if _, ok := err.(*UnsupportedValueError); !ok {
+			t.Errorf("Marshal(%v): error %v, want UnsupportedValueError", v, err)
+		}
+	}
+}
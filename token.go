@@ -0,0 +1,364 @@
+package asn1go
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// topTokens appends the tokens of the top-level value in d.data to dst.
+// Errors in the values of literals are saved in d.savedError.
+func (d *decodeState) topTokens(dst []Token) []Token {
+	d.scan.reset()
+	d.scanWhile(scanSkipSpace)
+	if d.opcode != scanBeginValueReference {
+		return d.valueTokens(dst)
+	}
+	name := d.name()
+	switch d.opcode {
+	case scanBeginTypeReference:
+		dst = append(dst, ValueReference(name))
+		dst = append(dst, TypeName(d.typeReference()), AssignmentOp{})
+		d.scanWhile(scanSkipSpace)
+		return d.valueTokens(dst)
+	case scanChoiceTag:
+		d.scanWhile(scanSkipSpace)
+		return d.valueTokens(append(dst, ChoiceTag(name)))
+	case scanBeginObject, scanBeginLiteral:
+		// The identifier was CONTAINING.
+		return d.valueTokens(append(dst, ContainingOp{}))
+	}
+	// A lone identifier, such as NULL.
+	return append(dst, d.literalToken(name))
+}
+
+// valueTokens appends the tokens of the value that began with the last
+// opcode to dst.
+func (d *decodeState) valueTokens(dst []Token) []Token {
+	switch d.opcode {
+	default:
+		panic(phasePanicMsg)
+	case scanBeginObject:
+		dst = d.objectTokens(dst)
+		d.scanNext()
+	case scanBeginLiteral:
+		item, _, choice := d.literal()
+		if choice {
+			return d.valueTokens(append(dst, ChoiceTag(item)))
+		}
+		if string(item) == containingKeyword {
+			return d.valueTokens(append(dst, ContainingOp{}))
+		}
+		dst = append(dst, d.literalToken(item))
+	}
+	return dst
+}
+
+// objectTokens is like objectInterface but appends the tokens of the
+// brace-delimited value to dst. An OBJECT IDENTIFIER value becomes a
+// single ObjectIdentifier token.
+func (d *decodeState) objectTokens(dst []Token) []Token {
+	begin := len(dst)
+	dst = append(dst, ObjectStart{})
+	oid := false
+	d.scanWhile(scanSkipSpace)
+	for d.opcode != scanEndObject {
+		kind, name, _ := d.elementHead()
+		switch kind {
+		case elementValue:
+			dst = d.valueTokens(dst)
+		case elementName:
+			dst = append(dst, d.literalToken(name))
+		case elementComponent:
+			dst = d.valueTokens(append(dst, Identifier(name)))
+		case elementChoice:
+			dst = d.valueTokens(append(dst, ChoiceTag(name)))
+		case elementContaining:
+			dst = d.valueTokens(append(dst, ContainingOp{}))
+		}
+		if d.nextElement() {
+			oid = true
+		}
+	}
+	if oid {
+		return append(dst[:begin], d.objectIdentifierToken(dst[begin+1:]))
+	}
+	return append(dst, ObjectEnd{})
+}
+
+// objectIdentifierToken converts the Number tokens of the components of an
+// OBJECT IDENTIFIER value to an ObjectIdentifier.
+func (d *decodeState) objectIdentifierToken(components []Token) Token {
+	oid := make(ObjectIdentifier, len(components))
+	for i, c := range components {
+		n, ok := c.(Number)
+		var err error
+		if ok {
+			oid[i], err = strconv.Atoi(string(n))
+		}
+		if !ok || err != nil {
+			d.saveError(&UnmarshalTypeError{Value: "object identifier component " + tokenString(c), Type: reflect.TypeOf(oid), Offset: int64(d.readIndex())})
+			return nil
+		}
+	}
+	return oid
+}
+
+// literalToken returns the token for the literal item.
+func (d *decodeState) literalToken(item []byte) Token {
+	switch c := item[0]; {
+	case c == '\'': // hstring or bstring
+		digits, kind := stringDigits(item)
+		if kind == 'B' {
+			return parseBitString(digits)
+		}
+		digits, err := d.hexDigits(digits, false)
+		var b []byte
+		if err == nil {
+			b, err = decodeHex(digits)
+		}
+		if err != nil {
+			d.saveError(err)
+			return nil
+		}
+		return HexString(b)
+
+	case c == '"': // cstring
+		return unquoteCString(item)
+
+	case c == '-' || isDigit(c) && !d.isName(item): // number
+		return Number(item)
+	}
+
+	// keyword or identifier
+	s := string(item)
+	switch s {
+	case "NULL":
+		return Null{}
+	case "TRUE":
+		return true
+	case "FALSE":
+		return false
+	}
+	if f, ok := specialReal(s); ok {
+		return f
+	}
+	return NamedValue(s)
+}
+
+// literalKind returns the kind of the Token that literalToken returns
+// for item, without decoding it.
+func (d *decodeState) literalKind(item []byte) TokenKind {
+	switch c := item[0]; {
+	case c == '\'': // hstring or bstring
+		if _, kind := stringDigits(item); kind == 'B' {
+			return TokenBitString
+		}
+		return TokenHexString
+	case c == '"': // cstring
+		return TokenString
+	case c == '-' || isDigit(c) && !d.isName(item): // number
+		return TokenNumber
+	}
+	switch s := string(item); s {
+	case "NULL":
+		return TokenNull
+	case "TRUE", "FALSE":
+		return TokenBool
+	default:
+		if _, ok := specialReal(s); ok {
+			return TokenReal
+		}
+	}
+	return TokenNamedValue
+}
+
+// tokenString describes tok for error messages.
+func tokenString(tok Token) string {
+	switch t := tok.(type) {
+	case nil:
+		return "nil"
+	case Number:
+		return string(t)
+	case NamedValue:
+		return string(t)
+	}
+	return reflect.TypeOf(tok).String()
+}
+
+// A TokenKind is the kind of a Token, by its type.
+type TokenKind int
+
+const (
+	TokenInvalid          TokenKind = iota
+	TokenObjectStart                // ObjectStart
+	TokenObjectEnd                  // ObjectEnd
+	TokenIdentifier                 // Identifier
+	TokenTypeName                   // TypeName
+	TokenAssignmentOp               // AssignmentOp
+	TokenChoiceTag                  // ChoiceTag
+	TokenContainingOp               // ContainingOp
+	TokenHexString                  // HexString
+	TokenBitString                  // BitString
+	TokenNumber                     // Number
+	TokenNull                       // Null
+	TokenBool                       // bool
+	TokenReal                       // float64
+	TokenString                     // string
+	TokenNamedValue                 // NamedValue
+	TokenObjectIdentifier           // ObjectIdentifier
+	TokenValueReference             // ValueReference
+)
+
+var tokenKindNames = [...]string{
+	TokenInvalid:          "invalid",
+	TokenObjectStart:      "ObjectStart",
+	TokenObjectEnd:        "ObjectEnd",
+	TokenIdentifier:       "Identifier",
+	TokenTypeName:         "TypeName",
+	TokenAssignmentOp:     "AssignmentOp",
+	TokenChoiceTag:        "ChoiceTag",
+	TokenContainingOp:     "ContainingOp",
+	TokenHexString:        "HexString",
+	TokenBitString:        "BitString",
+	TokenNumber:           "Number",
+	TokenNull:             "Null",
+	TokenBool:             "Bool",
+	TokenReal:             "Real",
+	TokenString:           "String",
+	TokenNamedValue:       "NamedValue",
+	TokenObjectIdentifier: "ObjectIdentifier",
+	TokenValueReference:   "ValueReference",
+}
+
+// String returns the name of the kind, such as "Identifier".
+func (k TokenKind) String() string {
+	if k >= 0 && int(k) < len(tokenKindNames) {
+		return tokenKindNames[k]
+	}
+	return "TokenKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// tokenKind returns the kind of tok.
+func tokenKind(tok Token) TokenKind {
+	switch tok.(type) {
+	case ObjectStart:
+		return TokenObjectStart
+	case ObjectEnd:
+		return TokenObjectEnd
+	case Identifier:
+		return TokenIdentifier
+	case TypeName:
+		return TokenTypeName
+	case AssignmentOp:
+		return TokenAssignmentOp
+	case ChoiceTag:
+		return TokenChoiceTag
+	case ContainingOp:
+		return TokenContainingOp
+	case HexString:
+		return TokenHexString
+	case BitString:
+		return TokenBitString
+	case Number:
+		return TokenNumber
+	case Null:
+		return TokenNull
+	case bool:
+		return TokenBool
+	case float64:
+		return TokenReal
+	case string:
+		return TokenString
+	case NamedValue:
+		return TokenNamedValue
+	case ObjectIdentifier:
+		return TokenObjectIdentifier
+	case ValueReference:
+		return TokenValueReference
+	}
+	return TokenInvalid
+}
+
+// A TokenInfo is a Token with its kind and its position in the input, as
+// Decoder.TokenInfo returns it, for trace logs and source maps.
+type TokenInfo struct {
+	Kind   TokenKind
+	Value  Token
+	Offset int64 // of the first byte of the token in the input
+	Line   int   // line of the offset, starting at 1
+	Column int   // byte of the offset in its line, starting at 1
+}
+
+// String formats the token with its position, such as
+// `3:5 Identifier header` or `4:21 HexString '4121'H`.
+func (t TokenInfo) String() string {
+	return strconv.Itoa(t.Line) + ":" + strconv.Itoa(t.Column) + " " + t.Kind.String() + " " + tokenText(t.Value)
+}
+
+// tokenText returns the value notation of tok.
+func tokenText(tok Token) string {
+	switch t := tok.(type) {
+	case ObjectStart:
+		return "{"
+	case ObjectEnd:
+		return "}"
+	case AssignmentOp:
+		return "::="
+	case ContainingOp:
+		return containingKeyword
+	case Null:
+		return "NULL"
+	case Identifier:
+		return string(t)
+	case ValueReference:
+		return string(t)
+	case TypeName:
+		return string(t)
+	case ChoiceTag:
+		return string(t)
+	case NamedValue:
+		return string(t)
+	case HexString:
+		tok = []byte(t)
+	}
+	b, err := Marshal(tok)
+	if err != nil {
+		return tokenString(tok)
+	}
+	return string(b)
+}
+
+// tokenOffsets returns the offsets in data, a top-level value, at which
+// the tokens that topTokens returned for it begin. The scanner reports the
+// beginning of each token but an ObjectIdentifier, which begins where its
+// brace does and spans the reports of its components and closing brace.
+// The identifiers of data are those of the dialect dl.
+func tokenOffsets(data []byte, tokens []Token, dl Dialect) []int {
+	scan := newScanner()
+	defer freeScanner(scan)
+	scan.dialect = dl
+	var starts []int
+	for i, c := range data {
+		switch scan.step(scan, c) {
+		case scanBeginObject, scanEndObject, scanBeginValueReference, scanBeginIdentifier, scanBeginTypeReference:
+			starts = append(starts, i)
+		case scanBeginLiteral:
+			starts = append(starts, valueStart(scan, c, i))
+		case scanAssignment:
+			starts = append(starts, i-len("::"))
+		}
+	}
+	offs := make([]int, len(tokens))
+	j := 0
+	for k, tok := range tokens {
+		if j < len(starts) {
+			offs[k] = starts[j]
+		}
+		if oid, ok := tok.(ObjectIdentifier); ok {
+			j += len(oid) + 2
+		} else {
+			j++
+		}
+	}
+	return offs
+}
@@ -0,0 +1,139 @@
+package asn1go
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecoderToken(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []Token
+	}{
+		{"5", []Token{Number("5")}},
+		{
+			"value1 ProfileElement ::= mf : { }",
+			[]Token{ValueReference("value1"), TypeName("ProfileElement"), AssignmentOp{}, ChoiceTag("mf"), ObjectStart{}, ObjectEnd{}},
+		},
+		{
+			`{ a 1, b '0A'H, c '01'B, d "x", e NULL, f TRUE, g on, h { 2 23 143 } }`,
+			[]Token{
+				ObjectStart{},
+				Identifier("a"), Number("1"),
+				Identifier("b"), HexString{0x0A},
+				Identifier("c"), BitString{Bytes: []byte{0x40}, BitLength: 2},
+				Identifier("d"), "x",
+				Identifier("e"), Null{},
+				Identifier("f"), true,
+				Identifier("g"), NamedValue("on"),
+				Identifier("h"), ObjectIdentifier{2, 23, 143},
+				ObjectEnd{},
+			},
+		},
+		{"{ 1, -2.5 } v T ::= CONTAINING 3", []Token{
+			ObjectStart{}, Number("1"), Number("-2.5"), ObjectEnd{},
+			ValueReference("v"), TypeName("T"), AssignmentOp{}, ContainingOp{}, Number("3"),
+		}},
+	}
+	for _, tt := range tests {
+		dec := NewDecoder(strings.NewReader(tt.in))
+		var got []Token
+		for {
+			tok, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Token(%q): %v", tt.in, err)
+			}
+			got = append(got, tok)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Token(%q):\nhave %#v\nwant %#v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDecoderTokenError(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("1 { a 1,, }"))
+	if tok, err := dec.Token(); err != nil || tok != Number("1") {
+		t.Fatalf("Token = %#v, %v", tok, err)
+	}
+	// The error comes before any token of the value it is in.
+	tok, err := dec.Token()
+	if _, ok := err.(*SyntaxError); !ok {
+		t.Errorf("Token = %#v, %v, want SyntaxError", tok, err)
+	}
+}
+
+func TestDecoderTokenThenDecode(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("{ a 1 } 2"))
+	if tok, err := dec.Token(); err != nil || tok != (ObjectStart{}) {
+		t.Fatalf("Token = %#v, %v", tok, err)
+	}
+	if !dec.More() {
+		t.Fatal("More = false with tokens left")
+	}
+	// Decode discards the rest of { a 1 }.
+	var n int
+	if err := dec.Decode(&n); err != nil || n != 2 {
+		t.Errorf("Decode = %d, %v, want 2", n, err)
+	}
+}
+
+func TestDecoderTokenInfo(t *testing.T) {
+	in := "v T ::= {\n  a 1, -- c\n  b 'AB'H\n}\n  7"
+	want := []TokenInfo{
+		{TokenValueReference, ValueReference("v"), 0, 1, 1},
+		{TokenTypeName, TypeName("T"), 2, 1, 3},
+		{TokenAssignmentOp, AssignmentOp{}, 4, 1, 5},
+		{TokenObjectStart, ObjectStart{}, 8, 1, 9},
+		{TokenIdentifier, Identifier("a"), 12, 2, 3},
+		{TokenNumber, Number("1"), 14, 2, 5},
+		{TokenIdentifier, Identifier("b"), 24, 3, 3},
+		{TokenHexString, HexString{0xAB}, 26, 3, 5},
+		{TokenObjectEnd, ObjectEnd{}, 32, 4, 1},
+		{TokenNumber, Number("7"), 36, 5, 3},
+	}
+	readers := []struct {
+		name string
+		r    func() io.Reader
+	}{
+		{"whole", func() io.Reader { return strings.NewReader(in) }},
+		{"one byte", func() io.Reader { return iotest.OneByteReader(strings.NewReader(in)) }},
+	}
+	for _, rd := range readers {
+		dec := NewDecoder(rd.r())
+		var got []TokenInfo
+		for {
+			tok, err := dec.TokenInfo()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: TokenInfo: %v", rd.name, err)
+			}
+			got = append(got, tok)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: TokenInfo:\nhave %v\nwant %v", rd.name, got, want)
+		}
+	}
+
+	tests := []struct {
+		t    TokenInfo
+		want string
+	}{
+		{want[4], "2:3 Identifier a"},
+		{want[7], "3:5 HexString 'AB'H"},
+		{want[8], "4:1 ObjectEnd }"},
+	}
+	for _, tt := range tests {
+		if got := tt.t.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,750 @@
+package asn1go
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TextToDER writes the DER encoding of the value notation in src, read as
+// values of the ASN.1 type t, to w. src holds a single value or a
+// sequence of value assignments, such as a profile package of
+// ProfileElement values; the encoding of each top-level value is written
+// to w as soon as it is complete. The type of a value assignment must be
+// t.Name, if t has a name.
+//
+// The value notation is encoded as it is read, without decoding it into
+// Go values first. It is read as EncodeDER reads the values Unmarshal
+// decodes it to, except that each value must be written in the notation of
+// its type: NULL for NULL, an hstring for an OCTET STRING, a cstring for
+// a character string, and the components of a SEQUENCE in the order of
+// its type, while those of a SET may come in any order. Values of an open
+// type are hstrings holding their encoding. Components that an extensible
+// SEQUENCE or SET type does not know are left out, as they cannot be
+// encoded without their types.
+func TextToDER(w io.Writer, t *Type, src []byte) error {
+	d := newDecodeState()
+	defer freeDecodeState(d)
+	n, err := checkValid(src, &d.scan)
+	if err != nil {
+		return err
+	}
+	d.init(src)
+	d.scan.reset()
+	d.scanWhile(scanSkipSpace)
+
+	e := textEncoder{d: d}
+	for i := 0; i < n; i++ {
+		b, err := e.topValue(t)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+		d.nextTopValue()
+	}
+	return d.savedError
+}
+
+// DERToText writes the value notation of the DER encoded values in src,
+// read as values of the ASN.1 type t, to w. src holds one encoding or
+// several concatenated ones, such as a profile package; each value is
+// written to w as soon as it is decoded, on a line of its own. If t has a
+// name, the values are written as value assignments named value1, value2
+// and so on, for TextToDER to read back.
+//
+// Values are written as Marshal writes the values DecodeDER decodes them
+// to, with components in the order of t. Unknown extension additions are
+// left out, except that of a CHOICE value, which is an error.
+func DERToText(w io.Writer, t *Type, src []byte) error {
+	return DERToTextIndent(w, t, src, "", "")
+}
+
+// DERToTextIndent is like DERToText but applies Indent to format the
+// output.
+func DERToTextIndent(w io.Writer, t *Type, src []byte, prefix, indent string) error {
+	return derToText(w, t, src, prefix, indent, 0)
+}
+
+// DERToSample is like DERToText but formats the output in the layout of
+// MarshalSample, to regenerate a sample profile from its encoding.
+func DERToSample(w io.Writer, t *Type, src []byte) error {
+	return derToText(w, t, src, "", "  ", sampleHexLine)
+}
+
+// DERToTextSchema is like DERToTextIndent with two spaces of indentation,
+// but reads DER encoded values whose types are not known, such as those
+// read from a card: it tries the types of the module schema in the order
+// of their assignments, and writes each value as an assignment of the
+// first type it is the encoding of, value1 Type ::= ..., with the
+// identifiers of its components. A value that no type matches, or every
+// value if schema is nil, is written as a dump of its tags, lengths and
+// contents in comments instead, one encoding per line and the encodings
+// that a constructed one holds indented below it, which TextToDER skips.
+//
+// Malformed DER is reported as a DERSyntaxError.
+func DERToTextSchema(w io.Writer, schema *Module, src []byte) error {
+	e := newEncodeState()
+	defer encodeStatePool.Put(e)
+	e.indent = "  "
+
+	d := derDecoder{data: src, opts: derDecodeOptions}
+	for off, i := 0, 1; off < len(src); i++ {
+		n, ok := 0, false
+		if schema != nil {
+			for _, t := range schema.Types {
+				if t.Name == "" || t.Kind == KindInvalid || t.Kind == KindAny {
+					continue
+				}
+				e.Reset()
+				var err error
+				if n, err = e.derTopValue(&d, t, off, i); err == nil {
+					ok = true
+					break
+				}
+			}
+		}
+		if !ok {
+			e.Reset()
+			x, err := d.parseTop(off)
+			if err != nil {
+				return err
+			}
+			n = len(x.raw)
+			e.WriteString("-- value" + strconv.Itoa(i) + " matches no type\n")
+			e.derDump(x.raw, 0)
+		}
+		off += n
+		if _, err := w.Write(e.Bytes()); err != nil {
+			return err
+		}
+		e.Reset()
+	}
+	return nil
+}
+
+// parseTop parses the top-level encoding at offset off in d.data.
+func (d *derDecoder) parseTop(off int) (x tlv, err error) {
+	err = derError(func() { x, _ = d.parse(d.data[off:], off) })
+	return x, err
+}
+
+// derDump writes the encodings in b as comments, one per line, indented by
+// depth: the tag, the universal type it is the tag of, if any, the length
+// and the contents in hex of a primitive encoding, and the encodings a
+// constructed one holds on the lines below it. Bytes that are not an
+// encoding are written in hex.
+func (e *encodeState) derDump(b []byte, depth int) {
+	for len(b) > 0 {
+		e.WriteString("-- " + strings.Repeat("  ", depth))
+		tag, constructed, l, n, err := parseHeader(b, false)
+		if err != nil || l < 0 || l > len(b)-n {
+			fmt.Fprintf(e, "not an encoding: %X\n", b)
+			return
+		}
+		e.WriteString(tag.String())
+		if name := universalName(tag); name != "" {
+			e.WriteString(" " + name)
+		}
+		fmt.Fprintf(e, ", length %d", l)
+		switch {
+		case constructed:
+			e.WriteByte('\n')
+			e.derDump(b[n:n+l], depth+1)
+		case l > 0:
+			fmt.Fprintf(e, ": %X\n", b[n:n+l])
+		default:
+			e.WriteByte('\n')
+		}
+		b = b[n+l:]
+	}
+}
+
+// universalName returns the name of the built-in type whose tag is tag,
+// such as "OCTET STRING", or "" if tag is not such a universal tag.
+func universalName(tag Tag) string {
+	if k, ok := universalKind(tag); ok {
+		return k.String()
+	}
+	return ""
+}
+
+// derToText implements DERToTextIndent and DERToSample.
+func derToText(w io.Writer, t *Type, src []byte, prefix, indent string, hexLine int) error {
+	e := newEncodeState()
+	defer encodeStatePool.Put(e)
+	e.prefix, e.indent, e.hexLine = prefix, indent, hexLine
+
+	d := derDecoder{data: src, opts: derDecodeOptions}
+	for off, i := 0, 1; off < len(src); i++ {
+		n, err := e.derTopValue(&d, t, off, i)
+		if err != nil {
+			return err
+		}
+		off += n
+		if _, err := w.Write(e.Bytes()); err != nil {
+			return err
+		}
+		e.Reset()
+	}
+	return nil
+}
+
+// derTopValue writes the value notation of the i'th DER encoded value,
+// which starts at offset off in d.data, and returns the length of its
+// encoding.
+func (e *encodeState) derTopValue(d *derDecoder, t *Type, off, i int) (n int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if je, ok := r.(asn1Error); ok {
+				err = je.error
+			} else {
+				panic(r)
+			}
+		}
+	}()
+	x, n := d.parse(d.data[off:], off)
+	if t.Name != "" {
+		e.WriteString("value" + strconv.Itoa(i) + " " + t.Name + " ::= ")
+	}
+	e.derValue(d, t, x)
+	e.WriteByte('\n')
+	return n, nil
+}
+
+// derValue writes the value notation of the encoding x of a value of type
+// t.
+func (e *encodeState) derValue(d *derDecoder, t *Type, x tlv) {
+	x, want := d.untag(t, x)
+	switch t.Kind {
+	case KindChoice:
+		c := d.alternative(t, x)
+		if c == nil {
+			d.syntaxError(x.off, "unknown extension addition of %v has no value notation", t)
+		}
+		e.WriteString(c.Name)
+		e.WriteString(" : ")
+		e.derValue(d, c.Type, x)
+
+	case KindAny:
+		e.writeHex(x.raw)
+
+	case KindSequence, KindSet:
+		d.expect(x, want, true)
+		elems, index, _ := d.componentsOf(t, x)
+		// The components of a SET come in the order of their tags, and
+		// are written in the order of t.
+		order := make([]int, len(elems))
+		for i := range order {
+			order[i] = i
+		}
+		if t.Kind == KindSet {
+			sort.SliceStable(order, func(i, j int) bool { return index[order[i]] < index[order[j]] })
+		}
+		// Unknown extension additions have no value notation, and are
+		// left out.
+		e.beginBrace()
+		n := 0
+		for _, i := range order {
+			if index[i] < 0 {
+				continue
+			}
+			e.elementSeparator(n)
+			n++
+			e.WriteString(t.Components[index[i]].Name)
+			e.WriteByte(' ')
+			e.derValue(d, t.Components[index[i]].Type, elems[i])
+		}
+		e.endBrace(n)
+
+	case KindSequenceOf, KindSetOf:
+		d.expect(x, want, true)
+		elems := d.elements(x)
+		e.beginBrace()
+		for i, el := range elems {
+			e.elementSeparator(i)
+			e.derValue(d, t.Elem, el)
+		}
+		e.endBrace(len(elems))
+
+	default:
+		d.expect(x, want, false)
+		switch val := d.primitive(t, x).(type) {
+		case bool:
+			if val {
+				e.WriteString("TRUE")
+			} else {
+				e.WriteString("FALSE")
+			}
+		case int64:
+			if name, ok := t.nameOf(val); ok {
+				e.WriteString(name)
+			} else {
+				e.Write(strconv.AppendInt(e.scratch[:0], val, 10))
+			}
+		case uint64:
+			e.Write(strconv.AppendUint(e.scratch[:0], val, 10))
+		case *big.Int:
+			e.WriteString(val.String())
+		case float64:
+			e.real(val, 64)
+		case BitString:
+			// A value of a type with named bits is written as a named
+			// bit list, if all its bits set have names.
+			named := e.named
+			e.named = t.Named
+			e.bitString(val)
+			e.named = named
+		case []byte:
+			e.writeHex(val)
+		case ObjectIdentifier, RelativeOID:
+			e.objectIdentifier(reflect.ValueOf(val))
+		case string:
+			e.cstring(reflect.ValueOf(val))
+		case nil:
+			e.WriteString("NULL")
+		}
+	}
+}
+
+// A textEncoder encodes value notation in DER as it reads it.
+type textEncoder struct {
+	d    *decodeState
+	path []string // identifiers of the components being encoded
+
+	// module, if set, holds the types of value assignments, which are
+	// looked up by their type reference.
+	module *Module
+}
+
+// error aborts the encoding with a ValueError for the value of type t.
+func (e *textEncoder) error(t *Type, format string, args ...interface{}) {
+	panic(asn1Error{&ValueError{strings.Join(e.path, "."), t.String(), fmt.Sprintf(format, args...)}})
+}
+
+// topValue returns the encoding of the top-level value at the current
+// opcode, consuming the value assignment header in front of it if there
+// is one.
+func (e *textEncoder) topValue(t *Type) (b []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if je, ok := r.(asn1Error); ok {
+				err = je.error
+			} else {
+				panic(r)
+			}
+		}
+	}()
+	e.path = e.path[:0]
+	d := e.d
+	if d.opcode != scanBeginValueReference {
+		return e.value(e.plainType(t)), nil
+	}
+	name := d.name()
+	switch d.opcode {
+	case scanBeginTypeReference:
+		typ := d.typeReference()
+		d.scanWhile(scanSkipSpace)
+		e.path = append(e.path, string(name))
+		if e.module != nil {
+			if t = e.module.Type(typ); t == nil {
+				e.error(&Type{Name: typ}, "undefined type in module %s", e.module.Name)
+			}
+		}
+		if t.Name != "" && typ != t.Name {
+			e.error(t, "value of type %s", typ)
+		}
+		return e.value(t), nil
+	case scanChoiceTag:
+		d.scanWhile(scanSkipSpace)
+		return e.choice(e.plainType(t), string(name)), nil
+	case scanBeginObject, scanBeginLiteral:
+		// The identifier was CONTAINING.
+		return e.containing(e.plainType(t)), nil
+	}
+	// A lone identifier, such as NULL.
+	return e.literal(e.plainType(t), name), nil
+}
+
+// plainType returns the type of a top-level value without a value
+// assignment header, which is t unless the types are looked up in a
+// module.
+func (e *textEncoder) plainType(t *Type) *Type {
+	if e.module != nil {
+		panic(asn1Error{&ValueError{Msg: "value without value assignment"}})
+	}
+	return t
+}
+
+// value returns the encoding of the value of type t that begins with the
+// current opcode, and reads the following byte ahead.
+func (e *textEncoder) value(t *Type) []byte {
+	d := e.d
+	if d.opcode == scanBeginLiteral {
+		item, _, choice := d.literal()
+		if choice {
+			return e.choice(t, string(item))
+		}
+		if string(item) == containingKeyword {
+			return e.containing(t)
+		}
+		return e.literal(t, item)
+	}
+
+	var content []byte
+	switch t.Kind {
+	case KindSequence, KindSet:
+		content = e.components(t)
+	case KindSequenceOf, KindSetOf:
+		content = e.elements(t)
+	case KindObjectIdentifier, KindRelativeOID:
+		content = e.objectIdentifier(t)
+	case KindReal:
+		content = e.real(t)
+	case KindBitString:
+		content = e.namedBits(t)
+	default:
+		e.error(t, "unexpected brace-delimited value")
+	}
+	d.scanNext()
+	constructed := t.Kind != KindObjectIdentifier && t.Kind != KindRelativeOID && t.Kind != KindReal && t.Kind != KindBitString
+	return applyTags(t, appendTLV(nil, Tag{ClassUniversal, t.Kind.universalTag()}, constructed, content), false)
+}
+
+// choice returns the encoding of the value of the CHOICE type t that
+// selects the alternative name, whose value begins with the current
+// opcode.
+func (e *textEncoder) choice(t *Type, name string) []byte {
+	if t.Kind != KindChoice {
+		e.error(t, "unexpected CHOICE value %s : ...", name)
+	}
+	c := t.Component(name)
+	if c == nil {
+		e.error(t, "unknown alternative %s", name)
+	}
+	e.path = append(e.path, name)
+	b := e.value(c.Type)
+	e.path = e.path[:len(e.path)-1]
+	return applyTags(t, b, false)
+}
+
+// containing returns the encoding of the containing value of the OCTET
+// STRING or BIT STRING type t, whose value contained begins with the
+// current opcode: a string of the DER encoding of that value as a value of
+// t.Contains.
+func (e *textEncoder) containing(t *Type) []byte {
+	if t.Contains == nil {
+		e.error(t, "unexpected containing value for a type without contents constraint")
+	}
+	if err := checkEncodedBy(t); err != nil {
+		e.error(t, "%v", err)
+	}
+	content := e.value(t.Contains)
+	if t.Kind == KindBitString {
+		content = appendBitString(nil, BitString{Bytes: content, BitLength: 8 * len(content)})
+	}
+	return applyTags(t, appendTLV(nil, Tag{ClassUniversal, t.Kind.universalTag()}, false, content), false)
+}
+
+// literal returns the encoding of the literal item as a value of type t.
+func (e *textEncoder) literal(t *Type, item []byte) []byte {
+	var content []byte
+	switch c := item[0]; {
+	case c == '\'': // hstring or bstring
+		digits, kind := stringDigits(item)
+		var bs BitString
+		if kind == 'B' {
+			bs = parseBitString(digits)
+		} else {
+			b, err := decodeHex(digits)
+			if err != nil {
+				e.error(t, "%v", err)
+			}
+			bs = BitString{Bytes: b, BitLength: 8 * len(b)}
+		}
+		switch {
+		case t.Kind == KindBitString:
+			if t.Named != nil && t.Size == nil {
+				bs = trimBits(bs)
+			}
+			content = appendBitString(nil, bs)
+		case t.Kind == KindOctetString && bs.BitLength%8 == 0:
+			content = bs.Bytes
+		case t.Kind == KindAny && kind == 'H' && validTLV(bs.Bytes):
+			return applyTags(t, bs.Bytes, false)
+		case t.Kind == KindAny:
+			e.error(t, "open type value must be an hstring of a DER encoding")
+		default:
+			e.mismatchLiteral(t, item)
+		}
+
+	case c == '"': // cstring
+		s := unquoteCString(item)
+		switch t.Kind {
+		case KindUTF8String, KindNumericString, KindPrintableString, KindIA5String, KindVisibleString,
+			KindUTCTime, KindGeneralizedTime, KindOIDIRI, KindRelativeOIDIRI,
+			KindTime, KindDate, KindTimeOfDay, KindDateTime, KindDuration:
+			if !validString(t.Kind, s) {
+				e.error(t, "invalid character in %q", s)
+			}
+			content = []byte(s)
+		default:
+			e.mismatchLiteral(t, item)
+		}
+
+	case c == '-' || isDigit(c): // number
+		switch t.Kind {
+		case KindInteger, KindEnumerated:
+			n, ok := new(big.Int).SetString(string(item), 10)
+			if !ok {
+				e.error(t, "invalid number %s", item)
+			}
+			content = appendBigInt(nil, n)
+		case KindReal:
+			f, err := strconv.ParseFloat(string(item), 64)
+			if err != nil {
+				e.error(t, "invalid number %s", item)
+			}
+			content = appendReal(nil, f)
+		default:
+			e.mismatchLiteral(t, item)
+		}
+
+	default: // keyword or identifier
+		s := string(item)
+		switch t.Kind {
+		case KindNull:
+			if s != "NULL" {
+				e.mismatchLiteral(t, item)
+			}
+		case KindBoolean:
+			switch s {
+			case "TRUE":
+				content = []byte{0xff}
+			case "FALSE":
+				content = []byte{0x00}
+			default:
+				e.mismatchLiteral(t, item)
+			}
+		case KindReal:
+			f, ok := specialReal(s)
+			if !ok {
+				e.mismatchLiteral(t, item)
+			}
+			content = appendReal(nil, f)
+		case KindInteger, KindEnumerated:
+			n, ok := t.NamedValue(s)
+			if !ok {
+				e.error(t, "unknown named value %s", s)
+			}
+			content = appendInt64(nil, n)
+		default:
+			e.mismatchLiteral(t, item)
+		}
+	}
+	return applyTags(t, appendTLV(nil, Tag{ClassUniversal, t.Kind.universalTag()}, false, content), false)
+}
+
+// mismatchLiteral aborts the encoding because the literal item is not a
+// value of type t.
+func (e *textEncoder) mismatchLiteral(t *Type, item []byte) {
+	e.error(t, "unexpected value %s", item)
+}
+
+// components returns the contents octets of the SEQUENCE or SET value of
+// type t whose opening brace has been read. The components of a SEQUENCE
+// must be in the order of t; those of a SET are matched by identifier in
+// any order and encoded in the canonical order of their tags.
+func (e *textEncoder) components(t *Type) []byte {
+	d := e.d
+	encs := make([][]byte, len(t.Components))
+	last := -1
+	d.scanWhile(scanSkipSpace)
+	for d.opcode != scanEndObject {
+		kind, name, _ := d.elementHead()
+		if kind != elementComponent {
+			e.error(t, "element without component identifier")
+		}
+		i := componentIndex(t, string(name))
+		if i < 0 && t.Extensible {
+			// An unknown extension addition cannot be encoded without
+			// its type, and is left out.
+			d.value(reflect.Value{})
+			d.nextElement()
+			continue
+		}
+		if i < 0 {
+			e.error(t, "unknown component %s", name)
+		}
+		switch {
+		case encs[i] != nil:
+			e.error(t, "duplicate component %s", name)
+		case t.Kind == KindSequence && i < last:
+			e.error(t, "component %s out of order", name)
+		}
+		last = i
+		e.path = append(e.path, string(name))
+		encs[i] = e.value(t.Components[i].Type)
+		e.path = e.path[:len(e.path)-1]
+		d.nextElement()
+	}
+
+	var elems [][]byte
+	for i, b := range encs {
+		c := &t.Components[i]
+		if b == nil {
+			if !c.Optional && c.Default == nil && !c.Extension {
+				e.error(t, "missing component %s", c.Name)
+			}
+			continue
+		}
+		if c.Default != nil {
+			if def, err := EncodeDER(c.Type, c.Default); err == nil && bytes.Equal(b, def) {
+				continue
+			}
+		}
+		elems = append(elems, b)
+	}
+	if t.Kind == KindSet {
+		sort.SliceStable(elems, func(i, j int) bool {
+			return tagLess(elems[i], elems[j])
+		})
+	}
+	return bytes.Join(elems, nil)
+}
+
+// componentIndex returns the index of the component name of t, or -1.
+func componentIndex(t *Type, name string) int {
+	for i := range t.Components {
+		if t.Components[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// elements returns the contents octets of the SEQUENCE OF or SET OF value
+// of type t whose opening brace has been read.
+func (e *textEncoder) elements(t *Type) []byte {
+	d := e.d
+	var elems [][]byte
+	d.scanWhile(scanSkipSpace)
+	for d.opcode != scanEndObject {
+		e.path = append(e.path, strconv.Itoa(len(elems)))
+		var b []byte
+		switch kind, name, _ := d.elementHead(); kind {
+		case elementValue:
+			b = e.value(t.Elem)
+		case elementName:
+			b = e.literal(t.Elem, name)
+		case elementChoice:
+			b = e.choice(t.Elem, string(name))
+		case elementContaining:
+			b = e.containing(t.Elem)
+		default:
+			e.error(t, "unexpected component %s", name)
+		}
+		e.path = e.path[:len(e.path)-1]
+		elems = append(elems, b)
+		d.nextElement()
+	}
+	if t.Kind == KindSetOf {
+		sort.Slice(elems, func(i, j int) bool { return bytes.Compare(elems[i], elems[j]) < 0 })
+	}
+	return bytes.Join(elems, nil)
+}
+
+// objectIdentifier returns the contents octets of the OBJECT IDENTIFIER
+// or RELATIVE-OID value whose opening brace has been read.
+func (e *textEncoder) objectIdentifier(t *Type) []byte {
+	d := e.d
+	var oid ObjectIdentifier
+	d.scanWhile(scanSkipSpace)
+	for d.opcode != scanEndObject {
+		if d.opcode != scanBeginLiteral {
+			e.error(t, "invalid object identifier component")
+		}
+		item, _, _ := d.literal()
+		n, err := strconv.Atoi(string(item))
+		if err != nil || n < 0 {
+			e.error(t, "invalid object identifier component %s", item)
+		}
+		oid = append(oid, n)
+		d.nextElement()
+	}
+	if t.Kind == KindRelativeOID {
+		return appendRelativeOID(nil, oid)
+	}
+	b, err := appendOID(nil, oid)
+	if err != nil {
+		e.error(t, "%v", err)
+	}
+	return b
+}
+
+// namedBits returns the contents octets of the named bit list of the BIT
+// STRING type t, such as { bitA, bitC }, whose opening brace has been
+// read.
+func (e *textEncoder) namedBits(t *Type) []byte {
+	d := e.d
+	var names []string
+	d.scanWhile(scanSkipSpace)
+	for d.opcode != scanEndObject {
+		kind, name, _ := d.elementHead()
+		if kind != elementName {
+			e.error(t, "invalid named bit list")
+		}
+		names = append(names, string(name))
+		d.nextElement()
+	}
+	bs, err := namedBits(t.Named, names)
+	if err != nil {
+		e.error(t, "%v", err)
+	}
+	return appendBitString(nil, bs)
+}
+
+// real returns the contents octets of the REAL value in sequence form,
+// { mantissa 314159, base 10, exponent -5 }, whose opening brace has been
+// read.
+func (e *textEncoder) real(t *Type) []byte {
+	d := e.d
+	var parts [3]*int64 // mantissa, base, exponent
+	d.scanWhile(scanSkipSpace)
+	for d.opcode != scanEndObject {
+		kind, name, _ := d.elementHead()
+		i := -1
+		switch string(name) {
+		case "mantissa":
+			i = 0
+		case "base":
+			i = 1
+		case "exponent":
+			i = 2
+		}
+		if kind != elementComponent || i < 0 || d.opcode != scanBeginLiteral {
+			e.error(t, "invalid REAL value")
+		}
+		item, _, _ := d.literal()
+		n, err := strconv.ParseInt(string(item), 10, 64)
+		if err != nil {
+			e.error(t, "invalid REAL %s %s", name, item)
+		}
+		parts[i] = &n
+		d.nextElement()
+	}
+	if parts[0] == nil || parts[1] == nil || parts[2] == nil {
+		e.error(t, "REAL value needs mantissa, base and exponent")
+	}
+	f, err := realFromParts(*parts[0], *parts[1], *parts[2])
+	if err != nil {
+		e.error(t, "%v", err)
+	}
+	return appendReal(nil, f)
+}
@@ -0,0 +1,193 @@
+package asn1go
+
+import (
+	"bytes"
+	"testing"
+)
+
+// transcodeRecord is a SEQUENCE type for the transcoding tests.
+var transcodeRecord = &Type{Name: "Rec", Kind: KindSequence, Components: []Component{
+	{Name: "a", Type: &Type{Kind: KindInteger}},
+	{Name: "b", Type: &Type{Kind: KindOctetString}, Optional: true},
+}}
+
+func TestTextToDER(t *testing.T) {
+	integer := &Type{Kind: KindInteger}
+	set := &Type{Kind: KindSet, Components: []Component{
+		{Name: "x", Type: &Type{Kind: KindInteger, Tags: []TypeTag{{Tag: Tag{ClassContextSpecific, 0}}}}},
+		{Name: "y", Type: &Type{Kind: KindBoolean, Tags: []TypeTag{{Tag: Tag{ClassContextSpecific, 1}}}}},
+	}}
+	tests := []struct {
+		t    *Type
+		in   string
+		want string
+	}{
+		{integer, "5", "02 01 05"},
+		{integer, "-129", "02 02 FF 7F"},
+		{&Type{Kind: KindNull}, "NULL", "05 00"},
+		{&Type{Kind: KindUTF8String}, `"hi"`, "0C 02 68 69"},
+		{&Type{Kind: KindObjectIdentifier}, "{ 1 2 840 113549 }", "06 06 2A 86 48 86 F7 0D"},
+		{transcodeRecord, "{ a 1 }", "30 03 02 01 01"},
+		{transcodeRecord, "{ a 2, b 'AB'H }", "30 06 02 01 02 04 01 AB"},
+		{set, "{ y TRUE, x 1 }", "31 06 80 01 01 81 01 FF"},
+		{
+			transcodeRecord,
+			"value1 Rec ::= { a 1 }\nvalue2 Rec ::= { a 2, b 'AB'H }",
+			"30 03 02 01 01 30 06 02 01 02 04 01 AB",
+		},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		if err := TextToDER(&b, tt.t, []byte(tt.in)); err != nil {
+			t.Errorf("TextToDER(%v, %q): %v", tt.t, tt.in, err)
+			continue
+		}
+		if want := fromHex(tt.want); !bytes.Equal(b.Bytes(), want) {
+			t.Errorf("TextToDER(%v, %q) = % X, want % X", tt.t, tt.in, b.Bytes(), want)
+		}
+	}
+}
+
+func TestTextToDERError(t *testing.T) {
+	tests := []struct {
+		t  *Type
+		in string
+	}{
+		{&Type{Kind: KindInteger}, `"x"`},
+		{&Type{Kind: KindOctetString}, `"x"`},
+		{transcodeRecord, "{ b 'AB'H, a 1 }"},
+		{transcodeRecord, "{ b 'AB'H }"},
+		{transcodeRecord, "value1 Other ::= { a 1 }"},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		err := TextToDER(&b, tt.t, []byte(tt.in))
+		if _, ok := err.(*ValueError); !ok {
+			t.Errorf("TextToDER(%v, %q): error %v, want ValueError", tt.t, tt.in, err)
+		}
+	}
+	var b bytes.Buffer
+	if _, ok := TextToDER(&b, transcodeRecord, []byte("{ a 1")).(*SyntaxError); !ok {
+		t.Error("TextToDER of invalid value notation: want SyntaxError")
+	}
+}
+
+func TestDERToText(t *testing.T) {
+	tests := []struct {
+		t      *Type
+		data   string
+		indent string
+		want   string
+	}{
+		{&Type{Kind: KindInteger}, "02 01 05 02 01 06", "", "5\n6\n"},
+		{&Type{Kind: KindBoolean}, "01 01 FF", "", "TRUE\n"},
+		{&Type{Kind: KindOctetString}, "04 02 AB CD", "", "'ABCD'H\n"},
+		{
+			transcodeRecord,
+			"30 03 02 01 01 30 06 02 01 02 04 01 AB",
+			"",
+			"value1 Rec ::= { a 1 }\nvalue2 Rec ::= { a 2, b 'AB'H }\n",
+		},
+		{
+			transcodeRecord,
+			"30 06 02 01 02 04 01 AB",
+			"\t",
+			"value1 Rec ::= {\n\ta 2,\n\tb 'AB'H\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		if err := DERToTextIndent(&b, tt.t, fromHex(tt.data), "", tt.indent); err != nil {
+			t.Errorf("DERToTextIndent(%v, %s, %q): %v", tt.t, tt.data, tt.indent, err)
+			continue
+		}
+		if b.String() != tt.want {
+			t.Errorf("DERToTextIndent(%v, %s, %q) = %q, want %q", tt.t, tt.data, tt.indent, b.String(), tt.want)
+		}
+		if tt.indent != "" {
+			continue
+		}
+		var plain bytes.Buffer
+		if err := DERToText(&plain, tt.t, fromHex(tt.data)); err != nil || plain.String() != tt.want {
+			t.Errorf("DERToText(%v, %s) = %q, %v, want %q", tt.t, tt.data, plain.String(), err, tt.want)
+		}
+	}
+}
+
+func TestDERToTextError(t *testing.T) {
+	tests := []struct {
+		t    *Type
+		data string
+	}{
+		{&Type{Kind: KindInteger}, "02 02 00 01"},
+		{&Type{Kind: KindInteger}, "02 02 01"},
+		{&Type{Kind: KindInteger}, "01 01 FF"},
+		{transcodeRecord, "30 00"},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		err := DERToText(&b, tt.t, fromHex(tt.data))
+		if _, ok := err.(*DERSyntaxError); !ok {
+			t.Errorf("DERToText(%v, %s): error %v, want DERSyntaxError", tt.t, tt.data, err)
+		}
+	}
+}
+
+func TestTranscodeRoundTrip(t *testing.T) {
+	pr := personnelRecord()
+	pr.Name = ""
+	in := `{ name { givenName "John", initial "P", familyName "Smith" }, title "Director", number 51, dateOfHire "19710917", nameOfSpouse { givenName "Mary", initial "T", familyName "Smith" }, children { { name { givenName "Ralph", initial "T", familyName "Smith" }, dateOfBirth "19571111" } } }`
+	var der bytes.Buffer
+	if err := TextToDER(&der, pr, []byte(in)); err != nil {
+		t.Fatalf("TextToDER: %v", err)
+	}
+	var text bytes.Buffer
+	if err := DERToText(&text, pr, der.Bytes()); err != nil {
+		t.Fatalf("DERToText: %v", err)
+	}
+	if got := text.String(); got != in+"\n" {
+		t.Errorf("DERToText(TextToDER(%q)) = %q", in, got)
+	}
+}
+
+func TestDERToTextSchema(t *testing.T) {
+	m, err := ParseModule([]byte(`M DEFINITIONS AUTOMATIC TAGS ::= BEGIN
+Header ::= [APPLICATION 1] SEQUENCE { major INTEGER, name UTF8String OPTIONAL }
+Flag ::= BOOLEAN
+Num ::= INTEGER
+END`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		schema *Module
+		data   string
+		want   string
+	}{
+		{m, "61 03 80 01 02", "value1 Header ::= {\n  major 2\n}\n"},
+		{m, "01 01 FF 02 01 05", "value1 Flag ::= TRUE\nvalue2 Num ::= 5\n"},
+		{m, "61 07 80 01 02 81 02 68 69 04 01 AB", "value1 Header ::= {\n  major 2,\n  name \"hi\"\n}\n" +
+			"-- value2 matches no type\n-- [UNIVERSAL 4] OCTET STRING, length 1: AB\n"},
+		{m, "30 06 02 01 05 0C 01 61", "-- value1 matches no type\n-- [UNIVERSAL 16] SEQUENCE, length 6\n" +
+			"--   [UNIVERSAL 2] INTEGER, length 1: 05\n--   [UNIVERSAL 12] UTF8String, length 1: 61\n"},
+		{nil, "61 03 80 01 02", "-- value1 matches no type\n-- [APPLICATION 1], length 3\n--   [0], length 1: 02\n"},
+		{m, "", ""},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		if err := DERToTextSchema(&b, tt.schema, fromHex(tt.data)); err != nil {
+			t.Errorf("DERToTextSchema(%s): %v", tt.data, err)
+			continue
+		}
+		if b.String() != tt.want {
+			t.Errorf("DERToTextSchema(%s):\nhave %q\nwant %q", tt.data, b.String(), tt.want)
+		}
+	}
+	for _, data := range []string{"61 03 80 01", "61 81 03 80 01 02"} {
+		var b bytes.Buffer
+		err := DERToTextSchema(&b, m, fromHex(data))
+		if _, ok := err.(*DERSyntaxError); !ok {
+			t.Errorf("DERToTextSchema(%s): error %v, want DERSyntaxError", data, err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package asn1go
+
+import (
+	"reflect"
+)
+
+// schemaValue converts the value that the typed value assignment of the
+// type reference typ decoded into v to the forms of its type in d.schema,
+// if v is an empty interface and the schema has the type.
+func (d *decodeState) schemaValue(typ string, v reflect.Value) {
+	if d.schema == nil || !v.IsValid() {
+		return
+	}
+	t := d.schema.Type(typ)
+	if t == nil {
+		return
+	}
+	_, pv := indirect(v)
+	if !isEmptyInterface(pv) || pv.IsNil() {
+		return
+	}
+	pv.Set(reflect.ValueOf(typedValue(t, pv.Elem().Interface())))
+}
+
+// typedValue returns the value val, in the form that decoding into an
+// empty interface gives it, in the form DecodeDER stores values of type t
+// in interfaces, except for SEQUENCE and SET values, which stay
+// OrderedObjects. UTCTime and GeneralizedTime values become time.Time, and
+// DATE, TIME-OF-DAY, DATE-TIME and DURATION values Date, TimeOfDay,
+// DateTime and Duration. A value that does not fit t is left as it is.
+func typedValue(t *Type, val interface{}) interface{} {
+	switch t.Kind {
+	case KindSequence, KindSet:
+		obj, ok := val.(OrderedObject)
+		if !ok {
+			return val
+		}
+		typed := make(OrderedObject, len(obj))
+		for i, f := range obj {
+			if c := t.Component(f.Name); c != nil {
+				f.Value = typedValue(c.Type, f.Value)
+			}
+			typed[i] = f
+		}
+		return typed
+
+	case KindSequenceOf, KindSetOf:
+		if obj, ok := val.(OrderedObject); ok && len(obj) == 0 {
+			return []interface{}{}
+		}
+		list, ok := val.([]interface{})
+		if !ok {
+			return val
+		}
+		typed := make([]interface{}, len(list))
+		for i, el := range list {
+			typed[i] = typedValue(t.Elem, el)
+		}
+		return typed
+
+	case KindChoice:
+		m, ok := val.(map[string]interface{})
+		if !ok || len(m) != 1 {
+			return val
+		}
+		for name, v := range m {
+			if c := t.Component(name); c != nil {
+				return map[string]interface{}{name: typedValue(c.Type, v)}
+			}
+		}
+
+	case KindInteger:
+		if s, ok := val.(string); ok {
+			if n, ok := t.NamedValue(s); ok {
+				return n
+			}
+		}
+
+	case KindReal:
+		if n, ok := val.(int64); ok {
+			return float64(n)
+		}
+
+	case KindBitString:
+		switch v := val.(type) {
+		case []byte:
+			return BitString{Bytes: v, BitLength: 8 * len(v)}
+		case OrderedObject:
+			if len(v) == 0 {
+				return BitString{}
+			}
+		case []interface{}:
+			names := make([]string, len(v))
+			for i, el := range v {
+				s, ok := el.(string)
+				if !ok {
+					return val
+				}
+				names[i] = s
+			}
+			if bs, err := namedBits(t.Named, names); err == nil {
+				return bs
+			}
+		case Containing:
+			return containedValue(t, v)
+		}
+
+	case KindOctetString:
+		if v, ok := val.(Containing); ok {
+			return containedValue(t, v)
+		}
+
+	case KindRelativeOID:
+		if oid, ok := val.(ObjectIdentifier); ok {
+			return RelativeOID(oid)
+		}
+
+	case KindUTCTime, KindGeneralizedTime:
+		if s, ok := val.(string); ok {
+			if tm, err := parseTime(t.Kind, s); err == nil {
+				return tm
+			}
+		}
+
+	case KindDate, KindTimeOfDay, KindDateTime, KindDuration:
+		if s, ok := val.(string); ok {
+			if dt, err := parseDate(t.Kind, s); err == nil {
+				return dt
+			}
+		}
+	}
+	return val
+}
+
+// containedValue returns the containing value v of the OCTET STRING or
+// BIT STRING type t with its value in the forms of the type it contains.
+func containedValue(t *Type, v Containing) interface{} {
+	if t.Contains == nil {
+		return v
+	}
+	return Containing{Value: typedValue(t.Contains, v.Value)}
+}
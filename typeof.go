@@ -0,0 +1,252 @@
+package asn1go
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// TypeOf returns the ASN.1 type of values of the Go type of v, for use
+// with the schema-driven encoding rules such as EncodeDER. It is read
+// from the Go type the way Marshal writes it:
+//
+//   - bool is BOOLEAN, integer types are INTEGER, or ENUMERATED with the
+//     "enumerated" tag option, big.Int is INTEGER and float types are REAL.
+//   - The "named:<id>=<n>|..." tag option, such as named:v1=0|v2=1, names
+//     the values of an INTEGER or ENUMERATED or the bits of a BIT STRING;
+//     Marshal and Unmarshal write and read the identifiers, and named bit
+//     lists such as { bitA, bitC }, in their place.
+//   - string is UTF8String, or NumericString, PrintableString, IA5String,
+//     VisibleString, UTCTime or GeneralizedTime with the "numeric",
+//     "printable", "ia5", "visible", "utc" or "generalized" tag option.
+//   - time.Time is GeneralizedTime, or UTCTime with the "utc" tag option;
+//     the encoders write it in UTC. Date, TimeOfDay, DateTime and Duration
+//     are DATE, TIME-OF-DAY, DATE-TIME and DURATION.
+//   - []byte and byte arrays are OCTET STRING, BitString is BIT STRING,
+//     ObjectIdentifier is OBJECT IDENTIFIER and RelativeOID is
+//     RELATIVE-OID. OIDIRI is OID-IRI, or RELATIVE-OID-IRI with the
+//     "relative" tag option.
+//   - Other slices and arrays are SEQUENCE OF, or SET OF with the "set"
+//     tag option, which also applies to their elements.
+//   - Empty structs are NULL, structs with "choice" fields are CHOICE and
+//     other structs are SEQUENCE, or SET with the "set" tag option or a
+//     blank field _ struct{} `asn1:",set"` of their own. Fields that are
+//     pointers or are tagged omitempty are OPTIONAL. A field
+//     tagged "choice:<alt>" is a CHOICE whose only known alternative is
+//     alt. A field tagged "..." holding Extensions makes the type
+//     extensible rather than being a component.
+//   - A field with the "tag:<n>" option has an implicit context-specific
+//     tag [n] in front of its type, such as [1] IMPLICIT OCTET STRING.
+//     The "explicit" option makes the tag explicit, and "application" or
+//     "private" puts it in the APPLICATION or PRIVATE class. The tag of a
+//     CHOICE is always explicit, as ASN.1 requires.
+//   - EmbeddedPDV is EMBEDDED PDV.
+//
+// Pointers stand for the type they point to. Maps, interfaces, channels
+// and functions have no ASN.1 type, nor do External and Value, and TypeOf
+// returns an UnsupportedTypeError for them.
+func TypeOf(v interface{}) (*Type, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return &Type{Kind: KindNull}, nil
+	}
+	b := typeBuilder{structs: make(map[structKey]*Type)}
+	return b.typeOf(t, "")
+}
+
+// A typeBuilder derives ASN.1 types from Go types.
+type typeBuilder struct {
+	// structs holds the types of the structs seen so far, so that
+	// recursive types refer to themselves.
+	structs map[structKey]*Type
+}
+
+type structKey struct {
+	t   reflect.Type
+	set bool
+}
+
+func (b *typeBuilder) typeOf(t reflect.Type, opts tagOptions) (*Type, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	named, err := opts.namedNumbers()
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case timeType:
+		return &Type{Kind: timeKind(opts)}, nil
+	case bitStringType:
+		return &Type{Kind: KindBitString, Named: named}, nil
+	case objectIdentifierType:
+		return &Type{Kind: KindObjectIdentifier}, nil
+	case relativeOIDType:
+		return &Type{Kind: KindRelativeOID}, nil
+	case dateType, timeOfDayType, dateTimeType, durationType:
+		k, _ := dateKind(t)
+		return &Type{Kind: k}, nil
+	case oidIRIType:
+		if opts.Contains("relative") {
+			return &Type{Kind: KindRelativeOIDIRI}, nil
+		}
+		return &Type{Kind: KindOIDIRI}, nil
+	case bigIntType:
+		return &Type{Kind: KindInteger, Named: named}, nil
+	case externalType, valueType:
+		return nil, &UnsupportedTypeError{t}
+	case embeddedPDVType:
+		st, err := b.structType(t, false)
+		if err != nil {
+			return nil, err
+		}
+		return embeddedPDV(st), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Type{Kind: KindBoolean}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if opts.Contains("enumerated") {
+			return &Type{Kind: KindEnumerated, Named: named}, nil
+		}
+		return &Type{Kind: KindInteger, Named: named}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Type{Kind: KindReal}, nil
+	case reflect.String:
+		return &Type{Kind: stringKind(opts)}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Type{Kind: KindOctetString}, nil
+		}
+		elem, err := b.typeOf(t.Elem(), opts)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Contains("set") {
+			return &Type{Kind: KindSetOf, Elem: elem}, nil
+		}
+		return &Type{Kind: KindSequenceOf, Elem: elem}, nil
+	case reflect.Struct:
+		return b.structType(t, opts.Contains("set") || cachedTypeFields(t).set)
+	}
+	return nil, &UnsupportedTypeError{t}
+}
+
+func (b *typeBuilder) structType(t reflect.Type, set bool) (*Type, error) {
+	if t.NumField() == 0 {
+		return &Type{Kind: KindNull}, nil
+	}
+	key := structKey{t, set}
+	if st, ok := b.structs[key]; ok {
+		return st, nil
+	}
+	st := &Type{Name: t.Name(), Kind: KindSequence}
+	if set {
+		st.Kind = KindSet
+	}
+	b.structs[key] = st
+
+	fields := cachedTypeFields(t)
+	if fields.choice {
+		st.Kind = KindChoice
+	}
+	for i := range fields.list {
+		f := &fields.list[i]
+		if fields.choice && !f.alternative {
+			continue
+		}
+		ft := t.FieldByIndex(f.index).Type
+		if f.name == "..." {
+			if ft != extensionsType {
+				return nil, &UnsupportedTypeError{ft}
+			}
+			st.Extensible = true
+			continue
+		}
+		if f.name == "*" {
+			if f.typ != rawChoiceType {
+				return nil, &UnsupportedTypeError{ft}
+			}
+			continue
+		}
+		if ft.Kind() == reflect.Interface {
+			return nil, &UnsupportedTypeError{ft}
+		}
+		ct, err := b.typeOf(ft, f.options)
+		if err != nil {
+			return nil, err
+		}
+		if f.choice != "" {
+			ct = &Type{Kind: KindChoice, Components: []Component{{Name: f.choice, Type: ct}}}
+		}
+		if ct, err = fieldTag(ct, f); err != nil {
+			return nil, err
+		}
+		st.Components = append(st.Components, Component{
+			Name:     f.name,
+			Type:     ct,
+			Optional: !fields.choice && (ft.Kind() == reflect.Pointer || f.omitEmpty),
+		})
+	}
+	return st, nil
+}
+
+// fieldTag returns t tagged as the tag options of the field f select:
+// "tag:<n>" for a context-specific tag, with "application" or "private"
+// for the other classes and "explicit" for an explicit tag.
+func fieldTag(t *Type, f *field) (*Type, error) {
+	num, ok := f.options.Get("tag")
+	if !ok {
+		for _, opt := range []string{"explicit", "implicit", "application", "private"} {
+			if f.options.Contains(opt) {
+				return nil, fmt.Errorf("asn1go: field %s has tag option %q without tag:<number>", f.name, opt)
+			}
+		}
+		return t, nil
+	}
+	n, err := strconv.Atoi(num)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("asn1go: field %s has invalid tag number %q", f.name, num)
+	}
+	tag := TypeTag{Tag: Tag{ClassContextSpecific, n}}
+	switch {
+	case f.options.Contains("application") && f.options.Contains("private"):
+		return nil, fmt.Errorf("asn1go: field %s is tagged both application and private", f.name)
+	case f.options.Contains("application"):
+		tag.Class = ClassApplication
+	case f.options.Contains("private"):
+		tag.Class = ClassPrivate
+	}
+	if f.options.Contains("explicit") {
+		if f.options.Contains("implicit") {
+			return nil, fmt.Errorf("asn1go: field %s is tagged both explicit and implicit", f.name)
+		}
+		tag.Explicit = true
+	}
+	// The type may be shared with other fields, or be the type of a
+	// recursive struct, so it is copied rather than changed.
+	tagged := *t
+	tagged.Tags = append([]TypeTag{tag}, t.Tags...)
+	return &tagged, nil
+}
+
+// stringKind returns the character string type the tag options select.
+func stringKind(opts tagOptions) Kind {
+	switch {
+	case opts.Contains("numeric"):
+		return KindNumericString
+	case opts.Contains("printable"):
+		return KindPrintableString
+	case opts.Contains("ia5"):
+		return KindIA5String
+	case opts.Contains("visible"):
+		return KindVisibleString
+	case opts.Contains("utc"):
+		return KindUTCTime
+	case opts.Contains("generalized"):
+		return KindGeneralizedTime
+	}
+	return KindUTF8String
+}
@@ -0,0 +1,356 @@
+package asn1go
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// BitString is the Go representation of a BIT STRING value, written as a
+// bstring ('0110'B) or an hstring ('60'H) in value notation.
+type BitString struct {
+	Bytes     []byte // bits packed into bytes, most significant bit first.
+	BitLength int    // length in bits.
+}
+
+// At returns the bit at the given index. If the index is out of range it
+// returns 0.
+func (b BitString) At(i int) int {
+	if i < 0 || i >= b.BitLength {
+		return 0
+	}
+	x := i / 8
+	y := 7 - uint(i%8)
+	return int(b.Bytes[x]>>y) & 1
+}
+
+// parseBitString returns the BitString for the binary digits of a bstring.
+func parseBitString(digits []byte) BitString {
+	bs := BitString{Bytes: make([]byte, (len(digits)+7)/8), BitLength: len(digits)}
+	for i, c := range digits {
+		if c == '1' {
+			bs.Bytes[i/8] |= 0x80 >> uint(i%8)
+		}
+	}
+	return bs
+}
+
+var bitStringType = reflect.TypeOf(BitString{})
+
+// namedBits returns the BIT STRING value of the named bit list names, the
+// identifiers of the bits set among the named bits of named, as in
+// { bitA, bitC }. The value ends with its last bit set, as DER requires of
+// a value of a type with named bits.
+func namedBits(named []NamedNumber, names []string) (BitString, error) {
+	var bs BitString
+	for _, name := range names {
+		n, ok := namedValue(named, name)
+		if !ok || n < 0 {
+			return BitString{}, errors.New("unknown named bit " + name)
+		}
+		i := int(n)
+		if i >= bs.BitLength {
+			bs.Bytes = append(bs.Bytes, make([]byte, i/8+1-len(bs.Bytes))...)
+			bs.BitLength = i + 1
+		}
+		bs.Bytes[i/8] |= 0x80 >> uint(i%8)
+	}
+	return bs, nil
+}
+
+// bitNames returns the identifiers of the bits set in bs among the named
+// bits of named, in the order of the bits. It reports false if a bit set
+// has no name.
+func bitNames(named []NamedNumber, bs BitString) ([]string, bool) {
+	names := []string{}
+	for i := 0; i < bs.BitLength; i++ {
+		if bs.At(i) == 0 {
+			continue
+		}
+		name, ok := numberName(named, int64(i))
+		if !ok {
+			return nil, false
+		}
+		names = append(names, name)
+	}
+	return names, true
+}
+
+// trimBits returns bs without its trailing 0 bits, as DER encodes the
+// values of a type with named bits.
+func trimBits(bs BitString) BitString {
+	n := bs.BitLength
+	for n > 0 && bs.At(n-1) == 0 {
+		n--
+	}
+	return BitString{Bytes: bs.Bytes[:(n+7)/8], BitLength: n}
+}
+
+// ObjectIdentifier is the Go representation of an OBJECT IDENTIFIER value
+// such as { 2 23 143 1 2 1 }.
+type ObjectIdentifier []int
+
+// Equal reports whether oi and other represent the same identifier.
+func (oi ObjectIdentifier) Equal(other ObjectIdentifier) bool {
+	if len(oi) != len(other) {
+		return false
+	}
+	for i := range oi {
+		if oi[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the identifier in dotted form, such as "2.23.143.1.2.1".
+func (oi ObjectIdentifier) String() string {
+	var s strings.Builder
+	for i, v := range oi {
+		if i > 0 {
+			s.WriteByte('.')
+		}
+		s.WriteString(strconv.Itoa(v))
+	}
+	return s.String()
+}
+
+// RelativeOID is the Go representation of a RELATIVE-OID value, the arcs
+// of an object identifier below a known one, such as { 4 3 2 }.
+type RelativeOID []int
+
+// String returns the arcs in dotted form, such as "4.3.2".
+func (r RelativeOID) String() string {
+	return ObjectIdentifier(r).String()
+}
+
+// OIDIRI is the Go representation of an OID-IRI value, an object
+// identifier written as the Unicode labels of its arcs, such as
+// "/ISO/Registration-Authority/19785.CBEFF". It is written as a cstring
+// in value notation. Values of RELATIVE-OID-IRI types, such as
+// "Registration-Authority/19785.CBEFF", have no leading solidus.
+type OIDIRI string
+
+// Arcs returns the labels of the arcs of the identifier.
+func (iri OIDIRI) Arcs() []string {
+	return strings.Split(strings.TrimPrefix(string(iri), "/"), "/")
+}
+
+// validIRI reports whether s is an OID-IRI value, or a RELATIVE-OID-IRI
+// value if relative is set: arcs separated by solidi, each a non-empty
+// label of unreserved characters or an integer without leading zeros, with
+// a solidus in front of the first arc of an OID-IRI.
+func validIRI(s string, relative bool) bool {
+	if !utf8.ValidString(s) {
+		return false
+	}
+	if !relative {
+		if !strings.HasPrefix(s, "/") {
+			return false
+		}
+		s = s[1:]
+	}
+	for _, arc := range strings.Split(s, "/") {
+		if arc == "" || len(arc) > 1 && arc[0] == '0' && strings.Trim(arc, "0123456789") == "" {
+			return false
+		}
+		for _, r := range arc {
+			if r < 0x80 && !isLetter(byte(r)) && !isDigit(byte(r)) && !strings.ContainsRune("-._~", r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// RawValue is a raw encoded ASN.1 value notation value.
+// It implements Marshaler and Unmarshaler and can be used to delay
+// decoding or to keep parts of a document verbatim.
+type RawValue []byte
+
+// MarshalASN1 returns m as the value notation encoding of m.
+func (m RawValue) MarshalASN1() ([]byte, error) {
+	if m == nil {
+		return []byte("NULL"), nil
+	}
+	return m, nil
+}
+
+// UnmarshalASN1 sets *m to a copy of data.
+func (m *RawValue) UnmarshalASN1(data []byte) error {
+	if m == nil {
+		return errors.New("asn1go.RawValue: UnmarshalASN1 on nil pointer")
+	}
+	*m = append((*m)[0:0], data...)
+	return nil
+}
+
+// Extensions are the extension additions of a value of an extensible
+// SEQUENCE, SET or CHOICE type that the type does not know, such as the
+// components a later version of a SEQUENCE adds. The schema-driven
+// decoders keep them in the encoding rules they were decoded from, so that
+// the encoder for the same rules writes them back as they were:
+//
+//   - In BER, CER and DER, each is the encoding of an addition.
+//   - In PER and OER, they stand for the additions after those the type
+//     knows, in order, and each is the open type encoding of an addition,
+//     or nil for an absent one. The last of a CHOICE value is its
+//     alternative, except that in OER a CHOICE value holds the encoding
+//     of its alternative, tag and all, as its only element.
+//
+// A SEQUENCE or SET value holds its Extensions as the component "...",
+// the key of a map or the identifier of a struct field tagged
+// asn1:"...". A CHOICE value whose alternative is unknown holds them as
+// its alternative "...", of a ChoiceValue, a map or a struct field tagged
+// asn1:"...,choice". The value notation has no place for them: Marshal
+// leaves them out.
+type Extensions [][]byte
+
+var extensionsType = reflect.TypeOf(Extensions(nil))
+
+// ValueAssignment is a top-level value assignment,
+//
+//	Name Type ::= Value
+//
+// such as value1 ProfileElement ::= header : { ... }. Marshal encodes a
+// ValueAssignment, or a slice of them, as a complete assignment list.
+// Unmarshal and Decoder.Decode fill in Name and Type and decode the value
+// into Value, into the value it points to if Value holds a non-nil
+// pointer. A value without an assignment leaves Name and Type empty.
+type ValueAssignment struct {
+	Name  string      // value reference, such as "value1"
+	Type  string      // type reference, such as "ProfileElement"
+	Value interface{} // the assigned value
+}
+
+// Containing is a value of an OCTET STRING or BIT STRING type with a
+// contents constraint, written in the containing-value notation as
+//
+//	CONTAINING Value
+//
+// such as CONTAINING { version 1 }, where Value is the value the string
+// holds the encoding of. Unmarshal decodes the value contained into Value,
+// and into an empty interface as a Containing; into any other Go value it
+// decodes it as if CONTAINING were not there. Marshal writes a Containing
+// back in the containing-value notation.
+type Containing struct {
+	Value interface{} // the value contained
+}
+
+var containingType = reflect.TypeOf(Containing{})
+
+// ChoiceValue is a value of a CHOICE type, written as
+//
+//	Alternative : Value
+//
+// such as genericFileManagement : { ... }. Unmarshal sets Alternative and
+// decodes the value into Value, into the value it points to if Value holds
+// a non-nil pointer.
+type ChoiceValue struct {
+	Alternative string      // identifier of the chosen alternative
+	Value       interface{} // value of the alternative
+}
+
+// OpenTypeValue is a value of an open type, such as the &Type field of
+// an information object class, written with its type in front of it as
+//
+//	Type : Value
+//
+// such as INTEGER : 5 or TYPE-IDENTIFIER.&Type : { 1 2 }. Type is a type
+// reference, which begins with an upper case letter, possibly with a
+// module reference or field references separated by dots; a type of
+// several words such as OCTET STRING cannot be written this way.
+// Unmarshal decodes such a value into an empty interface as an
+// OpenTypeValue, and into an OpenTypeValue it sets Type and decodes the
+// value into Value, as a new value of the Go type a Registry holds for
+// Type if the Decoder uses one, see Decoder.UseRegistry. Into any other Go
+// value it decodes the value as if Type were not there, unless the Go
+// value is a CHOICE or has a component named Type. Marshal writes an
+// OpenTypeValue back as Type : Value. The schema-driven encoders cannot
+// encode it.
+type OpenTypeValue struct {
+	Type  string      // type of the value, such as "INTEGER"
+	Value interface{} // the value
+}
+
+var openTypeValueType = reflect.TypeOf(OpenTypeValue{})
+
+// RawChoice is a value of a CHOICE type whose alternative a struct does
+// not know, such as one a later version of a specification adds, with the
+// value notation of its value. A CHOICE struct holds it in a field tagged
+// asn1:"*,choice", which a Decoder that keeps unknown alternatives fills,
+// see Decoder.KeepUnknownAlternatives, and which Marshal writes back as
+// Alternative : Value. TypeOf leaves the field out, and the schema-driven
+// encoders cannot encode it.
+type RawChoice struct {
+	Alternative string
+	Value       RawValue
+}
+
+var rawChoiceType = reflect.TypeOf(RawChoice{})
+
+// OrderedObject is the Go representation of a SEQUENCE or SET value that
+// keeps its components in the order they were written, repeated
+// identifiers included, such as { a 1, b 2, a 3 }. Unmarshal stores it for
+// SEQUENCE and SET values into an interface value, and Marshal writes it
+// back in the same order.
+type OrderedObject []Field
+
+// Field is a component of an OrderedObject.
+type Field struct {
+	Name  string      // identifier of the component
+	Value interface{} // value of the component
+}
+
+var orderedObjectType = reflect.TypeOf(OrderedObject(nil))
+
+// Get returns the value of the first component of o named name, reporting
+// whether there is one.
+func (o OrderedObject) Get(name string) (interface{}, bool) {
+	for _, f := range o {
+		if f.Name == name {
+			return f.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Map returns the components of o as a map, the form DecodeDER stores
+// SEQUENCE values in. Of components with the same identifier, the last
+// one counts.
+func (o OrderedObject) Map() map[string]interface{} {
+	m := make(map[string]interface{}, len(o))
+	for _, f := range o {
+		m[f.Name] = f.Value
+	}
+	return m
+}
+
+// MarshalJSON returns o as a JSON object with the members in the order of
+// the components, repeated names included.
+func (o OrderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,124 @@
+package asn1go
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBitStringAt(t *testing.T) {
+	b := BitString{Bytes: []byte{0xA5, 0x80}, BitLength: 9}
+	want := []int{1, 0, 1, 0, 0, 1, 0, 1, 1}
+	for i, w := range want {
+		if got := b.At(i); got != w {
+			t.Errorf("At(%d) = %d, want %d", i, got, w)
+		}
+	}
+	for _, i := range []int{-1, 9, 16} {
+		if got := b.At(i); got != 0 {
+			t.Errorf("At(%d) = %d, want 0", i, got)
+		}
+	}
+}
+
+func TestObjectIdentifier(t *testing.T) {
+	tests := []struct {
+		a, b  ObjectIdentifier
+		equal bool
+		str   string
+	}{
+		{ObjectIdentifier{2, 23, 143, 1, 2, 1}, ObjectIdentifier{2, 23, 143, 1, 2, 1}, true, "2.23.143.1.2.1"},
+		{ObjectIdentifier{2, 23, 143}, ObjectIdentifier{2, 23, 143, 1}, false, "2.23.143"},
+		{ObjectIdentifier{1, 2}, ObjectIdentifier{1, 3}, false, "1.2"},
+		{nil, ObjectIdentifier{}, true, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.a.Equal(tt.b); got != tt.equal {
+			t.Errorf("%v.Equal(%v) = %v, want %v", tt.a, tt.b, got, tt.equal)
+		}
+		if got := tt.a.String(); got != tt.str {
+			t.Errorf("String() = %q, want %q", got, tt.str)
+		}
+	}
+}
+
+func TestRawValue(t *testing.T) {
+	var v struct {
+		A RawValue
+		B []RawValue
+	}
+	if err := Unmarshal([]byte(`{ a { x 1,  y "z" }, b { 1, header : { } } }`), &v); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if string(v.A) != `{ x 1,  y "z" }` {
+		t.Errorf("A = %q", v.A)
+	}
+	if len(v.B) != 2 || string(v.B[0]) != "1" || string(v.B[1]) != "header : { }" {
+		t.Errorf("B = %q", v.B)
+	}
+
+	var m RawValue
+	data := []byte("5")
+	if err := m.UnmarshalASN1(data); err != nil {
+		t.Fatalf("UnmarshalASN1: %v", err)
+	}
+	data[0] = '6'
+	if string(m) != "5" {
+		t.Errorf("UnmarshalASN1 keeps its input: %q", m)
+	}
+}
+
+func TestRelativeOID(t *testing.T) {
+	tests := []struct {
+		r   RelativeOID
+		str string
+	}{
+		{RelativeOID{4, 3, 2}, "4.3.2"},
+		{RelativeOID{300}, "300"},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.r.String(); got != tt.str {
+			t.Errorf("%#v.String() = %q, want %q", tt.r, got, tt.str)
+		}
+	}
+}
+
+func TestOIDIRIArcs(t *testing.T) {
+	tests := []struct {
+		iri  OIDIRI
+		arcs []string
+	}{
+		{"/ISO/Registration-Authority/19785.CBEFF", []string{"ISO", "Registration-Authority", "19785.CBEFF"}},
+		{"Registration-Authority/19785.CBEFF", []string{"Registration-Authority", "19785.CBEFF"}},
+		{"/2", []string{"2"}},
+	}
+	for _, tt := range tests {
+		if got := tt.iri.Arcs(); !reflect.DeepEqual(got, tt.arcs) {
+			t.Errorf("OIDIRI(%q).Arcs() = %q, want %q", tt.iri, got, tt.arcs)
+		}
+	}
+}
+
+func TestValidIRI(t *testing.T) {
+	tests := []struct {
+		s        string
+		relative bool
+		valid    bool
+	}{
+		{"/ISO/Registration-Authority/19785.CBEFF", false, true},
+		{"/2/0", false, true},
+		{"ISO/a", false, false},
+		{"/ISO//a", false, false},
+		{"/01", false, false},
+		{"/a b", false, false},
+		{"/Ünicode", false, true},
+		{"a/b", true, true},
+		{"/a/b", true, false},
+		{"a/", true, false},
+	}
+	for _, tt := range tests {
+		if got := validIRI(tt.s, tt.relative); got != tt.valid {
+			t.Errorf("validIRI(%q, %v) = %v, want %v", tt.s, tt.relative, got, tt.valid)
+		}
+	}
+}
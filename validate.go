@@ -0,0 +1,58 @@
+package asn1go
+
+// Validate checks that the value assignments in the value notation in
+// data, such as a profile package, are valid values of their types in the
+// module schema. Each value must be a value assignment whose type
+// reference is assigned in the module, and each value must be written in
+// the notation of its type: the SEQUENCE and SET values with known
+// components only, unless their types are extensible, and with all
+// components that are neither OPTIONAL nor DEFAULT, those of a SEQUENCE in
+// the order of its type and those of a SET in any order, NULL for NULL,
+// an hstring for an OCTET STRING, a number or named number for an INTEGER
+// and so on, as TextToDER reads them.
+//
+// Malformed value notation is reported as a SyntaxError, and the first
+// value that is not valid as a ValidationError.
+func Validate(schema *Module, data []byte) error {
+	d := newDecodeState()
+	defer freeDecodeState(d)
+	n, err := checkValid(data, &d.scan)
+	if err != nil {
+		return err
+	}
+	d.init(data)
+	d.scan.reset()
+	d.scanWhile(scanSkipSpace)
+
+	e := textEncoder{d: d, module: schema}
+	for i := 0; i < n; i++ {
+		if _, err := e.topValue(nil); err != nil {
+			if ve, ok := err.(*ValueError); ok {
+				return &ValidationError{ve.Path, ve.Type, ve.Msg, int64(d.readIndex())}
+			}
+			return err
+		}
+		d.nextTopValue()
+	}
+	return d.savedError
+}
+
+// A ValidationError describes a value that is not a valid value of its
+// type.
+type ValidationError struct {
+	Path   string // value reference and component identifiers, such as "value1.header.iccid"
+	Type   string // the ASN.1 type, or "" if the value has none
+	Msg    string
+	Offset int64 // error occurred after reading Offset bytes
+}
+
+func (e *ValidationError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "value"
+	}
+	if e.Type == "" {
+		return "asn1go: invalid " + path + ": " + e.Msg
+	}
+	return "asn1go: invalid " + path + " of type " + e.Type + ": " + e.Msg
+}
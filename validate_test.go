@@ -0,0 +1,76 @@
+package asn1go
+
+import "testing"
+
+const peModuleSrc = `PE DEFINITIONS AUTOMATIC TAGS ::= BEGIN
+ProfileElement ::= CHOICE { header ProfileHeader, mf MF }
+ProfileHeader ::= SEQUENCE {
+	major-version INTEGER,
+	minor-version INTEGER,
+	profileType UTF8String OPTIONAL,
+	iccid OCTET STRING,
+	eUICC-Mandatory-services SEQUENCE { usim NULL OPTIONAL, milenage NULL OPTIONAL },
+	eUICC-Mandatory-GFSTEList SEQUENCE OF OBJECT IDENTIFIER
+}
+MF ::= SEQUENCE { state ENUMERATED { on, off } DEFAULT on }
+END`
+
+func TestValidate(t *testing.T) {
+	m, err := ParseModule([]byte(peModuleSrc))
+	if err != nil {
+		t.Fatalf("ParseModule: %v", err)
+	}
+	tests := []string{
+		`value1 ProfileElement ::= header : {
+  major-version 2, minor-version 3, profileType "x", iccid '8900'H,
+  eUICC-Mandatory-services { usim NULL },
+  eUICC-Mandatory-GFSTEList { { 2 23 143 1 2 1 } }
+}
+value2 ProfileElement ::= mf : { state off }`,
+		`value1 ProfileElement ::= mf : { }`,
+		`value1 MF ::= { state on }`,
+	}
+	for _, in := range tests {
+		if err := Validate(m, []byte(in)); err != nil {
+			t.Errorf("Validate(%q): %v", in, err)
+		}
+	}
+}
+
+func TestValidateError(t *testing.T) {
+	m, err := ParseModule([]byte(peModuleSrc))
+	if err != nil {
+		t.Fatalf("ParseModule: %v", err)
+	}
+	const header = `value1 ProfileElement ::= header : { major-version 2, minor-version 3, iccid '00'H, `
+	tests := []struct {
+		in   string
+		path string
+		typ  string
+	}{
+		{`value1 Foo ::= 5`, "value1", "Foo"},
+		{`5`, "", ""},
+		{`value1 ProfileElement ::= xx : { }`, "value1", "ProfileElement"},
+		{`value1 ProfileElement ::= header : { major-version 2 }`, "value1.header", "ProfileHeader"},
+		{`value1 ProfileElement ::= header : { major-version '00'H }`, "value1.header.major-version", "[0] IMPLICIT INTEGER"},
+		{`value1 ProfileElement ::= header : { major-version 2, minor-version 3, iccid 5 }`, "value1.header.iccid", "[3] IMPLICIT OCTET STRING"},
+		{`value1 ProfileElement ::= mf : { state maybe }`, "value1.mf.state", "[0] IMPLICIT ENUMERATED"},
+		{`value1 ProfileElement ::= mf : { other 1 }`, "value1.mf", "MF"},
+		{header + `eUICC-Mandatory-services { usim 1 }, eUICC-Mandatory-GFSTEList {} }`, "value1.header.eUICC-Mandatory-services.usim", "[0] IMPLICIT NULL"},
+		{header + `eUICC-Mandatory-services { }, eUICC-Mandatory-GFSTEList { {1 2}, NULL } }`, "value1.header.eUICC-Mandatory-GFSTEList.1", "OBJECT IDENTIFIER"},
+	}
+	for _, tt := range tests {
+		err := Validate(m, []byte(tt.in))
+		ve, ok := err.(*ValidationError)
+		if !ok {
+			t.Errorf("Validate(%q): error %v, want ValidationError", tt.in, err)
+			continue
+		}
+		if ve.Path != tt.path || ve.Type != tt.typ {
+			t.Errorf("Validate(%q): error at %q of type %q, want %q of type %q", tt.in, ve.Path, ve.Type, tt.path, tt.typ)
+		}
+	}
+	if _, ok := Validate(m, []byte(`value1 MF ::= { state`)).(*SyntaxError); !ok {
+		t.Error("Validate of malformed value notation: want SyntaxError")
+	}
+}
@@ -0,0 +1,714 @@
+package asn1go
+
+import (
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A ValueKind is the kind of value a Value holds.
+type ValueKind uint8
+
+const (
+	ValueInvalid    ValueKind = iota // the zero Value
+	ValueNull                        // NULL
+	ValueBoolean                     // TRUE or FALSE
+	ValueInteger                     // INTEGER, of any size
+	ValueReal                        // REAL
+	ValueString                      // cstring, of a character string type
+	ValueOctets                      // hstring, of an OCTET STRING
+	ValueBits                        // bstring, of a BIT STRING
+	ValueIdentifier                  // identifier, such as an ENUMERATED value
+	ValueOID                         // OBJECT IDENTIFIER or RELATIVE-OID value
+	ValueSequence                    // SEQUENCE or SET value, components in order
+	ValueList                        // SEQUENCE OF or SET OF value
+	ValueChoice                      // CHOICE value, alternative : value
+	ValueOpenType                    // open type value, Type : value
+	ValueContaining                  // containing value, CONTAINING value
+)
+
+var valueKindNames = [...]string{
+	ValueInvalid:    "invalid",
+	ValueNull:       "Null",
+	ValueBoolean:    "Boolean",
+	ValueInteger:    "Integer",
+	ValueReal:       "Real",
+	ValueString:     "String",
+	ValueOctets:     "Octets",
+	ValueBits:       "Bits",
+	ValueIdentifier: "Identifier",
+	ValueOID:        "OID",
+	ValueSequence:   "Sequence",
+	ValueList:       "List",
+	ValueChoice:     "Choice",
+	ValueOpenType:   "OpenType",
+	ValueContaining: "Containing",
+}
+
+// String returns the name of the kind, such as "Sequence".
+func (k ValueKind) String() string {
+	if int(k) < len(valueKindNames) {
+		return valueKindNames[k]
+	}
+	return "ValueKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// A Value is an ASN.1 value of any type, for programs that inspect and
+// transform values without Go types of their own and without type
+// switches over the forms Unmarshal stores in an empty interface. Unlike
+// those forms, a Value tells identifiers, such as ENUMERATED values, from
+// cstrings, and CHOICE values from SEQUENCE values of one component.
+//
+// Unmarshal, Marshal and the schema-driven codecs, such as DecodeDER and
+// EncodeDER, read and write Values as they do the values of other Go
+// types. The zero Value is invalid; the New functions make the others.
+// Values are immutable: the slices the accessors return must not be
+// modified.
+type Value struct {
+	kind   ValueKind
+	num    int64    // of a Boolean, an Integer or the bit length of Bits
+	big    *big.Int // of an Integer too large for num
+	real   float64
+	str    string // of a String or Identifier, or the name of a Choice or OpenType
+	bytes  []byte // of Octets or Bits
+	oid    ObjectIdentifier
+	elems  []Value // of a List, or the single value of a Choice, OpenType or Containing
+	fields []ValueField
+}
+
+// A ValueField is a component of a SEQUENCE or SET Value.
+type ValueField struct {
+	Name  string
+	Value Value
+}
+
+var valueType = reflect.TypeOf(Value{})
+
+// NewNull returns the NULL Value.
+func NewNull() Value { return Value{kind: ValueNull} }
+
+// NewBool returns the BOOLEAN Value b.
+func NewBool(b bool) Value {
+	v := Value{kind: ValueBoolean}
+	if b {
+		v.num = 1
+	}
+	return v
+}
+
+// NewInt returns the INTEGER Value n.
+func NewInt(n int64) Value { return Value{kind: ValueInteger, num: n} }
+
+// NewBigInt returns the INTEGER Value n, which it copies.
+func NewBigInt(n *big.Int) Value {
+	if n.IsInt64() {
+		return NewInt(n.Int64())
+	}
+	return Value{kind: ValueInteger, big: new(big.Int).Set(n)}
+}
+
+// NewReal returns the REAL Value f.
+func NewReal(f float64) Value { return Value{kind: ValueReal, real: f} }
+
+// NewString returns the character string Value s, written as a cstring.
+func NewString(s string) Value { return Value{kind: ValueString, str: s} }
+
+// NewOctets returns the OCTET STRING Value b, written as an hstring.
+func NewOctets(b []byte) Value {
+	return Value{kind: ValueOctets, bytes: append([]byte{}, b...)}
+}
+
+// NewBits returns the BIT STRING Value bs, written as a bstring.
+func NewBits(bs BitString) Value {
+	return Value{kind: ValueBits, bytes: append([]byte{}, bs.Bytes...), num: int64(bs.BitLength)}
+}
+
+// NewIdentifier returns the Value written as the identifier name, such as
+// an ENUMERATED value or a named INTEGER value.
+func NewIdentifier(name string) Value { return Value{kind: ValueIdentifier, str: name} }
+
+// NewOID returns the OBJECT IDENTIFIER Value oid.
+func NewOID(oid ObjectIdentifier) Value {
+	return Value{kind: ValueOID, oid: append(ObjectIdentifier{}, oid...)}
+}
+
+// NewSequence returns the SEQUENCE or SET Value of the components fields,
+// in order.
+func NewSequence(fields ...ValueField) Value {
+	return Value{kind: ValueSequence, fields: append([]ValueField{}, fields...)}
+}
+
+// NewList returns the SEQUENCE OF or SET OF Value of the elements elems.
+func NewList(elems ...Value) Value {
+	return Value{kind: ValueList, elems: append([]Value{}, elems...)}
+}
+
+// NewChoice returns the CHOICE Value of the alternative alt with value v.
+func NewChoice(alt string, v Value) Value {
+	return Value{kind: ValueChoice, str: alt, elems: []Value{v}}
+}
+
+// NewOpenType returns the open type Value of the type typ with value v,
+// written as typ : v.
+func NewOpenType(typ string, v Value) Value {
+	return Value{kind: ValueOpenType, str: typ, elems: []Value{v}}
+}
+
+// NewContaining returns the containing Value of v, written as CONTAINING
+// v, for an OCTET STRING or BIT STRING that holds the encoding of v.
+func NewContaining(v Value) Value {
+	return Value{kind: ValueContaining, elems: []Value{v}}
+}
+
+// Kind returns the kind of v.
+func (v Value) Kind() ValueKind { return v.kind }
+
+// Bool returns the BOOLEAN value of v, or false if v is not one.
+func (v Value) Bool() bool { return v.kind == ValueBoolean && v.num != 0 }
+
+// Int returns the INTEGER value of v. It reports false if v is not an
+// INTEGER or does not fit an int64.
+func (v Value) Int() (int64, bool) {
+	return v.num, v.kind == ValueInteger && v.big == nil
+}
+
+// BigInt returns the INTEGER value of v as a new big.Int, or nil if v is
+// not an INTEGER.
+func (v Value) BigInt() *big.Int {
+	switch {
+	case v.kind != ValueInteger:
+		return nil
+	case v.big != nil:
+		return new(big.Int).Set(v.big)
+	}
+	return big.NewInt(v.num)
+}
+
+// Float returns the REAL value of v, or 0 if v is not a REAL.
+func (v Value) Float() float64 { return v.real }
+
+// Text returns the characters of a String Value, or the identifier of an
+// Identifier Value, and "" for other Values.
+func (v Value) Text() string {
+	if v.kind == ValueString || v.kind == ValueIdentifier {
+		return v.str
+	}
+	return ""
+}
+
+// Name returns the alternative of a Choice Value or the type of an
+// OpenType Value, and "" for other Values.
+func (v Value) Name() string {
+	if v.kind == ValueChoice || v.kind == ValueOpenType {
+		return v.str
+	}
+	return ""
+}
+
+// Bytes returns the octets of an Octets Value, and nil for other Values.
+func (v Value) Bytes() []byte {
+	if v.kind == ValueOctets {
+		return v.bytes
+	}
+	return nil
+}
+
+// Bits returns the bits of a Bits Value, and an empty BitString for other
+// Values.
+func (v Value) Bits() BitString {
+	if v.kind == ValueBits {
+		return BitString{Bytes: v.bytes, BitLength: int(v.num)}
+	}
+	return BitString{}
+}
+
+// OID returns the arcs of an OID Value, and nil for other Values.
+func (v Value) OID() ObjectIdentifier { return v.oid }
+
+// Len returns the number of elements of a List Value or components of a
+// Sequence Value, and 0 for other Values.
+func (v Value) Len() int {
+	switch v.kind {
+	case ValueList:
+		return len(v.elems)
+	case ValueSequence:
+		return len(v.fields)
+	}
+	return 0
+}
+
+// Index returns the ith element of a List Value. It panics if v is not a
+// List or i is out of range.
+func (v Value) Index(i int) Value {
+	if v.kind != ValueList {
+		panic("asn1go: Index of " + v.kind.String() + " Value")
+	}
+	return v.elems[i]
+}
+
+// Elems returns the elements of a List Value, and nil for other Values.
+func (v Value) Elems() []Value {
+	if v.kind == ValueList {
+		return v.elems
+	}
+	return nil
+}
+
+// Fields returns the components of a Sequence Value, in order, and nil
+// for other Values.
+func (v Value) Fields() []ValueField { return v.fields }
+
+// Field returns the value of the first component of a Sequence Value with
+// the identifier name. It reports false if there is none.
+func (v Value) Field(name string) (Value, bool) {
+	for _, f := range v.fields {
+		if f.Name == name {
+			return f.Value, true
+		}
+	}
+	return Value{}, false
+}
+
+// Elem returns the value of a Choice, OpenType or Containing Value, and the
+// zero Value for other Values.
+func (v Value) Elem() Value {
+	switch v.kind {
+	case ValueChoice, ValueOpenType, ValueContaining:
+		return v.elems[0]
+	}
+	return Value{}
+}
+
+// Interface returns v in the form Unmarshal stores in an empty interface:
+// nil, bool, int64 or *big.Int, float64, string, []byte, BitString,
+// ObjectIdentifier, OrderedObject, []interface{}, a single-entry
+// map[string]interface{} for a CHOICE value, OpenTypeValue or Containing.
+// Identifiers become strings.
+func (v Value) Interface() interface{} {
+	switch v.kind {
+	case ValueBoolean:
+		return v.Bool()
+	case ValueInteger:
+		if v.big != nil {
+			return v.BigInt()
+		}
+		return v.num
+	case ValueReal:
+		return v.real
+	case ValueString, ValueIdentifier:
+		return v.str
+	case ValueOctets:
+		return v.bytes
+	case ValueBits:
+		return v.Bits()
+	case ValueOID:
+		return v.oid
+	case ValueSequence:
+		obj := make(OrderedObject, len(v.fields))
+		for i, f := range v.fields {
+			obj[i] = Field{Name: f.Name, Value: f.Value.Interface()}
+		}
+		return obj
+	case ValueList:
+		list := make([]interface{}, len(v.elems))
+		for i, el := range v.elems {
+			list[i] = el.Interface()
+		}
+		return list
+	case ValueChoice:
+		return map[string]interface{}{v.str: v.elems[0].Interface()}
+	case ValueOpenType:
+		return OpenTypeValue{Type: v.str, Value: v.elems[0].Interface()}
+	case ValueContaining:
+		return Containing{Value: v.elems[0].Interface()}
+	}
+	return nil
+}
+
+// ValueOf returns the Value of x, one of the forms Unmarshal and the
+// schema-driven decoders store in an empty interface, a Go integer or
+// float, a Number, a ChoiceValue or a Value. A map[string]interface{}
+// with a single entry is a CHOICE value, and other maps are SEQUENCE
+// values with their components in the order of their identifiers; strings
+// are character strings. Components named "...", which hold unknown
+// extension additions, are left out. Other types are reported as an
+// UnsupportedTypeError.
+func ValueOf(x interface{}) (Value, error) {
+	switch x := x.(type) {
+	case nil:
+		return NewNull(), nil
+	case Value:
+		return x, nil
+	case bool:
+		return NewBool(x), nil
+	case int:
+		return NewInt(int64(x)), nil
+	case int8:
+		return NewInt(int64(x)), nil
+	case int16:
+		return NewInt(int64(x)), nil
+	case int32:
+		return NewInt(int64(x)), nil
+	case int64:
+		return NewInt(x), nil
+	case uint:
+		return NewBigInt(new(big.Int).SetUint64(uint64(x))), nil
+	case uint8:
+		return NewInt(int64(x)), nil
+	case uint16:
+		return NewInt(int64(x)), nil
+	case uint32:
+		return NewInt(int64(x)), nil
+	case uint64:
+		return NewBigInt(new(big.Int).SetUint64(x)), nil
+	case *big.Int:
+		if x == nil {
+			return NewNull(), nil
+		}
+		return NewBigInt(x), nil
+	case float32:
+		return NewReal(float64(x)), nil
+	case float64:
+		return NewReal(x), nil
+	case Number:
+		if strings.ContainsAny(string(x), ".eE") {
+			f, err := x.Float64()
+			return NewReal(f), err
+		}
+		n, err := x.BigInt()
+		if err != nil {
+			return Value{}, err
+		}
+		return NewBigInt(n), nil
+	case string:
+		return NewString(x), nil
+	case []byte:
+		return NewOctets(x), nil
+	case BitString:
+		return NewBits(x), nil
+	case ObjectIdentifier:
+		return NewOID(x), nil
+	case RelativeOID:
+		return NewOID(ObjectIdentifier(x)), nil
+	case OrderedObject:
+		fields := make([]ValueField, 0, len(x))
+		for _, f := range x {
+			if f.Name == "..." {
+				continue
+			}
+			fv, err := ValueOf(f.Value)
+			if err != nil {
+				return Value{}, err
+			}
+			fields = append(fields, ValueField{f.Name, fv})
+		}
+		return Value{kind: ValueSequence, fields: fields}, nil
+	case map[string]interface{}:
+		names := make([]string, 0, len(x))
+		for name := range x {
+			if name != "..." {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		fields := make([]ValueField, len(names))
+		for i, name := range names {
+			fv, err := ValueOf(x[name])
+			if err != nil {
+				return Value{}, err
+			}
+			fields[i] = ValueField{name, fv}
+		}
+		if len(x) == 1 && len(fields) == 1 {
+			return NewChoice(fields[0].Name, fields[0].Value), nil
+		}
+		return Value{kind: ValueSequence, fields: fields}, nil
+	case []interface{}:
+		elems := make([]Value, len(x))
+		for i, el := range x {
+			ev, err := ValueOf(el)
+			if err != nil {
+				return Value{}, err
+			}
+			elems[i] = ev
+		}
+		return Value{kind: ValueList, elems: elems}, nil
+	case ChoiceValue:
+		ev, err := ValueOf(x.Value)
+		return NewChoice(x.Alternative, ev), err
+	case OpenTypeValue:
+		ev, err := ValueOf(x.Value)
+		return NewOpenType(x.Type, ev), err
+	case Containing:
+		ev, err := ValueOf(x.Value)
+		return NewContaining(ev), err
+	}
+	return Value{}, &UnsupportedTypeError{reflect.TypeOf(x)}
+}
+
+// valueOfType is like ValueOf for the form x of a value of type t that a
+// schema-driven decoder stored in an empty interface, which t tells the
+// kind of: the components of SEQUENCE and SET values are in the order of
+// t, a CHOICE value may have any number of components and the strings of
+// ENUMERATED and INTEGER values are identifiers.
+func valueOfType(t *Type, x interface{}) (Value, error) {
+	switch t.Kind {
+	case KindSequence, KindSet:
+		m, ok := x.(map[string]interface{})
+		if !ok {
+			break
+		}
+		var fields []ValueField
+		for _, c := range t.Components {
+			cx, ok := m[c.Name]
+			if !ok {
+				continue
+			}
+			cv, err := valueOfType(c.Type, cx)
+			if err != nil {
+				return Value{}, err
+			}
+			fields = append(fields, ValueField{c.Name, cv})
+		}
+		return Value{kind: ValueSequence, fields: fields}, nil
+	case KindChoice:
+		m, ok := x.(map[string]interface{})
+		if !ok || len(m) != 1 {
+			break
+		}
+		for name, ax := range m {
+			if c := t.Component(name); c != nil {
+				av, err := valueOfType(c.Type, ax)
+				return NewChoice(name, av), err
+			}
+		}
+	case KindSequenceOf, KindSetOf:
+		list, ok := x.([]interface{})
+		if !ok {
+			break
+		}
+		elems := make([]Value, len(list))
+		for i, el := range list {
+			ev, err := valueOfType(t.Elem, el)
+			if err != nil {
+				return Value{}, err
+			}
+			elems[i] = ev
+		}
+		return Value{kind: ValueList, elems: elems}, nil
+	case KindEnumerated, KindInteger:
+		if s, ok := x.(string); ok {
+			return NewIdentifier(s), nil
+		}
+	}
+	return ValueOf(x)
+}
+
+// dynamic decodes a value of type t into the Value v by way of its
+// generic form, which decode stores in the empty interface it is given.
+func (s *storer) dynamic(t *Type, off int, v reflect.Value, decode func(reflect.Value)) {
+	var x interface{}
+	decode(reflect.ValueOf(&x).Elem())
+	val, err := valueOfType(t, x)
+	if err != nil {
+		s.typeError(t, off, v)
+		return
+	}
+	v.Set(reflect.ValueOf(val))
+}
+
+// dynamicValue is like valueInterface but returns a Value.
+func (d *decodeState) dynamicValue() Value {
+	switch d.opcode {
+	default:
+		panic(phasePanicMsg)
+	case scanBeginObject:
+		val := d.objectValue()
+		d.scanNext()
+		return val
+	case scanBeginLiteral:
+	}
+	item, _, choice := d.literal()
+	if choice {
+		name := string(item)
+		if isOpenType(item) {
+			return NewOpenType(name, d.dynamicValue())
+		}
+		return NewChoice(name, d.dynamicValue())
+	}
+	if string(item) == containingKeyword {
+		return NewContaining(d.dynamicValue())
+	}
+	return d.literalValue(item)
+}
+
+// topDynamicValue is like dynamicValue for a top-level value that is not
+// a value assignment and begins with the identifier name, which topValue
+// has read.
+func (d *decodeState) topDynamicValue(name []byte) Value {
+	switch d.opcode {
+	case scanChoiceTag:
+		d.scanWhile(scanSkipSpace)
+		if isOpenType(name) {
+			return NewOpenType(string(name), d.dynamicValue())
+		}
+		return NewChoice(string(name), d.dynamicValue())
+	case scanBeginObject, scanBeginLiteral:
+		// The identifier was CONTAINING.
+		return NewContaining(d.dynamicValue())
+	}
+	return d.literalValue(name)
+}
+
+// objectValue is like objectInterface but returns a Value.
+func (d *decodeState) objectValue() Value {
+	var fields []ValueField
+	var elems []Value
+	oid := false
+	d.scanWhile(scanSkipSpace)
+	for first := true; d.opcode != scanEndObject; first = false {
+		kind, name, _ := d.elementHead()
+		if first && kind == elementComponent {
+			fields = []ValueField{}
+		}
+		if fields != nil && kind != elementComponent && kind != elementChoice {
+			for _, f := range fields {
+				elems = append(elems, NewChoice(f.Name, f.Value))
+			}
+			fields = nil
+		}
+		switch {
+		case fields != nil:
+			fields = append(fields, ValueField{d.str(name), d.dynamicValue()})
+		case kind == elementContaining:
+			elems = append(elems, NewContaining(d.dynamicValue()))
+		case kind == elementName:
+			elems = append(elems, d.literalValue(name))
+		case kind == elementValue:
+			elems = append(elems, d.dynamicValue())
+		case isOpenType(name):
+			elems = append(elems, NewOpenType(string(name), d.dynamicValue()))
+		default:
+			elems = append(elems, NewChoice(string(name), d.dynamicValue()))
+		}
+		if d.nextElement() {
+			oid = true
+		}
+	}
+
+	switch {
+	case fields != nil:
+		return Value{kind: ValueSequence, fields: fields}
+	case oid:
+		list := make([]interface{}, len(elems))
+		for i, el := range elems {
+			list[i] = el.Interface()
+		}
+		if oid, ok := d.objectIdentifierInterface(list).(ObjectIdentifier); ok {
+			return Value{kind: ValueOID, oid: oid}
+		}
+		return Value{}
+	case elems != nil:
+		return Value{kind: ValueList, elems: elems}
+	}
+	return Value{kind: ValueSequence}
+}
+
+// literalValue is like literalInterface but returns a Value, which keeps
+// identifiers apart from cstrings.
+func (d *decodeState) literalValue(item []byte) Value {
+	if val, ok, err := d.resolveLiteral(item, valueType); ok || err != nil {
+		if err == nil {
+			var v Value
+			if v, err = ValueOf(val); err == nil {
+				return v
+			}
+		}
+		d.saveError(err)
+		return Value{}
+	}
+	if _, ref := d.refs[string(item)]; !ref && d.literalKind(item) == TokenNamedValue {
+		return NewIdentifier(string(item))
+	}
+	v, err := ValueOf(d.literalInterface(item))
+	if err != nil {
+		d.saveError(err)
+	}
+	return v
+}
+
+// dynamicValue writes the Value v.
+func (e *encodeState) dynamicValue(v Value) {
+	switch v.kind {
+	case ValueNull:
+		e.WriteString("NULL")
+	case ValueBoolean:
+		if v.Bool() {
+			e.WriteString("TRUE")
+		} else {
+			e.WriteString("FALSE")
+		}
+	case ValueInteger:
+		if v.big != nil {
+			e.WriteString(v.big.String())
+		} else {
+			e.Write(strconv.AppendInt(e.scratch[:0], v.num, 10))
+		}
+	case ValueReal:
+		e.real(v.real, 64)
+	case ValueString:
+		e.cstring(reflect.ValueOf(v.str))
+	case ValueOctets:
+		e.writeHex(v.bytes)
+	case ValueBits:
+		e.bitString(v.Bits())
+	case ValueIdentifier:
+		if !isValidReference(v.str) {
+			e.error(&UnsupportedValueError{reflect.ValueOf(v), "identifier " + strconv.Quote(v.str) + " is not an identifier"})
+		}
+		e.WriteString(v.str)
+	case ValueOID:
+		e.objectIdentifier(reflect.ValueOf(v.oid))
+	case ValueSequence:
+		e.beginBrace()
+		for i, f := range v.fields {
+			if !isValidReference(f.Name) {
+				e.error(&UnsupportedValueError{reflect.ValueOf(v), "component name " + strconv.Quote(f.Name) + " is not an identifier"})
+			}
+			e.elementSeparator(i)
+			e.WriteString(f.Name)
+			e.WriteByte(' ')
+			e.dynamicValue(f.Value)
+		}
+		e.endBrace(len(v.fields))
+	case ValueList:
+		e.beginBrace()
+		for i, el := range v.elems {
+			e.elementSeparator(i)
+			e.dynamicValue(el)
+		}
+		e.endBrace(len(v.elems))
+	case ValueChoice:
+		if !isValidReference(v.str) {
+			e.error(&UnsupportedValueError{reflect.ValueOf(v), "CHOICE alternative " + strconv.Quote(v.str) + " is not an identifier"})
+		}
+		e.WriteString(v.str)
+		e.WriteString(" : ")
+		e.dynamicValue(v.elems[0])
+	case ValueOpenType:
+		if !isValidOpenType(v.str) {
+			e.error(&UnsupportedValueError{reflect.ValueOf(v), "open type " + strconv.Quote(v.str) + " is not a type reference"})
+		}
+		e.WriteString(v.str)
+		e.WriteString(" : ")
+		e.dynamicValue(v.elems[0])
+	case ValueContaining:
+		e.WriteString(containingKeyword)
+		e.WriteByte(' ')
+		e.dynamicValue(v.elems[0])
+	default:
+		e.error(&UnsupportedValueError{reflect.ValueOf(v), "invalid Value"})
+	}
+}
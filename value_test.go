@@ -0,0 +1,106 @@
+package asn1go
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestValueLen(t *testing.T) {
+	one := NewInt(1)
+	tests := []struct {
+		v    Value
+		kind ValueKind
+		len  int
+	}{
+		{Value{}, ValueInvalid, 0},
+		{NewNull(), ValueNull, 0},
+		{NewBool(true), ValueBoolean, 0},
+		{one, ValueInteger, 0},
+		{NewReal(1.5), ValueReal, 0},
+		{NewString("ab"), ValueString, 0},
+		{NewOctets([]byte{1, 2}), ValueOctets, 0},
+		{NewBits(BitString{Bytes: []byte{0x80}, BitLength: 1}), ValueBits, 0},
+		{NewIdentifier("on"), ValueIdentifier, 0},
+		{NewOID(ObjectIdentifier{2, 23, 143}), ValueOID, 0},
+		{NewSequence(ValueField{"a", one}, ValueField{"b", one}), ValueSequence, 2},
+		{NewSequence(), ValueSequence, 0},
+		{NewList(one, one, one), ValueList, 3},
+		{NewList(), ValueList, 0},
+		{NewChoice("alt", one), ValueChoice, 0},
+		{NewOpenType("INTEGER", one), ValueOpenType, 0},
+		{NewContaining(one), ValueContaining, 0},
+	}
+	for _, tt := range tests {
+		if k := tt.v.Kind(); k != tt.kind {
+			t.Errorf("Kind() = %v, want %v", k, tt.kind)
+		}
+		if n := tt.v.Len(); n != tt.len {
+			t.Errorf("%v Value: Len() = %d, want %d", tt.kind, n, tt.len)
+		}
+	}
+}
+
+func TestValueAccessors(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	seq := NewSequence(ValueField{"a", NewInt(1)}, ValueField{"b", NewString("x")})
+	if v, ok := seq.Field("b"); !ok || v.Text() != "x" {
+		t.Errorf(`Field("b") = %v, %v, want "x", true`, v, ok)
+	}
+	if _, ok := seq.Field("c"); ok {
+		t.Error(`Field("c") reported true for a missing component`)
+	}
+	if n, ok := NewBigInt(huge).Int(); ok {
+		t.Errorf("Int() of %v = %d, true, want false", huge, n)
+	}
+	if got := NewBigInt(huge).BigInt(); got.Cmp(huge) != 0 {
+		t.Errorf("BigInt() = %v, want %v", got, huge)
+	}
+	if got := NewChoice("alt", NewBool(true)); got.Name() != "alt" || !got.Elem().Bool() {
+		t.Errorf("NewChoice: Name() = %q, Elem() = %v", got.Name(), got.Elem())
+	}
+	if got := NewList(NewInt(7)).Index(0); !reflect.DeepEqual(got, NewInt(7)) {
+		t.Errorf("Index(0) = %v, want 7", got)
+	}
+}
+
+func TestValueUnmarshal(t *testing.T) {
+	tests := []struct {
+		in   string
+		kind ValueKind
+	}{
+		{"NULL", ValueNull},
+		{"TRUE", ValueBoolean},
+		{"-5", ValueInteger},
+		{"1.5", ValueReal},
+		{`"text"`, ValueString},
+		{"'0A'H", ValueOctets},
+		{"'01'B", ValueBits},
+		{"enabled", ValueIdentifier},
+		{"{ a 1, b 2 }", ValueSequence},
+		{"{ 1, 2 }", ValueList},
+		{"alt : 1", ValueChoice},
+		{"INTEGER : 1", ValueOpenType},
+		{"CONTAINING { a 1 }", ValueContaining},
+		{"v T ::= alt : 1", ValueChoice},
+	}
+	for _, tt := range tests {
+		var v Value
+		if err := Unmarshal([]byte(tt.in), &v); err != nil {
+			t.Errorf("Unmarshal(%q): %v", tt.in, err)
+			continue
+		}
+		if v.Kind() != tt.kind {
+			t.Errorf("Unmarshal(%q) Kind() = %v, want %v", tt.in, v.Kind(), tt.kind)
+		}
+		out, err := Marshal(v)
+		if err != nil {
+			t.Errorf("Marshal of %q: %v", tt.in, err)
+			continue
+		}
+		var again Value
+		if err := Unmarshal(out, &again); err != nil || !reflect.DeepEqual(again, v) {
+			t.Errorf("Unmarshal(Marshal(%q)) = %v, %v, want %v", tt.in, again, err, v)
+		}
+	}
+}
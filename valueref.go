@@ -0,0 +1,100 @@
+package asn1go
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Value notation may refer to a value defined elsewhere by its value
+// reference, as in "{ maxSize ub-filesize }". A Decoder that resolves
+// references, see Decoder.ResolveReferences, replaces such an identifier
+// in value position by the value of the value assignment it names.
+
+// A ValueReferenceError describes a value reference that cannot be
+// resolved because its value refers back to it.
+type ValueReferenceError struct {
+	Cycle  []string // the references followed, beginning and ending with the same one
+	Offset int64    // error occurred after reading Offset bytes
+}
+
+func (e *ValueReferenceError) Error() string {
+	return "asn1go: cyclic value reference " + strings.Join(e.Cycle, " -> ")
+}
+
+// reference returns a decodeState positioned at the beginning of the value
+// of the value reference name, if d resolves references and knows it, to
+// decode that value in place of the reference. It returns nil otherwise,
+// and after saving an error if the value refers back to name or is not a
+// single valid value.
+func (d *decodeState) reference(name []byte) *decodeState {
+	raw, ok := d.refs[string(name)]
+	if !ok {
+		return nil
+	}
+	for i, r := range d.resolving {
+		if r == string(name) {
+			cycle := append(append([]string(nil), d.resolving[i:]...), r)
+			d.saveError(&ValueReferenceError{Cycle: cycle, Offset: int64(d.readIndex())})
+			return nil
+		}
+	}
+	sub := &decodeState{
+		refs:         d.refs,
+		resolving:    append(d.resolving[:len(d.resolving):len(d.resolving)], string(name)),
+		useNumber:    d.useNumber,
+		hexPolicy:    d.hexPolicy,
+		noDuplicates: d.noDuplicates,
+		keepUnknown:  d.keepUnknown,
+		registry:     d.registry,
+		resolvers:    d.resolvers,
+		schema:       d.schema,
+		ctx:          d.ctx,
+	}
+	sub.scan.reset()
+	sub.scan.allowMultipleTopValues = false
+	sub.scan.dialect = d.scan.dialect
+	if _, err := scanValid(raw, &sub.scan); err != nil {
+		d.saveError(err)
+		return nil
+	}
+	sub.init(raw)
+	sub.utcTime, sub.named = d.utcTime, d.named
+	sub.scan.reset()
+	sub.scanWhile(scanSkipSpace)
+	return sub
+}
+
+// referenceStore decodes the value of a value reference into v with sub,
+// as reference returned it, and saves its errors in d. Type errors are
+// reported at the reference, the last byte read by d.
+func (d *decodeState) referenceStore(sub *decodeState, v reflect.Value) error {
+	if _, err := sub.topValue(v); err != nil {
+		return err
+	}
+	d.saveReferenceError(sub)
+	return nil
+}
+
+// referenceInterface is like referenceStore but returns the value as
+// valueInterface does.
+func (d *decodeState) referenceInterface(sub *decodeState) interface{} {
+	var val interface{}
+	if _, err := sub.topValue(reflect.ValueOf(&val).Elem()); err != nil {
+		d.saveError(err)
+	}
+	d.saveReferenceError(sub)
+	return val
+}
+
+// saveReferenceError saves the error of sub, if any, in d.
+func (d *decodeState) saveReferenceError(sub *decodeState) {
+	switch err := sub.savedError.(type) {
+	case nil:
+		return
+	case *UnmarshalTypeError:
+		err.Offset = int64(d.readIndex())
+	case *ValueReferenceError:
+		err.Offset = int64(d.readIndex())
+	}
+	d.saveError(sub.savedError)
+}
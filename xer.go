@@ -0,0 +1,753 @@
+package asn1go
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EncodeXER returns the XER encoding of v as a value of the ASN.1 type t.
+// It takes the same values as EncodeDER, except that the encoding a value
+// of an open type holds is written in hexadecimal digits.
+//
+// The encoding is an element named after the type reference of t, or the
+// built-in type such as OCTET_STRING, and has no whitespace between its
+// elements, as in the canonical XER. BOOLEAN and ENUMERATED values are
+// empty elements such as <true/>, components of a SEQUENCE or SET and the
+// alternative of a CHOICE are elements named after their identifiers and
+// the elements of a SEQUENCE OF or SET OF are elements named after their
+// type, unless they are BOOLEAN, ENUMERATED or CHOICE values. BIT STRING
+// values are binary digits and OCTET STRING values hexadecimal digits.
+func EncodeXER(t *Type, v interface{}) ([]byte, error) {
+	var e xerEncoder
+	return e.marshal(t, reflect.ValueOf(v))
+}
+
+// MarshalXER returns the XER encoding of v, as a value of the ASN.1 type
+// TypeOf derives from the type of v.
+func MarshalXER(v interface{}) ([]byte, error) {
+	t, err := TypeOf(v)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeXER(t, v)
+}
+
+// DecodeXER parses data as the XER encoding of a value of the ASN.1 type t
+// and stores the result in the value pointed to by v, which must be a
+// non-nil pointer. It stores values as DecodeJER does. As BASIC-XER
+// allows, whitespace may surround the elements and the contents of values
+// other than character strings, and may appear within BIT STRING and
+// OCTET STRING values.
+//
+// Malformed XML and XML that does not match t are reported as an
+// XERSyntaxError. If a value is not appropriate for a given target type,
+// DecodeXER skips that value and completes the decoding as best it can,
+// and then returns an UnmarshalTypeError describing the earliest such
+// error.
+func DecodeXER(t *Type, data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+	d := xerDecoder{dec: xml.NewDecoder(bytes.NewReader(data))}
+	return d.unmarshal(t, rv)
+}
+
+// UnmarshalXER parses the XER encoded data and stores the result in the
+// value pointed to by v, which must be a non-nil pointer. The ASN.1 type
+// of the encoding is read from the Go type of v as TypeOf describes.
+func UnmarshalXER(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+	t, err := TypeOf(v)
+	if err != nil {
+		return err
+	}
+	return DecodeXER(t, data, v)
+}
+
+// An XERSyntaxError describes malformed XER input, or XML that is not a
+// value of the ASN.1 type it is decoded as.
+type XERSyntaxError struct {
+	Msg    string
+	Offset int64 // error occurred after reading Offset bytes
+}
+
+func (e *XERSyntaxError) Error() string {
+	return "asn1go: XER syntax error at offset " + strconv.FormatInt(e.Offset, 10) + ": " + e.Msg
+}
+
+// xmlName returns the name of the elements that hold values of type t:
+// its type reference, or the name of its built-in type with underscores
+// for spaces.
+func xmlName(t *Type) string {
+	if t.Name != "" {
+		return t.Name
+	}
+	return strings.ReplaceAll(t.Kind.String(), " ", "_")
+}
+
+// isValueList reports whether the elements of a SEQUENCE OF or SET OF
+// with elements of type t are written without an element of their own,
+// as values of type t are elements already.
+func isValueList(t *Type) bool {
+	return t.Kind == KindBoolean || t.Kind == KindEnumerated || t.Kind == KindChoice
+}
+
+// controlNames holds the names of the empty elements that stand for the
+// control characters, which XML cannot carry.
+var controlNames = [0x20]string{
+	"nul", "soh", "stx", "etx", "eot", "enq", "ack", "bel",
+	"bs", "", "", "vt", "ff", "", "so", "si",
+	"dle", "dc1", "dc2", "dc3", "dc4", "nak", "syn", "etb",
+	"can", "em", "sub", "esc", "is4", "is3", "is2", "is1",
+}
+
+// controlCharacter returns the control character the empty element name
+// stands for, or -1.
+func controlCharacter(name string) int {
+	for c, n := range controlNames {
+		if n != "" && n == name {
+			return c
+		}
+	}
+	return -1
+}
+
+// An xerEncoder encodes values in XER.
+type xerEncoder struct {
+	binaryEncoder
+	buf []byte
+}
+
+func (e *xerEncoder) marshal(t *Type, v reflect.Value) (b []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if je, ok := r.(asn1Error); ok {
+				err = je.error
+			} else {
+				panic(r)
+			}
+		}
+	}()
+	e.element(xmlName(t), t, v)
+	return e.buf, nil
+}
+
+// element writes v as a value of type t in an element with the given
+// name, which is empty if the value has no contents.
+func (e *xerEncoder) element(name string, t *Type, v reflect.Value) {
+	start := len(e.buf)
+	e.buf = append(e.buf, '<')
+	e.buf = append(e.buf, name...)
+	e.buf = append(e.buf, '>')
+	content := len(e.buf)
+	e.value(t, v)
+	if len(e.buf) == content {
+		e.buf = append(e.buf[:start], '<')
+		e.buf = append(e.buf, name...)
+		e.buf = append(e.buf, "/>"...)
+		return
+	}
+	e.buf = append(e.buf, "</"...)
+	e.buf = append(e.buf, name...)
+	e.buf = append(e.buf, '>')
+}
+
+// empty writes an empty element with the given name.
+func (e *xerEncoder) empty(name string) {
+	e.buf = append(e.buf, '<')
+	e.buf = append(e.buf, name...)
+	e.buf = append(e.buf, "/>"...)
+}
+
+// value writes the contents of the element holding v as a value of type
+// t.
+func (e *xerEncoder) value(t *Type, v reflect.Value) {
+	v = derIndirect(v)
+	switch t.Kind {
+	case KindBoolean:
+		if v.Kind() != reflect.Bool {
+			e.mismatch(t, v)
+		}
+		e.empty(strconv.FormatBool(v.Bool()))
+
+	case KindInteger:
+		if v.Kind() == reflect.Struct && v.Type() == bigIntType {
+			e.buf = bigIntOf(v).Append(e.buf, 10)
+			break
+		}
+		e.buf = strconv.AppendInt(e.buf, e.integerOf(t, v), 10)
+
+	case KindEnumerated:
+		name, ok := t.nameOf(e.integerOf(t, v))
+		if !ok {
+			e.error(t, "unknown value %v", v)
+		}
+		e.empty(name)
+
+	case KindReal:
+		switch f := e.realOf(t, v); {
+		case math.IsInf(f, 1):
+			e.empty("PLUS-INFINITY")
+		case math.IsInf(f, -1):
+			e.empty("MINUS-INFINITY")
+		case math.IsNaN(f):
+			e.empty("NOT-A-NUMBER")
+		case f == 0 && math.Signbit(f):
+			e.empty("MINUS-ZERO")
+		case f == 0:
+			e.buf = append(e.buf, '0')
+		default:
+			e.buf = appendXERReal(e.buf, f)
+		}
+
+	case KindBitString:
+		bs := e.bitStringOf(t, v)
+		for i := 0; i < bs.BitLength; i++ {
+			e.buf = append(e.buf, '0'+bs.Bytes[i/8]>>uint(7-i%8)&1)
+		}
+
+	case KindOctetString:
+		b, ok := bytesOf(v)
+		if !ok {
+			e.mismatch(t, v)
+		}
+		e.buf = append(e.buf, strings.ToUpper(hex.EncodeToString(b))...)
+
+	case KindNull:
+
+	case KindObjectIdentifier:
+		oid, ok := objectIdentifierOf(v)
+		if !ok {
+			e.mismatch(t, v)
+		}
+		if _, err := appendOID(nil, oid); err != nil {
+			e.error(t, "%v", err)
+		}
+		for i, c := range oid {
+			if i > 0 {
+				e.buf = append(e.buf, '.')
+			}
+			e.buf = strconv.AppendInt(e.buf, int64(c), 10)
+		}
+
+	case KindRelativeOID:
+		oid, ok := objectIdentifierOf(v)
+		if !ok {
+			e.mismatch(t, v)
+		}
+		e.buf = append(e.buf, oid.String()...)
+
+	case KindUTF8String, KindNumericString, KindPrintableString, KindIA5String, KindVisibleString,
+		KindUTCTime, KindGeneralizedTime, KindOIDIRI, KindRelativeOIDIRI,
+		KindTime, KindDate, KindTimeOfDay, KindDateTime, KindDuration:
+		e.text(e.stringOf(t, v))
+
+	case KindSequence, KindSet:
+		e.components(t, v)
+
+	case KindSequenceOf, KindSetOf:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			e.mismatch(t, v)
+		}
+		for i := 0; i < v.Len(); i++ {
+			e.path = append(e.path, strconv.Itoa(i))
+			if isValueList(t.Elem) {
+				e.value(t.Elem, v.Index(i))
+			} else {
+				e.element(xmlName(t.Elem), t.Elem, v.Index(i))
+			}
+			e.path = e.path[:len(e.path)-1]
+		}
+
+	case KindChoice:
+		name, av, ok := choiceOf(v)
+		if !ok {
+			e.mismatch(t, v)
+		}
+		c := t.Component(name)
+		if c == nil {
+			e.error(t, "unknown alternative %s", name)
+		}
+		e.path = append(e.path, name)
+		e.element(name, c.Type, av)
+		e.path = e.path[:len(e.path)-1]
+
+	case KindAny:
+		raw, ok := bytesOf(v)
+		if !ok || v.Kind() == reflect.String {
+			e.error(t, "open type value must be an encoding")
+		}
+		e.buf = append(e.buf, strings.ToUpper(hex.EncodeToString(raw))...)
+
+	default:
+		e.error(t, "unsupported type")
+	}
+}
+
+// text writes the character string s, escaping the characters XML
+// reserves and writing control characters as empty elements.
+func (e *xerEncoder) text(s string) {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '&':
+			e.buf = append(e.buf, "&amp;"...)
+		case c == '<':
+			e.buf = append(e.buf, "&lt;"...)
+		case c == '>':
+			e.buf = append(e.buf, "&gt;"...)
+		case c == '\r':
+			// XML turns a literal CR into LF.
+			e.buf = append(e.buf, "&#xD;"...)
+		case c < 0x20 && controlNames[c] != "":
+			e.empty(controlNames[c])
+		default:
+			e.buf = append(e.buf, c)
+		}
+	}
+}
+
+// appendXERReal appends the nonzero finite REAL value f to dst in the
+// canonical form of XER: a mantissa with one digit before the point and
+// no trailing zeros, and an exponent with neither sign nor leading zeros
+// unless it is negative, as in 1.5E2.
+func appendXERReal(dst []byte, f float64) []byte {
+	s := strconv.FormatFloat(f, 'E', -1, 64)
+	mantissa, exp, _ := strings.Cut(s, "E")
+	n, _ := strconv.Atoi(exp)
+	dst = append(dst, mantissa...)
+	dst = append(dst, 'E')
+	return strconv.AppendInt(dst, int64(n), 10)
+}
+
+// components writes the components of the SEQUENCE or SET value v present
+// as elements named after them, in the order of the components.
+func (e *xerEncoder) components(t *Type, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			e.mismatch(t, v)
+		}
+		for _, k := range v.MapKeys() {
+			if t.Component(k.String()) == nil && !(t.Extensible && k.String() == "...") {
+				e.error(t, "unknown component %s", k.String())
+			}
+		}
+	default:
+		e.mismatch(t, v)
+	}
+
+	for i := range t.Components {
+		c := &t.Components[i]
+		cv, ok := componentValue(v, c.Name)
+		if !ok {
+			if c.Optional || c.Default != nil || c.Extension {
+				continue
+			}
+			e.error(t, "missing component %s", c.Name)
+		}
+		if c.Default != nil && equalValue(c.Type, cv, reflect.ValueOf(c.Default)) {
+			continue
+		}
+		e.path = append(e.path, c.Name)
+		e.element(c.Name, c.Type, cv)
+		e.path = e.path[:len(e.path)-1]
+	}
+}
+
+// An xerDecoder decodes XER encodings.
+type xerDecoder struct {
+	storer
+	dec    *xml.Decoder
+	peeked xml.Token
+	off    int // offset of the token last read
+}
+
+func (d *xerDecoder) unmarshal(t *Type, v reflect.Value) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if je, ok := r.(asn1Error); ok {
+				err = je.error
+			} else {
+				panic(r)
+			}
+		}
+	}()
+	d.element(xmlName(t), t, v)
+	for {
+		tok, err := d.dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if cd, ok := tok.(xml.CharData); err != nil || !ok || len(bytes.TrimSpace(cd)) > 0 {
+			if _, ok := tok.(xml.Comment); !ok {
+				d.off = int(d.dec.InputOffset())
+				d.syntaxError("trailing data after top-level value")
+			}
+		}
+	}
+	return d.savedError
+}
+
+// syntaxError aborts the decoding with an XERSyntaxError at the token last
+// read.
+func (d *xerDecoder) syntaxError(format string, args ...interface{}) {
+	panic(asn1Error{&XERSyntaxError{fmt.Sprintf(format, args...), int64(d.off)}})
+}
+
+// token reads the next XML token, other than comments and processing
+// instructions.
+func (d *xerDecoder) token() xml.Token {
+	if tok := d.peeked; tok != nil {
+		d.peeked = nil
+		return tok
+	}
+	for {
+		d.off = int(d.dec.InputOffset())
+		tok, err := d.dec.Token()
+		if err == io.EOF {
+			d.syntaxError("unexpected end of XML input")
+		}
+		if err != nil {
+			d.syntaxError("%v", err)
+		}
+		switch tok.(type) {
+		case xml.Comment, xml.ProcInst, xml.Directive:
+			continue
+		}
+		return xml.CopyToken(tok)
+	}
+}
+
+// unread makes tok the next token token returns.
+func (d *xerDecoder) unread(tok xml.Token) {
+	d.peeked = tok
+}
+
+// start reads the start of the next element, skipping whitespace. It
+// reports false, reading nothing, at the end of the enclosing element.
+func (d *xerDecoder) start() (xml.StartElement, bool) {
+	for {
+		switch tok := d.token().(type) {
+		case xml.StartElement:
+			return tok, true
+		case xml.EndElement:
+			d.unread(tok)
+			return xml.StartElement{}, false
+		case xml.CharData:
+			if len(bytes.TrimSpace(tok)) > 0 {
+				d.syntaxError("unexpected text %q", bytes.TrimSpace(tok))
+			}
+		}
+	}
+}
+
+// end reads the end of the element name, skipping whitespace.
+func (d *xerDecoder) end(name string) {
+	if _, ok := d.start(); ok {
+		d.syntaxError("unexpected element in %s", name)
+	}
+	if tok := d.token().(xml.EndElement); tok.Name.Local != name {
+		d.syntaxError("unexpected end of %s, want %s", tok.Name.Local, name)
+	}
+}
+
+// skip reads the rest of the element being read, up to and including its
+// end, and discards it.
+func (d *xerDecoder) skip() {
+	depth := 0
+	for {
+		switch d.token().(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				return
+			}
+			depth--
+		}
+	}
+}
+
+// element reads an element with the given name holding a value of type t
+// into v.
+func (d *xerDecoder) element(name string, t *Type, v reflect.Value) {
+	se, ok := d.start()
+	if !ok {
+		d.syntaxError("missing element %s", name)
+	}
+	if se.Name.Local != name {
+		d.syntaxError("unexpected element %s, want %s", se.Name.Local, name)
+	}
+	d.value(t, v)
+	d.end(name)
+}
+
+// text reads the text in the element being read, with any empty elements
+// for control characters, up to the end of the element.
+func (d *xerDecoder) text() string {
+	var b []byte
+	for {
+		switch tok := d.token().(type) {
+		case xml.CharData:
+			b = append(b, tok...)
+		case xml.StartElement:
+			c := controlCharacter(tok.Name.Local)
+			if c < 0 {
+				d.syntaxError("unexpected element %s in text", tok.Name.Local)
+			}
+			b = append(b, byte(c))
+			d.end(tok.Name.Local)
+		default:
+			d.unread(tok)
+			return string(b)
+		}
+	}
+}
+
+// word reads the contents of the element being read as text with the
+// surrounding whitespace taken off, or the name of the empty element it
+// holds. It reports whether it was an empty element.
+func (d *xerDecoder) word() (string, bool) {
+	for {
+		switch tok := d.token().(type) {
+		case xml.CharData:
+			if s := strings.TrimSpace(string(tok)); s != "" {
+				return s + strings.TrimSpace(d.text()), false
+			}
+		case xml.StartElement:
+			d.end(tok.Name.Local)
+			return tok.Name.Local, true
+		default:
+			d.unread(tok)
+			return "", false
+		}
+	}
+}
+
+// value reads the contents of the element holding a value of type t into
+// v, up to the end of the element.
+func (d *xerDecoder) value(t *Type, v reflect.Value) {
+	v = target(v)
+	if v.IsValid() && v.Type() == valueType {
+		d.dynamic(t, d.off, v, func(v reflect.Value) { d.value(t, v) })
+		return
+	}
+	off := d.off
+	switch t.Kind {
+	case KindBoolean:
+		switch s, _ := d.word(); s {
+		case "true":
+			d.store(t, off, true, v)
+		case "false":
+			d.store(t, off, false, v)
+		default:
+			d.syntaxError("invalid BOOLEAN %q", s)
+		}
+
+	case KindInteger:
+		s, isElem := d.word()
+		n, ok := t.NamedValue(s)
+		switch {
+		case ok:
+			d.store(t, off, n, v)
+		case isElem:
+			d.syntaxError("unknown value %s of %v", s, t)
+		default:
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				d.store(t, off, n, v)
+			} else if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+				d.store(t, off, u, v)
+			} else if b, ok := new(big.Int).SetString(s, 10); ok {
+				d.store(t, off, b, v)
+			} else {
+				d.syntaxError("invalid INTEGER %q", s)
+			}
+		}
+
+	case KindEnumerated:
+		s, _ := d.word()
+		n, ok := t.NamedValue(s)
+		if !ok {
+			d.syntaxError("unknown value %s of %v", s, t)
+		}
+		d.store(t, off, n, v)
+
+	case KindReal:
+		s, isElem := d.word()
+		var f float64
+		switch {
+		case isElem && s == "PLUS-INFINITY":
+			f = math.Inf(1)
+		case isElem && s == "MINUS-INFINITY":
+			f = math.Inf(-1)
+		case isElem && s == "NOT-A-NUMBER":
+			f = math.NaN()
+		case isElem && s == "MINUS-ZERO":
+			f = math.Copysign(0, -1)
+		case !isElem && strings.Trim(s, "0123456789+-.eE") == "":
+			var err error
+			if f, err = strconv.ParseFloat(s, 64); err == nil {
+				break
+			}
+			fallthrough
+		default:
+			d.syntaxError("invalid REAL %q", s)
+		}
+		d.store(t, off, f, v)
+
+	case KindBitString:
+		s := stripSpace(d.text())
+		bs := BitString{Bytes: make([]byte, (len(s)+7)/8), BitLength: len(s)}
+		for i := 0; i < len(s); i++ {
+			switch s[i] {
+			case '1':
+				bs.Bytes[i/8] |= 0x80 >> uint(i%8)
+			case '0':
+			default:
+				d.syntaxError("invalid BIT STRING")
+			}
+		}
+		d.store(t, off, bs, v)
+
+	case KindOctetString, KindAny:
+		b, err := hex.DecodeString(stripSpace(d.text()))
+		if err != nil {
+			d.syntaxError("invalid hexadecimal digits in %v", t)
+		}
+		d.store(t, off, b, v)
+
+	case KindNull:
+		if s, _ := d.word(); s != "" {
+			d.syntaxError("invalid NULL")
+		}
+		d.store(t, off, nil, v)
+
+	case KindObjectIdentifier:
+		s, _ := d.word()
+		oid, ok := parseDottedOID(s)
+		if !ok {
+			d.syntaxError("invalid OBJECT IDENTIFIER %q", s)
+		}
+		d.store(t, off, oid, v)
+
+	case KindRelativeOID:
+		s, _ := d.word()
+		oid, ok := parseDottedRelativeOID(s)
+		if !ok {
+			d.syntaxError("invalid RELATIVE-OID %q", s)
+		}
+		d.store(t, off, oid, v)
+
+	case KindUTF8String, KindNumericString, KindPrintableString, KindIA5String, KindVisibleString,
+		KindUTCTime, KindGeneralizedTime, KindOIDIRI, KindRelativeOIDIRI,
+		KindTime, KindDate, KindTimeOfDay, KindDateTime, KindDuration:
+		s := d.text()
+		if !validString(t.Kind, s) {
+			d.syntaxError("invalid character in %v", t.Kind)
+		}
+		d.store(t, off, s, v)
+
+	case KindSequence, KindSet:
+		d.components(t, v)
+
+	case KindSequenceOf, KindSetOf:
+		l := d.list(t, off, v)
+		for {
+			se, ok := d.start()
+			if !ok {
+				break
+			}
+			if isValueList(t.Elem) {
+				// The element read is the value itself.
+				d.unread(se)
+				d.value(t.Elem, l.next())
+				continue
+			}
+			if name := xmlName(t.Elem); se.Name.Local != name {
+				d.syntaxError("unexpected element %s, want %s", se.Name.Local, name)
+			}
+			d.value(t.Elem, l.next())
+			d.end(se.Name.Local)
+		}
+		l.finish()
+
+	case KindChoice:
+		se, ok := d.start()
+		if !ok {
+			d.syntaxError("missing alternative of %v", t)
+		}
+		c := t.Component(se.Name.Local)
+		if c == nil {
+			d.syntaxError("unknown alternative %s of %v", se.Name.Local, t)
+		}
+		d.storeChoice(t, c, off, v, func(v reflect.Value) { d.value(c.Type, v) })
+		d.end(c.Name)
+
+	default:
+		d.syntaxError("unsupported type %v", t)
+	}
+}
+
+// components reads the elements of the components of a SEQUENCE or SET
+// value of type t into the struct or map v. Those of a SEQUENCE must be in
+// the order of the components.
+func (d *xerDecoder) components(t *Type, v reflect.Value) {
+	off := d.off
+	ct := d.composite(t, off, v)
+	found := make([]bool, len(t.Components))
+	last := -1
+	for {
+		se, ok := d.start()
+		if !ok {
+			break
+		}
+		c := t.Component(se.Name.Local)
+		if c == nil && t.Extensible {
+			// An extension addition that t does not know.
+			d.skip()
+			continue
+		}
+		if c == nil {
+			d.syntaxError("unknown component %s of %v", se.Name.Local, t)
+		}
+		i := c.index(t)
+		switch {
+		case found[i]:
+			d.syntaxError("duplicate component %s of %v", c.Name, t)
+		case t.Kind == KindSequence && i < last:
+			d.syntaxError("component %s of %v out of order", c.Name, t)
+		}
+		found[i], last = true, i
+		d.component(ct, c, d.off, func(v reflect.Value) { d.value(c.Type, v) })
+		d.end(c.Name)
+	}
+	for i := range t.Components {
+		if c := &t.Components[i]; !found[i] && !c.Optional && c.Default == nil && !c.Extension {
+			d.syntaxError("missing component %s of %v", c.Name, t)
+		}
+	}
+	d.finish(ct, t, off, found)
+}
+
+// stripSpace returns s without its whitespace.
+func stripSpace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,142 @@
+package asn1go
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestXER(t *testing.T) {
+	boolean := &Type{Kind: KindBoolean}
+	seq := &Type{Name: "Rec", Kind: KindSequence, Components: []Component{
+		{Name: "b", Type: boolean},
+		{Name: "i", Type: &Type{Kind: KindInteger}, Default: int64(3)},
+		{Name: "o", Type: &Type{Kind: KindOctetString}, Optional: true},
+	}}
+	choice := &Type{Kind: KindChoice, Components: []Component{
+		{Name: "x", Type: &Type{Kind: KindNull}},
+		{Name: "y", Type: boolean},
+	}}
+	tests := []struct {
+		t    *Type
+		v    interface{}
+		want string
+	}{
+		{boolean, true, `<BOOLEAN><true/></BOOLEAN>`},
+		{&Type{Kind: KindInteger}, int64(-5), `<INTEGER>-5</INTEGER>`},
+		{&Type{Name: "Onoff", Kind: KindEnumerated, Named: []NamedNumber{{"off", 0, false}, {"on", 1, false}}}, "off", `<Onoff><off/></Onoff>`},
+		{&Type{Kind: KindReal}, 1.5, `<REAL>1.5E0</REAL>`},
+		{&Type{Kind: KindReal}, math.Inf(-1), `<REAL><MINUS-INFINITY/></REAL>`},
+		{&Type{Kind: KindNull}, nil, `<NULL/>`},
+		{&Type{Kind: KindOctetString}, []byte{0xDE, 0xAD}, `<OCTET_STRING>DEAD</OCTET_STRING>`},
+		{&Type{Kind: KindBitString}, BitString{Bytes: []byte{0xA0}, BitLength: 3}, `<BIT_STRING>101</BIT_STRING>`},
+		{&Type{Kind: KindObjectIdentifier}, ObjectIdentifier{1, 2, 840}, `<OBJECT_IDENTIFIER>1.2.840</OBJECT_IDENTIFIER>`},
+		{&Type{Kind: KindUTF8String}, "a<b&", `<UTF8String>a&lt;b&amp;</UTF8String>`},
+		{&Type{Kind: KindSequenceOf, Elem: &Type{Kind: KindInteger}}, []interface{}{int64(1), int64(2)}, `<SEQUENCE_OF><INTEGER>1</INTEGER><INTEGER>2</INTEGER></SEQUENCE_OF>`},
+		{&Type{Kind: KindSequenceOf, Elem: boolean}, []interface{}{true, false}, `<SEQUENCE_OF><true/><false/></SEQUENCE_OF>`},
+		{choice, map[string]interface{}{"y": true}, `<CHOICE><y><true/></y></CHOICE>`},
+		{choice, map[string]interface{}{"x": nil}, `<CHOICE><x/></CHOICE>`},
+		{seq, map[string]interface{}{"b": true, "i": int64(4), "o": []byte{0xAB}}, `<Rec><b><true/></b><i>4</i><o>AB</o></Rec>`},
+		{seq, map[string]interface{}{"b": false, "i": int64(3)}, `<Rec><b><false/></b></Rec>`},
+	}
+	for _, tt := range tests {
+		b, err := EncodeXER(tt.t, tt.v)
+		if err != nil {
+			t.Errorf("EncodeXER(%v, %v): %v", tt.t, tt.v, err)
+			continue
+		}
+		if string(b) != tt.want {
+			t.Errorf("EncodeXER(%v, %v) = %s, want %s", tt.t, tt.v, b, tt.want)
+		}
+		var got interface{}
+		if err := DecodeXER(tt.t, []byte(tt.want), &got); err != nil {
+			t.Errorf("DecodeXER(%v, %s): %v", tt.t, tt.want, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.v) {
+			t.Errorf("DecodeXER(%v, %s) = %#v, want %#v", tt.t, tt.want, got, tt.v)
+		}
+	}
+}
+
+func TestDecodeXERBasic(t *testing.T) {
+	seq := &Type{Name: "Rec", Kind: KindSequence, Components: []Component{
+		{Name: "b", Type: &Type{Kind: KindBoolean}},
+		{Name: "bs", Type: &Type{Kind: KindBitString}},
+		{Name: "o", Type: &Type{Kind: KindOctetString}},
+		{Name: "s", Type: &Type{Kind: KindUTF8String}},
+		{Name: "l", Type: &Type{Kind: KindSequenceOf, Elem: &Type{Kind: KindReal}}},
+	}}
+	in := `<?xml version="1.0"?>
+<Rec>
+  <b> <true/> </b>
+  <bs> 1 0 1 </bs>
+  <o> DE AD </o>
+  <s>  keep  </s>
+  <!-- comment -->
+  <l><REAL>1.5E2</REAL></l>
+</Rec>
+`
+	want := map[string]interface{}{
+		"b":  true,
+		"bs": BitString{Bytes: []byte{0xA0}, BitLength: 3},
+		"o":  []byte{0xDE, 0xAD},
+		"s":  "  keep  ",
+		"l":  []interface{}{150.0},
+	}
+	var got interface{}
+	if err := DecodeXER(seq, []byte(in), &got); err != nil {
+		t.Fatalf("DecodeXER: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeXER:\nhave %#v\nwant %#v", got, want)
+	}
+}
+
+func TestDecodeXERError(t *testing.T) {
+	seq := &Type{Name: "Rec", Kind: KindSequence, Components: []Component{
+		{Name: "b", Type: &Type{Kind: KindBoolean}},
+		{Name: "e", Type: &Type{Kind: KindEnumerated, Named: []NamedNumber{{"on", 1, false}}}, Optional: true},
+	}}
+	tests := []string{
+		`<Rec></Rec>`,
+		`<Rec><e><on/></e><b><true/></b></Rec>`,
+		`<Foo/>`,
+		`<Rec><b><maybe/></b></Rec>`,
+		`<Rec><b><true/></b>`,
+		`<Rec><b><true/></b></Rec>x`,
+	}
+	for _, in := range tests {
+		var v interface{}
+		err := DecodeXER(seq, []byte(in), &v)
+		if _, ok := err.(*XERSyntaxError); !ok {
+			t.Errorf("DecodeXER(%s): error %v, want XERSyntaxError", in, err)
+		}
+	}
+}
+
+func TestMarshalXER(t *testing.T) {
+	type record struct {
+		A int
+		B []string
+	}
+	v := record{A: 1, B: []string{"x"}}
+	want := `<record><a>1</a><b><UTF8String>x</UTF8String></b></record>`
+	b, err := MarshalXER(v)
+	if err != nil {
+		t.Fatalf("MarshalXER: %v", err)
+	}
+	if string(b) != want {
+		t.Errorf("MarshalXER = %s, want %s", b, want)
+	}
+	var got record
+	if err := UnmarshalXER(b, &got); err != nil {
+		t.Fatalf("UnmarshalXER: %v", err)
+	}
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("UnmarshalXER = %+v, want %+v", got, v)
+	}
+	if err := UnmarshalXER(b, got); err == nil {
+		t.Error("UnmarshalXER into a non-pointer: no error")
+	}
+}
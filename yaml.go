@@ -0,0 +1,704 @@
+package asn1go
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/openesim/asn1go/ast"
+)
+
+// ToYAML converts the ASN.1 value notation document data to YAML, without
+// a schema, the way ToJSON converts it to JSON, in the block style and
+// with the members of mappings in the order of the document. The values
+// that JSON does not tell apart from strings and objects are tagged:
+//
+//	hstring           !hex 4F3A
+//	bstring           !bits 0101
+//	identifier        !ident disabled
+//	OBJECT IDENTIFIER !oid 2.23.143
+//	CHOICE            !choice, on a mapping with a single member
+//
+// so that FromYAML needs no hints but the types of the value assignments,
+// which ToYAML drops along with the comments.
+func ToYAML(data []byte) ([]byte, error) {
+	as, err := ParseAssignments(data)
+	if err != nil {
+		return nil, err
+	}
+	unwrapContaining(as)
+	named, err := namedAssignments(as, "ToYAML")
+	if err != nil {
+		return nil, err
+	}
+	var root ast.Node
+	switch {
+	case named:
+		obj := new(ast.ObjectNode)
+		for _, a := range as {
+			obj.Elements = append(obj.Elements, &ast.FieldNode{Name: a.Name, Value: a.Value})
+		}
+		root = obj
+	case len(as) == 1:
+		root = as[0].Value
+	default:
+		obj := new(ast.ObjectNode)
+		for _, a := range as {
+			obj.Elements = append(obj.Elements, a.Value)
+		}
+		root = obj
+	}
+	var buf bytes.Buffer
+	if s, ok := yamlScalar(root); ok {
+		buf.WriteString(s)
+		buf.WriteByte('\n')
+		return buf.Bytes(), nil
+	}
+	if _, ok := root.(*ast.ChoiceNode); ok {
+		buf.WriteString("!choice\n")
+	}
+	yamlBlock(&buf, root, 0)
+	return buf.Bytes(), nil
+}
+
+// yamlScalar returns the YAML of n on a single line, if n is not a
+// non-empty brace-delimited value or a CHOICE value.
+func yamlScalar(n ast.Node) (string, bool) {
+	switch n := n.(type) {
+	case *ast.ObjectNode:
+		if len(n.Elements) == 0 {
+			return "[]", true
+		}
+	case *ast.OIDNode:
+		var buf bytes.Buffer
+		nodeJSON(&buf, n)
+		s, _ := strconv.Unquote(buf.String())
+		return "!oid " + yamlPlain(s), true
+	case *ast.NullNode:
+		return "null", true
+	case *ast.BoolNode:
+		return strconv.FormatBool(n.Value), true
+	case *ast.IntNode:
+		i, _ := new(big.Int).SetString(n.Text, 10)
+		return i.String(), true
+	case *ast.RealNode:
+		return jsonReal(n.Text), true
+	case *ast.HexNode:
+		return "!hex " + yamlPlain(strings.ToUpper(n.Digits)), true
+	case *ast.BitsNode:
+		return "!bits " + yamlPlain(n.Digits), true
+	case *ast.StringNode:
+		var buf bytes.Buffer
+		jsonString(&buf, n.Value)
+		return buf.String(), true
+	case *ast.Ident:
+		return "!ident " + yamlPlain(n.Name), true
+	}
+	return "", false
+}
+
+// yamlPlain returns s as a plain scalar if it is a run of letters, digits,
+// hyphens and dots, and as a double-quoted scalar otherwise.
+func yamlPlain(s string) string {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !isLetterOrDigit(c) && (i == 0 || c != '-' && c != '.') {
+			var buf bytes.Buffer
+			jsonString(&buf, s)
+			return buf.String()
+		}
+	}
+	if s == "" {
+		return `""`
+	}
+	return s
+}
+
+func isLetterOrDigit(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// yamlBlock writes the non-empty brace-delimited or CHOICE value n, or a
+// component of a list, to buf as a block collection indented by indent
+// levels.
+func yamlBlock(buf *bytes.Buffer, n ast.Node, indent int) {
+	switch n := n.(type) {
+	case *ast.ChoiceNode:
+		yamlEntry(buf, n.Name.Name, n.Value, indent)
+	case *ast.FieldNode:
+		// A component among elements without identifiers, which is a
+		// mapping with a single member, as in ToJSON.
+		yamlEntry(buf, n.Name.Name, n.Value, indent)
+	case *ast.ObjectNode:
+		if fields, ok := componentsOf(n); ok {
+			for _, f := range fields {
+				yamlEntry(buf, f.Name.Name, f.Value, indent)
+			}
+			return
+		}
+		pad := strings.Repeat("  ", indent)
+		for _, el := range n.Elements {
+			if s, ok := yamlScalar(el); ok {
+				buf.WriteString(pad + "- " + s + "\n")
+				continue
+			}
+			if _, ok := el.(*ast.ChoiceNode); ok {
+				buf.WriteString(pad + "- !choice\n")
+				yamlBlock(buf, el, indent+1)
+				continue
+			}
+			// Start the collection on the line of its hyphen, which
+			// takes the place of one level of indentation.
+			start := buf.Len()
+			yamlBlock(buf, el, indent+1)
+			copy(buf.Bytes()[start+len(pad):], "- ")
+		}
+	}
+}
+
+// yamlEntry writes the mapping entry of key and the value v to buf.
+func yamlEntry(buf *bytes.Buffer, key string, v ast.Node, indent int) {
+	buf.WriteString(strings.Repeat("  ", indent) + key + ":")
+	if s, ok := yamlScalar(v); ok {
+		buf.WriteString(" " + s + "\n")
+		return
+	}
+	if _, ok := v.(*ast.ChoiceNode); ok {
+		buf.WriteString(" !choice")
+	}
+	buf.WriteByte('\n')
+	yamlBlock(buf, v, indent+1)
+}
+
+// FromYAML converts the YAML yamlData, such as that returned by ToYAML, to
+// ASN.1 value notation the way FromJSON converts JSON. The tags of ToYAML
+// give the kinds of the values they are on, and hints the types of value
+// assignments and the kinds of untagged values; hints may be nil.
+//
+// FromYAML reads a single document in the block and flow styles, with
+// plain and quoted scalars on a single line; it does not support anchors,
+// aliases or block scalars.
+func FromYAML(yamlData []byte, hints *JSONHints) ([]byte, error) {
+	if hints == nil {
+		hints = new(JSONHints)
+	}
+	p, err := newYAMLParser(yamlData)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if len(p.lines) > 0 {
+		if v, err = p.node(-1); err != nil {
+			return nil, err
+		}
+	}
+	if p.i < len(p.lines) {
+		return nil, p.errorf(p.lines[p.i].num, "bad indentation")
+	}
+	return marshalGeneric(v, hints)
+}
+
+// A YAMLSyntaxError describes malformed YAML input of FromYAML.
+type YAMLSyntaxError struct {
+	Msg  string
+	Line int // line of the error, starting at 1
+}
+
+func (e *YAMLSyntaxError) Error() string {
+	return "asn1go: YAML syntax error at line " + strconv.Itoa(e.Line) + ": " + e.Msg
+}
+
+var yamlTags = map[string]JSONKind{
+	"!hex":    JSONHex,
+	"!bits":   JSONBits,
+	"!ident":  JSONIdent,
+	"!oid":    JSONOID,
+	"!choice": JSONChoice,
+	"!!str":   JSONDefault,
+	"!!map":   JSONDefault,
+	"!!seq":   JSONDefault,
+}
+
+// A yamlLine is a line of YAML that is not blank or a comment.
+type yamlLine struct {
+	num    int // line number, starting at 1
+	indent int
+	text   string // without the indentation and comment
+}
+
+// A yamlParser reads YAML into the values that readJSON returns, and
+// taggedValue values.
+type yamlParser struct {
+	lines []yamlLine
+	i     int // index of the next line
+}
+
+func newYAMLParser(data []byte) (*yamlParser, error) {
+	p := new(yamlParser)
+	for i, l := range strings.Split(string(data), "\n") {
+		text := strings.TrimLeft(l, " ")
+		indent := len(l) - len(text)
+		text = strings.TrimRight(yamlStripComment(text), " \t\r")
+		switch {
+		case text == "":
+			continue
+		case text[0] == '\t':
+			return nil, p.errorf(i+1, "tab in indentation")
+		case (text == "---" || strings.HasPrefix(text, "--- ")) && indent == 0:
+			if len(p.lines) > 0 {
+				return nil, p.errorf(i+1, "more than one document")
+			}
+			if text = strings.TrimLeft(text[3:], " "); text == "" {
+				continue
+			}
+		case text == "..." && indent == 0:
+			return p, nil
+		case text[0] == '%' && indent == 0:
+			// A directive, such as %YAML 1.2.
+			continue
+		}
+		p.lines = append(p.lines, yamlLine{num: i + 1, indent: indent, text: text})
+	}
+	return p, nil
+}
+
+// yamlStripComment returns the line text without its comment.
+func yamlStripComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return text[:i]
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" \t[{,:", text[i-1]) >= 0):
+			quote = c
+		}
+	}
+	return text
+}
+
+func (p *yamlParser) errorf(line int, msg string) error {
+	return &YAMLSyntaxError{Msg: msg, Line: line}
+}
+
+func isYAMLSequenceEntry(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// node reads the block node starting at the next line, which is indented
+// more than parent.
+func (p *yamlParser) node(parent int) (interface{}, error) {
+	l := p.lines[p.i]
+	if isYAMLSequenceEntry(l.text) {
+		return p.sequence(l.indent)
+	}
+	if _, _, ok := yamlKey(l.text); ok {
+		return p.mapping(l.indent)
+	}
+	p.i++
+	return p.inline(l.num, l.text, parent)
+}
+
+// nested reads the block node on the lines after one indented by parent,
+// if they are indented more.
+func (p *yamlParser) nested(parent int) (interface{}, error) {
+	if p.i < len(p.lines) && p.lines[p.i].indent > parent {
+		return p.node(parent)
+	}
+	return nil, nil
+}
+
+// inline reads the value text on the line num, the rest of a node indented
+// more than parent. A tag alone tags the block node on the next lines.
+func (p *yamlParser) inline(num int, text string, parent int) (interface{}, error) {
+	if text[0] == '!' && !strings.Contains(text, " ") {
+		kind, ok := yamlTags[text]
+		if !ok {
+			return nil, p.errorf(num, "unknown tag "+text)
+		}
+		v, err := p.nested(parent)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			// An empty node, which is an empty scalar under a tag.
+			v = ""
+		}
+		return taggedValue{kind, v}, nil
+	}
+	s := &yamlScanner{s: text}
+	v, err := s.value()
+	if err == nil && s.skipSpace() < len(s.s) {
+		err = s.errorf("unexpected " + strconv.Quote(s.s[s.i:]) + " after value")
+	}
+	if err != nil {
+		return nil, p.errorf(num, err.Error())
+	}
+	return v, nil
+}
+
+// mapping reads the block mapping whose keys are indented by indent.
+func (p *yamlParser) mapping(indent int) (interface{}, error) {
+	obj := jsonObject{}
+	for p.i < len(p.lines) && p.lines[p.i].indent == indent {
+		l := p.lines[p.i]
+		key, rest, ok := yamlKey(l.text)
+		if !ok {
+			return nil, p.errorf(l.num, "expected mapping key")
+		}
+		p.i++
+		var v interface{}
+		var err error
+		switch {
+		case rest != "":
+			v, err = p.inline(l.num, rest, indent)
+		case p.i < len(p.lines) && p.lines[p.i].indent == indent && isYAMLSequenceEntry(p.lines[p.i].text):
+			// A sequence may be indented as much as the key it is the value of.
+			v, err = p.sequence(indent)
+		default:
+			v, err = p.nested(indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		obj = append(obj, jsonMember{key, v})
+	}
+	if p.i < len(p.lines) && p.lines[p.i].indent > indent {
+		return nil, p.errorf(p.lines[p.i].num, "bad indentation")
+	}
+	return obj, nil
+}
+
+// sequence reads the block sequence whose hyphens are indented by indent.
+func (p *yamlParser) sequence(indent int) (interface{}, error) {
+	list := []interface{}{}
+	for p.i < len(p.lines) && p.lines[p.i].indent == indent && isYAMLSequenceEntry(p.lines[p.i].text) {
+		l := &p.lines[p.i]
+		rest := strings.TrimLeft(l.text[1:], " ")
+		var v interface{}
+		var err error
+		if rest == "" {
+			p.i++
+			v, err = p.nested(indent)
+		} else {
+			// A node that starts on the line of its hyphen: read the
+			// line as if the hyphen were indentation.
+			l.indent += len(l.text) - len(rest)
+			l.text = rest
+			v, err = p.node(indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	if p.i < len(p.lines) && p.lines[p.i].indent > indent {
+		return nil, p.errorf(p.lines[p.i].num, "bad indentation")
+	}
+	return list, nil
+}
+
+// yamlKey splits the line text of a block mapping entry into its key and
+// the rest of the line after the colon.
+func yamlKey(text string) (key, rest string, ok bool) {
+	if text[0] == '"' || text[0] == '\'' {
+		s := &yamlScanner{s: text}
+		key, err := s.quoted()
+		if err != nil {
+			return "", "", false
+		}
+		s.skipSpace()
+		if !strings.HasPrefix(s.s[s.i:], ":") || s.i+1 < len(s.s) && s.s[s.i+1] != ' ' {
+			return "", "", false
+		}
+		return key, strings.TrimLeft(s.s[s.i+1:], " "), true
+	}
+	if strings.IndexByte("[{!&*|>#-?@`%", text[0]) >= 0 {
+		return "", "", false
+	}
+	i := strings.Index(text, ": ")
+	if i < 0 {
+		if !strings.HasSuffix(text, ":") {
+			return "", "", false
+		}
+		i = len(text) - 1
+	}
+	return strings.TrimRight(text[:i], " "), strings.TrimLeft(text[i+1:], " "), true
+}
+
+// A yamlScanner reads a value on a single line: a scalar, or a collection
+// in the flow style.
+type yamlScanner struct {
+	s    string
+	i    int
+	flow int // depth of flow collections
+}
+
+func (s *yamlScanner) errorf(msg string) error {
+	return errors.New(msg)
+}
+
+// skipSpace skips spaces and returns the new offset.
+func (s *yamlScanner) skipSpace() int {
+	for s.i < len(s.s) && (s.s[s.i] == ' ' || s.s[s.i] == '\t') {
+		s.i++
+	}
+	return s.i
+}
+
+func (s *yamlScanner) value() (interface{}, error) {
+	s.skipSpace()
+	tag := ""
+	if strings.HasPrefix(s.s[s.i:], "!") {
+		start := s.i
+		for s.i < len(s.s) && s.s[s.i] != ' ' && (s.flow == 0 || strings.IndexByte(",]}", s.s[s.i]) < 0) {
+			s.i++
+		}
+		tag = s.s[start:s.i]
+		if _, ok := yamlTags[tag]; !ok {
+			return nil, s.errorf("unknown tag " + tag)
+		}
+		s.skipSpace()
+	}
+	var v interface{}
+	var err error
+	c := byte(0)
+	if s.i < len(s.s) {
+		c = s.s[s.i]
+	}
+	switch c {
+	case '[':
+		v, err = s.flowSequence()
+	case '{':
+		v, err = s.flowMapping()
+	case '"', '\'':
+		v, err = s.quoted()
+	case '&', '*':
+		return nil, s.errorf("anchors and aliases are not supported")
+	case '|', '>':
+		return nil, s.errorf("block scalars are not supported")
+	default:
+		text := s.plain()
+		if tag != "" {
+			v = text
+		} else {
+			v = yamlResolve(text)
+		}
+	}
+	if err != nil || tag == "" {
+		return v, err
+	}
+	return taggedValue{yamlTags[tag], v}, nil
+}
+
+// plain reads a plain scalar, up to the end of the line, or up to a flow
+// indicator or the colon of a key inside a flow collection.
+func (s *yamlScanner) plain() string {
+	start := s.i
+	for ; s.i < len(s.s); s.i++ {
+		if s.flow > 0 && (strings.IndexByte(",]}", s.s[s.i]) >= 0 || s.s[s.i] == ':' && (s.i+1 == len(s.s) || strings.IndexByte(" ,]}", s.s[s.i+1]) >= 0)) {
+			break
+		}
+	}
+	return strings.TrimRight(s.s[start:s.i], " \t")
+}
+
+// yamlResolve returns the value of the untagged plain scalar text.
+func yamlResolve(text string) interface{} {
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if i, ok := new(big.Int).SetString(strings.TrimPrefix(text, "+"), 10); ok && isYAMLNumber(text) {
+		return json.Number(i.String())
+	}
+	if isYAMLNumber(text) {
+		return json.Number(jsonReal(strings.TrimPrefix(text, "+")))
+	}
+	return text
+}
+
+// isYAMLNumber reports whether text is a number of the YAML core schema,
+// [-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?.
+func isYAMLNumber(text string) bool {
+	i := 0
+	digits := func() int {
+		start := i
+		for i < len(text) && '0' <= text[i] && text[i] <= '9' {
+			i++
+		}
+		return i - start
+	}
+	if i < len(text) && (text[i] == '-' || text[i] == '+') {
+		i++
+	}
+	n := digits()
+	if i < len(text) && text[i] == '.' {
+		i++
+		if digits() == 0 && n == 0 {
+			return false
+		}
+	} else if n == 0 {
+		return false
+	}
+	if i < len(text) && (text[i] == 'e' || text[i] == 'E') {
+		i++
+		if i < len(text) && (text[i] == '-' || text[i] == '+') {
+			i++
+		}
+		if digits() == 0 {
+			return false
+		}
+	}
+	return i == len(text)
+}
+
+// quoted reads a single- or double-quoted scalar.
+func (s *yamlScanner) quoted() (string, error) {
+	q := s.s[s.i]
+	s.i++
+	var b strings.Builder
+	for s.i < len(s.s) {
+		c := s.s[s.i]
+		s.i++
+		switch {
+		case c == q && q == '\'' && s.i < len(s.s) && s.s[s.i] == '\'':
+			b.WriteByte('\'')
+			s.i++
+		case c == q:
+			return b.String(), nil
+		case c == '\\' && q == '"':
+			if err := s.escape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", s.errorf("unterminated quoted scalar")
+}
+
+// escape reads the escape sequence after a backslash in a double-quoted
+// scalar and writes its character to b.
+func (s *yamlScanner) escape(b *strings.Builder) error {
+	if s.i == len(s.s) {
+		return s.errorf("unterminated quoted scalar")
+	}
+	c := s.s[s.i]
+	s.i++
+	if r, ok := yamlEscapes[c]; ok {
+		b.WriteRune(r)
+		return nil
+	}
+	n := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+	if n == 0 || s.i+n > len(s.s) {
+		return s.errorf("invalid escape sequence \\" + string(c))
+	}
+	r, err := strconv.ParseUint(s.s[s.i:s.i+n], 16, 32)
+	if err != nil {
+		return s.errorf("invalid escape sequence \\" + s.s[s.i-1:s.i+n])
+	}
+	s.i += n
+	if utf16.IsSurrogate(rune(r)) && strings.HasPrefix(s.s[s.i:], `\u`) && s.i+6 <= len(s.s) {
+		if r2, err := strconv.ParseUint(s.s[s.i+2:s.i+6], 16, 32); err == nil {
+			if dec := utf16.DecodeRune(rune(r), rune(r2)); dec != utf8.RuneError {
+				s.i += 6
+				r = uint64(dec)
+			}
+		}
+	}
+	b.WriteRune(rune(r))
+	return nil
+}
+
+var yamlEscapes = map[byte]rune{
+	'0': 0, 'a': '\a', 'b': '\b', 't': '\t', '\t': '\t', 'n': '\n', 'v': '\v', 'f': '\f', 'r': '\r',
+	'e': 0x1b, ' ': ' ', '"': '"', '/': '/', '\\': '\\', 'N': 0x85, '_': 0xa0, 'L': 0x2028, 'P': 0x2029,
+}
+
+func (s *yamlScanner) flowSequence() (interface{}, error) {
+	s.i++
+	s.flow++
+	list := []interface{}{}
+	for {
+		if s.skipSpace() < len(s.s) && s.s[s.i] == ']' {
+			s.i++
+			s.flow--
+			return list, nil
+		}
+		v, err := s.value()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+		if err := s.flowSeparator(']'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (s *yamlScanner) flowMapping() (interface{}, error) {
+	s.i++
+	s.flow++
+	obj := jsonObject{}
+	for {
+		if s.skipSpace() < len(s.s) && s.s[s.i] == '}' {
+			s.i++
+			s.flow--
+			return obj, nil
+		}
+		var key string
+		if s.i < len(s.s) && (s.s[s.i] == '"' || s.s[s.i] == '\'') {
+			k, err := s.quoted()
+			if err != nil {
+				return nil, err
+			}
+			key = k
+		} else {
+			key = s.plain()
+		}
+		var v interface{}
+		if s.skipSpace() < len(s.s) && s.s[s.i] == ':' {
+			s.i++
+			var err error
+			if v, err = s.value(); err != nil {
+				return nil, err
+			}
+		}
+		obj = append(obj, jsonMember{key, v})
+		if err := s.flowSeparator('}'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// flowSeparator reads the comma after an entry of a flow collection, if
+// it is not the last one before the closing bracket end.
+func (s *yamlScanner) flowSeparator(end byte) error {
+	if s.skipSpace() == len(s.s) {
+		return s.errorf("unterminated flow collection")
+	}
+	switch s.s[s.i] {
+	case ',':
+		s.i++
+	case end:
+	default:
+		return s.errorf("expected , or " + string(end) + " in flow collection")
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package asn1go
+
+import "testing"
+
+func TestToYAML(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"sequence", "v T ::= { a 1, b TRUE }", "v:\n  a: 1\n  b: true\n"},
+		{"list", "v T ::= { 1, 2 }", "v:\n  - 1\n  - 2\n"},
+		{"choice", "v T ::= alt : 'AB'H", "v: !choice\n  alt: !hex AB\n"},
+		{"component then value", "v T ::= { a 1, { b 2 } }", "v:\n  - a: 1\n  - b: 2\n"},
+		{"component then identifier", "v T ::= { a 1, b }", "v:\n  - a: 1\n  - !ident b\n"},
+		{"nested component", "v T ::= { 1, a { b 2 } }", "v:\n  - 1\n  - a:\n      b: 2\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToYAML([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("ToYAML(%q): %v", tt.in, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ToYAML(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromYAMLMixed(t *testing.T) {
+	// The components of a list read back as SEQUENCE values of a single
+	// component, which is how Unmarshal decodes them too.
+	tests := []struct {
+		in, want string
+	}{
+		{"v T ::= { a 1, { b 2 } }", "{ v { { a 1 }, { b 2 } } }"},
+		{"v T ::= { a 1, b }", "{ v { { a 1 }, b } }"},
+	}
+	for _, tt := range tests {
+		y, err := ToYAML([]byte(tt.in))
+		if err != nil {
+			t.Fatalf("ToYAML(%q): %v", tt.in, err)
+		}
+		got, err := FromYAML(y, nil)
+		if err != nil {
+			t.Fatalf("FromYAML(%q): %v", y, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("FromYAML(%q) = %q, want %q", y, got, tt.want)
+		}
+	}
+}